@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/urfave/cli/v3"
+)
+
+var errBenchNotConfirmed = errors.New("the benchmark is load on the relays, set -confirm-load to benchmark relays which aren't on localhost")
+
+var (
+	benchDurationFlag = &cli.DurationFlag{
+		Name:     "duration",
+		Sources:  cli.EnvVars("BENCH_DURATION"),
+		Value:    10 * time.Second,
+		Usage:    "how long to send requests to the relays",
+		Category: BenchCategory,
+	}
+	benchTimeoutFlag = &cli.DurationFlag{
+		Name:     "timeout",
+		Sources:  cli.EnvVars("BENCH_TIMEOUT"),
+		Value:    10 * time.Second,
+		Usage:    "timeout of each request, above the relay timeouts so the latencies of slow requests are measured",
+		Category: BenchCategory,
+	}
+	benchGetHeaderConcurrencyFlag = &cli.IntFlag{
+		Name:     "get-header-concurrency",
+		Sources:  cli.EnvVars("BENCH_GET_HEADER_CONCURRENCY"),
+		Value:    4,
+		Usage:    "concurrent getHeader requests per relay, 0 to not benchmark getHeader",
+		Category: BenchCategory,
+	}
+	benchRegisterValidatorConcurrencyFlag = &cli.IntFlag{
+		Name:     "register-validator-concurrency",
+		Sources:  cli.EnvVars("BENCH_REGISTER_VALIDATOR_CONCURRENCY"),
+		Usage:    "concurrent registerValidator requests per relay, 0 to not benchmark registerValidator",
+		Category: BenchCategory,
+	}
+	benchRegistrationsFlag = &cli.IntFlag{
+		Name:     "registrations",
+		Sources:  cli.EnvVars("BENCH_REGISTRATIONS"),
+		Value:    100,
+		Usage:    "validator registrations of each registerValidator request, signed by random keys",
+		Category: BenchCategory,
+	}
+	benchConfirmLoadFlag = &cli.BoolFlag{
+		Name:     "confirm-load",
+		Usage:    "confirm benchmarking relays which aren't on localhost, with the consent of the relay operators",
+		Category: BenchCategory,
+	}
+
+	benchCommand = &cli.Command{
+		Name:   "bench",
+		Usage:  "send concurrent getHeader and registerValidator requests to the configured relays and report the latency percentiles",
+		Action: toolAction(runBench),
+		Flags: []cli.Flag{
+			benchDurationFlag,
+			benchTimeoutFlag,
+			benchGetHeaderConcurrencyFlag,
+			benchRegisterValidatorConcurrencyFlag,
+			benchRegistrationsFlag,
+			benchConfirmLoadFlag,
+		},
+	}
+)
+
+// runBench benchmarks the relays of the -relay flags with the relay client settings of mev-boost, for the network of
+// the genesis flags, and prints the results
+func runBench(ctx context.Context, cmd *cli.Command) error {
+	relays, err := toolRelays(cmd)
+	if err != nil {
+		return err
+	}
+	if !cmd.Bool(benchConfirmLoadFlag.Name) && !localRelays(relays) {
+		return errBenchNotConfirmed
+	}
+	genesisForkVersion, genesisTime, slotTimeSec := setupGenesis(cmd)
+
+	duration := cmd.Duration(benchDurationFlag.Name)
+	log.Infof("benchmarking %d relays for %s", len(relays), duration)
+	benches, err := tools.BenchRelays(ctx, relays, tools.RelayBenchOpts{
+		Duration:                     duration,
+		Timeout:                      cmd.Duration(benchTimeoutFlag.Name),
+		GetHeaderConcurrency:         int(cmd.Int(benchGetHeaderConcurrencyFlag.Name)),
+		RegisterValidatorConcurrency: int(cmd.Int(benchRegisterValidatorConcurrencyFlag.Name)),
+		Registrations:                int(cmd.Int(benchRegistrationsFlag.Name)),
+		GenesisForkVersionHex:        genesisForkVersion,
+		GenesisTime:                  genesisTime,
+		SlotTimeSec:                  slotTimeSec,
+		RelayClient:                  toolRelayClient(cmd),
+	})
+	if err != nil {
+		return err
+	}
+	printRelayBenches(cmd.Writer, benches)
+	return nil
+}
+
+// localRelays returns true if all relays are on localhost, like a mock relay
+func localRelays(relays []types.RelayEntry) bool {
+	for _, relay := range relays {
+		host := relay.URL.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return false
+		}
+	}
+	return true
+}
+
+// printRelayBenches writes the table of the benchmark results, followed by the last error of the endpoints with
+// failed requests. The percentiles are of the successful requests.
+func printRelayBenches(w io.Writer, benches []tools.RelayBench) {
+	table := newTable(w, "RELAY", "ENDPOINT", "REQUESTS", "ERRORS", "REQ/S", "P50", "P90", "P99", "MAX")
+	var failures []string
+	for _, bench := range benches {
+		name := bench.Relay.URL.Host
+		row := []string{name, bench.Endpoint, strconv.Itoa(bench.Requests), strconv.Itoa(bench.Errors), fmt.Sprintf("%.1f", bench.Throughput())}
+		for _, p := range []float64{0.5, 0.9, 0.99, 1} {
+			latency := "-"
+			if len(bench.Latencies) > 0 {
+				latency = tools.FormatLatency(bench.Percentile(p))
+			}
+			row = append(row, latency)
+		}
+		table.row(row...)
+		if bench.Errors > 0 {
+			failures = append(failures, fmt.Sprintf("%s: %s: %d errors, last: %s", name, bench.Endpoint, bench.Errors, bench.LastError))
+		}
+	}
+	table.flush()
+	for _, failure := range failures {
+		fmt.Fprintln(w, failure)
+	}
+}
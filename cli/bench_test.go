@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintRelayBenches(t *testing.T) {
+	relay, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay.example.com")
+	require.NoError(t, err)
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	benches := []tools.RelayBench{
+		{Relay: relay, Endpoint: tools.RelayBenchGetHeader, Requests: 100, Latencies: latencies, Elapsed: 10 * time.Second},
+		{Relay: relay, Endpoint: tools.RelayBenchRegisterValidator, Requests: 5, Errors: 5, LastError: errors.New("unknown validator"), Elapsed: 10 * time.Second},
+	}
+
+	var out bytes.Buffer
+	printRelayBenches(&out, benches)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"RELAY", "ENDPOINT", "REQUESTS", "ERRORS", "REQ/S", "P50", "P90", "P99", "MAX"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"relay.example.com", "getHeader", "100", "0", "10.0", "50.00ms", "90.00ms", "99.00ms", "100.00ms"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"relay.example.com", "registerValidator", "5", "5", "0.5", "-", "-", "-", "-"}, strings.Fields(lines[2]))
+	require.Equal(t, "relay.example.com: registerValidator: 5 errors, last: unknown validator", lines[3])
+}
+
+func TestLocalRelays(t *testing.T) {
+	relays := func(urls ...string) []types.RelayEntry {
+		entries, err := parseRelayURLs(urls)
+		require.NoError(t, err)
+		return entries
+	}
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	require.True(t, localRelays(relays("http://"+pubkey+"@localhost:28545", "http://"+pubkey+"@127.0.0.1:28546", "http://"+pubkey+"@[::1]:28547")))
+	require.False(t, localRelays(relays("http://"+pubkey+"@localhost:28545", "https://"+pubkey+"@relay.example.com")))
+}
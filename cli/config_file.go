@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// networkConfigKey selects the network in the config file, i.e. `network: holesky`
+const networkConfigKey = "network"
+
+var (
+	errUnsupportedConfigFile = errors.New("unsupported config file format, use .yaml, .yml or .toml")
+	errUnknownConfigKey      = errors.New("unknown config file key")
+	errUnknownNetwork        = errors.New("unknown network")
+	errInvalidConfigValue    = errors.New("invalid config file value")
+	errInvalidRelayConfig    = errors.New("invalid relay config")
+	errNestedConfigValue     = errors.New("nested values are not supported")
+
+	// networkFlags are the flags which can be selected with the network key of the config file
+	networkFlags = []*cli.BoolFlag{mainnetFlag, sepoliaFlag, holeskyFlag, gnosisFlag, chiadoFlag}
+
+	// relayOptions are the options of relay objects in the config file, which are passed on as relay URL query args
+	relayOptions = types.RelayArgs
+)
+
+// loadConfigFile applies the values of the config file to the flags which are neither set on the command line nor by
+// environment variable. The config file keys are the flag names, see applyConfig.
+func loadConfigFile(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if !cmd.IsSet(configFlag.Name) {
+		return ctx, nil
+	}
+
+	path := cmd.String(configFlag.Name)
+	values, err := readConfigFile(path)
+	if err != nil {
+		return ctx, fmt.Errorf("failed reading config file %s: %w", path, err)
+	}
+	if err := applyConfig(cmd, values); err != nil {
+		return ctx, fmt.Errorf("failed applying config file %s: %w", path, err)
+	}
+	return ctx, nil
+}
+
+// readConfigFile decodes a YAML or TOML config file, depending on the file extension
+func readConfigFile(path string) (map[string]any, error) {
+	values := make(map[string]any)
+	if err := decodeConfigFile(path, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// decodeConfigFile decodes a YAML or TOML file into dst, depending on the file extension. Keys without a matching struct
+// field are an error.
+func decodeConfigFile(path string, dst any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), dst)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%w: %s", errUnknownConfigKey, undecoded[0])
+		}
+	default:
+		return errUnsupportedConfigFile
+	}
+	return nil
+}
+
+// applyConfig sets the flags to the config values. Lists are applied as multiple values, relays can be given as URL or
+// as object with an url field and the relay options.
+func applyConfig(cmd *cli.Command, values map[string]any) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, value := key, values[key]
+		switch {
+		case key == networkConfigKey:
+			network := strings.ToLower(fmt.Sprint(value))
+			if !slices.ContainsFunc(networkFlags, func(f *cli.BoolFlag) bool { return f.Name == network }) {
+				return fmt.Errorf("%w: %s", errUnknownNetwork, network)
+			}
+			name, value = network, true
+		case slices.Contains(relaysFlag.Names(), key):
+			relays, err := relayConfigURLs(value)
+			if err != nil {
+				return err
+			}
+			name, value = relaysFlag.Name, relays
+		default:
+			var ok bool
+			if name, ok = flagName(key); !ok || name == configFlag.Name {
+				return fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+			}
+		}
+
+		// Flags and environment variables take precedence over the config file
+		if cmd.IsSet(name) {
+			log.WithField("flag", name).Debug("ignoring config file value, the flag is set")
+			continue
+		}
+
+		entries, err := configValues(value)
+		if err != nil {
+			return fmt.Errorf("%w for %s: %w", errInvalidConfigValue, key, err)
+		}
+		for _, entry := range entries {
+			if err := cmd.Set(name, entry); err != nil {
+				return fmt.Errorf("%w for %s: %w", errInvalidConfigValue, key, err)
+			}
+		}
+		configFileFlags[name] = true
+	}
+	return nil
+}
+
+// relayConfigURLs returns the URLs of the relays in the config file, which are either URLs or objects with an url field
+// and the relay options
+func relayConfigURLs(value any) ([]any, error) {
+	entries, ok := value.([]any)
+	if !ok {
+		entries = []any{value}
+	}
+
+	urls := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		switch relay := entry.(type) {
+		case string:
+			urls = append(urls, relay)
+		case map[string]any:
+			url, ok := relay["url"].(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: missing url", errInvalidRelayConfig)
+			}
+			args := neturl.Values{}
+			for option, value := range relay {
+				switch {
+				case option == "url":
+				case option == types.RelayArgHeader:
+					headers, err := configValues(value)
+					if err != nil {
+						return nil, fmt.Errorf("%w: %s of relay %s: %w", errInvalidRelayConfig, option, url, err)
+					}
+					for _, header := range headers {
+						args.Add(option, header)
+					}
+				case slices.Contains(relayOptions, option):
+					args.Set(option, relayOptionValue(value))
+				default:
+					return nil, fmt.Errorf("%w: unknown option %s for relay %s", errInvalidRelayConfig, option, url)
+				}
+			}
+			if len(args) > 0 {
+				separator := "?"
+				if strings.Contains(url, "?") {
+					separator = "&"
+				}
+				url += separator + args.Encode()
+			}
+			urls = append(urls, url)
+		default:
+			return nil, fmt.Errorf("%w: %v", errInvalidRelayConfig, entry)
+		}
+	}
+	return urls, nil
+}
+
+// relayOptionValue returns the relay URL query arg of a relay option, list entries (i.e. labels) are separated by
+// spaces
+func relayOptionValue(value any) string {
+	entries, ok := value.([]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	ret := make([]string, len(entries))
+	for i, entry := range entries {
+		ret[i] = fmt.Sprint(entry)
+	}
+	return strings.Join(ret, " ")
+}
+
+// configValues returns the flag values of a config value, one for each list entry
+func configValues(value any) ([]string, error) {
+	entries, ok := value.([]any)
+	if !ok {
+		entries = []any{value}
+	}
+
+	ret := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		switch entry.(type) {
+		case []any, map[string]any:
+			return nil, errNestedConfigValue
+		}
+		ret = append(ret, fmt.Sprint(entry))
+	}
+	return ret, nil
+}
+
+// flagName returns the name of the mev-boost flag with the name or alias
+func flagName(name string) (string, bool) {
+	for _, f := range flags {
+		if names := f.Names(); slices.Contains(names, name) {
+			return names[0], true
+		}
+	}
+	return "", false
+}
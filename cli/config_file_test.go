@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	testRelayA = "https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-a.example.com"
+	testRelayB = "https://0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae@relay-b.example.com"
+)
+
+// freshFlags copies the mev-boost flags, since the flags keep their values after a command run
+func freshFlags() []cli.Flag {
+	ret := make([]cli.Flag, 0, len(flags))
+	for _, f := range flags {
+		switch f := f.(type) {
+		case *cli.BoolFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.DurationFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.FloatFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.IntFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.StringFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.StringSliceFlag:
+			c := *f
+			ret = append(ret, &c)
+		case *cli.UintFlag:
+			c := *f
+			ret = append(ret, &c)
+		default:
+			panic("unexpected flag type")
+		}
+	}
+	return ret
+}
+
+// runWithConfig runs a command with the mev-boost flags and the config file, and returns the parsed command
+func runWithConfig(t *testing.T, name, content string, args ...string) (*cli.Command, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	clear(configFileFlags)
+	var parsed *cli.Command
+	cmd := &cli.Command{
+		Name:   "mev-boost",
+		Flags:  freshFlags(),
+		Before: loadConfigFile,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			parsed = cmd
+			return nil
+		},
+	}
+	err := cmd.Run(context.Background(), append([]string{"mev-boost", "-config", path}, args...))
+	return parsed, err
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", `
+network: holesky
+relays:
+  - `+testRelayA+`
+  - url: `+testRelayB+`
+min-bid: 0.05
+request-timeout-getheader: 500
+loglevel: debug
+webhooks: [http://localhost:8080]
+`)
+		require.NoError(t, err)
+		require.True(t, cmd.Bool(holeskyFlag.Name))
+		require.Equal(t, []string{testRelayA, testRelayB}, cmd.StringSlice(relaysFlag.Name))
+		require.InDelta(t, 0.05, cmd.Float(minBidFlag.Name), 0)
+		require.Equal(t, int64(500), cmd.Int(timeoutGetHeaderFlag.Name))
+		require.Equal(t, "debug", cmd.String(logLevelFlag.Name))
+		require.Equal(t, []string{"http://localhost:8080"}, cmd.StringSlice(webhookFlag.Name))
+	})
+
+	t.Run("Relay options", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", `
+relays:
+  - url: `+testRelayA+`
+    timeout_get_header: 750ms
+    labels: [non-filtering, eu]
+`)
+		require.NoError(t, err)
+		require.Equal(t, []string{testRelayA + "?labels=non-filtering+eu&timeout_get_header=750ms"}, cmd.StringSlice(relaysFlag.Name))
+
+		relays, err := parseRelayURLs(cmd.StringSlice(relaysFlag.Name))
+		require.NoError(t, err)
+		require.Equal(t, 750*time.Millisecond, relays[0].TimeoutGetHeader)
+		require.Equal(t, []string{"non-filtering", "eu"}, relays[0].Labels)
+	})
+
+	t.Run("Relay headers", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", `
+relays:
+  - url: `+testRelayA+`
+    basic_auth: operator:s3cr3t
+    header: ["X-Api-Key: abc", "Authorization: Bearer xyz"]
+`)
+		require.NoError(t, err)
+		relays, err := parseRelayURLs(cmd.StringSlice(relaysFlag.Name))
+		require.NoError(t, err)
+		require.Equal(t, "operator", relays[0].BasicAuth.Username())
+		require.Equal(t, "abc", relays[0].Headers.Get("X-Api-Key"))
+		require.Equal(t, "Bearer xyz", relays[0].Headers.Get("Authorization"))
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.toml", `
+network = "sepolia"
+relay = ["`+testRelayA+`"]
+readyz-relay-max-age = "30s"
+`)
+		require.NoError(t, err)
+		require.True(t, cmd.Bool(sepoliaFlag.Name))
+		require.Equal(t, []string{testRelayA}, cmd.StringSlice(relaysFlag.Name))
+		require.Equal(t, "30s", cmd.Duration(readyRelayMaxAgeFlag.Name).String())
+	})
+
+	t.Run("Flags take precedence", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", `
+relays: [`+testRelayA+`]
+min-bid: 0.05
+`, "-relays", testRelayB, "-min-bid", "0.1")
+		require.NoError(t, err)
+		require.Equal(t, []string{testRelayB}, cmd.StringSlice(relaysFlag.Name))
+		require.InDelta(t, 0.1, cmd.Float(minBidFlag.Name), 0)
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		_, err := runWithConfig(t, "config.yaml", `relay-timeout: 100`)
+		require.ErrorIs(t, err, errUnknownConfigKey)
+
+		_, err = runWithConfig(t, "config.yaml", `network: goerli`)
+		require.ErrorIs(t, err, errUnknownNetwork)
+
+		_, err = runWithConfig(t, "config.yaml", `relays: [{url: `+testRelayA+`, priority: 2}]`)
+		require.ErrorIs(t, err, errInvalidRelayConfig)
+
+		_, err = runWithConfig(t, "config.yaml", `min-bid: [1, [2]]`)
+		require.ErrorIs(t, err, errInvalidConfigValue)
+
+		_, err = runWithConfig(t, "config.json", `{}`)
+		require.ErrorIs(t, err, errUnsupportedConfigFile)
+	})
+}
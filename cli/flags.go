@@ -1,48 +1,176 @@
 package cli
 
-import "github.com/urfave/cli/v3"
+import (
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/urfave/cli/v3"
+)
 
 const (
 	LoggingCategory = "LOGGING AND DEBUGGING"
+	MetricsCategory = "METRICS"
 	GenesisCategory = "GENESIS"
 	RelayCategory   = "RELAYS"
 	GeneralCategory = "GENERAL"
+	NotifyCategory  = "NOTIFICATIONS"
+
+	MockRelayCategory  = "MOCK RELAY"
+	TestRelaysCategory = "TEST RELAYS"
+	BenchCategory      = "BENCH"
+	RelayDataCategory  = "RELAY DATA"
+	ReplayCategory     = "REPLAY"
 )
 
 var flags = []cli.Flag{
 	// general
 	addrFlag,
+	grpcAddrFlag,
 	versionFlag,
+	configFlag,
+	adminTokenFlag,
+	clientAllowlistFlag,
+	clientDenylistFlag,
+	readyMinRelaysFlag,
+	readyRelayMaxAgeFlag,
+	beaconNodeFlag,
+	publishBlockFallbackFlag,
+	shutdownDrainTimeoutFlag,
+	registerValidatorMaxBytesFlag,
+	getPayloadMaxBytesFlag,
+	rateLimitPerIPFlag,
+	rateLimitGlobalFlag,
+	loadSheddingFlag,
+	executionNodeFlag,
 	// logging
 	jsonFlag,
 	debugFlag,
 	logLevelFlag,
 	logServiceFlag,
 	logNoVersionFlag,
+	logFileFlag,
+	logFileMaxSizeFlag,
+	logFileMaxBackupsFlag,
+	logFileMaxAgeFlag,
+	logFileCompressFlag,
+	logFileRotateIntervalFlag,
+	bidAuditLogFlag,
+	bidHistoryDBFlag,
+	sentryDSNFlag,
+	sentryEnvironmentFlag,
+	pprofFlag,
+	pprofAddrFlag,
+	otlpEndpointFlag,
+	otlpServiceNameFlag,
+	otlpSampleRatioFlag,
+	// metrics
+	metricsFlag,
+	metricsAddrFlag,
+	metricsTokenFlag,
+	statsdAddrFlag,
+	statsdPrefixFlag,
+	statsdDogStatsDFlag,
 	// genesis
 	customGenesisForkFlag,
 	customGenesisTimeFlag,
+	networkConfigFlag,
+	fuluForkEpochFlag,
+	legacyForksFlag,
 	mainnetFlag,
 	sepoliaFlag,
 	holeskyFlag,
+	gnosisFlag,
+	chiadoFlag,
 	// relay
 	relaysFlag,
+	relaysURLFlag,
+	relaysURLPubkeyFlag,
+	relaysURLIntervalFlag,
+	relaysDNSFlag,
+	relaysDNSIntervalFlag,
+	relayDNSResolverFlag,
+	relayDNSMaxTTLFlag,
+	relayPrewarmLeadFlag,
+	relayHTTP2Flag,
+	relayMaxIdleConnsFlag,
+	relayIdleConnTimeoutFlag,
+	relayTCPKeepAliveFlag,
 	relayMonitorFlag,
 	minBidFlag,
+	minBidPercentFlag,
+	minBidSlotsFlag,
+	localBlockValueDeltaFlag,
+	maxBidFlag,
+	maxBidMedianMultipleFlag,
+	bidAnomalyActionFlag,
+	gasLimitCheckFlag,
+	payloadCheckFlag,
+	bidPolicyURLFlag,
+	bidPolicyTimeoutFlag,
+	bidPolicyFailClosedFlag,
+	bidScriptFlag,
+	bidPluginFlag,
+	bidTiebreakerFlag,
+	relayLabelPreferenceFlag,
+	relaySignatureCheckFlag,
+	validatorRoutesFlag,
 	relayCheckFlag,
 	timeoutGetHeaderFlag,
+	timeoutGetHeaderMinFlag,
+	timeoutGetHeaderMaxFlag,
 	timeoutGetPayloadFlag,
+	getPayloadFallbackDelayFlag,
+	getPayloadHedgeDelayFlag,
+	getPayloadCacheTTLFlag,
+	getPayloadStrictFlag,
+	revealJournalFileFlag,
 	timeoutRegValFlag,
+	registrationResendIntervalFlag,
+	registrationChunkSizeFlag,
+	registrationChunkRetriesFlag,
+	registrationSpreadWindowFlag,
+	registrationVerifySignaturesFlag,
+	getHeaderMaxMsIntoSlotFlag,
+	getHeaderSoftDeadlineFlag,
+	getHeaderStaggerDelayFlag,
+	backupRelayWindowFlag,
+	getHeaderPollIntervalFlag,
+	getHeaderPollBudgetFlag,
+	getHeaderRetryDelayFlag,
 	maxRetriesFlag,
+	retryBackoffFlag,
+	retryJitterFlag,
+	requestCompressionMinSizeFlag,
+	relayClientCertFlag,
+	relayClientKeyFlag,
+	circuitBreakerFailuresFlag,
+	circuitBreakerCooldownFlag,
+	quarantineFaultsFlag,
+	quarantineEpochsFlag,
+	reputationFileFlag,
+	registrationsFileFlag,
+	payloadDeliveryCheckDelayFlag,
+	// notifications
+	webhookFlag,
+	webhookTimeoutFlag,
+	eventsTokenFlag,
 }
 
 var (
 	// General
-	addrFlag = &cli.StringFlag{
+	addrFlag = &cli.StringSliceFlag{
 		Name:     "addr",
 		Sources:  cli.EnvVars("BOOST_LISTEN_ADDR"),
-		Value:    "localhost:18550",
-		Usage:    "listen-address for mev-boost server",
+		Value:    []string{"localhost:18550"},
+		Usage:    "listen-addresses for mev-boost server - single entry or comma-separated list of host:port or unix:///path/to/socket, with optional ?tls_cert=...&tls_key=...&tls_client_ca=...",
+		Category: GeneralCategory,
+	}
+	grpcAddrFlag = &cli.StringFlag{
+		Name:     "grpc-addr",
+		Sources:  cli.EnvVars("BOOST_GRPC_LISTEN_ADDR"),
+		Usage:    "listen-address for the gRPC builder API, i.e. localhost:18552 (disabled if empty)",
 		Category: GeneralCategory,
 	}
 	versionFlag = &cli.BoolFlag{
@@ -50,6 +178,99 @@ var (
 		Usage:    "print version",
 		Category: GeneralCategory,
 	}
+	configFlag = &cli.StringFlag{
+		Name:     "config",
+		Sources:  cli.EnvVars("CONFIG_FILE"),
+		Usage:    "YAML or TOML config file with flag values, flags and environment variables take precedence",
+		Category: GeneralCategory,
+	}
+	adminTokenFlag = &cli.StringFlag{
+		Name:     "admin-token",
+		Sources:  cli.EnvVars("ADMIN_TOKEN"),
+		Usage:    "enables the admin API (i.e. POST /admin/reload and /admin/relays), authenticated by this bearer token",
+		Category: GeneralCategory,
+	}
+	clientAllowlistFlag = &cli.StringSliceFlag{
+		Name:     "client-allowlist",
+		Sources:  cli.EnvVars("CLIENT_ALLOWLIST"),
+		Usage:    "only serve requests of clients in these networks - single entry or comma-separated list of IPs or CIDRs (i.e. 127.0.0.1,10.0.0.0/8)",
+		Category: GeneralCategory,
+	}
+	clientDenylistFlag = &cli.StringSliceFlag{
+		Name:     "client-denylist",
+		Sources:  cli.EnvVars("CLIENT_DENYLIST"),
+		Usage:    "refuse requests of clients in these networks - single entry or comma-separated list of IPs or CIDRs",
+		Category: GeneralCategory,
+	}
+	readyMinRelaysFlag = &cli.IntFlag{
+		Name:     "readyz-min-relays",
+		Sources:  cli.EnvVars("READYZ_MIN_RELAYS"),
+		Value:    1,
+		Usage:    "number of reachable relays required for /readyz to report ready",
+		Category: GeneralCategory,
+	}
+	readyRelayMaxAgeFlag = &cli.DurationFlag{
+		Name:     "readyz-relay-max-age",
+		Sources:  cli.EnvVars("READYZ_RELAY_MAX_AGE"),
+		Value:    time.Minute,
+		Usage:    "a relay counts as reachable for /readyz if a request succeeded within this duration, otherwise the relays are checked",
+		Category: GeneralCategory,
+	}
+	beaconNodeFlag = &cli.StringFlag{
+		Name:     "beacon-node",
+		Sources:  cli.EnvVars("BEACON_NODE_URL"),
+		Usage:    "beacon node url, used to check that blocks with delivered payloads landed on chain (scheme://host:port)",
+		Category: GeneralCategory,
+	}
+	publishBlockFallbackFlag = &cli.BoolFlag{
+		Name:     "publish-block-fallback",
+		Sources:  cli.EnvVars("PUBLISH_BLOCK_FALLBACK"),
+		Usage:    "publish the block through the beacon node if the payload couldn't be returned to the beacon node which requested it",
+		Category: GeneralCategory,
+	}
+	shutdownDrainTimeoutFlag = &cli.DurationFlag{
+		Name:     "shutdown-drain-timeout",
+		Sources:  cli.EnvVars("SHUTDOWN_DRAIN_TIMEOUT"),
+		Value:    12 * time.Second,
+		Usage:    "on SIGTERM, serve the getPayload requests of the slot in flight for up to this long before exiting",
+		Category: GeneralCategory,
+	}
+	registerValidatorMaxBytesFlag = &cli.IntFlag{
+		Name:     "register-validator-max-bytes",
+		Sources:  cli.EnvVars("REGISTER_VALIDATOR_MAX_BYTES"),
+		Usage:    "refuse registerValidator requests with larger bodies with 413, 0 for the default of 32 MiB [bytes]",
+		Category: GeneralCategory,
+	}
+	getPayloadMaxBytesFlag = &cli.IntFlag{
+		Name:     "get-payload-max-bytes",
+		Sources:  cli.EnvVars("GET_PAYLOAD_MAX_BYTES"),
+		Usage:    "refuse getPayload requests with larger bodies with 413, 0 for the defaults by fork of the blinded block [bytes]",
+		Category: GeneralCategory,
+	}
+	rateLimitPerIPFlag = &cli.FloatFlag{
+		Name:     "rate-limit-per-ip",
+		Sources:  cli.EnvVars("RATE_LIMIT_PER_IP"),
+		Usage:    "refuse builder API requests of a client address over this many per second with 429, getHeader and getPayload are never limited, 0 to disable",
+		Category: GeneralCategory,
+	}
+	rateLimitGlobalFlag = &cli.FloatFlag{
+		Name:     "rate-limit-global",
+		Sources:  cli.EnvVars("RATE_LIMIT_GLOBAL"),
+		Usage:    "refuse builder API requests of all clients over this many per second with 429, getHeader and getPayload are never limited, 0 to disable",
+		Category: GeneralCategory,
+	}
+	loadSheddingFlag = &cli.BoolFlag{
+		Name:     "load-shedding",
+		Sources:  cli.EnvVars("LOAD_SHEDDING"),
+		Usage:    "refuse registerValidator requests with 503 while getHeader or getPayload requests are served",
+		Category: GeneralCategory,
+	}
+	executionNodeFlag = &cli.StringFlag{
+		Name:     "execution-node",
+		Sources:  cli.EnvVars("EXECUTION_NODE_URL"),
+		Usage:    "execution node JSON-RPC url, used to check that fee recipients received the bid values of delivered payloads (scheme://host:port)",
+		Category: GeneralCategory,
+	}
 	// Logging and debugging
 	jsonFlag = &cli.BoolFlag{
 		Name:     "json",
@@ -83,6 +304,140 @@ var (
 		Usage:    "disables adding the version to every log entry",
 		Category: LoggingCategory,
 	}
+	logFileFlag = &cli.StringFlag{
+		Name:     "log-file",
+		Sources:  cli.EnvVars("LOG_FILE"),
+		Usage:    "also write logs to this file, with rotation",
+		Category: LoggingCategory,
+	}
+	logFileMaxSizeFlag = &cli.IntFlag{
+		Name:     "log-file-max-size",
+		Sources:  cli.EnvVars("LOG_FILE_MAX_SIZE_MB"),
+		Value:    100,
+		Usage:    "rotate the log file when it reaches this size [MB]",
+		Category: LoggingCategory,
+	}
+	logFileMaxBackupsFlag = &cli.IntFlag{
+		Name:     "log-file-max-backups",
+		Sources:  cli.EnvVars("LOG_FILE_MAX_BACKUPS"),
+		Value:    10,
+		Usage:    "maximum number of rotated log files to keep (0 keeps all)",
+		Category: LoggingCategory,
+	}
+	logFileMaxAgeFlag = &cli.IntFlag{
+		Name:     "log-file-max-age",
+		Sources:  cli.EnvVars("LOG_FILE_MAX_AGE_DAYS"),
+		Value:    30,
+		Usage:    "maximum number of days to keep rotated log files (0 keeps all)",
+		Category: LoggingCategory,
+	}
+	logFileCompressFlag = &cli.BoolFlag{
+		Name:     "log-file-compress",
+		Sources:  cli.EnvVars("LOG_FILE_COMPRESS"),
+		Usage:    "gzip rotated log files",
+		Category: LoggingCategory,
+	}
+	logFileRotateIntervalFlag = &cli.DurationFlag{
+		Name:     "log-file-rotate-interval",
+		Sources:  cli.EnvVars("LOG_FILE_ROTATE_INTERVAL"),
+		Usage:    "additionally rotate the log file at this interval, i.e. 24h (0 disables time-based rotation)",
+		Category: LoggingCategory,
+	}
+	bidAuditLogFlag = &cli.StringFlag{
+		Name:     "bid-audit-log",
+		Sources:  cli.EnvVars("BID_AUDIT_LOG"),
+		Usage:    "append every received bid as JSON line to this file",
+		Category: LoggingCategory,
+	}
+	bidHistoryDBFlag = &cli.StringFlag{
+		Name:     "bid-history-db",
+		Sources:  cli.EnvVars("BID_HISTORY_DB"),
+		Usage:    "store bids and payload deliveries in this SQLite database, and enable the history API",
+		Category: LoggingCategory,
+	}
+	metricsFlag = &cli.BoolFlag{
+		Name:     "metrics",
+		Sources:  cli.EnvVars("METRICS_ENABLED"),
+		Usage:    "enables a metrics server",
+		Category: MetricsCategory,
+	}
+	metricsAddrFlag = &cli.StringFlag{
+		Name:     "metrics-addr",
+		Sources:  cli.EnvVars("METRICS_ADDR"),
+		Value:    "localhost:18551",
+		Usage:    "listening address for the metrics server",
+		Category: MetricsCategory,
+	}
+	metricsTokenFlag = &cli.StringFlag{
+		Name:     "metrics-token",
+		Sources:  cli.EnvVars("METRICS_TOKEN"),
+		Usage:    "protects the metrics, relay stats, relay scores, bid history, validator registration and verbose status endpoints with this bearer token (or basic auth password)",
+		Category: MetricsCategory,
+	}
+	statsdAddrFlag = &cli.StringFlag{
+		Name:     "statsd-addr",
+		Sources:  cli.EnvVars("STATSD_ADDR"),
+		Usage:    "also send the relay metrics to this StatsD server (host:port)",
+		Category: MetricsCategory,
+	}
+	statsdPrefixFlag = &cli.StringFlag{
+		Name:     "statsd-prefix",
+		Sources:  cli.EnvVars("STATSD_PREFIX"),
+		Value:    "mevboost",
+		Usage:    "prefix of the StatsD metric names",
+		Category: MetricsCategory,
+	}
+	statsdDogStatsDFlag = &cli.BoolFlag{
+		Name:     "statsd-dogstatsd",
+		Sources:  cli.EnvVars("STATSD_DOGSTATSD"),
+		Usage:    "send DogStatsD tags instead of appending the tag values to the StatsD metric names",
+		Category: MetricsCategory,
+	}
+	sentryDSNFlag = &cli.StringFlag{
+		Name:     "sentry-dsn",
+		Sources:  cli.EnvVars("SENTRY_DSN"),
+		Usage:    "report panics and high-severity auction failures to the Sentry project of this DSN",
+		Category: LoggingCategory,
+	}
+	sentryEnvironmentFlag = &cli.StringFlag{
+		Name:     "sentry-environment",
+		Sources:  cli.EnvVars("SENTRY_ENVIRONMENT"),
+		Usage:    "environment reported to Sentry, i.e. the network or validator cluster",
+		Category: LoggingCategory,
+	}
+	pprofFlag = &cli.BoolFlag{
+		Name:     "debug-pprof",
+		Sources:  cli.EnvVars("DEBUG_PPROF"),
+		Usage:    "enables a pprof server for capturing CPU, heap and goroutine profiles",
+		Category: LoggingCategory,
+	}
+	pprofAddrFlag = &cli.StringFlag{
+		Name:     "debug-pprof-addr",
+		Sources:  cli.EnvVars("DEBUG_PPROF_ADDR"),
+		Value:    "localhost:6060",
+		Usage:    "listening address for the pprof server, must be a loopback address",
+		Category: LoggingCategory,
+	}
+	otlpEndpointFlag = &cli.StringFlag{
+		Name:     "otlp-endpoint",
+		Sources:  cli.EnvVars("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		Usage:    "export OpenTelemetry traces to this OTLP/HTTP endpoint (e.g. http://localhost:4318/v1/traces)",
+		Category: LoggingCategory,
+	}
+	otlpServiceNameFlag = &cli.StringFlag{
+		Name:     "otlp-service-name",
+		Sources:  cli.EnvVars("OTEL_SERVICE_NAME"),
+		Value:    "mev-boost",
+		Usage:    "service name reported in exported traces",
+		Category: LoggingCategory,
+	}
+	otlpSampleRatioFlag = &cli.FloatFlag{
+		Name:     "otlp-sample-ratio",
+		Sources:  cli.EnvVars("OTEL_TRACES_SAMPLE_RATIO"),
+		Value:    1.0,
+		Usage:    "fraction of requests to trace, between 0 and 1",
+		Category: LoggingCategory,
+	}
 	// Genesis Flags
 	customGenesisForkFlag = &cli.StringFlag{
 		Name:     "genesis-fork-version",
@@ -96,6 +451,24 @@ var (
 		Usage:    "use a custom genesis timestamp (unix seconds)",
 		Category: GenesisCategory,
 	}
+	networkConfigFlag = &cli.StringFlag{
+		Name:     "network-config",
+		Sources:  cli.EnvVars("NETWORK_CONFIG_FILE"),
+		Usage:    "path to a consensus layer network config file (config.yaml), i.e. of a devnet, for the genesis fork version, genesis time and slot duration",
+		Category: GenesisCategory,
+	}
+	fuluForkEpochFlag = &cli.UintFlag{
+		Name:     "fulu-fork-epoch",
+		Sources:  cli.EnvVars("FULU_FORK_EPOCH"),
+		Usage:    "use a custom Fulu fork epoch, from which on Electra blinded blocks are Fulu blocks (default: the epoch of the network or network config)",
+		Category: GenesisCategory,
+	}
+	legacyForksFlag = &cli.BoolFlag{
+		Name:     "legacy-forks",
+		Sources:  cli.EnvVars("LEGACY_FORKS"),
+		Usage:    "accept Bellatrix and Capella bids and blinded blocks, for devnets and interop tests which still run pre-Deneb forks",
+		Category: GenesisCategory,
+	}
 	mainnetFlag = &cli.BoolFlag{
 		Name:     "mainnet",
 		Sources:  cli.EnvVars("MAINNET"),
@@ -115,6 +488,18 @@ var (
 		Usage:    "use Holesky",
 		Category: GenesisCategory,
 	}
+	gnosisFlag = &cli.BoolFlag{
+		Name:     "gnosis",
+		Sources:  cli.EnvVars("GNOSIS"),
+		Usage:    "use Gnosis Chain",
+		Category: GenesisCategory,
+	}
+	chiadoFlag = &cli.BoolFlag{
+		Name:     "chiado",
+		Sources:  cli.EnvVars("CHIADO"),
+		Usage:    "use Chiado (Gnosis Chain testnet)",
+		Category: GenesisCategory,
+	}
 	// Relay
 	relaysFlag = &cli.StringSliceFlag{
 		Name:     "relay",
@@ -123,6 +508,86 @@ var (
 		Usage:    "relay urls - single entry or comma-separated list (scheme://pubkey@host)",
 		Category: RelayCategory,
 	}
+	relaysURLFlag = &cli.StringFlag{
+		Name:     "relays-url",
+		Sources:  cli.EnvVars("RELAYS_URL"),
+		Usage:    "url of a signed relay list, which is fetched periodically and used in addition to the relays",
+		Category: RelayCategory,
+	}
+	relaysURLPubkeyFlag = &cli.StringFlag{
+		Name:     "relays-url-pubkey",
+		Sources:  cli.EnvVars("RELAYS_URL_PUBKEY"),
+		Usage:    "hex-encoded ed25519 public key the relay list is signed with",
+		Category: RelayCategory,
+	}
+	relaysURLIntervalFlag = &cli.DurationFlag{
+		Name:     "relays-url-interval",
+		Sources:  cli.EnvVars("RELAYS_URL_INTERVAL"),
+		Value:    time.Minute,
+		Usage:    "how often the relay list is fetched",
+		Category: RelayCategory,
+	}
+	relaysDNSFlag = &cli.StringSliceFlag{
+		Name:     "relays-dns",
+		Sources:  cli.EnvVars("RELAYS_DNS"),
+		Usage:    "domains whose TXT and SRV records are resolved periodically for relays, used in addition to the relays",
+		Category: RelayCategory,
+	}
+	relaysDNSIntervalFlag = &cli.DurationFlag{
+		Name:     "relays-dns-interval",
+		Sources:  cli.EnvVars("RELAYS_DNS_INTERVAL"),
+		Value:    time.Minute,
+		Usage:    "how often the relay DNS records are resolved",
+		Category: RelayCategory,
+	}
+	relayDNSResolverFlag = &cli.StringFlag{
+		Name:     "relay-dns-resolver",
+		Sources:  cli.EnvVars("RELAY_DNS_RESOLVER"),
+		Usage:    "DNS server resolving the relay host names and the relay DNS records, i.e. 1.1.1.1:53, the system resolver if unset",
+		Category: RelayCategory,
+	}
+	relayDNSMaxTTLFlag = &cli.DurationFlag{
+		Name:     "relay-dns-max-ttl",
+		Sources:  cli.EnvVars("RELAY_DNS_MAX_TTL"),
+		Value:    30 * time.Second,
+		Usage:    "re-resolve the relay host names after the TTL of their records, at most after this long",
+		Category: RelayCategory,
+	}
+	relayPrewarmLeadFlag = &cli.DurationFlag{
+		Name:     "relay-prewarm-lead",
+		Sources:  cli.EnvVars("RELAY_PREWARM_LEAD"),
+		Value:    time.Second,
+		Usage:    "establish or refresh the relay connections this long before each slot, so getHeader doesn't wait for handshakes, 0 to disable",
+		Category: RelayCategory,
+	}
+	relayHTTP2Flag = &cli.BoolFlag{
+		Name:     "relay-http2",
+		Sources:  cli.EnvVars("RELAY_HTTP2"),
+		Value:    true,
+		Usage:    "use HTTP/2 for relays supporting it, over TLS",
+		Category: RelayCategory,
+	}
+	relayMaxIdleConnsFlag = &cli.IntFlag{
+		Name:     "relay-max-idle-conns",
+		Sources:  cli.EnvVars("RELAY_MAX_IDLE_CONNS"),
+		Value:    2,
+		Usage:    "idle connections kept open per relay for later requests",
+		Category: RelayCategory,
+	}
+	relayIdleConnTimeoutFlag = &cli.DurationFlag{
+		Name:     "relay-idle-conn-timeout",
+		Sources:  cli.EnvVars("RELAY_IDLE_CONN_TIMEOUT"),
+		Value:    90 * time.Second,
+		Usage:    "close idle relay connections after this long",
+		Category: RelayCategory,
+	}
+	relayTCPKeepAliveFlag = &cli.DurationFlag{
+		Name:     "relay-tcp-keepalive",
+		Sources:  cli.EnvVars("RELAY_TCP_KEEPALIVE"),
+		Value:    30 * time.Second,
+		Usage:    "TCP keepalive period of the relay connections, negative to disable",
+		Category: RelayCategory,
+	}
 	relayMonitorFlag = &cli.StringSliceFlag{
 		Name:     "relay-monitors",
 		Aliases:  []string{"relay-monitor"},
@@ -136,6 +601,115 @@ var (
 		Usage:    "minimum bid to accept from a relay [eth]",
 		Category: RelayCategory,
 	}
+	minBidPercentFlag = &cli.FloatFlag{
+		Name:     "min-bid-percent",
+		Sources:  cli.EnvVars("MIN_BID_PERCENT"),
+		Usage:    "raises the minimum bid to this percentage of the median bid value of the last -min-bid-slots slots [%]",
+		Category: RelayCategory,
+	}
+	minBidSlotsFlag = &cli.IntFlag{
+		Name:     "min-bid-slots",
+		Sources:  cli.EnvVars("MIN_BID_SLOTS"),
+		Value:    100,
+		Usage:    "number of recent slots for the median bid value of -min-bid-percent",
+		Category: RelayCategory,
+	}
+	localBlockValueDeltaFlag = &cli.FloatFlag{
+		Name:     "local-block-value-delta",
+		Sources:  cli.EnvVars("LOCAL_BLOCK_VALUE_DELTA_ETH"),
+		Usage:    "if the beacon node sends the value of its local block, only return a bid worth at least this much more [eth]",
+		Category: RelayCategory,
+	}
+	maxBidFlag = &cli.FloatFlag{
+		Name:     "max-bid",
+		Sources:  cli.EnvVars("MAX_BID_ETH"),
+		Usage:    "bids above this value are implausible, see -bid-anomaly-action (0 to disable) [eth]",
+		Category: RelayCategory,
+	}
+	maxBidMedianMultipleFlag = &cli.FloatFlag{
+		Name:     "max-bid-median-multiple",
+		Sources:  cli.EnvVars("MAX_BID_MEDIAN_MULTIPLE"),
+		Usage:    "bids above this multiple of the median bid value of the last -min-bid-slots slots are implausible (0 to disable)",
+		Category: RelayCategory,
+	}
+	bidAnomalyActionFlag = &cli.StringFlag{
+		Name:     "bid-anomaly-action",
+		Sources:  cli.EnvVars("BID_ANOMALY_ACTION"),
+		Value:    server.BidAnomalyReject,
+		Usage:    "what happens to implausibly high bids: " + strings.Join(server.BidAnomalyActions, ", "),
+		Category: RelayCategory,
+	}
+	gasLimitCheckFlag = &cli.StringFlag{
+		Name:     "gas-limit-check",
+		Sources:  cli.EnvVars("GAS_LIMIT_CHECK"),
+		Value:    server.GasLimitCheckWarn,
+		Usage:    "what happens to bids not matching the registered gas limit of the validator: " + strings.Join(server.GasLimitChecks, ", "),
+		Category: RelayCategory,
+	}
+	payloadCheckFlag = &cli.StringFlag{
+		Name:     "payload-check",
+		Sources:  cli.EnvVars("PAYLOAD_CHECK"),
+		Value:    server.PayloadCheckWarn,
+		Usage:    "what happens to payloads whose block hash, transactions or withdrawals root doesn't match the signed blinded block: " + strings.Join(server.PayloadChecks, ", "),
+		Category: RelayCategory,
+	}
+	bidPolicyURLFlag = &cli.StringFlag{
+		Name:     "bid-policy-url",
+		Sources:  cli.EnvVars("BID_POLICY_URL"),
+		Usage:    "policy service which receives the candidate bids of getHeader and may veto or reorder them",
+		Category: RelayCategory,
+	}
+	bidPolicyTimeoutFlag = &cli.IntFlag{
+		Name:     "bid-policy-timeout",
+		Sources:  cli.EnvVars("BID_POLICY_TIMEOUT_MS"),
+		Usage:    "time budget of the bid policy service, the best bid is used if it doesn't answer in time [ms]",
+		Value:    100,
+		Category: RelayCategory,
+	}
+	bidPolicyFailClosedFlag = &cli.BoolFlag{
+		Name:     "bid-policy-fail-closed",
+		Sources:  cli.EnvVars("BID_POLICY_FAIL_CLOSED"),
+		Usage:    "return no bid if the bid policy service fails or doesn't answer in time",
+		Category: RelayCategory,
+	}
+	bidScriptFlag = &cli.StringFlag{
+		Name:     "bid-script",
+		Sources:  cli.EnvVars("BID_SCRIPT"),
+		Usage:    "Starlark script whose score_bid(bid) function accepts, rejects or scores each bid",
+		Category: RelayCategory,
+	}
+	bidPluginFlag = &cli.StringFlag{
+		Name:     "bid-plugin",
+		Sources:  cli.EnvVars("BID_PLUGIN"),
+		Usage:    "WASM module implementing the bid policy ABI (filter, score, tiebreak), reloaded with the config file",
+		Category: RelayCategory,
+	}
+	bidTiebreakerFlag = &cli.StringFlag{
+		Name:     "bid-tiebreaker",
+		Sources:  cli.EnvVars("BID_TIEBREAKER"),
+		Value:    server.TiebreakerBlockHash,
+		Usage:    "decides between bids of the same value: " + strings.Join(server.Tiebreakers, ", "),
+		Category: RelayCategory,
+	}
+	relayLabelPreferenceFlag = &cli.StringSliceFlag{
+		Name:     "relay-label-preference",
+		Sources:  cli.EnvVars("RELAY_LABEL_PREFERENCES"),
+		Usage:    "prefer the bids of relays with a label unless other bids are more than the percentage higher - label:percent, single entry or comma-separated list (i.e. non-filtering:5)",
+		Category: RelayCategory,
+	}
+	relaySignatureCheckFlag = &cli.StringFlag{
+		Name:     "relay-signature-check",
+		Sources:  cli.EnvVars("RELAY_SIGNATURE_CHECK"),
+		Value:    types.SignatureCheckVerify,
+		Usage:    "signature check of relay bids, unless set per relay with the signature_check option: " + strings.Join(types.SignatureChecks, ", "),
+		Category: RelayCategory,
+	}
+	validatorRoutesFlag = &cli.StringFlag{
+		Name:     "validator-routes",
+		Sources:  cli.EnvVars("VALIDATOR_ROUTES_FILE"),
+		Usage:    "file mapping validator pubkeys to the relays and min-bid used for them (.yaml, .yml or .toml)",
+		Category: RelayCategory,
+	}
 	relayCheckFlag = &cli.BoolFlag{
 		Name:     "relay-check",
 		Sources:  cli.EnvVars("RELAY_STARTUP_CHECK"),
@@ -150,6 +724,19 @@ var (
 		Value:    950,
 		Category: RelayCategory,
 	}
+	timeoutGetHeaderMinFlag = &cli.IntFlag{
+		Name:     "request-timeout-getheader-min",
+		Sources:  cli.EnvVars("RELAY_TIMEOUT_MS_GETHEADER_MIN"),
+		Usage:    "lower bound of the getHeader timeouts adapted to the latency of the relays [ms]",
+		Value:    200,
+		Category: RelayCategory,
+	}
+	timeoutGetHeaderMaxFlag = &cli.IntFlag{
+		Name:     "request-timeout-getheader-max",
+		Sources:  cli.EnvVars("RELAY_TIMEOUT_MS_GETHEADER_MAX"),
+		Usage:    "upper bound of the getHeader timeouts adapted to the latency of the relays, 0 keeps the fixed timeout [ms]",
+		Category: RelayCategory,
+	}
 	timeoutGetPayloadFlag = &cli.IntFlag{
 		Name:     "request-timeout-getpayload",
 		Sources:  cli.EnvVars("RELAY_TIMEOUT_MS_GETPAYLOAD"),
@@ -157,6 +744,37 @@ var (
 		Value:    4000,
 		Category: RelayCategory,
 	}
+	getPayloadFallbackDelayFlag = &cli.DurationFlag{
+		Name:     "getpayload-fallback-delay",
+		Sources:  cli.EnvVars("GETPAYLOAD_FALLBACK_DELAY"),
+		Value:    time.Second,
+		Usage:    "send the signed block to the relays of the bid first, and to the other relays after this delay (0 sends it to all relays at once)",
+		Category: RelayCategory,
+	}
+	getPayloadHedgeDelayFlag = &cli.DurationFlag{
+		Name:     "getpayload-hedge-delay",
+		Sources:  cli.EnvVars("GETPAYLOAD_HEDGE_DELAY"),
+		Usage:    "send the signed block to one relay at a time, the fastest relay of the bid first, and to the next relay after this delay or once the previous one failed, i.e. 200ms (0 sends it to all relays at once)",
+		Category: RelayCategory,
+	}
+	getPayloadCacheTTLFlag = &cli.DurationFlag{
+		Name:     "getpayload-cache-ttl",
+		Sources:  cli.EnvVars("GETPAYLOAD_CACHE_TTL"),
+		Usage:    "answer retried getPayload requests for the same block with the payload delivered within this time, i.e. 12s (0 asks the relays again)",
+		Category: RelayCategory,
+	}
+	getPayloadStrictFlag = &cli.BoolFlag{
+		Name:     "getpayload-strict",
+		Sources:  cli.EnvVars("GETPAYLOAD_STRICT"),
+		Usage:    "refuse getPayload requests for blocks whose header wasn't returned by getHeader of this instance",
+		Category: RelayCategory,
+	}
+	revealJournalFileFlag = &cli.StringFlag{
+		Name:     "reveal-journal-file",
+		Sources:  cli.EnvVars("REVEAL_JOURNAL_FILE"),
+		Usage:    "record the block revealed for each slot in this file, and refuse to send a second block of the same slot to the relays",
+		Category: RelayCategory,
+	}
 	timeoutRegValFlag = &cli.IntFlag{
 		Name:     "request-timeout-regval",
 		Sources:  cli.EnvVars("RELAY_TIMEOUT_MS_REGVAL"),
@@ -164,6 +782,80 @@ var (
 		Value:    3000,
 		Category: RelayCategory,
 	}
+	registrationResendIntervalFlag = &cli.DurationFlag{
+		Name:     "registration-resend-interval",
+		Sources:  cli.EnvVars("REGISTRATION_RESEND_INTERVAL"),
+		Usage:    "forward unchanged validator registrations to a relay only this often, i.e. 1h (0 forwards all registrations)",
+		Category: RelayCategory,
+	}
+	registrationChunkSizeFlag = &cli.IntFlag{
+		Name:     "registration-chunk-size",
+		Sources:  cli.EnvVars("REGISTRATION_CHUNK_SIZE"),
+		Usage:    "split the validator registrations to a relay into concurrent requests of at most this many registrations (0 sends one request)",
+		Category: RelayCategory,
+	}
+	registrationChunkRetriesFlag = &cli.IntFlag{
+		Name:     "registration-chunk-retries",
+		Sources:  cli.EnvVars("REGISTRATION_CHUNK_RETRIES"),
+		Usage:    "retry failed requests with validator registrations to a relay this many times",
+		Category: RelayCategory,
+	}
+	registrationSpreadWindowFlag = &cli.DurationFlag{
+		Name:     "registration-spread-window",
+		Sources:  cli.EnvVars("REGISTRATION_SPREAD_WINDOW"),
+		Usage:    "buffer the validator registrations and forward each to the relays at a random time within this window, i.e. 6m24s (0 forwards at once)",
+		Category: RelayCategory,
+	}
+	registrationVerifySignaturesFlag = &cli.BoolFlag{
+		Name:     "registration-verify-signatures",
+		Sources:  cli.EnvVars("REGISTRATION_VERIFY_SIGNATURES"),
+		Usage:    "verify the signatures of the validator registrations, rejecting batches with invalid signatures instead of forwarding them",
+		Category: RelayCategory,
+	}
+	getHeaderMaxMsIntoSlotFlag = &cli.IntFlag{
+		Name:     "getheader-max-ms-into-slot",
+		Sources:  cli.EnvVars("GETHEADER_MAX_MS_INTO_SLOT"),
+		Usage:    "respond with no bid to getHeader requests arriving later into the slot, 0 to disable [ms]",
+		Category: RelayCategory,
+	}
+	getHeaderSoftDeadlineFlag = &cli.IntFlag{
+		Name:     "getheader-soft-deadline-ms-into-slot",
+		Sources:  cli.EnvVars("GETHEADER_SOFT_DEADLINE_MS_INTO_SLOT"),
+		Usage:    "return the best bid so far at this time into the slot without waiting for the remaining relays, 0 to disable [ms]",
+		Category: RelayCategory,
+	}
+	getHeaderStaggerDelayFlag = &cli.IntFlag{
+		Name:     "getheader-stagger-delay-ms",
+		Sources:  cli.EnvVars("GETHEADER_STAGGER_DELAY_MS"),
+		Usage:    "ask the relays for bids from the fastest to the slowest, each this much later than the previous one, 0 asks all relays at once [ms]",
+		Category: RelayCategory,
+	}
+	backupRelayWindowFlag = &cli.IntFlag{
+		Name:     "relay-backup-window-ms",
+		Sources:  cli.EnvVars("RELAY_BACKUP_WINDOW_MS"),
+		Usage:    "ask the backup relays for bids if no other relay has a usable bid after this time, 0 waits for all other relays [ms]",
+		Value:    300,
+		Category: RelayCategory,
+	}
+	getHeaderPollIntervalFlag = &cli.IntFlag{
+		Name:     "getheader-poll-interval-ms",
+		Sources:  cli.EnvVars("GETHEADER_POLL_INTERVAL_MS"),
+		Usage:    "poll the relays for newer bids this often until the poll budget is over, for relays with bid cancellations, 0 asks the relays once [ms]",
+		Category: RelayCategory,
+	}
+	getHeaderPollBudgetFlag = &cli.IntFlag{
+		Name:     "getheader-poll-budget-ms",
+		Sources:  cli.EnvVars("GETHEADER_POLL_BUDGET_MS"),
+		Usage:    "how long after the start of a getHeader request the relays are polled for newer bids [ms]",
+		Value:    500,
+		Category: RelayCategory,
+	}
+	getHeaderRetryDelayFlag = &cli.IntFlag{
+		Name:     "getheader-retry-delay-ms",
+		Sources:  cli.EnvVars("GETHEADER_RETRY_DELAY_MS"),
+		Usage:    "ask the relays once more after this delay if none of them had a usable bid, before the getHeader deadlines, 0 to disable [ms]",
+		Category: RelayCategory,
+	}
 	maxRetriesFlag = &cli.IntFlag{
 		Name:     "request-max-retries",
 		Sources:  cli.EnvVars("REQUEST_MAX_RETRIES"),
@@ -171,4 +863,100 @@ var (
 		Value:    5,
 		Category: RelayCategory,
 	}
+	retryBackoffFlag = &cli.DurationFlag{
+		Name:     "request-retry-backoff",
+		Sources:  cli.EnvVars("REQUEST_RETRY_BACKOFF"),
+		Usage:    "delay between the retries of a relay request",
+		Value:    100 * time.Millisecond,
+		Category: RelayCategory,
+	}
+	retryJitterFlag = &cli.DurationFlag{
+		Name:     "request-retry-jitter",
+		Sources:  cli.EnvVars("REQUEST_RETRY_JITTER"),
+		Usage:    "random extra delay up to this between the retries of a relay request, i.e. 50ms",
+		Category: RelayCategory,
+	}
+	requestCompressionMinSizeFlag = &cli.IntFlag{
+		Name:     "request-compression-min-size",
+		Sources:  cli.EnvVars("REQUEST_COMPRESSION_MIN_SIZE"),
+		Usage:    "gzip getPayload and registerValidator request bodies to relays of at least this size, 0 to disable [bytes]",
+		Category: RelayCategory,
+	}
+	relayClientCertFlag = &cli.StringFlag{
+		Name:     "relay-client-cert",
+		Sources:  cli.EnvVars("RELAY_CLIENT_CERT"),
+		Usage:    "TLS client certificate file presented to relays gating access by mutual TLS",
+		Category: RelayCategory,
+	}
+	relayClientKeyFlag = &cli.StringFlag{
+		Name:     "relay-client-key",
+		Sources:  cli.EnvVars("RELAY_CLIENT_KEY"),
+		Usage:    "key file of the TLS client certificate presented to relays",
+		Category: RelayCategory,
+	}
+	circuitBreakerFailuresFlag = &cli.IntFlag{
+		Name:     "relay-circuit-breaker-failures",
+		Sources:  cli.EnvVars("RELAY_CIRCUIT_BREAKER_FAILURES"),
+		Usage:    "stop querying a relay for getHeader after this many consecutive failed requests, until the cool-down is over (0 to disable)",
+		Category: RelayCategory,
+	}
+	circuitBreakerCooldownFlag = &cli.DurationFlag{
+		Name:     "relay-circuit-breaker-cooldown",
+		Sources:  cli.EnvVars("RELAY_CIRCUIT_BREAKER_COOLDOWN"),
+		Value:    time.Minute,
+		Usage:    "how long a relay isn't queried after tripping the circuit breaker, before a single probe request",
+		Category: RelayCategory,
+	}
+	quarantineFaultsFlag = &cli.IntFlag{
+		Name:     "relay-quarantine-faults",
+		Sources:  cli.EnvVars("RELAY_QUARANTINE_FAULTS"),
+		Usage:    "quarantine a relay after this many consecutive bids or payloads with bad signatures, wrong parent hashes or invalid blobs (0 to disable)",
+		Category: RelayCategory,
+	}
+	quarantineEpochsFlag = &cli.IntFlag{
+		Name:     "relay-quarantine-epochs",
+		Sources:  cli.EnvVars("RELAY_QUARANTINE_EPOCHS"),
+		Usage:    "how many epochs a quarantined relay isn't asked for bids",
+		Value:    10,
+		Category: RelayCategory,
+	}
+	reputationFileFlag = &cli.StringFlag{
+		Name:     "relay-reputation-file",
+		Sources:  cli.EnvVars("RELAY_REPUTATION_FILE"),
+		Usage:    "keep the relay statistics, scores, circuit breakers and latencies across restarts in this file",
+		Category: RelayCategory,
+	}
+	registrationsFileFlag = &cli.StringFlag{
+		Name:     "registrations-file",
+		Sources:  cli.EnvVars("REGISTRATIONS_FILE"),
+		Usage:    "keep the latest validator registrations across restarts in this file, replayed to added and recovered relays",
+		Category: RelayCategory,
+	}
+	payloadDeliveryCheckDelayFlag = &cli.DurationFlag{
+		Name:     "payload-delivery-check-delay",
+		Sources:  cli.EnvVars("PAYLOAD_DELIVERY_CHECK_DELAY"),
+		Usage:    "confirm payload deliveries with the relay data API, queried after this delay, i.e. 12s (0 disables the check)",
+		Category: RelayCategory,
+	}
+	// Notifications
+	webhookFlag = &cli.StringSliceFlag{
+		Name:     "webhook",
+		Aliases:  []string{"webhooks"},
+		Sources:  cli.EnvVars("WEBHOOKS"),
+		Usage:    "webhook urls which receive auction events (bid selected, no bids, payload requested, payload delivered, getPayload failed) - single entry or comma-separated list",
+		Category: NotifyCategory,
+	}
+	webhookTimeoutFlag = &cli.IntFlag{
+		Name:     "webhook-timeout",
+		Sources:  cli.EnvVars("WEBHOOK_TIMEOUT_MS"),
+		Usage:    "timeout for webhook requests [ms]",
+		Value:    2000,
+		Category: NotifyCategory,
+	}
+	eventsTokenFlag = &cli.StringFlag{
+		Name:     "events-token",
+		Sources:  cli.EnvVars("EVENTS_TOKEN"),
+		Usage:    "enables the /events Server-Sent Events stream and /events/bids WebSocket bid stream, authenticated by this bearer token",
+		Category: NotifyCategory,
+	}
 )
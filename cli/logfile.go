@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// setupLogFile returns the log output: stdout, and additionally the rotated log file if --log-file is set
+func setupLogFile(cmd *cli.Command) io.Writer {
+	if !cmd.IsSet(logFileFlag.Name) {
+		return os.Stdout
+	}
+
+	logFile := &lumberjack.Logger{
+		Filename:   cmd.String(logFileFlag.Name),
+		MaxSize:    int(cmd.Int(logFileMaxSizeFlag.Name)),
+		MaxBackups: int(cmd.Int(logFileMaxBackupsFlag.Name)),
+		MaxAge:     int(cmd.Int(logFileMaxAgeFlag.Name)),
+		Compress:   cmd.Bool(logFileCompressFlag.Name),
+	}
+
+	// Size-based rotation is done by lumberjack, time-based rotation on top of it
+	if interval := cmd.Duration(logFileRotateIntervalFlag.Name); interval > 0 {
+		go func() {
+			for range time.Tick(interval) {
+				if err := logFile.Rotate(); err != nil {
+					log.WithError(err).Error("failed rotating log file")
+				}
+			}
+		}()
+	}
+	return io.MultiWriter(os.Stdout, logFile)
+}
@@ -2,17 +2,23 @@ package cli
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/flashbots/go-boost-utils/types"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/mev-boost/common"
 	"github.com/flashbots/mev-boost/config"
 	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
 )
@@ -22,11 +28,19 @@ const (
 	genesisForkVersionSepolia = "0x90000069"
 	genesisForkVersionGoerli  = "0x00001020"
 	genesisForkVersionHolesky = "0x01017000"
+	genesisForkVersionGnosis  = "0x00000064"
+	genesisForkVersionChiado  = "0x0000006f"
 
 	genesisTimeMainnet = 1606824023
 	genesisTimeSepolia = 1655733600
 	genesisTimeGoerli  = 1614588812
 	genesisTimeHolesky = 1695902400
+	genesisTimeGnosis  = 1638993340
+	genesisTimeChiado  = 1665396300
+
+	fuluForkEpochMainnet = 411392
+	fuluForkEpochSepolia = 272640
+	fuluForkEpochHolesky = 165120
 )
 
 var (
@@ -34,6 +48,11 @@ var (
 	errInvalidLoglevel = errors.New("invalid loglevel")
 	errNegativeBid     = errors.New("please specify a non-negative minimum bid")
 	errLargeMinBid     = errors.New("minimum bid is too large, please ensure min-bid is denominated in Ethers")
+	errMinBidPercent   = errors.New("please specify a min-bid-percent between 0 and 100")
+	errNegativeMaxBid  = errors.New("please specify a non-negative max-bid and max-bid-median-multiple")
+	errLabelPreference = errors.New("please specify relay label preferences as label:percent")
+	errInvalidPubkey   = errors.New("invalid relay list public key, expected a hex-encoded ed25519 public key")
+	errNoListenAddr    = errors.New("please specify at least one listen address")
 
 	log = logrus.NewEntry(logrus.New())
 )
@@ -42,8 +61,16 @@ func Main() {
 	cmd := &cli.Command{
 		Name:   "mev-boost",
 		Usage:  "mev-boost implementation, see help for more info",
+		Before: loadConfigFile,
 		Action: start,
 		Flags:  flags,
+		Commands: []*cli.Command{
+			mockRelayCommand,
+			testRelaysCommand,
+			benchCommand,
+			relayDataCommand,
+			replayCommand,
+		},
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
@@ -52,7 +79,7 @@ func Main() {
 }
 
 // start starts the mev-boost cli
-func start(_ context.Context, cmd *cli.Command) error {
+func start(ctx context.Context, cmd *cli.Command) error {
 	// Only print the version if the flag is set
 	if cmd.IsSet(versionFlag.Name) {
 		fmt.Fprintf(cmd.Writer, "mev-boost %s\n", config.Version)
@@ -64,37 +91,306 @@ func start(_ context.Context, cmd *cli.Command) error {
 		log.WithError(err).Fatal("failed setting up logging")
 	}
 
+	shutdownTracing, err := setupTracing(ctx, cmd)
+	if err != nil {
+		log.WithError(err).Fatal("failed setting up tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.WithError(err).Warn("failed shutting down tracing")
+		}
+	}()
+
 	var (
-		genesisForkVersion, genesisTime      = setupGenesis(cmd)
-		relays, monitors, minBid, relayCheck = setupRelays(cmd)
-		listenAddr                           = cmd.String(addrFlag.Name)
+		genesisForkVersion, genesisTime, slotTimeSec = setupGenesis(cmd)
+		relays, monitors, minBid, relayCheck         = setupRelays(cmd)
+		listenAddrs                                  = splitList(cmd.StringSlice(addrFlag.Name))
 	)
+	if len(listenAddrs) == 0 {
+		log.WithError(errNoListenAddr).Fatal("invalid addr")
+	}
 
 	opts := server.BoostServiceOpts{
-		Log:                      log,
-		ListenAddr:               listenAddr,
-		Relays:                   relays,
-		RelayMonitors:            monitors,
-		GenesisForkVersionHex:    genesisForkVersion,
-		GenesisTime:              genesisTime,
-		RelayCheck:               relayCheck,
-		RelayMinBid:              minBid,
-		RequestTimeoutGetHeader:  time.Duration(cmd.Int(timeoutGetHeaderFlag.Name)) * time.Millisecond,
-		RequestTimeoutGetPayload: time.Duration(cmd.Int(timeoutGetPayloadFlag.Name)) * time.Millisecond,
-		RequestTimeoutRegVal:     time.Duration(cmd.Int(timeoutRegValFlag.Name)) * time.Millisecond,
-		RequestMaxRetries:        int(cmd.Int(maxRetriesFlag.Name)),
-	}
-	service, err := server.NewBoostService(opts)
+		Log:                       log,
+		ListenAddr:                listenAddrs[0],
+		AdditionalListenAddrs:     listenAddrs[1:],
+		GRPCAddr:                  cmd.String(grpcAddrFlag.Name),
+		MetricsAddr:               cmd.String(metricsAddrFlag.Name),
+		MetricsToken:              cmd.String(metricsTokenFlag.Name),
+		PprofAddr:                 cmd.String(pprofAddrFlag.Name),
+		Relays:                    relays,
+		RelayMonitors:             monitors,
+		GenesisForkVersionHex:     genesisForkVersion,
+		GenesisTime:               genesisTime,
+		SlotTimeSec:               slotTimeSec,
+		FuluForkEpoch:             setupFuluForkEpoch(cmd),
+		LegacyForks:               cmd.Bool(legacyForksFlag.Name),
+		RelayCheck:                relayCheck,
+		RelayMinBid:               minBid,
+		RelayMinBidPercent:        setupMinBidPercent(cmd),
+		RelayMinBidSlots:          int(cmd.Int(minBidSlotsFlag.Name)),
+		LocalBlockValueDelta:      setupLocalBlockValueDelta(cmd),
+		MaxBid:                    setupMaxBid(cmd),
+		MaxBidMedianMultiple:      cmd.Float(maxBidMedianMultipleFlag.Name),
+		BidAnomalyAction:          cmd.String(bidAnomalyActionFlag.Name),
+		GasLimitCheck:             cmd.String(gasLimitCheckFlag.Name),
+		PayloadCheck:              cmd.String(payloadCheckFlag.Name),
+		BidTiebreaker:             cmd.String(bidTiebreakerFlag.Name),
+		RelayLabelPreferences:     setupLabelPreferences(cmd),
+		RelaySignatureCheck:       setupSignatureCheck(cmd),
+		ReadyMinRelays:            int(cmd.Int(readyMinRelaysFlag.Name)),
+		ReadyRelayMaxAge:          cmd.Duration(readyRelayMaxAgeFlag.Name),
+		ReputationFile:            cmd.String(reputationFileFlag.Name),
+		RegistrationsFile:         cmd.String(registrationsFileFlag.Name),
+		RequestTimeoutGetHeader:   time.Duration(cmd.Int(timeoutGetHeaderFlag.Name)) * time.Millisecond,
+		RequestTimeoutGetPayload:  time.Duration(cmd.Int(timeoutGetPayloadFlag.Name)) * time.Millisecond,
+		GetPayloadFallbackDelay:   cmd.Duration(getPayloadFallbackDelayFlag.Name),
+		GetPayloadHedgeDelay:      cmd.Duration(getPayloadHedgeDelayFlag.Name),
+		GetPayloadCacheTTL:        cmd.Duration(getPayloadCacheTTLFlag.Name),
+		GetPayloadStrict:          cmd.Bool(getPayloadStrictFlag.Name),
+		RevealJournalFile:         cmd.String(revealJournalFileFlag.Name),
+		RequestTimeoutRegVal:      time.Duration(cmd.Int(timeoutRegValFlag.Name)) * time.Millisecond,
+		RegValResendInterval:      cmd.Duration(registrationResendIntervalFlag.Name),
+		RegValChunkSize:           int(cmd.Int(registrationChunkSizeFlag.Name)),
+		RegValChunkRetries:        int(cmd.Int(registrationChunkRetriesFlag.Name)),
+		RegValSpreadWindow:        cmd.Duration(registrationSpreadWindowFlag.Name),
+		RegValVerifySignatures:    cmd.Bool(registrationVerifySignaturesFlag.Name),
+		RequestMaxRetries:         int(cmd.Int(maxRetriesFlag.Name)),
+		RequestRetryBackoff:       cmd.Duration(retryBackoffFlag.Name),
+		RequestRetryJitter:        cmd.Duration(retryJitterFlag.Name),
+		RequestCompressionMinSize: int(cmd.Int(requestCompressionMinSizeFlag.Name)),
+		RelayClientCert:           cmd.String(relayClientCertFlag.Name),
+		RelayClientKey:            cmd.String(relayClientKeyFlag.Name),
+		CircuitBreakerFailures:    int(cmd.Int(circuitBreakerFailuresFlag.Name)),
+		CircuitBreakerCooldown:    cmd.Duration(circuitBreakerCooldownFlag.Name),
+		QuarantineFaults:          int(cmd.Int(quarantineFaultsFlag.Name)),
+		QuarantineEpochs:          int(cmd.Int(quarantineEpochsFlag.Name)),
+		AdaptiveTimeoutMin:        time.Duration(cmd.Int(timeoutGetHeaderMinFlag.Name)) * time.Millisecond,
+		AdaptiveTimeoutMax:        time.Duration(cmd.Int(timeoutGetHeaderMaxFlag.Name)) * time.Millisecond,
+		GetHeaderMaxIntoSlot:      time.Duration(cmd.Int(getHeaderMaxMsIntoSlotFlag.Name)) * time.Millisecond,
+		GetHeaderSoftDeadline:     time.Duration(cmd.Int(getHeaderSoftDeadlineFlag.Name)) * time.Millisecond,
+		GetHeaderStaggerDelay:     time.Duration(cmd.Int(getHeaderStaggerDelayFlag.Name)) * time.Millisecond,
+		BackupRelayWindow:         time.Duration(cmd.Int(backupRelayWindowFlag.Name)) * time.Millisecond,
+		GetHeaderPollInterval:     time.Duration(cmd.Int(getHeaderPollIntervalFlag.Name)) * time.Millisecond,
+		GetHeaderPollBudget:       time.Duration(cmd.Int(getHeaderPollBudgetFlag.Name)) * time.Millisecond,
+		GetHeaderRetryDelay:       time.Duration(cmd.Int(getHeaderRetryDelayFlag.Name)) * time.Millisecond,
+		PayloadDeliveryCheckDelay: cmd.Duration(payloadDeliveryCheckDelayFlag.Name),
+		Webhooks:                  splitList(cmd.StringSlice(webhookFlag.Name)),
+		WebhookTimeout:            time.Duration(cmd.Int(webhookTimeoutFlag.Name)) * time.Millisecond,
+		EventsToken:               cmd.String(eventsTokenFlag.Name),
+		AdminToken:                cmd.String(adminTokenFlag.Name),
+		ClientAllowlist:           setupClientNetworks(cmd, clientAllowlistFlag.Name),
+		ClientDenylist:            setupClientNetworks(cmd, clientDenylistFlag.Name),
+		RegisterValidatorMaxBytes: cmd.Int(registerValidatorMaxBytesFlag.Name),
+		GetPayloadMaxBytes:        cmd.Int(getPayloadMaxBytesFlag.Name),
+		RateLimitPerIP:            cmd.Float(rateLimitPerIPFlag.Name),
+		RateLimitGlobal:           cmd.Float(rateLimitGlobalFlag.Name),
+		LoadShedding:              cmd.Bool(loadSheddingFlag.Name),
+		RelayDNSResolver:          cmd.String(relayDNSResolverFlag.Name),
+		RelayDNSMaxTTL:            cmd.Duration(relayDNSMaxTTLFlag.Name),
+		RelayPrewarmLead:          cmd.Duration(relayPrewarmLeadFlag.Name),
+		RelayDisableHTTP2:         !cmd.Bool(relayHTTP2Flag.Name),
+		RelayMaxIdleConnsPerHost:  int(cmd.Int(relayMaxIdleConnsFlag.Name)),
+		RelayIdleConnTimeout:      cmd.Duration(relayIdleConnTimeoutFlag.Name),
+		RelayKeepAlive:            cmd.Duration(relayTCPKeepAliveFlag.Name),
+	}
+	if cmd.IsSet(relaysURLFlag.Name) {
+		pubkey, err := parseRelayListPubkey(cmd.String(relaysURLPubkeyFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed setting up relay list")
+		}
+		opts.RelaysURL = cmd.String(relaysURLFlag.Name)
+		opts.RelaysURLPubkey = pubkey
+		opts.RelaysURLInterval = cmd.Duration(relaysURLIntervalFlag.Name)
+	}
+
+	if cmd.IsSet(relaysDNSFlag.Name) {
+		opts.RelaysDNS = splitList(cmd.StringSlice(relaysDNSFlag.Name))
+		opts.RelaysDNSInterval = cmd.Duration(relaysDNSIntervalFlag.Name)
+	}
+
+	if cmd.IsSet(validatorRoutesFlag.Name) {
+		routes, filters, err := readValidatorRoutes(cmd.String(validatorRoutesFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed setting up validator routes")
+		}
+		opts.ValidatorRoutes = routes
+		opts.RelayFilters = filters
+		log.Infof("routing %d validators and filtering %d relays with %s", len(routes), len(filters), cmd.String(validatorRoutesFlag.Name))
+	}
+
+	if cmd.IsSet(bidAuditLogFlag.Name) {
+		auditLogFile, err := os.OpenFile(cmd.String(bidAuditLogFlag.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.WithError(err).Fatal("failed opening bid audit log")
+		}
+		defer auditLogFile.Close()
+		opts.BidAuditLog = auditLogFile
+		log.Infof("writing bid audit log to %s", auditLogFile.Name())
+	}
+
+	if cmd.IsSet(beaconNodeFlag.Name) {
+		beaconNodeURL, err := url.ParseRequestURI(cmd.String(beaconNodeFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("invalid beacon node url")
+		}
+		opts.BeaconNodeURL = beaconNodeURL
+		log.Infof("checking block inclusion with beacon node %s", beaconNodeURL.Host)
+	}
+	opts.PublishBlockFallback = cmd.Bool(publishBlockFallbackFlag.Name)
+
+	if cmd.IsSet(executionNodeFlag.Name) {
+		executionNodeURL, err := url.ParseRequestURI(cmd.String(executionNodeFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("invalid execution node url")
+		}
+		opts.ExecutionNodeURL = executionNodeURL
+		log.Infof("checking proposer payments with execution node %s", executionNodeURL.Host)
+	}
+
+	if cmd.IsSet(bidPolicyURLFlag.Name) {
+		bidPolicyURL, err := url.ParseRequestURI(cmd.String(bidPolicyURLFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("invalid bid policy url")
+		}
+		opts.BidPolicyURL = bidPolicyURL
+		opts.BidPolicyTimeout = time.Duration(cmd.Int(bidPolicyTimeoutFlag.Name)) * time.Millisecond
+		opts.BidPolicyFailClosed = cmd.Bool(bidPolicyFailClosedFlag.Name)
+		log.Infof("deciding bids with the policy service at %s", bidPolicyURL.Host)
+	}
+
+	if cmd.IsSet(bidScriptFlag.Name) {
+		bidScript, err := server.LoadBidScript(cmd.String(bidScriptFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed loading bid script")
+		}
+		opts.BidScript = bidScript
+		log.Infof("scoring bids with %s", cmd.String(bidScriptFlag.Name))
+	}
+
+	if cmd.IsSet(bidPluginFlag.Name) {
+		bidPlugin, err := server.LoadBidPlugin(cmd.String(bidPluginFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed loading bid plugin")
+		}
+		opts.BidPlugin = bidPlugin
+		log.Infof("deciding bids with plugin %s", cmd.String(bidPluginFlag.Name))
+	}
+
+	if cmd.IsSet(statsdAddrFlag.Name) {
+		statsdSink, err := server.NewStatsdSink(cmd.String(statsdAddrFlag.Name), cmd.String(statsdPrefixFlag.Name), cmd.Bool(statsdDogStatsDFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed setting up statsd")
+		}
+		defer statsdSink.Close()
+		opts.MetricsSink = statsdSink
+		log.Infof("sending metrics to statsd at %s", cmd.String(statsdAddrFlag.Name))
+	}
+
+	if cmd.IsSet(sentryDSNFlag.Name) {
+		sentryReporter, err := server.NewSentryReporter(cmd.String(sentryDSNFlag.Name), cmd.String(sentryEnvironmentFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed setting up sentry")
+		}
+		defer sentryReporter.Close()
+		opts.ErrorReporter = sentryReporter
+		log.Info("reporting errors to sentry")
+	}
+
+	if cmd.IsSet(bidHistoryDBFlag.Name) {
+		bidStore, err := server.OpenBidStore(cmd.String(bidHistoryDBFlag.Name))
+		if err != nil {
+			log.WithError(err).Fatal("failed opening bid history database")
+		}
+		defer bidStore.Close()
+		opts.BidStore = bidStore
+		log.Infof("storing bid history in %s", cmd.String(bidHistoryDBFlag.Name))
+	}
+
+	if opts.ReputationFile != "" {
+		log.Infof("persisting the relay reputation in %s", opts.ReputationFile)
+	}
+	if opts.RegistrationsFile != "" {
+		log.Infof("persisting the validator registrations in %s", opts.RegistrationsFile)
+	}
+	if opts.RevealJournalFile != "" {
+		log.Infof("recording revealed blocks in %s", opts.RevealJournalFile)
+	}
+
+	var service *server.BoostService
+	if cmd.IsSet(configFlag.Name) {
+		opts.ReloadConfig = func() error { return reloadConfig(cmd, service) }
+	}
+	service, err = server.NewBoostService(opts)
 	if err != nil {
 		log.WithError(err).Fatal("failed creating the server")
 	}
+	if cmd.IsSet(configFlag.Name) {
+		go reloadOnSIGHUP(cmd, service)
+	}
+
+	if len(opts.RelaysDNS) > 0 || opts.RelaysURL != "" {
+		if err := service.UpdateRelaySources(); err != nil {
+			if len(relays) == 0 {
+				log.WithError(err).Fatal("failed fetching relays")
+			}
+			log.WithError(err).Error("failed fetching relays, using the other relays until it succeeds")
+		}
+		if opts.RelaysURL != "" {
+			log.Infof("fetching relay list from %s every %s", opts.RelaysURL, opts.RelaysURLInterval)
+		}
+		for _, domain := range opts.RelaysDNS {
+			log.Infof("discovering relays with DNS records of %s every %s", domain, opts.RelaysDNSInterval)
+		}
+		service.StartRelaySourceUpdates()
+	}
 
 	if relayCheck && service.CheckRelays() == 0 {
 		log.Error("no relay passed the health-check!")
 	}
 
-	log.Infof("listening on %v", listenAddr)
-	return service.StartHTTPServer()
+	if cmd.Bool(metricsFlag.Name) {
+		go func() {
+			log.Infof("metrics server listening on %v", opts.MetricsAddr)
+			if err := service.StartMetricsServer(); err != nil {
+				log.WithError(err).Error("metrics server failed")
+			}
+		}()
+	}
+
+	if cmd.Bool(pprofFlag.Name) {
+		go func() {
+			log.Infof("pprof server listening on %v", opts.PprofAddr)
+			if err := service.StartPprofServer(); err != nil {
+				log.WithError(err).Error("pprof server failed")
+			}
+		}()
+	}
+
+	if opts.GRPCAddr != "" {
+		go func() {
+			log.Infof("gRPC server listening on %v", opts.GRPCAddr)
+			if err := service.StartGRPCServer(); err != nil {
+				log.WithError(err).Error("gRPC server failed")
+			}
+		}()
+	}
+
+	log.Infof("listening on %v", strings.Join(listenAddrs, ", "))
+	return serveUntilShutdown(service, cmd.Duration(shutdownDrainTimeoutFlag.Name))
+}
+
+// splitList flattens comma-separated flag values into a single list
+func splitList(values []string) []string {
+	ret := []string{}
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				ret = append(ret, entry)
+			}
+		}
+	}
+	return ret
 }
 
 func setupRelays(cmd *cli.Command) (relayList, relayMonitorList, types.U256Str, bool) {
@@ -104,22 +400,18 @@ func setupRelays(cmd *cli.Command) (relayList, relayMonitorList, types.U256Str,
 		monitors relayMonitorList
 	)
 	if cmd.IsSet(relaysFlag.Name) {
-		relayURLs := cmd.StringSlice(relaysFlag.Name)
-		for _, urls := range relayURLs {
-			for _, url := range strings.Split(urls, ",") {
-				if err := relays.Set(strings.TrimSpace(url)); err != nil {
-					log.WithError(err).WithField("relay", url).Fatal("invalid relay URL")
-				}
-			}
+		var err error
+		if relays, err = parseRelayURLs(cmd.StringSlice(relaysFlag.Name)); err != nil {
+			log.WithError(err).Fatal("invalid relay URL")
 		}
 	}
 
-	if len(relays) == 0 {
+	if len(relays) == 0 && !cmd.IsSet(relaysURLFlag.Name) && !cmd.IsSet(relaysDNSFlag.Name) {
 		log.Fatal("no relays specified")
 	}
 	log.Infof("using %d relays", len(relays))
 	for index, relay := range relays {
-		log.Infof("relay #%d: %s", index+1, relay.String())
+		log.WithFields(relayOptionFields(relay)).Infof("relay #%d: %s", index+1, relay.String())
 	}
 
 	// For backwards compatibility with the -relay-monitors flag.
@@ -151,39 +443,118 @@ func setupRelays(cmd *cli.Command) (relayList, relayMonitorList, types.U256Str,
 	return relays, monitors, *relayMinBidWei, cmd.Bool(relayCheckFlag.Name)
 }
 
-func setupGenesis(cmd *cli.Command) (string, uint64) {
+// parseRelayURLs parses the relay flag values, which can be comma-separated lists
+func parseRelayURLs(relayURLs []string) (relayList, error) {
+	var relays relayList
+	for _, url := range splitRelayURLs(relayURLs) {
+		if err := relays.Set(url); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, url)
+		}
+	}
+	return relays, nil
+}
+
+// splitRelayURLs returns the relay URLs of the relay flag values, which can be comma-separated lists
+func splitRelayURLs(relayURLs []string) []string {
+	var urls []string
+	for _, list := range relayURLs {
+		for _, url := range strings.Split(list, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// setupGenesis returns the genesis fork version, genesis time and slot duration of the network
+func setupGenesis(cmd *cli.Command) (string, uint64, uint64) {
 	var (
 		genesisForkVersion string
 		genesisTime        uint64
+		slotTimeSec        uint64 = common.SlotTimeSecMainnet
 	)
 
+	if cmd.IsSet(networkConfigFlag.Name) {
+		genesisForkVersion, genesisTime, slotTimeSec = setupNetworkConfig(cmd.String(networkConfigFlag.Name))
+	}
+
 	switch {
 	case cmd.IsSet(customGenesisForkFlag.Name):
 		genesisForkVersion = cmd.String(customGenesisForkFlag.Name)
+	case genesisForkVersion != "":
+		// from the network config
 	case cmd.Bool(sepoliaFlag.Name):
 		genesisForkVersion = genesisForkVersionSepolia
 		genesisTime = genesisTimeSepolia
 	case cmd.Bool(holeskyFlag.Name):
 		genesisForkVersion = genesisForkVersionHolesky
 		genesisTime = genesisTimeHolesky
+	case cmd.Bool(gnosisFlag.Name):
+		genesisForkVersion = genesisForkVersionGnosis
+		genesisTime = genesisTimeGnosis
+		slotTimeSec = common.SlotTimeSecGnosis
+	case cmd.Bool(chiadoFlag.Name):
+		genesisForkVersion = genesisForkVersionChiado
+		genesisTime = genesisTimeChiado
+		slotTimeSec = common.SlotTimeSecGnosis
 	case cmd.Bool(mainnetFlag.Name):
 		genesisForkVersion = genesisForkVersionMainnet
 		genesisTime = genesisTimeMainnet
 	default:
 		flag.Usage()
-		log.Fatal("please specify a genesis fork version (eg. -mainnet / -sepolia / -goerli / -holesky / -genesis-fork-version flags)")
+		log.Fatal("please specify a genesis fork version (eg. -mainnet / -sepolia / -goerli / -holesky / -gnosis / -chiado / -genesis-fork-version / -network-config flags)")
 	}
 
 	if cmd.IsSet(customGenesisTimeFlag.Name) {
 		genesisTime = cmd.Uint(customGenesisTimeFlag.Name)
 	}
-	log.Infof("using genesis fork version: %s time: %d", genesisForkVersion, genesisTime)
-	return genesisForkVersion, genesisTime
+	if _, ok := os.LookupEnv("SLOT_SEC"); ok {
+		slotTimeSec = config.SlotTimeSec
+	}
+	log.Infof("using genesis fork version: %s time: %d slot time: %ds", genesisForkVersion, genesisTime, slotTimeSec)
+	return genesisForkVersion, genesisTime, slotTimeSec
+}
+
+// setupFuluForkEpoch returns the Fulu fork epoch of the network, nil if it isn't known
+func setupFuluForkEpoch(cmd *cli.Command) *phase0.Epoch {
+	var epoch uint64
+	switch {
+	case cmd.IsSet(fuluForkEpochFlag.Name):
+		epoch = cmd.Uint(fuluForkEpochFlag.Name)
+	case cmd.IsSet(networkConfigFlag.Name):
+		// setupGenesis already read the file
+		cfg, err := readNetworkConfig(cmd.String(networkConfigFlag.Name))
+		if err != nil || cfg.FuluForkEpoch == nil {
+			return nil
+		}
+		epoch = *cfg.FuluForkEpoch
+	case cmd.IsSet(customGenesisForkFlag.Name), cmd.Bool(gnosisFlag.Name), cmd.Bool(chiadoFlag.Name):
+		return nil
+	case cmd.Bool(sepoliaFlag.Name):
+		epoch = fuluForkEpochSepolia
+	case cmd.Bool(holeskyFlag.Name):
+		epoch = fuluForkEpochHolesky
+	default:
+		epoch = fuluForkEpochMainnet
+	}
+	log.Infof("using fulu fork epoch: %d", epoch)
+	fuluForkEpoch := phase0.Epoch(epoch)
+	return &fuluForkEpoch
+}
+
+// setupSignatureCheck returns the signature check of relay bids, supporting the deprecated SKIP_RELAY_SIGNATURE_CHECK
+func setupSignatureCheck(cmd *cli.Command) string {
+	if !cmd.IsSet(relaySignatureCheckFlag.Name) && config.SkipRelaySignatureCheck {
+		log.Warn("SKIP_RELAY_SIGNATURE_CHECK is deprecated, use -relay-signature-check skip")
+		return types.SignatureCheckSkip
+	}
+	return cmd.String(relaySignatureCheckFlag.Name)
 }
 
 func setupLogging(cmd *cli.Command) error {
 	// setup logging
-	log.Logger.SetOutput(os.Stdout)
+	log.Logger.SetOutput(setupLogFile(cmd))
 	if cmd.IsSet(jsonFlag.Name) {
 		log.Logger.SetFormatter(&logrus.JSONFormatter{
 			TimestampFormat: config.RFC3339Milli,
@@ -220,6 +591,81 @@ func setupLogging(cmd *cli.Command) error {
 	return nil
 }
 
+// parseRelayListPubkey parses the public key the relay list is signed with
+func parseRelayListPubkey(pubkeyHex string) (ed25519.PublicKey, error) {
+	pubkey, err := hex.DecodeString(strings.TrimPrefix(pubkeyHex, "0x"))
+	if err != nil || len(pubkey) != ed25519.PublicKeySize {
+		return nil, errInvalidPubkey
+	}
+	return pubkey, nil
+}
+
+// setupMinBidPercent returns the percentage of the median recent bid value for the relative min-bid
+func setupMinBidPercent(cmd *cli.Command) float64 {
+	percent := cmd.Float(minBidPercentFlag.Name)
+	if percent < 0 || percent > 100 {
+		log.WithError(errMinBidPercent).Fatalf("invalid min-bid-percent %v", percent)
+	}
+	if percent > 0 {
+		log.Infof("min bid raised to %v%% of the median bid value of the last %d slots", percent, cmd.Int(minBidSlotsFlag.Name))
+	}
+	return percent
+}
+
+// setupLabelPreferences returns the percentages by which the bids of relays with a label are preferred, by label
+func setupLabelPreferences(cmd *cli.Command) map[string]float64 {
+	preferences := make(map[string]float64)
+	for _, preference := range cmd.StringSlice(relayLabelPreferenceFlag.Name) {
+		label, value, ok := strings.Cut(preference, ":")
+		percent, err := strconv.ParseFloat(value, 64)
+		if !ok || label == "" || err != nil {
+			log.WithError(errLabelPreference).Fatalf("invalid relay label preference %s", preference)
+		}
+		preferences[label] = percent
+		log.Infof("preferring bids of relays labeled %s by %v%%", label, percent)
+	}
+	return preferences
+}
+
+// setupClientNetworks returns the networks of the client allowlist or denylist flag. Single IPs are networks of one
+// address.
+func setupClientNetworks(cmd *cli.Command, flagName string) []netip.Prefix {
+	networks := []netip.Prefix{}
+	for _, entry := range splitList(cmd.StringSlice(flagName)) {
+		network, err := netip.ParsePrefix(entry)
+		if addr, addrErr := netip.ParseAddr(entry); addrErr == nil {
+			network, err = addr.Prefix(addr.BitLen())
+		}
+		if err != nil {
+			log.WithError(err).Fatalf("invalid %s entry %s", flagName, entry)
+		}
+		networks = append(networks, network.Masked())
+	}
+	return networks
+}
+
+// setupMaxBid returns the max-bid, the bids above are implausible
+func setupMaxBid(cmd *cli.Command) types.U256Str {
+	maxBid, multiple := cmd.Float(maxBidFlag.Name), cmd.Float(maxBidMedianMultipleFlag.Name)
+	if maxBid < 0 || multiple < 0 {
+		log.WithError(errNegativeMaxBid).Fatalf("invalid max-bid %v or max-bid-median-multiple %v", maxBid, multiple)
+	}
+	maxBidWei, err := common.FloatEthTo256Wei(maxBid)
+	if err != nil {
+		log.WithError(err).Fatal("invalid max-bid")
+	}
+	return *maxBidWei
+}
+
+// setupLocalBlockValueDelta returns how much a bid has to beat the local block value sent by the beacon node
+func setupLocalBlockValueDelta(cmd *cli.Command) types.U256Str {
+	delta, err := sanitizeMinBid(cmd.Float(localBlockValueDeltaFlag.Name))
+	if err != nil {
+		log.WithError(err).Fatal("invalid local-block-value-delta")
+	}
+	return *delta
+}
+
 func sanitizeMinBid(minBid float64) (*types.U256Str, error) {
 	if minBid < 0.0 {
 		return nil, errNegativeBid
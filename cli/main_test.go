@@ -2,8 +2,11 @@ package cli
 
 import (
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/mev-boost/common"
 	"github.com/stretchr/testify/require"
@@ -35,3 +38,74 @@ func TestFloatEthTo256Wei(t *testing.T) {
 
 	require.Equal(t, *referenceWeiU256, *weiU256)
 }
+
+func TestParseRelayListPubkey(t *testing.T) {
+	pubkey, err := parseRelayListPubkey("0xd75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	require.NoError(t, err)
+	require.Len(t, pubkey, 32)
+
+	_, err = parseRelayListPubkey("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	require.NoError(t, err)
+
+	_, err = parseRelayListPubkey("0xd75a9801")
+	require.ErrorIs(t, err, errInvalidPubkey)
+
+	_, err = parseRelayListPubkey("")
+	require.ErrorIs(t, err, errInvalidPubkey)
+}
+
+func TestSetupGenesis(t *testing.T) {
+	t.Run("Gnosis", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "network: gnosis\n")
+		require.NoError(t, err)
+		genesisForkVersion, genesisTime, slotTimeSec := setupGenesis(cmd)
+		require.Equal(t, genesisForkVersionGnosis, genesisForkVersion)
+		require.Equal(t, uint64(genesisTimeGnosis), genesisTime)
+		require.Equal(t, uint64(common.SlotTimeSecGnosis), slotTimeSec)
+	})
+
+	t.Run("Mainnet by default", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "")
+		require.NoError(t, err)
+		genesisForkVersion, _, slotTimeSec := setupGenesis(cmd)
+		require.Equal(t, genesisForkVersionMainnet, genesisForkVersion)
+		require.Equal(t, uint64(common.SlotTimeSecMainnet), slotTimeSec)
+	})
+
+	t.Run("Network config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "network.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("GENESIS_FORK_VERSION: 0x10000038\nGENESIS_TIME: 1718000123\nSECONDS_PER_SLOT: 6\n"), 0o600))
+		cmd, err := runWithConfig(t, "config.yaml", "", "-network-config", path, "-genesis-timestamp", "1718000000")
+		require.NoError(t, err)
+		genesisForkVersion, genesisTime, slotTimeSec := setupGenesis(cmd)
+		require.Equal(t, "0x10000038", genesisForkVersion)
+		require.Equal(t, uint64(1718000000), genesisTime)
+		require.Equal(t, uint64(6), slotTimeSec)
+	})
+}
+
+func TestSetupFuluForkEpoch(t *testing.T) {
+	t.Run("Mainnet by default", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "")
+		require.NoError(t, err)
+		require.Equal(t, phase0.Epoch(fuluForkEpochMainnet), *setupFuluForkEpoch(cmd))
+	})
+
+	t.Run("Network config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "network.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("GENESIS_FORK_VERSION: 0x10000038\nFULU_FORK_EPOCH: 10\n"), 0o600))
+		cmd, err := runWithConfig(t, "config.yaml", "", "-network-config", path)
+		require.NoError(t, err)
+		require.Equal(t, phase0.Epoch(10), *setupFuluForkEpoch(cmd))
+
+		cmd, err = runWithConfig(t, "config.yaml", "", "-network-config", path, "-fulu-fork-epoch", "20")
+		require.NoError(t, err)
+		require.Equal(t, phase0.Epoch(20), *setupFuluForkEpoch(cmd))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "network: gnosis\n")
+		require.NoError(t, err)
+		require.Nil(t, setupFuluForkEpoch(cmd))
+	})
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/urfave/cli/v3"
+)
+
+var errInvalidSecretKey = errors.New("invalid mock relay secret key, expected a hex-encoded BLS secret key")
+
+var (
+	mockRelayAddrFlag = &cli.StringFlag{
+		Name:     "addr",
+		Sources:  cli.EnvVars("MOCK_RELAY_LISTEN_ADDR"),
+		Value:    "localhost:28545",
+		Usage:    "listen-address of the mock relay",
+		Category: MockRelayCategory,
+	}
+	mockRelayScenarioFlag = &cli.StringFlag{
+		Name:     "scenario",
+		Sources:  cli.EnvVars("MOCK_RELAY_SCENARIO"),
+		Usage:    "YAML or TOML scenario file with the behaviors of the mock relay by endpoint, all requests are served normally if not set",
+		Category: MockRelayCategory,
+	}
+	mockRelaySecretKeyFlag = &cli.StringFlag{
+		Name:     "secret-key",
+		Sources:  cli.EnvVars("MOCK_RELAY_SECRET_KEY"),
+		Usage:    "hex-encoded BLS secret key signing the bids of the mock relay, a random key if not set",
+		Category: MockRelayCategory,
+	}
+
+	mockRelayCommand = &cli.Command{
+		Name:   "mock-relay",
+		Usage:  "serve the builder API of a relay with scripted behaviors, to test the failure handling of mev-boost and the beacon node",
+		Action: toolAction(runMockRelay),
+		Flags:  []cli.Flag{mockRelayAddrFlag, mockRelayScenarioFlag, mockRelaySecretKeyFlag},
+	}
+)
+
+// runMockRelay serves the mock relay, for the network of the genesis flags
+func runMockRelay(_ context.Context, cmd *cli.Command) error {
+	genesisForkVersion, genesisTime, slotTimeSec := setupGenesis(cmd)
+
+	var scenario mock.Scenario
+	if cmd.IsSet(mockRelayScenarioFlag.Name) {
+		var err error
+		if scenario, err = readMockRelayScenario(cmd.String(mockRelayScenarioFlag.Name)); err != nil {
+			return err
+		}
+	}
+	var secretKey *bls.SecretKey
+	if cmd.IsSet(mockRelaySecretKeyFlag.Name) {
+		var err error
+		if secretKey, err = parseSecretKey(cmd.String(mockRelaySecretKeyFlag.Name)); err != nil {
+			return err
+		}
+	}
+
+	relay, err := mock.NewScenarioRelay(mock.ScenarioRelayOpts{
+		Log:                   log.WithField("service", "mock-relay"),
+		Scenario:              scenario,
+		SecretKey:             secretKey,
+		GenesisForkVersionHex: genesisForkVersion,
+		GenesisTime:           genesisTime,
+		SlotTimeSec:           slotTimeSec,
+	})
+	if err != nil {
+		return err
+	}
+
+	addr := cmd.String(mockRelayAddrFlag.Name)
+	log.WithField("relay", fmt.Sprintf("http://%s@%s", relay.PublicKey(), addr)).Info("serving mock relay")
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           relay.Handler(),
+		ReadHeaderTimeout: time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// readMockRelayScenario reads a YAML or TOML scenario file of the mock relay
+func readMockRelayScenario(path string) (mock.Scenario, error) {
+	var scenario mock.Scenario
+	if err := decodeConfigFile(path, &scenario); err != nil {
+		return scenario, fmt.Errorf("failed reading scenario file %s: %w", path, err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return scenario, fmt.Errorf("failed reading scenario file %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+func parseSecretKey(secretKeyHex string) (*bls.SecretKey, error) {
+	secretKeyBytes, err := hexutil.Decode(secretKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidSecretKey, err)
+	}
+	secretKey, err := bls.SecretKeyFromBytes(secretKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidSecretKey, err)
+	}
+	return secretKey, nil
+}
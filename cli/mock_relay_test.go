@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMockRelayScenario(t *testing.T) {
+	writeScenario := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("YAML", func(t *testing.T) {
+		path := writeScenario(t, "scenario.yaml", `
+fork: electra
+get-header:
+  - slots: [10, 11]
+    status: 500
+  - value: 0.05
+    delay: 300ms
+get-payload:
+  - wrong-blobs: true
+`)
+		scenario, err := readMockRelayScenario(path)
+		require.NoError(t, err)
+		require.Equal(t, "electra", scenario.Fork)
+		require.Len(t, scenario.GetHeader, 2)
+		require.Equal(t, []uint64{10, 11}, scenario.GetHeader[0].Slots)
+		require.Equal(t, 500, scenario.GetHeader[0].Status)
+		require.InDelta(t, 0.05, *scenario.GetHeader[1].Value, 0)
+		require.Equal(t, 300*time.Millisecond, scenario.GetHeader[1].Delay)
+		require.True(t, scenario.GetPayload[0].WrongBlobs)
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		path := writeScenario(t, "scenario.toml", `
+[[get-header]]
+slots = [10]
+invalid-signature = true
+
+[[register-validator]]
+delay = "2s"
+`)
+		scenario, err := readMockRelayScenario(path)
+		require.NoError(t, err)
+		require.True(t, scenario.GetHeader[0].InvalidSignature)
+		require.Equal(t, 2*time.Second, scenario.RegisterValidator[0].Delay)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := readMockRelayScenario(writeScenario(t, "scenario.yaml", "get-header:\n  - unknown: true\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("Secret key", func(t *testing.T) {
+		_, err := parseSecretKey("0x4e343a647c5a5c44d76c2c58b63f02cdf3a9a0ec40f102ebc26363b4b1b95033")
+		require.NoError(t, err)
+		_, err = parseSecretKey("0x1234")
+		require.ErrorIs(t, err, errInvalidSecretKey)
+	})
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/flashbots/mev-boost/common"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+var errInvalidNetworkConfig = errors.New("invalid network config")
+
+// networkConfig are the settings of a consensus layer network config file (config.yaml), as generated for devnets, i.e.
+// by kurtosis. Other keys of the file are ignored.
+type networkConfig struct {
+	GenesisForkVersion string  `yaml:"GENESIS_FORK_VERSION"`
+	GenesisTime        *uint64 `yaml:"GENESIS_TIME"` // not part of the spec config, set by some devnet tools
+	MinGenesisTime     uint64  `yaml:"MIN_GENESIS_TIME"`
+	GenesisDelay       uint64  `yaml:"GENESIS_DELAY"`
+	SecondsPerSlot     uint64  `yaml:"SECONDS_PER_SLOT"`
+
+	AltairForkEpoch    *uint64 `yaml:"ALTAIR_FORK_EPOCH"`
+	BellatrixForkEpoch *uint64 `yaml:"BELLATRIX_FORK_EPOCH"`
+	CapellaForkEpoch   *uint64 `yaml:"CAPELLA_FORK_EPOCH"`
+	DenebForkEpoch     *uint64 `yaml:"DENEB_FORK_EPOCH"`
+	ElectraForkEpoch   *uint64 `yaml:"ELECTRA_FORK_EPOCH"`
+	FuluForkEpoch      *uint64 `yaml:"FULU_FORK_EPOCH"`
+}
+
+// readNetworkConfig reads a network config file
+func readNetworkConfig(path string) (*networkConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(networkConfig)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidNetworkConfig, err)
+	}
+	if cfg.GenesisForkVersion == "" {
+		return nil, fmt.Errorf("%w: missing GENESIS_FORK_VERSION", errInvalidNetworkConfig)
+	}
+	return cfg, nil
+}
+
+// genesisTime returns the genesis time of the network. Without GENESIS_TIME it is MIN_GENESIS_TIME + GENESIS_DELAY,
+// which is the genesis time of devnets starting from a genesis state.
+func (c *networkConfig) genesisTime() uint64 {
+	if c.GenesisTime != nil {
+		return *c.GenesisTime
+	}
+	if c.MinGenesisTime == 0 {
+		return 0
+	}
+	return c.MinGenesisTime + c.GenesisDelay
+}
+
+// forkEpochs returns the fork epochs of the network config, for logging
+func (c *networkConfig) forkEpochs() logrus.Fields {
+	fields := logrus.Fields{}
+	for name, epoch := range map[string]*uint64{
+		"altair":    c.AltairForkEpoch,
+		"bellatrix": c.BellatrixForkEpoch,
+		"capella":   c.CapellaForkEpoch,
+		"deneb":     c.DenebForkEpoch,
+		"electra":   c.ElectraForkEpoch,
+		"fulu":      c.FuluForkEpoch,
+	} {
+		if epoch != nil {
+			fields[name] = *epoch
+		}
+	}
+	return fields
+}
+
+// setupNetworkConfig reads the network config file and returns the genesis fork version, genesis time and slot duration
+func setupNetworkConfig(path string) (string, uint64, uint64) {
+	cfg, err := readNetworkConfig(path)
+	if err != nil {
+		log.WithError(err).Fatalf("failed reading network config %s", path)
+	}
+
+	slotTimeSec := cfg.SecondsPerSlot
+	if slotTimeSec == 0 {
+		slotTimeSec = common.SlotTimeSecMainnet
+	}
+	log.WithFields(cfg.forkEpochs()).Infof("using network config %s", path)
+	return cfg.GenesisForkVersion, cfg.genesisTime(), slotTimeSec
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNetworkConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("Devnet config", func(t *testing.T) {
+		path := writeConfig(t, `
+PRESET_BASE: 'mainnet'
+CONFIG_NAME: testnet
+MIN_GENESIS_ACTIVE_VALIDATOR_COUNT: 64
+MIN_GENESIS_TIME: 1718000000
+GENESIS_FORK_VERSION: 0x10000038
+GENESIS_DELAY: 60
+ALTAIR_FORK_VERSION: 0x20000038
+ALTAIR_FORK_EPOCH: 0
+DENEB_FORK_EPOCH: 0
+ELECTRA_FORK_EPOCH: 18446744073709551615
+SECONDS_PER_SLOT: 6
+`)
+		cfg, err := readNetworkConfig(path)
+		require.NoError(t, err)
+		require.Equal(t, "0x10000038", cfg.GenesisForkVersion)
+		require.Equal(t, uint64(1718000060), cfg.genesisTime())
+		require.Equal(t, uint64(6), cfg.SecondsPerSlot)
+
+		epochs := cfg.forkEpochs()
+		require.Len(t, epochs, 3)
+		require.Equal(t, uint64(0), epochs["deneb"])
+		require.Equal(t, uint64(18446744073709551615), epochs["electra"])
+	})
+
+	t.Run("Genesis time", func(t *testing.T) {
+		path := writeConfig(t, `
+GENESIS_FORK_VERSION: '0x10000038'
+GENESIS_TIME: 1718000123
+MIN_GENESIS_TIME: 1718000000
+GENESIS_DELAY: 60
+`)
+		cfg, err := readNetworkConfig(path)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1718000123), cfg.genesisTime())
+	})
+
+	t.Run("Missing genesis fork version", func(t *testing.T) {
+		_, err := readNetworkConfig(writeConfig(t, "SECONDS_PER_SLOT: 12\n"))
+		require.ErrorIs(t, err, errInvalidNetworkConfig)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		_, err := readNetworkConfig(writeConfig(t, "GENESIS_FORK_VERSION: 0x10000038\nSECONDS_PER_SLOT: six\n"))
+		require.ErrorIs(t, err, errInvalidNetworkConfig)
+	})
+}
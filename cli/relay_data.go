@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	errRelayDataQuery  = errors.New("set the -slot or the -pubkey to query")
+	errRelayDataPubkey = errors.New("invalid validator public key")
+	errRelayDataFailed = errors.New("all relays failed to respond")
+)
+
+var (
+	relayDataSlotFlag = &cli.UintFlag{
+		Name:     "slot",
+		Sources:  cli.EnvVars("RELAY_DATA_SLOT"),
+		Usage:    "slot to query the delivered payload and the received blocks of",
+		Category: RelayDataCategory,
+	}
+	relayDataPubkeyFlag = &cli.StringFlag{
+		Name:     "pubkey",
+		Sources:  cli.EnvVars("RELAY_DATA_PUBKEY"),
+		Usage:    "validator public key to query the delivered payloads and the registrations of",
+		Category: RelayDataCategory,
+	}
+	relayDataLimitFlag = &cli.IntFlag{
+		Name:     "limit",
+		Sources:  cli.EnvVars("RELAY_DATA_LIMIT"),
+		Value:    10,
+		Usage:    "maximum number of delivered payloads and received blocks to print, the highest bids of a slot and the latest payloads of a validator",
+		Category: RelayDataCategory,
+	}
+	relayDataTimeoutFlag = &cli.DurationFlag{
+		Name:     "timeout",
+		Sources:  cli.EnvVars("RELAY_DATA_TIMEOUT"),
+		Value:    5 * time.Second,
+		Usage:    "timeout of each request to the relays",
+		Category: RelayDataCategory,
+	}
+	relayDataOutputFlag = &cli.StringFlag{
+		Name:     "output",
+		Sources:  cli.EnvVars("RELAY_DATA_OUTPUT"),
+		Value:    "table",
+		Usage:    "output format: table or json",
+		Category: RelayDataCategory,
+	}
+
+	relayDataCommand = &cli.Command{
+		Name:   "relay-data",
+		Usage:  "query the data APIs of the configured relays for the payloads delivered and blocks received in a slot, or the deliveries and registrations of a validator",
+		Action: toolAction(runRelayData),
+		Flags: []cli.Flag{
+			relayDataSlotFlag,
+			relayDataPubkeyFlag,
+			relayDataLimitFlag,
+			relayDataTimeoutFlag,
+			relayDataOutputFlag,
+		},
+	}
+)
+
+// relayDataReport are the merged data API responses of the relays, the queries which don't apply are nil
+type relayDataReport struct {
+	Delivered     *tools.RelayBidTraces     `json:"delivered,omitempty"`
+	Received      *tools.RelayBidTraces     `json:"received,omitempty"`
+	Registrations *tools.RelayRegistrations `json:"registrations,omitempty"`
+}
+
+// runRelayData queries the data APIs of the relays of the -relay flags: the delivered payloads of the slot and the
+// validator, the blocks received in the slot, and the registrations of the validator
+func runRelayData(ctx context.Context, cmd *cli.Command) error {
+	relays, err := toolRelays(cmd)
+	if err != nil {
+		return err
+	}
+	output := cmd.String(relayDataOutputFlag.Name)
+	if err := checkToolOutput(output); err != nil {
+		return err
+	}
+	hasSlot, hasPubkey := cmd.IsSet(relayDataSlotFlag.Name), cmd.IsSet(relayDataPubkeyFlag.Name)
+	if !hasSlot && !hasPubkey {
+		return errRelayDataQuery
+	}
+	var pubkey string
+	if hasPubkey {
+		parsed, err := utils.HexToPubkey(cmd.String(relayDataPubkeyFlag.Name))
+		if err != nil {
+			return fmt.Errorf("%w: %w", errRelayDataPubkey, err)
+		}
+		pubkey = parsed.String()
+	}
+	_, genesisTime, slotTimeSec := setupGenesis(cmd)
+
+	opts := tools.RelayDataOpts{
+		Timeout:     cmd.Duration(relayDataTimeoutFlag.Name),
+		RelayClient: toolRelayClient(cmd),
+	}
+	limit := int(cmd.Int(relayDataLimitFlag.Name))
+	slot := strconv.FormatUint(cmd.Uint(relayDataSlotFlag.Name), 10)
+
+	var report relayDataReport
+	query := url.Values{}
+	if hasSlot {
+		query.Set("slot", slot)
+	}
+	if hasPubkey {
+		query.Set("proposer_pubkey", pubkey)
+		if limit > 0 {
+			query.Set("limit", strconv.Itoa(limit))
+		}
+	}
+	delivered, err := tools.QueryDeliveredPayloads(ctx, relays, query, opts)
+	if err != nil {
+		return err
+	}
+	report.Delivered = &delivered
+	if hasSlot {
+		// The relays return the received blocks in their order, the highest bids are kept after merging
+		received, err := tools.QueryReceivedBlocks(ctx, relays, url.Values{"slot": []string{slot}}, opts)
+		if err != nil {
+			return err
+		}
+		report.Received = &received
+	}
+	if hasPubkey {
+		registrations, err := tools.QueryValidatorRegistrations(ctx, relays, pubkey, opts)
+		if err != nil {
+			return err
+		}
+		report.Registrations = &registrations
+	}
+	report.limit(limit)
+
+	if output == "json" {
+		if err := printJSON(cmd.Writer, report); err != nil {
+			return err
+		}
+	} else {
+		report.print(cmd.Writer, genesisTime, slotTimeSec)
+	}
+	if report.failed(len(relays)) {
+		return errRelayDataFailed
+	}
+	return nil
+}
+
+// limit keeps the first bid traces, the latest and highest, if limit is positive
+func (r *relayDataReport) limit(limit int) {
+	for _, traces := range []*tools.RelayBidTraces{r.Delivered, r.Received} {
+		if traces != nil && limit > 0 && len(traces.Traces) > limit {
+			traces.Traces = traces.Traces[:limit]
+		}
+	}
+}
+
+// failed returns whether all relays failed all queries
+func (r *relayDataReport) failed(relays int) bool {
+	switch {
+	case r.Delivered != nil && len(r.Delivered.Errors) < relays:
+		return false
+	case r.Received != nil && len(r.Received.Errors) < relays:
+		return false
+	case r.Registrations != nil && len(r.Registrations.Errors) < relays:
+		return false
+	}
+	return true
+}
+
+// print writes the tables of the report, each followed by the errors of the relays. The receive times of the blocks
+// are relative to the start of the slot.
+func (r *relayDataReport) print(w io.Writer, genesisTime, slotTimeSec uint64) {
+	section := func(title string, header []string, rows [][]string, errs []tools.RelayDataError) {
+		fmt.Fprintf(w, "%s:\n", title)
+		if len(rows) == 0 {
+			fmt.Fprintln(w, "none")
+		} else {
+			table := newTable(w, header...)
+			for _, row := range rows {
+				table.row(row...)
+			}
+			table.flush()
+		}
+		for _, err := range errs {
+			fmt.Fprintf(w, "%s: %s\n", err.Relay, err.Error)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if r.Delivered != nil {
+		rows := make([][]string, 0, len(r.Delivered.Traces))
+		for _, trace := range r.Delivered.Traces {
+			rows = append(rows, []string{
+				strconv.FormatUint(trace.Slot, 10), trace.BlockHash, formatWeiEth(trace.Value.BigInt(), false), strconv.FormatUint(trace.NumTx, 10),
+				strconv.FormatUint(trace.GasUsed, 10), shortHex(trace.BuilderPubkey), strings.Join(trace.Relays, ","),
+			})
+		}
+		section("Delivered payloads", []string{"SLOT", "BLOCK HASH", "VALUE (ETH)", "TXS", "GAS USED", "BUILDER", "RELAYS"}, rows, r.Delivered.Errors)
+	}
+	if r.Received != nil {
+		rows := make([][]string, 0, len(r.Received.Traces))
+		for _, trace := range r.Received.Traces {
+			received := "-"
+			if trace.TimestampMs > 0 {
+				slotStart := int64((genesisTime + trace.Slot*slotTimeSec) * 1000)
+				received = fmt.Sprintf("%+dms", int64(trace.TimestampMs)-slotStart)
+			}
+			rows = append(rows, []string{
+				strconv.FormatUint(trace.Slot, 10), trace.BlockHash, formatWeiEth(trace.Value.BigInt(), false), strconv.FormatUint(trace.NumTx, 10),
+				shortHex(trace.BuilderPubkey), received, strings.Join(trace.Relays, ","),
+			})
+		}
+		section("Received blocks", []string{"SLOT", "BLOCK HASH", "VALUE (ETH)", "TXS", "BUILDER", "RECEIVED", "RELAYS"}, rows, r.Received.Errors)
+	}
+	if r.Registrations != nil {
+		rows := make([][]string, 0, len(r.Registrations.Registrations))
+		for _, registration := range r.Registrations.Registrations {
+			rows = append(rows, []string{
+				registration.FeeRecipient, strconv.FormatUint(registration.GasLimit, 10),
+				time.Unix(int64(registration.Timestamp), 0).UTC().Format(time.RFC3339), strings.Join(registration.Relays, ","),
+			})
+		}
+		section("Validator registrations", []string{"FEE RECIPIENT", "GAS LIMIT", "TIMESTAMP", "RELAYS"}, rows, r.Registrations.Errors)
+		if len(rows) > 1 {
+			fmt.Fprintf(w, "the relays know %d different registrations of the validator\n\n", len(rows))
+		}
+	}
+}
+
+// shortHex abbreviates long hex strings like public keys to their first and last 4 bytes
+func shortHex(s string) string {
+	if len(s) <= 20 {
+		return s
+	}
+	return s[:10] + "..." + s[len(s)-8:]
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayDataReport(t *testing.T) {
+	const builder = "0xa1dead01e65f0a0eee7b5170223f20c8f0cbf122eac3324d61afbdb33a8885ff8cab2ef514ac2c7698ae0d6289ef27fc"
+	trace := func(blockHash string, wei uint64, timestampMs uint64, relays ...string) tools.RelayBidTrace {
+		return tools.RelayBidTrace{
+			BidTrace: tools.BidTrace{Slot: 10, BlockHash: blockHash, BuilderPubkey: builder, Value: types.IntToU256(wei), NumTx: 3, GasUsed: 1000, TimestampMs: timestampMs},
+			Relays:   relays,
+		}
+	}
+	newReport := func() relayDataReport {
+		return relayDataReport{
+			Delivered: &tools.RelayBidTraces{Traces: []tools.RelayBidTrace{trace("0xaa", 50_000_000_000_000_000, 0, "relay-a", "relay-b")}},
+			Received: &tools.RelayBidTraces{
+				Traces: []tools.RelayBidTrace{trace("0xbb", 60_000_000_000_000_000, 121_500, "relay-a"), trace("0xaa", 50_000_000_000_000_000, 119_000, "relay-a")},
+				Errors: []tools.RelayDataError{{Relay: "relay-b", Error: "unexpected status code 500"}},
+			},
+		}
+	}
+
+	t.Run("Tables", func(t *testing.T) {
+		report := newReport()
+		var out bytes.Buffer
+		// Slot 10 starts 120s after genesis
+		report.print(&out, 0, 12)
+		lines := strings.Split(out.String(), "\n")
+		require.Equal(t, "Delivered payloads:", lines[0])
+		require.Equal(t, []string{"SLOT", "BLOCK", "HASH", "VALUE", "(ETH)", "TXS", "GAS", "USED", "BUILDER", "RELAYS"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"10", "0xaa", "0.050000", "3", "1000", "0xa1dead01...89ef27fc", "relay-a,relay-b"}, strings.Fields(lines[2]))
+		require.Equal(t, "Received blocks:", lines[4])
+		require.Equal(t, []string{"10", "0xbb", "0.060000", "3", "0xa1dead01...89ef27fc", "+1500ms", "relay-a"}, strings.Fields(lines[6]))
+		require.Equal(t, []string{"10", "0xaa", "0.050000", "3", "0xa1dead01...89ef27fc", "-1000ms", "relay-a"}, strings.Fields(lines[7]))
+		require.Equal(t, "relay-b: unexpected status code 500", lines[8])
+		require.NotContains(t, out.String(), "Validator registrations")
+	})
+
+	t.Run("Registrations", func(t *testing.T) {
+		report := relayDataReport{Registrations: &tools.RelayRegistrations{Registrations: []tools.RelayRegistration{
+			{FeeRecipient: "0xabcf8e0d4e9587369b2301d0790347320302cc09", GasLimit: 36_000_000, Timestamp: 1_700_000_000, Relays: []string{"relay-a"}},
+			{FeeRecipient: "0xabcf8e0d4e9587369b2301d0790347320302cc09", GasLimit: 30_000_000, Timestamp: 1_600_000_000, Relays: []string{"relay-b"}},
+		}}}
+		var out bytes.Buffer
+		report.print(&out, 0, 12)
+		lines := strings.Split(out.String(), "\n")
+		require.Equal(t, "Validator registrations:", lines[0])
+		require.Equal(t, []string{"0xabcf8e0d4e9587369b2301d0790347320302cc09", "36000000", "2023-11-14T22:13:20Z", "relay-a"}, strings.Fields(lines[2]))
+		require.Contains(t, out.String(), "the relays know 2 different registrations of the validator")
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		report := newReport()
+		report.limit(1)
+		require.Len(t, report.Received.Traces, 1)
+		require.Equal(t, "0xbb", report.Received.Traces[0].BlockHash)
+		report.limit(0)
+		require.Len(t, report.Received.Traces, 1)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		report := newReport()
+		require.False(t, report.failed(2))
+		report.Delivered.Errors = []tools.RelayDataError{{Relay: "relay-a"}, {Relay: "relay-b"}}
+		require.False(t, report.failed(2))
+		report.Received.Errors = append(report.Received.Errors, tools.RelayDataError{Relay: "relay-a"})
+		require.True(t, report.failed(2))
+	})
+}
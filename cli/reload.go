@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+)
+
+// configFileFlags are the flags which were set from the config file. Only they change when the config file is reloaded,
+// flags and environment variables keep taking precedence.
+var configFileFlags = map[string]bool{}
+
+// reloadOnSIGHUP reloads the config file whenever the process receives SIGHUP
+func reloadOnSIGHUP(cmd *cli.Command, service *server.BoostService) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		log.Info("received SIGHUP, reloading config file")
+		if err := reloadConfig(cmd, service); err != nil {
+			log.WithError(err).Error("failed reloading config file")
+		}
+	}
+}
+
+// reloadConfig re-reads the config file and applies the relays, min-bid, timeouts, validator routes, bid plugin and log
+// level. Settings which were removed from the config file return to their defaults. The bid plugin is loaded again, so
+// a changed module replaces the running one.
+func reloadConfig(cmd *cli.Command, service *server.BoostService) error {
+	path := cmd.String(configFlag.Name)
+	values, err := readConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("failed reading config file %s: %w", path, err)
+	}
+
+	relayURLs := cmd.StringSlice(relaysFlag.Name)
+	if value, ok := reloadedValue(cmd, values, relaysFlag); ok {
+		relayURLs = nil
+		if value != nil {
+			urls, err := relayConfigURLs(value)
+			if err != nil {
+				return err
+			}
+			if relayURLs, err = configValues(urls); err != nil {
+				return fmt.Errorf("%w for %s: %w", errInvalidConfigValue, relaysFlag.Name, err)
+			}
+		}
+	}
+	relays, err := parseRelayURLs(relayURLs)
+	if err != nil {
+		return err
+	}
+
+	minBid, err := reloadedFloat(cmd, values, minBidFlag)
+	if err != nil {
+		return fmt.Errorf("%w for %s: %w", errInvalidConfigValue, minBidFlag.Name, err)
+	}
+	minBidWei, err := sanitizeMinBid(minBid)
+	if err != nil {
+		return err
+	}
+
+	timeouts := make(map[*cli.IntFlag]time.Duration)
+	for _, f := range []*cli.IntFlag{timeoutGetHeaderFlag, timeoutGetPayloadFlag, timeoutRegValFlag} {
+		ms, err := reloadedInt(cmd, values, f)
+		if err != nil {
+			return fmt.Errorf("%w for %s: %w", errInvalidConfigValue, f.Name, err)
+		}
+		timeouts[f] = time.Duration(ms) * time.Millisecond
+	}
+
+	routesPath, err := reloadedString(cmd, values, validatorRoutesFlag)
+	if err != nil {
+		return err
+	}
+	var routes server.ValidatorRoutes
+	var filters server.RelayFilters
+	if routesPath != "" {
+		if routes, filters, err = readValidatorRoutes(routesPath); err != nil {
+			return err
+		}
+	}
+
+	pluginPath, err := reloadedString(cmd, values, bidPluginFlag)
+	if err != nil {
+		return err
+	}
+	var plugin *server.BidPlugin
+	if pluginPath != "" {
+		if plugin, err = server.LoadBidPlugin(pluginPath); err != nil {
+			return fmt.Errorf("failed loading bid plugin %s: %w", pluginPath, err)
+		}
+	}
+
+	logLevel, err := reloadedString(cmd, values, logLevelFlag)
+	if err != nil {
+		return err
+	}
+	if cmd.Bool(debugFlag.Name) {
+		logLevel = "debug"
+	}
+	lvl, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidLoglevel, logLevel)
+	}
+
+	err = service.Reload(server.ReloadOpts{
+		Relays:                   relays,
+		RelayMinBid:              *minBidWei,
+		RequestTimeoutGetHeader:  timeouts[timeoutGetHeaderFlag],
+		RequestTimeoutGetPayload: timeouts[timeoutGetPayloadFlag],
+		RequestTimeoutRegVal:     timeouts[timeoutRegValFlag],
+		ValidatorRoutes:          routes,
+		RelayFilters:             filters,
+		BidPlugin:                plugin,
+	})
+	if err != nil {
+		if plugin != nil {
+			_ = plugin.Close()
+		}
+		return err
+	}
+	log.Logger.SetLevel(lvl)
+	return nil
+}
+
+// reloadedValue returns the config file value of the flag, or nil if it was removed from the config file. ok is false if
+// the flag is set on the command line or by environment variable, which take precedence.
+func reloadedValue(cmd *cli.Command, values map[string]any, f cli.Flag) (value any, ok bool) {
+	name := f.Names()[0]
+	if cmd.IsSet(name) && !configFileFlags[name] {
+		return nil, false
+	}
+	for _, key := range f.Names() {
+		if value, found := values[key]; found {
+			return value, true
+		}
+	}
+	return nil, true
+}
+
+func reloadedInt(cmd *cli.Command, values map[string]any, f *cli.IntFlag) (int64, error) {
+	value, ok := reloadedValue(cmd, values, f)
+	switch {
+	case !ok:
+		return cmd.Int(f.Name), nil
+	case value == nil:
+		return f.Value, nil
+	}
+	return strconv.ParseInt(fmt.Sprint(value), 10, 64)
+}
+
+func reloadedFloat(cmd *cli.Command, values map[string]any, f *cli.FloatFlag) (float64, error) {
+	value, ok := reloadedValue(cmd, values, f)
+	switch {
+	case !ok:
+		return cmd.Float(f.Name), nil
+	case value == nil:
+		return f.Value, nil
+	}
+	return strconv.ParseFloat(fmt.Sprint(value), 64)
+}
+
+func reloadedString(cmd *cli.Command, values map[string]any, f *cli.StringFlag) (string, error) {
+	value, ok := reloadedValue(cmd, values, f)
+	switch {
+	case !ok:
+		return cmd.String(f.Name), nil
+	case value == nil:
+		return f.Value, nil
+	}
+	entries, err := configValues(value)
+	if err != nil || len(entries) != 1 {
+		return "", fmt.Errorf("%w for %s", errInvalidConfigValue, f.Name)
+	}
+	return entries[0], nil
+}
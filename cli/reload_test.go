@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig(t *testing.T) {
+	newService := func(t *testing.T) *server.BoostService {
+		t.Helper()
+		relays, err := parseRelayURLs([]string{testRelayA})
+		require.NoError(t, err)
+		service, err := server.NewBoostService(server.BoostServiceOpts{
+			Log:                   log,
+			Relays:                relays,
+			GenesisForkVersionHex: genesisForkVersionMainnet,
+		})
+		require.NoError(t, err)
+		return service
+	}
+	defer log.Logger.SetLevel(logrus.InfoLevel)
+
+	t.Run("Applies the config file", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "relays: ["+testRelayA+"]\nloglevel: info\n")
+		require.NoError(t, err)
+		service := newService(t)
+
+		require.NoError(t, os.WriteFile(cmd.String(configFlag.Name), []byte("relays: ["+testRelayB+"]\nloglevel: debug\nmin-bid: 0.1\n"), 0o600))
+		require.NoError(t, reloadConfig(cmd, service))
+		require.Equal(t, logrus.DebugLevel, log.Logger.GetLevel())
+
+		require.NoError(t, os.WriteFile(cmd.String(configFlag.Name), []byte("min-bid: -1\n"), 0o600))
+		require.ErrorIs(t, reloadConfig(cmd, service), errNegativeBid)
+
+		require.NoError(t, os.WriteFile(cmd.String(configFlag.Name), []byte("min-bid: 0.1\n"), 0o600))
+		require.Error(t, reloadConfig(cmd, service), "no relays")
+	})
+
+	t.Run("Flags take precedence", func(t *testing.T) {
+		cmd, err := runWithConfig(t, "config.yaml", "loglevel: info\n", "-relay", testRelayA, "-loglevel", "warn")
+		require.NoError(t, err)
+		service := newService(t)
+
+		require.NoError(t, os.WriteFile(cmd.String(configFlag.Name), []byte("loglevel: debug\n"), 0o600))
+		require.NoError(t, reloadConfig(cmd, service))
+		require.Equal(t, logrus.WarnLevel, log.Logger.GetLevel())
+	})
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/urfave/cli/v3"
+)
+
+var errNoBidAuditLog = errors.New("no bid audit log specified, pass the files or set -bid-audit-log")
+
+var (
+	replayAllFlag = &cli.BoolFlag{
+		Name:     "all",
+		Sources:  cli.EnvVars("REPLAY_ALL"),
+		Usage:    "print all auctions, not only those where the replay chooses another bid",
+		Category: ReplayCategory,
+	}
+	replayOutputFlag = &cli.StringFlag{
+		Name:     "output",
+		Sources:  cli.EnvVars("REPLAY_OUTPUT"),
+		Value:    "table",
+		Usage:    "output format: table or json",
+		Category: ReplayCategory,
+	}
+
+	replayCommand = &cli.Command{
+		Name:      "replay",
+		Usage:     "feed the bids of bid audit logs through the bid selection with the configured min-bid, relays, label preferences and tiebreaker, and report which bids would have been chosen",
+		ArgsUsage: "[bid audit log files, -bid-audit-log if none]",
+		Action:    toolAction(runReplay),
+		Flags: []cli.Flag{
+			replayAllFlag,
+			replayOutputFlag,
+		},
+	}
+)
+
+// runReplay replays the auctions of the bid audit logs with the bid selection flags of mev-boost: -min-bid,
+// -min-bid-percent, -min-bid-slots, -bid-tiebreaker, -relay-label-preference and the -relay flags with their options
+func runReplay(_ context.Context, cmd *cli.Command) error {
+	output := cmd.String(replayOutputFlag.Name)
+	if err := checkToolOutput(output); err != nil {
+		return err
+	}
+	files := cmd.Args().Slice()
+	if len(files) == 0 && cmd.String(bidAuditLogFlag.Name) != "" {
+		files = []string{cmd.String(bidAuditLogFlag.Name)}
+	}
+	if len(files) == 0 {
+		return errNoBidAuditLog
+	}
+
+	relays, err := parseRelayURLs(cmd.StringSlice(relaysFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid relay URL: %w", err)
+	}
+	minBid, err := sanitizeMinBid(cmd.Float(minBidFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid min-bid: %w", err)
+	}
+	opts := tools.BidReplayOpts{
+		Relays:                relays,
+		RelayMinBid:           *minBid,
+		RelayMinBidPercent:    setupMinBidPercent(cmd),
+		RelayMinBidSlots:      int(cmd.Int(minBidSlotsFlag.Name)),
+		BidTiebreaker:         cmd.String(bidTiebreakerFlag.Name),
+		RelayLabelPreferences: setupLabelPreferences(cmd),
+	}
+
+	// The files in the given order, i.e. the rotated logs first
+	var records []server.BidAuditRecord
+	for _, file := range files {
+		fileRecords, err := readBidAuditLogFile(file)
+		if err != nil {
+			return err
+		}
+		records = append(records, fileRecords...)
+	}
+	slots, err := tools.ReplayBids(records, opts)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return printJSON(cmd.Writer, slots)
+	}
+	printBidReplay(cmd.Writer, slots, len(records), cmd.Bool(replayAllFlag.Name))
+	return nil
+}
+
+func readBidAuditLogFile(name string) ([]server.BidAuditRecord, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := tools.ReadBidAuditLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid audit log %s: %w", name, err)
+	}
+	return records, nil
+}
+
+// printBidReplay writes the summary of the replay and the table of the auctions where the replay chooses another bid,
+// or of all auctions
+func printBidReplay(w io.Writer, slots []tools.BidReplaySlot, bids int, all bool) {
+	changed, lost, won := 0, 0, 0
+	delta := new(big.Int)
+	table := newTable(w, "SLOT", "MIN BID (ETH)", "RECORDED", "VALUE (ETH)", "REPLAYED", "VALUE (ETH)", "RELAYS", "DELTA (ETH)")
+	rows := 0
+	for _, slot := range slots {
+		slotDelta := new(big.Int).Sub(replayChoiceValue(slot.Replayed), replayChoiceValue(slot.Recorded))
+		if slot.Changed() {
+			changed++
+			delta.Add(delta, slotDelta)
+			switch {
+			case slot.Replayed == nil:
+				lost++
+			case slot.Recorded == nil:
+				won++
+			}
+		}
+		if !slot.Changed() && !all {
+			continue
+		}
+		recordedHash, recordedValue := replayChoiceColumns(slot.Recorded)
+		replayedHash, replayedValue := replayChoiceColumns(slot.Replayed)
+		relays := "-"
+		if slot.Replayed != nil {
+			relays = strings.Join(slot.Replayed.Relays, ",")
+		}
+		minBid, _ := new(big.Int).SetString(slot.MinBid, 10)
+		table.row(
+			strconv.FormatUint(slot.Slot, 10), formatWeiEth(minBid, false), recordedHash, recordedValue, replayedHash, replayedValue,
+			relays, formatWeiEth(slotDelta, true),
+		)
+		rows++
+	}
+	if rows > 0 {
+		table.flush()
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "replayed %d auctions with %d bids: %d unchanged, %d with another bid (%d without a bid, %d with a bid instead of none)\n",
+		len(slots), bids, len(slots)-changed, changed, lost, won)
+	fmt.Fprintf(w, "value difference of the changed auctions: %s ETH\n", formatWeiEth(delta, true))
+}
+
+// replayChoiceValue returns the value of the chosen bid in wei, 0 without a bid
+func replayChoiceValue(choice *tools.BidReplayChoice) *big.Int {
+	if choice == nil {
+		return new(big.Int)
+	}
+	value, ok := new(big.Int).SetString(choice.Value, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return value
+}
+
+// replayChoiceColumns returns the abbreviated block hash and the value in ETH of the chosen bid, "-" without a bid
+func replayChoiceColumns(choice *tools.BidReplayChoice) (string, string) {
+	if choice == nil {
+		return "-", "-"
+	}
+	return shortHex(choice.BlockHash), formatWeiEth(replayChoiceValue(choice), false)
+}
+
+// formatWeiEth returns the wei value in ETH with 6 decimals, with the sign if signed
+func formatWeiEth(wei *big.Int, signed bool) string {
+	if wei == nil {
+		wei = new(big.Int)
+	}
+	eth := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+	if signed {
+		return fmt.Sprintf("%+.6f", eth)
+	}
+	return eth.Text('f', 6)
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintBidReplay(t *testing.T) {
+	const (
+		blockA = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		blockB = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+	choice := func(blockHash, value string) *tools.BidReplayChoice {
+		return &tools.BidReplayChoice{BlockHash: blockHash, Value: value, Relays: []string{"relay-a", "relay-b"}}
+	}
+	slots := []tools.BidReplaySlot{
+		{Slot: 10, MinBid: "0", Recorded: choice(blockA, "60000000000000000"), Replayed: choice(blockA, "60000000000000000")},
+		{Slot: 11, MinBid: "0", Recorded: choice(blockA, "60000000000000000"), Replayed: choice(blockB, "55000000000000000")},
+		{Slot: 12, MinBid: "50000000000000000", Recorded: choice(blockB, "30000000000000000")},
+	}
+
+	t.Run("Changed", func(t *testing.T) {
+		var out bytes.Buffer
+		printBidReplay(&out, slots, 5, false)
+		lines := strings.Split(out.String(), "\n")
+		require.Equal(t, []string{"SLOT", "MIN", "BID", "(ETH)", "RECORDED", "VALUE", "(ETH)", "REPLAYED", "VALUE", "(ETH)", "RELAYS", "DELTA", "(ETH)"}, strings.Fields(lines[0]))
+		require.Equal(t, []string{"11", "0.000000", "0xaaaaaaaa...aaaaaaaa", "0.060000", "0xbbbbbbbb...bbbbbbbb", "0.055000", "relay-a,relay-b", "-0.005000"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"12", "0.050000", "0xbbbbbbbb...bbbbbbbb", "0.030000", "-", "-", "-", "-0.030000"}, strings.Fields(lines[2]))
+		require.Equal(t, "replayed 3 auctions with 5 bids: 1 unchanged, 2 with another bid (1 without a bid, 0 with a bid instead of none)", lines[4])
+		require.Equal(t, "value difference of the changed auctions: -0.035000 ETH", lines[5])
+	})
+
+	t.Run("All", func(t *testing.T) {
+		var out bytes.Buffer
+		printBidReplay(&out, slots, 5, true)
+		lines := strings.Split(out.String(), "\n")
+		require.Equal(t, "10", strings.Fields(lines[1])[0])
+		require.Equal(t, "+0.000000", strings.Fields(lines[1])[7])
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		var out bytes.Buffer
+		printBidReplay(&out, slots[:1], 1, false)
+		require.True(t, strings.HasPrefix(out.String(), "replayed 1 auctions"))
+	})
+}
+
+func TestReadBidAuditLogFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "bids.jsonl")
+	require.NoError(t, os.WriteFile(name, []byte(`{"slot":1,"value":"100","selected":true}`+"\ninvalid\n"), 0o600))
+	_, err := readBidAuditLogFile(name)
+	require.ErrorContains(t, err, "invalid bid audit log "+name+": line 2")
+
+	_, err = readBidAuditLogFile(filepath.Join(t.TempDir(), "missing.jsonl"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/flashbots/mev-boost/server"
+)
+
+// serveUntilShutdown serves the builder API until the process receives SIGTERM or SIGINT, then drains the requests of
+// the slot in flight within the drain timeout. A second signal exits right away.
+func serveUntilShutdown(service *server.BoostService, drainTimeout time.Duration) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	errc := make(chan error, 1)
+	go func() { errc <- service.StartHTTPServer() }()
+	select {
+	case err := <-errc:
+		return err
+	case sig := <-signals:
+		log.Infof("received %s, shutting down within %s", sig, drainTimeout)
+	}
+
+	go func() {
+		sig := <-signals
+		log.Warnf("received %s again, exiting without draining", sig)
+		os.Exit(1)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	return service.Shutdown(ctx)
+}
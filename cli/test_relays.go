@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/urfave/cli/v3"
+)
+
+var errRelayTestsFailed = errors.New("relay checks failed")
+
+// testRelaysColumns are the probe steps in the columns of the test-relays table
+var testRelaysColumns = []string{tools.RelayProbeDNS, tools.RelayProbeTLS, tools.RelayProbeStatus, tools.RelayProbePubkey, tools.RelayProbeJSON, tools.RelayProbeSSZ}
+
+var (
+	testRelaysTimeoutFlag = &cli.DurationFlag{
+		Name:     "timeout",
+		Sources:  cli.EnvVars("TEST_RELAYS_TIMEOUT"),
+		Value:    5 * time.Second,
+		Usage:    "timeout of each request to the relays",
+		Category: TestRelaysCategory,
+	}
+
+	testRelaysCommand = &cli.Command{
+		Name:   "test-relays",
+		Usage:  "check the DNS, TLS, status endpoint, public key, latency and JSON and SSZ support of the configured relays",
+		Action: toolAction(runTestRelays),
+		Flags:  []cli.Flag{testRelaysTimeoutFlag},
+	}
+)
+
+// testedRelay is a relay flag value, with the parse error if it isn't a valid relay URL
+type testedRelay struct {
+	relay types.RelayEntry
+	err   error
+}
+
+// runTestRelays probes the relays of the -relay flags with the relay client settings of mev-boost, prints the results,
+// and fails if a check of a relay failed
+func runTestRelays(ctx context.Context, cmd *cli.Command) error {
+	// The relays are tested one by one, an invalid relay URL doesn't stop the checks of the others
+	var tested []testedRelay
+	var relays []types.RelayEntry
+	for _, url := range splitRelayURLs(cmd.StringSlice(relaysFlag.Name)) {
+		relay, err := types.NewRelayEntry(url)
+		tested = append(tested, testedRelay{relay: relay, err: err})
+		if err == nil {
+			relays = append(relays, relay)
+		}
+	}
+	if len(tested) == 0 {
+		return errNoRelays
+	}
+
+	probes, err := tools.ProbeRelays(ctx, relays, tools.RelayProbeOpts{
+		Timeout:     cmd.Duration(testRelaysTimeoutFlag.Name),
+		RelayClient: toolRelayClient(cmd),
+	})
+	if err != nil {
+		return err
+	}
+	if printRelayProbes(cmd.Writer, tested, probes) {
+		return errRelayTestsFailed
+	}
+	return nil
+}
+
+// printRelayProbes writes the table of the probe results followed by the details of the steps and the failures, and
+// returns whether a relay failed. The probes are those of the valid relays, in order.
+func printRelayProbes(w io.Writer, tested []testedRelay, probes []tools.RelayProbe) bool {
+	header := []string{"RELAY"}
+	for _, column := range testRelaysColumns {
+		header = append(header, strings.ToUpper(column))
+	}
+	table := newTable(w, append(header, "LATENCY")...)
+
+	var details, failures []string
+	for i, t := range tested {
+		if t.err != nil {
+			// The URL isn't printed, it might not be a relay URL but a secret passed to the wrong flag
+			name := fmt.Sprintf("#%d", i+1)
+			row := []string{name}
+			for range len(testRelaysColumns) + 1 {
+				row = append(row, tools.RelayProbeSkipped)
+			}
+			table.row(row...)
+			failures = append(failures, fmt.Sprintf("%s: invalid relay URL: %s", name, t.err))
+			continue
+		}
+		probe := probes[0]
+		probes = probes[1:]
+
+		name := probe.Relay.URL.Host
+		row := []string{name}
+		for _, column := range testRelaysColumns {
+			step := probe.Step(column)
+			row = append(row, step.Result)
+			switch {
+			case step.Err != nil && step.Result == tools.RelayProbeFailed:
+				failures = append(failures, fmt.Sprintf("%s: %s: %s", name, column, step.Err))
+			case step.Detail != "":
+				details = append(details, fmt.Sprintf("%s: %s: %s", name, column, step.Detail))
+			}
+		}
+		latency := tools.RelayProbeSkipped
+		if probe.Latency > 0 {
+			latency = tools.FormatLatency(probe.Latency)
+		}
+		table.row(append(row, latency)...)
+	}
+	table.flush()
+
+	for _, line := range append(details, failures...) {
+		fmt.Fprintln(w, line)
+	}
+	return len(failures) > 0
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/internal/tools"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintRelayProbes(t *testing.T) {
+	relay, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay.example.com")
+	require.NoError(t, err)
+	steps := func(ssz string, sszErr error) []tools.RelayProbeStep {
+		return []tools.RelayProbeStep{
+			{Name: tools.RelayProbePubkey, Result: tools.RelayProbeOK},
+			{Name: tools.RelayProbeDNS, Result: tools.RelayProbeOK},
+			{Name: tools.RelayProbeTLS, Result: tools.RelayProbeOK, Detail: "TLS 1.3, expires 2027-01-01"},
+			{Name: tools.RelayProbeStatus, Result: tools.RelayProbeOK},
+			{Name: tools.RelayProbeJSON, Result: tools.RelayProbeOK},
+			{Name: tools.RelayProbeSSZ, Result: ssz, Err: sszErr},
+		}
+	}
+
+	t.Run("Passing relays", func(t *testing.T) {
+		var out bytes.Buffer
+		probes := []tools.RelayProbe{{Relay: relay, Steps: steps(tools.RelayProbeUnsupported, errors.New("rejected")), Latency: 42 * time.Millisecond}}
+		failed := printRelayProbes(&out, []testedRelay{{relay: relay}}, probes)
+		require.False(t, failed)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Equal(t, []string{"RELAY", "DNS", "TLS", "STATUS", "PUBKEY", "JSON", "SSZ", "LATENCY"}, strings.Fields(lines[0]))
+		require.Equal(t, []string{"relay.example.com", "ok", "ok", "ok", "ok", "ok", "no", "42.00ms"}, strings.Fields(lines[1]))
+		require.Equal(t, "relay.example.com: tls: TLS 1.3, expires 2027-01-01", lines[2])
+	})
+
+	t.Run("Failing relays", func(t *testing.T) {
+		var out bytes.Buffer
+		tested := []testedRelay{{err: errors.New("invalid relay public key")}, {relay: relay}}
+		probes := []tools.RelayProbe{{Relay: relay, Steps: steps(tools.RelayProbeFailed, errors.New("relay rejected the encoding"))}}
+		failed := printRelayProbes(&out, tested, probes)
+		require.True(t, failed)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 6)
+		require.Equal(t, []string{"#1", "-", "-", "-", "-", "-", "-", "-"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"relay.example.com", "ok", "ok", "ok", "ok", "ok", "FAIL", "-"}, strings.Fields(lines[2]))
+		require.Equal(t, "#1: invalid relay URL: invalid relay public key", lines[4])
+		require.Equal(t, "relay.example.com: ssz: relay rejected the encoding", lines[5])
+	})
+}
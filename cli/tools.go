@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	errNoRelays   = errors.New("no relays specified, set them with -relay")
+	errToolOutput = errors.New("invalid output, expected table or json")
+)
+
+// toolAction returns the action of a subcommand, which sets up the logging before running
+func toolAction(run cli.ActionFunc) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		if err := setupLogging(cmd); err != nil {
+			log.WithError(err).Fatal("failed setting up logging")
+		}
+		return run(ctx, cmd)
+	}
+}
+
+// toolRelays returns the relays of the -relay flags, at least one
+func toolRelays(cmd *cli.Command) ([]types.RelayEntry, error) {
+	relays, err := parseRelayURLs(cmd.StringSlice(relaysFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid relay URL: %w", err)
+	}
+	if len(relays) == 0 {
+		return nil, errNoRelays
+	}
+	return relays, nil
+}
+
+// toolRelayClient returns the relay client settings of the relay flags
+func toolRelayClient(cmd *cli.Command) server.RelayClientOpts {
+	return server.RelayClientOpts{
+		ClientCert:          cmd.String(relayClientCertFlag.Name),
+		ClientKey:           cmd.String(relayClientKeyFlag.Name),
+		DNSResolver:         cmd.String(relayDNSResolverFlag.Name),
+		DisableHTTP2:        !cmd.Bool(relayHTTP2Flag.Name),
+		MaxIdleConnsPerHost: int(cmd.Int(relayMaxIdleConnsFlag.Name)),
+		IdleConnTimeout:     cmd.Duration(relayIdleConnTimeoutFlag.Name),
+		KeepAlive:           cmd.Duration(relayTCPKeepAliveFlag.Name),
+	}
+}
+
+// checkToolOutput checks the output format of a subcommand, table or json
+func checkToolOutput(output string) error {
+	if output != "table" && output != "json" {
+		return fmt.Errorf("%w: %s", errToolOutput, output)
+	}
+	return nil
+}
+
+// printJSON writes the indented JSON of the subcommand results
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// table writes rows of columns aligned, once flushed
+type table struct {
+	w *tabwriter.Writer
+}
+
+// newTable returns a table with the header columns
+func newTable(w io.Writer, header ...string) table {
+	t := table{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	t.row(header...)
+	return t
+}
+
+func (t table) row(columns ...string) {
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+}
+
+func (t table) flush() {
+	_ = t.w.Flush()
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/flashbots/mev-boost/config"
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// setupTracing registers an OTLP/HTTP trace exporter as global tracer provider if an endpoint is configured.
+// The returned function flushes and stops the exporter.
+func setupTracing(ctx context.Context, cmd *cli.Command) (func(context.Context) error, error) {
+	endpoint := cmd.String(otlpEndpointFlag.Name)
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint)}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cmd.String(otlpServiceNameFlag.Name)),
+		semconv.ServiceVersion(config.Version),
+	)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cmd.Float(otlpSampleRatioFlag.Name)))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	log.Infof("exporting traces to %s", endpoint)
+	return provider.Shutdown, nil
+}
@@ -3,9 +3,12 @@ package cli
 import (
 	"errors"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
 )
 
 var errDuplicateEntry = errors.New("duplicate entry")
@@ -37,6 +40,68 @@ func (r *relayList) Set(value string) error {
 	return nil
 }
 
+// relayOptionFields returns the relay options which are set, for logging
+func relayOptionFields(relay types.RelayEntry) logrus.Fields {
+	fields := logrus.Fields{}
+	timeouts := map[string]time.Duration{
+		"timeoutGetHeader":  relay.TimeoutGetHeader,
+		"timeoutGetPayload": relay.TimeoutGetPayload,
+		"timeoutRegVal":     relay.TimeoutRegVal,
+		"retryBackoff":      relay.RetryBackoff,
+		"retryJitter":       relay.RetryJitter,
+	}
+	for name, timeout := range timeouts {
+		if timeout > 0 {
+			fields[name] = timeout.String()
+		}
+	}
+	if relay.Tier > 0 {
+		fields["tier"] = relay.Tier
+	}
+	if relay.Weight > 0 {
+		fields["weight"] = relay.Weight
+	}
+	if relay.SignatureCheck != "" {
+		fields["signatureCheck"] = relay.SignatureCheck
+	}
+	if relay.Backup {
+		fields["backup"] = true
+	}
+	if relay.BoostFactor > 0 {
+		fields["boostFactor"] = relay.BoostFactor
+	}
+	if relay.Constraints {
+		fields["constraints"] = true
+	}
+	if len(relay.Labels) > 0 {
+		fields["labels"] = relay.Labels
+	}
+	if relay.SSZ {
+		fields["ssz"] = true
+	}
+	if relay.MaxRetries > 0 {
+		fields["maxRetries"] = relay.MaxRetries
+	}
+	if relay.ClientCert != "" {
+		fields["clientCert"] = relay.ClientCert
+	}
+	if relay.Proxy != nil {
+		fields["proxy"] = relay.Proxy.Redacted()
+	}
+	if relay.BasicAuth != nil {
+		fields["basicAuth"] = relay.BasicAuth.Username() + ":xxxxx"
+	}
+	if len(relay.Headers) > 0 {
+		headers := make([]string, 0, len(relay.Headers))
+		for name := range relay.Headers {
+			headers = append(headers, name+": xxxxx")
+		}
+		slices.Sort(headers)
+		fields["headers"] = headers
+	}
+	return fields
+}
+
 type relayMonitorList []*url.URL
 
 func (rm *relayMonitorList) String() string {
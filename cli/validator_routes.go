@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+var (
+	errInvalidValidatorRoute = errors.New("invalid validator route")
+	errInvalidRelayFilter    = errors.New("invalid relay filter")
+)
+
+// validatorRoutesFile is the format of the validator routes file, i.e.
+//
+//	routes:
+//	  - name: client-a
+//	    relays: [https://0x...@relay.example.com]
+//	    min-bid: 0.05
+//	    validators: [0x8a1d...]
+//	relay-filters:
+//	  - relay: https://0x...@relay.example.com
+//	    deny: [0x8a1d...]
+type validatorRoutesFile struct {
+	Routes       []validatorRouteConfig `yaml:"routes"        toml:"routes"`
+	RelayFilters []relayFilterConfig    `yaml:"relay-filters" toml:"relay-filters"`
+}
+
+// validatorRouteConfig is a route of the validator routes file. Relays can be given as URL or as object with an url
+// field and the relay options, like in the config file.
+type validatorRouteConfig struct {
+	Name       string   `yaml:"name"       toml:"name"`
+	Relays     []any    `yaml:"relays"     toml:"relays"`
+	MinBid     *float64 `yaml:"min-bid"    toml:"min-bid"`
+	Validators []string `yaml:"validators" toml:"validators"`
+}
+
+// relayFilterConfig is a relay filter of the validator routes file: only the allowed validators (all if none) which are
+// not denied use the relay
+type relayFilterConfig struct {
+	Relay string   `yaml:"relay" toml:"relay"`
+	Allow []string `yaml:"allow" toml:"allow"`
+	Deny  []string `yaml:"deny"  toml:"deny"`
+}
+
+// readValidatorRoutes reads the validator routes file, a YAML or TOML file mapping validator pubkeys to the relays and
+// min bid used for them, and restricting which validators use some relays
+func readValidatorRoutes(path string) (server.ValidatorRoutes, server.RelayFilters, error) {
+	var file validatorRoutesFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed reading validator routes file %s: %w", path, err)
+	}
+
+	routes := make(server.ValidatorRoutes)
+	for i, config := range file.Routes {
+		name := config.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		route, err := parseValidatorRoute(name, config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w %s: %w", errInvalidValidatorRoute, name, err)
+		}
+		if len(config.Validators) == 0 {
+			return nil, nil, fmt.Errorf("%w %s: no validators", errInvalidValidatorRoute, name)
+		}
+		for _, validator := range config.Validators {
+			pubkey, err := utils.HexToPubkey(validator)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w %s: invalid validator pubkey %s: %w", errInvalidValidatorRoute, name, validator, err)
+			}
+			if other, ok := routes[pubkey]; ok {
+				return nil, nil, fmt.Errorf("%w %s: validator %s is already routed by %s", errInvalidValidatorRoute, name, validator, other.Name)
+			}
+			routes[pubkey] = route
+		}
+	}
+
+	filters := make(server.RelayFilters)
+	for _, config := range file.RelayFilters {
+		relay, err := types.NewRelayEntry(config.Relay)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w %s: %w", errInvalidRelayFilter, config.Relay, err)
+		}
+		if _, ok := filters[relay.PublicKey]; ok {
+			return nil, nil, fmt.Errorf("%w %s: duplicate relay", errInvalidRelayFilter, config.Relay)
+		}
+		filter := &server.RelayFilter{}
+		if filter.Allow, err = parseValidatorSet(config.Allow); err != nil {
+			return nil, nil, fmt.Errorf("%w %s: %w", errInvalidRelayFilter, config.Relay, err)
+		}
+		if filter.Deny, err = parseValidatorSet(config.Deny); err != nil {
+			return nil, nil, fmt.Errorf("%w %s: %w", errInvalidRelayFilter, config.Relay, err)
+		}
+		if len(filter.Allow) == 0 && len(filter.Deny) == 0 {
+			return nil, nil, fmt.Errorf("%w %s: no validators", errInvalidRelayFilter, config.Relay)
+		}
+		filters[relay.PublicKey] = filter
+	}
+	return routes, filters, nil
+}
+
+// parseValidatorSet parses a list of validator pubkeys
+func parseValidatorSet(validators []string) (map[phase0.BLSPubKey]bool, error) {
+	ret := make(map[phase0.BLSPubKey]bool, len(validators))
+	for _, validator := range validators {
+		pubkey, err := utils.HexToPubkey(validator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator pubkey %s: %w", validator, err)
+		}
+		ret[pubkey] = true
+	}
+	return ret, nil
+}
+
+func parseValidatorRoute(name string, config validatorRouteConfig) (*server.ValidatorRoute, error) {
+	route := &server.ValidatorRoute{Name: name}
+	if len(config.Relays) > 0 {
+		urls, err := relayConfigURLs(config.Relays)
+		if err != nil {
+			return nil, err
+		}
+		relayURLs, err := configValues(urls)
+		if err != nil {
+			return nil, err
+		}
+		if route.Relays, err = parseRelayURLs(relayURLs); err != nil {
+			return nil, err
+		}
+	}
+	if config.MinBid != nil {
+		minBid, err := sanitizeMinBid(*config.MinBid)
+		if err != nil {
+			return nil, err
+		}
+		route.RelayMinBid = minBid
+	}
+	return route, nil
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testValidatorA = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	testValidatorB = "0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae"
+)
+
+func TestReadValidatorRoutes(t *testing.T) {
+	writeRoutes := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	validatorA, err := utils.HexToPubkey(testValidatorA)
+	require.NoError(t, err)
+	validatorB, err := utils.HexToPubkey(testValidatorB)
+	require.NoError(t, err)
+
+	t.Run("YAML", func(t *testing.T) {
+		path := writeRoutes(t, "routes.yaml", `
+routes:
+  - name: client-a
+    relays:
+      - `+testRelayA+`
+      - url: `+testRelayB+`
+        timeout_get_header: 500ms
+    min-bid: 0.05
+    validators: [`+testValidatorA+`]
+  - name: client-b
+    min-bid: 0.1
+    validators: [`+testValidatorB+`]
+`)
+		routes, _, err := readValidatorRoutes(path)
+		require.NoError(t, err)
+		require.Len(t, routes, 2)
+
+		routeA := routes[validatorA]
+		require.Equal(t, "client-a", routeA.Name)
+		require.Len(t, routeA.Relays, 2)
+		require.Equal(t, 500*time.Millisecond, routeA.Relays[1].TimeoutGetHeader)
+		require.Equal(t, "50000000000000000", routeA.RelayMinBid.String())
+
+		routeB := routes[validatorB]
+		require.Equal(t, "client-b", routeB.Name)
+		require.Empty(t, routeB.Relays)
+		require.Equal(t, "100000000000000000", routeB.RelayMinBid.String())
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		path := writeRoutes(t, "routes.toml", `
+[[routes]]
+relays = ["`+testRelayB+`"]
+validators = ["`+testValidatorA+`", "`+testValidatorB+`"]
+`)
+		routes, _, err := readValidatorRoutes(path)
+		require.NoError(t, err)
+		require.Len(t, routes, 2)
+		require.Same(t, routes[validatorA], routes[validatorB])
+		require.Equal(t, "#1", routes[validatorA].Name)
+		require.Nil(t, routes[validatorA].RelayMinBid)
+	})
+
+	t.Run("Invalid routes", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"duplicate validator": "routes:\n  - validators: [" + testValidatorA + "]\n  - validators: [" + testValidatorA + "]\n",
+			"invalid pubkey":      "routes:\n  - validators: [0x1234]\n",
+			"no validators":       "routes:\n  - relays: [" + testRelayA + "]\n",
+			"unknown key":         "routes:\n  - validators: [" + testValidatorA + "]\n    priority: 1\n",
+			"invalid relay":       "routes:\n  - relays: [relay.example.com]\n    validators: [" + testValidatorA + "]\n",
+			"negative min-bid":    "routes:\n  - min-bid: -1\n    validators: [" + testValidatorA + "]\n",
+			"filter validators":   "relay-filters:\n  - relay: " + testRelayA + "\n",
+			"filter relay":        "relay-filters:\n  - relay: relay.example.com\n    deny: [" + testValidatorA + "]\n",
+			"filter pubkey":       "relay-filters:\n  - relay: " + testRelayA + "\n    allow: [0x1234]\n",
+			"duplicate filter":    "relay-filters:\n  - relay: " + testRelayA + "\n    deny: [" + testValidatorA + "]\n  - relay: " + testRelayA + "\n    deny: [" + testValidatorB + "]\n",
+		} {
+			_, _, err := readValidatorRoutes(writeRoutes(t, "routes.yaml", content))
+			require.Error(t, err, name)
+		}
+	})
+
+	t.Run("Relay filters", func(t *testing.T) {
+		path := writeRoutes(t, "routes.yaml", `
+relay-filters:
+  - relay: `+testRelayA+`
+    allow: [`+testValidatorA+`]
+  - relay: `+testRelayB+`
+    deny: [`+testValidatorA+`]
+`)
+		routes, filters, err := readValidatorRoutes(path)
+		require.NoError(t, err)
+		require.Empty(t, routes)
+		require.Len(t, filters, 2)
+
+		relayA, err := utils.HexToPubkey(testRelayA[8:106])
+		require.NoError(t, err)
+		require.Equal(t, map[phase0.BLSPubKey]bool{validatorA: true}, filters[relayA].Allow)
+		require.Empty(t, filters[relayA].Deny)
+	})
+}
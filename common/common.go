@@ -10,6 +10,7 @@ import (
 
 const (
 	SlotTimeSecMainnet = 12
+	SlotTimeSecGnosis  = 5
 )
 
 func GetEnv(key, defaultValue string) string {
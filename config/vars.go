@@ -28,7 +28,8 @@ var (
 	// ServerMaxHeaderBytes defines the max header byte size for requests (for dos prevention)
 	ServerMaxHeaderBytes = common.GetEnvInt("MAX_HEADER_BYTES", 4000)
 
-	// SkipRelaySignatureCheck can be used to disable relay signature check
+	// SkipRelaySignatureCheck can be used to disable relay signature check.
+	// Deprecated: use the -relay-signature-check flag or the signature_check relay option.
 	SkipRelaySignatureCheck = os.Getenv("SKIP_RELAY_SIGNATURE_CHECK") == "1"
 
 	SlotTimeSec = uint64(common.GetEnvInt("SLOT_SEC", common.SlotTimeSecMainnet))
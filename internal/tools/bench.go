@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// The endpoints of a relay benchmark
+const (
+	RelayBenchGetHeader         = "getHeader"
+	RelayBenchRegisterValidator = "registerValidator"
+)
+
+// relayBenchGasLimit is the gas limit of the validator registrations of a benchmark
+const relayBenchGasLimit = 36_000_000
+
+var (
+	errRelayBenchDuration = errors.New("benchmark duration must be positive")
+	errRelayBenchLoad     = errors.New("benchmark concurrency and registrations must not be negative, and an endpoint must have concurrent requests")
+)
+
+// RelayBenchOpts are the load of a relay benchmark and the relay client settings
+type RelayBenchOpts struct {
+	Duration time.Duration
+	Timeout  time.Duration // of each request
+
+	// Concurrent requests of each endpoint per relay, the endpoint isn't benchmarked if zero
+	GetHeaderConcurrency         int
+	RegisterValidatorConcurrency int
+	// Registrations is the number of validator registrations of each registerValidator request, signed by random keys.
+	// The getHeader requests are for the validator of the first registration.
+	Registrations int
+
+	GenesisForkVersionHex string
+	GenesisTime           uint64
+	SlotTimeSec           uint64
+
+	RelayClient server.RelayClientOpts
+}
+
+// RelayBench are the results of benchmarking an endpoint of a relay
+type RelayBench struct {
+	Relay     types.RelayEntry
+	Endpoint  string
+	Requests  int
+	Errors    int
+	LastError error
+	Latencies []time.Duration // sorted latencies of the successful requests
+	Elapsed   time.Duration
+}
+
+// Percentile returns the nearest-rank percentile p (0 to 1) of the successful requests, zero if none succeeded
+func (b RelayBench) Percentile(p float64) time.Duration {
+	if len(b.Latencies) == 0 {
+		return 0
+	}
+	return percentile(b.Latencies, p)
+}
+
+// Throughput returns the requests per second
+func (b RelayBench) Throughput() float64 {
+	if b.Elapsed <= 0 {
+		return 0
+	}
+	return float64(b.Requests) / b.Elapsed.Seconds()
+}
+
+// BenchRelays sends concurrent getHeader and registerValidator requests to the relays for the duration of the
+// benchmark, with the relay client settings of mev-boost, and returns the results by relay and endpoint. Each worker
+// sends its next request once the previous one finished. The getHeader requests are for the current slot with a
+// random parent hash, so real relays respond without a bid. The results are in the order of the relays.
+func BenchRelays(ctx context.Context, relays []types.RelayEntry, opts RelayBenchOpts) ([]RelayBench, error) {
+	if opts.Duration <= 0 {
+		return nil, errRelayBenchDuration
+	}
+	if opts.GetHeaderConcurrency < 0 || opts.RegisterValidatorConcurrency < 0 || opts.Registrations < 0 ||
+		opts.GetHeaderConcurrency+opts.RegisterValidatorConcurrency == 0 {
+		return nil, errRelayBenchLoad
+	}
+	registrations, err := benchRegistrations(opts)
+	if err != nil {
+		return nil, err
+	}
+	pubkey := registrations[0].Message.Pubkey
+	var parentHash phase0.Hash32
+	_, _ = rand.Read(parentHash[:])
+
+	clients, err := server.NewRelayClients(opts.RelayClient, relays)
+	if err != nil {
+		return nil, err
+	}
+	defer clients.CloseIdleConnections()
+
+	type endpoint struct {
+		name        string
+		concurrency int
+		send        func(ctx context.Context, client http.Client, relay types.RelayEntry) (int, error)
+	}
+	endpoints := []endpoint{
+		{name: RelayBenchGetHeader, concurrency: opts.GetHeaderConcurrency, send: func(ctx context.Context, client http.Client, relay types.RelayEntry) (int, error) {
+			slot := benchSlot(time.Now(), opts.GenesisTime, opts.SlotTimeSec)
+			url := relay.GetURI(fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash.String(), pubkey.String()))
+			return server.SendRelayRequest(ctx, client, relay, http.MethodGet, url, nil, new(builderSpec.VersionedSignedBuilderBid))
+		}},
+		{name: RelayBenchRegisterValidator, concurrency: opts.RegisterValidatorConcurrency, send: func(ctx context.Context, client http.Client, relay types.RelayEntry) (int, error) {
+			return server.SendRelayRequest(ctx, client, relay, http.MethodPost, relay.GetURI(params.PathRegisterValidator), registrations[:opts.Registrations], nil)
+		}},
+	}
+
+	var results []*RelayBench
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+	var wg sync.WaitGroup
+	start := time.Now()
+	for _, relay := range relays {
+		client := clients.Client(relay, opts.Timeout)
+		for _, e := range endpoints {
+			if e.concurrency <= 0 {
+				continue
+			}
+			result := &RelayBench{Relay: relay, Endpoint: e.name}
+			results = append(results, result)
+			var mu sync.Mutex
+			for range e.concurrency {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for ctx.Err() == nil {
+						requestStart := time.Now()
+						_, err := e.send(ctx, client, relay)
+						latency := time.Since(requestStart)
+						if ctx.Err() != nil {
+							// Requests cut off by the end of the benchmark aren't counted
+							return
+						}
+						mu.Lock()
+						result.Requests++
+						if err != nil {
+							result.Errors++
+							result.LastError = err
+						} else {
+							result.Latencies = append(result.Latencies, latency)
+						}
+						mu.Unlock()
+					}
+				}()
+			}
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	benches := make([]RelayBench, len(results))
+	for i, result := range results {
+		slices.Sort(result.Latencies)
+		result.Elapsed = elapsed
+		benches[i] = *result
+	}
+	return benches, nil
+}
+
+// benchRegistrations returns the validator registrations of the benchmark, at least one for the getHeader requests
+func benchRegistrations(opts RelayBenchOpts) ([]builderApiV1.SignedValidatorRegistration, error) {
+	domain, err := server.ComputeDomain(ssz.DomainTypeAppBuilder, opts.GenesisForkVersionHex, phase0.Root{}.String())
+	if err != nil {
+		return nil, err
+	}
+	registrations := make([]builderApiV1.SignedValidatorRegistration, max(opts.Registrations, 1))
+	for i := range registrations {
+		secretKey, err := bls.GenerateRandomSecretKey()
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := bls.PublicKeyFromSecretKey(secretKey)
+		if err != nil {
+			return nil, err
+		}
+		var feeRecipient bellatrix.ExecutionAddress
+		_, _ = rand.Read(feeRecipient[:])
+		message := &builderApiV1.ValidatorRegistration{
+			FeeRecipient: feeRecipient,
+			GasLimit:     relayBenchGasLimit,
+			Timestamp:    time.Now(),
+			Pubkey:       phase0.BLSPubKey(bls.PublicKeyToBytes(publicKey)),
+		}
+		signature, err := ssz.SignMessage(message, domain, secretKey)
+		if err != nil {
+			return nil, err
+		}
+		registrations[i] = builderApiV1.SignedValidatorRegistration{Message: message, Signature: signature}
+	}
+	return registrations, nil
+}
+
+// benchSlot returns the current slot, the first slot before genesis
+func benchSlot(now time.Time, genesisTime, slotTimeSec uint64) uint64 {
+	genesis := time.Unix(int64(genesisTime), 0)
+	if slotTimeSec == 0 || now.Before(genesis) {
+		return 0
+	}
+	return uint64(now.Sub(genesis) / (time.Duration(slotTimeSec) * time.Second))
+}
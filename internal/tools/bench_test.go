@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchRelays(t *testing.T) {
+	const pubkey = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	// newRelay returns a relay without bids, recording the number of registrations and the content type of the last
+	// registerValidator request
+	newRelay := func(t *testing.T, registerStatus int, registrations *atomic.Int64, contentType *atomic.Value) types.RelayEntry {
+		t.Helper()
+		router := mux.NewRouter()
+		router.HandleFunc(params.PathGetHeader, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		router.HandleFunc(params.PathRegisterValidator, func(w http.ResponseWriter, req *http.Request) {
+			contentType.Store(req.Header.Get("Content-Type"))
+			if req.Header.Get("Content-Type") == server.MediaTypeJSON {
+				var payload []builderApiV1.SignedValidatorRegistration
+				if err := json.NewDecoder(req.Body).Decode(&payload); err == nil {
+					registrations.Store(int64(len(payload)))
+				}
+			}
+			w.WriteHeader(registerStatus)
+		})
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+		relay, err := types.NewRelayEntry(strings.Replace(ts.URL, "http://", "http://"+pubkey+"@", 1))
+		require.NoError(t, err)
+		return relay
+	}
+	opts := RelayBenchOpts{
+		Duration:                     200 * time.Millisecond,
+		Timeout:                      time.Second,
+		GetHeaderConcurrency:         2,
+		RegisterValidatorConcurrency: 1,
+		Registrations:                3,
+		GenesisForkVersionHex:        "0x00000000",
+	}
+
+	t.Run("Endpoints of all relays", func(t *testing.T) {
+		var registrations atomic.Int64
+		var contentType atomic.Value
+		relays := []types.RelayEntry{
+			newRelay(t, http.StatusOK, &registrations, &contentType),
+			newRelay(t, http.StatusOK, &registrations, &contentType),
+		}
+		benches, err := BenchRelays(context.Background(), relays, opts)
+		require.NoError(t, err)
+		require.Len(t, benches, 4)
+		for i, bench := range benches {
+			require.Equal(t, relays[i/2].String(), bench.Relay.String())
+			require.Equal(t, []string{RelayBenchGetHeader, RelayBenchRegisterValidator}[i%2], bench.Endpoint)
+			require.Positive(t, bench.Requests)
+			require.Zero(t, bench.Errors)
+			require.Len(t, bench.Latencies, bench.Requests)
+			require.True(t, slices.IsSorted(bench.Latencies))
+			require.LessOrEqual(t, bench.Percentile(0.5), bench.Percentile(0.99))
+			require.Equal(t, bench.Latencies[len(bench.Latencies)-1], bench.Percentile(1))
+			require.Positive(t, bench.Throughput())
+		}
+		require.Equal(t, int64(3), registrations.Load())
+		require.Equal(t, server.MediaTypeJSON, contentType.Load())
+	})
+
+	t.Run("Failing requests", func(t *testing.T) {
+		var registrations atomic.Int64
+		var contentType atomic.Value
+		relay := newRelay(t, http.StatusBadRequest, &registrations, &contentType)
+		benches, err := BenchRelays(context.Background(), []types.RelayEntry{relay}, RelayBenchOpts{Duration: 100 * time.Millisecond, RegisterValidatorConcurrency: 1, GenesisForkVersionHex: "0x00000000"})
+		require.NoError(t, err)
+		require.Len(t, benches, 1)
+		require.Equal(t, RelayBenchRegisterValidator, benches[0].Endpoint)
+		require.Positive(t, benches[0].Errors)
+		require.Equal(t, benches[0].Requests, benches[0].Errors)
+		require.ErrorContains(t, benches[0].LastError, "400")
+		require.Zero(t, benches[0].Percentile(0.5))
+	})
+
+	t.Run("SSZ relays", func(t *testing.T) {
+		var registrations atomic.Int64
+		var contentType atomic.Value
+		relay := newRelay(t, http.StatusOK, &registrations, &contentType)
+		relay.SSZ = true
+		benches, err := BenchRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.Zero(t, benches[0].Errors)
+		require.Zero(t, benches[1].Errors)
+		require.Equal(t, server.MediaTypeSSZ, contentType.Load())
+	})
+
+	t.Run("Invalid duration", func(t *testing.T) {
+		_, err := BenchRelays(context.Background(), nil, RelayBenchOpts{})
+		require.ErrorIs(t, err, errRelayBenchDuration)
+	})
+
+	t.Run("Invalid load", func(t *testing.T) {
+		_, err := BenchRelays(context.Background(), nil, RelayBenchOpts{Duration: time.Second})
+		require.ErrorIs(t, err, errRelayBenchLoad)
+		_, err = BenchRelays(context.Background(), nil, RelayBenchOpts{Duration: time.Second, GetHeaderConcurrency: 1, Registrations: -1})
+		require.ErrorIs(t, err, errRelayBenchLoad)
+	})
+}
+
+func TestBenchSlot(t *testing.T) {
+	genesis := time.Unix(1_600_000_000, 0)
+	require.Equal(t, uint64(10), benchSlot(genesis.Add(125*time.Second), 1_600_000_000, 12))
+	require.Equal(t, uint64(0), benchSlot(genesis.Add(-time.Minute), 1_600_000_000, 12))
+	require.Equal(t, uint64(0), benchSlot(genesis, 1_600_000_000, 0))
+}
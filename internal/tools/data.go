@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+var errRelayDataRegistration = errors.New("invalid registration")
+
+// RelayDataOpts are the request timeout and the relay client settings of the data API queries
+type RelayDataOpts struct {
+	Timeout     time.Duration
+	RelayClient server.RelayClientOpts
+}
+
+// BidTrace is a bid trace of the relay data API, of a delivered payload or of a block received from a builder
+type BidTrace struct {
+	Slot                 uint64        `json:"slot,string"`
+	ParentHash           string        `json:"parent_hash"`
+	BlockHash            string        `json:"block_hash"`
+	BuilderPubkey        string        `json:"builder_pubkey"`
+	ProposerPubkey       string        `json:"proposer_pubkey"`
+	ProposerFeeRecipient string        `json:"proposer_fee_recipient"`
+	GasLimit             uint64        `json:"gas_limit,string"`
+	GasUsed              uint64        `json:"gas_used,string"`
+	Value                types.U256Str `json:"value"`
+	BlockNumber          uint64        `json:"block_number,string"`
+	NumTx                uint64        `json:"num_tx,string"`
+	TimestampMs          uint64        `json:"timestamp_ms,string,omitempty"` // when the relay received the block
+}
+
+// RelayBidTrace is a bid trace reported by the relays, by their host
+type RelayBidTrace struct {
+	BidTrace
+	Relays []string `json:"relays"`
+}
+
+// RelayRegistration is a validator registration known to the relays, by their host
+type RelayRegistration struct {
+	Pubkey       string   `json:"pubkey"`
+	FeeRecipient string   `json:"fee_recipient"`
+	GasLimit     uint64   `json:"gas_limit,string"`
+	Timestamp    uint64   `json:"timestamp,string"`
+	Relays       []string `json:"relays"`
+}
+
+// RelayDataError is the error of a relay data API query
+type RelayDataError struct {
+	Relay string `json:"relay"`
+	Error string `json:"error"`
+}
+
+// RelayBidTraces are the merged bid traces of the relays, and the errors of the relays which couldn't be queried
+type RelayBidTraces struct {
+	Traces []RelayBidTrace  `json:"traces"`
+	Errors []RelayDataError `json:"errors,omitempty"`
+}
+
+// RelayRegistrations are the merged registrations of a validator, and the errors of the relays which couldn't be
+// queried or don't know the validator
+type RelayRegistrations struct {
+	Registrations []RelayRegistration `json:"registrations"`
+	Errors        []RelayDataError    `json:"errors,omitempty"`
+}
+
+// QueryDeliveredPayloads returns the bid traces of the payloads the relays delivered, filtered by the query parameters
+// of the data API, i.e. slot or proposer_pubkey, merged by block
+func QueryDeliveredPayloads(ctx context.Context, relays []types.RelayEntry, query url.Values, opts RelayDataOpts) (RelayBidTraces, error) {
+	return queryBidTraces(ctx, relays, params.PathDataProposerPayloadDelivered, query, opts)
+}
+
+// QueryReceivedBlocks returns the bid traces of the blocks the relays received from builders, filtered by the query
+// parameters of the data API, i.e. slot or builder_pubkey, merged by block
+func QueryReceivedBlocks(ctx context.Context, relays []types.RelayEntry, query url.Values, opts RelayDataOpts) (RelayBidTraces, error) {
+	return queryBidTraces(ctx, relays, params.PathDataBuilderBlocksReceived, query, opts)
+}
+
+// QueryValidatorRegistrations returns the registrations of the validator the relays know, merged by their parameters
+func QueryValidatorRegistrations(ctx context.Context, relays []types.RelayEntry, pubkey string, opts RelayDataOpts) (RelayRegistrations, error) {
+	var ret RelayRegistrations
+	responses, err := queryRelayData[builderApiV1.SignedValidatorRegistration](ctx, relays, params.PathDataValidatorRegistration, url.Values{"pubkey": []string{pubkey}}, opts)
+	if err != nil {
+		return ret, err
+	}
+	ret.Registrations = []RelayRegistration{}
+	for _, response := range responses {
+		switch {
+		case response.err != nil:
+			ret.Errors = append(ret.Errors, RelayDataError{Relay: response.relay, Error: response.err.Error()})
+			continue
+		case response.data.Message == nil:
+			ret.Errors = append(ret.Errors, RelayDataError{Relay: response.relay, Error: errRelayDataRegistration.Error()})
+			continue
+		}
+		registration := RelayRegistration{
+			Pubkey:       response.data.Message.Pubkey.String(),
+			FeeRecipient: response.data.Message.FeeRecipient.String(),
+			GasLimit:     response.data.Message.GasLimit,
+			Timestamp:    uint64(response.data.Message.Timestamp.Unix()),
+		}
+		i := slices.IndexFunc(ret.Registrations, func(r RelayRegistration) bool {
+			return r.Pubkey == registration.Pubkey && r.FeeRecipient == registration.FeeRecipient && r.GasLimit == registration.GasLimit && r.Timestamp == registration.Timestamp
+		})
+		if i < 0 {
+			ret.Registrations = append(ret.Registrations, registration)
+			i = len(ret.Registrations) - 1
+		}
+		ret.Registrations[i].Relays = append(ret.Registrations[i].Relays, response.relay)
+	}
+	// The latest registration first
+	slices.SortStableFunc(ret.Registrations, func(a, b RelayRegistration) int { return cmp.Compare(b.Timestamp, a.Timestamp) })
+	return ret, nil
+}
+
+// queryBidTraces queries the bid traces of the data API path from the relays, and merges those of the same block. The
+// traces are sorted by slot and value, the latest and highest first.
+func queryBidTraces(ctx context.Context, relays []types.RelayEntry, path string, query url.Values, opts RelayDataOpts) (RelayBidTraces, error) {
+	ret := RelayBidTraces{Traces: []RelayBidTrace{}}
+	responses, err := queryRelayData[[]BidTrace](ctx, relays, path, query, opts)
+	if err != nil {
+		return ret, err
+	}
+	blocks := make(map[string]int)
+	for _, response := range responses {
+		if response.err != nil {
+			ret.Errors = append(ret.Errors, RelayDataError{Relay: response.relay, Error: response.err.Error()})
+			continue
+		}
+		for _, trace := range response.data {
+			key := strconv.FormatUint(trace.Slot, 10) + "/" + strings.ToLower(trace.BlockHash)
+			i, ok := blocks[key]
+			if !ok {
+				i = len(ret.Traces)
+				blocks[key] = i
+				ret.Traces = append(ret.Traces, RelayBidTrace{BidTrace: trace})
+			}
+			merged := &ret.Traces[i]
+			if trace.TimestampMs > 0 && (merged.TimestampMs == 0 || trace.TimestampMs < merged.TimestampMs) {
+				merged.TimestampMs = trace.TimestampMs
+			}
+			if !slices.Contains(merged.Relays, response.relay) {
+				merged.Relays = append(merged.Relays, response.relay)
+			}
+		}
+	}
+	slices.SortStableFunc(ret.Traces, func(a, b RelayBidTrace) int {
+		if c := cmp.Compare(b.Slot, a.Slot); c != 0 {
+			return c
+		}
+		return b.Value.Cmp(&a.Value)
+	})
+	return ret, nil
+}
+
+// relayDataResponse is the decoded response of a relay to a data API query, or the error
+type relayDataResponse[T any] struct {
+	relay string
+	data  T
+	err   error
+}
+
+// queryRelayData sends the data API query to all relays with the relay client settings, the responses are in the order
+// of the relays
+func queryRelayData[T any](ctx context.Context, relays []types.RelayEntry, path string, query url.Values, opts RelayDataOpts) ([]relayDataResponse[T], error) {
+	clients, err := server.NewRelayClients(opts.RelayClient, relays)
+	if err != nil {
+		return nil, err
+	}
+	defer clients.CloseIdleConnections()
+
+	responses := make([]relayDataResponse[T], len(relays))
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i].relay = relay.URL.Host
+			_, responses[i].err = server.SendHTTPRequest(ctx, clients.Client(relay, opts.Timeout), http.MethodGet, relay.GetURIWithQuery(path, query), "", nil, nil, &responses[i].data)
+		}()
+	}
+	wg.Wait()
+	return responses, nil
+}
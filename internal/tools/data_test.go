@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRelayData(t *testing.T) {
+	const (
+		pubkey    = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+		validator = "0xa7f1b62a1e3d2b0c0bf1f2cda4e6b23ba8d0b4a4d9f2d0fdba9c0d6a1e0f0b2e8f6d5c4b3a2910f0e1d2c3b4a5968778"
+		blockA    = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		blockB    = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	trace := func(slot uint64, blockHash, value string, timestampMs uint64) string {
+		return fmt.Sprintf(`{"slot":"%d","block_hash":"%s","value":"%s","gas_limit":"36000000","gas_used":"1000","block_number":"100","num_tx":"3","timestamp_ms":"%d"}`, slot, blockHash, value, timestampMs)
+	}
+	registration := func(gasLimit uint64) string {
+		return fmt.Sprintf(`{"message":{"fee_recipient":"0xabcf8e0d4e9587369b2301d0790347320302cc09","gas_limit":"%d","timestamp":"1700000000","pubkey":"%s"},"signature":"0x%s"}`, gasLimit, validator, strings.Repeat("00", 96))
+	}
+
+	// newRelay returns a relay responding to the data API path with the body, recording the query of the last request if
+	// query is set
+	newRelay := func(t *testing.T, path, body string, query *url.Values) types.RelayEntry {
+		t.Helper()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != path {
+				http.Error(w, `{"code":400,"message":"no registration found for validator"}`, http.StatusBadRequest)
+				return
+			}
+			if query != nil {
+				*query = req.URL.Query()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+		relay, err := types.NewRelayEntry(strings.Replace(ts.URL, "http://", "http://"+pubkey+"@", 1))
+		require.NoError(t, err)
+		return relay
+	}
+	opts := RelayDataOpts{Timeout: time.Second}
+
+	t.Run("Delivered payloads merged by block", func(t *testing.T) {
+		var query url.Values
+		relays := []types.RelayEntry{
+			newRelay(t, params.PathDataProposerPayloadDelivered, "["+trace(10, blockA, "100", 0)+","+trace(9, blockB, "50", 0)+"]", &query),
+			newRelay(t, params.PathDataProposerPayloadDelivered, "["+trace(10, "0x"+strings.ToUpper(blockA[2:]), "100", 0)+"]", nil),
+			newRelay(t, params.PathDataProposerPayloadDelivered, "invalid", nil),
+		}
+		traces, err := QueryDeliveredPayloads(context.Background(), relays, url.Values{"proposer_pubkey": []string{validator}}, opts)
+		require.NoError(t, err)
+		require.Equal(t, validator, query.Get("proposer_pubkey"))
+		require.Len(t, traces.Traces, 2)
+		require.Len(t, traces.Errors, 1)
+		require.Equal(t, relays[2].URL.Host, traces.Errors[0].Relay)
+
+		// Block hashes are compared case-insensitively
+		require.Equal(t, blockA, traces.Traces[0].BlockHash)
+		require.Equal(t, []string{relays[0].URL.Host, relays[1].URL.Host}, traces.Traces[0].Relays)
+		require.Equal(t, uint64(9), traces.Traces[1].Slot)
+		require.Equal(t, uint64(3), traces.Traces[1].NumTx)
+		require.Equal(t, "50", traces.Traces[1].Value.String())
+	})
+
+	t.Run("Received blocks", func(t *testing.T) {
+		var query url.Values
+		relays := []types.RelayEntry{
+			newRelay(t, params.PathDataBuilderBlocksReceived, "["+trace(10, blockA, "100", 2000)+","+trace(10, blockB, "200", 3000)+"]", &query),
+			newRelay(t, params.PathDataBuilderBlocksReceived, "["+trace(10, blockA, "100", 1000)+"]", nil),
+		}
+		traces, err := QueryReceivedBlocks(context.Background(), relays, url.Values{"slot": []string{"10"}}, opts)
+		require.NoError(t, err)
+		require.Equal(t, "10", query.Get("slot"))
+		require.Empty(t, traces.Errors)
+		require.Len(t, traces.Traces, 2)
+
+		// The highest bid first, the earliest timestamp of the relays
+		require.Equal(t, blockB, traces.Traces[0].BlockHash)
+		require.Equal(t, blockA, traces.Traces[1].BlockHash)
+		require.Equal(t, uint64(1000), traces.Traces[1].TimestampMs)
+		require.Equal(t, []string{relays[0].URL.Host, relays[1].URL.Host}, traces.Traces[1].Relays)
+	})
+
+	t.Run("Relays under a base path", func(t *testing.T) {
+		relay := newRelay(t, "/relay"+params.PathDataBuilderBlocksReceived, "["+trace(10, blockA, "100", 0)+"]", nil)
+		relay.URL.Path = "/relay"
+		traces, err := QueryReceivedBlocks(context.Background(), []types.RelayEntry{relay}, url.Values{"slot": []string{"10"}}, opts)
+		require.NoError(t, err)
+		require.Empty(t, traces.Errors)
+		require.Len(t, traces.Traces, 1)
+	})
+
+	t.Run("Validator registrations", func(t *testing.T) {
+		var query url.Values
+		relays := []types.RelayEntry{
+			newRelay(t, params.PathDataValidatorRegistration, registration(36_000_000), &query),
+			newRelay(t, params.PathDataValidatorRegistration, registration(30_000_000), nil),
+			newRelay(t, params.PathDataValidatorRegistration, registration(36_000_000), nil),
+			newRelay(t, "/unregistered", "", nil),
+		}
+		registrations, err := QueryValidatorRegistrations(context.Background(), relays, validator, opts)
+		require.NoError(t, err)
+		require.Equal(t, validator, query.Get("pubkey"))
+		require.Len(t, registrations.Registrations, 2)
+		require.Equal(t, uint64(36_000_000), registrations.Registrations[0].GasLimit)
+		require.Equal(t, []string{relays[0].URL.Host, relays[2].URL.Host}, registrations.Registrations[0].Relays)
+		require.Equal(t, uint64(30_000_000), registrations.Registrations[1].GasLimit)
+		require.Equal(t, uint64(1700000000), registrations.Registrations[1].Timestamp)
+		require.Len(t, registrations.Errors, 1)
+		require.Contains(t, registrations.Errors[0].Error, "no registration found")
+	})
+}
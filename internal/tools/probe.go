@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// The steps of a relay probe
+const (
+	RelayProbeDNS    = "dns"
+	RelayProbeTLS    = "tls"
+	RelayProbeStatus = "status"
+	RelayProbePubkey = "pubkey"
+	RelayProbeJSON   = "json"
+	RelayProbeSSZ    = "ssz"
+)
+
+// The results of a relay probe step
+const (
+	RelayProbeOK          = "ok"
+	RelayProbeFailed      = "FAIL"
+	RelayProbeSkipped     = "-"  // the step doesn't apply, i.e. TLS of plain HTTP relays, or an earlier step failed
+	RelayProbeUnsupported = "no" // the relay doesn't support the encoding, only a failure if it is configured
+)
+
+// relayProbeRequests is the number of status requests of the latency measurement, after the first request
+const relayProbeRequests = 3
+
+var (
+	errRelayProbeStatus   = errors.New("unexpected status code")
+	errRelayProbePubkey   = errors.New("not a valid BLS public key")
+	errRelayProbeEncoding = errors.New("relay rejected the encoding")
+)
+
+// RelayProbeOpts are the request timeout and the relay client settings of the probes
+type RelayProbeOpts struct {
+	Timeout     time.Duration
+	RelayClient server.RelayClientOpts
+}
+
+// RelayProbeStep is the result of a step of a relay probe, with the error if it failed
+type RelayProbeStep struct {
+	Name   string
+	Result string
+	Detail string // i.e. the TLS version and certificate expiry
+	Err    error
+}
+
+// RelayProbe is the outcome of probing a relay
+type RelayProbe struct {
+	Relay   types.RelayEntry
+	Steps   []RelayProbeStep
+	Latency time.Duration // median latency of the status requests on an established connection, zero if they failed
+}
+
+// Failed returns whether a step of the probe failed
+func (p RelayProbe) Failed() bool {
+	return slices.ContainsFunc(p.Steps, func(step RelayProbeStep) bool { return step.Result == RelayProbeFailed })
+}
+
+// Step returns the result of the step of the probe
+func (p RelayProbe) Step(name string) RelayProbeStep {
+	for _, step := range p.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return RelayProbeStep{Name: name, Result: RelayProbeSkipped}
+}
+
+// ProbeRelays checks that the relays are reachable and correctly configured, with the relay client settings of
+// mev-boost: the DNS lookup and TLS handshake, the status endpoint, the relay public key, the latency, and the JSON and
+// SSZ support, by sending an empty list of validator registrations. The probes are in the order of the relays.
+func ProbeRelays(ctx context.Context, relays []types.RelayEntry, opts RelayProbeOpts) ([]RelayProbe, error) {
+	probes := make([]RelayProbe, len(relays))
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		// Each relay gets its own clients, so the first request does the TLS handshake
+		clients, err := server.NewRelayClients(opts.RelayClient, []types.RelayEntry{relay})
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer clients.CloseIdleConnections()
+			probes[i] = probeRelay(ctx, clients.Client(relay, opts.Timeout), clients.LookupHost, relay)
+		}()
+	}
+	wg.Wait()
+	return probes, nil
+}
+
+// probeRelay probes the relay with the client, resolving its host name with lookup
+func probeRelay(ctx context.Context, client http.Client, lookup func(ctx context.Context, host string) ([]netip.Addr, error), relay types.RelayEntry) RelayProbe {
+	probe := RelayProbe{Relay: relay}
+	add := func(step RelayProbeStep) {
+		if step.Result == "" {
+			step.Result = RelayProbeOK
+			if step.Err != nil {
+				step.Result = RelayProbeFailed
+			}
+		}
+		probe.Steps = append(probe.Steps, step)
+	}
+	skipNetwork := func() {
+		for _, name := range []string{RelayProbeTLS, RelayProbeStatus, RelayProbeJSON, RelayProbeSSZ} {
+			if !slices.ContainsFunc(probe.Steps, func(step RelayProbeStep) bool { return step.Name == name }) {
+				add(RelayProbeStep{Name: name, Result: RelayProbeSkipped})
+			}
+		}
+	}
+
+	if _, err := bls.PublicKeyFromBytes(relay.PublicKey[:]); err != nil {
+		add(RelayProbeStep{Name: RelayProbePubkey, Err: fmt.Errorf("%w: %w", errRelayProbePubkey, err)})
+	} else {
+		add(RelayProbeStep{Name: RelayProbePubkey})
+	}
+
+	host := relay.URL.Hostname()
+	if _, err := netip.ParseAddr(host); err == nil {
+		add(RelayProbeStep{Name: RelayProbeDNS, Result: RelayProbeSkipped})
+	} else {
+		addrs, err := lookup(ctx, host)
+		add(RelayProbeStep{Name: RelayProbeDNS, Detail: fmt.Sprint(addrs), Err: err})
+		if err != nil {
+			skipNetwork()
+			return probe
+		}
+	}
+
+	// The first status request connects to the relay
+	var tlsMu sync.Mutex
+	var tlsState *tls.ConnectionState
+	var tlsErr error
+	traceCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			tlsMu.Lock()
+			defer tlsMu.Unlock()
+			tlsState, tlsErr = &state, err
+		},
+	})
+	url := relay.GetURI(params.PathStatus)
+	start := time.Now()
+	code, err := server.SendHTTPRequest(traceCtx, client, http.MethodGet, url, "", nil, nil, nil)
+	requestLatency := time.Since(start)
+	tlsMu.Lock()
+	switch {
+	case relay.URL.Scheme != "https":
+		add(RelayProbeStep{Name: RelayProbeTLS, Result: RelayProbeSkipped})
+	case tlsErr != nil:
+		add(RelayProbeStep{Name: RelayProbeTLS, Err: tlsErr})
+	case tlsState != nil:
+		add(RelayProbeStep{Name: RelayProbeTLS, Detail: tlsDetail(tlsState)})
+	default:
+		// The connection failed before the handshake, the status step fails
+		add(RelayProbeStep{Name: RelayProbeTLS, Result: RelayProbeSkipped})
+	}
+	tlsMu.Unlock()
+	if tlsErr != nil {
+		skipNetwork()
+		return probe
+	}
+	if err == nil && code != http.StatusOK {
+		err = fmt.Errorf("%w %d", errRelayProbeStatus, code)
+	}
+	add(RelayProbeStep{Name: RelayProbeStatus, Detail: "first request " + FormatLatency(requestLatency), Err: err})
+	if err != nil {
+		skipNetwork()
+		return probe
+	}
+
+	latencies := make([]time.Duration, 0, relayProbeRequests)
+	for range relayProbeRequests {
+		start := time.Now()
+		if _, err := server.SendHTTPRequest(ctx, client, http.MethodGet, url, "", nil, nil, nil); err == nil {
+			latencies = append(latencies, time.Since(start))
+		}
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		probe.Latency = latencies[len(latencies)/2]
+	}
+
+	add(probeEncoding(ctx, client, relay, RelayProbeJSON, false))
+	sszStep := probeEncoding(ctx, client, relay, RelayProbeSSZ, true)
+	if sszStep.Err != nil && errors.Is(sszStep.Err, errRelayProbeEncoding) && !relay.SSZ {
+		sszStep.Result = RelayProbeUnsupported
+	}
+	add(sszStep)
+	return probe
+}
+
+// probeEncoding sends the empty list of validator registrations to the relay, SSZ or JSON encoded. Any response but
+// 406 and 415 means the relay decodes the encoding.
+func probeEncoding(ctx context.Context, client http.Client, relay types.RelayEntry, name string, ssz bool) RelayProbeStep {
+	relay.SSZ = ssz
+	code, err := server.SendRelayRequest(ctx, client, relay, http.MethodPost, relay.GetURI(params.PathRegisterValidator), []builderApiV1.SignedValidatorRegistration{}, nil)
+	switch code {
+	case 0:
+		return RelayProbeStep{Name: name, Err: err}
+	case http.StatusNotAcceptable, http.StatusUnsupportedMediaType:
+		return RelayProbeStep{Name: name, Err: fmt.Errorf("%w with status code %d", errRelayProbeEncoding, code)}
+	}
+	return RelayProbeStep{Name: name}
+}
+
+// tlsDetail returns the TLS version and the expiry of the relay certificate
+func tlsDetail(state *tls.ConnectionState) string {
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		detail += ", expires " + state.PeerCertificates[0].NotAfter.Format(time.DateOnly)
+	}
+	return detail
+}
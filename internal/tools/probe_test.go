@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeRelays(t *testing.T) {
+	const pubkey = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	// newRelay returns a relay only accepting JSON registrations
+	newRelay := func(t *testing.T, statusCode int) *httptest.Server {
+		t.Helper()
+		mux := http.NewServeMux()
+		mux.HandleFunc(params.PathStatus, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(statusCode) })
+		mux.HandleFunc(params.PathRegisterValidator, func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Content-Type") == server.MediaTypeSSZ {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		ts := httptest.NewServer(mux)
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	relayEntry := func(t *testing.T, rawURL string) types.RelayEntry {
+		t.Helper()
+		relay, err := types.NewRelayEntry(rawURL)
+		require.NoError(t, err)
+		return relay
+	}
+	opts := RelayProbeOpts{Timeout: time.Second}
+
+	t.Run("Reachable relay", func(t *testing.T) {
+		ts := newRelay(t, http.StatusOK)
+		relay := relayEntry(t, strings.Replace(ts.URL, "http://", "http://"+pubkey+"@", 1))
+		probes, err := ProbeRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.Len(t, probes, 1)
+
+		probe := probes[0]
+		require.False(t, probe.Failed())
+		require.Equal(t, RelayProbeSkipped, probe.Step(RelayProbeDNS).Result)
+		require.Equal(t, RelayProbeSkipped, probe.Step(RelayProbeTLS).Result)
+		require.Equal(t, RelayProbeOK, probe.Step(RelayProbeStatus).Result)
+		require.Equal(t, RelayProbeOK, probe.Step(RelayProbePubkey).Result)
+		require.Equal(t, RelayProbeOK, probe.Step(RelayProbeJSON).Result)
+		require.Equal(t, RelayProbeUnsupported, probe.Step(RelayProbeSSZ).Result)
+		require.Positive(t, probe.Latency)
+
+		// SSZ is required of relays configured with it
+		relay.SSZ = true
+		probes, err = ProbeRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.True(t, probes[0].Failed())
+		require.Equal(t, RelayProbeFailed, probes[0].Step(RelayProbeSSZ).Result)
+	})
+
+	t.Run("Failing status", func(t *testing.T) {
+		ts := newRelay(t, http.StatusServiceUnavailable)
+		relay := relayEntry(t, strings.Replace(ts.URL, "http://", "http://"+pubkey+"@", 1))
+		probes, err := ProbeRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.True(t, probes[0].Failed())
+		require.Equal(t, RelayProbeFailed, probes[0].Step(RelayProbeStatus).Result)
+		require.Equal(t, RelayProbeSkipped, probes[0].Step(RelayProbeJSON).Result)
+		require.Zero(t, probes[0].Latency)
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.NotFoundHandler())
+		defer ts.Close()
+		relay := relayEntry(t, strings.Replace(ts.URL, "https://", "https://"+pubkey+"@", 1))
+		probes, err := ProbeRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.Equal(t, RelayProbeFailed, probes[0].Step(RelayProbeTLS).Result)
+		require.Equal(t, RelayProbeSkipped, probes[0].Step(RelayProbeStatus).Result)
+	})
+
+	t.Run("Invalid public key", func(t *testing.T) {
+		ts := newRelay(t, http.StatusOK)
+		relay := relayEntry(t, strings.Replace(ts.URL, "http://", "http://"+pubkey+"@", 1))
+		copy(relay.PublicKey[:], bytes.Repeat([]byte{0xab}, len(relay.PublicKey)))
+		probes, err := ProbeRelays(context.Background(), []types.RelayEntry{relay}, opts)
+		require.NoError(t, err)
+		require.True(t, probes[0].Failed())
+		require.ErrorIs(t, probes[0].Step(RelayProbePubkey).Err, errRelayProbePubkey)
+		require.Equal(t, RelayProbeOK, probes[0].Step(RelayProbeStatus).Result)
+	})
+
+	t.Run("Unresolvable host", func(t *testing.T) {
+		lookup := func(context.Context, string) ([]netip.Addr, error) {
+			return nil, errors.New("no such host")
+		}
+		relay := relayEntry(t, fmt.Sprintf("https://%s@relay.example.com", pubkey))
+		probe := probeRelay(context.Background(), http.Client{}, lookup, relay)
+		require.True(t, probe.Failed())
+		require.Equal(t, RelayProbeFailed, probe.Step(RelayProbeDNS).Result)
+		require.Equal(t, RelayProbeSkipped, probe.Step(RelayProbeTLS).Result)
+		require.Equal(t, RelayProbeSkipped, probe.Step(RelayProbeSSZ).Result)
+	})
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+)
+
+// BidReplayOpts are the bid selection settings of a replay, as in server.BoostServiceOpts. Bids of relays not in Relays are
+// dropped, all relays of the audit log are used with default options if Relays is empty.
+type BidReplayOpts struct {
+	Relays                []types.RelayEntry
+	RelayMinBid           types.U256Str
+	RelayMinBidPercent    float64
+	RelayMinBidSlots      int
+	BidTiebreaker         string
+	RelayLabelPreferences map[string]float64
+}
+
+// BidReplayChoice is the bid chosen in a slot
+type BidReplayChoice struct {
+	BlockHash string   `json:"block_hash"`
+	Value     string   `json:"value"`
+	Relays    []string `json:"relays"`
+}
+
+// BidReplaySlot is the bid chosen in a slot when the bids were received and in the replay, nil if no bid was chosen
+type BidReplaySlot struct {
+	Slot     uint64           `json:"slot"`
+	SlotUID  string           `json:"slot_uid"`
+	MinBid   string           `json:"min_bid"` // the min-bid of the replay
+	Recorded *BidReplayChoice `json:"recorded"`
+	Replayed *BidReplayChoice `json:"replayed"`
+}
+
+// Changed returns whether the replay chose another bid than the recorded auction
+func (s BidReplaySlot) Changed() bool {
+	if s.Recorded == nil || s.Replayed == nil {
+		return s.Recorded != s.Replayed
+	}
+	return s.Recorded.BlockHash != s.Replayed.BlockHash
+}
+
+// ReadBidAuditLog reads the records of a bid audit log, one JSON object per line
+func ReadBidAuditLog(r io.Reader) ([]server.BidAuditRecord, error) {
+	var records []server.BidAuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record server.BidAuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ReplayBids feeds the bids of the audit log records through the bid selection with the settings, auction by auction
+// in the order of the records, and returns the recorded and replayed choice of each auction. The replay decides the
+// min-bid (including the relative min-bid), the relay tiers, weights and boost factors, the label preferences and the
+// tiebreaker. Bid scripts, bid plugins and the bid policy service aren't replayed, the bids they rejected stay
+// rejected, and the random tiebreaker falls back to the block hash.
+func ReplayBids(records []server.BidAuditRecord, opts BidReplayOpts) ([]BidReplaySlot, error) {
+	selector, err := server.NewDefaultBidSelector(opts.BidTiebreaker, opts.RelayLabelPreferences)
+	if err != nil {
+		return nil, err
+	}
+	relativeMinBid, err := server.NewRelativeMinBid(opts.RelayMinBid, opts.RelayMinBidPercent, opts.RelayMinBidSlots)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the records by auction, the auctions in the order they were recorded
+	var auctions [][]server.BidAuditRecord
+	index := make(map[string]int)
+	for _, record := range records {
+		key := record.SlotUID
+		if key == "" {
+			key = fmt.Sprint(record.Slot)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(auctions)
+			index[key] = i
+			auctions = append(auctions, nil)
+		}
+		auctions[i] = append(auctions[i], record)
+	}
+
+	slots := make([]BidReplaySlot, 0, len(auctions))
+	for _, auction := range auctions {
+		minBid := relativeMinBid.MinBid()
+		slot := BidReplaySlot{Slot: auction[0].Slot, SlotUID: auction[0].SlotUID, MinBid: minBid.String()}
+		var best *server.Bid
+		candidates := make(map[phase0.Hash32]*server.Bid)
+		for _, record := range auction {
+			if record.Selected {
+				slot.Recorded = addReplayChoice(slot.Recorded, record)
+			}
+			relay, relayOrder, ok := replayRelay(opts.Relays, record.Relay)
+			if !ok || record.RejectedBeforeMinBid() {
+				continue
+			}
+			value, err := uint256.FromDecimal(record.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of bid %s in slot %d: %w", record.BlockHash, record.Slot, err)
+			}
+
+			// The value counts for the relative min-bid of later slots, as in getHeader
+			relativeMinBid.Record(record.Slot, value.ToBig())
+			if !record.Selectable() {
+				continue
+			}
+			if value.CmpBig(minBid.BigInt()) == -1 {
+				continue
+			}
+
+			blockHash, err := utils.HexToHash(record.BlockHash)
+			if err != nil {
+				return nil, fmt.Errorf("invalid block hash of bid in slot %d: %w", record.Slot, err)
+			}
+			latency := time.Duration(record.RequestDurationMs) * time.Millisecond
+			bid, ok := candidates[blockHash]
+			if !ok {
+				bid = &server.Bid{BlockHash: blockHash, Value: value, RelayOrder: relayOrder, Latency: latency}
+				candidates[blockHash] = bid
+			}
+			bid.Relays = append(bid.Relays, relay)
+			bid.RelayOrder = min(bid.RelayOrder, relayOrder)
+			bid.Latency = min(bid.Latency, latency)
+		}
+		for _, bid := range candidates {
+			if best == nil || selector.Better(bid, best) {
+				best = bid
+			}
+		}
+		if best != nil {
+			slot.Replayed = &BidReplayChoice{BlockHash: best.BlockHash.String(), Value: best.Value.Dec()}
+			for _, relay := range best.Relays {
+				slot.Replayed.Relays = append(slot.Replayed.Relays, relay.URL.Host)
+			}
+		}
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+// addReplayChoice adds the selected bid of the record to the recorded choice of the auction
+func addReplayChoice(choice *BidReplayChoice, record server.BidAuditRecord) *BidReplayChoice {
+	if choice == nil {
+		choice = &BidReplayChoice{BlockHash: record.BlockHash, Value: record.Value}
+	}
+	if u, err := url.Parse(record.Relay); err == nil {
+		choice.Relays = append(choice.Relays, u.Host)
+	}
+	return choice
+}
+
+// replayRelay returns the relay of the replay with the host of the recorded relay URL and its position, or a relay
+// with default options if the replay has no relays. Recorded relays not in the relays of the replay are dropped.
+func replayRelay(relays []types.RelayEntry, recorded string) (types.RelayEntry, int, bool) {
+	u, err := url.Parse(recorded)
+	if err != nil {
+		return types.RelayEntry{}, 0, false
+	}
+	if len(relays) == 0 {
+		return types.RelayEntry{URL: &url.URL{Scheme: u.Scheme, Host: u.Host}}, 0, true
+	}
+	for i, relay := range relays {
+		if relay.URL.Host == u.Host {
+			return relay, i, true
+		}
+	}
+	return types.RelayEntry{}, 0, false
+}
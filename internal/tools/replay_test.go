@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flashbots/mev-boost/server"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBidAuditLog(t *testing.T) {
+	records, err := ReadBidAuditLog(strings.NewReader(`{"slot":1,"relay":"http://relay-a","value":"100","selected":true}
+
+{"slot":2,"relay":"http://relay-b","value":"50","rejection_reason":"outbid"}
+`))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.True(t, records[0].Selected)
+	require.Equal(t, uint64(2), records[1].Slot)
+	require.Equal(t, "outbid", records[1].RejectionReason)
+
+	_, err = ReadBidAuditLog(strings.NewReader("{\"slot\":1}\ninvalid\n"))
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestReplayBids(t *testing.T) {
+	const (
+		blockA = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		blockB = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+		blockC = "0xcccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"
+		pubkey = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	)
+	record := func(slot uint64, relay, blockHash, value, reason string) server.BidAuditRecord {
+		return server.BidAuditRecord{
+			Slot: slot, SlotUID: "uid-" + string(rune('0'+slot)), Relay: "http://" + relay, BlockHash: blockHash, Value: value,
+			Selected: reason == "", RejectionReason: reason, RequestDurationMs: 10,
+		}
+	}
+	newRelay := func(t *testing.T, host string) types.RelayEntry {
+		t.Helper()
+		relay, err := types.NewRelayEntry("http://" + pubkey + "@" + host)
+		require.NoError(t, err)
+		return relay
+	}
+	records := []server.BidAuditRecord{
+		record(1, "relay-a", blockA, "100", ""),
+		record(1, "relay-b", blockA, "100", ""),
+		record(1, "relay-c", blockB, "90", "outbid"),
+		record(1, "relay-c", blockC, "200", "script_rejected"),
+		record(2, "relay-a", blockB, "40", "below_min_bid"),
+	}
+
+	t.Run("Recorded settings", func(t *testing.T) {
+		slots, err := ReplayBids(records, BidReplayOpts{RelayMinBid: types.IntToU256(50)})
+		require.NoError(t, err)
+		require.Len(t, slots, 2)
+		require.Equal(t, uint64(1), slots[0].Slot)
+		require.Equal(t, "uid-1", slots[0].SlotUID)
+		require.False(t, slots[0].Changed())
+		require.Equal(t, blockA, slots[0].Replayed.BlockHash)
+		require.Equal(t, []string{"relay-a", "relay-b"}, slots[0].Recorded.Relays)
+		require.Equal(t, []string{"relay-a", "relay-b"}, slots[0].Replayed.Relays)
+
+		// No bid in either auction
+		require.Nil(t, slots[1].Recorded)
+		require.Nil(t, slots[1].Replayed)
+		require.False(t, slots[1].Changed())
+	})
+
+	t.Run("Min-bid", func(t *testing.T) {
+		slots, err := ReplayBids(records, BidReplayOpts{RelayMinBid: types.IntToU256(95)})
+		require.NoError(t, err)
+		require.False(t, slots[0].Changed())
+		require.Equal(t, "95", slots[0].MinBid)
+
+		slots, err = ReplayBids(records, BidReplayOpts{RelayMinBid: types.IntToU256(150)})
+		require.NoError(t, err)
+		require.True(t, slots[0].Changed())
+		require.Nil(t, slots[0].Replayed)
+
+		slots, err = ReplayBids(records, BidReplayOpts{})
+		require.NoError(t, err)
+		require.True(t, slots[1].Changed())
+		require.Equal(t, blockB, slots[1].Replayed.BlockHash)
+		require.Equal(t, "40", slots[1].Replayed.Value)
+	})
+
+	t.Run("Relative min-bid", func(t *testing.T) {
+		// Half of the highest bid of slot 1, the bid rejected by the bid script counts too
+		slots, err := ReplayBids(records, BidReplayOpts{RelayMinBidPercent: 50, RelayMinBidSlots: 10})
+		require.NoError(t, err)
+		require.Equal(t, "0", slots[0].MinBid)
+		require.Equal(t, "100", slots[1].MinBid)
+		require.Nil(t, slots[1].Replayed)
+
+		_, err = ReplayBids(records, BidReplayOpts{RelayMinBidPercent: 50})
+		require.Error(t, err)
+	})
+
+	t.Run("Relays", func(t *testing.T) {
+		// The bids of relays not configured are dropped
+		slots, err := ReplayBids(records, BidReplayOpts{Relays: []types.RelayEntry{newRelay(t, "relay-b")}})
+		require.NoError(t, err)
+		require.False(t, slots[0].Changed())
+		require.Equal(t, []string{"relay-b"}, slots[0].Replayed.Relays)
+		require.Nil(t, slots[1].Replayed)
+
+		// A higher tier wins over the higher bid
+		slots, err = ReplayBids(records, BidReplayOpts{Relays: []types.RelayEntry{
+			newRelay(t, "relay-a?tier=1"), newRelay(t, "relay-c"),
+		}})
+		require.NoError(t, err)
+		require.True(t, slots[0].Changed())
+		require.Equal(t, blockB, slots[0].Replayed.BlockHash)
+		require.Equal(t, []string{"relay-c"}, slots[0].Replayed.Relays)
+	})
+
+	t.Run("Tiebreaker", func(t *testing.T) {
+		tied := []server.BidAuditRecord{
+			record(1, "relay-b", blockB, "100", ""),
+			record(1, "relay-a", blockA, "100", "outbid"),
+		}
+		relays := []types.RelayEntry{newRelay(t, "relay-b"), newRelay(t, "relay-a")}
+		slots, err := ReplayBids(tied, BidReplayOpts{Relays: relays})
+		require.NoError(t, err)
+		require.Equal(t, blockA, slots[0].Replayed.BlockHash)
+
+		slots, err = ReplayBids(tied, BidReplayOpts{Relays: relays, BidTiebreaker: server.TiebreakerRelayOrder})
+		require.NoError(t, err)
+		require.Equal(t, blockB, slots[0].Replayed.BlockHash)
+		require.False(t, slots[0].Changed())
+
+		_, err = ReplayBids(tied, BidReplayOpts{BidTiebreaker: "unknown"})
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid records", func(t *testing.T) {
+		_, err := ReplayBids([]server.BidAuditRecord{record(1, "relay-a", blockA, "invalid", "")}, BidReplayOpts{})
+		require.ErrorContains(t, err, "invalid value")
+		_, err = ReplayBids([]server.BidAuditRecord{record(1, "relay-a", "0x01", "100", "")}, BidReplayOpts{})
+		require.ErrorContains(t, err, "invalid block hash")
+	})
+}
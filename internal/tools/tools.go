@@ -0,0 +1,20 @@
+// Package tools implements the subcommands of mev-boost which check the relays or replay the bid audit log, with the
+// relay client and bid selection settings of the service
+package tools
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FormatLatency returns the latency in milliseconds with two decimals, i.e. 0.04ms or 12.30ms
+func FormatLatency(latency time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(latency)/float64(time.Millisecond))
+}
+
+// percentile returns the nearest-rank percentile p (0 to 1) of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+const (
+	// latencyWindowSize is the number of recent getHeader latencies the percentiles are computed over
+	latencyWindowSize = 100
+	// adaptiveTimeoutMinSamples is the number of latencies needed before the timeout of a relay adapts
+	adaptiveTimeoutMinSamples = 10
+	// adaptiveTimeoutMargin is the factor of the p99 latency which is used as timeout
+	adaptiveTimeoutMargin = 1.5
+)
+
+// latencyWindow keeps the latencies of the recent getHeader requests to a relay in a ring buffer, timed out requests
+// count with their timeout
+type latencyWindow struct {
+	samples     [latencyWindowSize]time.Duration
+	count, next int
+}
+
+// adaptiveTimeouts derives the getHeader timeouts of relays from their recent latencies: 1.5 times the p99 latency,
+// bounded by min and max. Relays which are slow but working get more time, fast relays fail fast if they go down. A
+// relay slowing down past its timeout gets more time with every timed out request, until it responds again.
+type adaptiveTimeouts struct {
+	mu       sync.Mutex
+	min, max time.Duration
+	windows  map[string]*latencyWindow
+}
+
+func newAdaptiveTimeouts(minTimeout, maxTimeout time.Duration) *adaptiveTimeouts {
+	return &adaptiveTimeouts{
+		min:     minTimeout,
+		max:     maxTimeout,
+		windows: make(map[string]*latencyWindow),
+	}
+}
+
+// record adds the latency of a getHeader request, or the timeout of a timed out request
+func (a *adaptiveTimeouts) record(relay types.RelayEntry, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.windows[relay.String()]
+	if !ok {
+		w = new(latencyWindow)
+		a.windows[relay.String()] = w
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % latencyWindowSize
+	w.count = min(w.count+1, latencyWindowSize)
+}
+
+// export returns the recent latencies of the relays by relay URL, oldest first
+func (a *adaptiveTimeouts) export() map[string][]time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	latencies := make(map[string][]time.Duration, len(a.windows))
+	for key, w := range a.windows {
+		latencies[key] = ringItems(w.samples[:], w.count, w.next)
+	}
+	return latencies
+}
+
+// restore replaces the recent latencies of the relay by the exported ones, i.e. after a restart
+func (a *adaptiveTimeouts) restore(relay string, latencies []time.Duration) {
+	w := new(latencyWindow)
+	w.count, w.next = fillRing(w.samples[:], latencies)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.windows[relay] = w
+}
+
+// percentiles returns the p50 and p99 latencies of the relay, false without enough samples
+func (a *adaptiveTimeouts) percentiles(relay types.RelayEntry) (p50, p99 time.Duration, ok bool) {
+	a.mu.Lock()
+	w, found := a.windows[relay.String()]
+	var samples []time.Duration
+	if found {
+		samples = slices.Clone(w.samples[:w.count])
+	}
+	a.mu.Unlock()
+
+	if len(samples) < adaptiveTimeoutMinSamples {
+		return 0, 0, false
+	}
+	slices.Sort(samples)
+	return percentile(samples, 0.5), percentile(samples, 0.99), true
+}
+
+// timeout returns the adapted getHeader timeout of the relay, false without enough samples
+func (a *adaptiveTimeouts) timeout(relay types.RelayEntry) (time.Duration, bool) {
+	_, p99, ok := a.percentiles(relay)
+	if !ok {
+		return 0, false
+	}
+	timeout := time.Duration(float64(p99) * adaptiveTimeoutMargin)
+	return min(max(timeout, a.min), a.max), true
+}
+
+// percentile returns the nearest-rank percentile p of the sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// getHeaderClient returns the getHeader client of the relay. Relays with their own getHeader timeout keep it, other
+// relays get the adapted timeout, if enabled.
+func (m *BoostService) getHeaderClient(cfg reloadableConfig, relay types.RelayEntry) http.Client {
+	client := cfg.getHeaderClient(relay)
+	if m.adaptiveTimeouts == nil || relay.TimeoutGetHeader > 0 {
+		return client
+	}
+	if timeout, ok := m.adaptiveTimeouts.timeout(relay); ok {
+		client.Timeout = timeout
+	}
+	return client
+}
+
+// setAdaptiveTimeout adds the latency percentiles and adapted timeout of the relay to its health
+func (h *RelayHealth) setAdaptiveTimeout(timeouts *adaptiveTimeouts, relay types.RelayEntry) {
+	if p50, p99, ok := timeouts.percentiles(relay); ok {
+		h.LatencyP50Ms, h.LatencyP99Ms = p50.Milliseconds(), p99.Milliseconds()
+	}
+	if timeout, ok := timeouts.timeout(relay); ok && relay.TimeoutGetHeader == 0 {
+		h.TimeoutGetHeaderMs = timeout.Milliseconds()
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	relay := mock.NewRelay(t).RelayEntry
+
+	t.Run("Needs enough samples", func(t *testing.T) {
+		timeouts := newAdaptiveTimeouts(10*time.Millisecond, time.Second)
+		for range adaptiveTimeoutMinSamples - 1 {
+			timeouts.record(relay, 100*time.Millisecond)
+		}
+		_, ok := timeouts.timeout(relay)
+		require.False(t, ok)
+
+		timeouts.record(relay, 100*time.Millisecond)
+		timeout, ok := timeouts.timeout(relay)
+		require.True(t, ok)
+		require.Equal(t, 150*time.Millisecond, timeout)
+	})
+
+	t.Run("Percentiles of the recent latencies", func(t *testing.T) {
+		timeouts := newAdaptiveTimeouts(10*time.Millisecond, time.Second)
+		for i := range latencyWindowSize {
+			timeouts.record(relay, time.Duration(i+1)*time.Millisecond)
+		}
+		p50, p99, ok := timeouts.percentiles(relay)
+		require.True(t, ok)
+		require.Equal(t, 50*time.Millisecond, p50)
+		require.Equal(t, 99*time.Millisecond, p99)
+
+		// Old latencies are forgotten
+		for range latencyWindowSize {
+			timeouts.record(relay, 20*time.Millisecond)
+		}
+		p50, p99, _ = timeouts.percentiles(relay)
+		require.Equal(t, 20*time.Millisecond, p50)
+		require.Equal(t, 20*time.Millisecond, p99)
+	})
+
+	t.Run("Bounded by min and max", func(t *testing.T) {
+		timeouts := newAdaptiveTimeouts(200*time.Millisecond, 500*time.Millisecond)
+		fast, slow := mock.NewRelay(t).RelayEntry, mock.NewRelay(t).RelayEntry
+		for range adaptiveTimeoutMinSamples {
+			timeouts.record(fast, 10*time.Millisecond)
+			timeouts.record(slow, time.Second)
+		}
+		timeout, _ := timeouts.timeout(fast)
+		require.Equal(t, 200*time.Millisecond, timeout)
+		timeout, _ = timeouts.timeout(slow)
+		require.Equal(t, 500*time.Millisecond, timeout)
+	})
+
+	t.Run("Export and restore", func(t *testing.T) {
+		timeouts := newAdaptiveTimeouts(10*time.Millisecond, time.Second)
+		for i := range latencyWindowSize + 5 {
+			timeouts.record(relay, time.Duration(i+1)*time.Millisecond)
+		}
+		latencies := timeouts.export()[relay.String()]
+		require.Len(t, latencies, latencyWindowSize)
+		require.Equal(t, 6*time.Millisecond, latencies[0], "oldest first")
+
+		restored := newAdaptiveTimeouts(10*time.Millisecond, time.Second)
+		restored.restore(relay.String(), latencies)
+		require.Equal(t, timeouts.export(), restored.export())
+		timeout, _ := timeouts.timeout(relay)
+		restoredTimeout, _ := restored.timeout(relay)
+		require.Equal(t, timeout, restoredTimeout)
+	})
+
+	t.Run("Used for getHeader unless the relay has its own timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adaptiveTimeouts = newAdaptiveTimeouts(200*time.Millisecond, 500*time.Millisecond)
+		relay := backend.relays[0].RelayEntry
+		cfg := backend.boost.currentConfig()
+		require.Equal(t, time.Second, backend.boost.getHeaderClient(cfg, relay).Timeout, "not enough samples")
+
+		hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+		pubkey := mock.HexToPubkey(
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+		for i := range adaptiveTimeoutMinSamples {
+			rr := backend.request(t, http.MethodGet, getHeaderPath(uint64(i+1), hash, pubkey), nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		require.Equal(t, 200*time.Millisecond, backend.boost.getHeaderClient(cfg, relay).Timeout)
+
+		relay.TimeoutGetHeader = 300 * time.Millisecond
+		require.Equal(t, 300*time.Millisecond, backend.boost.getHeaderClient(cfg, relay).Timeout)
+	})
+
+	t.Run("Grows when the relay slows down past the timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adaptiveTimeouts = newAdaptiveTimeouts(20*time.Millisecond, 500*time.Millisecond)
+		relay := backend.relays[0].RelayEntry
+		hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+		pubkey := mock.HexToPubkey(
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+		for i := range adaptiveTimeoutMinSamples {
+			rr := backend.request(t, http.MethodGet, getHeaderPath(uint64(i+1), hash, pubkey), nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		require.Equal(t, 20*time.Millisecond, backend.boost.getHeaderClient(backend.boost.currentConfig(), relay).Timeout)
+
+		// The relay times out, until its timeout grew past its latency
+		backend.relays[0].ResponseDelay = 50 * time.Millisecond
+		codes := make([]int, 0)
+		for i := range 5 {
+			rr := backend.request(t, http.MethodGet, getHeaderPath(uint64(adaptiveTimeoutMinSamples+i+1), hash, pubkey), nil)
+			codes = append(codes, rr.Code)
+			if rr.Code == http.StatusOK {
+				break
+			}
+		}
+		require.Equal(t, http.StatusOK, codes[len(codes)-1], codes)
+		require.Greater(t, backend.boost.getHeaderClient(backend.boost.currentConfig(), relay).Timeout, 50*time.Millisecond)
+	})
+}
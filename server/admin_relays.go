@@ -0,0 +1,251 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Relay changes of the admin API
+const (
+	relayActionAdd    = "add"
+	relayActionRemove = "remove"
+	relayActionPause  = "pause"
+	relayActionResume = "resume"
+)
+
+var (
+	errUnknownRelay = errors.New("unknown relay")
+	errRelayExists  = errors.New("relay already exists")
+)
+
+// adminRelays are the relay changes made with the admin API. They apply on top of the relays of the flags, the config
+// file and the relay sources, and are kept across reloads.
+type adminRelays struct {
+	added   []types.RelayEntry
+	removed map[phase0.BLSPubKey]bool
+	paused  map[phase0.BLSPubKey]bool
+}
+
+func newAdminRelays() adminRelays {
+	return adminRelays{
+		removed: make(map[phase0.BLSPubKey]bool),
+		paused:  make(map[phase0.BLSPubKey]bool),
+	}
+}
+
+// withAdded returns the relays including the added ones, and including removed and paused relays
+func (a adminRelays) withAdded(relays []types.RelayEntry) []types.RelayEntry {
+	return mergeRelays(relays, a.added)
+}
+
+// active returns the relays to use, without removed and paused relays
+func (a adminRelays) active(relays []types.RelayEntry) []types.RelayEntry {
+	if len(a.removed) == 0 && len(a.paused) == 0 && len(a.added) == 0 {
+		return relays
+	}
+	return slices.DeleteFunc(slices.Clone(a.withAdded(relays)), func(relay types.RelayEntry) bool {
+		return a.removed[relay.PublicKey] || a.paused[relay.PublicKey]
+	})
+}
+
+// relayChange is a pending relay change of the admin API
+type relayChange struct {
+	action string
+	relay  types.RelayEntry // only for add
+	pubkey phase0.BLSPubKey
+}
+
+// apply applies the relay change
+func (a *adminRelays) apply(change relayChange) {
+	switch change.action {
+	case relayActionAdd:
+		delete(a.removed, change.pubkey)
+		if !containsRelay(a.added, change.relay) {
+			a.added = append(a.added, change.relay)
+		}
+	case relayActionRemove:
+		a.added = slices.DeleteFunc(a.added, func(relay types.RelayEntry) bool { return relay.PublicKey == change.pubkey })
+		a.removed[change.pubkey] = true
+		delete(a.paused, change.pubkey)
+	case relayActionPause:
+		a.paused[change.pubkey] = true
+	case relayActionResume:
+		delete(a.paused, change.pubkey)
+	}
+}
+
+// scheduleRelayChange queues a relay change. Pending changes are applied in order between slots, see
+// untilRelayListApply. It returns when they are applied.
+func (m *BoostService) scheduleRelayChange(change relayChange) time.Time {
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+
+	m.relayChanges = append(m.relayChanges, change)
+	if m.relayChangesApplyAt.IsZero() {
+		wait := m.untilRelayListApply(time.Now())
+		m.relayChangesApplyAt = time.Now().Add(wait)
+		time.AfterFunc(wait, m.applyRelayChanges)
+	}
+	return m.relayChangesApplyAt
+}
+
+// applyRelayChanges applies the pending relay changes
+func (m *BoostService) applyRelayChanges() {
+	defer m.replayRegistrationsToNewRelays(m.currentConfig())
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+
+	for _, change := range m.relayChanges {
+		m.adminRelays.apply(change)
+		m.log.WithFields(logrus.Fields{
+			"action": change.action,
+			"relay":  change.pubkey.String(),
+		}).Info("applied admin relay change")
+	}
+	m.relayChanges = nil
+	m.relayChangesApplyAt = time.Time{}
+}
+
+// knownRelays returns the relays which are in use or paused, and the relays pending to be added
+func (m *BoostService) knownRelays() []types.RelayEntry {
+	m.configLock.RLock()
+	defer m.configLock.RUnlock()
+
+	relays := slices.DeleteFunc(slices.Clone(m.adminRelays.withAdded(mergeRelays(m.relays, m.remoteRelays))), func(relay types.RelayEntry) bool {
+		return m.adminRelays.removed[relay.PublicKey]
+	})
+	for _, change := range m.relayChanges {
+		if change.action == relayActionAdd {
+			relays = append(relays, change.relay)
+		}
+	}
+	return relays
+}
+
+// AdminRelay is a relay in the admin API relay list
+type AdminRelay struct {
+	URL    string `json:"url"`
+	Paused bool   `json:"paused"`
+}
+
+// AdminPendingRelayChange is a relay change in the admin API relay list which is not applied yet
+type AdminPendingRelayChange struct {
+	Action string `json:"action"`
+	Relay  string `json:"relay"`
+}
+
+// AdminRelaysResponse is the response of the admin API relay list
+type AdminRelaysResponse struct {
+	Relays  []AdminRelay              `json:"relays"`
+	Pending []AdminPendingRelayChange `json:"pending"`
+}
+
+// AdminRelayChangeResponse is the response of the admin API relay changes
+type AdminRelayChangeResponse struct {
+	Action  string    `json:"action"`
+	Relay   string    `json:"relay"`
+	ApplyAt time.Time `json:"apply_at"`
+}
+
+// handleAdminRelays lists the relays, including paused relays and pending changes
+func (m *BoostService) handleAdminRelays(w http.ResponseWriter, req *http.Request) {
+	if !m.authorizedAdminRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+
+	m.configLock.RLock()
+	resp := AdminRelaysResponse{Relays: []AdminRelay{}, Pending: []AdminPendingRelayChange{}}
+	for _, relay := range m.adminRelays.withAdded(mergeRelays(m.relays, m.remoteRelays)) {
+		if !m.adminRelays.removed[relay.PublicKey] {
+			resp.Relays = append(resp.Relays, AdminRelay{URL: relay.String(), Paused: m.adminRelays.paused[relay.PublicKey]})
+		}
+	}
+	for _, change := range m.relayChanges {
+		resp.Pending = append(resp.Pending, AdminPendingRelayChange{Action: change.action, Relay: change.pubkey.String()})
+	}
+	m.configLock.RUnlock()
+
+	m.respondOK(w, resp)
+}
+
+// handleAdminAddRelay adds the relay of the request body, i.e. {"url": "https://0x...@relay.example.com"}
+func (m *BoostService) handleAdminAddRelay(w http.ResponseWriter, req *http.Request) {
+	if !m.authorizedAdminRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+
+	var body struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	relay, err := types.NewRelayEntry(body.URL)
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if containsRelay(m.knownRelays(), relay) {
+		m.respondError(w, http.StatusConflict, errRelayExists.Error())
+		return
+	}
+
+	m.respondRelayChange(w, relayChange{action: relayActionAdd, relay: relay, pubkey: relay.PublicKey})
+}
+
+// handleAdminRemoveRelay removes the relay of the pubkey. Like pause and resume, it applies to all relay URLs with the
+// pubkey, i.e. the regional endpoints of a relay.
+func (m *BoostService) handleAdminRemoveRelay(w http.ResponseWriter, req *http.Request) {
+	m.handleAdminRelayChange(w, req, relayActionRemove)
+}
+
+// handleAdminPauseRelay pauses the relay of the pubkey, it is not used until resumed
+func (m *BoostService) handleAdminPauseRelay(w http.ResponseWriter, req *http.Request) {
+	m.handleAdminRelayChange(w, req, relayActionPause)
+}
+
+// handleAdminResumeRelay resumes the paused relay of the pubkey
+func (m *BoostService) handleAdminResumeRelay(w http.ResponseWriter, req *http.Request) {
+	m.handleAdminRelayChange(w, req, relayActionResume)
+}
+
+func (m *BoostService) handleAdminRelayChange(w http.ResponseWriter, req *http.Request, action string) {
+	if !m.authorizedAdminRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+
+	pubkey, err := utils.HexToPubkey(mux.Vars(req)["pubkey"])
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, errInvalidPubkey.Error())
+		return
+	}
+	if !slices.ContainsFunc(m.knownRelays(), func(relay types.RelayEntry) bool { return relay.PublicKey == pubkey }) {
+		m.respondError(w, http.StatusNotFound, errUnknownRelay.Error())
+		return
+	}
+
+	m.respondRelayChange(w, relayChange{action: action, pubkey: pubkey})
+}
+
+func (m *BoostService) respondRelayChange(w http.ResponseWriter, change relayChange) {
+	applyAt := m.scheduleRelayChange(change)
+	m.log.WithFields(logrus.Fields{
+		"action":  change.action,
+		"relay":   change.pubkey.String(),
+		"applyAt": applyAt,
+	}).Info("scheduled admin relay change")
+	m.respondOK(w, AdminRelayChangeResponse{Action: change.action, Relay: change.pubkey.String(), ApplyAt: applyAt})
+}
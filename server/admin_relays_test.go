@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRelays(t *testing.T) {
+	adminRequest := func(t *testing.T, backend *testBackend, method, path, token string, body []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+	relayPath := func(relay types.RelayEntry, suffix string) string {
+		return strings.Replace(params.PathAdminRelay, "{pubkey:0x[a-fA-F0-9]+}", relay.PublicKey.String(), 1) + suffix
+	}
+	relayURLs := func(relays []types.RelayEntry) []string {
+		return types.RelayEntriesToStrings(relays)
+	}
+
+	t.Run("Disabled without token", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := adminRequest(t, backend, http.MethodGet, params.PathAdminRelays, "", nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adminToken = "secret"
+		rr := adminRequest(t, backend, http.MethodPost, relayPath(backend.relays[0].RelayEntry, "/pause"), "wrong", nil)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	newRelay := func(t *testing.T, url string) types.RelayEntry {
+		t.Helper()
+		relay, err := types.NewRelayEntry(url)
+		require.NoError(t, err)
+		return relay
+	}
+	relayA := newRelay(t, testRelayListRelayA)
+	relayB := newRelay(t, testRelayListRelayB)
+
+	t.Run("Pauses and resumes relays between slots", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adminToken = "secret"
+		require.NoError(t, backend.boost.Reload(ReloadOpts{Relays: []types.RelayEntry{relayA, relayB}}))
+
+		rr := adminRequest(t, backend, http.MethodPost, relayPath(relayA, "/pause"), "secret", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := new(AdminRelayChangeResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, relayActionPause, resp.Action)
+		require.True(t, resp.ApplyAt.After(time.Now()))
+
+		// Not applied until the next slot
+		require.Len(t, backend.boost.currentConfig().relays, 2)
+		backend.boost.applyRelayChanges()
+		require.Equal(t, []string{testRelayListRelayB}, relayURLs(backend.boost.currentConfig().relays))
+
+		rr = adminRequest(t, backend, http.MethodGet, params.PathAdminRelays, "secret", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		list := new(AdminRelaysResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), list))
+		require.Equal(t, []AdminRelay{{URL: testRelayListRelayA, Paused: true}, {URL: testRelayListRelayB}}, list.Relays)
+
+		rr = adminRequest(t, backend, http.MethodPost, relayPath(relayA, "/resume"), "secret", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		backend.boost.applyRelayChanges()
+		require.Equal(t, []string{testRelayListRelayA, testRelayListRelayB}, relayURLs(backend.boost.currentConfig().relays))
+	})
+
+	t.Run("Adds and removes relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adminToken = "secret"
+		require.NoError(t, backend.boost.Reload(ReloadOpts{Relays: []types.RelayEntry{relayA}}))
+
+		body, err := json.Marshal(map[string]string{"url": testRelayListRelayB})
+		require.NoError(t, err)
+		rr := adminRequest(t, backend, http.MethodPost, params.PathAdminRelays, "secret", body)
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = adminRequest(t, backend, http.MethodPost, params.PathAdminRelays, "secret", body)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		backend.boost.applyRelayChanges()
+		require.Equal(t, []string{testRelayListRelayA, testRelayListRelayB}, relayURLs(backend.boost.currentConfig().relays))
+
+		// Admin changes are kept across reloads
+		require.NoError(t, backend.boost.Reload(ReloadOpts{Relays: []types.RelayEntry{relayA}}))
+		require.Len(t, backend.boost.currentConfig().relays, 2)
+
+		rr = adminRequest(t, backend, http.MethodDelete, relayPath(relayA, ""), "secret", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		backend.boost.applyRelayChanges()
+		require.Equal(t, []string{testRelayListRelayB}, relayURLs(backend.boost.currentConfig().relays))
+		require.Len(t, backend.boost.relays, 1, "static relays are unchanged")
+
+		rr = adminRequest(t, backend, http.MethodPost, relayPath(relayA, "/pause"), "secret", nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid relay", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adminToken = "secret"
+		rr := adminRequest(t, backend, http.MethodPost, params.PathAdminRelays, "secret", []byte(`{"url": "https://relay.example.com"}`))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requestToken returns the bearer token of the request, or the password of its basic auth credentials, for clients
+// which only support basic auth. The user name is ignored.
+func requestToken(req *http.Request) (string, bool) {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return token, true
+	}
+	if _, password, ok := req.BasicAuth(); ok {
+		return password, true
+	}
+	return "", false
+}
+
+// authorizedRequest checks the bearer token or basic auth password of the request
+func authorizedRequest(req *http.Request, token string) bool {
+	got, ok := requestToken(req)
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// requireToken is a middleware refusing requests without the token with 401, if a token is set
+func (m *BoostService) requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !authorizedRequest(req, token) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mev-boost"`)
+			m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
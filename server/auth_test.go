@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAuthentication(t *testing.T) {
+	// get returns the response code of a request with the authentication
+	get := func(backend *testBackend, path string, authenticate func(req *http.Request)) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		authenticate(req)
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr.Code
+	}
+	bearer := func(token string) func(req *http.Request) {
+		return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+	}
+	basic := func(password string) func(req *http.Request) {
+		return func(req *http.Request) { req.SetBasicAuth("prometheus", password) }
+	}
+	none := func(*http.Request) {}
+
+	t.Run("The monitoring endpoints are open without metrics token", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.Equal(t, http.StatusOK, get(backend, params.PathRelayStats, none))
+	})
+
+	t.Run("The metrics token protects the monitoring endpoints", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.metricsToken = "secret"
+		for _, path := range []string{params.PathRelayStats, params.PathRelayScores} {
+			require.Equal(t, http.StatusUnauthorized, get(backend, path, none))
+			require.Equal(t, http.StatusUnauthorized, get(backend, path, bearer("wrong")))
+			require.Equal(t, http.StatusUnauthorized, get(backend, path, basic("wrong")))
+			require.Equal(t, http.StatusOK, get(backend, path, bearer("secret")))
+			require.Equal(t, http.StatusOK, get(backend, path, basic("secret")))
+		}
+		// The builder API stays open
+		require.Equal(t, http.StatusOK, get(backend, params.PathStatus, none))
+	})
+
+	t.Run("The metrics token protects the verbose status", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.metricsToken = "secret"
+		require.Equal(t, http.StatusUnauthorized, get(backend, params.PathStatus+"?verbose=true", none))
+		require.Equal(t, http.StatusUnauthorized, get(backend, params.PathStatus+"?verbose=true", bearer("wrong")))
+		require.Equal(t, http.StatusOK, get(backend, params.PathStatus+"?verbose=true", bearer("secret")))
+		require.Equal(t, http.StatusOK, get(backend, params.PathStatus+"?verbose=false", none))
+	})
+
+	t.Run("The metrics token protects the validator registration status", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.metricsToken = "secret"
+		path := "/api/v1/validator-registrations/0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+		require.Equal(t, http.StatusUnauthorized, get(backend, path, none))
+		require.Equal(t, http.StatusUnauthorized, get(backend, path, basic("wrong")))
+		require.Zero(t, backend.relays[0].GetRequestCount(params.PathDataValidatorRegistration))
+		require.NotEqual(t, http.StatusUnauthorized, get(backend, path, bearer("secret")))
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathDataValidatorRegistration))
+	})
+
+	t.Run("The admin API takes basic auth", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.adminToken = "secret"
+		require.Equal(t, http.StatusUnauthorized, get(backend, params.PathAdminRelays, basic("wrong")))
+		require.Equal(t, http.StatusOK, get(backend, params.PathAdminRelays, basic("secret")))
+	})
+
+	t.Run("The metrics server is protected by the metrics token", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.metricsToken = "secret"
+		scrape := func(authenticate func(req *http.Request)) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			authenticate(req)
+			rr := httptest.NewRecorder()
+			backend.boost.metricsHandler().ServeHTTP(rr, req)
+			return rr
+		}
+		rr := scrape(none)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, `Basic realm="mev-boost"`, rr.Header().Get("WWW-Authenticate"))
+		require.Equal(t, http.StatusOK, scrape(bearer("secret")).Code)
+	})
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// Bid anomaly actions decide what happens to bids above the max-bid bounds
+const (
+	BidAnomalyReject = "reject" // the bid is ignored
+	BidAnomalyWarn   = "warn"   // the bid is used with a warning
+)
+
+// BidAnomalyActions are the names of all bid anomaly actions
+var BidAnomalyActions = []string{BidAnomalyReject, BidAnomalyWarn}
+
+// bidAnomaly returns why the bid value is implausibly high, empty if it's within the max-bid bounds. Relays and
+// builders which bid absurd values often fail to deliver the payload.
+func (m *BoostService) bidAnomaly(value *uint256.Int) string {
+	if m.maxBid.BigInt().Sign() > 0 && value.CmpBig(m.maxBid.BigInt()) > 0 {
+		return fmt.Sprintf("above the max-bid of %s wei", m.maxBid.String())
+	}
+	if m.maxBidMedianMultiple <= 0 || m.bidValues == nil {
+		return ""
+	}
+	median := m.bidValues.median()
+	if median == nil || median.Sign() == 0 {
+		return ""
+	}
+	limit, _ := new(big.Float).Mul(new(big.Float).SetInt(median), big.NewFloat(m.maxBidMedianMultiple)).Int(nil)
+	if value.CmpBig(limit) > 0 {
+		return fmt.Sprintf("more than %v times the median recent bid value of %s wei", m.maxBidMedianMultiple, median)
+	}
+	return ""
+}
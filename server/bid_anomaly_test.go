@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidAnomaly(t *testing.T) {
+	t.Run("Bounds", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.Empty(t, backend.boost.bidAnomaly(uint256.NewInt(1e18)), "disabled")
+
+		backend.boost.maxBid = types.IntToU256(1000)
+		require.Empty(t, backend.boost.bidAnomaly(uint256.NewInt(1000)))
+		require.NotEmpty(t, backend.boost.bidAnomaly(uint256.NewInt(1001)))
+
+		// Without recent bids, only the max-bid applies
+		backend.boost.maxBid = types.IntToU256(0)
+		backend.boost.maxBidMedianMultiple = 2.5
+		backend.boost.bidValues = newBidValueHistory(10)
+		require.Empty(t, backend.boost.bidAnomaly(uint256.NewInt(1e18)))
+
+		backend.boost.bidValues.record(1, big.NewInt(100))
+		backend.boost.bidValues.record(2, big.NewInt(200))
+		backend.boost.bidValues.record(3, big.NewInt(300))
+		require.Empty(t, backend.boost.bidAnomaly(uint256.NewInt(500)))
+		require.NotEmpty(t, backend.boost.bidAnomaly(uint256.NewInt(501)))
+	})
+
+	t.Run("Unknown action", func(t *testing.T) {
+		_, err := NewBoostService(BoostServiceOpts{
+			Log:                   mock.TestLog,
+			Relays:                []types.RelayEntry{mock.NewRelay(t).RelayEntry},
+			GenesisForkVersionHex: "0x00000000",
+			BidAnomalyAction:      "ignore",
+		})
+		require.ErrorIs(t, err, errUnknownBidAnomalyAction)
+	})
+
+	// Relay 0 bids absurdly high
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	getHeader := func(action string) uint64 {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.maxBid = types.IntToU256(1e6)
+		backend.boost.bidAnomalyAction = action
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			1e9,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return value.Uint64()
+	}
+
+	t.Run("Rejects implausible bids", func(t *testing.T) {
+		require.Equal(t, uint64(12345), getHeader(BidAnomalyReject))
+	})
+
+	t.Run("Warns about implausible bids", func(t *testing.T) {
+		require.Equal(t, uint64(1e9), getHeader(BidAnomalyWarn))
+	})
+}
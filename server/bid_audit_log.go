@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Rejection reasons of bids in the bid audit log
+const (
+	bidRejectedEmptyBlockHash = "empty_block_hash"
+	bidRejectedPubkeyMismatch = "pubkey_mismatch"
+	bidRejectedSignature      = "invalid_signature"
+	bidRejectedParentHash     = "parent_hash_mismatch"
+	bidRejectedTimestamp      = "timestamp_mismatch"
+	bidRejectedZeroValue      = "zero_value"
+	bidRejectedBelowMinBid    = "below_min_bid"
+	bidRejectedOutbid         = "outbid"
+	bidRejectedReplaced       = "replaced" // replaced by a later bid of the relay while polling
+	bidRejectedAnomalousValue = "anomalous_value"
+	bidRejectedGasLimit       = "gas_limit_mismatch"
+	bidRejectedConstraints    = "constraints_unproven"
+	bidRejectedScript         = "script_rejected"
+	bidRejectedPlugin         = "plugin_rejected"
+	bidRejectedPolicyVeto     = "policy_veto"
+	bidRejectedPolicyFailure  = "policy_unavailable" // the policy service failed and the bid policy fails closed
+)
+
+// BidAuditRecord is a single bid received from a relay, as written to the bid audit log
+type BidAuditRecord struct {
+	Slot              uint64 `json:"slot"`
+	SlotUID           string `json:"slot_uid"`
+	Relay             string `json:"relay"`
+	Value             string `json:"value"`
+	BlockHash         string `json:"block_hash"`
+	ParentHash        string `json:"parent_hash"`
+	BlockNumber       uint64 `json:"block_number"`
+	ReceivedAtMs      int64  `json:"received_at_ms"`
+	MsIntoSlot        int64  `json:"ms_into_slot"`
+	RequestDurationMs int64  `json:"request_duration_ms"`
+	Selected          bool   `json:"selected"`
+	RejectionReason   string `json:"rejection_reason,omitempty"`
+}
+
+// Selectable returns whether the bid was selected or rejected for a reason which depends on the bid selection settings,
+// the min-bid or a higher bid, so it could be selected with other settings
+func (r BidAuditRecord) Selectable() bool {
+	return r.Selected || r.RejectionReason == "" || r.RejectionReason == bidRejectedOutbid || r.RejectionReason == bidRejectedBelowMinBid
+}
+
+// RejectedBeforeMinBid returns whether the bid was rejected before the min-bid check, its value doesn't count for the
+// relative min-bid
+func (r BidAuditRecord) RejectedBeforeMinBid() bool {
+	return slices.Contains([]string{
+		bidRejectedEmptyBlockHash, bidRejectedPubkeyMismatch, bidRejectedSignature, bidRejectedParentHash,
+		bidRejectedTimestamp, bidRejectedZeroValue, bidRejectedAnomalousValue, bidRejectedGasLimit,
+	}, r.RejectionReason)
+}
+
+// bidAuditLogQueueSize is the number of getHeader auctions waiting to be written to the bid audit log, the records of
+// further auctions are dropped
+const bidAuditLogQueueSize = 64
+
+// bidAuditLog appends BidAuditRecords as JSON lines to a writer. The records are written by a single goroutine, so
+// slow writes don't delay the getHeader response.
+type bidAuditLog struct {
+	log    *logrus.Entry
+	enc    *json.Encoder
+	mu     sync.Mutex
+	closed bool
+	queue  chan []*BidAuditRecord
+	done   chan struct{}
+}
+
+func newBidAuditLog(w io.Writer, log *logrus.Entry) *bidAuditLog {
+	l := &bidAuditLog{
+		log:   log,
+		enc:   json.NewEncoder(w),
+		queue: make(chan []*BidAuditRecord, bidAuditLogQueueSize),
+		done:  make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// write queues the records of an auction, it doesn't block if the queue is full
+func (l *bidAuditLog) write(records []*BidAuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.queue <- records:
+	default:
+		l.log.WithField("records", len(records)).Error("bid audit log queue is full, dropping the records")
+	}
+}
+
+// run appends the queued records, one JSON object per line, until the bid audit log is closed
+func (l *bidAuditLog) run() {
+	defer close(l.done)
+	for records := range l.queue {
+		for _, record := range records {
+			if err := l.enc.Encode(record); err != nil {
+				l.log.WithError(err).Error("could not write bid audit log")
+				break
+			}
+		}
+	}
+}
+
+// close writes the queued records and stops the writer, later records are dropped
+func (l *bidAuditLog) close() {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidAuditLog(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+
+	backend := newTestBackend(t, 3, time.Second)
+	buf := new(bytes.Buffer)
+	backend.boost.bidAuditLog = newBidAuditLog(buf, mock.TestLog)
+
+	values := []uint64{12344, 12346, 12345}
+	blockHashes := []string{
+		"0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0xa38385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+	}
+	for i, relay := range backend.relays {
+		relay.GetHeaderResponse = relay.MakeGetHeaderResponse(
+			values[i],
+			blockHashes[i],
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+	}
+
+	rr := backend.request(t, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	backend.boost.bidAuditLog.close()
+
+	records := make(map[string]BidAuditRecord)
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		record := BidAuditRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records[record.BlockHash] = record
+	}
+	require.Len(t, records, 3)
+
+	require.Equal(t, bidRejectedBelowMinBid, records[blockHashes[0]].RejectionReason)
+	require.False(t, records[blockHashes[0]].Selected)
+
+	require.True(t, records[blockHashes[1]].Selected)
+	require.Empty(t, records[blockHashes[1]].RejectionReason)
+	require.Equal(t, "12346", records[blockHashes[1]].Value)
+	require.Equal(t, uint64(1), records[blockHashes[1]].Slot)
+
+	require.Equal(t, bidRejectedOutbid, records[blockHashes[2]].RejectionReason)
+	require.False(t, records[blockHashes[2]].Selected)
+}
+
+func TestBidAuditLogClose(t *testing.T) {
+	buf := new(bytes.Buffer)
+	auditLog := newBidAuditLog(buf, mock.TestLog)
+	auditLog.write([]*BidAuditRecord{{Slot: 1}, {Slot: 2}})
+	auditLog.close()
+	require.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	// Records of requests still in flight after the shutdown are dropped
+	auditLog.write([]*BidAuditRecord{{Slot: 3}})
+	auditLog.close()
+	require.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestBidAuditRecordRejections(t *testing.T) {
+	for _, tc := range []struct {
+		record               BidAuditRecord
+		selectable           bool
+		rejectedBeforeMinBid bool
+	}{
+		{record: BidAuditRecord{Selected: true}, selectable: true},
+		{record: BidAuditRecord{RejectionReason: bidRejectedOutbid}, selectable: true},
+		{record: BidAuditRecord{RejectionReason: bidRejectedBelowMinBid}, selectable: true},
+		{record: BidAuditRecord{RejectionReason: bidRejectedScript}},
+		{record: BidAuditRecord{RejectionReason: bidRejectedSignature}, rejectedBeforeMinBid: true},
+	} {
+		require.Equal(t, tc.selectable, tc.record.Selectable(), tc.record.RejectionReason)
+		require.Equal(t, tc.rejectedBeforeMinBid, tc.record.RejectedBeforeMinBid(), tc.record.RejectionReason)
+	}
+}
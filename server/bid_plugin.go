@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// The bid policy ABI of WASM plugins. A plugin exports its memory and alloc(size i32) i32, which returns the address
+// of size bytes for the input of the next call. The policy functions are optional:
+//
+//   - filter(ptr, len i32) i32 gets the bid as JSON and returns 0 to reject it
+//   - score(ptr, len i32) f64 gets the bid as JSON and returns its score, 0 rejects it
+//   - tiebreak(ptr, len i32) i32 gets two bids of the same value as JSON array and returns a positive number if the
+//     first one wins, a negative number if the second one wins and 0 to leave it to the configured tiebreaker
+const (
+	bidPluginAlloc    = "alloc"
+	bidPluginFilter   = "filter"
+	bidPluginScore    = "score"
+	bidPluginTiebreak = "tiebreak"
+)
+
+const (
+	// bidPluginCallTimeout bounds each call of a plugin function, so a runaway plugin can't delay getHeader
+	bidPluginCallTimeout = 50 * time.Millisecond
+	// bidPluginMemoryPages bounds the memory of a plugin to 16 MiB
+	bidPluginMemoryPages = 256
+	// bidPluginCloseDelay is how long a replaced plugin is kept for the auctions which still use it
+	bidPluginCloseDelay = time.Minute
+)
+
+var (
+	errBidPluginABI    = errors.New("WASM module doesn't implement the bid policy ABI")
+	errBidPluginResult = errors.New("invalid bid plugin result")
+)
+
+// pluginBid is the JSON input of the filter and score functions of a plugin
+type pluginBid struct {
+	BlockHash   string      `json:"block_hash"`
+	Slot        uint64      `json:"slot,string"`
+	ParentHash  string      `json:"parent_hash"`
+	Builder     string      `json:"builder"`
+	Value       string      `json:"value"`
+	BlockNumber uint64      `json:"block_number,string"`
+	GasLimit    uint64      `json:"gas_limit,string"`
+	Relay       string      `json:"relay"`
+	RelayLabels []string    `json:"relay_labels"`
+	RelayStats  RelayStats  `json:"relay_stats"`
+	RelayHealth RelayHealth `json:"relay_health"`
+}
+
+// pluginCandidate is the JSON input of the tiebreak function of a plugin, for each of the two bids
+type pluginCandidate struct {
+	BlockHash string   `json:"block_hash"`
+	Value     string   `json:"value"`
+	Relays    []string `json:"relays"`
+	LatencyMs int64    `json:"latency_ms"`
+}
+
+// BidPlugin is a WASM module implementing the bid policy ABI. The module has no access to the file system, network or
+// real clock. Calls are serialized, a single instance of the module handles them all.
+type BidPlugin struct {
+	filename string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+
+	mu     sync.Mutex
+	module api.Module // instantiated on first use, and again after a call timed out
+}
+
+// LoadBidPlugin compiles the WASM module, which has to export memory and alloc
+func LoadBidPlugin(filename string) (*BidPlugin, error) {
+	wasm, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(bidPluginMemoryPages))
+
+	// Modules built with WASI toolchains (i.e. TinyGo or Rust) import it, without any access to the host
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, err
+	}
+	exports := compiled.ExportedFunctions()
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok || exports[bidPluginAlloc] == nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("%w: missing memory or %s export", errBidPluginABI, bidPluginAlloc)
+	}
+	return &BidPlugin{filename: filename, runtime: runtime, compiled: compiled}, nil
+}
+
+// Close releases the module
+func (p *BidPlugin) Close() error {
+	return p.runtime.Close(context.Background())
+}
+
+// exports returns true if the plugin implements the function
+func (p *BidPlugin) exports(function string) bool {
+	return p.compiled.ExportedFunctions()[function] != nil
+}
+
+// call passes the input as JSON to the function and returns its result
+func (p *BidPlugin) call(function string, input any) (uint64, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return 0, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), bidPluginCallTimeout)
+	defer cancel()
+	if p.module == nil || p.module.IsClosed() {
+		config := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+		if p.module, err = p.runtime.InstantiateModule(ctx, p.compiled, config); err != nil {
+			return 0, err
+		}
+	}
+
+	ret, err := p.module.ExportedFunction(bidPluginAlloc).Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, err
+	}
+	ptr := uint32(ret[0])
+	if !p.module.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("%w: %s returned an address out of memory", errBidPluginResult, bidPluginAlloc)
+	}
+	ret, err = p.module.ExportedFunction(function).Call(ctx, uint64(ptr), uint64(len(data)))
+	if err != nil {
+		return 0, err
+	}
+	return ret[0], nil
+}
+
+// pluginScoreBid runs the filter and score functions of the plugin with the bid of the relay, and returns the score of
+// the bid: 0 rejects it, 1 if the plugin doesn't score bids. A failing plugin accepts the bid with score 1, like a
+// failing bid script.
+func (m *BoostService) pluginScoreBid(log *logrus.Entry, plugin *BidPlugin, relay types.RelayEntry, slot phase0.Slot, info bidInfo) float64 {
+	bid := pluginBid{
+		BlockHash:   info.blockHash.String(),
+		Slot:        uint64(slot),
+		ParentHash:  info.parentHash.String(),
+		Builder:     info.pubkey.String(),
+		Value:       info.value.Dec(),
+		BlockNumber: info.blockNumber,
+		GasLimit:    info.gasLimit,
+		Relay:       relay.GetURI(""),
+		RelayLabels: relay.Labels,
+		RelayStats:  m.relayStats.snapshot([]types.RelayEntry{relay})[0],
+		RelayHealth: m.relayHealth.snapshot([]types.RelayEntry{relay})[0],
+	}
+	log = log.WithField("bidPlugin", plugin.filename)
+	if plugin.exports(bidPluginFilter) {
+		accepted, err := plugin.call(bidPluginFilter, bid)
+		if err != nil {
+			log.WithError(err).Error("bid plugin filter failed, accepting the bid")
+			return 1
+		}
+		if uint32(accepted) == 0 {
+			return 0
+		}
+	}
+	if !plugin.exports(bidPluginScore) {
+		return 1
+	}
+	ret, err := plugin.call(bidPluginScore, bid)
+	if err != nil {
+		log.WithError(err).Error("bid plugin score failed, accepting the bid")
+		return 1
+	}
+	score := api.DecodeF64(ret)
+	if score < 0 || math.IsNaN(score) || math.IsInf(score, 0) {
+		log.WithError(fmt.Errorf("%w: score %v", errBidPluginResult, score)).Error("bid plugin score failed, accepting the bid")
+		return 1
+	}
+	return score
+}
+
+// tiebreaker returns a tiebreaker which asks the tiebreak function of the plugin first, and the fallback if the plugin
+// doesn't decide or fails
+func (p *BidPlugin) tiebreaker(log *logrus.Entry, fallback tiebreaker) tiebreaker {
+	if !p.exports(bidPluginTiebreak) {
+		return fallback
+	}
+	return func(bid, best *Bid) bool {
+		input := make([]pluginCandidate, 0, 2)
+		for _, b := range []*Bid{bid, best} {
+			relays := make([]string, 0, len(b.Relays))
+			for _, relay := range b.Relays {
+				relays = append(relays, relay.GetURI(""))
+			}
+			input = append(input, pluginCandidate{
+				BlockHash: b.BlockHash.String(),
+				Value:     b.Value.Dec(),
+				Relays:    relays,
+				LatencyMs: b.Latency.Milliseconds(),
+			})
+		}
+		ret, err := p.call(bidPluginTiebreak, input)
+		if err != nil {
+			log.WithError(err).WithField("bidPlugin", p.filename).Error("bid plugin tiebreak failed")
+			return fallback(bid, best)
+		}
+		switch decision := int32(ret); {
+		case decision > 0:
+			return true
+		case decision < 0:
+			return false
+		}
+		return fallback(bid, best)
+	}
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// wasmFunction is an exported function of a test plugin, with its type: 0 is (i32) i32, 1 is (i32, i32) i32 and 2 is
+// (i32, i32) f64
+type wasmFunction struct {
+	name string
+	typ  byte
+	body []byte // the instructions, without locals
+}
+
+// The functions of the test plugins
+var (
+	// alloc returns the address 1024 for any input
+	wasmAlloc = wasmFunction{"alloc", 0, []byte{0x41, 0x80, 0x08}}
+	// filter rejects the bids whose block hash ends with 8, at offset 80 of the JSON input
+	wasmFilter = wasmFunction{"filter", 1, []byte{0x20, 0x00, 0x2d, 0x00, 0x50, 0x41, '8', 0x47}}
+	// scoreLoop never returns
+	wasmScoreLoop = wasmFunction{"score", 2, append([]byte{0x03, 0x40, 0x0c, 0x00, 0x0b}, wasmF64(1)...)}
+)
+
+// wasmScore returns the score
+func wasmScore(score float64) wasmFunction {
+	return wasmFunction{"score", 2, wasmF64(score)}
+}
+
+// wasmTiebreak returns the decision, which has to be between -64 and 63
+func wasmTiebreak(decision int8) wasmFunction {
+	return wasmFunction{"tiebreak", 1, []byte{0x41, byte(decision) & 0x7f}}
+}
+
+func wasmF64(value float64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{0x44}, math.Float64bits(value))
+}
+
+// writeBidPlugin writes a WASM module exporting one page of memory and the functions
+func writeBidPlugin(t *testing.T, functions ...wasmFunction) string {
+	t.Helper()
+	section := func(id byte, content []byte) []byte {
+		require.Less(t, len(content), 128)
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	funcTypes := []byte{3, 0x60, 1, 0x7f, 1, 0x7f, 0x60, 2, 0x7f, 0x7f, 1, 0x7f, 0x60, 2, 0x7f, 0x7f, 1, 0x7c}
+	funcs := []byte{byte(len(functions))}
+	exports := []byte{byte(len(functions) + 1), 6, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0}
+	code := []byte{byte(len(functions))}
+	for i, f := range functions {
+		funcs = append(funcs, f.typ)
+		exports = append(append(append(exports, byte(len(f.name))), f.name...), 0x00, byte(i))
+		body := append(append([]byte{0}, f.body...), 0x0b)
+		code = append(append(code, byte(len(body))), body...)
+	}
+
+	wasm := []byte{0x00, 'a', 's', 'm', 1, 0, 0, 0}
+	wasm = append(wasm, section(1, funcTypes)...)
+	wasm = append(wasm, section(3, funcs)...)
+	wasm = append(wasm, section(5, []byte{1, 0, 1})...)
+	wasm = append(wasm, section(7, exports)...)
+	wasm = append(wasm, section(10, code)...)
+	filename := filepath.Join(t.TempDir(), "plugin.wasm")
+	require.NoError(t, os.WriteFile(filename, wasm, 0o600))
+	return filename
+}
+
+func loadBidPlugin(t *testing.T, functions ...wasmFunction) *BidPlugin {
+	t.Helper()
+	plugin, err := LoadBidPlugin(writeBidPlugin(t, functions...))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = plugin.Close() })
+	return plugin
+}
+
+func TestLoadBidPlugin(t *testing.T) {
+	t.Run("Invalid module", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "plugin.wasm")
+		require.NoError(t, os.WriteFile(filename, []byte("not wasm"), 0o600))
+		_, err := LoadBidPlugin(filename)
+		require.Error(t, err)
+	})
+
+	t.Run("Without alloc", func(t *testing.T) {
+		_, err := LoadBidPlugin(writeBidPlugin(t, wasmFilter))
+		require.ErrorIs(t, err, errBidPluginABI)
+	})
+}
+
+func TestPluginScoreBid(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	relay := backend.relays[0].RelayEntry
+	info := func(blockHash string) bidInfo {
+		return bidInfo{blockHash: mock.HexToHash(blockHash), value: uint256.NewInt(20000)}
+	}
+	accepted := info("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	rejected := info("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8")
+
+	plugin := loadBidPlugin(t, wasmAlloc)
+	require.InDelta(t, 1, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, rejected), 0)
+
+	plugin = loadBidPlugin(t, wasmAlloc, wasmFilter, wasmScore(1.5))
+	require.InDelta(t, 1.5, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, accepted), 0)
+	require.InDelta(t, 0, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, rejected), 0)
+
+	// Invalid scores accept the bid
+	plugin = loadBidPlugin(t, wasmAlloc, wasmScore(-1))
+	require.InDelta(t, 1, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, accepted), 0)
+
+	// A plugin which doesn't return in time accepts the bid, and is instantiated again for the next call
+	plugin = loadBidPlugin(t, wasmAlloc, wasmFilter, wasmScoreLoop)
+	require.InDelta(t, 1, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, accepted), 0)
+	require.InDelta(t, 0, backend.boost.pluginScoreBid(mock.TestLog, plugin, relay, 1, rejected), 0)
+}
+
+func TestBidPluginTiebreaker(t *testing.T) {
+	bid := newBidCandidate(bidInfo{blockHash: mock.HexToHash("0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), value: uint256.NewInt(12345)}).bid()
+	best := newBidCandidate(bidInfo{blockHash: mock.HexToHash("0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), value: uint256.NewInt(12345)}).bid()
+	fallback := func(_, _ *Bid) bool { return false }
+
+	require.True(t, loadBidPlugin(t, wasmAlloc, wasmTiebreak(1)).tiebreaker(mock.TestLog, fallback)(bid, best))
+	require.False(t, loadBidPlugin(t, wasmAlloc, wasmTiebreak(-1)).tiebreaker(mock.TestLog, fallback)(bid, best))
+
+	// Undecided ties are left to the fallback
+	fallback = func(_, _ *Bid) bool { return true }
+	require.True(t, loadBidPlugin(t, wasmAlloc, wasmTiebreak(0)).tiebreaker(mock.TestLog, fallback)(bid, best))
+	require.True(t, loadBidPlugin(t, wasmAlloc).tiebreaker(mock.TestLog, fallback)(bid, best))
+}
+
+func TestBidPlugin(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	backend := newTestBackend(t, 2, time.Second)
+	backend.boost.signatureCheck = types.SignatureCheckSkip
+	for i, bid := range []struct {
+		value     uint64
+		blockHash string
+	}{
+		{20000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8"},
+		{15000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"},
+	} {
+		backend.relays[i].GetHeaderResponse = backend.relays[i].MakeGetHeaderResponse(
+			bid.value,
+			bid.blockHash,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			pubkey,
+			spec.DataVersionDeneb,
+		)
+	}
+	getHeader := func() uint64 {
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return value.Uint64()
+	}
+	reload := func(plugin *BidPlugin) {
+		require.NoError(t, backend.boost.Reload(ReloadOpts{
+			Relays:                   backend.boost.relays,
+			RequestTimeoutGetHeader:  time.Second,
+			RequestTimeoutGetPayload: time.Second,
+			RequestTimeoutRegVal:     time.Second,
+			BidPlugin:                plugin,
+		}))
+	}
+	require.Equal(t, uint64(20000), getHeader())
+
+	// The filter rejects the higher bid
+	reload(loadBidPlugin(t, wasmAlloc, wasmFilter))
+	require.Equal(t, uint64(15000), getHeader())
+
+	// Replacing the plugin at runtime
+	reload(loadBidPlugin(t, wasmAlloc, wasmScore(2)))
+	require.Equal(t, uint64(20000), getHeader())
+	reload(nil)
+	require.Nil(t, backend.boost.currentConfig().bidPlugin)
+	require.Equal(t, uint64(20000), getHeader())
+}
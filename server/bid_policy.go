@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBidPolicyTimeout is the time budget of the policy service if none is configured
+const defaultBidPolicyTimeout = 100 * time.Millisecond
+
+var bidPolicyDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "bid_policy_decisions_total",
+	Help:      "Number of getHeader auctions decided by the bid policy service, by result",
+}, []string{"result"})
+
+func init() {
+	metricsRegistry.MustRegister(bidPolicyDecisions)
+}
+
+// bidPolicyRequest asks the policy service to veto or reorder the candidate bids of a slot, the best bid first
+type bidPolicyRequest struct {
+	Slot       uint64         `json:"slot,string"`
+	ParentHash string         `json:"parent_hash"`
+	Pubkey     string         `json:"pubkey"`
+	Bids       []bidPolicyBid `json:"bids"`
+}
+
+type bidPolicyBid struct {
+	BlockHash string   `json:"block_hash"`
+	Value     string   `json:"value"`
+	Relays    []string `json:"relays"`
+}
+
+// bidPolicyResponse lists the block hashes of the accepted bids, the preferred bid first. Bids which aren't listed are
+// vetoed.
+type bidPolicyResponse struct {
+	BlockHashes []string `json:"block_hashes"`
+}
+
+// bidPolicy is the operator's policy service, which decides about the candidate bids before getHeader returns
+type bidPolicy struct {
+	url        string
+	client     http.Client
+	failClosed bool // no bid is returned if the policy service fails, instead of the best bid
+}
+
+func newBidPolicy(url string, timeout time.Duration, failClosed bool) *bidPolicy {
+	if timeout <= 0 {
+		timeout = defaultBidPolicyTimeout
+	}
+	return &bidPolicy{
+		url: url,
+		client: http.Client{
+			Timeout:       timeout,
+			CheckRedirect: httpClientDisallowRedirects,
+		},
+		failClosed: failClosed,
+	}
+}
+
+// rankBidCandidates returns the candidates ordered by the bid selector, the best bid first
+func rankBidCandidates(candidates map[BlockHashHex]*bidCandidate, selector BidSelector) []*bidCandidate {
+	ranked := make([]*bidCandidate, 0, len(candidates))
+	bids := make(map[*bidCandidate]*Bid, len(candidates))
+	for _, candidate := range candidates {
+		ranked = append(ranked, candidate)
+		bids[candidate] = candidate.bid()
+	}
+	slices.SortFunc(ranked, func(a, b *bidCandidate) int {
+		if selector.Better(bids[a], bids[b]) {
+			return -1
+		}
+		if selector.Better(bids[b], bids[a]) {
+			return 1
+		}
+		return 0
+	})
+	return ranked
+}
+
+// applyBidPolicy lets the policy service veto or reorder the ranked candidate bids. The first accepted bid becomes the
+// result, which is empty if all bids are vetoed. If the policy service fails or doesn't answer within its time budget,
+// the result is kept, or emptied if the policy fails closed.
+func (m *BoostService) applyBidPolicy(ctx context.Context, log *logrus.Entry, slot phase0.Slot, parentHashHex string, result *bidResp, ranked []*bidCandidate, records []*BidAuditRecord) {
+	req := bidPolicyRequest{
+		Slot:       uint64(slot),
+		ParentHash: parentHashHex,
+		Pubkey:     result.pubkey,
+		Bids:       make([]bidPolicyBid, 0, len(ranked)),
+	}
+	byHash := make(map[string]*bidCandidate, len(ranked))
+	for _, candidate := range ranked {
+		relays := make([]string, 0, len(candidate.relays))
+		for _, relay := range candidate.relays {
+			relays = append(relays, relay.GetURI(""))
+		}
+		hash := candidate.info.blockHash.String()
+		byHash[hash] = candidate
+		req.Bids = append(req.Bids, bidPolicyBid{BlockHash: hash, Value: candidate.info.value.Dec(), Relays: relays})
+	}
+
+	start := time.Now()
+	resp := new(bidPolicyResponse)
+	_, err := SendHTTPRequest(ctx, m.bidPolicy.client, http.MethodPost, m.bidPolicy.url, "", nil, req, resp)
+	log = log.WithField("policyDurationMs", time.Since(start).Milliseconds())
+	if err != nil {
+		bidPolicyDecisions.WithLabelValues("failed").Inc()
+		if !m.bidPolicy.failClosed {
+			log.WithError(err).Warn("bid policy service failed, using the best bid")
+			return
+		}
+		log.WithError(err).Error("bid policy service failed, not returning a bid")
+		rejectBidRecords(records, nil, bidRejectedPolicyFailure)
+		*result = bidResp{responded: result.responded, pubkey: result.pubkey}
+		return
+	}
+
+	// The first accepted bid wins, the other bids are outbid or vetoed
+	var chosen *bidCandidate
+	accepted := make(map[string]bool, len(resp.BlockHashes))
+	for _, hash := range resp.BlockHashes {
+		candidate, ok := byHash[strings.ToLower(hash)]
+		if !ok {
+			log.WithField("blockHash", hash).Warn("bid policy service accepted an unknown bid")
+			continue
+		}
+		accepted[candidate.info.blockHash.String()] = true
+		if chosen == nil {
+			chosen = candidate
+		}
+	}
+	rejectBidRecords(records, accepted, bidRejectedPolicyVeto)
+
+	switch {
+	case chosen == nil:
+		bidPolicyDecisions.WithLabelValues("vetoed").Inc()
+		log.Info("bid policy service vetoed all bids")
+		*result = bidResp{responded: result.responded, pubkey: result.pubkey}
+	case chosen.info.blockHash != result.bidInfo.blockHash:
+		bidPolicyDecisions.WithLabelValues("reordered").Inc()
+		log.WithFields(logrus.Fields{
+			"blockHash": chosen.info.blockHash.String(),
+			"value":     chosen.info.value.Dec(),
+		}).Info("bid policy service selected another bid")
+		result.response = chosen.response
+		result.bidInfo = chosen.info
+		result.relays = chosen.relays
+		result.t = time.Now()
+	default:
+		bidPolicyDecisions.WithLabelValues("accepted").Inc()
+		log.Debug("bid policy service accepted the best bid")
+	}
+}
+
+// rejectBidRecords rejects the usable bids which aren't accepted, all of them if nothing is accepted
+func rejectBidRecords(records []*BidAuditRecord, accepted map[string]bool, reason string) {
+	for _, record := range records {
+		if record.RejectionReason == "" && !accepted[record.BlockHash] {
+			record.RejectionReason = reason
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidPolicy(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	bestHash := "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8"
+	otherHash := "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"
+
+	// getHeader returns the response code and bid value of getHeader, with the policy service answering the accepted
+	// block hashes after the delay
+	getHeader := func(t *testing.T, accepted []string, delay time.Duration, failClosed bool) (int, uint64, *bidPolicyRequest) {
+		t.Helper()
+		received := new(bidPolicyRequest)
+		policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(received))
+			time.Sleep(delay)
+			require.NoError(t, json.NewEncoder(w).Encode(bidPolicyResponse{BlockHashes: accepted}))
+		}))
+		t.Cleanup(policy.Close)
+
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.bidPolicy = newBidPolicy(policy.URL, 100*time.Millisecond, failClosed)
+		backend.boost.signatureCheck = types.SignatureCheckSkip
+		for i, bid := range []struct {
+			value     uint64
+			blockHash string
+		}{
+			{20000, bestHash},
+			{12345, otherHash},
+		} {
+			backend.relays[i].GetHeaderResponse = backend.relays[i].MakeGetHeaderResponse(bid.value, bid.blockHash, otherHash, pubkey, spec.DataVersionDeneb)
+		}
+
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+		if rr.Code != http.StatusOK {
+			return rr.Code, 0, received
+		}
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return rr.Code, value.Uint64(), received
+	}
+
+	t.Run("Accepts the best bid", func(t *testing.T) {
+		code, value, req := getHeader(t, []string{bestHash, otherHash}, 0, false)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(20000), value)
+		require.Equal(t, uint64(1), req.Slot)
+		require.Equal(t, pubkey, req.Pubkey)
+		require.Len(t, req.Bids, 2)
+		require.Equal(t, bestHash, req.Bids[0].BlockHash)
+		require.Equal(t, "20000", req.Bids[0].Value)
+		require.Len(t, req.Bids[0].Relays, 1)
+		require.Equal(t, otherHash, req.Bids[1].BlockHash)
+	})
+
+	t.Run("Reorders the bids", func(t *testing.T) {
+		code, value, _ := getHeader(t, []string{"0xunknown", otherHash, bestHash}, 0, false)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(12345), value)
+	})
+
+	t.Run("Vetoes the bids", func(t *testing.T) {
+		code, _, _ := getHeader(t, []string{}, 0, false)
+		require.Equal(t, http.StatusNoContent, code)
+	})
+
+	t.Run("Keeps the best bid if the policy service is too slow", func(t *testing.T) {
+		code, value, _ := getHeader(t, []string{otherHash}, 200*time.Millisecond, false)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(20000), value)
+	})
+
+	t.Run("Fails closed", func(t *testing.T) {
+		code, _, _ := getHeader(t, []string{otherHash}, 200*time.Millisecond, true)
+		require.Equal(t, http.StatusNoContent, code)
+	})
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// bidScriptFunction is the function a bid script has to define, it's called with each bid
+const bidScriptFunction = "score_bid"
+
+// bidScriptMaxSteps bounds the execution of a bid script per bid, so a runaway script can't delay getHeader
+const bidScriptMaxSteps = 100_000
+
+var (
+	errBidScriptFunction = errors.New("bid script doesn't define " + bidScriptFunction + "(bid)")
+	errBidScriptResult   = errors.New("invalid bid script result")
+)
+
+// BidScript is an operator-provided Starlark script which accepts, rejects or scores each bid. Starlark has no access
+// to the file system or network, the script only sees the bid.
+type BidScript struct {
+	filename string
+	score    *starlark.Function
+}
+
+// LoadBidScript loads the Starlark script, which has to define score_bid(bid)
+func LoadBidScript(filename string) (*BidScript, error) {
+	thread := &starlark.Thread{Name: filename}
+	thread.SetMaxExecutionSteps(bidScriptMaxSteps)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, filename, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	score, ok := globals[bidScriptFunction].(*starlark.Function)
+	if !ok || score.NumParams() != 1 {
+		return nil, errBidScriptFunction
+	}
+	return &BidScript{filename: filename, score: score}, nil
+}
+
+// evaluate calls score_bid with the bid and returns its score: 0 rejects the bid, otherwise the bid value is multiplied
+// by the score to compare it with the other bids. True and None accept the bid with score 1, False rejects it.
+func (s *BidScript) evaluate(log *logrus.Entry, bid starlark.Value) (float64, error) {
+	thread := &starlark.Thread{
+		Name:  s.filename,
+		Print: func(_ *starlark.Thread, msg string) { log.WithField("bidScript", s.filename).Info(msg) },
+	}
+	thread.SetMaxExecutionSteps(bidScriptMaxSteps)
+	ret, err := starlark.Call(thread, s.score, starlark.Tuple{bid}, nil)
+	if err != nil {
+		return 0, err
+	}
+	switch ret := ret.(type) {
+	case starlark.NoneType:
+		return 1, nil
+	case starlark.Bool:
+		if ret {
+			return 1, nil
+		}
+		return 0, nil
+	case starlark.Int, starlark.Float:
+		score, _ := starlark.AsFloat(ret)
+		if score < 0 || math.IsNaN(score) || math.IsInf(score, 0) {
+			return 0, fmt.Errorf("%w: score %v", errBidScriptResult, score)
+		}
+		return score, nil
+	}
+	return 0, fmt.Errorf("%w: %s", errBidScriptResult, ret.Type())
+}
+
+// scoreBid runs the bid script with the bid of the relay, along with the stats of the relay. A failing script accepts
+// the bid with score 1, so a broken script doesn't cost the proposer its bids.
+func (m *BoostService) scoreBid(log *logrus.Entry, relay types.RelayEntry, slot phase0.Slot, info bidInfo) float64 {
+	stats := m.relayStats.snapshot([]types.RelayEntry{relay})[0]
+	health := m.relayHealth.snapshot([]types.RelayEntry{relay})[0]
+	labels := make([]starlark.Value, 0, len(relay.Labels))
+	for _, label := range relay.Labels {
+		labels = append(labels, starlark.String(label))
+	}
+	bid := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"slot":         starlark.MakeUint64(uint64(slot)),
+		"block_hash":   starlark.String(info.blockHash.String()),
+		"parent_hash":  starlark.String(info.parentHash.String()),
+		"builder":      starlark.String(info.pubkey.String()),
+		"value":        starlark.MakeBigInt(info.value.ToBig()),
+		"block_number": starlark.MakeUint64(info.blockNumber),
+		"gas_limit":    starlark.MakeUint64(info.gasLimit),
+		"relay":        starlark.String(relay.GetURI("")),
+		"relay_labels": starlark.NewList(labels),
+		"relay_stats": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"bids_received":      starlark.MakeUint64(stats.BidsReceived),
+			"bids_won":           starlark.MakeUint64(stats.BidsWon),
+			"signature_failures": starlark.MakeUint64(stats.SignatureFailures),
+			"payloads_delivered": starlark.MakeUint64(stats.PayloadsDelivered),
+			"payloads_missed":    starlark.MakeUint64(stats.PayloadsMissed),
+			"blocks_missed":      starlark.MakeUint64(stats.BlocksMissed),
+			"recent_error_rate":  starlark.Float(health.RecentErrorRate),
+			"latency_ms":         starlark.Float(health.LatencyMs),
+		}),
+	})
+	score, err := m.bidScript.evaluate(log, bid)
+	if err != nil {
+		log.WithError(err).Error("bid script failed, accepting the bid")
+		return 1
+	}
+	return score
+}
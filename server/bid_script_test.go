@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+)
+
+func writeBidScript(t *testing.T, src string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "bids.star")
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o600))
+	return filename
+}
+
+func TestLoadBidScript(t *testing.T) {
+	t.Run("Without score_bid", func(t *testing.T) {
+		_, err := LoadBidScript(writeBidScript(t, "def other(bid):\n    return True\n"))
+		require.ErrorIs(t, err, errBidScriptFunction)
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := LoadBidScript(writeBidScript(t, "def score_bid(bid)\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("No file system access", func(t *testing.T) {
+		_, err := LoadBidScript(writeBidScript(t, "load('os', 'open')\ndef score_bid(bid):\n    return True\n"))
+		require.Error(t, err)
+	})
+}
+
+func TestScoreBid(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	relay := backend.relays[0].RelayEntry
+	relay.Labels = []string{"non-filtering"}
+	info := bidInfo{value: uint256.NewInt(20000), gasLimit: 30_000_000}
+	score := func(src string) float64 {
+		script, err := LoadBidScript(writeBidScript(t, src))
+		require.NoError(t, err)
+		backend.boost.bidScript = script
+		return backend.boost.scoreBid(mock.TestLog, relay, 1, info)
+	}
+
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return None\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return bid.value > 10000\n"), 0)
+	require.InDelta(t, 0, score("def score_bid(bid):\n    return bid.gas_limit < 30000000\n"), 0)
+	require.InDelta(t, 1.05, score("def score_bid(bid):\n    return 1.05 if 'non-filtering' in bid.relay_labels else 1\n"), 0)
+	require.InDelta(t, 2, score("def score_bid(bid):\n    return bid.relay_stats.bids_received + 2\n"), 0)
+
+	// Failing scripts accept the bid
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return 'yes'\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return -1\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return float('nan')\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return float('inf')\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return 1"+strings.Repeat("0", 400)+"\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    return bid.unknown\n"), 0)
+	require.InDelta(t, 1, score("def score_bid(bid):\n    for i in range(1000000):\n        pass\n    return False\n"), 0)
+}
+
+func TestBidScriptResult(t *testing.T) {
+	for _, result := range []string{"-1", "float('nan')", "float('inf')", "-float('inf')", "1" + strings.Repeat("0", 400)} {
+		script, err := LoadBidScript(writeBidScript(t, "def score_bid(bid):\n    return "+result+"\n"))
+		require.NoError(t, err)
+		_, err = script.evaluate(mock.TestLog, starlark.None)
+		require.ErrorIs(t, err, errBidScriptResult, result)
+	}
+}
+
+func TestBidScript(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	getHeader := func(src string) (int, uint64) {
+		script, err := LoadBidScript(writeBidScript(t, src))
+		require.NoError(t, err)
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.bidScript = script
+		backend.boost.signatureCheck = types.SignatureCheckSkip
+		for i, bid := range []struct {
+			value     uint64
+			blockHash string
+		}{
+			{20000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8"},
+			{15000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"},
+		} {
+			backend.relays[i].GetHeaderResponse = backend.relays[i].MakeGetHeaderResponse(
+				bid.value,
+				bid.blockHash,
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				pubkey,
+				spec.DataVersionDeneb,
+			)
+		}
+
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+		if rr.Code != http.StatusOK {
+			return rr.Code, 0
+		}
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return rr.Code, value.Uint64()
+	}
+
+	t.Run("Accepts the bids", func(t *testing.T) {
+		code, value := getHeader("def score_bid(bid):\n    return True\n")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(20000), value)
+	})
+
+	t.Run("Rejects a bid", func(t *testing.T) {
+		code, value := getHeader("def score_bid(bid):\n    return bid.value < 20000\n")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(15000), value)
+	})
+
+	t.Run("Rejects all bids", func(t *testing.T) {
+		code, _ := getHeader("def score_bid(bid):\n    return False\n")
+		require.Equal(t, http.StatusNoContent, code)
+	})
+
+	t.Run("Scores the bids", func(t *testing.T) {
+		code, value := getHeader("def score_bid(bid):\n    return 2 if bid.block_hash.endswith('ab7') else 1\n")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, uint64(15000), value)
+	})
+}
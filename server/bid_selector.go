@@ -0,0 +1,123 @@
+package server
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+)
+
+// Bid is a candidate bid of a getHeader auction, as compared by a BidSelector. Relays might deliver the same block, the
+// bid has all relays which delivered it.
+type Bid struct {
+	BlockHash  phase0.Hash32
+	Builder    phase0.BLSPubKey
+	Value      *uint256.Int
+	GasLimit   uint64
+	Relays     []types.RelayEntry
+	RelayOrder int           // the lowest position of the relays in the relay list
+	Latency    time.Duration // the fastest response of the relays
+	Score      float64       // the highest bid script and bid plugin score of the relays, 0 without both
+
+	random uint64 // drawn once per bid, for the random tiebreaker
+}
+
+// BidSelector decides which bid wins a getHeader auction. Every bid is compared with the best bid so far, and the
+// candidates are ranked with it for the bid policy service.
+type BidSelector interface {
+	// Better returns true if the bid should replace the best bid
+	Better(bid, best *Bid) bool
+}
+
+// defaultBidSelector is the bid selection of mev-boost
+type defaultBidSelector struct {
+	tiebreak    tiebreaker
+	preferences labelPreferences
+}
+
+// NewDefaultBidSelector returns the bid selection of mev-boost, with the tiebreaker (see Tiebreakers) and the label
+// preferences in percent, i.e. to fall back to in a custom BidSelector
+func NewDefaultBidSelector(tiebreakerName string, labelPreferences map[string]float64) (BidSelector, error) {
+	tiebreak, err := newTiebreaker(tiebreakerName)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := newLabelPreferences(labelPreferences)
+	if err != nil {
+		return nil, err
+	}
+	return &defaultBidSelector{tiebreak: tiebreak, preferences: preferences}, nil
+}
+
+func (s *defaultBidSelector) Better(bid, best *Bid) bool {
+	return isBetterBid(bid, best, s.tiebreak, s.preferences)
+}
+
+// auctionBidSelector returns the bid selector of a getHeader auction. The default selector asks the tiebreak function
+// of the bid plugin (if any) first, a custom selector decides ties itself.
+func (m *BoostService) auctionBidSelector(log *logrus.Entry, plugin *BidPlugin) BidSelector {
+	if m.bidSelector != nil {
+		return m.bidSelector
+	}
+	selector := &defaultBidSelector{tiebreak: m.tiebreaker, preferences: m.labelPreferences}
+	if plugin != nil {
+		selector.tiebreak = plugin.tiebreaker(log, m.tiebreaker)
+	}
+	return selector
+}
+
+// isBetterBid returns true if the bid should replace the best bid. The bid from the higher relay tier wins, then the
+// higher value multiplied by the relay boost factor and the label preferences, then the higher relay weight. Equal bids
+// are decided by the tiebreaker.
+func isBetterBid(bid, best *Bid, tiebreak tiebreaker, preferences labelPreferences) bool {
+	bidTier, bidWeight := relayPriority(bid.Relays)
+	bestTier, bestWeight := relayPriority(best.Relays)
+	if bidTier != bestTier {
+		return bidTier < bestTier
+	}
+	if valueDiff := boostedValue(bid, preferences).Cmp(boostedValue(best, preferences)); valueDiff != 0 {
+		return valueDiff > 0
+	}
+	if bidWeight != bestWeight {
+		return bidWeight > bestWeight
+	}
+	return tiebreak(bid, best)
+}
+
+// relayPriority returns the highest tier and weight of the relays which delivered a bid
+func relayPriority(relays []types.RelayEntry) (tier, weight int) {
+	for i, relay := range relays {
+		switch {
+		case i == 0 || relay.Tier < tier:
+			tier, weight = relay.Tier, relay.Weight
+		case relay.Tier == tier:
+			weight = max(weight, relay.Weight)
+		}
+	}
+	return tier, weight
+}
+
+// boostedValue returns the bid value multiplied by the highest boost factor of the relays which delivered the bid, by
+// the label preferences of the relays and by the bid script score (if any)
+func boostedValue(bid *Bid, preferences labelPreferences) *big.Float {
+	factor := 0.0
+	for _, relay := range bid.Relays {
+		if relay.BoostFactor == 0 {
+			factor = max(factor, 1)
+		} else {
+			factor = max(factor, relay.BoostFactor)
+		}
+	}
+	factor *= preferences.factor(bid.Relays)
+	if bid.Score > 0 {
+		factor *= bid.Score
+	}
+	value := new(big.Float).SetPrec(512).SetInt(bid.Value.ToBig())
+	if factor == 1 {
+		return value
+	}
+	return value.Mul(value, big.NewFloat(factor))
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// lowestValueSelector prefers the lowest bid
+type lowestValueSelector struct{}
+
+func (lowestValueSelector) Better(bid, best *Bid) bool {
+	return bid.Value.Lt(best.Value)
+}
+
+func TestNewDefaultBidSelector(t *testing.T) {
+	selector, err := NewDefaultBidSelector(TiebreakerLatency, map[string]float64{"non-filtering": 5})
+	require.NoError(t, err)
+	fast := newBidCandidate(bidInfo{blockHash: mock.HexToHash("0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), value: uint256.NewInt(12345)})
+	fast.add(types.RelayEntry{}, 0, 10*time.Millisecond, 0)
+	slow := newBidCandidate(bidInfo{blockHash: mock.HexToHash("0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), value: uint256.NewInt(12345)})
+	slow.add(types.RelayEntry{}, 0, 20*time.Millisecond, 0)
+	require.True(t, selector.Better(fast.bid(), slow.bid()))
+	require.False(t, selector.Better(slow.bid(), fast.bid()))
+
+	_, err = NewDefaultBidSelector("fastest", nil)
+	require.ErrorIs(t, err, errUnknownTiebreaker)
+	_, err = NewDefaultBidSelector("", map[string]float64{"filtering": -100})
+	require.ErrorIs(t, err, errInvalidLabelPreference)
+}
+
+func TestBidSelector(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	backend := newTestBackend(t, 2, time.Second)
+	backend.boost.signatureCheck = types.SignatureCheckSkip
+	backend.boost.bidSelector = lowestValueSelector{}
+	for i, bid := range []struct {
+		value     uint64
+		blockHash string
+	}{
+		{20000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8"},
+		{15000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"},
+	} {
+		backend.relays[i].GetHeaderResponse = backend.relays[i].MakeGetHeaderResponse(
+			bid.value,
+			bid.blockHash,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			pubkey,
+			spec.DataVersionDeneb,
+		)
+	}
+
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	resp := new(builderSpec.VersionedSignedBuilderBid)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	value, err := resp.Value()
+	require.NoError(t, err)
+	require.Equal(t, uint64(15000), value.Uint64())
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite" // registers the pure-go "sqlite" database/sql driver
+)
+
+const bidStoreSchema = `
+CREATE TABLE IF NOT EXISTS bids (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	slot                INTEGER NOT NULL,
+	slot_uid            TEXT NOT NULL,
+	relay               TEXT NOT NULL,
+	value               TEXT NOT NULL,
+	block_hash          TEXT NOT NULL,
+	parent_hash         TEXT NOT NULL,
+	block_number        INTEGER NOT NULL,
+	received_at_ms      INTEGER NOT NULL,
+	ms_into_slot        INTEGER NOT NULL,
+	request_duration_ms INTEGER NOT NULL,
+	selected            BOOLEAN NOT NULL,
+	rejection_reason    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS bids_slot_idx ON bids (slot);
+CREATE INDEX IF NOT EXISTS bids_relay_idx ON bids (relay);
+
+CREATE TABLE IF NOT EXISTS payload_deliveries (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	slot            INTEGER NOT NULL,
+	block_hash      TEXT NOT NULL,
+	relay           TEXT NOT NULL,
+	delivered_at_ms INTEGER NOT NULL,
+	ms_into_slot    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS payload_deliveries_slot_idx ON payload_deliveries (slot);
+CREATE INDEX IF NOT EXISTS payload_deliveries_relay_idx ON payload_deliveries (relay);
+`
+
+const (
+	bidStoreDefaultQueryLimit = 100
+	bidStoreMaxQueryLimit     = 10000
+
+	// bidStoreQueueSize is the number of writes waiting for the database, further writes are dropped
+	bidStoreQueueSize = 256
+)
+
+var errInvalidQueryParam = errors.New("invalid query parameter")
+
+// likeEscaper escapes the wildcards of a LIKE pattern, with the escape character of BidStoreQuery.where
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// PayloadDeliveryRecord is a payload which was delivered by a relay
+type PayloadDeliveryRecord struct {
+	Slot          uint64 `json:"slot"`
+	BlockHash     string `json:"block_hash"`
+	Relay         string `json:"relay"`
+	DeliveredAtMs int64  `json:"delivered_at_ms"`
+	MsIntoSlot    int64  `json:"ms_into_slot"`
+}
+
+// BidStoreQuery filters the records returned from the BidStore
+type BidStoreQuery struct {
+	FromSlot uint64
+	ToSlot   uint64 // inclusive, 0 means no upper bound
+	Relay    string
+	Limit    int
+}
+
+// BidStore persists received bids (including the selection outcome) and payload deliveries to an embedded SQLite database.
+// The writes of the service are queued and done by a single goroutine, so slow writes don't delay the responses.
+type BidStore struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	closed bool
+	queue  chan bidStoreWrite
+	done   chan struct{}
+}
+
+// bidStoreWrite is a queued write of the records of a getHeader auction, or of a payload delivery
+type bidStoreWrite struct {
+	log      *logrus.Entry
+	bids     []*BidAuditRecord
+	delivery *PayloadDeliveryRecord
+}
+
+// OpenBidStore opens (and creates, if needed) the SQLite database at path
+func OpenBidStore(path string) (*BidStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports a single writer
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(bidStoreSchema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	s := &BidStore{
+		db:    db,
+		queue: make(chan bidStoreWrite, bidStoreQueueSize),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Close writes the queued records and closes the underlying database, later writes are dropped
+func (s *BidStore) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	<-s.done
+	return s.db.Close()
+}
+
+// write queues a write, it doesn't block if the queue is full
+func (s *BidStore) write(w bidStoreWrite) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- w:
+	default:
+		w.log.Error("bid store queue is full, dropping the write")
+	}
+}
+
+// run does the queued writes until the store is closed
+func (s *BidStore) run() {
+	defer close(s.done)
+	for w := range s.queue {
+		if w.delivery != nil {
+			if err := s.InsertPayloadDelivery(*w.delivery); err != nil {
+				w.log.WithError(err).Error("could not store payload delivery")
+			}
+			continue
+		}
+		if err := s.InsertBids(w.bids); err != nil {
+			w.log.WithError(err).Error("could not store bids")
+		}
+	}
+}
+
+// InsertBids stores the records of a getHeader auction
+func (s *BidStore) InsertBids(records []*BidAuditRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(`INSERT INTO bids (slot, slot_uid, relay, value, block_hash, parent_hash, block_number,
+		received_at_ms, ms_into_slot, request_duration_ms, selected, rejection_reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		_, err := stmt.Exec(r.Slot, r.SlotUID, r.Relay, r.Value, r.BlockHash, r.ParentHash, r.BlockNumber,
+			r.ReceivedAtMs, r.MsIntoSlot, r.RequestDurationMs, r.Selected, r.RejectionReason)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// InsertPayloadDelivery stores a payload delivery
+func (s *BidStore) InsertPayloadDelivery(r PayloadDeliveryRecord) error {
+	_, err := s.db.Exec(`INSERT INTO payload_deliveries (slot, block_hash, relay, delivered_at_ms, ms_into_slot) VALUES (?, ?, ?, ?, ?)`,
+		r.Slot, r.BlockHash, r.Relay, r.DeliveredAtMs, r.MsIntoSlot)
+	return err
+}
+
+// QueryBids returns the stored bids matching the query, ordered by slot
+func (s *BidStore) QueryBids(q BidStoreQuery) ([]BidAuditRecord, error) {
+	where, args := q.where()
+	rows, err := s.db.Query(`SELECT slot, slot_uid, relay, value, block_hash, parent_hash, block_number,
+		received_at_ms, ms_into_slot, request_duration_ms, selected, rejection_reason FROM bids`+where+` ORDER BY slot, id LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []BidAuditRecord{}
+	for rows.Next() {
+		r := BidAuditRecord{}
+		err := rows.Scan(&r.Slot, &r.SlotUID, &r.Relay, &r.Value, &r.BlockHash, &r.ParentHash, &r.BlockNumber,
+			&r.ReceivedAtMs, &r.MsIntoSlot, &r.RequestDurationMs, &r.Selected, &r.RejectionReason)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// QueryPayloadDeliveries returns the stored payload deliveries matching the query, ordered by slot
+func (s *BidStore) QueryPayloadDeliveries(q BidStoreQuery) ([]PayloadDeliveryRecord, error) {
+	where, args := q.where()
+	rows, err := s.db.Query(`SELECT slot, block_hash, relay, delivered_at_ms, ms_into_slot FROM payload_deliveries`+where+` ORDER BY slot, id LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []PayloadDeliveryRecord{}
+	for rows.Next() {
+		r := PayloadDeliveryRecord{}
+		if err := rows.Scan(&r.Slot, &r.BlockHash, &r.Relay, &r.DeliveredAtMs, &r.MsIntoSlot); err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// where returns the WHERE clause and its arguments, with the limit as last argument
+func (q BidStoreQuery) where() (string, []any) {
+	conditions := []string{"slot >= ?"}
+	args := []any{q.FromSlot}
+	if q.ToSlot > 0 {
+		conditions = append(conditions, "slot <= ?")
+		args = append(args, q.ToSlot)
+	}
+	if q.Relay != "" {
+		// The relay matches as a substring, i.e. the host, so the wildcards of LIKE are escaped
+		conditions = append(conditions, `relay LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(q.Relay)+"%")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = bidStoreDefaultQueryLimit
+	}
+	args = append(args, min(limit, bidStoreMaxQueryLimit))
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// parseBidStoreQuery reads the from_slot, to_slot, relay and limit query args
+func parseBidStoreQuery(req *http.Request) (q BidStoreQuery, err error) {
+	args := req.URL.Query()
+	if v := args.Get("from_slot"); v != "" {
+		if q.FromSlot, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return q, errInvalidQueryParam
+		}
+	}
+	if v := args.Get("to_slot"); v != "" {
+		if q.ToSlot, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return q, errInvalidQueryParam
+		}
+	}
+	if v := args.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, errInvalidQueryParam
+		}
+	}
+	q.Relay = args.Get("relay")
+	return q, nil
+}
+
+// handleBidHistory returns stored bids, filtered by slot range and relay
+func (m *BoostService) handleBidHistory(w http.ResponseWriter, req *http.Request) {
+	q, err := parseBidStoreQuery(req)
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bids, err := m.bidStore.QueryBids(q)
+	if err != nil {
+		m.log.WithError(err).Error("could not query bid history")
+		m.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	m.respondOK(w, bids)
+}
+
+// handlePayloadHistory returns stored payload deliveries, filtered by slot range and relay
+func (m *BoostService) handlePayloadHistory(w http.ResponseWriter, req *http.Request) {
+	q, err := parseBidStoreQuery(req)
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	payloads, err := m.bidStore.QueryPayloadDeliveries(q)
+	if err != nil {
+		m.log.WithError(err).Error("could not query payload history")
+		m.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	m.respondOK(w, payloads)
+}
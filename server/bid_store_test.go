@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidStore(t *testing.T) {
+	store, err := OpenBidStore(filepath.Join(t.TempDir(), "bids.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.InsertBids([]*BidAuditRecord{
+		{Slot: 1, Relay: "http://relay-a.com", Value: "100", BlockHash: "0x01", Selected: true},
+		{Slot: 1, Relay: "http://relay-b.com", Value: "99", BlockHash: "0x02", RejectionReason: bidRejectedOutbid},
+		{Slot: 2, Relay: "http://relay-b.com", Value: "50", BlockHash: "0x03", Selected: true},
+	}))
+	require.NoError(t, store.InsertPayloadDelivery(PayloadDeliveryRecord{Slot: 1, BlockHash: "0x01", Relay: "http://relay-a.com"}))
+
+	t.Run("Query by slot range", func(t *testing.T) {
+		bids, err := store.QueryBids(BidStoreQuery{FromSlot: 2})
+		require.NoError(t, err)
+		require.Len(t, bids, 1)
+		require.Equal(t, "0x03", bids[0].BlockHash)
+
+		bids, err = store.QueryBids(BidStoreQuery{FromSlot: 1, ToSlot: 1})
+		require.NoError(t, err)
+		require.Len(t, bids, 2)
+		require.True(t, bids[0].Selected)
+		require.Equal(t, bidRejectedOutbid, bids[1].RejectionReason)
+	})
+
+	t.Run("Query by relay", func(t *testing.T) {
+		bids, err := store.QueryBids(BidStoreQuery{Relay: "relay-b"})
+		require.NoError(t, err)
+		require.Len(t, bids, 2)
+
+		payloads, err := store.QueryPayloadDeliveries(BidStoreQuery{Relay: "relay-b"})
+		require.NoError(t, err)
+		require.Empty(t, payloads)
+
+		// The wildcards of LIKE match themselves only
+		for _, relay := range []string{"relay_b", "%", `relay\-b`} {
+			bids, err = store.QueryBids(BidStoreQuery{Relay: relay})
+			require.NoError(t, err)
+			require.Empty(t, bids, relay)
+		}
+	})
+
+	t.Run("Queued writes are done before closing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bids.db")
+		queued, err := OpenBidStore(path)
+		require.NoError(t, err)
+		queued.write(bidStoreWrite{log: mock.TestLog, bids: []*BidAuditRecord{{Slot: 3, Relay: "http://relay-c.com", BlockHash: "0x04"}}})
+		queued.write(bidStoreWrite{log: mock.TestLog, delivery: &PayloadDeliveryRecord{Slot: 3, BlockHash: "0x04", Relay: "http://relay-c.com"}})
+		require.NoError(t, queued.Close())
+		queued.write(bidStoreWrite{log: mock.TestLog, bids: []*BidAuditRecord{{Slot: 4}}})
+
+		reopened, err := OpenBidStore(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+		bids, err := reopened.QueryBids(BidStoreQuery{})
+		require.NoError(t, err)
+		require.Len(t, bids, 1)
+		payloads, err := reopened.QueryPayloadDeliveries(BidStoreQuery{})
+		require.NoError(t, err)
+		require.Len(t, payloads, 1)
+	})
+
+	t.Run("History API", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.bidStore = store
+
+		rr := backend.request(t, http.MethodGet, params.PathBidHistory+"?from_slot=1&to_slot=1&limit=1", nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		bids := []BidAuditRecord{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &bids))
+		require.Len(t, bids, 1)
+		require.Equal(t, "0x01", bids[0].BlockHash)
+
+		rr = backend.request(t, http.MethodGet, params.PathPayloadHistory+"?relay=relay-a", nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		payloads := []PayloadDeliveryRecord{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &payloads))
+		require.Len(t, payloads, 1)
+
+		rr = backend.request(t, http.MethodGet, params.PathBidHistory+"?from_slot=abc", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
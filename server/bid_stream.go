@@ -0,0 +1,127 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	bidStreamWriteTimeout = 5 * time.Second
+	bidStreamPongTimeout  = 2 * eventStreamKeepaliveInterval
+)
+
+// bidStreamRequest is sent by WebSocket clients to change their slot subscriptions
+type bidStreamRequest struct {
+	Op   string `json:"op"` // "subscribe" or "unsubscribe"
+	Slot uint64 `json:"slot"`
+}
+
+// bidStreamSubscription is the set of slots a WebSocket client is interested in. Without any slot, all bids are sent.
+type bidStreamSubscription struct {
+	mu    sync.Mutex
+	slots map[uint64]struct{}
+}
+
+func (s *bidStreamSubscription) update(req bidStreamRequest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.Op {
+	case "subscribe":
+		s.slots[req.Slot] = struct{}{}
+	case "unsubscribe":
+		delete(s.slots, req.Slot)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *bidStreamSubscription) matches(slot uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.slots) == 0 {
+		return true
+	}
+	_, ok := s.slots[slot]
+	return ok
+}
+
+var bidStreamUpgrader = websocket.Upgrader{
+	// Requests are authenticated by token, so dashboards may be served from any origin
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// handleBidStream streams the bids of the subscribed slots over a WebSocket, as they arrive from the relays
+func (m *BoostService) handleBidStream(w http.ResponseWriter, req *http.Request) {
+	if !m.authorizedEventsRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+
+	sub := &bidStreamSubscription{slots: make(map[uint64]struct{})}
+	if v := req.URL.Query().Get("slot"); v != "" {
+		slot, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			m.respondError(w, http.StatusBadRequest, errInvalidSlot.Error())
+			return
+		}
+		sub.slots[slot] = struct{}{}
+	}
+
+	conn, err := bidStreamUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		m.log.WithError(err).Debug("could not upgrade bid stream connection")
+		return
+	}
+	defer conn.Close()
+	log := m.log.WithField("remoteAddr", req.RemoteAddr)
+
+	events := m.events.subscribe()
+	defer m.events.unsubscribe(events)
+
+	// Read subscription changes until the client goes away
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = conn.SetReadDeadline(time.Now().Add(bidStreamPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(bidStreamPongTimeout))
+		})
+		for {
+			subReq := bidStreamRequest{}
+			if err := conn.ReadJSON(&subReq); err != nil {
+				return
+			}
+			if !sub.update(subReq) {
+				log.WithField("op", subReq.Op).Debug("invalid bid stream request")
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventStreamKeepaliveInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(bidStreamWriteTimeout)); err != nil {
+				return
+			}
+		case event := <-events:
+			if !event.Type.perBid() || !sub.matches(event.Slot) {
+				continue
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(bidStreamWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				log.WithError(err).Debug("could not write to bid stream")
+				return
+			}
+		}
+	}
+}
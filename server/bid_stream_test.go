@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidStream(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.eventsToken = "secret"
+	ts := httptest.NewServer(backend.boost.getRouter())
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + params.PathBidStream
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Streams bids of subscribed slot", func(t *testing.T) {
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=secret&slot=2", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		defer conn.Close()
+
+		// Bids for slot 1 are not sent
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			23456,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		rr = backend.request(t, http.MethodGet, getHeaderPath(2, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		event := Event{}
+		require.NoError(t, conn.ReadJSON(&event))
+		require.Equal(t, EventBidReceived, event.Type)
+		require.Equal(t, uint64(2), event.Slot)
+		require.Equal(t, "23456", event.Value)
+		require.Equal(t, hash.String(), event.BlockHash)
+		require.Equal(t, []string{backend.relays[0].RelayEntry.GetURI("")}, event.Relays)
+
+		require.NoError(t, conn.ReadJSON(&event))
+		require.Equal(t, EventBestBidUpdated, event.Type)
+		require.Equal(t, uint64(2), event.Slot)
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRegisterValidatorMaxBytes is the default body limit of registerValidator requests, room for about 70k
+// registrations in JSON
+const defaultRegisterValidatorMaxBytes = 32 << 20
+
+// defaultGetPayloadMaxBytes are the default body limits of getPayload requests by the fork of the blinded block. The
+// deposit and blob commitment lists of the later forks make for larger blocks. Requests without consensus version get
+// the largest limit.
+var defaultGetPayloadMaxBytes = map[string]int64{
+	spec.DataVersionBellatrix.String(): 2 << 20,
+	spec.DataVersionCapella.String():   2 << 20,
+	spec.DataVersionDeneb.String():     4 << 20,
+	spec.DataVersionElectra.String():   8 << 20,
+	spec.DataVersionFulu.String():      8 << 20,
+}
+
+// bodyLimits are the body size limits of the builder API requests. Zero limits use the defaults.
+type bodyLimits struct {
+	registerValidator int64
+	getPayload        int64
+}
+
+// registerValidatorLimit returns the body limit of registerValidator requests
+func (l bodyLimits) registerValidatorLimit(*http.Request) int64 {
+	if l.registerValidator > 0 {
+		return l.registerValidator
+	}
+	return defaultRegisterValidatorMaxBytes
+}
+
+// getPayloadLimit returns the body limit of getPayload requests, by the consensus version of the request
+func (l bodyLimits) getPayloadLimit(req *http.Request) int64 {
+	if l.getPayload > 0 {
+		return l.getPayload
+	}
+	if limit, ok := defaultGetPayloadMaxBytes[strings.ToLower(req.Header.Get(HeaderEthConsensusVersion))]; ok {
+		return limit
+	}
+	var limit int64
+	for _, forkLimit := range defaultGetPayloadMaxBytes {
+		limit = max(limit, forkLimit)
+	}
+	return limit
+}
+
+// respondBodyError responds with 413 if reading the request body failed on the body limit, else with 400
+func (m *BoostService) respondBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		m.respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", maxBytesErr.Limit))
+		return
+	}
+	m.respondError(w, http.StatusBadRequest, err.Error())
+}
+
+// limitBody refuses requests with bodies larger than the limit with 413. Requests announcing a larger body are
+// refused before reading it, the body of the other requests fails to read beyond the limit.
+func (m *BoostService) limitBody(limit func(req *http.Request) int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		maxBytes := limit(req)
+		if req.ContentLength > maxBytes {
+			m.log.WithFields(logrus.Fields{
+				"path":          req.URL.Path,
+				"contentLength": req.ContentLength,
+				"limit":         maxBytes,
+			}).Warn("refused request with body over the limit")
+			m.respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", maxBytes))
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, maxBytes)
+		next(w, req)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimits(t *testing.T) {
+	t.Run("Fork-aware getPayload defaults", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, params.PathGetPayload, nil)
+		req.Header.Set(HeaderEthConsensusVersion, "deneb")
+		require.Equal(t, int64(4<<20), bodyLimits{}.getPayloadLimit(req))
+		req.Header.Set(HeaderEthConsensusVersion, "Electra")
+		require.Equal(t, int64(8<<20), bodyLimits{}.getPayloadLimit(req))
+		req.Header.Del(HeaderEthConsensusVersion)
+		require.Equal(t, int64(8<<20), bodyLimits{}.getPayloadLimit(req))
+
+		require.Equal(t, int64(1000), bodyLimits{getPayload: 1000}.getPayloadLimit(req))
+		require.Equal(t, int64(defaultRegisterValidatorMaxBytes), bodyLimits{}.registerValidatorLimit(req))
+	})
+
+	t.Run("Oversized registrations are refused with 413", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.bodyLimits.registerValidator = 1000
+		payload := []*builderApiV1.SignedValidatorRegistration{testRegistration(), testRegistration(), testRegistration()}
+
+		rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+		// Without content length, reading the body fails at the limit
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, params.PathRegisterValidator, io.MultiReader(bytes.NewReader(body)))
+		req.ContentLength = -1
+		rr = httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+		rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, payload[:1])
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Oversized blinded blocks are refused with 413", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.bodyLimits.getPayload = 100
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, map[string]string{"message": string(make([]byte, 200))})
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Circuit states of a relay
+const (
+	circuitClosed   = "closed"    // the relay is queried
+	circuitOpen     = "open"      // the relay is not queried until the cool-down is over
+	circuitHalfOpen = "half-open" // a single probe request decides whether to close or open the circuit again
+)
+
+var (
+	relayCircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "relay_circuit_open",
+		Help:      "Whether the circuit breaker of the relay is open (1) or not (0)",
+	}, []string{"relay"})
+	relayCircuitTrips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "relay_circuit_trips_total",
+		Help:      "Number of times the circuit breaker of the relay opened",
+	}, []string{"relay"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(relayCircuitOpen, relayCircuitTrips)
+}
+
+// relayCircuit is the circuit breaker state of a relay
+type relayCircuit struct {
+	state      string
+	failures   int       // consecutive failures
+	openedAt   time.Time // when the circuit opened
+	probeStart time.Time // when the probe of the half-open circuit started
+}
+
+// circuitBreaker stops querying relays for getHeader after consecutive failures. After the cool-down a single probe
+// request is sent, which closes the circuit if successful and opens it again otherwise.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	metrics   MetricsSink
+	circuits  map[string]*relayCircuit
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, metrics MetricsSink) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		metrics:   metrics,
+		circuits:  make(map[string]*relayCircuit),
+	}
+}
+
+// allow returns whether to query the relay, starting a probe if the cool-down of an open circuit is over
+func (b *circuitBreaker) allow(relay types.RelayEntry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.get(relay)
+	switch c.state {
+	case circuitOpen:
+		if time.Since(c.openedAt) < b.cooldown {
+			return false
+		}
+		c.state = circuitHalfOpen
+		c.probeStart = time.Now()
+		return true
+	case circuitHalfOpen:
+		// Only one probe at a time, unless the probe never finished
+		if time.Since(c.probeStart) < b.cooldown {
+			return false
+		}
+		c.probeStart = time.Now()
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a request to the relay, returning true if the relay recovered (the circuit closed)
+func (b *circuitBreaker) record(relay types.RelayEntry, failed bool) (recovered bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.get(relay)
+	if !failed {
+		if c.state != circuitClosed {
+			relayCircuitOpen.WithLabelValues(relayLabel(relay)).Set(0)
+		}
+		recovered = c.state != circuitClosed
+		c.state = circuitClosed
+		c.failures = 0
+		return recovered
+	}
+
+	c.failures++
+	if c.state == circuitHalfOpen || (c.state == circuitClosed && c.failures >= b.threshold) {
+		c.state = circuitOpen
+		c.openedAt = time.Now()
+		relayCircuitOpen.WithLabelValues(relayLabel(relay)).Set(1)
+		relayCircuitTrips.WithLabelValues(relayLabel(relay)).Inc()
+		b.metrics.Count("relay_circuit_trips", relayMetricTags(relay, map[string]string{}))
+	}
+	return false
+}
+
+// state returns the circuit state of the relay
+func (b *circuitBreaker) state(relay types.RelayEntry) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.get(relay).state
+}
+
+// export returns the circuits of the relays by relay URL
+func (b *circuitBreaker) export() map[string]relayCircuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	circuits := make(map[string]relayCircuit, len(b.circuits))
+	for key, c := range b.circuits {
+		circuits[key] = *c
+	}
+	return circuits
+}
+
+// restore replaces the circuit of the relay by an exported one, i.e. after a restart. A half-open circuit is restored
+// as open, the probe starts again.
+func (b *circuitBreaker) restore(relay string, c relayCircuit) {
+	if c.state == circuitHalfOpen {
+		c.state, c.probeStart = circuitOpen, time.Time{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.circuits[relay] = &c
+}
+
+// get returns the circuit of a relay, creating it if needed. The caller must hold the lock.
+func (b *circuitBreaker) get(relay types.RelayEntry) *relayCircuit {
+	c, ok := b.circuits[relay.String()]
+	if !ok {
+		c = &relayCircuit{state: circuitClosed}
+		b.circuits[relay.String()] = c
+	}
+	return c
+}
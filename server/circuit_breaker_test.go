@@ -0,0 +1,90 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	relay := mock.NewRelay(t).RelayEntry
+
+	t.Run("Opens after consecutive failures", func(t *testing.T) {
+		breaker := newCircuitBreaker(3, time.Hour, nopMetricsSink{})
+		breaker.record(relay, true)
+		breaker.record(relay, true)
+		breaker.record(relay, false)
+		breaker.record(relay, true)
+		breaker.record(relay, true)
+		require.Equal(t, circuitClosed, breaker.state(relay))
+		require.True(t, breaker.allow(relay))
+
+		breaker.record(relay, true)
+		require.Equal(t, circuitOpen, breaker.state(relay))
+		require.False(t, breaker.allow(relay))
+	})
+
+	t.Run("Probes after the cool-down", func(t *testing.T) {
+		breaker := newCircuitBreaker(1, 10*time.Millisecond, nopMetricsSink{})
+		breaker.record(relay, true)
+		require.False(t, breaker.allow(relay))
+
+		time.Sleep(20 * time.Millisecond)
+		require.True(t, breaker.allow(relay))
+		require.Equal(t, circuitHalfOpen, breaker.state(relay))
+		require.False(t, breaker.allow(relay), "only one probe at a time")
+
+		// A failed probe opens the circuit again
+		breaker.record(relay, true)
+		require.Equal(t, circuitOpen, breaker.state(relay))
+
+		// A successful probe closes it
+		time.Sleep(20 * time.Millisecond)
+		require.True(t, breaker.allow(relay))
+		breaker.record(relay, false)
+		require.Equal(t, circuitClosed, breaker.state(relay))
+		require.True(t, breaker.allow(relay))
+	})
+
+	t.Run("Export and restore", func(t *testing.T) {
+		breaker := newCircuitBreaker(1, time.Hour, nopMetricsSink{})
+		breaker.record(relay, true)
+		circuits := breaker.export()
+		require.Equal(t, circuitOpen, circuits[relay.String()].state)
+
+		// A half-open circuit is restored as open
+		restored := newCircuitBreaker(1, time.Hour, nopMetricsSink{})
+		restored.restore(relay.String(), relayCircuit{state: circuitHalfOpen, failures: 1, openedAt: time.Now(), probeStart: time.Now()})
+		require.Equal(t, circuitOpen, restored.state(relay))
+		require.False(t, restored.allow(relay))
+	})
+
+	t.Run("Skips relays with open circuit in getHeader", func(t *testing.T) {
+		hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+		pubkey := mock.HexToPubkey(
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+		path := getHeaderPath(1, hash, pubkey)
+
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.breaker = newCircuitBreaker(2, time.Hour, nopMetricsSink{})
+
+		// The requests to the closed relay fail with connection refused
+		backend.relays[0].Server.Close()
+		for range 2 {
+			rr := backend.request(t, http.MethodGet, path, nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		require.Equal(t, circuitOpen, backend.boost.breaker.state(backend.relays[0].RelayEntry))
+		require.Equal(t, circuitClosed, backend.boost.breaker.state(backend.relays[1].RelayEntry))
+
+		// A relay with open circuit is not queried
+		backend.boost.breaker.record(backend.relays[1].RelayEntry, true)
+		backend.boost.breaker.record(backend.relays[1].RelayEntry, true)
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, 2, backend.relays[1].GetRequestCount(path))
+	})
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+	"slices"
+)
+
+var errClientForbidden = errors.New("client address not allowed")
+
+// clientAllowed returns false for addresses in the denylist, or outside of the allowlist if there is one
+func clientAllowed(addr netip.Addr, allowlist, denylist []netip.Prefix) bool {
+	addr = addr.Unmap()
+	contains := func(prefix netip.Prefix) bool { return prefix.Contains(addr) }
+	if slices.ContainsFunc(denylist, contains) {
+		return false
+	}
+	return len(allowlist) == 0 || slices.ContainsFunc(allowlist, contains)
+}
+
+// filterClients is a middleware refusing requests of client addresses which aren't allowed with 403. Requests over a
+// unix socket have no client address and aren't filtered, forwarding headers are ignored.
+func (m *BoostService) filterClients(next http.Handler) http.Handler {
+	if len(m.clientAllowlist) == 0 && len(m.clientDenylist) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		addr, err := netip.ParseAddrPort(req.RemoteAddr)
+		if err == nil && !clientAllowed(addr.Addr(), m.clientAllowlist, m.clientDenylist) {
+			m.log.WithField("remoteAddr", req.RemoteAddr).WithField("path", req.URL.Path).Warn("refused request of a client address which isn't allowed")
+			m.respondError(w, http.StatusForbidden, errClientForbidden.Error())
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
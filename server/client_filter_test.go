@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFilter(t *testing.T) {
+	// status returns the response code of a status request from the client address
+	status := func(backend *testBackend, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, params.PathStatus, nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("All clients are allowed by default", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.Equal(t, http.StatusOK, status(backend, "203.0.113.1:1234"))
+	})
+
+	t.Run("Only clients of the allowlist are allowed", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.clientAllowlist = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("10.0.0.0/8")}
+		require.Equal(t, http.StatusOK, status(backend, "127.0.0.1:1234"))
+		require.Equal(t, http.StatusOK, status(backend, "10.1.2.3:1234"))
+		require.Equal(t, http.StatusOK, status(backend, "[::ffff:10.1.2.3]:1234"))
+		require.Equal(t, http.StatusForbidden, status(backend, "203.0.113.1:1234"))
+		require.Equal(t, http.StatusForbidden, status(backend, "[::1]:1234"))
+	})
+
+	t.Run("Clients of the denylist are refused", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.clientAllowlist = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+		backend.boost.clientDenylist = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}
+		require.Equal(t, http.StatusOK, status(backend, "10.1.2.3:1234"))
+		require.Equal(t, http.StatusForbidden, status(backend, "10.0.0.3:1234"))
+	})
+
+	t.Run("Unix socket clients aren't filtered", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.clientAllowlist = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+		require.Equal(t, http.StatusOK, status(backend, "@"))
+	})
+}
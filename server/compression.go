@@ -0,0 +1,55 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// compressionTransport gzips the request bodies of at least minSize bytes. Responses are decompressed by the base
+// transport, which asks for gzip responses.
+type compressionTransport struct {
+	base    http.RoundTripper
+	minSize int64
+}
+
+// newCompressionTransport returns the transport of the relay clients, compressing request bodies of at least minSize
+// bytes. Nothing is compressed if minSize is zero.
+func newCompressionTransport(minSize int) http.RoundTripper {
+	if minSize <= 0 {
+		return nil
+	}
+	return &compressionTransport{base: http.DefaultTransport, minSize: int64(minSize)}
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.ContentLength < t.minSize || req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, fmt.Errorf("could not compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("could not compress request body: %w", err)
+	}
+
+	compressed := buf.Bytes()
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(compressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.ContentLength = int64(len(compressed))
+	req.Header.Set("Content-Encoding", "gzip")
+	return t.base.RoundTrip(req)
+}
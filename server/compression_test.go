@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionTransport(t *testing.T) {
+	require.Nil(t, newCompressionTransport(0))
+
+	var contentEncoding string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+		reader := io.Reader(r.Body)
+		if contentEncoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			reader = zr
+		}
+		var err error
+		body, err = io.ReadAll(reader)
+		require.NoError(t, err)
+
+		// Respond gzipped, the response is decompressed transparently
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err = zw.Write([]byte(`{ "msg": "test-message" }`))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer ts.Close()
+
+	client := http.Client{Transport: newCompressionTransport(100)}
+	payload := strings.Repeat("a", 100)
+	resp := struct{ Msg string }{}
+	code, err := SendHTTPRequest(context.Background(), client, http.MethodPost, ts.URL, "", nil, payload, &resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "gzip", contentEncoding)
+	require.Equal(t, `"`+payload+`"`, string(body))
+	require.Equal(t, "test-message", resp.Msg)
+
+	// Smaller bodies are sent uncompressed
+	code, err = SendHTTPRequest(context.Background(), client, http.MethodPost, ts.URL, "", nil, "small", &resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, contentEncoding)
+	require.Equal(t, `"small"`, string(body))
+}
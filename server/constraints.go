@@ -0,0 +1,291 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	fastssz "github.com/ferranbt/fastssz"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// constraintsRetentionSlots is the number of slots the constraints are kept for after their slot
+	constraintsRetentionSlots = 64
+
+	// maxBytesPerTransaction and maxTransactionsPerPayload are the SSZ limits of the transactions of a payload
+	maxBytesPerTransaction    = 1 << 30
+	maxTransactionsPerPayload = 1 << 20
+	// transactionsGeneralizedIndex is the generalized index of the first transaction in the transactions list, whose
+	// length is mixed into the transactions root
+	transactionsGeneralizedIndex = 2 * maxTransactionsPerPayload
+)
+
+var (
+	errNoConstraintsRelays    = errors.New("no relay supports the constraints API")
+	errMissingInclusionProofs = errors.New("bid without inclusion proofs of the constraints")
+	errInvalidInclusionProofs = errors.New("invalid inclusion proofs of the constraints")
+	errConstraintNotProven    = errors.New("constrained transaction not proven")
+)
+
+// signedConstraints are the transactions the proposer, or its delegate, committed to include in the block of a slot
+type signedConstraints struct {
+	Message struct {
+		Pubkey       phase0.BLSPubKey `json:"pubkey"`
+		Slot         uint64           `json:"slot"`
+		Top          bool             `json:"top"`
+		Transactions []hexutil.Bytes  `json:"transactions"`
+	} `json:"message"`
+	Signature phase0.BLSSignature `json:"signature"`
+}
+
+// inclusionProofs prove that the constrained transactions are in the transactions root of a bid
+type inclusionProofs struct {
+	TransactionHashes  []phase0.Hash32 `json:"transaction_hashes"`
+	GeneralizedIndexes []uint64        `json:"generalized_indexes"`
+	MerkleHashes       []phase0.Hash32 `json:"merkle_hashes"`
+}
+
+// bidWithProofs is a bid of the constraints API, with the inclusion proofs next to the signed bid
+type bidWithProofs struct {
+	bid    *builderSpec.VersionedSignedBuilderBid
+	proofs *inclusionProofs
+}
+
+func (b *bidWithProofs) UnmarshalJSON(input []byte) error {
+	if err := json.Unmarshal(input, b.bid); err != nil {
+		return err
+	}
+	var data struct {
+		Data struct {
+			Proofs *inclusionProofs `json:"proofs"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return err
+	}
+	b.proofs = data.Data.Proofs
+	return nil
+}
+
+// slotConstraints are the constrained transactions of the recent slots
+type slotConstraints struct {
+	mu    sync.Mutex
+	slots map[phase0.Slot][]hexutil.Bytes
+}
+
+func newSlotConstraints() *slotConstraints {
+	return &slotConstraints{slots: make(map[phase0.Slot][]hexutil.Bytes)}
+}
+
+// add records the constrained transactions, and forgets the constraints of old slots
+func (c *slotConstraints) add(constraints []signedConstraints) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, constraint := range constraints {
+		slot := phase0.Slot(constraint.Message.Slot)
+		c.slots[slot] = append(c.slots[slot], constraint.Message.Transactions...)
+		for s := range c.slots {
+			if s+constraintsRetentionSlots < slot {
+				delete(c.slots, s)
+			}
+		}
+	}
+}
+
+// get returns the constrained transactions of the slot
+func (c *slotConstraints) get(slot phase0.Slot) []hexutil.Bytes {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.slots[slot]
+}
+
+// verifyInclusionProofs checks that the proofs of a bid prove the inclusion of all constrained transactions in its
+// transactions root
+func verifyInclusionProofs(txRoot phase0.Root, constraints []hexutil.Bytes, proofs *inclusionProofs) error {
+	if proofs == nil {
+		return errMissingInclusionProofs
+	}
+	roots := make(map[phase0.Hash32][]byte, len(constraints))
+	for _, tx := range constraints {
+		root, err := transactionRoot(tx)
+		if err != nil {
+			return err
+		}
+		roots[phase0.Hash32(crypto.Keccak256Hash(tx))] = root[:]
+	}
+	if len(proofs.TransactionHashes) != len(roots) || len(proofs.GeneralizedIndexes) != len(roots) {
+		return fmt.Errorf("%w: %d proofs for %d transactions", errInvalidInclusionProofs, len(proofs.TransactionHashes), len(roots))
+	}
+
+	leaves := make([][]byte, len(proofs.TransactionHashes))
+	indices := make([]int, len(proofs.GeneralizedIndexes))
+	for i, hash := range proofs.TransactionHashes {
+		root, ok := roots[hash]
+		if !ok {
+			return fmt.Errorf("%w: %s", errConstraintNotProven, hash)
+		}
+		delete(roots, hash)
+		leaves[i] = root
+
+		// The indexes must point into the transactions list, rather than at any other node of the tree
+		index := proofs.GeneralizedIndexes[i]
+		if index < transactionsGeneralizedIndex || index >= transactionsGeneralizedIndex+maxTransactionsPerPayload {
+			return fmt.Errorf("%w: generalized index %d is not a transaction", errInvalidInclusionProofs, index)
+		}
+		indices[i] = int(index)
+	}
+	hashes := make([][]byte, len(proofs.MerkleHashes))
+	for i := range proofs.MerkleHashes {
+		hashes[i] = proofs.MerkleHashes[i][:]
+	}
+
+	if !verifyMultiproof(txRoot[:], hashes, leaves, indices) {
+		return errInvalidInclusionProofs
+	}
+	return nil
+}
+
+// verifyMultiproof checks the proof of the leaves at the generalized indexes, which are all of the same depth, against
+// the root. The proof hashes are the sibling nodes needed to compute the root, by decreasing generalized index.
+func verifyMultiproof(root []byte, proof, leaves [][]byte, indexes []int) bool {
+	nodes := make(map[int][]byte, len(leaves)+len(proof))
+	onPath := make(map[int]bool)
+	for i, index := range indexes {
+		nodes[index] = leaves[i]
+		for node := index; node > 1; node >>= 1 {
+			onPath[node] = true
+		}
+	}
+	required := make([]int, 0, len(proof))
+	for node := range onPath {
+		if !onPath[node^1] {
+			required = append(required, node^1)
+		}
+	}
+	if len(required) != len(proof) {
+		return false
+	}
+	slices.Sort(required)
+	slices.Reverse(required)
+	for i, index := range required {
+		nodes[index] = proof[i]
+	}
+
+	// Compute the parents level by level, up to the root
+	level := indexes
+	for len(level) > 0 && level[0] > 1 {
+		parents := make([]int, 0, len(level))
+		for _, node := range level {
+			parent := node >> 1
+			if _, ok := nodes[parent]; ok {
+				continue
+			}
+			hash := sha256.Sum256(append(slices.Clone(nodes[parent<<1]), nodes[parent<<1|1]...))
+			nodes[parent] = hash[:]
+			parents = append(parents, parent)
+		}
+		level = parents
+	}
+	return bytes.Equal(nodes[1], root)
+}
+
+// transactionRoot returns the SSZ hash tree root of a transaction, a leaf of the transactions root
+func transactionRoot(tx []byte) ([32]byte, error) {
+	hh := fastssz.NewHasher()
+	index := hh.Index()
+	hh.AppendBytes32(tx)
+	hh.MerkleizeWithMixin(index, uint64(len(tx)), (maxBytesPerTransaction+31)/32)
+	return hh.HashRoot()
+}
+
+// handleSubmitConstraints forwards the constraints of the proposer to the relays supporting the constraints API, and
+// remembers them to check the inclusion proofs of the bids
+func (m *BoostService) handleSubmitConstraints(w http.ResponseWriter, req *http.Request) {
+	log := m.log.WithField("method", "submitConstraints")
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var constraints []signedConstraints
+	if err := json.Unmarshal(body, &constraints); err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log = log.WithField("numConstraints", len(constraints))
+
+	if err := m.forwardToConstraintsRelays(req, log, params.PathConstraints, json.RawMessage(body)); err != nil {
+		m.respondError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	m.constraints.add(constraints)
+	m.respondOK(w, nilResponse)
+}
+
+// handleConstraintsDelegation forwards delegations and revocations of the constraints signing key to the relays
+// supporting the constraints API
+func (m *BoostService) handleConstraintsDelegation(w http.ResponseWriter, req *http.Request) {
+	log := m.log.WithField("method", "constraintsDelegation")
+	var payload json.RawMessage
+	if err := DecodeJSON(req.Body, &payload); err != nil {
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := m.forwardToConstraintsRelays(req, log, req.URL.Path, payload); err != nil {
+		m.respondError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	m.respondOK(w, nilResponse)
+}
+
+// forwardToConstraintsRelays posts the payload to the relays supporting the constraints API, and succeeds if any relay
+// accepted it
+func (m *BoostService) forwardToConstraintsRelays(req *http.Request, log *logrus.Entry, path string, payload any) error {
+	ctx := context.WithoutCancel(req.Context())
+	ua := UserAgent(req.Header.Get("User-Agent"))
+	cfg := m.currentConfig()
+	relayRespCh := make(chan error, len(cfg.relays))
+	numRelays := 0
+	for _, relay := range cfg.relays {
+		if !relay.Constraints {
+			continue
+		}
+		numRelays++
+		go func(relay types.RelayEntry) {
+			url := relay.GetURI(path)
+			start := time.Now()
+			code, err := SendHTTPRequest(ctx, cfg.regValClient(relay), http.MethodPost, url, ua, nil, payload, nil)
+			m.recordRelayRequest(relay, "constraints", start, code, err)
+			if err != nil {
+				log.WithError(err).WithField("url", url).Warn("error forwarding constraints to relay")
+			}
+			relayRespCh <- err
+		}(relay)
+	}
+	if numRelays == 0 {
+		return errNoConstraintsRelays
+	}
+
+	for range numRelays {
+		if err := <-relayRespCh; err == nil {
+			return nil
+		}
+	}
+	return errNoSuccessfulRelayResponse
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	utilbellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	fastssz "github.com/ferranbt/fastssz"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testInclusionProofs returns the transactions root of the transactions, and the proofs of the transactions at the
+// indexes
+func testInclusionProofs(t *testing.T, txs []hexutil.Bytes, indexes ...int) (phase0.Root, *inclusionProofs) {
+	t.Helper()
+	transactions := make([]bellatrix.Transaction, len(txs))
+	leaves := make([]*fastssz.Node, len(txs))
+	for i, tx := range txs {
+		transactions[i] = bellatrix.Transaction(tx)
+		root, err := transactionRoot(tx)
+		require.NoError(t, err)
+		leaves[i] = fastssz.NewNodeWithValue(root[:])
+	}
+	txRoot, err := (&utilbellatrix.ExecutionPayloadTransactions{Transactions: transactions}).HashTreeRoot()
+	require.NoError(t, err)
+
+	tree, err := fastssz.TreeFromNodesWithMixin(leaves, len(leaves), maxTransactionsPerPayload)
+	require.NoError(t, err)
+	require.Equal(t, txRoot[:], tree.Hash())
+	generalizedIndexes := make([]int, len(indexes))
+	proofs := new(inclusionProofs)
+	for i, index := range indexes {
+		generalizedIndexes[i] = transactionsGeneralizedIndex + index
+		proofs.TransactionHashes = append(proofs.TransactionHashes, phase0.Hash32(crypto.Keccak256Hash(txs[index])))
+		proofs.GeneralizedIndexes = append(proofs.GeneralizedIndexes, uint64(transactionsGeneralizedIndex+index))
+	}
+	multiproof, err := tree.ProveMulti(generalizedIndexes)
+	require.NoError(t, err)
+	for _, hash := range multiproof.Hashes {
+		proofs.MerkleHashes = append(proofs.MerkleHashes, phase0.Hash32(hash))
+	}
+	return txRoot, proofs
+}
+
+func TestVerifyInclusionProofs(t *testing.T) {
+	txs := []hexutil.Bytes{{0x02, 0x01}, {0x02, 0x02, 0x03}, {0x02, 0x04}}
+	txRoot, proofs := testInclusionProofs(t, txs, 0, 2)
+
+	t.Run("Valid proofs", func(t *testing.T) {
+		require.NoError(t, verifyInclusionProofs(txRoot, []hexutil.Bytes{txs[2], txs[0]}, proofs))
+	})
+
+	t.Run("Missing proofs", func(t *testing.T) {
+		require.ErrorIs(t, verifyInclusionProofs(txRoot, txs[:1], nil), errMissingInclusionProofs)
+	})
+
+	t.Run("Unproven transaction", func(t *testing.T) {
+		require.ErrorIs(t, verifyInclusionProofs(txRoot, []hexutil.Bytes{txs[1], txs[0]}, proofs), errConstraintNotProven)
+		require.ErrorIs(t, verifyInclusionProofs(txRoot, txs, proofs), errInvalidInclusionProofs)
+	})
+
+	t.Run("Other transactions root", func(t *testing.T) {
+		otherRoot, _ := testInclusionProofs(t, txs[:2], 0)
+		require.ErrorIs(t, verifyInclusionProofs(otherRoot, []hexutil.Bytes{txs[0], txs[2]}, proofs), errInvalidInclusionProofs)
+	})
+
+	t.Run("Index outside of the transactions", func(t *testing.T) {
+		_, proofs := testInclusionProofs(t, txs, 0)
+		proofs.GeneralizedIndexes[0] = 2
+		require.ErrorIs(t, verifyInclusionProofs(txRoot, txs[:1], proofs), errInvalidInclusionProofs)
+	})
+}
+
+func TestConstraints(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	txs := []hexutil.Bytes{{0x02, 0x01}, {0x02, 0x02, 0x03}}
+	constraints := []signedConstraints{{}}
+	constraints[0].Message.Slot = 1
+	constraints[0].Message.Transactions = txs[1:]
+
+	// Relay 0 supports the constraints API
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.relays[0].Constraints = true
+		backend.boost.signatureCheck = types.SignatureCheckSkip
+		return backend
+	}
+
+	t.Run("Forwards constraints to the constraints relays", func(t *testing.T) {
+		backend := setup(t)
+		rr := backend.request(t, http.MethodPost, params.PathConstraints, constraints)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathConstraints))
+		require.Equal(t, 0, backend.relays[1].GetRequestCount(params.PathConstraints))
+		require.Equal(t, txs[1:], backend.boost.constraints.get(1))
+	})
+
+	t.Run("No constraints relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := backend.request(t, http.MethodPost, params.PathConstraints, constraints)
+		require.Equal(t, http.StatusBadGateway, rr.Code)
+		require.Empty(t, backend.boost.constraints.get(1))
+	})
+
+	getHeader := func(t *testing.T, proofs *inclusionProofs) *testBackend {
+		t.Helper()
+		backend := setup(t)
+		backend.boost.constraints.add(constraints)
+		txRoot, _ := testInclusionProofs(t, txs)
+		bid := backend.relays[0].MakeGetHeaderResponse(
+			12345,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		bid.Deneb.Message.Header.TransactionsRoot = txRoot
+		backend.relays[0].GetHeaderResponse = bid
+		backend.relays[0].GetHeaderProofs = proofs
+		return backend
+	}
+	proofsPath := "/eth/v1/builder/header_with_proofs/1/" + hash.String() + "/" + pubkey.String()
+
+	t.Run("Bids proving the constraints are used", func(t *testing.T) {
+		_, proofs := testInclusionProofs(t, txs, 1)
+		backend := getHeader(t, proofs)
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(proofsPath))
+		require.Equal(t, 0, backend.relays[1].GetRequestCount(getHeaderPath(1, hash, pubkey)))
+	})
+
+	t.Run("Bids without proofs are ignored", func(t *testing.T) {
+		backend := getHeader(t, nil)
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Bids with invalid proofs are ignored", func(t *testing.T) {
+		_, proofs := testInclusionProofs(t, txs, 1)
+		proofs.MerkleHashes[0][0] ^= 1
+		backend := getHeader(t, proofs)
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Bids of slots without constraints are not checked", func(t *testing.T) {
+		backend := getHeader(t, nil)
+		rr := backend.request(t, http.MethodGet, getHeaderPath(2, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(getHeaderPath(2, hash, pubkey)))
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// payloadDeliveryCheckAttempts is the number of times the relay data API is queried before a delivery counts as unconfirmed
+const payloadDeliveryCheckAttempts = 3
+
+// deliveredPayloadTrace is the part of the relay data API bid trace needed to confirm a delivery
+type deliveredPayloadTrace struct {
+	Slot      string `json:"slot"`
+	BlockHash string `json:"block_hash"`
+}
+
+// checkPayloadDelivery queries the data API of the relay which delivered the payload, until it reports the delivery.
+// Relays which serve the payload but never report the delivery might not have published the block.
+func (m *BoostService) checkPayloadDelivery(log *logrus.Entry, relay types.RelayEntry, slot phase0.Slot, slotUID string, blockHash phase0.Hash32) {
+	defer m.reportPanic(backgroundTaskTags("checkPayloadDelivery", slot, slotUID))
+	log = log.WithField("relay", relay.GetURI(""))
+	for attempt := 1; attempt <= payloadDeliveryCheckAttempts; attempt++ {
+		time.Sleep(m.payloadDeliveryCheckDelay)
+		delivered, err := m.relayReportsPayloadDelivered(relay, slot, blockHash)
+		if err != nil {
+			log.WithError(err).WithField("attempt", attempt).Warn("could not query payload delivery from relay data API")
+			continue
+		}
+		if delivered {
+			log.Debug("relay data API reports payload delivery")
+			return
+		}
+	}
+
+	log.Error("relay data API does not report the payload delivery, the block might not have been published")
+	m.relayStats.record(relay, relayStatsPayloadUnconfirmed)
+	m.emitEvent(Event{
+		Type:      EventPayloadUnconfirmed,
+		Slot:      uint64(slot),
+		SlotUID:   slotUID,
+		BlockHash: blockHash.String(),
+		Relays:    []string{relay.GetURI("")},
+	})
+}
+
+// relayReportsPayloadDelivered returns true if the relay data API lists the payload as delivered
+func (m *BoostService) relayReportsPayloadDelivered(relay types.RelayEntry, slot phase0.Slot, blockHash phase0.Hash32) (bool, error) {
+	url := relay.GetURIWithQuery(params.PathDataProposerPayloadDelivered, url.Values{"slot": []string{strconv.FormatUint(uint64(slot), 10)}})
+
+	traces := []deliveredPayloadTrace{}
+	if _, err := SendHTTPRequest(context.Background(), m.currentConfig().dataAPIClient(relay), http.MethodGet, url, "", nil, nil, &traces); err != nil {
+		return false, err
+	}
+	for _, trace := range traces {
+		if trace.Slot == strconv.FormatUint(uint64(slot), 10) && strings.EqualFold(trace.BlockHash, blockHash.String()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPayloadDelivery(t *testing.T) {
+	blockHash := mock.HexToHash("0x534809bd2b6832edff8d8ce4cb0e50068804fd1ef432c8362ad708a74fdc0e46")
+
+	// newDataAPIRelay returns a relay under the base path whose data API reports the given traces after the delay
+	newDataAPIRelay := func(t *testing.T, basePath string, traces []deliveredPayloadTrace, delay time.Duration) (types.RelayEntry, *atomic.Int32) {
+		t.Helper()
+		requests := new(atomic.Int32)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, basePath+params.PathDataProposerPayloadDelivered, r.URL.Path)
+			require.Equal(t, "1", r.URL.Query().Get("slot"))
+			requests.Add(1)
+			time.Sleep(delay)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(traces)
+		}))
+		t.Cleanup(ts.Close)
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		relay, err := types.NewRelayEntry(fmt.Sprintf("http://%s@%s%s", mock.NewRelay(t).RelayEntry.PublicKey.String(), u.Host, basePath))
+		require.NoError(t, err)
+		return relay, requests
+	}
+
+	t.Run("Delivery reported", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.payloadDeliveryCheckDelay = time.Millisecond
+		relay, requests := newDataAPIRelay(t, "/relay", []deliveredPayloadTrace{{Slot: "1", BlockHash: blockHash.String()}}, 0)
+
+		backend.boost.checkPayloadDelivery(mock.TestLog, relay, 1, "", blockHash)
+		require.Equal(t, int32(1), requests.Load())
+		require.Zero(t, backend.boost.relayStats.snapshot([]types.RelayEntry{relay})[0].PayloadsUnconfirmed)
+	})
+
+	t.Run("Delivery never reported", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.payloadDeliveryCheckDelay = time.Millisecond
+		relay, requests := newDataAPIRelay(t, "", []deliveredPayloadTrace{{Slot: "1", BlockHash: nilHash.String()}}, 0)
+
+		events := backend.boost.events.subscribe()
+		backend.boost.checkPayloadDelivery(mock.TestLog, relay, 1, "", blockHash)
+		require.Equal(t, int32(payloadDeliveryCheckAttempts), requests.Load())
+		require.Equal(t, uint64(1), backend.boost.relayStats.snapshot([]types.RelayEntry{relay})[0].PayloadsUnconfirmed)
+
+		event := <-events
+		require.Equal(t, EventPayloadUnconfirmed, event.Type)
+		require.Equal(t, blockHash.String(), event.BlockHash)
+	})
+
+	t.Run("Slower data API than getHeader timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 1, 50*time.Millisecond)
+		backend.boost.payloadDeliveryCheckDelay = time.Millisecond
+		relay, requests := newDataAPIRelay(t, "", []deliveredPayloadTrace{{Slot: "1", BlockHash: blockHash.String()}}, 200*time.Millisecond)
+
+		backend.boost.checkPayloadDelivery(mock.TestLog, relay, 1, "", blockHash)
+		require.Equal(t, int32(1), requests.Load())
+		require.Zero(t, backend.boost.relayStats.snapshot([]types.RelayEntry{relay})[0].PayloadsUnconfirmed)
+	})
+}
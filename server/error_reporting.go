@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/gorilla/mux"
+)
+
+// severityError is the severity of events about a failure which already happened, i.e. a missed block
+const severityError = "error"
+
+// ErrorReporter receives panics and high-severity auction failures, i.e. a SentryReporter
+type ErrorReporter interface {
+	// ReportPanic is called with the recovered value of a panic. The process may crash afterwards, so it should not
+	// return before the report is sent.
+	ReportPanic(recovered any, tags map[string]string)
+	// ReportEvent is called for auction events with severity error or critical
+	ReportEvent(event Event)
+}
+
+// nopErrorReporter drops all reports. It's used unless an ErrorReporter is configured.
+type nopErrorReporter struct{}
+
+func (nopErrorReporter) ReportPanic(any, map[string]string) {}
+func (nopErrorReporter) ReportEvent(Event)                  {}
+
+// highSeverity returns true for the events which are passed on to the ErrorReporter
+func (e Event) highSeverity() bool {
+	return e.Severity == severityError || e.Severity == severityCritical
+}
+
+// reportPanic reports a panic to the ErrorReporter and panics again, so the panic is handled as without reporting.
+// It must be called deferred.
+func (m *BoostService) reportPanic(tags map[string]string) {
+	if recovered := recover(); recovered != nil {
+		m.errorReporter.ReportPanic(recovered, tags)
+		panic(recovered)
+	}
+}
+
+// reportPanics is a middleware reporting panics of the handlers, tagged with the request and slot details.
+// The logging middleware recovers them afterwards.
+func (m *BoostService) reportPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tags := map[string]string{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		}
+		for key, value := range mux.Vars(req) {
+			tags[key] = value
+		}
+		if slotUID := req.Header.Get(HeaderKeySlotUID); slotUID != "" {
+			tags["slotUID"] = slotUID
+		}
+		defer m.reportPanic(tags)
+		next.ServeHTTP(w, req)
+	})
+}
+
+// backgroundTaskTags are the tags of panics in the background tasks for a slot
+func backgroundTaskTags(task string, slot phase0.Slot, slotUID string) map[string]string {
+	return map[string]string{"task": task, "slot": strconv.FormatUint(uint64(slot), 10), "slotUID": slotUID}
+}
+
+// relayTaskTags are the tags of panics in the requests to a relay for a slot
+func relayTaskTags(task string, slot phase0.Slot, slotUID string, relay types.RelayEntry) map[string]string {
+	tags := backgroundTaskTags(task, slot, slotUID)
+	tags["relay"] = relay.GetURI("")
+	return tags
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// testErrorReporter records the reports
+type testErrorReporter struct {
+	mu     sync.Mutex
+	panics []map[string]string
+	events []Event
+}
+
+func (r *testErrorReporter) ReportPanic(_ any, tags map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.panics = append(r.panics, tags)
+}
+
+func (r *testErrorReporter) ReportEvent(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestErrorReporter(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	reporter := &testErrorReporter{}
+	backend.boost.errorReporter = reporter
+
+	t.Run("High-severity events", func(t *testing.T) {
+		backend.boost.emitEvent(Event{Type: EventNoBids, Slot: 1})
+		backend.boost.emitEvent(Event{Type: EventGetPayloadFailed, Severity: severityCritical, Slot: 2})
+		backend.boost.emitEvent(Event{Type: EventBlockMissed, Severity: severityError, Slot: 3})
+
+		require.Len(t, reporter.events, 2)
+		require.Equal(t, EventGetPayloadFailed, reporter.events[0].Type)
+		require.Equal(t, EventBlockMissed, reporter.events[1].Type)
+	})
+
+	t.Run("Panics", func(t *testing.T) {
+		r := mux.NewRouter()
+		r.Use(backend.boost.reportPanics)
+		r.HandleFunc("/panic/{slot}", func(http.ResponseWriter, *http.Request) { panic("test") })
+
+		req := httptest.NewRequest(http.MethodGet, "/panic/5", nil)
+		req.Header.Set(HeaderKeySlotUID, "uid")
+		require.PanicsWithValue(t, "test", func() { r.ServeHTTP(httptest.NewRecorder(), req) })
+
+		require.Len(t, reporter.panics, 1)
+		require.Equal(t, map[string]string{"method": http.MethodGet, "path": "/panic/5", "slot": "5", "slotUID": "uid"}, reporter.panics[0])
+	})
+
+	t.Run("Panics of relay requests", func(t *testing.T) {
+		relay := backend.relays[0].RelayEntry
+		require.PanicsWithValue(t, "test", func() {
+			defer backend.boost.reportPanic(relayTaskTags("getHeader", 5, "uid", relay))
+			panic("test")
+		})
+
+		require.Len(t, reporter.panics, 2)
+		require.Equal(t, map[string]string{"task": "getHeader", "slot": "5", "slotUID": "uid", "relay": relay.GetURI("")}, reporter.panics[1])
+	})
+}
+
+func TestSentryReporter(t *testing.T) {
+	envelopes := make(chan string, 2)
+	sentryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		envelopes <- string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sentryServer.Close()
+
+	dsn := strings.Replace(sentryServer.URL, "http://", "http://public@", 1) + "/1"
+	reporter, err := NewSentryReporter(dsn, "test")
+	require.NoError(t, err)
+
+	reporter.ReportEvent(Event{Type: EventGetPayloadFailed, Severity: severityCritical, Slot: 123, Error: errNoSuccessfulRelayResponse.Error()})
+	require.NoError(t, reporter.Close())
+
+	envelope := <-envelopes
+	require.Contains(t, envelope, "get_payload_failed in slot 123")
+	require.Contains(t, envelope, `"slot":"123"`)
+	require.Contains(t, envelope, `"level":"fatal"`)
+	require.Contains(t, envelope, `"environment":"test"`)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	eventStreamBufferSize        = 64
+	eventStreamKeepaliveInterval = 15 * time.Second
+)
+
+// eventBroker fans out events to all current subscribers. Slow subscribers miss events instead of blocking the auction.
+type eventBroker struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subs: make(map[chan Event]struct{})}
+}
+
+func (b *eventBroker) subscribe() chan Event {
+	ch := make(chan Event, eventStreamBufferSize)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	delete(b.subs, ch)
+	b.mu.Unlock()
+}
+
+func (b *eventBroker) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// authorizedEventsRequest checks the bearer token or basic auth password, which can also be passed as token query arg
+// since browsers' EventSource can't set headers
+func (m *BoostService) authorizedEventsRequest(req *http.Request) bool {
+	if _, ok := requestToken(req); ok {
+		return authorizedRequest(req, m.eventsToken)
+	}
+	return subtle.ConstantTimeCompare([]byte(req.URL.Query().Get("token")), []byte(m.eventsToken)) == 1
+}
+
+// handleEvents streams all auction events as Server-Sent Events
+func (m *BoostService) handleEvents(w http.ResponseWriter, req *http.Request) {
+	if !m.authorizedEventsRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+
+	// The stream is long-lived, so it must not be cut off by the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		m.log.WithError(err).Debug("could not clear write deadline of event stream")
+	}
+
+	events := m.events.subscribe()
+	defer m.events.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		m.log.WithError(err).Error("event stream not supported by response writer")
+		return
+	}
+
+	keepalive := time.NewTicker(eventStreamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				m.log.WithError(err).Error("could not encode event")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.eventsToken = "secret"
+	ts := httptest.NewServer(backend.boost.getRouter())
+	defer ts.Close()
+
+	t.Run("Disabled without token", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := backend.request(t, http.MethodGet, params.PathEvents, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + params.PathEvents + "?token=wrong") //nolint:noctx
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Streams auction events", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+params.PathEvents, nil) //nolint:noctx
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// Read events until the bid was selected
+		received := []EventType{}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			event := Event{}
+			require.NoError(t, json.Unmarshal([]byte(data), &event))
+			received = append(received, event.Type)
+			if event.Type == EventBidReceived {
+				require.Equal(t, "12345", event.Value)
+				require.Equal(t, []string{backend.relays[0].RelayEntry.GetURI("")}, event.Relays)
+			}
+			if event.Type == EventBidSelected {
+				break
+			}
+		}
+		require.Equal(t, []EventType{EventBidReceived, EventBestBidUpdated, EventBidSelected}, received)
+	})
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// EventType is the type of an auction event
+type EventType string
+
+const (
+	EventBidReceived      EventType = "bid_received"
+	EventBestBidUpdated   EventType = "best_bid_updated"
+	EventBidSelected      EventType = "bid_selected"
+	EventNoBids           EventType = "no_bids"
+	EventPayloadRequested EventType = "payload_requested"
+	EventPayloadDelivered EventType = "payload_delivered"
+	EventGetPayloadFailed EventType = "get_payload_failed"
+
+	// EventPayloadUnconfirmed is emitted if the relay data API doesn't report a payload delivery
+	EventPayloadUnconfirmed EventType = "payload_unconfirmed"
+	// EventBlockMissed is emitted if the block with the delivered payload didn't land on chain
+	EventBlockMissed EventType = "block_missed"
+)
+
+// perBid returns true for the events which are emitted for every single bid
+func (t EventType) perBid() bool {
+	return t == EventBidReceived || t == EventBestBidUpdated
+}
+
+// Event is a notification about the auction of a slot
+type Event struct {
+	Type        EventType `json:"type"`
+	Severity    string    `json:"severity,omitempty"`
+	TimestampMs int64     `json:"timestamp_ms"`
+	Slot        uint64    `json:"slot"`
+	SlotUID     string    `json:"slot_uid,omitempty"`
+	ParentHash  string    `json:"parent_hash,omitempty"`
+	Pubkey      string    `json:"pubkey,omitempty"`
+	BlockHash   string    `json:"block_hash,omitempty"`
+	Value       string    `json:"value,omitempty"`
+	Relays      []string  `json:"relays,omitempty"`
+	LatencyMs   int64     `json:"latency_ms,omitempty"`
+	Error       string    `json:"error,omitempty"`
+
+	// RelayErrors are the failure reasons by relay
+	RelayErrors map[string]string `json:"relay_errors,omitempty"`
+}
+
+// webhookNotifier POSTs events as JSON to the configured webhook URLs
+type webhookNotifier struct {
+	log    *logrus.Entry
+	urls   []string
+	client http.Client
+}
+
+func newWebhookNotifier(log *logrus.Entry, urls []string, timeout time.Duration) *webhookNotifier {
+	return &webhookNotifier{
+		log:  log.WithField("module", "webhooks"),
+		urls: urls,
+		client: http.Client{
+			Timeout:       timeout,
+			CheckRedirect: httpClientDisallowRedirects,
+		},
+	}
+}
+
+// notify sends the event to all webhooks, without blocking the caller
+func (n *webhookNotifier) notify(event Event) {
+	for _, url := range n.urls {
+		go func(url string) {
+			log := n.log.WithFields(logrus.Fields{"url": url, "event": event.Type})
+			if _, err := SendHTTPRequest(context.Background(), n.client, http.MethodPost, url, "", nil, event, nil); err != nil {
+				log.WithError(err).Warn("error calling webhook")
+				return
+			}
+			log.Debug("sent event to webhook")
+		}(url)
+	}
+}
+
+// emitEvent timestamps the event and passes it on to all event consumers. Webhooks don't receive the per-bid events,
+// the ErrorReporter only receives the high-severity events.
+func (m *BoostService) emitEvent(event Event) {
+	event.TimestampMs = time.Now().UTC().UnixMilli()
+	if m.webhooks != nil && !event.Type.perBid() {
+		m.webhooks.notify(event)
+	}
+	if event.highSeverity() {
+		m.errorReporter.ReportEvent(event)
+	}
+	m.events.publish(event)
+}
+
+// relayURIs returns the URIs (without public key) of the relays
+func relayURIs(relays []types.RelayEntry) []string {
+	ret := make([]string, len(relays))
+	for i, relay := range relays {
+		ret[i] = relay.GetURI("")
+	}
+	return ret
+}
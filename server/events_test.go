@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifications(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+
+	events := make(chan Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := Event{}
+		require.NoError(t, DecodeJSON(r.Body, &event)) //nolint:testifylint // if we fail here the test has failed
+		events <- event
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.webhooks = newWebhookNotifier(mock.TestLog, []string{webhook.URL}, time.Second)
+
+	t.Run("Bid selected", func(t *testing.T) {
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		event := <-events
+		require.Equal(t, EventBidSelected, event.Type)
+		require.Equal(t, uint64(1), event.Slot)
+		require.Equal(t, "12345", event.Value)
+		require.Equal(t, []string{backend.relays[0].RelayEntry.GetURI("")}, event.Relays)
+		require.Positive(t, event.TimestampMs)
+	})
+
+	t.Run("No bids", func(t *testing.T) {
+		// The only bid is rejected because of the parent hash mismatch
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, phase0.Hash32{}, pubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		event := <-events
+		require.Equal(t, EventNoBids, event.Type)
+		require.Equal(t, pubkey.String(), event.Pubkey)
+	})
+}
+
+func TestEventJSON(t *testing.T) {
+	b, err := json.Marshal(Event{Type: EventNoBids, Slot: 1})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"no_bids","timestamp_ms":0,"slot":1}`, string(b))
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// EventFeeRecipientMismatch is emitted if the fee recipient of the proposer received less than the value of the bid
+const EventFeeRecipientMismatch EventType = "fee_recipient_mismatch"
+
+var (
+	errExecutionRPC    = errors.New("execution node rpc error")
+	errBlockNotFound   = errors.New("block not found on the execution node")
+	errBlockNotOnChain = errors.New("another block is on chain")
+)
+
+var feeRecipientChecked = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "fee_recipient_checks_total",
+	Help:      "Number of checked proposer payments of delivered payloads, by result",
+}, []string{"relay", "result"})
+
+func init() {
+	metricsRegistry.MustRegister(feeRecipientChecked)
+}
+
+// executionRPCRequest is a JSON-RPC request to the execution node
+type executionRPCRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+// executionRPCResponse is a JSON-RPC response of the execution node
+type executionRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// checkFeeRecipient verifies with the execution node that the registered fee recipient of the proposer received at
+// least the bid value in the block with the delivered payload, and alerts otherwise. The payment is the balance change
+// of the fee recipient in the block, so relays or builders which underpay or misdirect the payment are detected.
+func (m *BoostService) checkFeeRecipient(log *logrus.Entry, relay types.RelayEntry, slot phase0.Slot, slotUID string, bid bidResp) {
+	defer m.reportPanic(backgroundTaskTags("checkFeeRecipient", slot, slotUID))
+	log = log.WithField("relay", relay.GetURI(""))
+	registration, ok := m.registrations.get(bid.pubkey)
+	if !ok {
+		log.Debug("proposer didn't register, not checking the proposer payment")
+		return
+	}
+	feeRecipient := registration.FeeRecipient.String()
+	log = log.WithField("feeRecipient", feeRecipient)
+	slotStart := time.Unix(int64(m.genesisTime+uint64(slot)*m.slotTimeSec), 0)
+	time.Sleep(time.Until(slotStart.Add(inclusionCheckSlots * time.Duration(m.slotTimeSec) * time.Second)))
+
+	for attempt := 1; attempt <= inclusionCheckAttempts; attempt++ {
+		received, err := m.proposerPayment(feeRecipient, bid.bidInfo.blockNumber, bid.bidInfo.blockHash)
+		if errors.Is(err, errBlockNotOnChain) {
+			log.Info("block with delivered payload is not on chain, not checking the proposer payment")
+			return
+		}
+		if err != nil {
+			log.WithError(err).WithField("attempt", attempt).Warn("could not query proposer payment from execution node")
+			time.Sleep(time.Duration(m.slotTimeSec) * time.Second)
+			continue
+		}
+
+		log = log.WithFields(logrus.Fields{
+			"value":         bid.bidInfo.value.Dec(),
+			"receivedValue": received.String(),
+		})
+		if received.Cmp(bid.bidInfo.value.ToBig()) >= 0 {
+			log.Info("fee recipient received the bid value")
+			feeRecipientChecked.WithLabelValues(relayLabel(relay), "paid").Inc()
+			return
+		}
+
+		feeRecipientChecked.WithLabelValues(relayLabel(relay), "underpaid").Inc()
+		m.metricsSink.Count("fee_recipient_mismatches", relayMetricTags(relay, map[string]string{}))
+		log.WithFields(logrus.Fields{
+			"alert":    "FEE_RECIPIENT_MISMATCH",
+			"severity": severityError,
+		}).Error("ALERT: fee recipient received less than the bid value, the payment was underpaid or misdirected")
+		m.emitEvent(Event{
+			Type:      EventFeeRecipientMismatch,
+			Severity:  severityError,
+			Slot:      uint64(slot),
+			SlotUID:   slotUID,
+			Pubkey:    bid.pubkey,
+			BlockHash: bid.bidInfo.blockHash.String(),
+			Value:     bid.bidInfo.value.Dec(),
+			Relays:    []string{relay.GetURI("")},
+			Error:     fmt.Sprintf("fee recipient %s received %s wei", feeRecipient, received),
+		})
+		return
+	}
+}
+
+// proposerPayment returns the balance change of the fee recipient in the block, errBlockNotOnChain if the block at its
+// height is another one
+func (m *BoostService) proposerPayment(feeRecipient string, blockNumber uint64, blockHash phase0.Hash32) (*big.Int, error) {
+	var block *struct {
+		Hash string `json:"hash"`
+	}
+	if err := m.executionRPC(&block, "eth_getBlockByNumber", hexutil.EncodeUint64(blockNumber), false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+	if !strings.EqualFold(block.Hash, blockHash.String()) {
+		return nil, errBlockNotOnChain
+	}
+
+	var before, after hexutil.Big
+	if err := m.executionRPC(&before, "eth_getBalance", feeRecipient, hexutil.EncodeUint64(blockNumber-1)); err != nil {
+		return nil, err
+	}
+	if err := m.executionRPC(&after, "eth_getBalance", feeRecipient, hexutil.EncodeUint64(blockNumber)); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(after.ToInt(), before.ToInt()), nil
+}
+
+// executionRPC calls the method of the execution node and decodes the result
+func (m *BoostService) executionRPC(result any, method string, params ...any) error {
+	req := executionRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
+	resp := new(executionRPCResponse)
+	_, err := SendHTTPRequest(context.Background(), m.currentConfig().httpClientGetHeader, http.MethodPost, m.executionNodeURL.String(), "", nil, req, resp)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%w: %s: %s", errExecutionRPC, method, resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
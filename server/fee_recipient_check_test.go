@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFeeRecipient(t *testing.T) {
+	blockHash := mock.HexToHash("0x534809bd2b6832edff8d8ce4cb0e50068804fd1ef432c8362ad708a74fdc0e46")
+	feeRecipient := "0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941"
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	// newExecutionNode returns an execution node with the block hash at height 10, and the fee recipient balances before
+	// and after it
+	newExecutionNode := func(t *testing.T, onChainBlockHash string, balanceBefore, balanceAfter uint64) *url.URL {
+		t.Helper()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req := new(executionRPCRequest)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+			switch req.Method {
+			case "eth_getBlockByNumber":
+				require.Equal(t, "0xa", req.Params[0])
+				_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"number":"0xa","hash":"%s"}}`, onChainBlockHash)
+			case "eth_getBalance":
+				require.Equal(t, feeRecipient, req.Params[0])
+				balance := balanceAfter
+				if req.Params[1] == "0x9" {
+					balance = balanceBefore
+				}
+				_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%x"}`, balance)
+			default:
+				t.Fatalf("unexpected method %s", req.Method)
+			}
+		}))
+		t.Cleanup(ts.Close)
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		return u
+	}
+
+	bid := bidResp{
+		pubkey:  pubkey,
+		bidInfo: bidInfo{blockHash: blockHash, blockNumber: 10, value: uint256.NewInt(1000)},
+	}
+	registration := []builderApiV1.SignedValidatorRegistration{{
+		Message: &builderApiV1.ValidatorRegistration{
+			FeeRecipient: mock.HexToAddress(feeRecipient),
+			Pubkey:       mock.HexToPubkey(pubkey),
+		},
+	}}
+
+	for _, tc := range []struct {
+		name             string
+		onChainBlockHash string
+		balanceAfter     uint64
+		mismatch         bool
+	}{
+		{"Paid", blockHash.String(), 6000, false},
+		{"Underpaid", blockHash.String(), 5999, true},
+		{"Other block on chain", nilHash.String(), 5000, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := newTestBackend(t, 1, time.Second)
+			backend.boost.executionNodeURL = newExecutionNode(t, tc.onChainBlockHash, 5000, tc.balanceAfter)
+			backend.boost.registrations.record(registration)
+			relay := backend.relays[0].RelayEntry
+			events := backend.boost.events.subscribe()
+
+			backend.boost.checkFeeRecipient(mock.TestLog, relay, 1, "", bid)
+
+			if tc.mismatch {
+				event := <-events
+				require.Equal(t, EventFeeRecipientMismatch, event.Type)
+				require.Equal(t, "1000", event.Value)
+				require.Equal(t, []string{relay.GetURI("")}, event.Relays)
+			} else {
+				require.Empty(t, events)
+			}
+		})
+	}
+
+	t.Run("Unregistered proposer", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.executionNodeURL = &url.URL{Scheme: "http", Host: "localhost:1"}
+		events := backend.boost.events.subscribe()
+		backend.boost.checkFeeRecipient(mock.TestLog, backend.relays[0].RelayEntry, 1, "", bid)
+		require.Empty(t, events)
+	})
+}
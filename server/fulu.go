@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+
+	fuluApi "github.com/attestantio/go-builder-client/api/fulu"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// slotsPerEpoch is the SLOTS_PER_EPOCH of all supported networks
+	slotsPerEpoch = 32
+	// cellsPerExtBlob is the CELLS_PER_EXT_BLOB of PeerDAS: since Fulu the blobs bundle has a proof for every cell of
+	// the extended blob instead of one proof per blob
+	cellsPerExtBlob = 128
+)
+
+// fuluSignedBlindedBeaconBlock is a signed blinded beacon block of the Fulu fork. Its contents are the same as in
+// Electra, only the payload differs: the blobs bundle of the relay has cell proofs.
+type fuluSignedBlindedBeaconBlock struct {
+	*eth2ApiV1Electra.SignedBlindedBeaconBlock
+}
+
+// isFulu returns true if the Electra blinded block of the slot is a Fulu block. The consensus version sent by the beacon
+// node decides, or the Fulu fork epoch without it.
+func (m *BoostService) isFulu(slot phase0.Slot, consensusVersion string) bool {
+	switch consensusVersion {
+	case spec.DataVersionFulu.String():
+		return true
+	case spec.DataVersionElectra.String():
+		return false
+	}
+	return m.fuluForkEpoch != nil && uint64(slot)/slotsPerEpoch >= uint64(*m.fuluForkEpoch)
+}
+
+// processFuluPayload requests the payload of a Fulu block (execution payload and blobs bundle with cell proofs) from
+// the relays
+func processFuluPayload(ctx context.Context, m *BoostService, log *logrus.Entry, ua UserAgent, blindedBlock *eth2ApiV1Electra.SignedBlindedBeaconBlock) (deliveredPayload, bidResp) {
+	return processPayload(ctx, m, log, ua, &fuluSignedBlindedBeaconBlock{blindedBlock})
+}
+
+// verifyCellProofs checks that the blobs bundle of a Fulu payload is valid: one blob per commitment of the signed
+// blinded block and cellsPerExtBlob proofs per blob
+func verifyCellProofs(log *logrus.Entry, blobs *fuluApi.BlobsBundle, commitments []deneb.KZGCommitment) error {
+	if len(commitments) != len(blobs.Blobs) || len(commitments) != len(blobs.Commitments) || len(commitments)*cellsPerExtBlob != len(blobs.Proofs) {
+		log.WithFields(logrus.Fields{
+			"requestBlobCommitments":  len(commitments),
+			"responseBlobs":           len(blobs.Blobs),
+			"responseBlobCommitments": len(blobs.Commitments),
+			"responseCellProofs":      len(blobs.Proofs),
+		}).Error("different lengths for blobs/commitments/cell proofs")
+		return errInvalidKZGLength
+	}
+
+	for i, commitment := range commitments {
+		if commitment != blobs.Commitments[i] {
+			log.WithFields(logrus.Fields{
+				"index":                  i,
+				"requestBlobCommitment":  commitment.String(),
+				"responseBlobCommitment": blobs.Commitments[i].String(),
+			}).Error("requestBlobCommitment does not equal responseBlobCommitment")
+			return errInvalidKZG
+		}
+	}
+	return nil
+}
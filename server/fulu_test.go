@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiFulu "github.com/attestantio/go-builder-client/api/fulu"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFulu(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	require.False(t, backend.boost.isFulu(64, ""))
+	require.True(t, backend.boost.isFulu(64, "fulu"))
+
+	epoch := phase0.Epoch(2)
+	backend.boost.fuluForkEpoch = &epoch
+	require.False(t, backend.boost.isFulu(63, ""))
+	require.True(t, backend.boost.isFulu(64, ""))
+	// The consensus version of the beacon node is preferred
+	require.False(t, backend.boost.isFulu(64, "electra"))
+}
+
+func TestVerifyCellProofs(t *testing.T) {
+	commitments := []deneb.KZGCommitment{{0x01}, {0x02}}
+	bundle := func(numProofs int) *builderApiFulu.BlobsBundle {
+		return &builderApiFulu.BlobsBundle{
+			Commitments: []deneb.KZGCommitment{{0x01}, {0x02}},
+			Proofs:      make([]deneb.KZGProof, numProofs),
+			Blobs:       make([]deneb.Blob, 2),
+		}
+	}
+	require.NoError(t, verifyCellProofs(mock.TestLog, bundle(2*cellsPerExtBlob), commitments))
+
+	// One proof per blob, as before Fulu
+	require.ErrorIs(t, verifyCellProofs(mock.TestLog, bundle(2), commitments), errInvalidKZGLength)
+
+	blobs := bundle(2 * cellsPerExtBlob)
+	blobs.Commitments[1] = deneb.KZGCommitment{0x03}
+	require.ErrorIs(t, verifyCellProofs(mock.TestLog, blobs, commitments), errInvalidKZG)
+}
+
+func TestGetPayloadFulu(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-electra.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+	body, err := json.Marshal(signedBlindedBeaconBlock)
+	require.NoError(t, err)
+
+	electraResponse := blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+	fuluResponse := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionFulu,
+		Fulu: &builderApiFulu.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: electraResponse.Electra.ExecutionPayload,
+			BlobsBundle: &builderApiFulu.BlobsBundle{
+				Commitments: electraResponse.Electra.BlobsBundle.Commitments,
+				Proofs:      make([]deneb.KZGProof, len(electraResponse.Electra.BlobsBundle.Commitments)*cellsPerExtBlob),
+				Blobs:       electraResponse.Electra.BlobsBundle.Blobs,
+			},
+		},
+	}
+
+	getPayload := func(t *testing.T, backend *testBackend, consensusVersion string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, params.PathGetPayload, bytes.NewReader(body))
+		require.NoError(t, err)
+		if consensusVersion != "" {
+			req.Header.Set(HeaderEthConsensusVersion, consensusVersion)
+		}
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Fulu fork epoch", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		epoch := phase0.Epoch(uint64(signedBlindedBeaconBlock.Message.Slot) / slotsPerEpoch)
+		backend.boost.fuluForkEpoch = &epoch
+		var relayConsensusVersion string
+		backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, req *http.Request) {
+			relayConsensusVersion = req.Header.Get(HeaderEthConsensusVersion)
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(fuluResponse))
+		})
+
+		rr := getPayload(t, backend, "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, "fulu", rr.Header().Get(HeaderEthConsensusVersion))
+		require.Equal(t, "fulu", relayConsensusVersion)
+		resp := new(builderApi.VersionedSubmitBlindedBlockResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, spec.DataVersionFulu, resp.Version)
+		require.Equal(t, blockHash(signedBlindedBeaconBlock), resp.Fulu.ExecutionPayload.BlockHash)
+	})
+
+	t.Run("Consensus version of the beacon node", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = fuluResponse
+		rr := getPayload(t, backend, "fulu")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Electra payload for a Fulu block", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = electraResponse
+		rr := getPayload(t, backend, "fulu")
+		require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Electra block before the fork", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		epoch := phase0.Epoch(uint64(signedBlindedBeaconBlock.Message.Slot)/slotsPerEpoch + 1)
+		backend.boost.fuluForkEpoch = &epoch
+		backend.relays[0].GetPayloadResponse = electraResponse
+		rr := getPayload(t, backend, "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, "electra", rr.Header().Get(HeaderEthConsensusVersion))
+	})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// Gas limit checks decide what happens to bids whose gas limit deviates from the registered gas limit of the validator
+const (
+	GasLimitCheckReject = "reject" // the bid is ignored
+	GasLimitCheckWarn   = "warn"   // the bid is used with a warning
+	GasLimitCheckOff    = "off"    // the gas limit isn't checked
+)
+
+// GasLimitChecks are the names of all gas limit checks
+var GasLimitChecks = []string{GasLimitCheckReject, GasLimitCheckWarn, GasLimitCheckOff}
+
+// gasLimitAdjustmentQuotient bounds the gas limit change per block to 1/1024 of the gas limit of the parent block
+const gasLimitAdjustmentQuotient = 1024
+
+// gasLimitMismatch returns why the gas limit of a bid doesn't match the registered gas limit of the validator, empty if
+// it matches or the validator didn't register. A block can only move its gas limit by 1/1024 towards the registered
+// one, so bids within that adjustment match.
+func (m *BoostService) gasLimitMismatch(pubkey string, gasLimit uint64) string {
+	if m.gasLimitCheck == GasLimitCheckOff {
+		return ""
+	}
+	registration, ok := m.registrations.get(pubkey)
+	if !ok || registration.GasLimit == 0 {
+		return ""
+	}
+	registered := registration.GasLimit
+	allowed := registered / gasLimitAdjustmentQuotient
+	if gasLimit+allowed >= registered && gasLimit <= registered+allowed {
+		return ""
+	}
+	return fmt.Sprintf("gas limit %d deviates from the registered gas limit %d by more than %d", gasLimit, registered, allowed)
+}
+
+// bidGasLimit returns the gas limit of the execution payload header of a bid, parseBidInfo checked the header exists
+func bidGasLimit(bid *builderSpec.VersionedSignedBuilderBid) uint64 {
+	switch bid.Version {
+	case spec.DataVersionBellatrix:
+		return bid.Bellatrix.Message.Header.GasLimit
+	case spec.DataVersionCapella:
+		return bid.Capella.Message.Header.GasLimit
+	case spec.DataVersionDeneb:
+		return bid.Deneb.Message.Header.GasLimit
+	case spec.DataVersionElectra:
+		return bid.Electra.Message.Header.GasLimit
+	case spec.DataVersionFulu:
+		return bid.Fulu.Message.Header.GasLimit
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
+		return 0
+	}
+	return 0
+}
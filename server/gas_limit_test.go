@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasLimitMismatch(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	registration := func(gasLimit uint64) []builderApiV1.SignedValidatorRegistration {
+		return []builderApiV1.SignedValidatorRegistration{{
+			Message: &builderApiV1.ValidatorRegistration{
+				FeeRecipient: mock.HexToAddress("0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941"),
+				GasLimit:     gasLimit,
+				Timestamp:    time.Unix(1234356, 0),
+				Pubkey:       mock.HexToPubkey(pubkey),
+			},
+		}}
+	}
+
+	t.Run("Allowed adjustment", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.Empty(t, backend.boost.gasLimitMismatch(pubkey, 1), "not registered")
+
+		backend.boost.registrations.record(registration(30_720_000))
+		require.Empty(t, backend.boost.gasLimitMismatch(pubkey, 30_720_000))
+		require.Empty(t, backend.boost.gasLimitMismatch(pubkey, 30_690_000))
+		require.Empty(t, backend.boost.gasLimitMismatch(pubkey, 30_750_000))
+		require.NotEmpty(t, backend.boost.gasLimitMismatch(pubkey, 30_689_999))
+		require.NotEmpty(t, backend.boost.gasLimitMismatch(pubkey, 30_750_001))
+
+		// The latest registration counts
+		backend.boost.registrations.record(registration(36_000_000))
+		require.NotEmpty(t, backend.boost.gasLimitMismatch(pubkey, 30_720_000))
+
+		backend.boost.gasLimitCheck = GasLimitCheckOff
+		require.Empty(t, backend.boost.gasLimitMismatch(pubkey, 30_720_000))
+	})
+
+	t.Run("Unknown check", func(t *testing.T) {
+		_, err := NewBoostService(BoostServiceOpts{
+			Log:                   mock.TestLog,
+			Relays:                []types.RelayEntry{mock.NewRelay(t).RelayEntry},
+			GenesisForkVersionHex: "0x00000000",
+			GasLimitCheck:         "ignore",
+		})
+		require.ErrorIs(t, err, errUnknownGasLimitCheck)
+	})
+
+	// Relay 0 bids higher, but ignores the registered gas limit
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	getHeader := func(check string) uint64 {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.gasLimitCheck = check
+		backend.boost.signatureCheck = types.SignatureCheckSkip
+		for i, bid := range []struct {
+			value     uint64
+			blockHash string
+			gasLimit  uint64
+		}{
+			{20000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8", 30_000_000},
+			{12345, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", 36_000_000},
+		} {
+			resp := backend.relays[i].MakeGetHeaderResponse(
+				bid.value,
+				bid.blockHash,
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				pubkey,
+				spec.DataVersionDeneb,
+			)
+			resp.Deneb.Message.Header.GasLimit = bid.gasLimit
+			backend.relays[i].GetHeaderResponse = resp
+		}
+
+		rr := backend.request(t, http.MethodPost, "/eth/v1/builder/validators", registration(36_000_000))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		return value.Uint64()
+	}
+
+	t.Run("Rejects mismatching bids", func(t *testing.T) {
+		require.Equal(t, uint64(12345), getHeader(GasLimitCheckReject))
+	})
+
+	t.Run("Warns about mismatching bids", func(t *testing.T) {
+		require.Equal(t, uint64(20000), getHeader(GasLimitCheckWarn))
+	})
+}
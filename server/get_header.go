@@ -1,22 +1,35 @@
 package server
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"math/big"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
-	"github.com/flashbots/mev-boost/config"
 	"github.com/flashbots/mev-boost/server/types"
 	"github.com/google/uuid"
+	"github.com/holiman/uint256"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// relaySignatureCheck returns the signature check of the relay bids, see types.SignatureChecks
+func (m *BoostService) relaySignatureCheck(relay types.RelayEntry) string {
+	if relay.SignatureCheck != "" {
+		return relay.SignatureCheck
+	}
+	return m.signatureCheck
+}
+
 // getHeader requests a bid from each relay and returns the most profitable one
-func (m *BoostService) getHeader(log *logrus.Entry, ua UserAgent, slot phase0.Slot, pubkey, parentHashHex string) (bidResp, error) {
+func (m *BoostService) getHeader(ctx context.Context, log *logrus.Entry, ua UserAgent, slot phase0.Slot, pubkey, parentHashHex string) (bidResp, error) {
 	// Ensure arguments are valid
 	if len(pubkey) != 98 {
 		return bidResp{}, errInvalidPubkey
@@ -34,22 +47,54 @@ func (m *BoostService) getHeader(log *logrus.Entry, ua UserAgent, slot phase0.Sl
 	slotUID := m.slotUID.uid
 	m.slotUIDLock.Unlock()
 	log = log.WithField("slotUID", slotUID)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("slotUID", slotUID.String()))
 
 	// Log how late into the slot the request starts
-	slotStartTimestamp := m.genesisTime + uint64(slot)*config.SlotTimeSec
+	slotStartTimestamp := m.genesisTime + uint64(slot)*m.slotTimeSec
 	msIntoSlot := uint64(time.Now().UTC().UnixMilli()) - slotStartTimestamp*1000
 	log.WithFields(logrus.Fields{
 		"genesisTime": m.genesisTime,
-		"slotTimeSec": config.SlotTimeSec,
+		"slotTimeSec": m.slotTimeSec,
 		"msIntoSlot":  msIntoSlot,
 	}).Infof("getHeader request start - %d milliseconds into slot %d", msIntoSlot, slot)
 
+	// Don't start an auction which can't finish in the proposer's time budget. The deadlines need the genesis time.
+	slotStart := time.UnixMilli(int64(slotStartTimestamp * 1000))
+	if m.getHeaderMaxIntoSlot > 0 && m.genesisTime > 0 && time.Since(slotStart) > m.getHeaderMaxIntoSlot {
+		return bidResp{}, errGetHeaderTooLate
+	}
+
+	// Bids for a slot which already ended, or which is more than a slot ahead of the local clock, can't be proposed
+	slotDuration := time.Duration(m.slotTimeSec) * time.Second
+	if m.genesisTime > 0 && time.Since(slotStart) >= slotDuration {
+		return bidResp{}, errGetHeaderPastSlot
+	}
+	if m.genesisTime > 0 && time.Until(slotStart) > slotDuration {
+		return bidResp{}, errGetHeaderFutureSlot
+	}
+
 	// Add request headers
 	headers := map[string]string{
 		HeaderKeySlotUID:      slotUID.String(),
 		HeaderStartTimeUnixMS: fmt.Sprintf("%d", time.Now().UTC().UnixMilli()),
 	}
 
+	// Validators with a route only query the relays of their route
+	cfg := m.currentConfig().forValidator(pubkey)
+	minBid := m.minBid(cfg)
+	if minBid != cfg.relayMinBid {
+		log.WithField("minBid", minBid.String()).Debug("using min-bid relative to recent bids")
+	}
+
+	// The bid selector decides which bid wins
+	selector := m.auctionBidSelector(log, cfg.bidPlugin)
+
+	// With constraints for the slot, only the bids proving their inclusion are usable
+	constraints := m.constraints.get(slot)
+	if len(constraints) > 0 {
+		log = log.WithField("numConstraints", len(constraints))
+	}
+
 	var (
 		mu sync.Mutex
 		wg sync.WaitGroup
@@ -57,135 +102,587 @@ func (m *BoostService) getHeader(log *logrus.Entry, ua UserAgent, slot phase0.Sl
 		// The final response, containing the highest bid (if any)
 		result = bidResp{}
 
-		// Relays that sent the bid for a specific blockHash
-		relays = make(map[BlockHashHex][]types.RelayEntry)
-	)
+		// Bids by blockHash, with the relays that sent them
+		candidates = make(map[BlockHashHex]*bidCandidate)
 
-	// Request a bid from each relay
-	for _, relay := range m.relays {
-		wg.Add(1)
-		go func(relay types.RelayEntry) {
-			defer wg.Done()
+		// All bids received, for the bid audit log
+		auditRecords = make([]*BidAuditRecord, 0, len(cfg.relays))
 
-			// Build the request URL
-			url := relay.GetURI(fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHashHex, pubkey))
-			log := log.WithField("url", url)
+		// The relays which are queried, for the relay scores
+		queried = make([]types.RelayEntry, 0, len(cfg.relays))
 
-			// Send the get bid request to the relay
-			bid := new(builderSpec.VersionedSignedBuilderBid)
-			code, err := SendHTTPRequest(context.Background(), m.httpClientGetHeader, http.MethodGet, url, ua, headers, nil, bid)
-			if err != nil {
-				log.WithError(err).Warn("error making request to relay")
-				return
-			}
-			if code == http.StatusNoContent {
-				log.Debug("no-content response")
-				return
-			}
+		// The relays which answered, by relay URL
+		responded = make(map[string]bool, len(cfg.relays))
 
-			// Skip if bid is empty
-			if bid.IsEmpty() {
-				return
-			}
+		// The latest bid of each relay while polling, by relay URL
+		latest = make(map[string]*polledBid)
 
-			// Getting the bid info will check if there are missing fields in the response
-			bidInfo, err := parseBidInfo(bid)
-			if err != nil {
-				log.WithError(err).Warn("error parsing bid info")
-				return
-			}
+		// Set after the soft deadline, when the result is returned without waiting for the remaining relays
+		closed bool
+
+		// Backup relays are queried once startBackups is closed, and not at all once skipBackups is closed
+		startBackups, skipBackups = make(chan struct{}), make(chan struct{})
+		primariesDone             sync.WaitGroup
+		hasBackups                bool
+	)
 
-			// Ignore bids with an empty block
-			if bidInfo.blockHash == nilHash {
-				log.Warn("relay responded with empty block hash")
-				return
+	// Request a bid from each relay, the faster relays first if staggered
+	pollEnd := time.Now().Add(m.getHeaderPollBudget)
+	stagger := m.getHeaderStagger(cfg, cfg.relays)
+	for i, relay := range cfg.relays {
+		// Don't wait for the timeout of relays which are down
+		if m.breaker != nil && !m.breaker.allow(relay) {
+			log.WithField("relay", relay.GetURI("")).Debug("skipping relay with open circuit breaker")
+			continue
+		}
+		if m.quarantine != nil {
+			if until, ok := m.quarantine.quarantinedUntil(relay); ok {
+				log.WithFields(logrus.Fields{"relay": relay.GetURI(""), "until": until}).Debug("skipping quarantined relay")
+				continue
 			}
+		}
+		if len(constraints) > 0 && !relay.Constraints {
+			log.WithField("relay", relay.GetURI("")).Debug("skipping relay without constraints API support")
+			continue
+		}
+		if relay.Backup {
+			hasBackups = true
+		} else {
+			queried = append(queried, relay)
+			primariesDone.Add(1)
+		}
 
-			// Add some info about the bid to the logger
-			valueEth := weiBigIntToEthBigFloat(bidInfo.value.ToBig())
-			log = log.WithFields(logrus.Fields{
-				"blockNumber": bidInfo.blockNumber,
-				"blockHash":   bidInfo.blockHash.String(),
-				"txRoot":      bidInfo.txRoot.String(),
-				"value":       valueEth.Text('f', 18),
-			})
-
-			// Ensure the bid uses the correct public key
-			if relay.PublicKey.String() != bidInfo.pubkey.String() {
-				log.Errorf("bid pubkey mismatch. expected: %s - got: %s", relay.PublicKey.String(), bidInfo.pubkey.String())
-				return
+		wg.Add(1)
+		go func(relay types.RelayEntry, relayOrder int) {
+			defer m.reportPanic(relayTaskTags("getHeader", slot, slotUID.String(), relay))
+			defer wg.Done()
+			if relay.Backup {
+				select {
+				case <-startBackups:
+				case <-skipBackups:
+					return
+				}
+				mu.Lock()
+				if closed {
+					mu.Unlock()
+					return
+				}
+				queried = append(queried, relay)
+				mu.Unlock()
+			} else {
+				defer primariesDone.Done()
+				if delay := stagger[relay.String()]; delay > 0 {
+					timer := time.NewTimer(delay)
+					defer timer.Stop()
+					select {
+					case <-timer.C:
+					case <-ctx.Done():
+						return
+					}
+				}
 			}
 
-			// Verify the relay signature in the relay response
-			if !config.SkipRelaySignatureCheck {
-				ok, err := checkRelaySignature(bid, m.builderSigningDomain, relay.PublicKey)
+			// Poll the relay until the poll budget is over, if enabled, stopping at the first failed request
+			failed := false
+			fetch := func() {
+				// A relay which doesn't serve its earlier bid anymore while polling withdraws it
+				withdraw := func(polled *polledBid) {
+					if !m.pollGetHeader() {
+						return
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					if !closed {
+						m.replacePolledBid(&result, candidates, latest, relay, selector, polled)
+					}
+				}
+
+				// Build the request URL, the relays prove the inclusion of the constraints if there are any
+				path := "/eth/v1/builder/header"
+				if len(constraints) > 0 {
+					path = "/eth/v1/builder/header_with_proofs"
+				}
+				url := relay.GetURI(fmt.Sprintf("%s/%d/%s/%s", path, slot, parentHashHex, pubkey))
+				log := log.WithField("url", url)
+
+				relayCtx, span := startSpan(ctx, "getHeader.relay", trace.WithAttributes(attribute.String("relay", relay.String())))
+				defer span.End()
+
+				// Send the get bid request to the relay
+				relayCtx, timings := withRequestTimings(relayCtx)
+				requestStart := time.Now()
+				bid := new(builderSpec.VersionedSignedBuilderBid)
+				withProofs := &bidWithProofs{bid: bid}
+				client := m.getHeaderClient(cfg, relay)
+				var code int
+				var err error
+				if len(constraints) > 0 {
+					// Bids with proofs are only JSON encoded
+					code, err = SendHTTPRequest(relayCtx, client, http.MethodGet, url, ua, headers, nil, withProofs)
+				} else {
+					code, err = m.sendRelayRequest(relayCtx, client, relay, http.MethodGet, url, ua, headers, nil, bid, retryPolicy{}, log)
+				}
+				m.observeRequestTimings(relay, "getHeader", timings)
+				m.recordRelayRequest(relay, "getHeader", requestStart, code, err)
 				if err != nil {
-					log.WithError(err).Error("error verifying relay signature")
+					// A timed out request counts with the timeout, so the timeout of a relay slowing down grows
+					if m.adaptiveTimeouts != nil && client.Timeout > 0 && time.Since(requestStart) >= client.Timeout &&
+						classifyRelayError(code, err) == relayErrorTimeout {
+						m.adaptiveTimeouts.record(relay, client.Timeout)
+					}
+					setSpanError(span, err)
+					log.WithError(err).Warn("error making request to relay")
+					failed = true
 					return
 				}
-				if !ok {
-					log.Error("failed to verify relay signature")
+				if m.adaptiveTimeouts != nil {
+					m.adaptiveTimeouts.record(relay, time.Since(requestStart))
+				}
+				mu.Lock()
+				responded[relay.String()] = true
+				mu.Unlock()
+				if code == http.StatusNoContent {
+					log.Debug("no-content response")
+					withdraw(new(polledBid))
 					return
 				}
-			}
 
-			// Verify response coherence with proposer's input data
-			if bidInfo.parentHash.String() != parentHashHex {
-				log.WithFields(logrus.Fields{
-					"originalParentHash": parentHashHex,
-					"responseParentHash": bidInfo.parentHash.String(),
-				}).Error("proposer and relay parent hashes are not the same")
-				return
-			}
+				// Skip if bid is empty
+				if bid.IsEmpty() {
+					withdraw(new(polledBid))
+					return
+				}
 
-			// Ignore bids with 0 value
-			isZeroValue := bidInfo.value.IsZero()
-			isEmptyListTxRoot := bidInfo.txRoot.String() == "0x7ffe241ea60187fdb0187bfa22de35d1f9bed7ab061d9401fd47e34a54fbede1"
-			if isZeroValue || isEmptyListTxRoot {
-				log.Warn("ignoring bid with 0 value")
-				return
-			}
+				// Getting the bid info will check if there are missing fields in the response
+				bidInfo, err := parseBidInfo(bid)
+				if err != nil {
+					m.recordRelayError(relay, "getHeader", relayErrorSchema)
+					log.WithError(err).Warn("error parsing bid info")
+					return
+				}
+				m.relayCapabilities.record(relay, bid.Version)
+				if !m.legacyForks && isLegacyFork(bid.Version.String()) {
+					m.recordRelayError(relay, "getHeader", relayErrorSchema)
+					log.WithField("version", bid.Version.String()).Warn("ignoring bid of a pre-Deneb fork, legacy forks are disabled")
+					return
+				}
+				if m.pollGetHeader() {
+					mu.Lock()
+					same := samePolledBid(latest, relay, bidInfo)
+					mu.Unlock()
+					if same {
+						log.Debug("same bid as the previous poll")
+						return
+					}
+				}
+
+				// Record the bid in the audit log, along with the reason it was rejected (if any)
+				receivedAt := time.Now().UTC()
+				audit := &BidAuditRecord{
+					Slot:              uint64(slot),
+					SlotUID:           slotUID.String(),
+					Relay:             relay.GetURI(""),
+					Value:             bidInfo.value.Dec(),
+					BlockHash:         bidInfo.blockHash.String(),
+					ParentHash:        bidInfo.parentHash.String(),
+					BlockNumber:       bidInfo.blockNumber,
+					ReceivedAtMs:      receivedAt.UnixMilli(),
+					MsIntoSlot:        receivedAt.UnixMilli() - int64(slotStartTimestamp*1000),
+					RequestDurationMs: receivedAt.Sub(requestStart).Milliseconds(),
+				}
+				defer func() {
+					mu.Lock()
+					if !closed {
+						auditRecords = append(auditRecords, audit)
+					}
+					mu.Unlock()
+				}()
 
-			log.Debug("bid received")
+				// Ignore bids with an empty block
+				if bidInfo.blockHash == nilHash {
+					audit.RejectionReason = bidRejectedEmptyBlockHash
+					log.Warn("relay responded with empty block hash")
+					return
+				}
 
-			// Skip if value is lower than the minimum bid
-			if bidInfo.value.CmpBig(m.relayMinBid.BigInt()) == -1 {
-				log.Debug("ignoring bid below min-bid value")
-				return
-			}
+				// Add some info about the bid to the logger
+				valueEth := weiBigIntToEthBigFloat(bidInfo.value.ToBig())
+				log = log.WithFields(logrus.Fields{
+					"blockNumber": bidInfo.blockNumber,
+					"blockHash":   bidInfo.blockHash.String(),
+					"txRoot":      bidInfo.txRoot.String(),
+					"value":       valueEth.Text('f', 18),
+				})
 
-			mu.Lock()
-			defer mu.Unlock()
+				// Ensure the bid uses the correct public key
+				if relay.PublicKey.String() != bidInfo.pubkey.String() {
+					audit.RejectionReason = bidRejectedPubkeyMismatch
+					log.Errorf("bid pubkey mismatch. expected: %s - got: %s", relay.PublicKey.String(), bidInfo.pubkey.String())
+					return
+				}
 
-			// Remember which relays delivered which bids (multiple relays might deliver the top bid)
-			relays[BlockHashHex(bidInfo.blockHash.String())] = append(relays[BlockHashHex(bidInfo.blockHash.String())], relay)
+				// Verify the relay signature in the relay response
+				if signatureCheck := m.relaySignatureCheck(relay); signatureCheck != types.SignatureCheckSkip {
+					_, sigSpan := startSpan(relayCtx, "checkRelaySignature")
+					ok, err := checkRelaySignature(bid, m.builderSigningDomain, relay.PublicKey)
+					sigSpan.SetAttributes(attribute.Bool("valid", ok))
+					endSpan(sigSpan, err)
+					if err != nil || !ok {
+						m.relayStats.record(relay, relayStatsSignatureFailure)
+						m.recordRelayError(relay, "getHeader", relayErrorBadSignature)
+					}
+					switch {
+					case (err != nil || !ok) && signatureCheck == types.SignatureCheckWarn:
+						log.WithError(err).Warn("invalid relay signature, using the bid anyway")
+					case err != nil:
+						audit.RejectionReason = bidRejectedSignature
+						log.WithError(err).Error("error verifying relay signature")
+						return
+					case !ok:
+						audit.RejectionReason = bidRejectedSignature
+						log.Error("failed to verify relay signature")
+						return
+					}
+				}
+
+				// Verify response coherence with proposer's input data
+				if bidInfo.parentHash.String() != parentHashHex {
+					log.WithFields(logrus.Fields{
+						"originalParentHash": parentHashHex,
+						"responseParentHash": bidInfo.parentHash.String(),
+					}).Error("proposer and relay parent hashes are not the same")
+					audit.RejectionReason = bidRejectedParentHash
+					m.recordRelayError(relay, "getHeader", relayErrorParentHash)
+					return
+				}
 
-			// Compare the bid with already known top bid (if any)
-			if !result.response.IsEmpty() {
-				valueDiff := bidInfo.value.Cmp(result.bidInfo.value)
-				if valueDiff == -1 {
-					// The current bid is less profitable than already known one
+				// The execution payload of the slot has the timestamp of the slot start, other bids fail at the beacon node
+				if m.genesisTime > 0 && bidInfo.timestamp != slotStartTimestamp {
+					log.WithFields(logrus.Fields{
+						"expectedTimestamp": slotStartTimestamp,
+						"responseTimestamp": bidInfo.timestamp,
+					}).Error("bid timestamp doesn't match the slot")
+					audit.RejectionReason = bidRejectedTimestamp
+					m.recordRelayError(relay, "getHeader", relayErrorTimestamp)
 					return
-				} else if valueDiff == 0 {
-					// The current bid is equally profitable as already known one
-					// Use hash as tiebreaker
-					previousBidBlockHash := result.bidInfo.blockHash
-					if bidInfo.blockHash.String() >= previousBidBlockHash.String() {
+				}
+
+				// Ignore bids not proving the inclusion of the constraints
+				if len(constraints) > 0 {
+					if err := verifyInclusionProofs(bidInfo.txRoot, constraints, withProofs.proofs); err != nil {
+						audit.RejectionReason = bidRejectedConstraints
+						log.WithError(err).Warn("ignoring bid without valid inclusion proofs of the constraints")
+						withdraw(&polledBid{info: bidInfo, audit: audit})
 						return
 					}
 				}
+
+				// Ignore bids with 0 value
+				isZeroValue := bidInfo.value.IsZero()
+				isEmptyListTxRoot := bidInfo.txRoot.String() == "0x7ffe241ea60187fdb0187bfa22de35d1f9bed7ab061d9401fd47e34a54fbede1"
+				if isZeroValue || isEmptyListTxRoot {
+					audit.RejectionReason = bidRejectedZeroValue
+					log.Warn("ignoring bid with 0 value")
+					withdraw(&polledBid{info: bidInfo, audit: audit})
+					return
+				}
+
+				log.Debug("bid received")
+				m.relayStats.record(relay, relayStatsBidReceived)
+				if m.quarantine != nil {
+					m.quarantine.valid(relay)
+				}
+				m.emitEvent(Event{
+					Type:       EventBidReceived,
+					Slot:       uint64(slot),
+					SlotUID:    slotUID.String(),
+					ParentHash: parentHashHex,
+					Pubkey:     pubkey,
+					BlockHash:  bidInfo.blockHash.String(),
+					Value:      bidInfo.value.Dec(),
+					Relays:     []string{relay.GetURI("")},
+					LatencyMs:  audit.RequestDurationMs,
+				})
+				span.SetAttributes(
+					attribute.String("blockHash", bidInfo.blockHash.String()),
+					attribute.String("value", bidInfo.value.Dec()),
+				)
+
+				// Reject implausibly high bids, or warn about them
+				if anomaly := m.bidAnomaly(bidInfo.value); anomaly != "" {
+					if m.bidAnomalyAction == BidAnomalyWarn {
+						log.WithField("anomaly", anomaly).Warn("implausibly high bid value, using the bid anyway")
+					} else {
+						audit.RejectionReason = bidRejectedAnomalousValue
+						log.WithField("anomaly", anomaly).Warn("ignoring bid with implausibly high value")
+						withdraw(&polledBid{info: bidInfo, audit: audit})
+						return
+					}
+				}
+
+				// Reject bids ignoring the registered gas limit of the validator, or warn about them
+				if mismatch := m.gasLimitMismatch(pubkey, bidInfo.gasLimit); mismatch != "" {
+					if m.gasLimitCheck == GasLimitCheckWarn {
+						log.WithField("mismatch", mismatch).Warn("bid gas limit doesn't match the registration, using the bid anyway")
+					} else {
+						audit.RejectionReason = bidRejectedGasLimit
+						log.WithField("mismatch", mismatch).Warn("ignoring bid with a gas limit not matching the registration")
+						withdraw(&polledBid{info: bidInfo, audit: audit})
+						return
+					}
+				}
+
+				// Skip if value is lower than the minimum bid. The value counts for the relative min-bid of later slots anyway.
+				if m.bidValues != nil {
+					m.bidValues.record(slot, bidInfo.value.ToBig())
+				}
+				if bidInfo.value.CmpBig(minBid.BigInt()) == -1 {
+					audit.RejectionReason = bidRejectedBelowMinBid
+					m.relayStats.record(relay, relayStatsBidBelowMinBid)
+					log.Debug("ignoring bid below min-bid value")
+					withdraw(&polledBid{info: bidInfo, audit: audit})
+					return
+				}
+
+				// The operator's bid script and bid plugin might reject or score the bid
+				score := 0.0
+				if m.bidScript != nil {
+					score = m.scoreBid(log, relay, slot, bidInfo)
+					if score == 0 {
+						audit.RejectionReason = bidRejectedScript
+						log.Info("bid rejected by the bid script")
+						withdraw(&polledBid{info: bidInfo, audit: audit})
+						return
+					}
+				}
+				if cfg.bidPlugin != nil {
+					pluginScore := m.pluginScoreBid(log, cfg.bidPlugin, relay, slot, bidInfo)
+					if pluginScore == 0 {
+						audit.RejectionReason = bidRejectedPlugin
+						log.Info("bid rejected by the bid plugin")
+						withdraw(&polledBid{info: bidInfo, audit: audit})
+						return
+					}
+					score = cmp.Or(score, 1) * pluginScore
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if closed {
+					log.Warn("bid received after the getHeader soft deadline")
+					return
+				}
+
+				// The latest bid of a polled relay replaces its earlier bid
+				if m.pollGetHeader() {
+					m.replacePolledBid(&result, candidates, latest, relay, selector, &polledBid{response: *bid, info: bidInfo, audit: audit, usable: true})
+				}
+
+				// Remember which relays delivered which bids (multiple relays might deliver the top bid)
+				candidate, ok := candidates[BlockHashHex(bidInfo.blockHash.String())]
+				if !ok {
+					candidate = newBidCandidate(bidInfo)
+					candidates[BlockHashHex(bidInfo.blockHash.String())] = candidate
+				}
+				if candidate.response.IsEmpty() || bidInfo.value.Gt(candidate.info.value) {
+					candidate.info, candidate.response = bidInfo, *bid
+				}
+				candidate.add(relay, relayOrder, receivedAt.Sub(requestStart), score)
+
+				// Compare the bid with already known top bid (if any)
+				if !result.response.IsEmpty() {
+					// Compare the values of the responses, relays might deliver the same block hash with different values
+					bid, best := candidate.bid(), candidates[BlockHashHex(result.bidInfo.blockHash.String())].bid()
+					bid.Value, best.Value = bidInfo.value, result.bidInfo.value
+					if !selector.Better(bid, best) {
+						return
+					}
+				}
+
+				// Use this relay's response as mev-boost response because it's most profitable
+				log.Debug("new best bid")
+				result.response = *bid
+				result.bidInfo = bidInfo
+				result.t = time.Now()
+				m.emitEvent(Event{
+					Type:       EventBestBidUpdated,
+					Slot:       uint64(slot),
+					SlotUID:    slotUID.String(),
+					ParentHash: parentHashHex,
+					Pubkey:     pubkey,
+					BlockHash:  bidInfo.blockHash.String(),
+					Value:      bidInfo.value.Dec(),
+					Relays:     []string{relay.GetURI("")},
+					LatencyMs:  audit.RequestDurationMs,
+				})
 			}
+			fetch()
+			for m.pollGetHeader() && !failed && pollAgain(ctx.Done(), m.getHeaderPollInterval, pollEnd) {
+				mu.Lock()
+				stop := closed
+				mu.Unlock()
+				if stop {
+					return
+				}
+				fetch()
+			}
+		}(relay, i)
+	}
+
+	if hasBackups {
+		go m.scheduleBackupRelays(log, &mu, &result, &primariesDone, startBackups, skipBackups)
+	}
+
+	// Wait for all relays, or until the soft deadline and return the best bid so far
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var softDeadline <-chan time.Time
+	if m.getHeaderSoftDeadline > 0 && m.genesisTime > 0 {
+		timer := time.NewTimer(time.Until(slotStart.Add(m.getHeaderSoftDeadline)))
+		defer timer.Stop()
+		softDeadline = timer.C
+	}
+	select {
+	case <-done:
+	case <-softDeadline:
+		log.Info("getHeader soft deadline reached, not waiting for the remaining relays")
+	}
+
+	// Set the winning relays before returning, bids arriving later are ignored
+	mu.Lock()
+	closed = true
+	if best, ok := candidates[BlockHashHex(result.bidInfo.blockHash.String())]; ok {
+		result.relays = best.relays
+	}
+	result.responded = len(responded)
+	result.pubkey = pubkey
+	records, queriedRelays := auditRecords, queried
+	var ranked []*bidCandidate
+	if m.bidPolicy != nil && !result.response.IsEmpty() {
+		ranked = rankBidCandidates(candidates, selector)
+	}
+	mu.Unlock()
 
-			// Use this relay's response as mev-boost response because it's most profitable
-			log.Debug("new best bid")
-			result.response = *bid
-			result.bidInfo = bidInfo
-			result.t = time.Now()
-		}(relay)
+	// The policy service might veto or reorder the bids
+	if len(ranked) > 0 {
+		m.applyBidPolicy(ctx, log, slot, parentHashHex, &result, ranked, records)
 	}
-	wg.Wait()
 
-	// Set the winning relays before returning
-	result.relays = relays[BlockHashHex(result.bidInfo.blockHash.String())]
+	m.relayAuctions.record(queriedRelays, records)
+	m.recordBids(log, result, records)
 	return result, nil
 }
+
+// retryGetHeader returns true if the relays are asked again after a getHeader without a usable bid. The retry has to
+// start before the soft deadline and the max time into the slot (if set), both need the genesis time.
+func (m *BoostService) retryGetHeader(slot phase0.Slot) bool {
+	if m.getHeaderRetryDelay <= 0 {
+		return false
+	}
+	if m.genesisTime == 0 {
+		return true
+	}
+	slotStart := time.Unix(int64(m.genesisTime+uint64(slot)*m.slotTimeSec), 0)
+	retryAt := time.Since(slotStart) + m.getHeaderRetryDelay
+	for _, deadline := range []time.Duration{m.getHeaderSoftDeadline, m.getHeaderMaxIntoSlot} {
+		if deadline > 0 && retryAt >= deadline {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLocalBlockValue parses the local block value header (in wei)
+func parseLocalBlockValue(header string) (*uint256.Int, error) {
+	value, err := uint256.FromDecimal(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidLocalBlockValue, err)
+	}
+	return value, nil
+}
+
+// beatsLocalBlock returns true if the bid is worth at least the local block value plus the local block value delta
+func (m *BoostService) beatsLocalBlock(result bidResp, localValue *uint256.Int) bool {
+	required := new(big.Int).Add(localValue.ToBig(), m.localBlockValueDelta.BigInt())
+	return result.bidInfo.value.CmpBig(required) >= 0
+}
+
+// scheduleBackupRelays starts the backup relays if no other relay has a usable bid after the backup window (if set), or
+// once all other relays answered. Otherwise the backup relays are skipped.
+func (m *BoostService) scheduleBackupRelays(log *logrus.Entry, mu *sync.Mutex, result *bidResp, primariesDone *sync.WaitGroup, startBackups, skipBackups chan struct{}) {
+	done := make(chan struct{})
+	go func() {
+		primariesDone.Wait()
+		close(done)
+	}()
+	var window <-chan time.Time
+	if m.backupRelayWindow > 0 {
+		timer := time.NewTimer(m.backupRelayWindow)
+		defer timer.Stop()
+		window = timer.C
+	}
+	select {
+	case <-window:
+	case <-done:
+	}
+
+	mu.Lock()
+	hasBid := !result.response.IsEmpty()
+	mu.Unlock()
+	if hasBid {
+		close(skipBackups)
+		return
+	}
+	log.Info("no usable bid from the primary relays, asking the backup relays")
+	close(startBackups)
+}
+
+// recordBids marks the selected bids and passes all records to the bid audit log and bid store (if enabled)
+func (m *BoostService) recordBids(log *logrus.Entry, result bidResp, records []*BidAuditRecord) {
+	if (m.bidAuditLog == nil && m.bidStore == nil) || len(records) == 0 {
+		return
+	}
+	for _, record := range records {
+		if record.RejectionReason != "" {
+			continue
+		}
+		if !result.response.IsEmpty() && record.BlockHash == result.bidInfo.blockHash.String() {
+			record.Selected = true
+		} else {
+			record.RejectionReason = bidRejectedOutbid
+		}
+	}
+	if m.bidAuditLog != nil {
+		m.bidAuditLog.write(records)
+	}
+	if m.bidStore != nil {
+		m.bidStore.write(bidStoreWrite{log: log, bids: records})
+	}
+}
+
+// getHeaderStagger returns how long to wait before requesting a bid from each relay, by relay URL. The relays are
+// ranked by their latency, relays without latency first, and each rank waits the stagger delay longer than the
+// previous one. A relay never waits longer than half of its getHeader timeout. Backup relays are not staggered.
+func (m *BoostService) getHeaderStagger(cfg reloadableConfig, relays []types.RelayEntry) map[string]time.Duration {
+	ret := make(map[string]time.Duration, len(relays))
+	if m.getHeaderStaggerDelay <= 0 {
+		return ret
+	}
+	relays = slices.DeleteFunc(slices.Clone(relays), func(relay types.RelayEntry) bool { return relay.Backup })
+	health := m.relayHealth.snapshot(relays)
+	ranks := make([]int, len(relays))
+	for i := range ranks {
+		ranks[i] = i
+	}
+	slices.SortStableFunc(ranks, func(a, b int) int {
+		return cmp.Compare(health[a].LatencyMs, health[b].LatencyMs)
+	})
+	for rank, i := range ranks {
+		delay := time.Duration(rank) * m.getHeaderStaggerDelay
+		ret[relays[i].String()] = min(delay, m.getHeaderClient(cfg, relays[i]).Timeout/2)
+	}
+	return ret
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
 	denebApi "github.com/attestantio/go-builder-client/api/deneb"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
 	eth2ApiV1Bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
 	eth2ApiV1Capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
@@ -17,17 +19,19 @@ import (
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
-	"github.com/flashbots/mev-boost/config"
 	"github.com/flashbots/mev-boost/server/params"
 	"github.com/flashbots/mev-boost/server/types"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Payload interface {
 	*eth2ApiV1Bellatrix.SignedBlindedBeaconBlock |
 		*eth2ApiV1Capella.SignedBlindedBeaconBlock |
 		*eth2ApiV1Deneb.SignedBlindedBeaconBlock |
-		*eth2ApiV1Electra.SignedBlindedBeaconBlock
+		*eth2ApiV1Electra.SignedBlindedBeaconBlock |
+		*fuluSignedBlindedBeaconBlock
 }
 
 var (
@@ -36,11 +40,68 @@ var (
 	errInvalidBlockhash = errors.New("invalid blockhash")
 	errInvalidKZGLength = errors.New("invalid KZG commitments length")
 	errInvalidKZG       = errors.New("invalid KZG commitment")
+	errUnknownBid       = errors.New("no header with this block hash was served for the slot")
 )
 
-// processPayload requests the payload (execution payload, blobs bundle, etc) from the relays
-func processPayload[P Payload](m *BoostService, log *logrus.Entry, ua UserAgent, blindedBlock P) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp) {
+// deliveredPayload is the first valid payload from the relays, along with the relay which delivered it
+type deliveredPayload struct {
+	response *builderApi.VersionedSubmitBlindedBlockResponse // nil if no relay delivered
+	relay    types.RelayEntry
+	failures []relayFailure // why the relays failed, if no relay delivered
+	refused  error          // why the block wasn't sent to the relays
+}
+
+// processPayload requests the payload from the relays like requestPayload, unless a request for the same block was
+// already made within the payload cache ttl, or the block is refused by the strict mode or the reveal journal
+func processPayload[P Payload](ctx context.Context, m *BoostService, log *logrus.Entry, ua UserAgent, blindedBlock P) (deliveredPayload, bidResp) {
+	if m.getPayloadStrict && !m.servedBid(slot(blindedBlock), blockHash(blindedBlock)) {
+		log.WithFields(logrus.Fields{
+			"slot":      slot(blindedBlock),
+			"blockHash": blockHash(blindedBlock).String(),
+		}).Error("refusing getPayload request, the block hash was never returned by getHeader")
+		return deliveredPayload{refused: errUnknownBid}, bidResp{}
+	}
+	if err := m.checkReveal(log, slot(blindedBlock), blockHash(blindedBlock)); err != nil {
+		return deliveredPayload{refused: err}, bidResp{}
+	}
+	if m.payloadCache == nil {
+		return requestPayload(ctx, m, log, ua, blindedBlock)
+	}
+	key := bidKey(slot(blindedBlock), blockHash(blindedBlock))
+	entry, first := m.payloadCache.start(key, time.Now())
+	if !first {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return deliveredPayload{refused: ctx.Err()}, bidResp{}
+		}
+		payloadCacheHits.Inc()
+		log.WithFields(logrus.Fields{
+			"slot":      slot(blindedBlock),
+			"blockHash": blockHash(blindedBlock).String(),
+		}).Info("retried getPayload request, serving the result of the previous request")
+		return entry.result, entry.bid
+	}
+	// Finish the entry even if the request panics, the retries would wait forever otherwise
+	var result deliveredPayload
+	var bid bidResp
+	defer func() { m.payloadCache.finish(key, entry, result, bid, time.Now()) }()
+	result, bid = requestPayload(ctx, m, log, ua, blindedBlock)
+	return result, bid
+}
+
+// servedBid returns true if a getHeader request of the slot was answered with the block hash
+func (m *BoostService) servedBid(slot phase0.Slot, blockHash phase0.Hash32) bool {
+	m.bidsLock.Lock()
+	defer m.bidsLock.Unlock()
+	_, ok := m.bids[bidKey(slot, blockHash)]
+	return ok
+}
+
+// requestPayload requests the payload (execution payload, blobs bundle, etc) from the relays
+func requestPayload[P Payload](ctx context.Context, m *BoostService, log *logrus.Entry, ua UserAgent, blindedBlock P) (deliveredPayload, bidResp) {
 	var (
+		start     = time.Now()
 		slot      = slot(blindedBlock)
 		blockHash = blockHash(blindedBlock)
 	)
@@ -57,13 +118,18 @@ func processPayload[P Payload](m *BoostService, log *logrus.Entry, ua UserAgent,
 
 	// Prepare logger
 	log = prepareLogger(log, blindedBlock, ua, currentSlotUID)
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int64("slot", int64(slot)),
+		attribute.String("blockHash", blockHash.String()),
+		attribute.String("slotUID", currentSlotUID),
+	)
 
 	// Log how late into the slot the request starts
-	slotStartTimestamp := m.genesisTime + uint64(slot)*config.SlotTimeSec
+	slotStartTimestamp := m.genesisTime + uint64(slot)*m.slotTimeSec
 	msIntoSlot := uint64(time.Now().UTC().UnixMilli()) - slotStartTimestamp*1000
 	log.WithFields(logrus.Fields{
 		"genesisTime": m.genesisTime,
-		"slotTimeSec": config.SlotTimeSec,
+		"slotTimeSec": m.slotTimeSec,
 		"msIntoSlot":  msIntoSlot,
 	}).Infof("submitBlindedBlock request start - %d milliseconds into slot %d", msIntoSlot, slot)
 
@@ -76,96 +142,207 @@ func processPayload[P Payload](m *BoostService, log *logrus.Entry, ua UserAgent,
 	} else if len(originalBid.relays) == 0 {
 		log.Warn("bid found but no associated relays")
 	}
+	m.emitEvent(Event{
+		Type:      EventPayloadRequested,
+		Slot:      uint64(slot),
+		SlotUID:   currentSlotUID,
+		BlockHash: blockHash.String(),
+		Relays:    relayURIs(originalBid.relays),
+	})
 
 	// Add request headers
 	headers := map[string]string{
 		HeaderKeySlotUID:      currentSlotUID,
 		HeaderStartTimeUnixMS: fmt.Sprintf("%d", time.Now().UTC().UnixMilli()),
 	}
+	// Fulu blinded blocks can't be told apart from Electra blinded blocks by their contents
+	headers[HeaderEthConsensusVersion] = payloadVersion(blindedBlock).String()
 
 	// Prepare for requests
-	resultCh := make(chan *builderApi.VersionedSubmitBlindedBlockResponse, len(m.relays))
+	// The bid may come from the relays of a validator route, which are not among the default relays
+	cfg := m.currentConfig().withBidRelays(originalBid.relays)
+	resultCh := make(chan deliveredPayload, len(cfg.relays)+1)
 	var received atomic.Bool
-	go func() {
-		// Make sure we receive a response within the timeout
-		time.Sleep(m.httpClientGetPayload.Timeout)
-		resultCh <- nil
-	}()
 
 	// Prepare the request context, which will be cancelled after the first successful response from a relay
-	requestCtx, requestCtxCancel := context.WithCancel(context.Background())
+	requestCtx, requestCtxCancel := context.WithCancel(ctx)
 	defer requestCtxCancel()
 
-	for _, relay := range m.relays {
-		go func(relay types.RelayEntry) {
+	// Remember when and why relays failed, for the missed payload alert
+	attempts := newRelayAttempts(start)
+
+	// The relays of the bid are asked first, the other relays only after the fallback delay or once all relays of the bid
+	// failed. There is no need to send the signed block to relays which can't have the payload.
+	bidRelays := make(map[string]bool, len(originalBid.relays))
+	for _, relay := range originalBid.relays {
+		bidRelays[relay.String()] = true
+	}
+	useFallback := m.getPayloadFallbackDelay > 0 && len(bidRelays) > 0 && len(bidRelays) < len(cfg.relays)
+	fallback := make(chan struct{})
+	var bidRelaysDone sync.WaitGroup
+
+	// Each relay has its full getPayload timeout from when it is asked, so relays asked after the hedge or fallback
+	// delays aren't cut short. Without a payload, the result is empty once all relays failed or timed out.
+	var relaysDone sync.WaitGroup
+
+	// Hedged, the relays which aren't fallback relays are asked one after another, see getPayloadHedge
+	hedgedRelays := make([]types.RelayEntry, 0, len(cfg.relays))
+	for _, relay := range cfg.relays {
+		if !useFallback || bidRelays[relay.String()] {
+			hedgedRelays = append(hedgedRelays, relay)
+		}
+	}
+	ranks := m.getPayloadHedgeRanks(hedgedRelays, bidRelays)
+	hedge := newGetPayloadHedge(len(ranks))
+	if len(ranks) > 1 {
+		go hedge.run(requestCtx, m.getPayloadHedgeDelay)
+	}
+
+	for _, relay := range cfg.relays {
+		isFallback := useFallback && !bidRelays[relay.String()]
+		if !isFallback {
+			bidRelaysDone.Add(1)
+		}
+		relaysDone.Add(1)
+		go func(relay types.RelayEntry, isFallback bool) {
+			defer m.reportPanic(relayTaskTags("getPayload", slot, currentSlotUID, relay))
+			defer relaysDone.Done()
+			if isFallback {
+				select {
+				case <-fallback:
+				case <-requestCtx.Done():
+					return
+				}
+			} else {
+				defer bidRelaysDone.Done()
+			}
+			delivered := false
+			if rank, ok := ranks[relay.String()]; ok {
+				select {
+				case <-hedge.released[rank]:
+				case <-requestCtx.Done():
+					return
+				}
+				defer func() {
+					if !delivered {
+						close(hedge.failed[rank])
+					}
+				}()
+			}
+
 			url := relay.GetURI(params.PathGetPayload)
 			log := log.WithField("url", url)
 			log.Debug("calling getPayload")
 
+			relayCtx, span := startSpan(requestCtx, "getPayload.relay", trace.WithAttributes(attribute.String("relay", relay.String())))
+			defer span.End()
+
+			relayCtx, timings := withRequestTimings(relayCtx)
+			requestStart := time.Now()
+			attempts.begin(relay, requestStart)
 			responsePayload := new(builderApi.VersionedSubmitBlindedBlockResponse)
-			_, err := SendHTTPRequestWithRetries(requestCtx, m.httpClientGetPayload, http.MethodPost, url, ua, headers, blindedBlock, responsePayload, m.requestMaxRetries, log)
+			code, err := m.sendRelayRequest(relayCtx, cfg.getPayloadClient(relay), relay, http.MethodPost, url, ua, headers, blindedBlock, responsePayload, m.getPayloadRetryPolicy(relay), log)
+			m.observeRequestTimings(relay, "getPayload", timings)
 			if err != nil {
+				setSpanError(span, err)
 				if errors.Is(requestCtx.Err(), context.Canceled) {
 					// This is expected if the payload has already been received by another relay
 					log.Info("request was cancelled")
 				} else {
+					m.recordRelayRequest(relay, "getPayload", requestStart, code, err)
+					attempts.fail(relay, classifyRelayError(code, err), err)
 					log.WithError(err).Error("error making request to relay")
 				}
 				return
 			}
+			m.recordRelayRequest(relay, "getPayload", requestStart, code, nil)
 
-			if err := verifyPayload(blindedBlock, log, responsePayload); err != nil {
+			if err := verifyPayload(blindedBlock, log, responsePayload, originalBid.response, m.payloadCheck); err != nil {
+				setSpanError(span, err)
+				attempts.fail(relay, classifyPayloadError(err), err)
+				m.recordRelayError(relay, "getPayload", classifyPayloadError(err))
 				return
 			}
+			m.relayCapabilities.record(relay, responsePayload.Version)
+			if m.quarantine != nil {
+				m.quarantine.valid(relay)
+			}
 
+			delivered = true
 			requestCtxCancel()
 			if received.CompareAndSwap(false, true) {
-				resultCh <- responsePayload
+				m.relayStats.record(relay, relayStatsPayloadDelivered)
+				m.recordPayloadDelivery(log, relay, slot, blockHash)
+				if m.payloadDeliveryCheckDelay > 0 {
+					go m.checkPayloadDelivery(log, relay, slot, currentSlotUID, blockHash)
+				}
+				if m.beaconNodeURL != nil && m.genesisTime > 0 {
+					go m.checkBlockInclusion(log, relay, slot, currentSlotUID, blockHash)
+				}
+				if m.executionNodeURL != nil && !originalBid.response.IsEmpty() {
+					go m.checkFeeRecipient(log, relay, slot, currentSlotUID, originalBid)
+				}
+				m.emitEvent(Event{
+					Type:      EventPayloadDelivered,
+					Slot:      uint64(slot),
+					SlotUID:   currentSlotUID,
+					BlockHash: blockHash.String(),
+					Relays:    []string{relay.GetURI("")},
+				})
+				resultCh <- deliveredPayload{response: responsePayload, relay: relay}
 				log.Info("received payload from relay")
 			} else {
 				log.Trace("discarding response, already received a correct response")
 			}
-		}(relay)
+		}(relay, isFallback)
 	}
 
+	if useFallback {
+		go func() {
+			bidRelaysFailed := make(chan struct{})
+			go func() {
+				bidRelaysDone.Wait()
+				close(bidRelaysFailed)
+			}()
+			timer := time.NewTimer(m.getPayloadFallbackDelay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-bidRelaysFailed:
+			case <-requestCtx.Done():
+			}
+			if requestCtx.Err() != nil {
+				return
+			}
+			log.Info("no payload from the relays of the bid, asking the other relays")
+			close(fallback)
+		}()
+	}
+
+	go func() {
+		relaysDone.Wait()
+		resultCh <- deliveredPayload{}
+	}()
+
 	// Wait for the first request to complete
 	result := <-resultCh
+	if result.response == nil {
+		result.failures = attempts.failures()
+		m.alertMissedPayload(log, slot, currentSlotUID, blockHash, originalBid, result.failures)
+	}
 
 	return result, originalBid
 }
 
-// verifyPayload checks that the payload is valid
-func verifyPayload[P Payload](payload P, log *logrus.Entry, response *builderApi.VersionedSubmitBlindedBlockResponse) error {
+// verifyPayload checks that the payload is valid and, depending on the payload check, that it matches the blinded block
+// and the bid
+func verifyPayload[P Payload](payload P, log *logrus.Entry, response *builderApi.VersionedSubmitBlindedBlockResponse, bid builderSpec.VersionedSignedBuilderBid, payloadCheck string) error {
 	// Verify version
-	switch any(payload).(type) {
-	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
-		if response.Version != spec.DataVersionBellatrix {
-			log.WithFields(logrus.Fields{
-				"version": response.Version,
-			}).Error("response version was not bellatrix")
-			return errInvalidVersion
-		}
-	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
-		if response.Version != spec.DataVersionCapella {
-			log.WithFields(logrus.Fields{
-				"version": response.Version,
-			}).Error("response version was not capella")
-			return errInvalidVersion
-		}
-	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
-		if response.Version != spec.DataVersionDeneb {
-			log.WithFields(logrus.Fields{
-				"version": response.Version,
-			}).Error("response version was not deneb")
-			return errInvalidVersion
-		}
-	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
-		if response.Version != spec.DataVersionElectra {
-			log.WithFields(logrus.Fields{
-				"version": response.Version,
-			}).Error("response version was not electra")
-			return errInvalidVersion
-		}
+	if version := payloadVersion(payload); response.Version != version {
+		log.WithFields(logrus.Fields{
+			"version": response.Version,
+		}).Errorf("response version was not %s", version)
+		return errInvalidVersion
 	}
 
 	// Verify payload is not empty
@@ -175,31 +352,17 @@ func verifyPayload[P Payload](payload P, log *logrus.Entry, response *builderApi
 	}
 
 	// Verify post-conditions
-	switch block := any(payload).(type) {
-	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
-		if err := verifyBlockHash(log, payload, response.Bellatrix.BlockHash); err != nil {
-			return err
-		}
-	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
-		if err := verifyBlockHash(log, payload, response.Capella.BlockHash); err != nil {
-			return err
-		}
-	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
-		if err := verifyBlockHash(log, payload, response.Deneb.ExecutionPayload.BlockHash); err != nil {
-			return err
-		}
-		if err := verifyKZGCommitments(log, response.Deneb.BlobsBundle, block.Message.Body.BlobKZGCommitments); err != nil {
-			return err
-		}
-	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
-		if err := verifyBlockHash(log, payload, response.Electra.ExecutionPayload.BlockHash); err != nil {
-			return err
-		}
-		if err := verifyKZGCommitments(log, response.Electra.BlobsBundle, block.Message.Body.BlobKZGCommitments); err != nil {
-			return err
-		}
+	executionPayloadHash, err := response.BlockHash()
+	if err != nil {
+		return err
 	}
-	return nil
+	if err := verifyBlockHash(log, payload, executionPayloadHash); err != nil {
+		return err
+	}
+	if err := verifyBlobsBundle(log, payload, response); err != nil {
+		return err
+	}
+	return checkPayload(log, payload, response, bid, payloadCheck)
 }
 
 // verifyBlockHash checks that the block hash is correct
@@ -213,6 +376,21 @@ func verifyBlockHash[P Payload](log *logrus.Entry, payload P, executionPayloadHa
 	return nil
 }
 
+// verifyBlobsBundle checks the blobs bundle of the payload against the commitments of the blinded block, since Deneb
+func verifyBlobsBundle[P Payload](log *logrus.Entry, payload P, response *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	switch block := any(payload).(type) {
+	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock, *eth2ApiV1Capella.SignedBlindedBeaconBlock:
+		return nil
+	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
+		return verifyKZGCommitments(log, response.Deneb.BlobsBundle, block.Message.Body.BlobKZGCommitments)
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		return verifyKZGCommitments(log, response.Electra.BlobsBundle, block.Message.Body.BlobKZGCommitments)
+	case *fuluSignedBlindedBeaconBlock:
+		return verifyCellProofs(log, response.Fulu.BlobsBundle, block.Message.Body.BlobKZGCommitments)
+	}
+	return nil
+}
+
 // verifyKZGCommitments checks that blobs bundle is valid
 func verifyKZGCommitments(log *logrus.Entry, blobs *denebApi.BlobsBundle, commitments []deneb.KZGCommitment) error {
 	// Ensure that blobs are valid and matches the request
@@ -241,41 +419,30 @@ func verifyKZGCommitments(log *logrus.Entry, blobs *denebApi.BlobsBundle, commit
 
 // prepareLogger adds relevant fields to the logger
 func prepareLogger[P Payload](log *logrus.Entry, payload P, userAgent UserAgent, slotUID string) *logrus.Entry {
-	switch block := any(payload).(type) {
+	return log.WithFields(logrus.Fields{
+		"ua":         userAgent,
+		"slot":       slot(payload),
+		"blockHash":  blockHash(payload).String(),
+		"parentHash": parentHash(payload).String(),
+		"slotUID":    slotUID,
+	})
+}
+
+// payloadVersion returns the consensus version of the block, which is the version of its payload
+func payloadVersion[P Payload](payload P) spec.DataVersion {
+	switch any(payload).(type) {
 	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
-		return log.WithFields(logrus.Fields{
-			"ua":         userAgent,
-			"slot":       block.Message.Slot,
-			"blockHash":  block.Message.Body.ExecutionPayloadHeader.BlockHash.String(),
-			"parentHash": block.Message.Body.ExecutionPayloadHeader.ParentHash.String(),
-			"slotUID":    slotUID,
-		})
+		return spec.DataVersionBellatrix
 	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
-		return log.WithFields(logrus.Fields{
-			"ua":         userAgent,
-			"slot":       block.Message.Slot,
-			"blockHash":  block.Message.Body.ExecutionPayloadHeader.BlockHash.String(),
-			"parentHash": block.Message.Body.ExecutionPayloadHeader.ParentHash.String(),
-			"slotUID":    slotUID,
-		})
+		return spec.DataVersionCapella
 	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
-		return log.WithFields(logrus.Fields{
-			"ua":         userAgent,
-			"slot":       block.Message.Slot,
-			"blockHash":  block.Message.Body.ExecutionPayloadHeader.BlockHash.String(),
-			"parentHash": block.Message.Body.ExecutionPayloadHeader.ParentHash.String(),
-			"slotUID":    slotUID,
-		})
+		return spec.DataVersionDeneb
 	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
-		return log.WithFields(logrus.Fields{
-			"ua":         userAgent,
-			"slot":       block.Message.Slot,
-			"blockHash":  block.Message.Body.ExecutionPayloadHeader.BlockHash.String(),
-			"parentHash": block.Message.Body.ExecutionPayloadHeader.ParentHash.String(),
-			"slotUID":    slotUID,
-		})
+		return spec.DataVersionElectra
+	case *fuluSignedBlindedBeaconBlock:
+		return spec.DataVersionFulu
 	}
-	return nil
+	return spec.DataVersionUnknown
 }
 
 // slot returns the block's slot
@@ -289,6 +456,8 @@ func slot[P Payload](payload P) phase0.Slot {
 		return block.Message.Slot
 	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
 		return block.Message.Slot
+	case *fuluSignedBlindedBeaconBlock:
+		return block.Message.Slot
 	}
 	return 0
 }
@@ -304,6 +473,25 @@ func blockHash[P Payload](payload P) phase0.Hash32 {
 		return block.Message.Body.ExecutionPayloadHeader.BlockHash
 	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
 		return block.Message.Body.ExecutionPayloadHeader.BlockHash
+	case *fuluSignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.BlockHash
+	}
+	return nilHash
+}
+
+// parentHash returns the block's execution parent hash
+func parentHash[P Payload](payload P) phase0.Hash32 {
+	switch block := any(payload).(type) {
+	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.ParentHash
+	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.ParentHash
+	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.ParentHash
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.ParentHash
+	case *fuluSignedBlindedBeaconBlock:
+		return block.Message.Body.ExecutionPayloadHeader.ParentHash
 	}
 	return nilHash
 }
@@ -312,3 +500,20 @@ func blockHash[P Payload](payload P) phase0.Hash32 {
 func bidKey(slot phase0.Slot, blockHash phase0.Hash32) string {
 	return fmt.Sprintf("%v%v", slot, blockHash)
 }
+
+// recordPayloadDelivery stores a payload delivery in the bid store (if enabled)
+func (m *BoostService) recordPayloadDelivery(log *logrus.Entry, relay types.RelayEntry, slot phase0.Slot, blockHash phase0.Hash32) {
+	if m.bidStore == nil {
+		return
+	}
+	deliveredAt := time.Now().UTC().UnixMilli()
+	slotStartTimestamp := m.genesisTime + uint64(slot)*m.slotTimeSec
+	record := PayloadDeliveryRecord{
+		Slot:          uint64(slot),
+		BlockHash:     blockHash.String(),
+		Relay:         relay.GetURI(""),
+		DeliveredAtMs: deliveredAt,
+		MsIntoSlot:    deliveredAt - int64(slotStartTimestamp*1000),
+	}
+	m.bidStore.write(bidStoreWrite{log: log, delivery: &record})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// getPayloadHedgeRanks returns the order in which the relays are asked for the payload, by relay URL: the relays of the
+// bid first, each group from the fastest to the slowest relay and then the relays without a successful request, whose
+// latency is unknown. All relays have rank 0 if hedging is disabled.
+func (m *BoostService) getPayloadHedgeRanks(relays []types.RelayEntry, bidRelays map[string]bool) map[string]int {
+	ret := make(map[string]int, len(relays))
+	if m.getPayloadHedgeDelay <= 0 {
+		return ret
+	}
+	health := m.relayHealth.snapshot(relays)
+	order := make([]int, len(relays))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if bidA, bidB := bidRelays[relays[a].String()], bidRelays[relays[b].String()]; bidA != bidB {
+			if bidA {
+				return -1
+			}
+			return 1
+		}
+		if unknownA, unknownB := health[a].LatencyMs == 0, health[b].LatencyMs == 0; unknownA != unknownB {
+			if unknownA {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(health[a].LatencyMs, health[b].LatencyMs)
+	})
+	for rank, i := range order {
+		ret[relays[i].String()] = rank
+	}
+	return ret
+}
+
+// getPayloadHedge releases the relays of each rank: rank 0 at once, each further rank the hedge delay after the
+// previous one, or as soon as the relay of the previous rank failed
+type getPayloadHedge struct {
+	released []chan struct{} // closed when the relay of the rank may be asked
+	failed   []chan struct{} // closed when the relay of the rank failed
+}
+
+func newGetPayloadHedge(ranks int) *getPayloadHedge {
+	h := &getPayloadHedge{released: make([]chan struct{}, ranks), failed: make([]chan struct{}, ranks)}
+	for i := range ranks {
+		h.released[i] = make(chan struct{})
+		h.failed[i] = make(chan struct{})
+	}
+	if ranks > 0 {
+		close(h.released[0])
+	}
+	return h
+}
+
+// run releases the ranks one after another, until the context is done
+func (h *getPayloadHedge) run(ctx context.Context, delay time.Duration) {
+	for rank := 1; rank < len(h.released); rank++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-h.failed[rank-1]:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+		close(h.released[rank])
+	}
+}
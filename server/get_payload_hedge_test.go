@@ -0,0 +1,120 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPayloadHedge(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	// Only relay 1 delivered the bid, all relays are asked at once without hedging
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 3, time.Second)
+		backend.boost.getPayloadFallbackDelay = 0
+		backend.boost.getPayloadHedgeDelay = time.Hour
+		backend.boost.requestMaxRetries = 1
+		key := bidKey(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash)
+		backend.boost.bids[key] = bidResp{
+			response: *backend.relays[1].MakeGetHeaderResponse(
+				12345,
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				spec.DataVersionDeneb,
+			),
+			relays: []types.RelayEntry{backend.relays[1].RelayEntry},
+		}
+		for _, relay := range backend.relays {
+			relay.GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		}
+		return backend
+	}
+	requestCounts := func(backend *testBackend) []int {
+		counts := make([]int, len(backend.relays))
+		for i, relay := range backend.relays {
+			counts[i] = relay.GetRequestCount(params.PathGetPayload)
+		}
+		return counts
+	}
+
+	t.Run("The relay of the bid is asked first", func(t *testing.T) {
+		backend := setup(t)
+		ranks := backend.boost.getPayloadHedgeRanks(backend.boost.relays, map[string]bool{backend.relays[1].RelayEntry.String(): true})
+		require.Equal(t, 0, ranks[backend.relays[1].RelayEntry.String()])
+		require.Equal(t, 1, ranks[backend.relays[0].RelayEntry.String()])
+		require.Equal(t, 2, ranks[backend.relays[2].RelayEntry.String()])
+
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, []int{0, 1, 0}, requestCounts(backend))
+	})
+
+	t.Run("The next relay is asked once the previous one failed", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[1].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, []int{1, 1, 0}, requestCounts(backend))
+	})
+
+	t.Run("The next relay is asked after the delay", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.getPayloadHedgeDelay = 50 * time.Millisecond
+		backend.relays[1].ResponseDelay = 500 * time.Millisecond
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathGetPayload))
+		require.Equal(t, 0, backend.relays[2].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Relays without a successful request are asked last", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.relayHealth.record(backend.relays[2].RelayEntry, 100*time.Millisecond, nil)
+		ranks := backend.boost.getPayloadHedgeRanks(backend.boost.relays, map[string]bool{backend.relays[1].RelayEntry.String(): true})
+		require.Equal(t, 0, ranks[backend.relays[1].RelayEntry.String()])
+		require.Equal(t, 1, ranks[backend.relays[2].RelayEntry.String()])
+		require.Equal(t, 2, ranks[backend.relays[0].RelayEntry.String()])
+	})
+
+	t.Run("A relay asked after the delay has its full timeout", func(t *testing.T) {
+		// Relays 1 and 2 never answer. Relay 0 is asked after the delay and answers within its timeout of one second,
+		// but after one second since relay 1 was asked.
+		backend := setup(t)
+		backend.boost.getPayloadHedgeDelay = 300 * time.Millisecond
+		for _, relay := range []*mock.Relay{backend.relays[1], backend.relays[2]} {
+			relay.OverrideHandleGetPayload(func(_ http.ResponseWriter, req *http.Request) {
+				// The context is only cancelled on disconnects once the body is read
+				_, _ = io.Copy(io.Discard, req.Body)
+				<-req.Context().Done()
+			})
+		}
+		backend.relays[0].ResponseDelay = 800 * time.Millisecond
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, []int{1, 1, 1}, requestCounts(backend))
+	})
+
+	t.Run("Without hedging all relays are asked at once", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.getPayloadHedgeDelay = 0
+		require.Empty(t, backend.boost.getPayloadHedgeRanks(backend.boost.relays, nil))
+	})
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/builderpb"
+	"github.com/flashbots/mev-boost/server/params"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errInvalidGRPCRegistration  = errors.New("validator registration needs a 20 byte fee recipient, a 48 byte pubkey and a 96 byte signature")
+	errInvalidGetHeaderRequest  = errors.New("getHeader request needs a slot, a 32 byte parent hash and a 48 byte pubkey")
+	errInvalidGetPayloadRequest = errors.New("getPayload request needs the consensus version and the SSZ encoded signed blinded block")
+)
+
+// grpcReservedMetadata are the gRPC metadata keys which are not passed on as HTTP headers
+var grpcReservedMetadata = map[string]bool{
+	"content-type": true,
+	"te":           true,
+}
+
+// grpcBuilderServer serves the builder service of builderpb with the HTTP handlers, so both interfaces behave the
+// same. The requests are passed on SSZ encoded. Request metadata is passed on as HTTP headers, response headers are
+// returned as header metadata.
+type grpcBuilderServer struct {
+	builderpb.UnimplementedBuilderServer
+	handler http.Handler
+}
+
+func (s *grpcBuilderServer) Status(ctx context.Context, _ *builderpb.StatusRequest) (*builderpb.StatusResponse, error) {
+	if _, _, err := s.serve(ctx, http.MethodGet, params.PathStatus, nil, nil); err != nil {
+		return nil, err
+	}
+	return &builderpb.StatusResponse{}, nil
+}
+
+func (s *grpcBuilderServer) RegisterValidator(ctx context.Context, req *builderpb.RegisterValidatorRequest) (*builderpb.RegisterValidatorResponse, error) {
+	list := &builderApiV1.SignedValidatorRegistrations{Registrations: make([]*builderApiV1.SignedValidatorRegistration, 0, len(req.GetRegistrations()))}
+	for _, registration := range req.GetRegistrations() {
+		if len(registration.GetFeeRecipient()) != bellatrix.ExecutionAddressLength || len(registration.GetPubkey()) != phase0.PublicKeyLength ||
+			len(registration.GetSignature()) != phase0.SignatureLength {
+			return nil, status.Error(codes.InvalidArgument, errInvalidGRPCRegistration.Error())
+		}
+		list.Registrations = append(list.Registrations, &builderApiV1.SignedValidatorRegistration{
+			Message: &builderApiV1.ValidatorRegistration{
+				FeeRecipient: bellatrix.ExecutionAddress(registration.GetFeeRecipient()),
+				GasLimit:     registration.GetGasLimit(),
+				Timestamp:    time.Unix(int64(registration.GetTimestamp()), 0), //nolint:gosec
+				Pubkey:       phase0.BLSPubKey(registration.GetPubkey()),
+			},
+			Signature: phase0.BLSSignature(registration.GetSignature()),
+		})
+	}
+	body, err := list.MarshalSSZ()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	header := http.Header{"Content-Type": {MediaTypeSSZ}}
+	if _, _, err := s.serve(ctx, http.MethodPost, params.PathRegisterValidator, header, body); err != nil {
+		return nil, err
+	}
+	return &builderpb.RegisterValidatorResponse{}, nil
+}
+
+func (s *grpcBuilderServer) GetHeader(ctx context.Context, req *builderpb.GetHeaderRequest) (*builderpb.GetHeaderResponse, error) {
+	if req.GetSlot() == 0 || len(req.GetParentHash()) != phase0.Hash32Length || len(req.GetPubkey()) != phase0.PublicKeyLength {
+		return nil, status.Error(codes.InvalidArgument, errInvalidGetHeaderRequest.Error())
+	}
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", req.GetSlot(), phase0.Hash32(req.GetParentHash()).String(), phase0.BLSPubKey(req.GetPubkey()).String())
+	respHeader, body, err := s.serve(ctx, http.MethodGet, path, http.Header{"Accept": {MediaTypeSSZ}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &builderpb.GetHeaderResponse{
+		Version:          respHeader.Get(HeaderEthConsensusVersion),
+		Value:            respHeader.Get(HeaderKeyBidValueWei),
+		SignedBuilderBid: body,
+	}, nil
+}
+
+func (s *grpcBuilderServer) GetPayload(ctx context.Context, req *builderpb.GetPayloadRequest) (*builderpb.GetPayloadResponse, error) {
+	if req.GetVersion() == "" || len(req.GetSignedBlindedBeaconBlock()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, errInvalidGetPayloadRequest.Error())
+	}
+	header := http.Header{
+		"Content-Type":            {MediaTypeSSZ},
+		"Accept":                  {MediaTypeSSZ},
+		HeaderEthConsensusVersion: {req.GetVersion()},
+	}
+	respHeader, body, err := s.serve(ctx, http.MethodPost, params.PathGetPayload, header, req.GetSignedBlindedBeaconBlock())
+	if err != nil {
+		return nil, err
+	}
+	return &builderpb.GetPayloadResponse{Version: respHeader.Get(HeaderEthConsensusVersion), Payload: body}, nil
+}
+
+// grpcResponseWriter collects the HTTP response of a gRPC request
+type grpcResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *grpcResponseWriter) Header() http.Header { return w.header }
+
+func (w *grpcResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *grpcResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// serve sends the request to the HTTP handlers and returns the response headers and body, or the gRPC status of the
+// HTTP error. The request metadata is passed on as HTTP headers after the headers of the request.
+func (s *grpcBuilderServer) serve(ctx context.Context, method, path string, header http.Header, body []byte) (http.Header, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") || grpcReservedMetadata[key] {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	w := &grpcResponseWriter{header: make(http.Header)}
+	s.handler.ServeHTTP(w, req)
+
+	md = metadata.MD{}
+	for key, values := range w.header {
+		if key != "Content-Type" && key != "Content-Length" {
+			md.Append(key, values...)
+		}
+	}
+	_ = grpc.SetHeader(ctx, md)
+
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	if code == http.StatusOK {
+		return w.header, w.body.Bytes(), nil
+	}
+	message := http.StatusText(code)
+	var httpErr httpErrorResp
+	if err := json.Unmarshal(w.body.Bytes(), &httpErr); err == nil && httpErr.Message != "" {
+		message = httpErr.Message
+	}
+	return nil, nil, status.Error(grpcCode(code), message)
+}
+
+// grpcCode returns the gRPC status code of an HTTP status code. No bid (204) is NotFound.
+func grpcCode(code int) codes.Code {
+	switch code {
+	case http.StatusNoContent, http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// newGRPCServer returns the gRPC server of the builder service
+func (m *BoostService) newGRPCServer() *grpc.Server {
+	srv := grpc.NewServer()
+	builderpb.RegisterBuilderServer(srv, &grpcBuilderServer{handler: m.getRouter()})
+	return srv
+}
+
+// StartGRPCServer serves the builder API over gRPC on the configured gRPC address
+func (m *BoostService) StartGRPCServer() error {
+	m.serversLock.Lock()
+	if err := m.checkServerStart(m.grpcSrv != nil); err != nil {
+		m.serversLock.Unlock()
+		return err
+	}
+	srv := m.newGRPCServer()
+	m.grpcSrv = srv
+	m.serversLock.Unlock()
+
+	listener, err := listen(m.grpcAddr)
+	if err != nil {
+		return err
+	}
+	err = srv.Serve(listener)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
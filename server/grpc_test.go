@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/flashbots/mev-boost/server/builderpb"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the gRPC builder API of the backend in memory and returns a client connection to it
+func newGRPCClient(t *testing.T, backend *testBackend) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := backend.boost.newGRPCServer()
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCBuilderAPI(t *testing.T) {
+	ctx := context.Background()
+	parentHash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	t.Run("Status", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		client := builderpb.NewBuilderClient(newGRPCClient(t, backend))
+		_, err := client.Status(ctx, &builderpb.StatusRequest{})
+		require.NoError(t, err)
+
+		backend.relays[0].Server.Close()
+		_, err = client.Status(ctx, &builderpb.StatusRequest{})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("GetHeader", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		client := builderpb.NewBuilderClient(newGRPCClient(t, backend))
+		req := &builderpb.GetHeaderRequest{Slot: 1, ParentHash: parentHash[:], Pubkey: pubkey[:]}
+
+		var header metadata.MD
+		resp, err := client.GetHeader(ctx, req, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Equal(t, "deneb", resp.GetVersion())
+		require.Equal(t, "12345", resp.GetValue())
+		bid := new(builderApiDeneb.SignedBuilderBid)
+		require.NoError(t, bid.UnmarshalSSZ(resp.GetSignedBuilderBid()))
+		require.Equal(t, parentHash, bid.Message.Header.ParentHash)
+		require.Equal(t, []string{"12345"}, header.Get(HeaderKeyBidValueWei))
+
+		// No bid
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		_, err = client.GetHeader(ctx, req)
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		// The parent hash and pubkey are checked before they become part of the path
+		_, err = client.GetHeader(ctx, &builderpb.GetHeaderRequest{Slot: 1, ParentHash: []byte("/../"), Pubkey: pubkey[:]})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.GetHeader(ctx, &builderpb.GetHeaderRequest{Slot: 1, ParentHash: parentHash[:]})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("GetPayload", func(t *testing.T) {
+		jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+		require.NoError(t, err)
+		defer jsonFile.Close()
+		signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+		require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+		block, err := signedBlindedBeaconBlock.MarshalSSZ()
+		require.NoError(t, err)
+
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		client := builderpb.NewBuilderClient(newGRPCClient(t, backend))
+		resp, err := client.GetPayload(ctx, &builderpb.GetPayloadRequest{Version: "deneb", SignedBlindedBeaconBlock: block})
+		require.NoError(t, err)
+		require.Equal(t, "deneb", resp.GetVersion())
+		payload := new(builderApiDeneb.ExecutionPayloadAndBlobsBundle)
+		require.NoError(t, payload.UnmarshalSSZ(resp.GetPayload()))
+		require.Equal(t, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash, payload.ExecutionPayload.BlockHash)
+
+		_, err = client.GetPayload(ctx, &builderpb.GetPayloadRequest{SignedBlindedBeaconBlock: block})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.GetPayload(ctx, &builderpb.GetPayloadRequest{Version: "deneb", SignedBlindedBeaconBlock: []byte{0x01}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("RegisterValidator", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		client := builderpb.NewBuilderClient(newGRPCClient(t, backend))
+		registration := testRegistration()
+		req := &builderpb.RegisterValidatorRequest{Registrations: []*builderpb.ValidatorRegistration{{
+			FeeRecipient: registration.Message.FeeRecipient[:],
+			GasLimit:     registration.Message.GasLimit,
+			Timestamp:    uint64(registration.Message.Timestamp.Unix()),
+			Pubkey:       registration.Message.Pubkey[:],
+			Signature:    registration.Signature[:],
+		}}}
+		_, err := client.RegisterValidator(ctx, req)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return backend.relays[0].GetRequestCount(params.PathRegisterValidator) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		req.Registrations[0].Signature = req.Registrations[0].Signature[:95]
+		_, err = client.RegisterValidator(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// polledBid is the latest bid of a relay while polling, which replaces its earlier bids
+type polledBid struct {
+	response builderSpec.VersionedSignedBuilderBid
+	info     bidInfo
+	audit    *BidAuditRecord
+	usable   bool // false if the bid is only known to withdraw an earlier bid, i.e. below min-bid
+}
+
+// pollGetHeader returns whether the relays are polled again until the poll budget is over
+func (m *BoostService) pollGetHeader() bool {
+	return m.getHeaderPollInterval > 0 && m.getHeaderPollBudget > 0
+}
+
+// pollAgain waits for the next poll of a relay and returns false if it shouldn't be polled again. The last poll starts
+// before the end of the poll budget.
+func pollAgain(done <-chan struct{}, interval time.Duration, pollEnd time.Time) bool {
+	if time.Now().Add(interval).After(pollEnd) {
+		return false
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// samePolledBid returns true if the relay responded with the bid of its previous poll again
+func samePolledBid(latest map[string]*polledBid, relay types.RelayEntry, info bidInfo) bool {
+	prev, ok := latest[relay.String()]
+	return ok && prev.info.blockHash == info.blockHash && prev.info.value.Eq(info.value)
+}
+
+// replacePolledBid records the latest bid of a relay. The earlier bid of the relay is withdrawn, relays implementing
+// bid cancellations only serve bids which are still valid. If the withdrawn bid was the best bid, the best bid is
+// selected again from the latest bids of all relays.
+func (m *BoostService) replacePolledBid(result *bidResp, candidates map[BlockHashHex]*bidCandidate, latest map[string]*polledBid, relay types.RelayEntry, selector BidSelector, bid *polledBid) {
+	prev, ok := latest[relay.String()]
+	latest[relay.String()] = bid
+	if !ok || !prev.usable {
+		return
+	}
+	if prev.audit.RejectionReason == "" {
+		prev.audit.RejectionReason = bidRejectedReplaced
+	}
+	hash := BlockHashHex(prev.info.blockHash.String())
+	if candidate, ok := candidates[hash]; ok {
+		candidate.remove(relay)
+		if len(candidate.relays) == 0 {
+			delete(candidates, hash)
+		}
+	}
+	if result.response.IsEmpty() || result.bidInfo.blockHash != prev.info.blockHash {
+		return
+	}
+
+	*result = bidResp{}
+	for _, polled := range latest {
+		if !polled.usable {
+			continue
+		}
+		candidate, ok := candidates[BlockHashHex(polled.info.blockHash.String())]
+		if !ok {
+			continue
+		}
+		if !result.response.IsEmpty() {
+			bid, best := candidate.bid(), candidates[BlockHashHex(result.bidInfo.blockHash.String())].bid()
+			bid.Value, best.Value = polled.info.value, result.bidInfo.value
+			if !selector.Better(bid, best) {
+				continue
+			}
+		}
+		result.response = polled.response
+		result.bidInfo = polled.info
+		result.t = time.Now()
+	}
+}
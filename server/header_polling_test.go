@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHeaderPolling(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+	makeBid := func(relay *mock.Relay, value uint64, blockHash string) http.HandlerFunc {
+		bid := relay.MakeGetHeaderResponse(
+			value,
+			blockHash,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(bid))
+		}
+	}
+
+	t.Run("Latest bid of each relay wins", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.getHeaderPollInterval = 20 * time.Millisecond
+		backend.boost.getHeaderPollBudget = 150 * time.Millisecond
+
+		// Relay 0 cancels its best bid after the first poll, relay 1 bids more from the third poll on
+		first := makeBid(backend.relays[0], 30000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab1")
+		cancelled := makeBid(backend.relays[0], 10000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab2")
+		polls0 := 0
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, req *http.Request) {
+			polls0++
+			if polls0 == 1 {
+				first(w, req)
+				return
+			}
+			cancelled(w, req)
+		})
+		low := makeBid(backend.relays[1], 20000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab3")
+		high := makeBid(backend.relays[1], 25000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab4")
+		polls1 := 0
+		backend.relays[1].OverrideHandleGetHeader(func(w http.ResponseWriter, req *http.Request) {
+			polls1++
+			if polls1 < 3 {
+				low(w, req)
+				return
+			}
+			high(w, req)
+		})
+
+		start := time.Now()
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+		require.Greater(t, backend.relays[0].GetRequestCount(path), 3)
+		require.Greater(t, backend.relays[1].GetRequestCount(path), 3)
+
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		require.Equal(t, uint64(25000), value.Uint64())
+	})
+
+	t.Run("No bid withdraws the earlier bid", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getHeaderPollInterval = 20 * time.Millisecond
+		backend.boost.getHeaderPollBudget = 100 * time.Millisecond
+
+		bid := makeBid(backend.relays[0], 30000, "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab1")
+		polls := 0
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, req *http.Request) {
+			polls++
+			if polls == 1 {
+				bid(w, req)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Failed relays are not polled again", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.getHeaderPollInterval = 20 * time.Millisecond
+		backend.boost.getHeaderPollBudget = 100 * time.Millisecond
+		backend.relays[0].Server.Close()
+
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Greater(t, backend.relays[1].GetRequestCount(path), 1)
+		health := backend.boost.relayHealth.snapshot([]types.RelayEntry{backend.relays[0].RelayEntry})
+		require.Equal(t, 1, health[0].RecentRequests)
+	})
+}
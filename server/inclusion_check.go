@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// inclusionCheckSlots is the number of slots after the start of the proposal slot after which the block must be on chain
+	inclusionCheckSlots = 2
+	// inclusionCheckAttempts is the number of times the beacon node is queried if it fails to respond
+	inclusionCheckAttempts = 3
+	// inclusionCheckTimeout is the timeout of the beacon node requests, which aren't time critical
+	inclusionCheckTimeout = 5 * time.Second
+)
+
+var errEmptyBeaconBlock = errors.New("beacon block without execution payload header")
+
+// beaconBlindedBlockResponse is the part of the beacon node blinded block response needed to check the inclusion
+type beaconBlindedBlockResponse struct {
+	Data struct {
+		Message struct {
+			Body struct {
+				ExecutionPayloadHeader struct {
+					BlockHash string `json:"block_hash"`
+				} `json:"execution_payload_header"`
+			} `json:"body"`
+		} `json:"message"`
+	} `json:"data"`
+}
+
+// checkBlockInclusion asks the beacon node whether the block with the delivered payload landed on chain, and records a
+// missed slot for the relay which delivered the payload if another block is on chain. Needs the genesis time.
+func (m *BoostService) checkBlockInclusion(log *logrus.Entry, relay types.RelayEntry, slot phase0.Slot, slotUID string, blockHash phase0.Hash32) {
+	defer m.reportPanic(backgroundTaskTags("checkBlockInclusion", slot, slotUID))
+	log = log.WithField("relay", relay.GetURI(""))
+	slotStart := time.Unix(int64(m.genesisTime+uint64(slot)*m.slotTimeSec), 0)
+	time.Sleep(time.Until(slotStart.Add(inclusionCheckSlots * time.Duration(m.slotTimeSec) * time.Second)))
+
+	for attempt := 1; attempt <= inclusionCheckAttempts; attempt++ {
+		onChainBlockHash, err := m.beaconBlockHash(slot)
+		if err != nil {
+			log.WithError(err).WithField("attempt", attempt).Warn("could not query block from beacon node")
+			if attempt < inclusionCheckAttempts {
+				time.Sleep(time.Duration(m.slotTimeSec) * time.Second)
+			}
+			continue
+		}
+
+		// The proposer may not have published the block in time, or it was orphaned, neither of which is up to the relay
+		if onChainBlockHash == "" {
+			log.Warn("slot is empty, block with delivered payload was not published or orphaned")
+			return
+		}
+
+		if strings.EqualFold(onChainBlockHash, blockHash.String()) {
+			log.Info("block with delivered payload is on chain")
+			m.relayStats.record(relay, relayStatsBlockIncluded)
+			return
+		}
+
+		log.WithField("onChainBlockHash", onChainBlockHash).Error("block with delivered payload is not on chain, slot missed!")
+		m.relayStats.record(relay, relayStatsBlockMissed)
+		m.emitEvent(Event{
+			Type:      EventBlockMissed,
+			Severity:  severityError,
+			Slot:      uint64(slot),
+			SlotUID:   slotUID,
+			BlockHash: blockHash.String(),
+			Relays:    []string{relay.GetURI("")},
+		})
+		return
+	}
+	log.WithField("attempts", inclusionCheckAttempts).Error("could not check whether the block with delivered payload is on chain, giving up")
+}
+
+// beaconBlockHash returns the execution block hash of the canonical block at the slot, or an empty string if the slot is empty
+func (m *BoostService) beaconBlockHash(slot phase0.Slot) (string, error) {
+	u := m.beaconNodeURL.JoinPath(fmt.Sprintf("/eth/v1/beacon/blinded_blocks/%d", slot))
+	resp := new(beaconBlindedBlockResponse)
+	client := http.Client{Timeout: inclusionCheckTimeout}
+	code, err := SendHTTPRequest(context.Background(), client, http.MethodGet, u.String(), "", nil, nil, resp)
+	if code == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Message.Body.ExecutionPayloadHeader.BlockHash == "" {
+		return "", errEmptyBeaconBlock
+	}
+	return resp.Data.Message.Body.ExecutionPayloadHeader.BlockHash, nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBlockInclusion(t *testing.T) {
+	blockHash := mock.HexToHash("0x534809bd2b6832edff8d8ce4cb0e50068804fd1ef432c8362ad708a74fdc0e46")
+
+	// newBeaconNode returns a beacon node serving the block hash for slot 1, or 404 if it is empty
+	newBeaconNode := func(t *testing.T, onChainBlockHash string) *url.URL {
+		t.Helper()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/eth/v1/beacon/blinded_blocks/1", r.URL.Path)
+			if onChainBlockHash == "" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"code":404,"message":"NOT_FOUND: beacon block at slot 1"}`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"data":{"message":{"slot":"1","body":{"execution_payload_header":{"block_hash":"%s"}}}}}`, onChainBlockHash)
+		}))
+		t.Cleanup(ts.Close)
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		return u
+	}
+
+	for _, tc := range []struct {
+		name             string
+		onChainBlockHash string
+		included, missed bool
+	}{
+		{"Block included", blockHash.String(), true, false},
+		{"Slot empty", "", false, false},
+		{"Other block on chain", nilHash.String(), false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := newTestBackend(t, 1, time.Second)
+			backend.boost.beaconNodeURL = newBeaconNode(t, tc.onChainBlockHash)
+			relay := backend.relays[0].RelayEntry
+			events := backend.boost.events.subscribe()
+
+			backend.boost.checkBlockInclusion(mock.TestLog, relay, 1, "", blockHash)
+
+			stats := backend.boost.relayStats.snapshot([]types.RelayEntry{relay})[0]
+			require.Equal(t, tc.included, stats.BlocksIncluded == 1)
+			require.Equal(t, tc.missed, stats.BlocksMissed == 1)
+			if tc.missed {
+				event := <-events
+				require.Equal(t, EventBlockMissed, event.Type)
+				require.Equal(t, []string{relay.GetURI("")}, event.Relays)
+			} else {
+				require.Empty(t, events)
+			}
+		})
+	}
+	t.Run("Not checked without the genesis time", func(t *testing.T) {
+		jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+		require.NoError(t, err)
+		defer jsonFile.Close()
+		signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+		require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+		var requests atomic.Int32
+		beaconNode := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		t.Cleanup(beaconNode.Close)
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.beaconNodeURL, err = url.Parse(beaconNode.URL)
+		require.NoError(t, err)
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Never(t, func() bool { return requests.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// unixAddrPrefix marks a listen address as the path of a unix domain socket, i.e. unix:///var/run/mev-boost.sock
+const unixAddrPrefix = "unix://"
+
+// unixSocketMode lets the group of mev-boost, i.e. the one of the consensus client, connect to the socket
+const unixSocketMode fs.FileMode = 0o660
+
+var errUnixSocketInUse = errors.New("unix socket path exists and isn't a socket")
+
+// listen listens on a TCP address, or on a unix domain socket for unix:// addresses. A socket left over by a previous
+// run is replaced, the socket is removed when the listener is closed.
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixAddrPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%w: %s", errUnixSocketInUse, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, unixSocketMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Listen address options, passed as query args of the address
+const (
+	listenArgTLSCert     = "tls_cert"
+	listenArgTLSKey      = "tls_key"
+	listenArgTLSClientCA = "tls_client_ca"
+)
+
+var (
+	errInvalidListenOption = errors.New("invalid listen address option")
+	errListenTLSCert       = errors.New("TLS listen addresses need tls_cert and tls_key")
+)
+
+// listenAddr is a listen address of the builder API with its TLS settings
+type listenAddr struct {
+	addr        string
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+}
+
+// parseListenAddr parses a listen address with its options, i.e. 10.8.0.1:18550?tls_cert=/etc/tls.crt&tls_key=/etc/tls.key.
+// With tls_client_ca, clients need a certificate signed by this CA.
+func parseListenAddr(s string) (listenAddr, error) {
+	addr, rawQuery, _ := strings.Cut(s, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return listenAddr{}, fmt.Errorf("%w: %w", errInvalidListenOption, err)
+	}
+	ret := listenAddr{
+		addr:        addr,
+		tlsCert:     query.Get(listenArgTLSCert),
+		tlsKey:      query.Get(listenArgTLSKey),
+		tlsClientCA: query.Get(listenArgTLSClientCA),
+	}
+	for key := range query {
+		if key != listenArgTLSCert && key != listenArgTLSKey && key != listenArgTLSClientCA {
+			return listenAddr{}, fmt.Errorf("%w: %s", errInvalidListenOption, key)
+		}
+	}
+	if (ret.tlsCert == "") != (ret.tlsKey == "") || (ret.tlsClientCA != "" && ret.tlsCert == "") {
+		return listenAddr{}, errListenTLSCert
+	}
+	return ret, nil
+}
+
+// tlsConfig returns the TLS config of a listen address with TLS
+func (a listenAddr) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(a.tlsCert, a.tlsKey)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if a.tlsClientCA != "" {
+		pem, err := os.ReadFile(a.tlsClientCA)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificate in %s", errInvalidListenOption, a.tlsClientCA)
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// listenHTTPAll listens on all listen addresses of the builder API, or none if one fails
+func listenHTTPAll(addrs []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		listener, err := listenHTTP(addr)
+		if err != nil {
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			return nil, fmt.Errorf("%s: %w", addr, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// listenHTTP listens on a listen address of the builder API, with TLS if configured
+func listenHTTP(s string) (net.Listener, error) {
+	addr, err := parseListenAddr(s)
+	if err != nil {
+		return nil, err
+	}
+	if addr.tlsCert == "" {
+		return listen(addr.addr)
+	}
+	cfg, err := addr.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := listen(addr.addr)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, cfg), nil
+}
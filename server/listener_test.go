@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketListener(t *testing.T) {
+	t.Run("The server listens on a unix socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mev-boost.sock")
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.listenAddr = unixAddrPrefix + path
+		go func() {
+			err := backend.boost.StartHTTPServer()
+			require.NoError(t, err) //nolint:testifylint
+		}()
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, unixSocketMode, info.Mode().Perm())
+
+		client := http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		code, err := SendHTTPRequest(context.Background(), client, http.MethodGet, "http://mev-boost"+params.PathStatus, "test", nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		require.NoError(t, backend.boost.srv.Close())
+		_, err = os.Stat(path)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("A stale socket is replaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mev-boost.sock")
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false) //nolint:forcetypeassert
+		require.NoError(t, stale.Close())
+
+		listener, err := listen(unixAddrPrefix + path)
+		require.NoError(t, err)
+		require.NoError(t, listener.Close())
+	})
+
+	t.Run("Other files aren't replaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mev-boost.sock")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+		_, err := listen(unixAddrPrefix + path)
+		require.ErrorIs(t, err, errUnixSocketInUse)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+	})
+}
+
+func TestListenAddrs(t *testing.T) {
+	t.Run("Listen address options", func(t *testing.T) {
+		addr, err := parseListenAddr("10.8.0.1:18550?tls_cert=/etc/tls.crt&tls_key=/etc/tls.key&tls_client_ca=/etc/ca.crt")
+		require.NoError(t, err)
+		require.Equal(t, listenAddr{addr: "10.8.0.1:18550", tlsCert: "/etc/tls.crt", tlsKey: "/etc/tls.key", tlsClientCA: "/etc/ca.crt"}, addr)
+
+		addr, err = parseListenAddr("unix:///var/run/mev-boost.sock")
+		require.NoError(t, err)
+		require.Equal(t, listenAddr{addr: "unix:///var/run/mev-boost.sock"}, addr)
+
+		_, err = parseListenAddr("localhost:18550?tls=true")
+		require.ErrorIs(t, err, errInvalidListenOption)
+		_, err = parseListenAddr("localhost:18550?tls_cert=/etc/tls.crt")
+		require.ErrorIs(t, err, errListenTLSCert)
+		_, err = parseListenAddr("localhost:18550?tls_client_ca=/etc/ca.crt")
+		require.ErrorIs(t, err, errListenTLSCert)
+	})
+
+	t.Run("The builder API is served on all listen addresses", func(t *testing.T) {
+		dir := t.TempDir()
+		serverCert := writeClientCert(t, dir, "mev-boost")
+		clientCert := writeClientCert(t, dir, "monitoring")
+		plainPath, tlsPath := filepath.Join(dir, "plain.sock"), filepath.Join(dir, "tls.sock")
+
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.listenAddr = unixAddrPrefix + plainPath
+		backend.boost.additionalListenAddrs = []string{fmt.Sprintf("%s%s?tls_cert=%s&tls_key=%s&tls_client_ca=%s",
+			unixAddrPrefix, tlsPath, serverCert.certFile, serverCert.keyFile, clientCert.certFile)}
+		go func() {
+			err := backend.boost.StartHTTPServer()
+			require.NoError(t, err) //nolint:testifylint
+		}()
+		t.Cleanup(func() { backend.boost.srv.Close() })
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(tlsPath)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		// client returns a client connecting to the unix socket, with the TLS config if set
+		client := func(path string, tlsConfig *tls.Config) http.Client {
+			return http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", path)
+				},
+				TLSClientConfig: tlsConfig,
+			}}
+		}
+		code, err := SendHTTPRequest(context.Background(), client(plainPath, nil), http.MethodGet, "http://mev-boost"+params.PathStatus, "test", nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		roots := x509.NewCertPool()
+		pem, err := os.ReadFile(serverCert.certFile)
+		require.NoError(t, err)
+		require.True(t, roots.AppendCertsFromPEM(pem))
+		certificate, err := tls.LoadX509KeyPair(clientCert.certFile, clientCert.keyFile)
+		require.NoError(t, err)
+		tlsConfig := &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+		code, err = SendHTTPRequest(context.Background(), client(tlsPath, tlsConfig), http.MethodGet, "https://mev-boost"+params.PathStatus, "test", nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		// Clients without certificate are refused
+		_, err = SendHTTPRequest(context.Background(), client(tlsPath, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}), http.MethodGet, "https://mev-boost"+params.PathStatus, "test", nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Nothing is served if a listen address fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mev-boost.sock")
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.listenAddr = unixAddrPrefix + path
+		backend.boost.additionalListenAddrs = []string{"localhost:876543"}
+		require.Error(t, backend.boost.StartHTTPServer())
+		_, err := os.Stat(path)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost/config"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "mevboost"
+
+var (
+	// metricsRegistry holds all mev-boost metrics, served by the metrics server
+	metricsRegistry = prometheus.NewRegistry()
+
+	relayRequestPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "relay_request_phase_duration_seconds",
+		Help:      "Duration of the phases (dns, connect, tls, ttfb, body, total) of requests to relays",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .2, .3, .5, .75, 1, 1.5, 2, 3, 5},
+	}, []string{"relay", "method", "phase"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		relayRequestPhaseDuration,
+	)
+}
+
+// relayLabel returns the metrics label for a relay (the host, without the public key)
+func relayLabel(relay types.RelayEntry) string {
+	return relay.URL.Host
+}
+
+// observeRequestTimings records the phase durations of a single request to a relay
+func (m *BoostService) observeRequestTimings(relay types.RelayEntry, method string, timings *requestTimings) {
+	for phase, duration := range timings.phases() {
+		relayRequestPhaseDuration.WithLabelValues(relayLabel(relay), method, phase).Observe(duration.Seconds())
+		m.metricsSink.Timing("relay_request_phase_duration", duration, relayMetricTags(relay, map[string]string{"method": method, "phase": phase}))
+	}
+}
+
+// metricsHandler serves the prometheus metrics, protected by the metrics token if set
+func (m *BoostService) metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.requireToken(m.metricsToken, promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})))
+	return mux
+}
+
+// StartMetricsServer serves the prometheus metrics on the configured metrics address
+func (m *BoostService) StartMetricsServer() error {
+	m.serversLock.Lock()
+	if err := m.checkServerStart(m.metricsSrv != nil); err != nil {
+		m.serversLock.Unlock()
+		return err
+	}
+	srv := &http.Server{
+		Addr:    m.metricsAddr,
+		Handler: m.metricsHandler(),
+
+		ReadHeaderTimeout: time.Duration(config.ServerReadHeaderTimeoutMs) * time.Millisecond,
+	}
+	m.metricsSrv = srv
+	m.serversLock.Unlock()
+
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
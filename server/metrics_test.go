@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayRequestPhaseMetrics(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	backend := newTestBackend(t, 1, time.Second)
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	relay := relayLabel(backend.relays[0].RelayEntry)
+	for _, phase := range []string{"connect", "ttfb", "body", "total"} {
+		metric := new(dto.Metric)
+		histogram := relayRequestPhaseDuration.WithLabelValues(relay, "getHeader", phase).(prometheus.Histogram) //nolint:forcetypeassert
+		require.NoError(t, histogram.Write(metric))
+		require.Positive(t, metric.GetHistogram().GetSampleCount(), phase)
+	}
+}
+
+func TestRequestTimingsRetries(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx, timings := withRequestTimings(context.Background())
+	_, err := sendHTTPRequestWithRetries(ctx, *http.DefaultClient, http.MethodGet, ts.URL, "", nil, nil, nil, retryPolicy{maxRetries: 2}, mock.TestLog)
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	// The total duration includes the failed attempt
+	require.GreaterOrEqual(t, timings.phases()["total"], 50*time.Millisecond)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// severityCritical is the severity of events which need immediate attention
+const severityCritical = "critical"
+
+// reasonNoResponseWithinTimeout is reported for relays which neither failed nor responded before the getPayload timeout
+const reasonNoResponseWithinTimeout = "no response within timeout"
+
+var missedPayloads = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "missed_payloads_total",
+	Help:      "Number of getPayload requests for which no relay returned a valid payload",
+})
+
+func init() {
+	metricsRegistry.MustRegister(missedPayloads)
+}
+
+// relayAttempts records the getPayload requests to the relays, to tell when and why each relay failed if none of them
+// delivered the payload
+type relayAttempts struct {
+	mu       sync.Mutex
+	start    time.Time
+	relays   []types.RelayEntry
+	attempts map[string]*relayAttempt
+}
+
+// relayAttempt is the getPayload request to a relay
+type relayAttempt struct {
+	requested time.Time
+	failed    time.Time // zero while the relay hasn't failed
+	class     relayErrorClass
+	err       error
+}
+
+func newRelayAttempts(start time.Time) *relayAttempts {
+	return &relayAttempts{start: start, attempts: make(map[string]*relayAttempt)}
+}
+
+// begin records the request to the relay
+func (a *relayAttempts) begin(relay types.RelayEntry, requested time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.relays = append(a.relays, relay)
+	a.attempts[relay.String()] = &relayAttempt{requested: requested}
+}
+
+// fail records the failure of the relay
+func (a *relayAttempts) fail(relay types.RelayEntry, class relayErrorClass, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if attempt, ok := a.attempts[relay.String()]; ok {
+		attempt.failed, attempt.class, attempt.err = time.Now(), class, err
+	}
+}
+
+// failures returns the failures of the requested relays sorted by relay. Relays which haven't failed yet didn't respond
+// within the timeout.
+func (a *relayAttempts) failures() []relayFailure {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	failures := make([]relayFailure, 0, len(a.relays))
+	for _, relay := range a.relays {
+		attempt := a.attempts[relay.String()]
+		failure := relayFailure{
+			Relay:            relay.GetURI(""),
+			Message:          reasonNoResponseWithinTimeout,
+			Class:            string(relayErrorTimeout),
+			RequestedAfterMs: attempt.requested.Sub(a.start).Milliseconds(),
+			DurationMs:       now.Sub(attempt.requested).Milliseconds(),
+		}
+		if attempt.err != nil {
+			failure.Message = attempt.err.Error()
+			failure.Class = string(attempt.class)
+			failure.DurationMs = attempt.failed.Sub(attempt.requested).Milliseconds()
+		}
+		failures = append(failures, failure)
+	}
+	return sortRelayFailures(failures)
+}
+
+// alertMissedPayload raises a critical alert if no relay returned the payload for a signed blinded block, through the log,
+// metrics and events (webhooks). The log record has the failures of all requested relays.
+func (m *BoostService) alertMissedPayload(log *logrus.Entry, slot phase0.Slot, slotUID string, blockHash phase0.Hash32, originalBid bidResp, failures []relayFailure) {
+	reasons := make(map[string]string, len(failures))
+	for _, failure := range failures {
+		reasons[failure.Relay] = failure.Message
+	}
+
+	value := ""
+	if !originalBid.response.IsEmpty() {
+		value = originalBid.bidInfo.value.Dec()
+	}
+
+	missedPayloads.Inc()
+	m.metricsSink.Count("missed_payloads", nil)
+	for _, relay := range originalBid.relays {
+		m.relayStats.record(relay, relayStatsPayloadMissed)
+	}
+
+	log.WithFields(logrus.Fields{
+		"alert":         "MISSED_PAYLOAD",
+		"severity":      severityCritical,
+		"winningRelays": relayURIs(originalBid.relays),
+		"bidValue":      value,
+		"relayErrors":   reasons,
+		"relayFailures": failures,
+	}).Error("ALERT: no relay returned the payload, the slot will be missed!")
+
+	m.emitEvent(Event{
+		Type:        EventGetPayloadFailed,
+		Severity:    severityCritical,
+		Slot:        uint64(slot),
+		SlotUID:     slotUID,
+		BlockHash:   blockHash.String(),
+		Value:       value,
+		Relays:      relayURIs(originalBid.relays),
+		Error:       errNoSuccessfulRelayResponse.Error(),
+		RelayErrors: reasons,
+	})
+}
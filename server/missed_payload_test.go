@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayAttempts(t *testing.T) {
+	relayA, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-a.example.com")
+	require.NoError(t, err)
+	relayB, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-b.example.com")
+	require.NoError(t, err)
+	relayC, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-c.example.com")
+	require.NoError(t, err)
+
+	start := time.Now().Add(-time.Second)
+	attempts := newRelayAttempts(start)
+	attempts.begin(relayB, start.Add(100*time.Millisecond))
+	attempts.begin(relayA, start.Add(200*time.Millisecond))
+	attempts.fail(relayB, relayErrorHTTP5xx, errHTTPErrorResponse)
+	// Relays which were never asked aren't failures
+	attempts.fail(relayC, relayErrorOther, errHTTPErrorResponse)
+
+	failures := attempts.failures()
+	require.Len(t, failures, 2)
+	require.Equal(t, "https://relay-a.example.com", failures[0].Relay)
+	require.Equal(t, reasonNoResponseWithinTimeout, failures[0].Message)
+	require.Equal(t, string(relayErrorTimeout), failures[0].Class)
+	require.Equal(t, int64(200), failures[0].RequestedAfterMs)
+	require.GreaterOrEqual(t, failures[0].DurationMs, int64(800))
+
+	require.Equal(t, "https://relay-b.example.com", failures[1].Relay)
+	require.Equal(t, errHTTPErrorResponse.Error(), failures[1].Message)
+	require.Equal(t, string(relayErrorHTTP5xx), failures[1].Class)
+	require.Equal(t, int64(100), failures[1].RequestedAfterMs)
+	require.GreaterOrEqual(t, failures[1].DurationMs, int64(900))
+}
@@ -11,12 +11,15 @@ import (
 	"time"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiBellatrix "github.com/attestantio/go-builder-client/api/bellatrix"
 	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
 	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
+	builderApiFulu "github.com/attestantio/go-builder-client/api/fulu"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	builderSpec "github.com/attestantio/go-builder-client/spec"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/electra"
@@ -66,6 +69,12 @@ type Relay struct {
 	GetHeaderResponse  *builderSpec.VersionedSignedBuilderBid
 	GetPayloadResponse *builderApi.VersionedSubmitBlindedBlockResponse
 
+	// GetHeaderProofs are the inclusion proofs of the constraints API getHeader response
+	GetHeaderProofs any
+
+	// Timestamp is the execution payload timestamp of the bids made by MakeGetHeaderResponse
+	Timestamp uint64
+
 	// Server section
 	Server        *httptest.Server
 	ResponseDelay time.Duration
@@ -120,6 +129,8 @@ func (m *Relay) getRouter() http.Handler {
 	r.HandleFunc(params.PathRegisterValidator, m.handleRegisterValidator).Methods(http.MethodPost)
 	r.HandleFunc(params.PathGetHeader, m.handleGetHeader).Methods(http.MethodGet)
 	r.HandleFunc(params.PathGetPayload, m.handleGetPayload).Methods(http.MethodPost)
+	r.HandleFunc(params.PathConstraints, m.handleSubmitConstraints).Methods(http.MethodPost)
+	r.HandleFunc(params.PathGetHeaderWithProofs, m.handleGetHeaderWithProofs).Methods(http.MethodGet)
 
 	return m.newTestMiddleware(r)
 }
@@ -174,82 +185,106 @@ func (m *Relay) defaultHandleRegisterValidator(w http.ResponseWriter, req *http.
 // method
 func (m *Relay) MakeGetHeaderResponse(value uint64, blockHash, parentHash, publicKey string, version spec.DataVersion) *builderSpec.VersionedSignedBuilderBid {
 	switch version {
-	case spec.DataVersionCapella:
-		// Fill the payload with custom values.
-		message := &builderApiCapella.BuilderBid{
-			Header: &capella.ExecutionPayloadHeader{
-				BlockHash:       HexToHash(blockHash),
-				ParentHash:      HexToHash(parentHash),
-				WithdrawalsRoot: phase0.Root{},
+	case spec.DataVersionBellatrix:
+		message := &builderApiBellatrix.BuilderBid{
+			Header: &bellatrix.ExecutionPayloadHeader{
+				BlockHash:  HexToHash(blockHash),
+				ParentHash: HexToHash(parentHash),
+				Timestamp:  m.Timestamp,
 			},
 			Value:  uint256.NewInt(value),
 			Pubkey: HexToPubkey(publicKey),
 		}
-		// Sign the message.
 		signature, err := ssz.SignMessage(message, ssz.DomainBuilder, m.secretKey)
 		require.NoError(m.t, err)
 		return &builderSpec.VersionedSignedBuilderBid{
-			Version: spec.DataVersionCapella,
-			Capella: &builderApiCapella.SignedBuilderBid{
+			Version: spec.DataVersionBellatrix,
+			Bellatrix: &builderApiBellatrix.SignedBuilderBid{
 				Message:   message,
 				Signature: signature,
 			},
 		}
-	case spec.DataVersionDeneb:
-		message := &builderApiDeneb.BuilderBid{
-			Header: &deneb.ExecutionPayloadHeader{
+	case spec.DataVersionCapella:
+		// Fill the payload with custom values.
+		message := &builderApiCapella.BuilderBid{
+			Header: &capella.ExecutionPayloadHeader{
 				BlockHash:       HexToHash(blockHash),
 				ParentHash:      HexToHash(parentHash),
+				Timestamp:       m.Timestamp,
 				WithdrawalsRoot: phase0.Root{},
-				BaseFeePerGas:   uint256.NewInt(0),
 			},
-			BlobKZGCommitments: make([]deneb.KZGCommitment, 0),
-			Value:              uint256.NewInt(value),
-			Pubkey:             HexToPubkey(publicKey),
+			Value:  uint256.NewInt(value),
+			Pubkey: HexToPubkey(publicKey),
 		}
-
 		// Sign the message.
 		signature, err := ssz.SignMessage(message, ssz.DomainBuilder, m.secretKey)
 		require.NoError(m.t, err)
-
 		return &builderSpec.VersionedSignedBuilderBid{
-			Version: spec.DataVersionDeneb,
-			Deneb: &builderApiDeneb.SignedBuilderBid{
+			Version: spec.DataVersionCapella,
+			Capella: &builderApiCapella.SignedBuilderBid{
 				Message:   message,
 				Signature: signature,
 			},
 		}
-	case spec.DataVersionElectra:
-		message := &builderApiElectra.BuilderBid{
-			Header: &deneb.ExecutionPayloadHeader{
-				BlockHash:       HexToHash(blockHash),
-				ParentHash:      HexToHash(parentHash),
-				WithdrawalsRoot: phase0.Root{},
-				BaseFeePerGas:   uint256.NewInt(0),
-			},
-			BlobKZGCommitments: make([]deneb.KZGCommitment, 0),
-			ExecutionRequests:  &electra.ExecutionRequests{},
-			Value:              uint256.NewInt(value),
-			Pubkey:             HexToPubkey(publicKey),
+	case spec.DataVersionDeneb, spec.DataVersionElectra, spec.DataVersionFulu:
+		header := &deneb.ExecutionPayloadHeader{
+			BlockHash:       HexToHash(blockHash),
+			ParentHash:      HexToHash(parentHash),
+			Timestamp:       m.Timestamp,
+			WithdrawalsRoot: phase0.Root{},
+			BaseFeePerGas:   uint256.NewInt(0),
 		}
-
-		// Sign the message.
-		signature, err := ssz.SignMessage(message, ssz.DomainBuilder, m.secretKey)
+		bid, err := signBid(version, header, uint256.NewInt(value), HexToPubkey(publicKey), ssz.DomainBuilder, m.secretKey)
 		require.NoError(m.t, err)
-
-		return &builderSpec.VersionedSignedBuilderBid{
-			Version: spec.DataVersionElectra,
-			Electra: &builderApiElectra.SignedBuilderBid{
-				Message:   message,
-				Signature: signature,
-			},
-		}
-	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix:
+		return bid
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
 		return nil
 	}
 	return nil
 }
 
+// signBid returns the Deneb, Electra or Fulu bid with the header and value, signed in the domain. Fulu bids are the same
+// as Electra bids.
+func signBid(version spec.DataVersion, header *deneb.ExecutionPayloadHeader, value *uint256.Int, pubkey phase0.BLSPubKey, domain phase0.Domain, secretKey *bls.SecretKey) (*builderSpec.VersionedSignedBuilderBid, error) {
+	if version == spec.DataVersionDeneb {
+		message := &builderApiDeneb.BuilderBid{
+			Header:             header,
+			BlobKZGCommitments: make([]deneb.KZGCommitment, 0),
+			Value:              value,
+			Pubkey:             pubkey,
+		}
+		signature, err := ssz.SignMessage(message, domain, secretKey)
+		if err != nil {
+			return nil, err
+		}
+		return &builderSpec.VersionedSignedBuilderBid{
+			Version: version,
+			Deneb:   &builderApiDeneb.SignedBuilderBid{Message: message, Signature: signature},
+		}, nil
+	}
+
+	message := &builderApiElectra.BuilderBid{
+		Header:             header,
+		BlobKZGCommitments: make([]deneb.KZGCommitment, 0),
+		ExecutionRequests: &electra.ExecutionRequests{
+			Deposits:       make([]*electra.DepositRequest, 0),
+			Withdrawals:    make([]*electra.WithdrawalRequest, 0),
+			Consolidations: make([]*electra.ConsolidationRequest, 0),
+		},
+		Value:  value,
+		Pubkey: pubkey,
+	}
+	signature, err := ssz.SignMessage(message, domain, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	signedBid := &builderApiElectra.SignedBuilderBid{Message: message, Signature: signature}
+	if version == spec.DataVersionFulu {
+		return &builderSpec.VersionedSignedBuilderBid{Version: version, Fulu: signedBid}, nil
+	}
+	return &builderSpec.VersionedSignedBuilderBid{Version: version, Electra: signedBid}, nil
+}
+
 // handleGetHeader handles incoming requests to server.pathGetHeader
 func (m *Relay) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 	m.mu.Lock()
@@ -268,18 +303,53 @@ func (m *Relay) defaultHandleGetHeader(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	// Build the default response.
-	response := m.MakeGetHeaderResponse(
+	if err := json.NewEncoder(w).Encode(m.getHeaderResponse()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getHeaderResponse returns the GetHeaderResponse, or the default bid
+func (m *Relay) getHeaderResponse() *builderSpec.VersionedSignedBuilderBid {
+	if m.GetHeaderResponse != nil {
+		return m.GetHeaderResponse
+	}
+	return m.MakeGetHeaderResponse(
 		12345,
 		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
 		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
 		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
 		spec.DataVersionDeneb,
 	)
-	if m.GetHeaderResponse != nil {
-		response = m.GetHeaderResponse
+}
+
+// handleSubmitConstraints accepts the constraints of the constraints API
+func (m *Relay) handleSubmitConstraints(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGetHeaderWithProofs returns the bid of handleGetHeader with the GetHeaderProofs, as in the constraints API
+func (m *Relay) handleGetHeaderWithProofs(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	encoded, err := json.Marshal(m.getHeaderResponse())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	var response struct {
+		Version string         `json:"version"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Data["proofs"] = m.GetHeaderProofs
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -287,26 +357,41 @@ func (m *Relay) defaultHandleGetHeader(w http.ResponseWriter) {
 }
 
 // MakeGetPayloadResponse is used to create the default or can be used to create a custom response to the getPayload
-// method
+// method, without blobs
 func (m *Relay) MakeGetPayloadResponse(parentHash, blockHash, feeRecipient string, blockNumber uint64, version spec.DataVersion) *builderApi.VersionedSubmitBlindedBlockResponse {
-	return &builderApi.VersionedSubmitBlindedBlockResponse{
-		Version: version,
-		Deneb: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
-			ExecutionPayload: &deneb.ExecutionPayload{
-				ParentHash:    HexToHash(parentHash),
-				BlockHash:     HexToHash(blockHash),
-				BlockNumber:   blockNumber,
-				FeeRecipient:  HexToAddress(feeRecipient),
-				BaseFeePerGas: uint256.NewInt(0),
-				Withdrawals:   make([]*capella.Withdrawal, 0),
-			},
-			BlobsBundle: &builderApiDeneb.BlobsBundle{
-				Blobs:       make([]deneb.Blob, 0),
-				Commitments: make([]deneb.KZGCommitment, 0),
-				Proofs:      make([]deneb.KZGProof, 0),
-			},
-		},
+	payload := &deneb.ExecutionPayload{
+		ParentHash:    HexToHash(parentHash),
+		BlockHash:     HexToHash(blockHash),
+		BlockNumber:   blockNumber,
+		FeeRecipient:  HexToAddress(feeRecipient),
+		BaseFeePerGas: uint256.NewInt(0),
+		Withdrawals:   make([]*capella.Withdrawal, 0),
 	}
+	return payloadResponse(version, payload, make([]deneb.KZGCommitment, 0), make([]deneb.KZGProof, 0), make([]deneb.Blob, 0))
+}
+
+// payloadResponse returns the getPayload response of the fork with the payload and blobs bundle. Fulu responses have a
+// Fulu blobs bundle, Electra and all other versions a Deneb one.
+func payloadResponse(version spec.DataVersion, payload *deneb.ExecutionPayload, commitments []deneb.KZGCommitment, proofs []deneb.KZGProof, blobs []deneb.Blob) *builderApi.VersionedSubmitBlindedBlockResponse {
+	response := &builderApi.VersionedSubmitBlindedBlockResponse{Version: version}
+	switch version {
+	case spec.DataVersionFulu:
+		response.Fulu = &builderApiFulu.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: payload,
+			BlobsBundle:      &builderApiFulu.BlobsBundle{Commitments: commitments, Proofs: proofs, Blobs: blobs},
+		}
+	case spec.DataVersionElectra:
+		response.Electra = &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: payload,
+			BlobsBundle:      &builderApiDeneb.BlobsBundle{Commitments: commitments, Proofs: proofs, Blobs: blobs},
+		}
+	default:
+		response.Deneb = &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: payload,
+			BlobsBundle:      &builderApiDeneb.BlobsBundle{Commitments: commitments, Proofs: proofs, Blobs: blobs},
+		}
+	}
+	return response
 }
 
 // handleGetPayload handles incoming requests to server.pathGetPayload
@@ -353,6 +438,13 @@ func (m *Relay) OverrideHandleRegisterValidator(method func(w http.ResponseWrite
 	m.handlerOverrideRegisterValidator = method
 }
 
+func (m *Relay) OverrideHandleGetHeader(method func(w http.ResponseWriter, req *http.Request)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlerOverrideGetHeader = method
+}
+
 func (m *Relay) OverrideHandleGetPayload(method func(w http.ResponseWriter, req *http.Request)) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// defaultScenarioFork is the fork of the bids and payloads if the scenario doesn't set one
+var defaultScenarioFork = spec.DataVersionFulu
+
+// scenarioForks are the forks the scenario relay can serve bids and payloads of
+var scenarioForks = []spec.DataVersion{spec.DataVersionDeneb, spec.DataVersionElectra, spec.DataVersionFulu}
+
+var errInvalidScenario = errors.New("invalid scenario")
+
+// Scenario are the behaviors of the scenario relay by endpoint, i.e.
+//
+//	fork: electra
+//	get-header:
+//	  - slots: [100, 101]
+//	    status: 500
+//	  - value: 0.05
+//	    delay: 300ms
+//	get-payload:
+//	  - slots: [102]
+//	    wrong-blobs: true
+//
+// The first behavior of an endpoint matching the slot of the request applies, behaviors without slots match all
+// requests. Requests without a matching behavior are served normally.
+type Scenario struct {
+	Fork              string     `yaml:"fork"               toml:"fork"`
+	Status            []Behavior `yaml:"status"             toml:"status"`
+	RegisterValidator []Behavior `yaml:"register-validator" toml:"register-validator"`
+	GetHeader         []Behavior `yaml:"get-header"         toml:"get-header"`
+	GetPayload        []Behavior `yaml:"get-payload"        toml:"get-payload"`
+}
+
+// Behavior is how the scenario relay responds to the requests of an endpoint
+type Behavior struct {
+	Slots  []uint64      `yaml:"slots"  toml:"slots"`  // slots of the getHeader or getPayload requests, all if empty
+	Delay  time.Duration `yaml:"delay"  toml:"delay"`  // wait before responding
+	Status int           `yaml:"status" toml:"status"` // respond with this status code instead, i.e. 204 (no bid) or 500
+
+	// getHeader
+	Value            *float64 `yaml:"value"             toml:"value"`             // bid value in ETH, 0.01 if not set
+	InvalidSignature bool     `yaml:"invalid-signature" toml:"invalid-signature"` // sign the bid with another key
+
+	// getPayload
+	EmptyPayload bool `yaml:"empty-payload" toml:"empty-payload"` // respond with a payload without block hash
+	WrongBlobs   bool `yaml:"wrong-blobs"   toml:"wrong-blobs"`   // respond with a blob not committed to by the block
+}
+
+// fork returns the fork of the bids and payloads of the scenario
+func (s Scenario) fork() spec.DataVersion {
+	for _, fork := range scenarioForks {
+		if strings.EqualFold(s.Fork, fork.String()) {
+			return fork
+		}
+	}
+	return defaultScenarioFork
+}
+
+// Validate checks the scenario for unknown forks and options given for endpoints they don't apply to
+func (s Scenario) Validate() error {
+	if s.Fork != "" && !slices.ContainsFunc(scenarioForks, func(fork spec.DataVersion) bool { return strings.EqualFold(s.Fork, fork.String()) }) {
+		return fmt.Errorf("%w: unsupported fork %s", errInvalidScenario, s.Fork)
+	}
+	endpoints := []struct {
+		name              string
+		behaviors         []Behavior
+		slots, bid, block bool
+	}{
+		{name: "status", behaviors: s.Status},
+		{name: "register-validator", behaviors: s.RegisterValidator},
+		{name: "get-header", behaviors: s.GetHeader, slots: true, bid: true},
+		{name: "get-payload", behaviors: s.GetPayload, slots: true, block: true},
+	}
+	for _, endpoint := range endpoints {
+		for i, b := range endpoint.behaviors {
+			var err error
+			switch {
+			case b.Delay < 0:
+				err = errors.New("negative delay")
+			case b.Status != 0 && (b.Status < 100 || b.Status > 599):
+				err = fmt.Errorf("invalid status %d", b.Status)
+			case len(b.Slots) > 0 && !endpoint.slots:
+				err = errors.New("slots are only supported for get-header and get-payload")
+			case (b.Value != nil || b.InvalidSignature) && !endpoint.bid:
+				err = errors.New("value and invalid-signature are only supported for get-header")
+			case b.Value != nil && *b.Value < 0:
+				err = errors.New("negative value")
+			case (b.EmptyPayload || b.WrongBlobs) && !endpoint.block:
+				err = errors.New("empty-payload and wrong-blobs are only supported for get-payload")
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %s #%d: %w", errInvalidScenario, endpoint.name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchBehavior returns the first behavior matching the slot, or the default behavior
+func matchBehavior(behaviors []Behavior, slot uint64) Behavior {
+	for _, b := range behaviors {
+		if len(b.Slots) == 0 || slices.Contains(b.Slots, slot) {
+			return b
+		}
+	}
+	return Behavior{}
+}
+
+// failed returns whether the behavior responds with an error status instead of serving the request
+func (b Behavior) failed() bool {
+	return b.Status != 0 && b.Status != http.StatusOK
+}
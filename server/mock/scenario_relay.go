@@ -0,0 +1,383 @@
+package mock
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	eth2UtilBellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
+	eth2UtilCapella "github.com/attestantio/go-eth2-client/util/capella"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/common"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/gorilla/mux"
+	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultBidValue is the value of the bids in ETH if the scenario doesn't set one
+	defaultBidValue = 0.01
+	// defaultGasLimit is the gas limit of the bids for validators which didn't register
+	defaultGasLimit = 36_000_000
+	// cellsPerExtBlob is the number of cell proofs per blob of Fulu blobs bundles
+	cellsPerExtBlob = 128
+)
+
+var (
+	errInvalidBlindedBlock = errors.New("invalid signed blinded block")
+	errInvalidForkVersion  = errors.New("invalid genesis fork version")
+)
+
+// mockTransaction is the transaction of the payloads, mev-boost ignores bids without transactions
+var mockTransaction = bellatrix.Transaction("mock-relay")
+
+// ScenarioRelayOpts are the options of the scenario relay
+type ScenarioRelayOpts struct {
+	Log                   *logrus.Entry
+	Scenario              Scenario
+	SecretKey             *bls.SecretKey // signs the bids, random if nil
+	GenesisForkVersionHex string
+	GenesisTime           uint64
+	SlotTimeSec           uint64
+}
+
+// ScenarioRelay is a mock relay serving the builder API with the scripted behaviors of a scenario, to test the failure
+// handling of mev-boost and the beacon node end to end. Unlike Relay it runs without a test, its bids follow the
+// requests and registrations, and it serves the network of the genesis settings. Its bids and payloads aren't valid
+// blocks, their execution payloads have a placeholder transaction and made up block hashes.
+type ScenarioRelay struct {
+	log         *logrus.Entry
+	scenario    Scenario
+	secretKey   *bls.SecretKey
+	publicKey   phase0.BLSPubKey
+	otherKey    *bls.SecretKey // signs the bids with invalid signatures
+	domain      phase0.Domain
+	genesisTime uint64
+	slotTimeSec uint64
+
+	// transactionsRoot and withdrawalsRoot are the roots of the payloads, for the bid headers
+	transactionsRoot phase0.Root
+	withdrawalsRoot  phase0.Root
+
+	mu            sync.Mutex
+	registrations map[phase0.BLSPubKey]*builderApiV1.ValidatorRegistration
+}
+
+// NewScenarioRelay returns a scenario relay
+func NewScenarioRelay(opts ScenarioRelayOpts) (*ScenarioRelay, error) {
+	if err := opts.Scenario.Validate(); err != nil {
+		return nil, err
+	}
+	secretKey := opts.SecretKey
+	if secretKey == nil {
+		var err error
+		if secretKey, err = bls.GenerateRandomSecretKey(); err != nil {
+			return nil, err
+		}
+	}
+	publicKey, err := bls.PublicKeyFromSecretKey(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	otherKey, err := bls.GenerateRandomSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	forkVersion, err := hexutil.Decode(opts.GenesisForkVersionHex)
+	if err != nil || len(forkVersion) != len(phase0.Version{}) {
+		return nil, fmt.Errorf("%w: %s", errInvalidForkVersion, opts.GenesisForkVersionHex)
+	}
+	domain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, phase0.Version(forkVersion), phase0.Root{})
+	transactionsRoot, err := (&eth2UtilBellatrix.ExecutionPayloadTransactions{Transactions: []bellatrix.Transaction{mockTransaction}}).HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	withdrawalsRoot, err := (&eth2UtilCapella.ExecutionPayloadWithdrawals{}).HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	slotTimeSec := opts.SlotTimeSec
+	if slotTimeSec == 0 {
+		slotTimeSec = common.SlotTimeSecMainnet
+	}
+
+	return &ScenarioRelay{
+		log:              opts.Log,
+		scenario:         opts.Scenario,
+		secretKey:        secretKey,
+		publicKey:        phase0.BLSPubKey(bls.PublicKeyToBytes(publicKey)),
+		otherKey:         otherKey,
+		domain:           domain,
+		genesisTime:      opts.GenesisTime,
+		slotTimeSec:      slotTimeSec,
+		transactionsRoot: transactionsRoot,
+		withdrawalsRoot:  withdrawalsRoot,
+		registrations:    make(map[phase0.BLSPubKey]*builderApiV1.ValidatorRegistration),
+	}, nil
+}
+
+// PublicKey returns the public key of the relay, which signs the bids
+func (r *ScenarioRelay) PublicKey() phase0.BLSPubKey {
+	return r.publicKey
+}
+
+// Handler returns the handler of the builder API of the relay
+func (r *ScenarioRelay) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc(params.PathStatus, r.handleStatus).Methods(http.MethodGet)
+	router.HandleFunc(params.PathRegisterValidator, r.handleRegisterValidator).Methods(http.MethodPost)
+	router.HandleFunc(params.PathGetHeader, r.handleGetHeader).Methods(http.MethodGet)
+	router.HandleFunc(params.PathGetPayload, r.handleGetPayload).Methods(http.MethodPost)
+	return router
+}
+
+// apply waits for the delay of the behavior and responds with its status code, if set. It returns whether the request
+// is still to be served.
+func (r *ScenarioRelay) apply(w http.ResponseWriter, req *http.Request, log *logrus.Entry, b Behavior) bool {
+	if b.Delay > 0 {
+		select {
+		case <-time.After(b.Delay):
+		case <-req.Context().Done():
+			log.Debug("request canceled during the delay")
+			return false
+		}
+	}
+	if b.failed() {
+		log.WithField("status", b.Status).Info("responding with the status of the scenario")
+		r.respondError(w, b.Status, "mock relay scenario")
+		return false
+	}
+	return true
+}
+
+func (r *ScenarioRelay) handleStatus(w http.ResponseWriter, req *http.Request) {
+	if !r.apply(w, req, r.log.WithField("method", "status"), matchBehavior(r.scenario.Status, 0)) {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRegisterValidator keeps the registrations, for the fee recipients and gas limits of the bids
+func (r *ScenarioRelay) handleRegisterValidator(w http.ResponseWriter, req *http.Request) {
+	log := r.log.WithField("method", "registerValidator")
+	if !r.apply(w, req, log, matchBehavior(r.scenario.RegisterValidator, 0)) {
+		return
+	}
+	var registrations []*builderApiV1.SignedValidatorRegistration
+	if err := json.NewDecoder(req.Body).Decode(&registrations); err != nil {
+		r.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.mu.Lock()
+	for _, registration := range registrations {
+		if registration == nil || registration.Message == nil {
+			continue
+		}
+		r.registrations[registration.Message.Pubkey] = registration.Message
+	}
+	r.mu.Unlock()
+	log.WithField("numRegistrations", len(registrations)).Debug("registered validators")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *ScenarioRelay) handleGetHeader(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	slot, err := strconv.ParseUint(vars["slot"], 10, 64)
+	if err != nil {
+		r.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	parentHash, err := utils.HexToHash(vars["parent_hash"])
+	if err != nil {
+		r.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pubkey, err := utils.HexToPubkey(vars["pubkey"])
+	if err != nil {
+		r.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log := r.log.WithFields(logrus.Fields{"method": "getHeader", "slot": slot, "parentHash": parentHash.String()})
+
+	b := matchBehavior(r.scenario.GetHeader, slot)
+	if !r.apply(w, req, log, b) {
+		return
+	}
+	bid, err := r.makeBid(slot, parentHash, pubkey, b)
+	if err != nil {
+		log.WithError(err).Error("could not make bid")
+		r.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.WithField("invalidSignature", b.InvalidSignature).Info("serving bid")
+	r.respondOK(w, bid.Version, bid)
+}
+
+// makeBid returns the bid of the slot, with the fee recipient and gas limit of the registration of the validator
+func (r *ScenarioRelay) makeBid(slot uint64, parentHash phase0.Hash32, pubkey phase0.BLSPubKey, b Behavior) (*builderSpec.VersionedSignedBuilderBid, error) {
+	value := defaultBidValue
+	if b.Value != nil {
+		value = *b.Value
+	}
+	wei, err := common.FloatEthTo256Wei(value)
+	if err != nil {
+		return nil, err
+	}
+	header := &deneb.ExecutionPayloadHeader{
+		ParentHash:       parentHash,
+		GasLimit:         defaultGasLimit,
+		Timestamp:        r.genesisTime + slot*r.slotTimeSec,
+		ExtraData:        []byte("mock-relay"),
+		BaseFeePerGas:    uint256.NewInt(0),
+		BlockHash:        mockBlockHash(slot, parentHash),
+		TransactionsRoot: r.transactionsRoot,
+		WithdrawalsRoot:  r.withdrawalsRoot,
+	}
+	r.mu.Lock()
+	if registration, ok := r.registrations[pubkey]; ok {
+		header.FeeRecipient = registration.FeeRecipient
+		header.GasLimit = registration.GasLimit
+	}
+	r.mu.Unlock()
+
+	secretKey := r.secretKey
+	if b.InvalidSignature {
+		secretKey = r.otherKey
+	}
+	return signBid(r.scenario.fork(), header, uint256.MustFromBig(wei.BigInt()), r.publicKey, r.domain, secretKey)
+}
+
+// mockBlockHash returns the made up block hash of the bids of the slot and parent
+func mockBlockHash(slot uint64, parentHash phase0.Hash32) phase0.Hash32 {
+	return sha256.Sum256(binary.BigEndian.AppendUint64(parentHash[:], slot))
+}
+
+// blindedBlock are the fields of a signed blinded block of Deneb, Electra or Fulu the mock relay needs for the payload
+type blindedBlock struct {
+	Message struct {
+		Slot phase0.Slot `json:"slot"`
+		Body struct {
+			ExecutionPayloadHeader *deneb.ExecutionPayloadHeader `json:"execution_payload_header"`
+			BlobKZGCommitments     []deneb.KZGCommitment         `json:"blob_kzg_commitments"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+func (r *ScenarioRelay) handleGetPayload(w http.ResponseWriter, req *http.Request) {
+	var block blindedBlock
+	if err := json.NewDecoder(req.Body).Decode(&block); err != nil {
+		r.respondError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", errInvalidBlindedBlock, err))
+		return
+	}
+	header := block.Message.Body.ExecutionPayloadHeader
+	if header == nil {
+		r.respondError(w, http.StatusBadRequest, fmt.Sprintf("%s: missing execution payload header", errInvalidBlindedBlock))
+		return
+	}
+	slot := uint64(block.Message.Slot)
+	log := r.log.WithFields(logrus.Fields{"method": "getPayload", "slot": slot, "blockHash": header.BlockHash.String()})
+
+	b := matchBehavior(r.scenario.GetPayload, slot)
+	if !r.apply(w, req, log, b) {
+		return
+	}
+	fork := r.scenario.fork()
+	for _, version := range scenarioForks {
+		if strings.EqualFold(req.Header.Get("Eth-Consensus-Version"), version.String()) {
+			fork = version
+		}
+	}
+	response := r.makePayload(fork, header, block.Message.Body.BlobKZGCommitments, b)
+	log.WithFields(logrus.Fields{
+		"emptyPayload": b.EmptyPayload,
+		"wrongBlobs":   b.WrongBlobs,
+	}).Info("serving payload")
+	r.respondOK(w, fork, response)
+}
+
+// makePayload returns the payload of the signed blinded block header: the mock transaction with the header fields, and
+// a blobs bundle with empty blobs for the commitments
+func (r *ScenarioRelay) makePayload(fork spec.DataVersion, header *deneb.ExecutionPayloadHeader, commitments []deneb.KZGCommitment, b Behavior) *builderApi.VersionedSubmitBlindedBlockResponse {
+	payload := &deneb.ExecutionPayload{
+		ParentHash:    header.ParentHash,
+		FeeRecipient:  header.FeeRecipient,
+		StateRoot:     header.StateRoot,
+		ReceiptsRoot:  header.ReceiptsRoot,
+		LogsBloom:     header.LogsBloom,
+		PrevRandao:    header.PrevRandao,
+		BlockNumber:   header.BlockNumber,
+		GasLimit:      header.GasLimit,
+		GasUsed:       header.GasUsed,
+		Timestamp:     header.Timestamp,
+		ExtraData:     header.ExtraData,
+		BaseFeePerGas: header.BaseFeePerGas,
+		BlockHash:     header.BlockHash,
+		Transactions:  []bellatrix.Transaction{mockTransaction},
+		Withdrawals:   make([]*capella.Withdrawal, 0),
+		BlobGasUsed:   header.BlobGasUsed,
+		ExcessBlobGas: header.ExcessBlobGas,
+	}
+	if payload.BaseFeePerGas == nil {
+		payload.BaseFeePerGas = uint256.NewInt(0)
+	}
+	if b.EmptyPayload {
+		payload = &deneb.ExecutionPayload{
+			BaseFeePerGas: uint256.NewInt(0),
+			Transactions:  make([]bellatrix.Transaction, 0),
+			Withdrawals:   make([]*capella.Withdrawal, 0),
+		}
+	}
+	if b.WrongBlobs {
+		commitments = append(commitments[:len(commitments):len(commitments)], deneb.KZGCommitment{0xc0})
+	}
+
+	proofsPerBlob := 1
+	if fork == spec.DataVersionFulu {
+		proofsPerBlob = cellsPerExtBlob
+	}
+	return payloadResponse(fork, payload, commitments, make([]deneb.KZGProof, len(commitments)*proofsPerBlob), make([]deneb.Blob, len(commitments)))
+}
+
+func (r *ScenarioRelay) respondOK(w http.ResponseWriter, fork spec.DataVersion, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Eth-Consensus-Version", fork.String())
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		r.log.WithError(err).Error("could not write response")
+	}
+}
+
+func (r *ScenarioRelay) respondError(w http.ResponseWriter, code int, message string) {
+	if code == http.StatusNoContent {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	resp := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{Code: code, Message: message}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		r.log.WithError(err).Error("could not write error response")
+	}
+}
@@ -0,0 +1,206 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testParentHash = "0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"
+	testPubkey     = "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+)
+
+func newScenarioRelay(t *testing.T, scenario Scenario) *ScenarioRelay {
+	t.Helper()
+	relay, err := NewScenarioRelay(ScenarioRelayOpts{
+		Log:                   logrus.NewEntry(logrus.New()),
+		Scenario:              scenario,
+		GenesisForkVersionHex: "0x00000000",
+		GenesisTime:           1000,
+	})
+	require.NoError(t, err)
+	return relay
+}
+
+func (r *ScenarioRelay) request(t *testing.T, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, req)
+	return rr
+}
+
+func getHeaderPath(slot uint64) string {
+	return fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, testParentHash, testPubkey)
+}
+
+func (r *ScenarioRelay) getHeader(t *testing.T, slot uint64) *builderSpec.VersionedSignedBuilderBid {
+	t.Helper()
+	rr := r.request(t, http.MethodGet, getHeaderPath(slot), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	bid := new(builderSpec.VersionedSignedBuilderBid)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), bid))
+	return bid
+}
+
+// validSignature returns whether the bid is signed by the relay
+func (r *ScenarioRelay) validSignature(t *testing.T, bid *builderSpec.VersionedSignedBuilderBid) bool {
+	t.Helper()
+	domain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, phase0.Version{}, phase0.Root{})
+	signature, err := bid.Signature()
+	require.NoError(t, err)
+	var message ssz.ObjWithHashTreeRoot
+	switch bid.Version {
+	case spec.DataVersionDeneb:
+		message = bid.Deneb.Message
+	case spec.DataVersionElectra:
+		message = bid.Electra.Message
+	default:
+		message = bid.Fulu.Message
+	}
+	ok, err := ssz.VerifySignature(message, domain, r.publicKey[:], signature[:])
+	require.NoError(t, err)
+	return ok
+}
+
+// blindedBlockBody returns the JSON of a signed blinded block of the slot with the bid header and blob commitments
+func blindedBlockBody(slot uint64, header *deneb.ExecutionPayloadHeader, commitments []deneb.KZGCommitment) any {
+	return map[string]any{
+		"message": map[string]any{
+			"slot": fmt.Sprint(slot),
+			"body": map[string]any{
+				"execution_payload_header": header,
+				"blob_kzg_commitments":     commitments,
+			},
+		},
+	}
+}
+
+func TestScenarioRelay(t *testing.T) {
+	t.Run("Bids are signed by the relay with the registered fee recipient", func(t *testing.T) {
+		relay := newScenarioRelay(t, Scenario{})
+		pubkey, err := utils.HexToPubkey(testPubkey)
+		require.NoError(t, err)
+		registration := &builderApiV1.SignedValidatorRegistration{Message: &builderApiV1.ValidatorRegistration{
+			FeeRecipient: bellatrix.ExecutionAddress{0x01},
+			GasLimit:     60_000_000,
+			Timestamp:    time.Unix(1, 0),
+			Pubkey:       pubkey,
+		}}
+		rr := relay.request(t, http.MethodPost, "/eth/v1/builder/validators", []*builderApiV1.SignedValidatorRegistration{registration})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		bid := relay.getHeader(t, 10)
+		require.Equal(t, spec.DataVersionFulu, bid.Version)
+		require.True(t, relay.validSignature(t, bid))
+		value, err := bid.Value()
+		require.NoError(t, err)
+		require.Equal(t, "10000000000000000", value.Dec())
+		header := bid.Fulu.Message.Header
+		require.Equal(t, testParentHash, header.ParentHash.String())
+		require.Equal(t, bellatrix.ExecutionAddress{0x01}, header.FeeRecipient)
+		require.Equal(t, uint64(60_000_000), header.GasLimit)
+		require.Equal(t, uint64(1000+10*12), header.Timestamp)
+		require.Equal(t, relay.publicKey, bid.Fulu.Message.Pubkey)
+	})
+
+	t.Run("getHeader behaviors by slot", func(t *testing.T) {
+		value := 0.5
+		relay := newScenarioRelay(t, Scenario{Fork: "deneb", GetHeader: []Behavior{
+			{Slots: []uint64{1}, Status: http.StatusNoContent},
+			{Slots: []uint64{2}, Status: http.StatusInternalServerError},
+			{Slots: []uint64{3}, InvalidSignature: true},
+			{Slots: []uint64{4}, Delay: 50 * time.Millisecond},
+			{Value: &value},
+		}})
+		require.Equal(t, http.StatusNoContent, relay.request(t, http.MethodGet, getHeaderPath(1), nil).Code)
+		require.Equal(t, http.StatusInternalServerError, relay.request(t, http.MethodGet, getHeaderPath(2), nil).Code)
+		require.False(t, relay.validSignature(t, relay.getHeader(t, 3)))
+
+		start := time.Now()
+		relay.getHeader(t, 4)
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+		bid := relay.getHeader(t, 5)
+		require.Equal(t, spec.DataVersionDeneb, bid.Version)
+		require.True(t, relay.validSignature(t, bid))
+		require.Equal(t, "500000000000000000", bid.Deneb.Message.Value.Dec())
+	})
+
+	t.Run("getPayload behaviors by slot", func(t *testing.T) {
+		relay := newScenarioRelay(t, Scenario{GetPayload: []Behavior{
+			{Slots: []uint64{2}, EmptyPayload: true},
+			{Slots: []uint64{3}, WrongBlobs: true},
+		}})
+		bid := relay.getHeader(t, 1)
+		header := bid.Fulu.Message.Header
+		commitments := []deneb.KZGCommitment{{0x01}, {0x02}}
+
+		getPayload := func(slot uint64) *builderApi.VersionedSubmitBlindedBlockResponse {
+			t.Helper()
+			rr := relay.request(t, http.MethodPost, "/eth/v1/builder/blinded_blocks", blindedBlockBody(slot, header, commitments))
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			response := new(builderApi.VersionedSubmitBlindedBlockResponse)
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), response))
+			require.Equal(t, spec.DataVersionFulu, response.Version)
+			return response
+		}
+
+		response := getPayload(1)
+		require.Equal(t, header.BlockHash, response.Fulu.ExecutionPayload.BlockHash)
+		require.Equal(t, commitments, response.Fulu.BlobsBundle.Commitments)
+		require.Len(t, response.Fulu.BlobsBundle.Blobs, 2)
+		require.Len(t, response.Fulu.BlobsBundle.Proofs, 2*cellsPerExtBlob)
+
+		require.Equal(t, phase0.Hash32{}, getPayload(2).Fulu.ExecutionPayload.BlockHash)
+		require.Len(t, getPayload(3).Fulu.BlobsBundle.Commitments, 3)
+
+		// The consensus version of the request decides the fork of the payload
+		req := httptest.NewRequest(http.MethodPost, "/eth/v1/builder/blinded_blocks", bytes.NewReader(mustJSON(t, blindedBlockBody(1, header, nil))))
+		req.Header.Set("Eth-Consensus-Version", "electra")
+		rr := httptest.NewRecorder()
+		relay.Handler().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, "electra", rr.Header().Get("Eth-Consensus-Version"))
+
+		rr = relay.request(t, http.MethodPost, "/eth/v1/builder/blinded_blocks", map[string]any{"message": map[string]any{"slot": "1"}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid scenario", func(t *testing.T) {
+		_, err := NewScenarioRelay(ScenarioRelayOpts{Scenario: Scenario{Fork: "phase0"}, GenesisForkVersionHex: "0x00000000"})
+		require.ErrorIs(t, err, errInvalidScenario)
+		_, err = NewScenarioRelay(ScenarioRelayOpts{GenesisForkVersionHex: "0x00"})
+		require.ErrorIs(t, err, errInvalidForkVersion)
+	})
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
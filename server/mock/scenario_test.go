@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario(t *testing.T) {
+	value := 0.05
+	t.Run("Valid", func(t *testing.T) {
+		scenario := Scenario{
+			Fork:      "Electra",
+			GetHeader: []Behavior{{Slots: []uint64{10}, Status: 500}, {Value: &value, Delay: time.Second}},
+			GetPayload: []Behavior{
+				{Slots: []uint64{11}, WrongBlobs: true},
+				{EmptyPayload: true},
+			},
+		}
+		require.NoError(t, scenario.Validate())
+		require.Equal(t, spec.DataVersionElectra, scenario.fork())
+		require.Equal(t, spec.DataVersionFulu, Scenario{}.fork())
+
+		require.Equal(t, 500, matchBehavior(scenario.GetHeader, 10).Status)
+		require.Equal(t, &value, matchBehavior(scenario.GetHeader, 11).Value)
+		require.True(t, matchBehavior(scenario.GetPayload, 11).WrongBlobs)
+		require.True(t, matchBehavior(scenario.GetPayload, 12).EmptyPayload)
+		require.Equal(t, Behavior{}, matchBehavior(scenario.Status, 0))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		negative := -1.0
+		for name, scenario := range map[string]Scenario{
+			"unknown fork":          {Fork: "capella"},
+			"negative delay":        {Status: []Behavior{{Delay: -time.Second}}},
+			"invalid status":        {GetHeader: []Behavior{{Status: 1000}}},
+			"slots of registration": {RegisterValidator: []Behavior{{Slots: []uint64{1}}}},
+			"value of getPayload":   {GetPayload: []Behavior{{Value: &value}}},
+			"negative value":        {GetHeader: []Behavior{{Value: &negative}}},
+			"blobs of getHeader":    {GetHeader: []Behavior{{WrongBlobs: true}}},
+		} {
+			require.ErrorIs(t, scenario.Validate(), errInvalidScenario, name)
+		}
+	})
+}
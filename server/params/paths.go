@@ -6,4 +6,34 @@ const (
 	PathRegisterValidator = "/eth/v1/builder/validators"
 	PathGetHeader         = "/eth/v1/builder/header/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
 	PathGetPayload        = "/eth/v1/builder/blinded_blocks"
+
+	// mev-boost specific paths
+	PathLivez          = "/livez"
+	PathReadyz         = "/readyz"
+	PathEvents         = "/events"
+	PathBidStream      = "/events/bids"
+	PathRelayStats     = "/api/v1/relay-stats"
+	PathRelayScores    = "/api/v1/relay-scores"
+	PathBidHistory     = "/api/v1/history/bids"
+	PathPayloadHistory = "/api/v1/history/payloads"
+	PathAdminReload    = "/admin/reload"
+
+	PathValidatorRegistration = "/api/v1/validator-registrations/{pubkey:0x[a-fA-F0-9]+}"
+
+	// admin API relay paths
+	PathAdminRelays      = "/admin/relays"
+	PathAdminRelay       = "/admin/relays/{pubkey:0x[a-fA-F0-9]+}"
+	PathAdminRelayPause  = "/admin/relays/{pubkey:0x[a-fA-F0-9]+}/pause"
+	PathAdminRelayResume = "/admin/relays/{pubkey:0x[a-fA-F0-9]+}/resume"
+
+	// constraints API paths
+	PathConstraints         = "/constraints/v1/builder/constraints"
+	PathConstraintsDelegate = "/constraints/v1/builder/delegate"
+	PathConstraintsRevoke   = "/constraints/v1/builder/revoke"
+	PathGetHeaderWithProofs = "/eth/v1/builder/header_with_proofs/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
+
+	// relay data API paths
+	PathDataProposerPayloadDelivered = "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	PathDataBuilderBlocksReceived    = "/relay/v1/data/bidtraces/builder_blocks_received"
+	PathDataValidatorRegistration    = "/relay/v1/data/validator_registration"
 )
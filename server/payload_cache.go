@@ -0,0 +1,73 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var payloadCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "payload_cache_hits_total",
+	Help:      "Number of retried getPayload requests served from the payload cache",
+})
+
+func init() {
+	metricsRegistry.MustRegister(payloadCacheHits)
+}
+
+// payloadCache remembers the payload delivered for a slot and block hash, so retried getPayload requests of the
+// consensus client are answered without asking the relays again. A retry arriving while the relays are still asked
+// waits for their result.
+type payloadCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*payloadCacheEntry // by bid key
+}
+
+// payloadCacheEntry is the result of the getPayload requests to the relays, available once done is closed
+type payloadCacheEntry struct {
+	done      chan struct{}
+	delivered time.Time
+	result    deliveredPayload
+	bid       bidResp
+}
+
+// newPayloadCache returns the payload cache, nil if the ttl is zero
+func newPayloadCache(ttl time.Duration) *payloadCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &payloadCache{ttl: ttl, entries: make(map[string]*payloadCacheEntry)}
+}
+
+// start returns the entry of the key, and true if there was none and the caller has to ask the relays and finish it
+func (c *payloadCache) start(key string, now time.Time) (*payloadCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.entries {
+		if !entry.delivered.IsZero() && now.Sub(entry.delivered) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	if entry, ok := c.entries[key]; ok {
+		return entry, false
+	}
+	entry := &payloadCacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// finish stores the result of the entry. Failed requests aren't cached, a retry asks the relays again.
+func (c *payloadCache) finish(key string, entry *payloadCacheEntry, result deliveredPayload, bid bidResp, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.result, entry.bid = result, bid
+	if result.response == nil {
+		delete(c.entries, key)
+	} else {
+		entry.delivered = now
+	}
+	close(entry.done)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadCache(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.payloadCache = newPayloadCache(time.Minute)
+		backend.boost.requestMaxRetries = 1
+		key := bidKey(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash)
+		backend.boost.bids[key] = bidResp{
+			response: *backend.relays[0].MakeGetHeaderResponse(
+				12345,
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				spec.DataVersionDeneb,
+			),
+			bidInfo: bidInfo{value: uint256.NewInt(12345)},
+			relays:  []types.RelayEntry{backend.relays[0].RelayEntry},
+		}
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		return backend
+	}
+
+	t.Run("Retries are served from the cache", func(t *testing.T) {
+		backend := setup(t)
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		retry := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, retry.Code, retry.Body.String())
+		require.JSONEq(t, rr.Body.String(), retry.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Retries wait for the request in flight", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[0].ResponseDelay = 100 * time.Millisecond
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Failures are not cached", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		for range 2 {
+			rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+			require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+		}
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Retries stop waiting when their request is done", func(t *testing.T) {
+		backend := setup(t)
+		// A request in flight which never finishes
+		key := bidKey(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash)
+		backend.boost.payloadCache.start(key, time.Now())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		result, _ := processPayload(ctx, backend.boost, backend.boost.log, "", signedBlindedBeaconBlock)
+		require.ErrorIs(t, result.refused, context.DeadlineExceeded)
+		require.Zero(t, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Entries expire after the ttl", func(t *testing.T) {
+		cache := newPayloadCache(time.Second)
+		now := time.Now()
+		entry, first := cache.start("key", now)
+		require.True(t, first)
+		cache.finish("key", entry, deliveredPayload{response: blindedBlockToBlockResponse(signedBlindedBeaconBlock)}, bidResp{}, now)
+
+		_, first = cache.start("key", now.Add(time.Second))
+		require.False(t, first)
+		_, first = cache.start("key", now.Add(2*time.Second))
+		require.True(t, first)
+	})
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2ApiV1Bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
+	eth2ApiV1Capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	eth2UtilBellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
+	eth2UtilCapella "github.com/attestantio/go-eth2-client/util/capella"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/sirupsen/logrus"
+)
+
+// Payload checks decide what happens to payloads which don't match the signed blinded block
+const (
+	PayloadCheckReject = "reject" // the payload is ignored, like an invalid payload
+	PayloadCheckWarn   = "warn"   // the payload is used with a warning
+	PayloadCheckOff    = "off"    // the payload isn't checked beyond the block hash field
+)
+
+// PayloadChecks are the names of all payload checks
+var PayloadChecks = []string{PayloadCheckReject, PayloadCheckWarn, PayloadCheckOff}
+
+// The execution request types of EIP-7685
+const (
+	depositRequestType       = 0x00
+	withdrawalRequestType    = 0x01
+	consolidationRequestType = 0x02
+)
+
+var (
+	errPayloadBlockHash        = errors.New("block hash of the payload doesn't match its contents")
+	errPayloadTransactionsRoot = errors.New("transactions root of the payload doesn't match the signed header")
+	errPayloadWithdrawalsRoot  = errors.New("withdrawals root of the payload doesn't match the signed header")
+	errExecutionRequests       = errors.New("execution requests of the signed block don't match the bid")
+)
+
+// checkPayload verifies the payload against the signed blinded block and the bid of the relay (if known), instead of
+// trusting the block hash reported by the relay
+func checkPayload[P Payload](log *logrus.Entry, blindedBlock P, response *builderApi.VersionedSubmitBlindedBlockResponse, bid builderSpec.VersionedSignedBuilderBid, check string) error {
+	if check == PayloadCheckOff {
+		return nil
+	}
+	err := verifyPayloadRoots(blindedBlock, response)
+	if err == nil {
+		err = verifyExecutionRequests(blindedBlock, bid)
+	}
+	if err == nil {
+		err = verifyPayloadBlockHash(blindedBlock, response)
+	}
+	if err == nil {
+		return nil
+	}
+	if check == PayloadCheckWarn {
+		log.WithError(err).Warn("payload doesn't match the signed blinded block, using it anyway")
+		return nil
+	}
+	log.WithError(err).Error("payload doesn't match the signed blinded block")
+	return err
+}
+
+// verifyPayloadRoots compares the SSZ roots of the transactions and withdrawals of the payload with the roots in the
+// header of the signed blinded block
+func verifyPayloadRoots[P Payload](blindedBlock P, response *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	var txs []bellatrix.Transaction
+	var withdrawals []*capella.Withdrawal
+	var signedTxsRoot phase0.Root
+	var signedWithdrawalsRoot *phase0.Root // nil before Capella
+	switch block := any(blindedBlock).(type) {
+	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
+		txs = response.Bellatrix.Transactions
+		signedTxsRoot = block.Message.Body.ExecutionPayloadHeader.TransactionsRoot
+	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
+		txs, withdrawals = response.Capella.Transactions, response.Capella.Withdrawals
+		signedTxsRoot = block.Message.Body.ExecutionPayloadHeader.TransactionsRoot
+		signedWithdrawalsRoot = &block.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot
+	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
+		txs, withdrawals = response.Deneb.ExecutionPayload.Transactions, response.Deneb.ExecutionPayload.Withdrawals
+		signedTxsRoot = block.Message.Body.ExecutionPayloadHeader.TransactionsRoot
+		signedWithdrawalsRoot = &block.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		txs, withdrawals = response.Electra.ExecutionPayload.Transactions, response.Electra.ExecutionPayload.Withdrawals
+		signedTxsRoot = block.Message.Body.ExecutionPayloadHeader.TransactionsRoot
+		signedWithdrawalsRoot = &block.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot
+	case *fuluSignedBlindedBeaconBlock:
+		txs, withdrawals = response.Fulu.ExecutionPayload.Transactions, response.Fulu.ExecutionPayload.Withdrawals
+		signedTxsRoot = block.Message.Body.ExecutionPayloadHeader.TransactionsRoot
+		signedWithdrawalsRoot = &block.Message.Body.ExecutionPayloadHeader.WithdrawalsRoot
+	}
+
+	txsRoot, err := (&eth2UtilBellatrix.ExecutionPayloadTransactions{Transactions: txs}).HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayloadTransactionsRoot, err)
+	}
+	if phase0.Root(txsRoot) != signedTxsRoot {
+		return fmt.Errorf("%w: computed %s, signed %s", errPayloadTransactionsRoot, phase0.Root(txsRoot), signedTxsRoot)
+	}
+	if signedWithdrawalsRoot == nil {
+		return nil
+	}
+	withdrawalsRoot, err := (&eth2UtilCapella.ExecutionPayloadWithdrawals{Withdrawals: withdrawals}).HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayloadWithdrawalsRoot, err)
+	}
+	if phase0.Root(withdrawalsRoot) != *signedWithdrawalsRoot {
+		return fmt.Errorf("%w: computed %s, signed %s", errPayloadWithdrawalsRoot, phase0.Root(withdrawalsRoot), signedWithdrawalsRoot)
+	}
+	return nil
+}
+
+// verifyExecutionRequests compares the execution requests of a signed Electra or Fulu blinded block with the execution
+// requests of the bid. The payload doesn't carry the execution requests, it only commits to them with its block hash,
+// so they have to be the ones the relay built the block with.
+func verifyExecutionRequests[P Payload](blindedBlock P, bid builderSpec.VersionedSignedBuilderBid) error {
+	var signed *electra.ExecutionRequests
+	switch block := any(blindedBlock).(type) {
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		signed = block.Message.Body.ExecutionRequests
+	case *fuluSignedBlindedBeaconBlock:
+		signed = block.Message.Body.ExecutionRequests
+	default:
+		return nil
+	}
+	if signed == nil {
+		return fmt.Errorf("%w: missing execution requests", errExecutionRequests)
+	}
+	signedRoot, err := signed.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errExecutionRequests, err)
+	}
+	bidRequests := bidExecutionRequests(bid)
+	if bidRequests == nil {
+		return nil
+	}
+	bidRoot, err := bidRequests.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errExecutionRequests, err)
+	}
+	if signedRoot != bidRoot {
+		return fmt.Errorf("%w: %d/%d/%d deposits/withdrawals/consolidations signed, %d/%d/%d in the bid", errExecutionRequests,
+			len(signed.Deposits), len(signed.Withdrawals), len(signed.Consolidations),
+			len(bidRequests.Deposits), len(bidRequests.Withdrawals), len(bidRequests.Consolidations))
+	}
+	return nil
+}
+
+// bidExecutionRequests returns the execution requests of an Electra or Fulu bid, nil for other bids
+func bidExecutionRequests(bid builderSpec.VersionedSignedBuilderBid) *electra.ExecutionRequests {
+	var signedBid *builderApiElectra.SignedBuilderBid
+	switch bid.Version {
+	case spec.DataVersionElectra:
+		signedBid = bid.Electra
+	case spec.DataVersionFulu:
+		signedBid = bid.Fulu
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix,
+		spec.DataVersionCapella, spec.DataVersionDeneb:
+		return nil
+	}
+	if signedBid == nil || signedBid.Message == nil {
+		return nil
+	}
+	return signedBid.Message.ExecutionRequests
+}
+
+// verifyPayloadBlockHash recomputes the execution block hash from the payload and compares it with the block hash of
+// the signed blinded block
+func verifyPayloadBlockHash[P Payload](blindedBlock P, response *builderApi.VersionedSubmitBlindedBlockResponse) error {
+	computed, err := payloadBlockHash(blindedBlock, response)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayloadBlockHash, err)
+	}
+	if signed := blockHash(blindedBlock); computed != signed {
+		return fmt.Errorf("%w: computed %s, signed %s", errPayloadBlockHash, computed, signed)
+	}
+	return nil
+}
+
+// payloadBlockHash returns the hash of the execution block header of the payload. The parent beacon block root
+// (since Deneb) and the execution requests (since Electra) aren't part of the payload, they come from the blinded block.
+func payloadBlockHash[P Payload](blindedBlock P, response *builderApi.VersionedSubmitBlindedBlockResponse) (phase0.Hash32, error) {
+	var header *ethTypes.Header
+	switch block := any(blindedBlock).(type) {
+	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
+		header = bellatrixBlockHeader(response.Bellatrix)
+	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
+		header = capellaBlockHeader(response.Capella)
+	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
+		header = denebBlockHeader(response.Deneb.ExecutionPayload, block.Message.ParentRoot)
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		var err error
+		if header, err = electraBlockHeader(response.Electra.ExecutionPayload, block); err != nil {
+			return phase0.Hash32{}, err
+		}
+	case *fuluSignedBlindedBeaconBlock:
+		var err error
+		if header, err = electraBlockHeader(response.Fulu.ExecutionPayload, block.SignedBlindedBeaconBlock); err != nil {
+			return phase0.Hash32{}, err
+		}
+	}
+	return phase0.Hash32(header.Hash()), nil
+}
+
+// electraBlockHeader returns the execution block header of an Electra or Fulu payload, with the requests hash of the
+// execution requests of the blinded block
+func electraBlockHeader(payload *deneb.ExecutionPayload, blindedBlock *eth2ApiV1Electra.SignedBlindedBeaconBlock) (*ethTypes.Header, error) {
+	header := denebBlockHeader(payload, blindedBlock.Message.ParentRoot)
+	requests, err := executionRequests(blindedBlock.Message.Body.ExecutionRequests)
+	if err != nil {
+		return nil, err
+	}
+	requestsHash := ethTypes.CalcRequestsHash(requests)
+	header.RequestsHash = &requestsHash
+	return header, nil
+}
+
+func bellatrixBlockHeader(payload *bellatrix.ExecutionPayload) *ethTypes.Header {
+	return &ethTypes.Header{
+		ParentHash:  common.Hash(payload.ParentHash),
+		UncleHash:   ethTypes.EmptyUncleHash,
+		Coinbase:    common.Address(payload.FeeRecipient),
+		Root:        payload.StateRoot,
+		TxHash:      transactionsRoot(payload.Transactions),
+		ReceiptHash: payload.ReceiptsRoot,
+		Bloom:       payload.LogsBloom,
+		Difficulty:  common.Big0,
+		Number:      new(big.Int).SetUint64(payload.BlockNumber),
+		GasLimit:    payload.GasLimit,
+		GasUsed:     payload.GasUsed,
+		Time:        payload.Timestamp,
+		Extra:       payload.ExtraData,
+		MixDigest:   payload.PrevRandao,
+		BaseFee:     littleEndianInt(payload.BaseFeePerGas),
+	}
+}
+
+func capellaBlockHeader(payload *capella.ExecutionPayload) *ethTypes.Header {
+	withdrawalsRoot := withdrawalsRoot(payload.Withdrawals)
+	return &ethTypes.Header{
+		ParentHash:      common.Hash(payload.ParentHash),
+		UncleHash:       ethTypes.EmptyUncleHash,
+		Coinbase:        common.Address(payload.FeeRecipient),
+		Root:            payload.StateRoot,
+		TxHash:          transactionsRoot(payload.Transactions),
+		ReceiptHash:     payload.ReceiptsRoot,
+		Bloom:           payload.LogsBloom,
+		Difficulty:      common.Big0,
+		Number:          new(big.Int).SetUint64(payload.BlockNumber),
+		GasLimit:        payload.GasLimit,
+		GasUsed:         payload.GasUsed,
+		Time:            payload.Timestamp,
+		Extra:           payload.ExtraData,
+		MixDigest:       payload.PrevRandao,
+		BaseFee:         littleEndianInt(payload.BaseFeePerGas),
+		WithdrawalsHash: &withdrawalsRoot,
+	}
+}
+
+func denebBlockHeader(payload *deneb.ExecutionPayload, parentBeaconRoot phase0.Root) *ethTypes.Header {
+	withdrawalsRoot := withdrawalsRoot(payload.Withdrawals)
+	beaconRoot := common.Hash(parentBeaconRoot)
+	return &ethTypes.Header{
+		ParentHash:       common.Hash(payload.ParentHash),
+		UncleHash:        ethTypes.EmptyUncleHash,
+		Coinbase:         common.Address(payload.FeeRecipient),
+		Root:             common.Hash(payload.StateRoot),
+		TxHash:           transactionsRoot(payload.Transactions),
+		ReceiptHash:      common.Hash(payload.ReceiptsRoot),
+		Bloom:            payload.LogsBloom,
+		Difficulty:       common.Big0,
+		Number:           new(big.Int).SetUint64(payload.BlockNumber),
+		GasLimit:         payload.GasLimit,
+		GasUsed:          payload.GasUsed,
+		Time:             payload.Timestamp,
+		Extra:            payload.ExtraData,
+		MixDigest:        payload.PrevRandao,
+		BaseFee:          payload.BaseFeePerGas.ToBig(),
+		WithdrawalsHash:  &withdrawalsRoot,
+		BlobGasUsed:      &payload.BlobGasUsed,
+		ExcessBlobGas:    &payload.ExcessBlobGas,
+		ParentBeaconRoot: &beaconRoot,
+	}
+}
+
+// rawTransactions are the transactions of a payload in their EIP-2718 encoding, as hashed into the transactions trie
+type rawTransactions []bellatrix.Transaction
+
+func (txs rawTransactions) Len() int { return len(txs) }
+
+func (txs rawTransactions) EncodeIndex(i int, w *bytes.Buffer) { w.Write(txs[i]) }
+
+// transactionsRoot returns the root of the transactions trie of the execution block
+func transactionsRoot(txs []bellatrix.Transaction) common.Hash {
+	return ethTypes.DeriveSha(rawTransactions(txs), trie.NewStackTrie(nil))
+}
+
+// withdrawalsRoot returns the root of the withdrawals trie of the execution block
+func withdrawalsRoot(withdrawals []*capella.Withdrawal) common.Hash {
+	list := make(ethTypes.Withdrawals, 0, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		list = append(list, &ethTypes.Withdrawal{
+			Index:     uint64(withdrawal.Index),
+			Validator: uint64(withdrawal.ValidatorIndex),
+			Address:   common.Address(withdrawal.Address),
+			Amount:    uint64(withdrawal.Amount),
+		})
+	}
+	return ethTypes.DeriveSha(list, trie.NewStackTrie(nil))
+}
+
+// executionRequests returns the EIP-7685 requests of the execution block: the request type followed by the SSZ encoded
+// requests of the type, types without requests are left out
+func executionRequests(requests *electra.ExecutionRequests) ([][]byte, error) {
+	if requests == nil {
+		return nil, nil
+	}
+	list := make([][]byte, 0, 3)
+	for _, typed := range []struct {
+		typ      byte
+		requests []sszMarshaler
+	}{
+		{depositRequestType, sszMarshalers(requests.Deposits)},
+		{withdrawalRequestType, sszMarshalers(requests.Withdrawals)},
+		{consolidationRequestType, sszMarshalers(requests.Consolidations)},
+	} {
+		if len(typed.requests) == 0 {
+			continue
+		}
+		data := []byte{typed.typ}
+		for _, request := range typed.requests {
+			var err error
+			if data, err = request.MarshalSSZTo(data); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, data)
+	}
+	return list, nil
+}
+
+type sszMarshaler interface {
+	MarshalSSZTo(buf []byte) ([]byte, error)
+}
+
+func sszMarshalers[T sszMarshaler](items []T) []sszMarshaler {
+	marshalers := make([]sszMarshaler, 0, len(items))
+	for _, item := range items {
+		marshalers = append(marshalers, item)
+	}
+	return marshalers
+}
+
+// littleEndianInt returns the little-endian encoded integer, the encoding of the base fee before Deneb
+func littleEndianInt(value [32]byte) *big.Int {
+	slices.Reverse(value[:])
+	return new(big.Int).SetBytes(value[:])
+}
@@ -0,0 +1,504 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
+	builderApiFulu "github.com/attestantio/go-builder-client/api/fulu"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2ApiV1Bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	eth2UtilBellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
+	eth2UtilCapella "github.com/attestantio/go-eth2-client/util/capella"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// testExecutionBlock returns an execution block with a transaction and a withdrawal, hashed by go-ethereum
+func testExecutionBlock(t *testing.T, requestsHash *common.Hash) (*ethTypes.Block, []bellatrix.Transaction) {
+	t.Helper()
+	to := common.HexToAddress("0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941")
+	tx := ethTypes.NewTx(&ethTypes.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(30), Gas: 21000, To: &to, Value: big.NewInt(12345)})
+	txBytes, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	blobGasUsed, excessBlobGas, beaconRoot := uint64(131072), uint64(0), common.HexToHash("0x01")
+	header := &ethTypes.Header{
+		ParentHash:       common.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"),
+		Coinbase:         to,
+		Root:             common.HexToHash("0x02"),
+		Difficulty:       common.Big0,
+		Number:           big.NewInt(12345),
+		GasLimit:         30_000_000,
+		GasUsed:          21000,
+		Time:             1732296378,
+		Extra:            []byte("mev-boost"),
+		MixDigest:        common.HexToHash("0x03"),
+		BaseFee:          big.NewInt(7),
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+		ParentBeaconRoot: &beaconRoot,
+		RequestsHash:     requestsHash,
+	}
+	withdrawals := []*ethTypes.Withdrawal{{Index: 1, Validator: 2, Address: to, Amount: 3}}
+	body := &ethTypes.Body{Transactions: ethTypes.Transactions{tx}, Withdrawals: withdrawals}
+	return ethTypes.NewBlock(header, body, nil, trie.NewStackTrie(nil)), []bellatrix.Transaction{txBytes}
+}
+
+// denebPayload returns the execution block as payload
+func denebPayload(block *ethTypes.Block, txs []bellatrix.Transaction) *deneb.ExecutionPayload {
+	header := block.Header()
+	withdrawals := make([]*capella.Withdrawal, 0, len(block.Withdrawals()))
+	for _, withdrawal := range block.Withdrawals() {
+		withdrawals = append(withdrawals, &capella.Withdrawal{
+			Index:          capella.WithdrawalIndex(withdrawal.Index),
+			ValidatorIndex: phase0.ValidatorIndex(withdrawal.Validator),
+			Address:        bellatrix.ExecutionAddress(withdrawal.Address),
+			Amount:         phase0.Gwei(withdrawal.Amount),
+		})
+	}
+	return &deneb.ExecutionPayload{
+		ParentHash:    phase0.Hash32(header.ParentHash),
+		FeeRecipient:  bellatrix.ExecutionAddress(header.Coinbase),
+		StateRoot:     phase0.Root(header.Root),
+		ReceiptsRoot:  phase0.Root(header.ReceiptHash),
+		LogsBloom:     header.Bloom,
+		PrevRandao:    header.MixDigest,
+		BlockNumber:   header.Number.Uint64(),
+		GasLimit:      header.GasLimit,
+		GasUsed:       header.GasUsed,
+		Timestamp:     header.Time,
+		ExtraData:     header.Extra,
+		BaseFeePerGas: uint256.MustFromBig(header.BaseFee),
+		BlockHash:     phase0.Hash32(block.Hash()),
+		Transactions:  txs,
+		Withdrawals:   withdrawals,
+		BlobGasUsed:   *header.BlobGasUsed,
+		ExcessBlobGas: *header.ExcessBlobGas,
+	}
+}
+
+// denebBlindedBlock returns the signed blinded block of the payload
+func denebBlindedBlock(t *testing.T, block *ethTypes.Block, payload *deneb.ExecutionPayload) *eth2ApiV1Deneb.SignedBlindedBeaconBlock {
+	t.Helper()
+	txsRoot, err := (&eth2UtilBellatrix.ExecutionPayloadTransactions{Transactions: payload.Transactions}).HashTreeRoot()
+	require.NoError(t, err)
+	withdrawalsRoot, err := (&eth2UtilCapella.ExecutionPayloadWithdrawals{Withdrawals: payload.Withdrawals}).HashTreeRoot()
+	require.NoError(t, err)
+	return &eth2ApiV1Deneb.SignedBlindedBeaconBlock{Message: &eth2ApiV1Deneb.BlindedBeaconBlock{
+		ParentRoot: phase0.Root(*block.BeaconRoot()),
+		Body: &eth2ApiV1Deneb.BlindedBeaconBlockBody{ExecutionPayloadHeader: &deneb.ExecutionPayloadHeader{
+			BlockHash:        payload.BlockHash,
+			TransactionsRoot: txsRoot,
+			WithdrawalsRoot:  withdrawalsRoot,
+		}},
+	}}
+}
+
+func TestPayloadBlockHash(t *testing.T) {
+	t.Run("Deneb", func(t *testing.T) {
+		block, txs := testExecutionBlock(t, nil)
+		payload := denebPayload(block, txs)
+		blindedBlock := denebBlindedBlock(t, block, payload)
+		response := &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionDeneb,
+			Deneb:   &builderApiDeneb.ExecutionPayloadAndBlobsBundle{ExecutionPayload: payload},
+		}
+		require.NoError(t, verifyPayloadBlockHash(blindedBlock, response))
+
+		// A payload with other transactions doesn't match the block hash
+		response.Deneb.ExecutionPayload.Transactions = nil
+		require.ErrorIs(t, verifyPayloadBlockHash(blindedBlock, response), errPayloadBlockHash)
+	})
+
+	t.Run("Electra", func(t *testing.T) {
+		requests := &electra.ExecutionRequests{
+			Withdrawals: []*electra.WithdrawalRequest{{Amount: 32_000_000_000}},
+		}
+		withdrawalRequest, err := requests.Withdrawals[0].MarshalSSZ()
+		require.NoError(t, err)
+		requestsHash := ethTypes.CalcRequestsHash([][]byte{append([]byte{withdrawalRequestType}, withdrawalRequest...)})
+		block, txs := testExecutionBlock(t, &requestsHash)
+
+		blindedBlock := &eth2ApiV1Electra.SignedBlindedBeaconBlock{Message: &eth2ApiV1Electra.BlindedBeaconBlock{
+			ParentRoot: phase0.Root(*block.BeaconRoot()),
+			Body: &eth2ApiV1Electra.BlindedBeaconBlockBody{
+				ExecutionPayloadHeader: &deneb.ExecutionPayloadHeader{BlockHash: phase0.Hash32(block.Hash())},
+				ExecutionRequests:      requests,
+			},
+		}}
+		response := &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{ExecutionPayload: denebPayload(block, txs)},
+		}
+		require.NoError(t, verifyPayloadBlockHash(blindedBlock, response))
+
+		// Fulu blocks have the same block hash
+		fuluResponse := &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionFulu,
+			Fulu:    &builderApiFulu.ExecutionPayloadAndBlobsBundle{ExecutionPayload: response.Electra.ExecutionPayload},
+		}
+		require.NoError(t, verifyPayloadBlockHash(&fuluSignedBlindedBeaconBlock{blindedBlock}, fuluResponse))
+
+		// The execution requests are part of the block hash
+		requests.Withdrawals = nil
+		require.ErrorIs(t, verifyPayloadBlockHash(blindedBlock, response), errPayloadBlockHash)
+		require.ErrorIs(t, verifyPayloadBlockHash(&fuluSignedBlindedBeaconBlock{blindedBlock}, fuluResponse), errPayloadBlockHash)
+	})
+
+	t.Run("Bellatrix", func(t *testing.T) {
+		block, txs := testExecutionBlock(t, nil)
+		header := block.Header()
+		header.WithdrawalsHash, header.BlobGasUsed, header.ExcessBlobGas, header.ParentBeaconRoot = nil, nil, nil, nil
+		payload := denebPayload(block, txs)
+		baseFee := [32]byte{7} // little-endian
+		blindedBlock := &eth2ApiV1Bellatrix.SignedBlindedBeaconBlock{Message: &eth2ApiV1Bellatrix.BlindedBeaconBlock{
+			Body: &eth2ApiV1Bellatrix.BlindedBeaconBlockBody{ExecutionPayloadHeader: &bellatrix.ExecutionPayloadHeader{BlockHash: phase0.Hash32(header.Hash())}},
+		}}
+		response := &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionBellatrix,
+			Bellatrix: &bellatrix.ExecutionPayload{
+				ParentHash:    payload.ParentHash,
+				FeeRecipient:  payload.FeeRecipient,
+				StateRoot:     payload.StateRoot,
+				ReceiptsRoot:  payload.ReceiptsRoot,
+				LogsBloom:     payload.LogsBloom,
+				PrevRandao:    payload.PrevRandao,
+				BlockNumber:   payload.BlockNumber,
+				GasLimit:      payload.GasLimit,
+				GasUsed:       payload.GasUsed,
+				Timestamp:     payload.Timestamp,
+				ExtraData:     payload.ExtraData,
+				BaseFeePerGas: baseFee,
+				Transactions:  txs,
+			},
+		}
+		require.NoError(t, verifyPayloadBlockHash(blindedBlock, response))
+	})
+}
+
+func TestPayloadRoots(t *testing.T) {
+	block, txs := testExecutionBlock(t, nil)
+	payload := denebPayload(block, txs)
+	blindedBlock := denebBlindedBlock(t, block, payload)
+	response := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionDeneb,
+		Deneb:   &builderApiDeneb.ExecutionPayloadAndBlobsBundle{ExecutionPayload: payload},
+	}
+	require.NoError(t, verifyPayloadRoots(blindedBlock, response))
+
+	payload.Withdrawals = nil
+	require.ErrorIs(t, verifyPayloadRoots(blindedBlock, response), errPayloadWithdrawalsRoot)
+
+	payload.Transactions = append(payload.Transactions, bellatrix.Transaction{0x02})
+	require.ErrorIs(t, verifyPayloadRoots(blindedBlock, response), errPayloadTransactionsRoot)
+}
+
+func TestCheckPayload(t *testing.T) {
+	block, txs := testExecutionBlock(t, nil)
+	payload := denebPayload(block, txs)
+	blindedBlock := denebBlindedBlock(t, block, payload)
+	response := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionDeneb,
+		Deneb:   &builderApiDeneb.ExecutionPayloadAndBlobsBundle{ExecutionPayload: payload},
+	}
+	require.NoError(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject))
+
+	// A payload with the transactions and withdrawals of the header, but another state root
+	payload.StateRoot = phase0.Root{0x04}
+	require.ErrorIs(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject), errPayloadBlockHash)
+	require.NoError(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckWarn))
+	require.NoError(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckOff))
+
+	_, err := NewBoostService(BoostServiceOpts{
+		Log:                   mock.TestLog,
+		Relays:                []types.RelayEntry{mock.NewRelay(t).RelayEntry},
+		GenesisForkVersionHex: "0x00000000",
+		PayloadCheck:          "strict",
+	})
+	require.ErrorIs(t, err, errUnknownPayloadCheck)
+}
+
+func TestVerifyExecutionRequests(t *testing.T) {
+	requests := func(withdrawals int) *electra.ExecutionRequests {
+		r := &electra.ExecutionRequests{}
+		for i := range withdrawals {
+			r.Withdrawals = append(r.Withdrawals, &electra.WithdrawalRequest{Amount: phase0.Gwei(i)})
+		}
+		return r
+	}
+	blindedBlock := func(r *electra.ExecutionRequests) *eth2ApiV1Electra.SignedBlindedBeaconBlock {
+		return &eth2ApiV1Electra.SignedBlindedBeaconBlock{Message: &eth2ApiV1Electra.BlindedBeaconBlock{
+			Body: &eth2ApiV1Electra.BlindedBeaconBlockBody{ExecutionRequests: r},
+		}}
+	}
+	bid := func(r *electra.ExecutionRequests) builderSpec.VersionedSignedBuilderBid {
+		return builderSpec.VersionedSignedBuilderBid{
+			Version: spec.DataVersionElectra,
+			Electra: &builderApiElectra.SignedBuilderBid{Message: &builderApiElectra.BuilderBid{ExecutionRequests: r}},
+		}
+	}
+
+	require.NoError(t, verifyExecutionRequests(blindedBlock(requests(2)), bid(requests(2))))
+	require.ErrorIs(t, verifyExecutionRequests(blindedBlock(requests(1)), bid(requests(2))), errExecutionRequests)
+	require.ErrorIs(t, verifyExecutionRequests(blindedBlock(nil), bid(requests(0))), errExecutionRequests)
+
+	// More requests than allowed
+	require.ErrorIs(t, verifyExecutionRequests(blindedBlock(requests(17)), builderSpec.VersionedSignedBuilderBid{}), errExecutionRequests)
+
+	// Without a bid, there is nothing to compare with
+	require.NoError(t, verifyExecutionRequests(blindedBlock(requests(2)), builderSpec.VersionedSignedBuilderBid{}))
+}
+
+// executionPayloadHeader returns the header of the payload, as signed in a blinded block
+func executionPayloadHeader(t *testing.T, payload *deneb.ExecutionPayload) *deneb.ExecutionPayloadHeader {
+	t.Helper()
+	txsRoot, err := (&eth2UtilBellatrix.ExecutionPayloadTransactions{Transactions: payload.Transactions}).HashTreeRoot()
+	require.NoError(t, err)
+	withdrawalsRoot, err := (&eth2UtilCapella.ExecutionPayloadWithdrawals{Withdrawals: payload.Withdrawals}).HashTreeRoot()
+	require.NoError(t, err)
+	return &deneb.ExecutionPayloadHeader{
+		ParentHash:       payload.ParentHash,
+		FeeRecipient:     payload.FeeRecipient,
+		StateRoot:        payload.StateRoot,
+		ReceiptsRoot:     payload.ReceiptsRoot,
+		LogsBloom:        payload.LogsBloom,
+		PrevRandao:       payload.PrevRandao,
+		BlockNumber:      payload.BlockNumber,
+		GasLimit:         payload.GasLimit,
+		GasUsed:          payload.GasUsed,
+		Timestamp:        payload.Timestamp,
+		ExtraData:        payload.ExtraData,
+		BaseFeePerGas:    payload.BaseFeePerGas,
+		BlockHash:        payload.BlockHash,
+		TransactionsRoot: txsRoot,
+		WithdrawalsRoot:  withdrawalsRoot,
+		BlobGasUsed:      payload.BlobGasUsed,
+		ExcessBlobGas:    payload.ExcessBlobGas,
+	}
+}
+
+// mainnetDenebBlock returns the blinded block and the getPayload response of Deneb mainnet block 8631513
+func mainnetDenebBlock(t *testing.T) (*eth2ApiV1Deneb.SignedBlindedBeaconBlock, *builderApi.VersionedSubmitBlindedBlockResponse) {
+	t.Helper()
+	jsonFile, err := os.Open("../testdata/beacon-block-deneb-mainnet-8631513.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	block := new(deneb.BeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, block))
+
+	body := block.Body
+	blindedBlock := &eth2ApiV1Deneb.SignedBlindedBeaconBlock{Message: &eth2ApiV1Deneb.BlindedBeaconBlock{
+		Slot:          block.Slot,
+		ProposerIndex: block.ProposerIndex,
+		ParentRoot:    block.ParentRoot,
+		StateRoot:     block.StateRoot,
+		Body: &eth2ApiV1Deneb.BlindedBeaconBlockBody{
+			RANDAOReveal:           body.RANDAOReveal,
+			ETH1Data:               body.ETH1Data,
+			Graffiti:               body.Graffiti,
+			ProposerSlashings:      body.ProposerSlashings,
+			AttesterSlashings:      body.AttesterSlashings,
+			Attestations:           body.Attestations,
+			Deposits:               body.Deposits,
+			VoluntaryExits:         body.VoluntaryExits,
+			SyncAggregate:          body.SyncAggregate,
+			ExecutionPayloadHeader: executionPayloadHeader(t, body.ExecutionPayload),
+			BLSToExecutionChanges:  body.BLSToExecutionChanges,
+			BlobKZGCommitments:     body.BlobKZGCommitments,
+		},
+	}}
+	response := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionDeneb,
+		Deneb: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: body.ExecutionPayload,
+			BlobsBundle: &builderApiDeneb.BlobsBundle{
+				Commitments: body.BlobKZGCommitments,
+				Proofs:      make([]deneb.KZGProof, len(body.BlobKZGCommitments)),
+				Blobs:       make([]deneb.Blob, len(body.BlobKZGCommitments)),
+			},
+		},
+	}
+	return blindedBlock, response
+}
+
+// devnetElectraBlock returns the blinded block and the getPayload response of an Electra block of a Pectra devnet
+func devnetElectraBlock(t *testing.T) (*eth2ApiV1Electra.SignedBlindedBeaconBlock, *builderApi.VersionedSubmitBlindedBlockResponse) {
+	t.Helper()
+	jsonFile, err := os.Open("../testdata/signed-beacon-block-electra-devnet-99329.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	block := new(electra.SignedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, block))
+
+	body := block.Message.Body
+	blindedBlock := &eth2ApiV1Electra.SignedBlindedBeaconBlock{
+		Message: &eth2ApiV1Electra.BlindedBeaconBlock{
+			Slot:          block.Message.Slot,
+			ProposerIndex: block.Message.ProposerIndex,
+			ParentRoot:    block.Message.ParentRoot,
+			StateRoot:     block.Message.StateRoot,
+			Body: &eth2ApiV1Electra.BlindedBeaconBlockBody{
+				RANDAOReveal:           body.RANDAOReveal,
+				ETH1Data:               body.ETH1Data,
+				Graffiti:               body.Graffiti,
+				ProposerSlashings:      body.ProposerSlashings,
+				AttesterSlashings:      body.AttesterSlashings,
+				Attestations:           body.Attestations,
+				Deposits:               body.Deposits,
+				VoluntaryExits:         body.VoluntaryExits,
+				SyncAggregate:          body.SyncAggregate,
+				ExecutionPayloadHeader: executionPayloadHeader(t, body.ExecutionPayload),
+				BLSToExecutionChanges:  body.BLSToExecutionChanges,
+				BlobKZGCommitments:     body.BlobKZGCommitments,
+				ExecutionRequests:      body.ExecutionRequests,
+			},
+		},
+		Signature: block.Signature,
+	}
+	response := &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionElectra,
+		Electra: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: body.ExecutionPayload,
+			BlobsBundle: &builderApiDeneb.BlobsBundle{
+				Commitments: body.BlobKZGCommitments,
+				Proofs:      make([]deneb.KZGProof, len(body.BlobKZGCommitments)),
+				Blobs:       make([]deneb.Blob, len(body.BlobKZGCommitments)),
+			},
+		},
+	}
+	return blindedBlock, response
+}
+
+// fuluResponse returns the Electra response as Fulu response, the payload and the block hash are the same since Fulu
+// only changes the blobs bundle
+func fuluResponse(response *builderApi.VersionedSubmitBlindedBlockResponse) *builderApi.VersionedSubmitBlindedBlockResponse {
+	return &builderApi.VersionedSubmitBlindedBlockResponse{
+		Version: spec.DataVersionFulu,
+		Fulu: &builderApiFulu.ExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: response.Electra.ExecutionPayload,
+			BlobsBundle: &builderApiFulu.BlobsBundle{
+				Commitments: response.Electra.BlobsBundle.Commitments,
+				Proofs:      make([]deneb.KZGProof, len(response.Electra.BlobsBundle.Commitments)*cellsPerExtBlob),
+				Blobs:       response.Electra.BlobsBundle.Blobs,
+			},
+		},
+	}
+}
+
+func TestCheckPayloadChainBlocks(t *testing.T) {
+	t.Run("Deneb mainnet", func(t *testing.T) {
+		blindedBlock, response := mainnetDenebBlock(t)
+		require.NotEmpty(t, response.Deneb.ExecutionPayload.Transactions)
+		require.NotEmpty(t, response.Deneb.ExecutionPayload.Withdrawals)
+		require.NoError(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject))
+
+		// The parent beacon block root is part of the block hash
+		blindedBlock.Message.ParentRoot = phase0.Root{0x01}
+		require.ErrorIs(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject), errPayloadBlockHash)
+	})
+
+	t.Run("Electra devnet", func(t *testing.T) {
+		blindedBlock, response := devnetElectraBlock(t)
+		require.NotEmpty(t, response.Electra.ExecutionPayload.Transactions)
+		require.NotEmpty(t, response.Electra.ExecutionPayload.Withdrawals)
+		require.NoError(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject))
+
+		// The execution requests are part of the block hash
+		blindedBlock.Message.Body.ExecutionRequests.Withdrawals = []*electra.WithdrawalRequest{{Amount: 1}}
+		require.ErrorIs(t, checkPayload(mock.TestLog, blindedBlock, response, builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject), errPayloadBlockHash)
+	})
+
+	t.Run("Fulu", func(t *testing.T) {
+		blindedBlock, response := devnetElectraBlock(t)
+		require.NoError(t, checkPayload(mock.TestLog, &fuluSignedBlindedBeaconBlock{blindedBlock}, fuluResponse(response), builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject))
+
+		response.Electra.ExecutionPayload.Transactions = response.Electra.ExecutionPayload.Transactions[1:]
+		require.ErrorIs(t, checkPayload(mock.TestLog, &fuluSignedBlindedBeaconBlock{blindedBlock}, fuluResponse(response), builderSpec.VersionedSignedBuilderBid{}, PayloadCheckReject), errPayloadTransactionsRoot)
+	})
+}
+
+func TestGetPayloadPayloadCheck(t *testing.T) {
+	getPayload := func(t *testing.T, backend *testBackend, blindedBlock any, consensusVersion string) *httptest.ResponseRecorder {
+		t.Helper()
+		body, err := json.Marshal(blindedBlock)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, params.PathGetPayload, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(HeaderEthConsensusVersion, consensusVersion)
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Chain blocks with the default payload check", func(t *testing.T) {
+		denebBlock, denebResponse := mainnetDenebBlock(t)
+		electraBlock, electraResponse := devnetElectraBlock(t)
+		for _, tc := range []struct {
+			version      string
+			blindedBlock any
+			response     *builderApi.VersionedSubmitBlindedBlockResponse
+		}{
+			{"deneb", denebBlock, denebResponse},
+			{"electra", electraBlock, electraResponse},
+			{"fulu", electraBlock, fuluResponse(electraResponse)},
+		} {
+			t.Run(tc.version, func(t *testing.T) {
+				backend := newTestBackend(t, 1, time.Second)
+				require.Equal(t, PayloadCheckWarn, backend.boost.payloadCheck)
+				backend.relays[0].GetPayloadResponse = tc.response
+				rr := getPayload(t, backend, tc.blindedBlock, tc.version)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				resp := new(builderApi.VersionedSubmitBlindedBlockResponse)
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+				require.Equal(t, tc.response.Version, resp.Version)
+
+				// The payloads pass the check, they aren't just used with a warning
+				backend = newTestBackend(t, 1, time.Second)
+				backend.boost.payloadCheck = PayloadCheckReject
+				backend.relays[0].GetPayloadResponse = tc.response
+				rr = getPayload(t, backend, tc.blindedBlock, tc.version)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			})
+		}
+	})
+
+	t.Run("Rejected payload", func(t *testing.T) {
+		blindedBlock, response := mainnetDenebBlock(t)
+		response.Deneb.ExecutionPayload.StateRoot = phase0.Root{0x01}
+
+		// The default uses the payload with a warning
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = response
+		rr := getPayload(t, backend, blindedBlock, "deneb")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		backend = newTestBackend(t, 1, time.Second)
+		backend.boost.payloadCheck = PayloadCheckReject
+		backend.relays[0].GetPayloadResponse = response
+		rr = getPayload(t, backend, blindedBlock, "deneb")
+		requireRelayFailures(t, rr, backend.relays...)
+	})
+}
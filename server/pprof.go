@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/flashbots/mev-boost/config"
+)
+
+var errPprofNotLoopback = errors.New("pprof server must listen on a loopback address")
+
+// pprofHandler serves the runtime profiles of net/http/pprof
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// isLoopbackAddr returns true if the host of a host:port address is localhost or a loopback IP
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StartPprofServer serves the pprof debug endpoints on the configured pprof address, which must be a loopback address
+func (m *BoostService) StartPprofServer() error {
+	if !isLoopbackAddr(m.pprofAddr) {
+		return errPprofNotLoopback
+	}
+	m.serversLock.Lock()
+	if err := m.checkServerStart(m.pprofSrv != nil); err != nil {
+		m.serversLock.Unlock()
+		return err
+	}
+	srv := &http.Server{
+		Addr:    m.pprofAddr,
+		Handler: pprofHandler(),
+
+		// No write timeout, CPU profiles and traces take as long as requested
+		ReadHeaderTimeout: time.Duration(config.ServerReadHeaderTimeoutMs) * time.Millisecond,
+	}
+	m.pprofSrv = srv
+	m.serversLock.Unlock()
+
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
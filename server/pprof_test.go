@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLoopbackAddr(t *testing.T) {
+	require.True(t, isLoopbackAddr("localhost:6060"))
+	require.True(t, isLoopbackAddr("127.0.0.1:6060"))
+	require.True(t, isLoopbackAddr("[::1]:6060"))
+	require.False(t, isLoopbackAddr("0.0.0.0:6060"))
+	require.False(t, isLoopbackAddr(":6060"))
+	require.False(t, isLoopbackAddr("192.168.1.1:6060"))
+	require.False(t, isLoopbackAddr("localhost"))
+}
+
+func TestPprofServer(t *testing.T) {
+	t.Run("Rejects non-loopback address", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.pprofAddr = "0.0.0.0:6060"
+		require.ErrorIs(t, backend.boost.StartPprofServer(), errPprofNotLoopback)
+	})
+
+	t.Run("Serves profiles", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil)
+		pprofHandler().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), "goroutine profile")
+	})
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// startRelayPrewarmTask pre-warms the relay connections shortly before each slot, see prewarmRelayConnections
+func (m *BoostService) startRelayPrewarmTask() {
+	for {
+		time.Sleep(m.untilRelayPrewarm(time.Now()))
+		m.prewarmRelayConnections()
+	}
+}
+
+// untilRelayPrewarm returns how long to wait before pre-warming the relay connections, prewarmLead before the next slot
+func (m *BoostService) untilRelayPrewarm(now time.Time) time.Duration {
+	slotDuration := time.Duration(m.slotTimeSec) * time.Second
+	untilSlot := slotDuration - now.Sub(time.Unix(int64(m.genesisTime), 0))%slotDuration
+	wait := (untilSlot - m.prewarmLead) % slotDuration
+	if wait <= 0 {
+		wait += slotDuration
+	}
+	return wait
+}
+
+// prewarmRelayConnections requests the status of all relays with the getHeader client, so the first getHeader of the
+// slot reuses the connection instead of paying for the DNS lookup and the TCP and TLS handshakes. The connections of
+// the relays which closed them since the last slot are established again.
+func (m *BoostService) prewarmRelayConnections() {
+	cfg := m.currentConfig()
+	var wg sync.WaitGroup
+	for _, relay := range cfg.relays {
+		wg.Add(1)
+		go func(relay types.RelayEntry) {
+			defer wg.Done()
+			m.prewarmRelayConnection(cfg, relay)
+		}(relay)
+	}
+	wg.Wait()
+}
+
+// prewarmRelayConnection requests the status of the relay, the response doesn't matter
+func (m *BoostService) prewarmRelayConnection(cfg reloadableConfig, relay types.RelayEntry) {
+	start := time.Now()
+	url := relay.GetURI(params.PathStatus)
+	_, err := SendHTTPRequest(context.Background(), cfg.getHeaderClient(relay), http.MethodGet, url, "", nil, nil, nil)
+	log := m.log.WithFields(logrus.Fields{
+		"method":    "prewarmRelayConnection",
+		"url":       url,
+		"latencyMs": time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		log.WithError(err).Debug("could not pre-warm the relay connection")
+		return
+	}
+	log.Debug("pre-warmed the relay connection")
+}
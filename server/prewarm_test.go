@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayPrewarm(t *testing.T) {
+	t.Run("Connections are pre-warmed the lead before each slot", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.genesisTime = 1_000_000
+		backend.boost.prewarmLead = time.Second
+		genesis := time.Unix(1_000_000, 0)
+
+		require.Equal(t, 8*time.Second, backend.boost.untilRelayPrewarm(genesis.Add(5*12*time.Second+3*time.Second)))
+		require.Equal(t, 11*time.Second, backend.boost.untilRelayPrewarm(genesis.Add(5*12*time.Second)))
+		require.Equal(t, 11500*time.Millisecond, backend.boost.untilRelayPrewarm(genesis.Add(5*12*time.Second+11500*time.Millisecond)))
+	})
+
+	t.Run("All relays are requested", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.prewarmRelayConnections()
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathStatus))
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(params.PathStatus))
+	})
+
+	t.Run("getHeader reuses the pre-warmed connection", func(t *testing.T) {
+		var connections atomic.Int32
+		relay := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		relay.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				connections.Add(1)
+			}
+		}
+		relay.Start()
+		t.Cleanup(relay.Close)
+		relayURL, err := url.Parse(relay.URL)
+		require.NoError(t, err)
+		entry := types.RelayEntry{URL: relayURL}
+
+		backend := newTestBackend(t, 1, time.Second)
+		cfg := backend.boost.currentConfig()
+		backend.boost.prewarmRelayConnection(cfg, entry)
+		require.Equal(t, int32(1), connections.Load())
+
+		_, err = SendHTTPRequest(context.Background(), cfg.getHeaderClient(entry), http.MethodGet, entry.GetURI("/eth/v1/builder/header/1/0x00/0x00"), "", nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), connections.Load())
+	})
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// ReadinessResponse is the response body of the readiness probe
+type ReadinessResponse struct {
+	Ready             bool `json:"ready"`
+	ReachableRelays   int  `json:"reachable_relays"`
+	MinRelays         int  `json:"min_relays"`
+	GenesisConfigured bool `json:"genesis_configured"`
+	ShuttingDown      bool `json:"shutting_down"`
+}
+
+// handleLivez returns OK as long as the process is able to serve requests
+func (m *BoostService) handleLivez(w http.ResponseWriter, _ *http.Request) {
+	m.respondOK(w, nilResponse)
+}
+
+// handleReadyz returns OK if the genesis is configured, enough relays are reachable and mev-boost isn't shutting down.
+// Relays count as reachable if a request succeeded within the readiness max age, the relays are only checked if that
+// is not enough.
+func (m *BoostService) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	minRelays := max(m.readyMinRelays, 1)
+	reachable := m.relayHealth.numReachable(m.currentConfig().relays, m.readyRelayMaxAge)
+	if reachable < minRelays {
+		reachable = m.CheckRelays()
+	}
+
+	resp := ReadinessResponse{
+		ReachableRelays:   reachable,
+		MinRelays:         minRelays,
+		GenesisConfigured: m.genesisTime > 0,
+		ShuttingDown:      m.shutdown.draining.Load(),
+	}
+	resp.Ready = resp.GenesisConfigured && reachable >= minRelays && !resp.ShuttingDown
+
+	code := http.StatusOK
+	if !resp.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		m.log.WithError(err).Error("could not write readiness response")
+	}
+}
+
+// numReachable returns the number of relays with a successful request within maxAge
+func (s *relayHealthStore) numReachable(relays []types.RelayEntry, maxAge time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	num := 0
+	for _, relay := range relays {
+		if h, ok := s.health[relay.String()]; ok && time.Since(h.lastSuccess) <= maxAge {
+			num++
+		}
+	}
+	return num
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivez(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	backend.relays[0].Server.Close()
+
+	rr := backend.request(t, http.MethodGet, params.PathLivez, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestReadyz(t *testing.T) {
+	readyz := func(t *testing.T, backend *testBackend) (int, ReadinessResponse) {
+		t.Helper()
+		rr := backend.request(t, http.MethodGet, params.PathReadyz, nil)
+		resp := ReadinessResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return rr.Code, resp
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.genesisTime = 1606824023
+		backend.boost.readyMinRelays = 2
+		backend.boost.readyRelayMaxAge = time.Minute
+
+		code, resp := readyz(t, backend)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, ReadinessResponse{Ready: true, ReachableRelays: 2, MinRelays: 2, GenesisConfigured: true}, resp)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathStatus))
+
+		// Recent successful requests are enough, the relays are not checked again
+		code, _ = readyz(t, backend)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathStatus))
+	})
+
+	t.Run("Not enough reachable relays", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.genesisTime = 1606824023
+		backend.boost.readyMinRelays = 2
+		backend.relays[1].Server.Close()
+
+		code, resp := readyz(t, backend)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.False(t, resp.Ready)
+		require.Equal(t, 1, resp.ReachableRelays)
+	})
+
+	t.Run("Genesis not configured", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+
+		code, resp := readyz(t, backend)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.False(t, resp.GenesisConfigured)
+		require.Equal(t, 1, resp.ReachableRelays)
+	})
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	eth2ApiV1Bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
+	eth2ApiV1Capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	eth2ApiV1Fulu "github.com/attestantio/go-eth2-client/api/v1/fulu"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/sirupsen/logrus"
+)
+
+// pathPublishBlock is the beacon node endpoint publishing a signed beacon block (publishBlockV2)
+const pathPublishBlock = "/eth/v2/beacon/blocks"
+
+// publishBlockTimeout is the timeout of the block publication, the block is useless after its slot anyway
+const publishBlockTimeout = 4 * time.Second
+
+var (
+	errPublishBlockBeaconNode = errors.New("the block publication fallback needs a beacon node")
+	errPayloadVersion         = errors.New("payload version doesn't match the blinded block")
+)
+
+// responseWriteRecorder remembers whether writing the response failed
+type responseWriteRecorder struct {
+	http.ResponseWriter
+	err error
+}
+
+func (w *responseWriteRecorder) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// publishBlockIfUndelivered publishes the block with the payload through the beacon node, if enabled and the payload
+// couldn't be returned to the beacon node which requested it, so the slot isn't missed on the last hop
+func (m *BoostService) publishBlockIfUndelivered(log *logrus.Entry, req *http.Request, w *responseWriteRecorder, blindedBlock any, result deliveredPayload) {
+	if !m.publishBlockFallback || result.response == nil {
+		return
+	}
+	reason := w.err
+	if reason == nil {
+		reason = req.Context().Err()
+	}
+	if reason == nil {
+		return
+	}
+
+	log = log.WithError(reason)
+	log.Warn("could not return the payload to the beacon node, publishing the block directly")
+	code, err := m.publishBlock(blindedBlock, result.response)
+	if err != nil {
+		log.WithField("publishError", err.Error()).Error("could not publish the block")
+		return
+	}
+	log.WithField("code", code).Info("published the block through the beacon node")
+}
+
+// publishBlock sends the signed beacon block, made of the signed blinded block and its payload, to the beacon node
+func (m *BoostService) publishBlock(blindedBlock any, payload *builderApi.VersionedSubmitBlindedBlockResponse) (int, error) {
+	block, err := unblindBlock(blindedBlock, payload)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishBlockTimeout)
+	defer cancel()
+	u := m.beaconNodeURL.JoinPath(pathPublishBlock)
+	headers := map[string]string{HeaderEthConsensusVersion: payload.Version.String()}
+	return SendHTTPRequest(ctx, m.currentConfig().httpClientGetPayload, http.MethodPost, u.String(), "", headers, block, nil)
+}
+
+// unblindBlock returns the signed beacon block, or the signed block contents since Deneb, of the signed blinded block
+// with the execution payload and blobs of the relay
+func unblindBlock(blindedBlock any, payload *builderApi.VersionedSubmitBlindedBlockResponse) (any, error) {
+	if payload == nil || getPayloadResponseIsEmpty(payload) {
+		return nil, errEmptyPayload
+	}
+	switch block := blindedBlock.(type) {
+	case *eth2ApiV1Bellatrix.SignedBlindedBeaconBlock:
+		if payload.Version != spec.DataVersionBellatrix {
+			return nil, fmt.Errorf("%w: %s", errPayloadVersion, payload.Version)
+		}
+		body := block.Message.Body
+		return &bellatrix.SignedBeaconBlock{
+			Message: &bellatrix.BeaconBlock{
+				Slot:          block.Message.Slot,
+				ProposerIndex: block.Message.ProposerIndex,
+				ParentRoot:    block.Message.ParentRoot,
+				StateRoot:     block.Message.StateRoot,
+				Body: &bellatrix.BeaconBlockBody{
+					RANDAOReveal:      body.RANDAOReveal,
+					ETH1Data:          body.ETH1Data,
+					Graffiti:          body.Graffiti,
+					ProposerSlashings: body.ProposerSlashings,
+					AttesterSlashings: body.AttesterSlashings,
+					Attestations:      body.Attestations,
+					Deposits:          body.Deposits,
+					VoluntaryExits:    body.VoluntaryExits,
+					SyncAggregate:     body.SyncAggregate,
+					ExecutionPayload:  payload.Bellatrix,
+				},
+			},
+			Signature: block.Signature,
+		}, nil
+	case *eth2ApiV1Capella.SignedBlindedBeaconBlock:
+		if payload.Version != spec.DataVersionCapella {
+			return nil, fmt.Errorf("%w: %s", errPayloadVersion, payload.Version)
+		}
+		body := block.Message.Body
+		return &capella.SignedBeaconBlock{
+			Message: &capella.BeaconBlock{
+				Slot:          block.Message.Slot,
+				ProposerIndex: block.Message.ProposerIndex,
+				ParentRoot:    block.Message.ParentRoot,
+				StateRoot:     block.Message.StateRoot,
+				Body: &capella.BeaconBlockBody{
+					RANDAOReveal:          body.RANDAOReveal,
+					ETH1Data:              body.ETH1Data,
+					Graffiti:              body.Graffiti,
+					ProposerSlashings:     body.ProposerSlashings,
+					AttesterSlashings:     body.AttesterSlashings,
+					Attestations:          body.Attestations,
+					Deposits:              body.Deposits,
+					VoluntaryExits:        body.VoluntaryExits,
+					SyncAggregate:         body.SyncAggregate,
+					ExecutionPayload:      payload.Capella,
+					BLSToExecutionChanges: body.BLSToExecutionChanges,
+				},
+			},
+			Signature: block.Signature,
+		}, nil
+	case *eth2ApiV1Deneb.SignedBlindedBeaconBlock:
+		if payload.Version != spec.DataVersionDeneb {
+			return nil, fmt.Errorf("%w: %s", errPayloadVersion, payload.Version)
+		}
+		body := block.Message.Body
+		return &eth2ApiV1Deneb.SignedBlockContents{
+			SignedBlock: &deneb.SignedBeaconBlock{
+				Message: &deneb.BeaconBlock{
+					Slot:          block.Message.Slot,
+					ProposerIndex: block.Message.ProposerIndex,
+					ParentRoot:    block.Message.ParentRoot,
+					StateRoot:     block.Message.StateRoot,
+					Body: &deneb.BeaconBlockBody{
+						RANDAOReveal:          body.RANDAOReveal,
+						ETH1Data:              body.ETH1Data,
+						Graffiti:              body.Graffiti,
+						ProposerSlashings:     body.ProposerSlashings,
+						AttesterSlashings:     body.AttesterSlashings,
+						Attestations:          body.Attestations,
+						Deposits:              body.Deposits,
+						VoluntaryExits:        body.VoluntaryExits,
+						SyncAggregate:         body.SyncAggregate,
+						ExecutionPayload:      payload.Deneb.ExecutionPayload,
+						BLSToExecutionChanges: body.BLSToExecutionChanges,
+						BlobKZGCommitments:    body.BlobKZGCommitments,
+					},
+				},
+				Signature: block.Signature,
+			},
+			KZGProofs: payload.Deneb.BlobsBundle.Proofs,
+			Blobs:     payload.Deneb.BlobsBundle.Blobs,
+		}, nil
+	case *eth2ApiV1Electra.SignedBlindedBeaconBlock:
+		return unblindElectraBlock(block, payload)
+	default:
+		return nil, errInvalidVersion
+	}
+}
+
+// unblindElectraBlock returns the signed block contents of an Electra or a Fulu signed blinded block, the version of
+// the payload decides
+func unblindElectraBlock(block *eth2ApiV1Electra.SignedBlindedBeaconBlock, payload *builderApi.VersionedSubmitBlindedBlockResponse) (any, error) {
+	body := block.Message.Body
+	signedBlock := func(executionPayload *deneb.ExecutionPayload) *electra.SignedBeaconBlock {
+		return &electra.SignedBeaconBlock{
+			Message: &electra.BeaconBlock{
+				Slot:          block.Message.Slot,
+				ProposerIndex: block.Message.ProposerIndex,
+				ParentRoot:    block.Message.ParentRoot,
+				StateRoot:     block.Message.StateRoot,
+				Body: &electra.BeaconBlockBody{
+					RANDAOReveal:          body.RANDAOReveal,
+					ETH1Data:              body.ETH1Data,
+					Graffiti:              body.Graffiti,
+					ProposerSlashings:     body.ProposerSlashings,
+					AttesterSlashings:     body.AttesterSlashings,
+					Attestations:          body.Attestations,
+					Deposits:              body.Deposits,
+					VoluntaryExits:        body.VoluntaryExits,
+					SyncAggregate:         body.SyncAggregate,
+					ExecutionPayload:      executionPayload,
+					BLSToExecutionChanges: body.BLSToExecutionChanges,
+					BlobKZGCommitments:    body.BlobKZGCommitments,
+					ExecutionRequests:     body.ExecutionRequests,
+				},
+			},
+			Signature: block.Signature,
+		}
+	}
+
+	switch payload.Version {
+	case spec.DataVersionElectra:
+		return &eth2ApiV1Electra.SignedBlockContents{
+			SignedBlock: signedBlock(payload.Electra.ExecutionPayload),
+			KZGProofs:   payload.Electra.BlobsBundle.Proofs,
+			Blobs:       payload.Electra.BlobsBundle.Blobs,
+		}, nil
+	case spec.DataVersionFulu:
+		return &eth2ApiV1Fulu.SignedBlockContents{
+			SignedBlock: signedBlock(payload.Fulu.ExecutionPayload),
+			KZGProofs:   payload.Fulu.BlobsBundle.Proofs,
+			Blobs:       payload.Fulu.BlobsBundle.Blobs,
+		}, nil
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair, spec.DataVersionBellatrix,
+		spec.DataVersionCapella, spec.DataVersionDeneb:
+		return nil, fmt.Errorf("%w: %s", errPayloadVersion, payload.Version)
+	}
+	return nil, fmt.Errorf("%w: %s", errPayloadVersion, payload.Version)
+}
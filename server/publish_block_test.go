@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishBlockFallback(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+	body, err := json.Marshal(signedBlindedBeaconBlock)
+	require.NoError(t, err)
+
+	// setup returns a backend and the blocks published to its beacon node
+	setup := func(t *testing.T) (*testBackend, chan *eth2ApiV1Deneb.SignedBlockContents) {
+		t.Helper()
+		published := make(chan *eth2ApiV1Deneb.SignedBlockContents, 1)
+		beaconNode := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != pathPublishBlock {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			require.Equal(t, "deneb", r.Header.Get(HeaderEthConsensusVersion))
+			contents := new(eth2ApiV1Deneb.SignedBlockContents)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(contents))
+			published <- contents
+		}))
+		t.Cleanup(beaconNode.Close)
+		u, err := url.Parse(beaconNode.URL)
+		require.NoError(t, err)
+
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.beaconNodeURL = u
+		backend.boost.publishBlockFallback = true
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		return backend, published
+	}
+
+	t.Run("The block is published if the beacon node is gone", func(t *testing.T) {
+		backend, published := setup(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, params.PathGetPayload, bytes.NewReader(body))
+		require.NoError(t, err)
+		backend.boost.getRouter().ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Len(t, published, 1)
+		contents := <-published
+		require.Equal(t, signedBlindedBeaconBlock.Message.Slot, contents.SignedBlock.Message.Slot)
+		require.Equal(t, signedBlindedBeaconBlock.Signature, contents.SignedBlock.Signature)
+		require.Equal(t, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash, contents.SignedBlock.Message.Body.ExecutionPayload.BlockHash)
+		require.Equal(t, signedBlindedBeaconBlock.Message.Body.BlobKZGCommitments, contents.SignedBlock.Message.Body.BlobKZGCommitments)
+	})
+
+	t.Run("The block isn't published if the payload was returned", func(t *testing.T) {
+		backend, published := setup(t)
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Empty(t, published)
+	})
+
+	t.Run("The fallback needs a beacon node", func(t *testing.T) {
+		_, err := NewBoostService(BoostServiceOpts{
+			Log:                   mock.TestLog,
+			Relays:                []types.RelayEntry{mock.NewRelay(t).RelayEntry},
+			GenesisForkVersionHex: "0x00000000",
+			PublishBlockFallback:  true,
+		})
+		require.ErrorIs(t, err, errPublishBlockBeaconNode)
+	})
+}
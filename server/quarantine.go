@@ -0,0 +1,125 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// EventRelayQuarantined is emitted if a relay is quarantined for serving invalid data
+const EventRelayQuarantined EventType = "relay_quarantined"
+
+// quarantineFaults are the relay errors which mean a relay serves invalid data, rather than being down
+var quarantineFaults = map[relayErrorClass]bool{
+	relayErrorBadSignature:    true,
+	relayErrorParentHash:      true,
+	relayErrorTimestamp:       true,
+	relayErrorBlobMismatch:    true,
+	relayErrorPayloadMismatch: true,
+}
+
+var relayQuarantines = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "relay_quarantines_total",
+	Help:      "Number of times the relay was quarantined for serving invalid data",
+}, []string{"relay"})
+
+func init() {
+	metricsRegistry.MustRegister(relayQuarantines)
+}
+
+// quarantinedRelay is the quarantine state of a relay
+type quarantinedRelay struct {
+	faults int       // consecutive faults
+	until  time.Time // end of the quarantine, if quarantined
+}
+
+// relayQuarantine stops asking relays for bids for a while after too many consecutive faults, i.e. bids with invalid
+// signatures or wrong parent hashes, or payloads with invalid blobs. Valid responses reset the faults.
+type relayQuarantine struct {
+	mu        sync.Mutex
+	threshold int
+	duration  time.Duration
+	relays    map[string]*quarantinedRelay
+}
+
+func newRelayQuarantine(threshold int, duration time.Duration) *relayQuarantine {
+	return &relayQuarantine{
+		threshold: threshold,
+		duration:  duration,
+		relays:    make(map[string]*quarantinedRelay),
+	}
+}
+
+// fault records a fault of the relay and returns the end of the quarantine, if the relay is quarantined because of it
+func (q *relayQuarantine) fault(relay types.RelayEntry) (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.relays[relay.String()]
+	if !ok {
+		r = new(quarantinedRelay)
+		q.relays[relay.String()] = r
+	}
+	if time.Now().Before(r.until) {
+		return time.Time{}, false
+	}
+	r.faults++
+	if r.faults < q.threshold {
+		return time.Time{}, false
+	}
+	r.faults = 0
+	r.until = time.Now().Add(q.duration)
+	return r.until, true
+}
+
+// valid resets the faults of the relay after a valid response
+func (q *relayQuarantine) valid(relay types.RelayEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if r, ok := q.relays[relay.String()]; ok {
+		r.faults = 0
+	}
+}
+
+// quarantinedUntil returns the end of the quarantine of the relay, false if it's not quarantined
+func (q *relayQuarantine) quarantinedUntil(relay types.RelayEntry) (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.relays[relay.String()]
+	if !ok || !time.Now().Before(r.until) {
+		return time.Time{}, false
+	}
+	return r.until, true
+}
+
+// recordRelayFault counts a fault of the relay, and quarantines it with an alert through the log, metrics and events
+// (webhooks) after too many faults
+func (m *BoostService) recordRelayFault(relay types.RelayEntry, class relayErrorClass) {
+	if m.quarantine == nil || !quarantineFaults[class] {
+		return
+	}
+	until, quarantined := m.quarantine.fault(relay)
+	if !quarantined {
+		return
+	}
+
+	relayQuarantines.WithLabelValues(relayLabel(relay)).Inc()
+	m.metricsSink.Count("relay_quarantines", relayMetricTags(relay, map[string]string{}))
+	m.log.WithFields(logrus.Fields{
+		"alert":    "RELAY_QUARANTINED",
+		"severity": severityError,
+		"relay":    relay.GetURI(""),
+		"fault":    class,
+		"until":    until.UTC().Format(time.RFC3339),
+	}).Error("ALERT: relay serves invalid data, not asking it for bids until the quarantine ends")
+
+	m.emitEvent(Event{
+		Type:     EventRelayQuarantined,
+		Severity: severityError,
+		Relays:   []string{relay.GetURI("")},
+		Error:    string(class),
+	})
+}
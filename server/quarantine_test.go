@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayQuarantine(t *testing.T) {
+	relay := mock.NewRelay(t).RelayEntry
+
+	t.Run("Quarantines after consecutive faults", func(t *testing.T) {
+		quarantine := newRelayQuarantine(3, time.Hour)
+		quarantine.fault(relay)
+		quarantine.fault(relay)
+		quarantine.valid(relay)
+		quarantine.fault(relay)
+		_, quarantined := quarantine.fault(relay)
+		require.False(t, quarantined)
+		_, ok := quarantine.quarantinedUntil(relay)
+		require.False(t, ok)
+
+		until, quarantined := quarantine.fault(relay)
+		require.True(t, quarantined)
+		require.WithinDuration(t, time.Now().Add(time.Hour), until, time.Minute)
+		quarantinedUntil, ok := quarantine.quarantinedUntil(relay)
+		require.True(t, ok)
+		require.Equal(t, until, quarantinedUntil)
+
+		// Faults during the quarantine don't extend it
+		_, quarantined = quarantine.fault(relay)
+		require.False(t, quarantined)
+	})
+
+	t.Run("Quarantine expires", func(t *testing.T) {
+		quarantine := newRelayQuarantine(1, 10*time.Millisecond)
+		_, quarantined := quarantine.fault(relay)
+		require.True(t, quarantined)
+		time.Sleep(20 * time.Millisecond)
+		_, ok := quarantine.quarantinedUntil(relay)
+		require.False(t, ok)
+	})
+
+	t.Run("Skips quarantined relays in getHeader", func(t *testing.T) {
+		hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+		pubkey := mock.HexToPubkey(
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+		path := getHeaderPath(1, hash, pubkey)
+
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.quarantine = newRelayQuarantine(2, time.Hour)
+
+		// Relay 0 bids on the wrong parent hash
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			20000,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		for range 2 {
+			rr := backend.request(t, http.MethodGet, path, nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		_, ok := backend.boost.quarantine.quarantinedUntil(backend.relays[0].RelayEntry)
+		require.True(t, ok)
+		_, ok = backend.boost.quarantine.quarantinedUntil(backend.relays[1].RelayEntry)
+		require.False(t, ok)
+
+		// A quarantined relay is not queried
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(path))
+		require.Equal(t, 3, backend.relays[1].GetRequestCount(path))
+	})
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rateLimitCleanupInterval is how often the rate limits of clients without recent requests are dropped
+const rateLimitCleanupInterval = time.Minute
+
+var (
+	errRateLimited = errors.New("too many requests")
+	errLoadShedded = errors.New("registerValidator requests are refused while getHeader or getPayload requests are served")
+)
+
+var requestsRefused = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "requests_refused_total",
+	Help:      "Number of builder API requests refused by the rate limits and the load shedding, by reason",
+}, []string{"reason"})
+
+func init() {
+	metricsRegistry.MustRegister(requestsRefused)
+}
+
+// slotCriticalPaths are the routes which are neither rate limited nor shed, the getHeader and getPayload requests of
+// the proposals
+var slotCriticalPaths = map[string]bool{
+	params.PathGetHeader:  true,
+	params.PathGetPayload: true,
+}
+
+// unlimitedPaths are the probes, which aren't rate limited so a flood of requests doesn't fail the health checks
+var unlimitedPaths = map[string]bool{
+	params.PathLivez:  true,
+	params.PathReadyz: true,
+}
+
+// tokenBucket allows rate requests per second, with a burst of one second of requests
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time, rate float64) bool {
+	burst := math.Max(rate, 1)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter limits the requests per client address and of all clients, and tracks the slot-critical requests in
+// flight for the load shedding
+type rateLimiter struct {
+	perIP        float64 // requests per second of a client address, unlimited if zero
+	global       float64 // requests per second of all clients, unlimited if zero
+	loadShedding bool    // refuse registerValidator requests while slot-critical requests are in flight
+
+	criticalInFlight atomic.Int64
+
+	mu          sync.Mutex
+	all         tokenBucket
+	clients     map[netip.Addr]*tokenBucket
+	lastCleanup time.Time
+}
+
+func newRateLimiter(perIP, global float64, loadShedding bool) *rateLimiter {
+	if perIP <= 0 && global <= 0 && !loadShedding {
+		return nil
+	}
+	return &rateLimiter{
+		perIP:        perIP,
+		global:       global,
+		loadShedding: loadShedding,
+		clients:      make(map[netip.Addr]*tokenBucket),
+	}
+}
+
+// allow returns whether the request of the client address is within the rate limits, and the refusal reason if not.
+// Requests without client address (over a unix socket) only count towards the global limit.
+func (l *rateLimiter) allow(now time.Time, addr netip.Addr, ok bool) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) > rateLimitCleanupInterval {
+		for client, bucket := range l.clients {
+			if now.Sub(bucket.last) > rateLimitCleanupInterval {
+				delete(l.clients, client)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	if l.perIP > 0 && ok {
+		bucket, found := l.clients[addr]
+		if !found {
+			bucket = new(tokenBucket)
+			l.clients[addr] = bucket
+		}
+		if !bucket.allow(now, l.perIP) {
+			return false, "rate_limit_ip"
+		}
+	}
+	if l.global > 0 && !l.all.allow(now, l.global) {
+		return false, "rate_limit_global"
+	}
+	return true, ""
+}
+
+// limitRequests is a middleware refusing requests over the rate limits with 429, and registerValidator requests with
+// 503 while getHeader or getPayload requests are served if load shedding is enabled. getHeader and getPayload requests
+// are never refused.
+func (m *BoostService) limitRequests(next http.Handler) http.Handler {
+	if m.rateLimiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var path string
+		if route := mux.CurrentRoute(req); route != nil {
+			path, _ = route.GetPathTemplate()
+		}
+		switch {
+		case slotCriticalPaths[path]:
+			m.rateLimiter.criticalInFlight.Add(1)
+			defer m.rateLimiter.criticalInFlight.Add(-1)
+		case unlimitedPaths[path]:
+		case m.rateLimiter.loadShedding && path == params.PathRegisterValidator && m.rateLimiter.criticalInFlight.Load() > 0:
+			requestsRefused.WithLabelValues("load_shedding").Inc()
+			w.Header().Set("Retry-After", "1")
+			m.respondError(w, http.StatusServiceUnavailable, errLoadShedded.Error())
+			return
+		default:
+			addrPort, err := netip.ParseAddrPort(req.RemoteAddr)
+			if ok, reason := m.rateLimiter.allow(time.Now(), addrPort.Addr().Unmap(), err == nil); !ok {
+				requestsRefused.WithLabelValues(reason).Inc()
+				m.log.WithField("remoteAddr", req.RemoteAddr).WithField("path", req.URL.Path).Debug("refused request over the rate limit")
+				w.Header().Set("Retry-After", "1")
+				m.respondError(w, http.StatusTooManyRequests, errRateLimited.Error())
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
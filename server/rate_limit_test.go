@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := new(tokenBucket)
+	require.True(t, bucket.allow(now, 2))
+	require.True(t, bucket.allow(now, 2))
+	require.False(t, bucket.allow(now, 2))
+	require.True(t, bucket.allow(now.Add(500*time.Millisecond), 2))
+	require.False(t, bucket.allow(now.Add(500*time.Millisecond), 2))
+
+	// The burst is one second of requests
+	require.True(t, bucket.allow(now.Add(time.Hour), 2))
+	require.True(t, bucket.allow(now.Add(time.Hour), 2))
+	require.False(t, bucket.allow(now.Add(time.Hour), 2))
+}
+
+func TestRateLimits(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	// request sends the request of the client address to the backend
+	request := func(backend *testBackend, method, path, remoteAddr string, payload any) *httptest.ResponseRecorder {
+		t.Helper()
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Requests of a client address over the limit are refused", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.rateLimiter = newRateLimiter(1, 0, false)
+
+		require.Equal(t, http.StatusOK, request(backend, http.MethodGet, params.PathStatus, "192.0.2.1:1234", nil).Code)
+		rr := request(backend, http.MethodGet, params.PathStatus, "192.0.2.1:1235", nil)
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.Equal(t, "1", rr.Header().Get("Retry-After"))
+		require.Equal(t, http.StatusOK, request(backend, http.MethodGet, params.PathStatus, "192.0.2.2:1234", nil).Code)
+
+		// getHeader and the probes aren't limited
+		for range 3 {
+			require.Equal(t, http.StatusOK, request(backend, http.MethodGet, getHeaderPath(1, hash, pubkey), "192.0.2.1:1234", nil).Code)
+			require.Equal(t, http.StatusOK, request(backend, http.MethodGet, params.PathLivez, "192.0.2.1:1234", nil).Code)
+		}
+	})
+
+	t.Run("Requests of all clients over the limit are refused", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.rateLimiter = newRateLimiter(0, 2, false)
+
+		require.Equal(t, http.StatusOK, request(backend, http.MethodGet, params.PathStatus, "192.0.2.1:1234", nil).Code)
+		require.Equal(t, http.StatusOK, request(backend, http.MethodGet, params.PathStatus, "192.0.2.2:1234", nil).Code)
+		require.Equal(t, http.StatusTooManyRequests, request(backend, http.MethodGet, params.PathStatus, "192.0.2.3:1234", nil).Code)
+		// Requests over a unix socket have no client address and count as well
+		require.Equal(t, http.StatusTooManyRequests, request(backend, http.MethodGet, params.PathStatus, "@", nil).Code)
+	})
+
+	t.Run("Registrations are shed while getHeader is served", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.rateLimiter = newRateLimiter(0, 0, true)
+		backend.relays[0].ResponseDelay = 200 * time.Millisecond
+		registrations := []*builderApiV1.SignedValidatorRegistration{testRegistration()}
+
+		done := make(chan int)
+		go func() {
+			done <- request(backend, http.MethodGet, getHeaderPath(1, hash, pubkey), "192.0.2.1:1234", nil).Code
+		}()
+		require.Eventually(t, func() bool {
+			return backend.boost.rateLimiter.criticalInFlight.Load() == 1
+		}, time.Second, time.Millisecond)
+		rr := request(backend, http.MethodPost, params.PathRegisterValidator, "192.0.2.1:1234", registrations)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		require.Equal(t, http.StatusOK, <-done)
+
+		backend.relays[0].ResponseDelay = 0
+		rr = request(backend, http.MethodPost, params.PathRegisterValidator, "192.0.2.1:1234", registrations)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var registrationsDeduplicated = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "registrations_deduplicated_total",
+	Help:      "Number of unchanged validator registrations which weren't forwarded to the relay",
+}, []string{"relay"})
+
+func init() {
+	metricsRegistry.MustRegister(registrationsDeduplicated)
+}
+
+// registrationCache remembers the registrations forwarded to each relay, so unchanged registrations are only forwarded
+// again after the resend interval
+type registrationCache struct {
+	mu             sync.Mutex
+	resendInterval time.Duration
+	forwarded      map[string]map[string]forwardedRegistration // by relay, by lowercase pubkey
+}
+
+// forwardedRegistration is a registration the relay accepted, and when
+type forwardedRegistration struct {
+	registration builderApiV1.ValidatorRegistration
+	at           time.Time
+}
+
+// newRegistrationCache returns the registration cache, nil if the resend interval is zero
+func newRegistrationCache(resendInterval time.Duration) *registrationCache {
+	if resendInterval <= 0 {
+		return nil
+	}
+	return &registrationCache{resendInterval: resendInterval, forwarded: make(map[string]map[string]forwardedRegistration)}
+}
+
+// changed returns the registrations which weren't forwarded to the relay within the resend interval, or which changed
+// since (fee recipient, gas limit or timestamp)
+func (c *registrationCache) changed(relay types.RelayEntry, registrations []builderApiV1.SignedValidatorRegistration, now time.Time) []builderApiV1.SignedValidatorRegistration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	forwarded := c.forwarded[relay.String()]
+	ret := make([]builderApiV1.SignedValidatorRegistration, 0, len(registrations))
+	for _, registration := range registrations {
+		if registration.Message != nil {
+			previous, ok := forwarded[strings.ToLower(registration.Message.Pubkey.String())]
+			if ok && now.Sub(previous.at) < c.resendInterval && sameRegistration(&previous.registration, registration.Message) {
+				continue
+			}
+		}
+		ret = append(ret, registration)
+	}
+	if skipped := len(registrations) - len(ret); skipped > 0 {
+		registrationsDeduplicated.WithLabelValues(relayLabel(relay)).Add(float64(skipped))
+	}
+	return ret
+}
+
+// record remembers the registrations the relay accepted
+func (c *registrationCache) record(relay types.RelayEntry, registrations []builderApiV1.SignedValidatorRegistration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	forwarded, ok := c.forwarded[relay.String()]
+	if !ok {
+		forwarded = make(map[string]forwardedRegistration)
+		c.forwarded[relay.String()] = forwarded
+	}
+	for _, registration := range registrations {
+		if registration.Message == nil {
+			continue
+		}
+		forwarded[strings.ToLower(registration.Message.Pubkey.String())] = forwardedRegistration{*registration.Message, now}
+	}
+}
+
+// sameRegistration returns true if the registrations have the same fee recipient, gas limit and timestamp
+func sameRegistration(a, b *builderApiV1.ValidatorRegistration) bool {
+	return a.FeeRecipient == b.FeeRecipient && a.GasLimit == b.GasLimit && a.Timestamp.Equal(b.Timestamp)
+}
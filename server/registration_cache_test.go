@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationCache(t *testing.T) {
+	require.Nil(t, newRegistrationCache(0))
+
+	relay, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay.example.com")
+	require.NoError(t, err)
+	otherRelay, err := types.NewRelayEntry("https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@other.example.com")
+	require.NoError(t, err)
+
+	cache := newRegistrationCache(time.Hour)
+	now := time.Now()
+	registrations := []builderApiV1.SignedValidatorRegistration{*testRegistration()}
+	require.Len(t, cache.changed(relay, registrations, now), 1)
+	cache.record(relay, registrations, now)
+
+	// Unchanged registrations are skipped until the resend interval is over, per relay
+	require.Empty(t, cache.changed(relay, registrations, now.Add(time.Minute)))
+	require.Len(t, cache.changed(otherRelay, registrations, now.Add(time.Minute)), 1)
+	require.Len(t, cache.changed(relay, registrations, now.Add(time.Hour)), 1)
+
+	// Changed registrations are forwarded at once
+	changed := *testRegistration()
+	changed.Message.GasLimit = 36_000_000
+	require.Len(t, cache.changed(relay, []builderApiV1.SignedValidatorRegistration{changed}, now.Add(time.Minute)), 1)
+	changed = *testRegistration()
+	changed.Message.Timestamp = changed.Message.Timestamp.Add(time.Second)
+	require.Len(t, cache.changed(relay, []builderApiV1.SignedValidatorRegistration{changed}, now.Add(time.Minute)), 1)
+}
+
+func TestRegisterValidatorDeduplication(t *testing.T) {
+	backend := newTestBackend(t, 2, time.Second)
+	backend.boost.registrationCache = newRegistrationCache(time.Hour)
+	payload := []builderApiV1.SignedValidatorRegistration{*testRegistration()}
+
+	// mev-boost responds after the first relay accepted the registrations
+	requestCount := func(relay int, expected int) func() bool {
+		return func() bool {
+			return backend.relays[relay].GetRequestCount(params.PathRegisterValidator) == expected
+		}
+	}
+	backend.relays[1].OverrideHandleRegisterValidator(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Eventually(t, requestCount(1, 1), time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+	// The relay which failed gets the registration again, the other relay doesn't
+	rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Eventually(t, requestCount(1, 2), time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+	// Only the changed registrations are forwarded
+	other := testRegistration()
+	other.Message.Pubkey = mock.HexToPubkey("0xb5246e299aeb782fbc7c91b41b3284245b1ed5206134b0028b81dfb974e5900616c67847c2354479934fc4bb75519ee1")
+	payload = append(payload, *other)
+	var forwarded []builderApiV1.SignedValidatorRegistration
+	backend.relays[0].OverrideHandleRegisterValidator(func(w http.ResponseWriter, req *http.Request) {
+		require.NoError(t, DecodeJSON(req.Body, &forwarded))
+		w.WriteHeader(http.StatusOK)
+	})
+	rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+	require.Len(t, forwarded, 1)
+	require.Equal(t, other.Message.Pubkey, forwarded[0].Message.Pubkey)
+}
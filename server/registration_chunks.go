@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+var errRegistrationChunks = errors.New("relay failed registration chunks")
+
+// chunkRegistrations splits the registrations into chunks of at most size registrations, one chunk if size is zero
+func chunkRegistrations(registrations []builderApiV1.SignedValidatorRegistration, size int) [][]builderApiV1.SignedValidatorRegistration {
+	if size <= 0 || len(registrations) <= size {
+		return [][]builderApiV1.SignedValidatorRegistration{registrations}
+	}
+	chunks := make([][]builderApiV1.SignedValidatorRegistration, 0, (len(registrations)+size-1)/size)
+	for start := 0; start < len(registrations); start += size {
+		chunks = append(chunks, registrations[start:min(start+size, len(registrations))])
+	}
+	return chunks
+}
+
+// sendRegistrations forwards the registrations to the relay in chunks of regValChunkSize, sent concurrently and each
+// retried regValChunkRetries times. The relay accepted the registrations if it accepted all chunks, else the error
+// counts the failed chunks and wraps the first chunk error.
+func (m *BoostService) sendRegistrations(ctx context.Context, client http.Client, relay types.RelayEntry, ua UserAgent, headers map[string]string, registrations []builderApiV1.SignedValidatorRegistration, log *logrus.Entry) (int, error) {
+	url := relay.GetURI(params.PathRegisterValidator)
+	chunks := chunkRegistrations(registrations, m.regValChunkSize)
+	codes := make([]int, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log := log
+			if len(chunks) > 1 {
+				log = log.WithFields(logrus.Fields{"chunk": i, "numChunkRegistrations": len(chunk)})
+			}
+
+			// Without retries the chunk is sent once
+			policy := retryPolicy{backoff: m.requestRetryBackoff, jitter: m.requestRetryJitter}
+			if m.regValChunkRetries > 0 {
+				policy.maxRetries = m.regValChunkRetries + 1
+			}
+			start := time.Now()
+			codes[i], errs[i] = m.sendRelayRequest(ctx, client, relay, http.MethodPost, url, ua, headers, chunk, nil, policy, log)
+			m.recordRelayRequest(relay, "registerValidator", start, codes[i], errs[i])
+			if errs[i] != nil {
+				log.WithError(errs[i]).Warn("error calling registerValidator on relay")
+			} else if m.registrationCache != nil {
+				m.registrationCache.record(relay, chunk, start)
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed, first := 0, -1
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	switch {
+	case failed == 0:
+		return codes[0], nil
+	case len(chunks) == 1:
+		return codes[0], errs[0]
+	default:
+		return codes[first], fmt.Errorf("%w: %d of %d: %w", errRegistrationChunks, failed, len(chunks), errs[first])
+	}
+}
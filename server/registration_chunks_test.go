@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkRegistrations(t *testing.T) {
+	registrations := make([]builderApiV1.SignedValidatorRegistration, 5)
+	require.Len(t, chunkRegistrations(registrations, 0), 1)
+	require.Len(t, chunkRegistrations(registrations, 5), 1)
+
+	chunks := chunkRegistrations(registrations, 2)
+	require.Len(t, chunks, 3)
+	require.Len(t, chunks[0], 2)
+	require.Len(t, chunks[2], 1)
+}
+
+func TestRegisterValidatorChunks(t *testing.T) {
+	payload := make([]builderApiV1.SignedValidatorRegistration, 5)
+	for i := range payload {
+		reg := testRegistration()
+		reg.Message.Pubkey[0] = byte(i)
+		payload[i] = *reg
+	}
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.regValChunkSize = 2
+	backend.boost.regValChunkRetries = 1
+
+	// The chunk with the last registration fails once
+	var mu sync.Mutex
+	var sizes []int
+	failures := 1
+	backend.relays[0].OverrideHandleRegisterValidator(func(w http.ResponseWriter, req *http.Request) {
+		var registrations []builderApiV1.SignedValidatorRegistration
+		require.NoError(t, DecodeJSON(req.Body, &registrations))
+		mu.Lock()
+		defer mu.Unlock()
+		if registrations[0].Message.Pubkey[0] == 4 && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sizes = append(sizes, len(registrations))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 4, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+	require.ElementsMatch(t, []int{2, 2, 1}, sizes)
+
+	// Without retries the relay failed one of the chunks
+	backend.boost.regValChunkRetries = 0
+	failures = 1
+	rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusBadGateway, rr.Code)
+	failed := requireRelayFailures(t, rr, backend.relays[0])
+	require.Contains(t, failed[0].Message, "relay failed registration chunks: 1 of 3")
+}
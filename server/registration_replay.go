@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// registrationsSaveInterval is how often changed validator registrations are saved to the registrations file
+const registrationsSaveInterval = time.Minute
+
+// registrationsFile is the content of the registrations file
+type registrationsFile struct {
+	SavedAt       time.Time                                  `json:"saved_at"`
+	Registrations []builderApiV1.SignedValidatorRegistration `json:"registrations"`
+}
+
+// loadRegistrations restores the validator registrations from the registrations file, if it exists
+func (m *BoostService) loadRegistrations() error {
+	data, err := os.ReadFile(m.registrationsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file registrationsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	m.registrations.record(file.Registrations)
+	m.registrations.snapshot()
+	m.log.WithField("savedAt", file.SavedAt).Infof("restored %d validator registrations from %s", len(file.Registrations), m.registrationsFile)
+	return nil
+}
+
+// saveRegistrations writes the validator registrations to the registrations file if they changed
+func (m *BoostService) saveRegistrations() error {
+	registrations, changed := m.registrations.snapshot()
+	if !changed {
+		return nil
+	}
+	data, err := json.Marshal(registrationsFile{SavedAt: time.Now().UTC(), Registrations: registrations})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(m.registrationsFile, data)
+}
+
+// startRegistrationsSaveTask saves the validator registrations every registrationsSaveInterval
+func (m *BoostService) startRegistrationsSaveTask() {
+	for {
+		time.Sleep(registrationsSaveInterval)
+		if err := m.saveRegistrations(); err != nil {
+			m.log.WithError(err).Error("could not save the validator registrations")
+		}
+	}
+}
+
+// replayRegistrationsToNewRelays replays the registrations to the relays which didn't receive them with the previous
+// settings, i.e. relays added at runtime or by a new validator route
+func (m *BoostService) replayRegistrationsToNewRelays(previous reloadableConfig) {
+	registrations := m.registrations.all()
+	if len(registrations) == 0 {
+		return
+	}
+	received := make(map[string]map[string]bool)
+	for _, relayPayload := range previous.registrationsByRelay(registrations) {
+		pubkeys := make(map[string]bool, len(relayPayload.registrations))
+		for _, registration := range relayPayload.registrations {
+			pubkeys[strings.ToLower(registration.Message.Pubkey.String())] = true
+		}
+		received[relayPayload.relay.String()] = pubkeys
+	}
+
+	for _, relayPayload := range m.currentConfig().registrationsByRelay(registrations) {
+		pubkeys := received[relayPayload.relay.String()]
+		missing := make([]builderApiV1.SignedValidatorRegistration, 0, len(relayPayload.registrations))
+		for _, registration := range relayPayload.registrations {
+			if !pubkeys[strings.ToLower(registration.Message.Pubkey.String())] {
+				missing = append(missing, registration)
+			}
+		}
+		if len(missing) > 0 {
+			go m.replayRegistrations(relayPayload.relay, missing, "relay added")
+		}
+	}
+}
+
+// replayRegistrationsToRecoveredRelay replays the registrations of the validators routed to the relay, after the relay
+// recovered from an outage
+func (m *BoostService) replayRegistrationsToRecoveredRelay(relay types.RelayEntry) {
+	for _, relayPayload := range m.currentConfig().registrationsByRelay(m.registrations.all()) {
+		if relayPayload.relay.String() == relay.String() && len(relayPayload.registrations) > 0 {
+			m.replayRegistrations(relay, relayPayload.registrations, "relay recovered")
+		}
+	}
+}
+
+// replayRegistrations sends the latest registrations to the relay, without waiting for the next registrations of the
+// consensus clients
+func (m *BoostService) replayRegistrations(relay types.RelayEntry, registrations []builderApiV1.SignedValidatorRegistration, reason string) {
+	log := m.log.WithFields(logrus.Fields{
+		"method":           "replayRegistrations",
+		"url":              relay.GetURI(params.PathRegisterValidator),
+		"reason":           reason,
+		"numRegistrations": len(registrations),
+	})
+
+	if _, err := m.sendRegistrations(context.Background(), m.currentConfig().regValClient(relay), relay, "", nil, registrations, log); err != nil {
+		log.WithError(err).Warn("could not replay the validator registrations to the relay")
+		return
+	}
+	log.Info("replayed the validator registrations to the relay")
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registrations.json")
+	reg := testRegistration()
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.registrationsFile = path
+	rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, []builderApiV1.SignedValidatorRegistration{*reg})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, backend.boost.saveRegistrations())
+
+	// Unchanged registrations aren't saved again
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, backend.boost.saveRegistrations())
+	require.NoFileExists(t, path)
+	backend.boost.registrations.record([]builderApiV1.SignedValidatorRegistration{*reg})
+	require.NoError(t, backend.boost.saveRegistrations())
+
+	// A restarted service knows the registrations
+	service, err := NewBoostService(BoostServiceOpts{
+		Log:                   mock.TestLog,
+		Relays:                []types.RelayEntry{backend.relays[0].RelayEntry},
+		GenesisForkVersionHex: "0x00000000",
+		RegistrationsFile:     path,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []builderApiV1.SignedValidatorRegistration{*reg}, service.registrations.all())
+
+	t.Run("Missing file", func(t *testing.T) {
+		service.registrationsFile = filepath.Join(t.TempDir(), "missing.json")
+		require.NoError(t, service.loadRegistrations())
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		service.registrationsFile = filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(service.registrationsFile, []byte("{"), 0o600))
+		require.Error(t, service.loadRegistrations())
+	})
+}
+
+func TestReplayRegistrations(t *testing.T) {
+	reg := testRegistration()
+
+	t.Run("Replays to added relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, []builderApiV1.SignedValidatorRegistration{*reg})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+		relay := mock.NewRelay(t)
+		err := backend.boost.Reload(ReloadOpts{Relays: []types.RelayEntry{backend.relays[0].RelayEntry, relay.RelayEntry}})
+		require.NoError(t, err)
+
+		// Only the added relay gets the registrations
+		require.Eventually(t, func() bool {
+			return relay.GetRequestCount(params.PathRegisterValidator) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+	})
+
+	t.Run("Replays to recovered relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.breaker = newCircuitBreaker(1, time.Hour, nopMetricsSink{})
+		backend.boost.registrations.record([]builderApiV1.SignedValidatorRegistration{*reg})
+		relay := backend.relays[0].RelayEntry
+
+		require.False(t, backend.boost.breaker.record(relay, true))
+		backend.boost.recordRelayRequest(relay, "getHeader", time.Now(), http.StatusOK, nil)
+		require.Eventually(t, func() bool {
+			return backend.relays[0].GetRequestCount(params.PathRegisterValidator) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		// Successful requests to a healthy relay don't replay
+		backend.boost.recordRelayRequest(relay, "getHeader", time.Now(), http.StatusOK, nil)
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+	})
+}
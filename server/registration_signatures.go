@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/ssz"
+)
+
+var errInvalidRegistrationSignature = errors.New("invalid validator registration signature")
+
+// invalidRegistrationSignatures returns the pubkeys of the registrations without a valid signature for the builder
+// domain, in payload order. The signatures are verified in parallel.
+func invalidRegistrationSignatures(registrations []builderApiV1.SignedValidatorRegistration, domain phase0.Domain) []string {
+	invalid := make([]bool, len(registrations))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), len(registrations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				invalid[i] = !validRegistrationSignature(&registrations[i], domain)
+			}
+		}()
+	}
+	for i := range registrations {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	ret := make([]string, 0)
+	for i, registration := range registrations {
+		if !invalid[i] {
+			continue
+		}
+		if registration.Message == nil {
+			ret = append(ret, fmt.Sprintf("#%d (no message)", i))
+			continue
+		}
+		ret = append(ret, registration.Message.Pubkey.String())
+	}
+	return ret
+}
+
+// validRegistrationSignature returns true if the registration is signed by its validator for the builder domain
+func validRegistrationSignature(registration *builderApiV1.SignedValidatorRegistration, domain phase0.Domain) bool {
+	if registration.Message == nil {
+		return false
+	}
+	ok, err := ssz.VerifySignature(registration.Message, domain, registration.Message.Pubkey[:], registration.Signature[:])
+	return err == nil && ok
+}
+
+// registrationSignatureError is the error listing the pubkeys of the registrations with invalid signature
+func registrationSignatureError(pubkeys []string) error {
+	return fmt.Errorf("%w: %s", errInvalidRegistrationSignature, strings.Join(pubkeys, ", "))
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterValidatorVerifySignatures(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.regValVerifySignatures = true
+
+	// signedRegistration returns a registration signed by a new validator key
+	signedRegistration := func(t *testing.T) builderApiV1.SignedValidatorRegistration {
+		t.Helper()
+		sk, pk, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		registration := &builderApiV1.ValidatorRegistration{Timestamp: time.Unix(1234356, 0)}
+		copy(registration.Pubkey[:], bls.PublicKeyToBytes(pk))
+		signature, err := ssz.SignMessage(registration, backend.boost.builderSigningDomain, sk)
+		require.NoError(t, err)
+		return builderApiV1.SignedValidatorRegistration{Message: registration, Signature: signature}
+	}
+
+	valid := signedRegistration(t)
+	rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, []builderApiV1.SignedValidatorRegistration{valid})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+	// A batch with invalid signatures is rejected, naming the validators
+	invalid := signedRegistration(t)
+	invalid.Signature = phase0.BLSSignature{}
+	rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, []builderApiV1.SignedValidatorRegistration{valid, invalid})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid validator registration signature: "+invalid.Message.Pubkey.String())
+	require.NotContains(t, rr.Body.String(), valid.Message.Pubkey.String())
+	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+}
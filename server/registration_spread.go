@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// registrationSpreadInterval is how often the buffered registrations which are due are forwarded to the relays
+const registrationSpreadInterval = time.Second
+
+// registrationSpreader buffers the validator registrations, each is forwarded to the relays at a random time within
+// the spread window after it arrived
+type registrationSpreader struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]pendingRegistration // by lowercase pubkey
+}
+
+// pendingRegistration is a buffered registration, and when it's forwarded
+type pendingRegistration struct {
+	registration builderApiV1.SignedValidatorRegistration
+	due          time.Time
+}
+
+// newRegistrationSpreader returns the registration spreader, nil if the window is zero
+func newRegistrationSpreader(window time.Duration) *registrationSpreader {
+	if window <= 0 {
+		return nil
+	}
+	return &registrationSpreader{window: window, pending: make(map[string]pendingRegistration)}
+}
+
+// add buffers the registrations. A registration replacing a buffered one keeps its time.
+func (s *registrationSpreader) add(registrations []builderApiV1.SignedValidatorRegistration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, registration := range registrations {
+		if registration.Message == nil {
+			continue
+		}
+		pubkey := strings.ToLower(registration.Message.Pubkey.String())
+		pending, ok := s.pending[pubkey]
+		if !ok {
+			pending.due = now.Add(rand.N(s.window)) //nolint:gosec
+		}
+		pending.registration = registration
+		s.pending[pubkey] = pending
+	}
+}
+
+// due removes and returns the registrations to forward at now, sorted by pubkey
+func (s *registrationSpreader) due(now time.Time) []builderApiV1.SignedValidatorRegistration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pubkeys := make([]string, 0)
+	for pubkey, pending := range s.pending {
+		if !pending.due.After(now) {
+			pubkeys = append(pubkeys, pubkey)
+		}
+	}
+	sort.Strings(pubkeys)
+	ret := make([]builderApiV1.SignedValidatorRegistration, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		ret = append(ret, s.pending[pubkey].registration)
+		delete(s.pending, pubkey)
+	}
+	return ret
+}
+
+// startRegistrationSpreadTask forwards the buffered registrations when they are due
+func (m *BoostService) startRegistrationSpreadTask() {
+	for {
+		time.Sleep(registrationSpreadInterval)
+		m.forwardDueRegistrations(time.Now())
+	}
+}
+
+// forwardDueRegistrations forwards the buffered registrations which are due to the relays, without waiting for the
+// relays
+func (m *BoostService) forwardDueRegistrations(now time.Time) {
+	registrations := m.registrationSpreader.due(now)
+	if len(registrations) == 0 {
+		return
+	}
+	headers := map[string]string{
+		HeaderStartTimeUnixMS: fmt.Sprintf("%d", time.Now().UTC().UnixMilli()),
+	}
+
+	cfg := m.currentConfig()
+	for _, relayPayload := range cfg.registrationsByRelay(registrations) {
+		go func(relay types.RelayEntry, registrations []builderApiV1.SignedValidatorRegistration) {
+			log := m.log.WithFields(logrus.Fields{
+				"method":                "forwardDueRegistrations",
+				"url":                   relay.GetURI(""),
+				"numRelayRegistrations": len(registrations),
+			})
+			if m.registrationCache != nil {
+				registrations = m.registrationCache.changed(relay, registrations, time.Now())
+				if len(registrations) == 0 {
+					return
+				}
+			}
+			if _, err := m.sendRegistrations(context.Background(), cfg.regValClient(relay), relay, "", headers, registrations, log); err != nil {
+				log.WithError(err).Warn("could not forward the buffered validator registrations to the relay")
+			}
+		}(relayPayload.relay, relayPayload.registrations)
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationSpreader(t *testing.T) {
+	require.Nil(t, newRegistrationSpreader(0))
+
+	now := time.Now()
+	spreader := newRegistrationSpreader(time.Minute)
+	reg := testRegistration()
+	spreader.add([]builderApiV1.SignedValidatorRegistration{*reg}, now)
+	due := spreader.pending[reg.Message.Pubkey.String()].due
+	require.False(t, due.Before(now))
+	require.True(t, due.Before(now.Add(time.Minute)))
+
+	// A newer registration replaces the buffered one and keeps its time
+	newer := testRegistration()
+	newer.Message.GasLimit = 30_000_000
+	spreader.add([]builderApiV1.SignedValidatorRegistration{*newer}, now.Add(time.Second))
+	require.Equal(t, due, spreader.pending[reg.Message.Pubkey.String()].due)
+
+	require.Empty(t, spreader.due(due.Add(-time.Nanosecond)))
+	require.Equal(t, []builderApiV1.SignedValidatorRegistration{*newer}, spreader.due(due))
+	require.Empty(t, spreader.due(now.Add(time.Minute)))
+}
+
+func TestRegisterValidatorSpread(t *testing.T) {
+	backend := newTestBackend(t, 2, time.Second)
+	backend.boost.registrationSpreader = newRegistrationSpreader(time.Minute)
+
+	// The registrations are buffered, the relays don't get them yet
+	payload := []builderApiV1.SignedValidatorRegistration{*testRegistration()}
+	rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 0, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+	backend.boost.forwardDueRegistrations(time.Now().Add(time.Minute))
+	for _, relay := range backend.relays {
+		require.Eventually(t, func() bool {
+			return relay.GetRequestCount(params.PathRegisterValidator) == 1
+		}, time.Second, 10*time.Millisecond)
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/gorilla/mux"
+)
+
+// registrationStatus is where a validator is registered, the response of the validator registration endpoint
+type registrationStatus struct {
+	Pubkey string                    `json:"pubkey"`
+	Local  *registrationParams       `json:"local"` // the latest registration mev-boost received, nil if none since the start
+	Relays []relayRegistrationStatus `json:"relays"`
+}
+
+// registrationParams are the parameters of a validator registration
+type registrationParams struct {
+	FeeRecipient string `json:"fee_recipient"`
+	GasLimit     string `json:"gas_limit"`
+	Timestamp    string `json:"timestamp"`
+}
+
+// Registration states of a validator at a relay
+const (
+	registrationRegistered    = "registered"
+	registrationNotRegistered = "not_registered"
+	registrationUnknown       = "unknown" // the relay failed to answer, see the error
+)
+
+// relayRegistrationStatus is the registration of the validator a relay knows, from its data API
+type relayRegistrationStatus struct {
+	Relay        string              `json:"relay"`
+	Status       string              `json:"status"`
+	Registration *registrationParams `json:"registration,omitempty"`
+	Mismatch     []string            `json:"mismatch,omitempty"` // the parameters differing from the local registration
+	Error        string              `json:"error,omitempty"`
+}
+
+func newRegistrationParams(registration *builderApiV1.ValidatorRegistration) *registrationParams {
+	return &registrationParams{
+		FeeRecipient: registration.FeeRecipient.String(),
+		GasLimit:     strconv.FormatUint(registration.GasLimit, 10),
+		Timestamp:    strconv.FormatInt(registration.Timestamp.Unix(), 10),
+	}
+}
+
+// mismatch returns the parameters which differ from the other registration
+func (p *registrationParams) mismatch(other *registrationParams) []string {
+	var ret []string
+	if p.FeeRecipient != other.FeeRecipient {
+		ret = append(ret, "fee_recipient")
+	}
+	if p.GasLimit != other.GasLimit {
+		ret = append(ret, "gas_limit")
+	}
+	if p.Timestamp != other.Timestamp {
+		ret = append(ret, "timestamp")
+	}
+	return ret
+}
+
+// handleValidatorRegistration returns the registration of the validator at each of its relays, queried from their data
+// API, next to the latest registration mev-boost received
+func (m *BoostService) handleValidatorRegistration(w http.ResponseWriter, req *http.Request) {
+	pubkey, err := utils.HexToPubkey(mux.Vars(req)["pubkey"])
+	if err != nil {
+		m.respondError(w, http.StatusBadRequest, errInvalidPubkey.Error())
+		return
+	}
+
+	status := registrationStatus{Pubkey: pubkey.String()}
+	if registration, ok := m.registrations.get(pubkey.String()); ok {
+		status.Local = newRegistrationParams(registration)
+	}
+
+	cfg := m.currentConfig().forValidatorPubkey(pubkey)
+	status.Relays = make([]relayRegistrationStatus, len(cfg.relays))
+	var wg sync.WaitGroup
+	for i, relay := range cfg.relays {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.Relays[i] = m.relayRegistrationStatus(req.Context(), cfg, relay, status.Pubkey, status.Local)
+		}()
+	}
+	wg.Wait()
+	m.respondOK(w, status)
+}
+
+// relayRegistrationStatus queries the registration of the validator from the relay data API
+func (m *BoostService) relayRegistrationStatus(ctx context.Context, cfg reloadableConfig, relay types.RelayEntry, pubkey string, local *registrationParams) relayRegistrationStatus {
+	ret := relayRegistrationStatus{Relay: relay.GetURI(""), Status: registrationUnknown}
+	url := relay.GetURIWithQuery(params.PathDataValidatorRegistration, url.Values{"pubkey": []string{pubkey}})
+
+	// Relays respond with 400 or 404 if the validator isn't registered, other failures leave the status unknown
+	registration := new(builderApiV1.SignedValidatorRegistration)
+	code, err := SendHTTPRequest(ctx, cfg.dataAPIClient(relay), http.MethodGet, url, "", nil, nil, registration)
+	if err != nil {
+		if code == http.StatusBadRequest || code == http.StatusNotFound {
+			ret.Status = registrationNotRegistered
+		}
+		ret.Error = err.Error()
+		return ret
+	}
+	if registration.Message == nil {
+		ret.Error = errInvalidResponse.Error()
+		return ret
+	}
+	ret.Status = registrationRegistered
+	ret.Registration = newRegistrationParams(registration.Message)
+	if local != nil {
+		ret.Mismatch = ret.Registration.mismatch(local)
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorRegistrationStatus(t *testing.T) {
+	reg := testRegistration()
+	pubkey := reg.Message.Pubkey.String()
+
+	// newDataAPIRelay returns a relay under the base path whose data API returns the registration, or the status code
+	// if the registration is nil
+	newDataAPIRelay := func(t *testing.T, basePath string, registration *builderApiV1.SignedValidatorRegistration, code int) types.RelayEntry {
+		t.Helper()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, basePath+params.PathDataValidatorRegistration, r.URL.Path)
+			require.Equal(t, pubkey, r.URL.Query().Get("pubkey"))
+			if registration == nil {
+				http.Error(w, fmt.Sprintf(`{"code":%d,"message":"no registration found for validator"}`, code), code)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(registration)
+		}))
+		t.Cleanup(ts.Close)
+
+		u, err := url.Parse(ts.URL)
+		require.NoError(t, err)
+		relay, err := types.NewRelayEntry(fmt.Sprintf("http://%s@%s%s", mock.NewRelay(t).RelayEntry.PublicKey.String(), u.Host, basePath))
+		require.NoError(t, err)
+		return relay
+	}
+
+	outdated := testRegistration()
+	outdated.Message.GasLimit = 30_000_000
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.relays = []types.RelayEntry{
+		newDataAPIRelay(t, "", reg, 0),
+		newDataAPIRelay(t, "/relay", outdated, 0),
+		newDataAPIRelay(t, "", nil, http.StatusBadRequest),
+		newDataAPIRelay(t, "", nil, http.StatusNotFound),
+		newDataAPIRelay(t, "", nil, http.StatusInternalServerError),
+	}
+	path := "/api/v1/validator-registrations/" + pubkey
+
+	// Without local registration
+	rr := backend.request(t, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var status registrationStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	require.Equal(t, pubkey, status.Pubkey)
+	require.Nil(t, status.Local)
+	require.Len(t, status.Relays, 5)
+	require.Equal(t, registrationRegistered, status.Relays[0].Status)
+	require.Equal(t, reg.Message.FeeRecipient.String(), status.Relays[0].Registration.FeeRecipient)
+	require.Empty(t, status.Relays[0].Mismatch)
+
+	// The relays are compared with the local registration
+	backend.boost.registrations.record([]builderApiV1.SignedValidatorRegistration{*reg})
+	rr = backend.request(t, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	status = registrationStatus{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	require.Equal(t, newRegistrationParams(reg.Message), status.Local)
+	require.Empty(t, status.Relays[0].Mismatch)
+	require.Equal(t, registrationRegistered, status.Relays[1].Status)
+	require.Equal(t, []string{"gas_limit"}, status.Relays[1].Mismatch)
+	require.Equal(t, registrationNotRegistered, status.Relays[2].Status)
+	require.Contains(t, status.Relays[2].Error, "no registration found")
+	require.Equal(t, registrationNotRegistered, status.Relays[3].Status)
+
+	// A relay which fails doesn't tell whether the validator is registered
+	require.Equal(t, registrationUnknown, status.Relays[4].Status)
+	require.Contains(t, status.Relays[4].Error, "500")
+
+	rr = backend.request(t, http.MethodGet, "/api/v1/validator-registrations/0x1234", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
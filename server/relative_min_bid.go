@@ -0,0 +1,119 @@
+package server
+
+import (
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// bidValueHistory keeps the highest bid value of the recent slots, for a min-bid relative to the recent bid values
+type bidValueHistory struct {
+	mu     sync.Mutex
+	slots  phase0.Slot
+	values map[phase0.Slot]*big.Int
+}
+
+func newBidValueHistory(slots int) *bidValueHistory {
+	return &bidValueHistory{
+		slots:  phase0.Slot(slots),
+		values: make(map[phase0.Slot]*big.Int),
+	}
+}
+
+// record records a bid value of the slot, and forgets the slots which are too old
+func (h *bidValueHistory) record(slot phase0.Slot, value *big.Int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if highest, ok := h.values[slot]; !ok || value.Cmp(highest) > 0 {
+		h.values[slot] = value
+	}
+	for s := range h.values {
+		if s+h.slots <= slot {
+			delete(h.values, s)
+		}
+	}
+}
+
+// median returns the median of the highest bid values of the recent slots, nil without bids
+func (h *bidValueHistory) median() *big.Int {
+	h.mu.Lock()
+	values := make([]*big.Int, 0, len(h.values))
+	for _, value := range h.values {
+		values = append(values, value)
+	}
+	h.mu.Unlock()
+
+	if len(values) == 0 {
+		return nil
+	}
+	slices.SortFunc(values, func(a, b *big.Int) int { return a.Cmp(b) })
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return new(big.Int).Set(values[mid])
+	}
+	median := new(big.Int).Add(values[mid-1], values[mid])
+	return median.Rsh(median, 1)
+}
+
+// minBid returns the min-bid for the next auction: the configured min-bid, or the configured percentage of the median
+// recent bid value if higher
+func (m *BoostService) minBid(cfg reloadableConfig) types.U256Str {
+	return relativeMinBid(cfg.relayMinBid, m.relayMinBidPercent, m.bidValues)
+}
+
+// relativeMinBid returns the percentage of the median recent bid value if higher than the min-bid, or the min-bid
+func relativeMinBid(minBid types.U256Str, percent float64, values *bidValueHistory) types.U256Str {
+	if values == nil {
+		return minBid
+	}
+	median := values.median()
+	if median == nil {
+		return minBid
+	}
+
+	relative, _ := new(big.Float).Mul(new(big.Float).SetInt(median), big.NewFloat(percent/100)).Int(nil)
+	if relative.Cmp(minBid.BigInt()) <= 0 {
+		return minBid
+	}
+	var ret types.U256Str
+	if err := ret.FromBig(relative); err != nil {
+		return minBid
+	}
+	return ret
+}
+
+// RelativeMinBid is the min-bid of the auctions with the relative min-bid settings of BoostServiceOpts, for replaying
+// auctions outside of the service
+type RelativeMinBid struct {
+	minBid  types.U256Str
+	percent float64
+	values  *bidValueHistory // nil without relative min-bid
+}
+
+// NewRelativeMinBid returns the min-bid, relative to the recent bid values of the slots if percent is positive
+func NewRelativeMinBid(minBid types.U256Str, percent float64, slots int) (*RelativeMinBid, error) {
+	r := &RelativeMinBid{minBid: minBid, percent: percent}
+	if percent > 0 {
+		if slots <= 0 {
+			return nil, errInvalidMinBidSlots
+		}
+		r.values = newBidValueHistory(slots)
+	}
+	return r, nil
+}
+
+// Record records the value of a bid of the slot, as the service does for the bids it receives
+func (r *RelativeMinBid) Record(slot uint64, value *big.Int) {
+	if r.values != nil {
+		r.values.record(phase0.Slot(slot), value)
+	}
+}
+
+// MinBid returns the min-bid for the next auction
+func (r *RelativeMinBid) MinBid() types.U256Str {
+	return relativeMinBid(r.minBid, r.percent, r.values)
+}
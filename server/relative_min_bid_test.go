@@ -0,0 +1,89 @@
+package server
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidValueHistory(t *testing.T) {
+	t.Run("Median of the highest bid per slot", func(t *testing.T) {
+		history := newBidValueHistory(10)
+		require.Nil(t, history.median())
+
+		history.record(1, big.NewInt(100))
+		history.record(1, big.NewInt(300))
+		history.record(1, big.NewInt(200))
+		require.Equal(t, big.NewInt(300), history.median())
+
+		history.record(2, big.NewInt(100))
+		require.Equal(t, big.NewInt(200), history.median())
+
+		history.record(3, big.NewInt(50))
+		require.Equal(t, big.NewInt(100), history.median())
+	})
+
+	t.Run("Forgets old slots", func(t *testing.T) {
+		history := newBidValueHistory(2)
+		history.record(1, big.NewInt(1000))
+		history.record(2, big.NewInt(10))
+		history.record(3, big.NewInt(20))
+		require.Equal(t, big.NewInt(15), history.median())
+	})
+}
+
+func TestRelativeMinBid(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	t.Run("Uses the higher min-bid", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relayMinBidPercent = 50
+		backend.boost.bidValues = newBidValueHistory(10)
+		cfg := backend.boost.currentConfig()
+		require.Equal(t, types.IntToU256(12345), backend.boost.minBid(cfg), "no recent bids")
+
+		backend.boost.bidValues.record(1, big.NewInt(20000))
+		require.Equal(t, types.IntToU256(12345), backend.boost.minBid(cfg))
+
+		backend.boost.bidValues.record(2, big.NewInt(40000))
+		require.Equal(t, types.IntToU256(15000), backend.boost.minBid(cfg))
+	})
+
+	t.Run("Rejects bids below the relative min-bid", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relayMinBidPercent = 50
+		backend.boost.bidValues = newBidValueHistory(10)
+
+		// The mock relay bids 12345, which is the median after the first slot
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		backend.boost.bidValues.record(2, big.NewInt(30000))
+		backend.boost.bidValues.record(3, big.NewInt(30000))
+		rr = backend.request(t, http.MethodGet, getHeaderPath(4, hash, pubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Outside of the service", func(t *testing.T) {
+		minBid, err := NewRelativeMinBid(types.IntToU256(100), 50, 2)
+		require.NoError(t, err)
+		require.Equal(t, types.IntToU256(100), minBid.MinBid())
+		minBid.Record(1, big.NewInt(1000))
+		require.Equal(t, types.IntToU256(500), minBid.MinBid())
+
+		minBid, err = NewRelativeMinBid(types.IntToU256(100), 0, 0)
+		require.NoError(t, err)
+		minBid.Record(1, big.NewInt(1000))
+		require.Equal(t, types.IntToU256(100), minBid.MinBid())
+
+		_, err = NewRelativeMinBid(types.IntToU256(100), 50, 0)
+		require.ErrorIs(t, err, errInvalidMinBidSlots)
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// relayCapabilityCheckSlots is how often (in slots) relays which didn't respond with the newest fork version are logged
+const relayCapabilityCheckSlots = 32
+
+// relayCapabilityStore records the consensus versions of the bids and payloads of each relay. The builder API has no
+// capability endpoint, so the versions are learned from the relay responses.
+type relayCapabilityStore struct {
+	mu       sync.Mutex
+	versions map[string]map[spec.DataVersion]bool
+}
+
+func newRelayCapabilityStore() *relayCapabilityStore {
+	return &relayCapabilityStore{versions: make(map[string]map[spec.DataVersion]bool)}
+}
+
+// record adds a consensus version the relay responded with
+func (s *relayCapabilityStore) record(relay types.RelayEntry, version spec.DataVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, ok := s.versions[relay.String()]
+	if !ok {
+		versions = make(map[spec.DataVersion]bool)
+		s.versions[relay.String()] = versions
+	}
+	versions[version] = true
+}
+
+// get returns the consensus versions the relay responded with, oldest first
+func (s *relayCapabilityStore) get(relay types.RelayEntry) []spec.DataVersion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := make([]spec.DataVersion, 0, len(s.versions[relay.String()]))
+	for version := range s.versions[relay.String()] {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// outdated returns the relays which never responded with the newest consensus version of any relay, along with their
+// newest version. Relays which never responded are not outdated.
+func (s *relayCapabilityStore) outdated(relays []types.RelayEntry) (spec.DataVersion, map[string]spec.DataVersion) {
+	latest := make(map[string]spec.DataVersion, len(relays))
+	newest := spec.DataVersionUnknown
+	for _, relay := range relays {
+		versions := s.get(relay)
+		if len(versions) == 0 {
+			continue
+		}
+		latest[relay.GetURI("")] = versions[len(versions)-1]
+		newest = max(newest, versions[len(versions)-1])
+	}
+	ret := make(map[string]spec.DataVersion)
+	for relay, version := range latest {
+		if version < newest {
+			ret[relay] = version
+		}
+	}
+	return newest, ret
+}
+
+// startRelayCapabilityCheck periodically warns about relays which didn't respond with the newest consensus version,
+// i.e. after a fork they don't support yet
+func (m *BoostService) startRelayCapabilityCheck() {
+	for {
+		time.Sleep(relayCapabilityCheckSlots * time.Duration(m.slotTimeSec) * time.Second)
+		m.checkRelayCapabilities()
+	}
+}
+
+// checkRelayCapabilities warns about relays which didn't respond with the newest consensus version, and returns them
+func (m *BoostService) checkRelayCapabilities() map[string]spec.DataVersion {
+	newest, outdated := m.relayCapabilities.outdated(m.currentConfig().relays)
+	for relay, version := range outdated {
+		m.log.WithFields(logrus.Fields{
+			"relay":         relay,
+			"version":       version.String(),
+			"newestVersion": newest.String(),
+		}).Warn("relay didn't respond with the newest consensus version of the other relays, it might not support the current fork")
+	}
+	return outdated
+}
+
+// setConsensusVersions adds the consensus versions the relay responded with to its health
+func (h *RelayHealth) setConsensusVersions(capabilities *relayCapabilityStore, relay types.RelayEntry) {
+	for _, version := range capabilities.get(relay) {
+		h.ConsensusVersions = append(h.ConsensusVersions, version.String())
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayCapabilities(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	backend := newTestBackend(t, 3, time.Second)
+	require.Empty(t, backend.boost.checkRelayCapabilities())
+
+	// Relay 0 bids with deneb, relay 1 with capella, relay 2 is down
+	backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+		12345,
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionCapella,
+	)
+	backend.relays[2].Server.Close()
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	require.Equal(t, []spec.DataVersion{spec.DataVersionDeneb}, backend.boost.relayCapabilities.get(backend.relays[0].RelayEntry))
+	require.Equal(t, map[string]spec.DataVersion{
+		backend.relays[1].RelayEntry.GetURI(""): spec.DataVersionCapella,
+	}, backend.boost.checkRelayCapabilities())
+
+	rr = backend.request(t, http.MethodGet, params.PathStatus+"?verbose=true", nil)
+	resp := StatusResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, []string{"deneb"}, resp.Relays[0].ConsensusVersions)
+	require.Equal(t, []string{"capella"}, resp.Relays[1].ConsensusVersions)
+	require.Empty(t, resp.Relays[2].ConsensusVersions)
+}
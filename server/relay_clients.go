@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// RelayClientOpts are the relay client settings of BoostServiceOpts
+type RelayClientOpts struct {
+	ClientCert          string
+	ClientKey           string
+	DNSResolver         string
+	DisableHTTP2        bool
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
+}
+
+// RelayClients are the HTTP clients of relays with the relay client settings of mev-boost, for the subcommands sending
+// requests to the relays outside of the service
+type RelayClients struct {
+	transports *relayTransports
+}
+
+// NewRelayClients returns the relay clients with the settings, and checks the client certificates of the relays
+func NewRelayClients(opts RelayClientOpts, relays []types.RelayEntry) (*RelayClients, error) {
+	settings := relayTransportSettings{
+		disableHTTP2:        opts.DisableHTTP2,
+		maxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+		idleConnTimeout:     opts.IdleConnTimeout,
+		keepAlive:           opts.KeepAlive,
+	}
+	transports, err := newRelayTransports(opts.ClientCert, opts.ClientKey, settings, relays)
+	if err != nil {
+		return nil, err
+	}
+	transports.hosts = newRelayHosts(opts.DNSResolver, 0)
+	return &RelayClients{transports: transports}, nil
+}
+
+// Client returns the client of the relay with the timeout, not following redirects
+func (c *RelayClients) Client(relay types.RelayEntry, timeout time.Duration) http.Client {
+	return c.transports.client(http.Client{Timeout: timeout, CheckRedirect: httpClientDisallowRedirects}, relay)
+}
+
+// LookupHost resolves the relay host name as the relay connections do
+func (c *RelayClients) LookupHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	return c.transports.hosts.addrs(ctx, host)
+}
+
+// CloseIdleConnections closes the idle connections to the relays
+func (c *RelayClients) CloseIdleConnections() {
+	c.transports.closeIdleConnections()
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// DNS records for relay discovery, see dnsRelaySource
+const (
+	relayDNSService      = "mev-relay"
+	relayDNSURLPrefix    = "mev-relay="
+	relayDNSPubkeyPrefix = "mev-relay-pubkey="
+	relayDNSSchemePrefix = "mev-relay-scheme="
+
+	relayDNSLookupTimeout = 5 * time.Second
+)
+
+var (
+	errNoDNSRelays      = errors.New("no relays found in DNS records")
+	errMissingDNSPubkey = errors.New("missing relay pubkey TXT record")
+)
+
+// relayResolver resolves the DNS records for relay discovery, implemented by net.Resolver
+type relayResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// dnsRelaySource returns the relay source of a domain. Relays are discovered from two kinds of records:
+//
+//   - TXT records of the domain with a relay URL, i.e. "mev-relay=https://0xpubkey@relay.example.com?tier=1"
+//   - SRV records of _mev-relay._tcp.<domain>. The relay pubkey is a TXT record of the target, i.e.
+//     "mev-relay-pubkey=0xpubkey", the scheme is https unless the target has a "mev-relay-scheme=http" TXT record. The
+//     priority and weight of the SRV record are used as tier and weight of the relay.
+func dnsRelaySource(resolver relayResolver, domain string, interval time.Duration) relaySource {
+	return relaySource{
+		name:     "dns:" + domain,
+		interval: interval,
+		fetch: func(ctx context.Context) ([]types.RelayEntry, error) {
+			ctx, cancel := context.WithTimeout(ctx, relayDNSLookupTimeout)
+			defer cancel()
+			return resolveRelays(ctx, resolver, domain)
+		},
+	}
+}
+
+// resolveRelays returns the relays of the TXT and SRV records of the domain
+func resolveRelays(ctx context.Context, resolver relayResolver, domain string) ([]types.RelayEntry, error) {
+	var relays []types.RelayEntry
+
+	records, err := resolver.LookupTXT(ctx, domain)
+	if err != nil && !isDNSNotFound(err) {
+		return nil, err
+	}
+	for _, record := range records {
+		url, ok := strings.CutPrefix(record, relayDNSURLPrefix)
+		if !ok {
+			continue
+		}
+		relay, err := types.NewRelayEntry(strings.TrimSpace(url))
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay TXT record %s: %w", record, err)
+		}
+		relays = append(relays, relay)
+	}
+
+	_, srvs, err := resolver.LookupSRV(ctx, relayDNSService, "tcp", domain)
+	if err != nil && !isDNSNotFound(err) {
+		return nil, err
+	}
+	for _, srv := range srvs {
+		relay, err := resolveSRVRelay(ctx, resolver, srv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay SRV record %s:%d: %w", srv.Target, srv.Port, err)
+		}
+		relays = append(relays, relay)
+	}
+
+	if len(relays) == 0 {
+		return nil, errNoDNSRelays
+	}
+	return relays, nil
+}
+
+// resolveSRVRelay returns the relay of a SRV record, with the pubkey and scheme from the TXT records of the target
+func resolveSRVRelay(ctx context.Context, resolver relayResolver, srv *net.SRV) (types.RelayEntry, error) {
+	records, err := resolver.LookupTXT(ctx, srv.Target)
+	if err != nil && !isDNSNotFound(err) {
+		return types.RelayEntry{}, err
+	}
+
+	pubkey, scheme := "", "https"
+	for _, record := range records {
+		if value, ok := strings.CutPrefix(record, relayDNSPubkeyPrefix); ok {
+			pubkey = strings.TrimSpace(value)
+		}
+		if value, ok := strings.CutPrefix(record, relayDNSSchemePrefix); ok {
+			scheme = strings.TrimSpace(value)
+		}
+	}
+	if pubkey == "" {
+		return types.RelayEntry{}, errMissingDNSPubkey
+	}
+
+	host := net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port)))
+	return types.NewRelayEntry(fmt.Sprintf("%s://%s@%s?%s=%d&%s=%d", scheme, pubkey, host,
+		types.RelayArgTier, srv.Priority, types.RelayArgWeight, srv.Weight))
+}
+
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
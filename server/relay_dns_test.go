@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testResolver serves the DNS records of the map, other names are not found
+type testResolver struct {
+	txt map[string][]string
+	srv map[string][]*net.SRV
+}
+
+func (r testResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	cname := "_" + service + "._" + proto + "." + name
+	if records, ok := r.srv[cname]; ok {
+		return cname, records, nil
+	}
+	return "", nil, &net.DNSError{Err: "no such host", Name: cname, IsNotFound: true}
+}
+
+func (r testResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func TestDNSRelaySource(t *testing.T) {
+	resolver := testResolver{
+		txt: map[string][]string{
+			"relays.example.com": {
+				"v=spf1 -all",
+				"mev-relay=" + testRelayListRelayA + "?timeout_get_header=750ms",
+			},
+			"relay-b.example.com.": {"mev-relay-pubkey=0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae"},
+			"relay-c.example.com.": {
+				"mev-relay-pubkey=0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				"mev-relay-scheme=http",
+			},
+		},
+		srv: map[string][]*net.SRV{
+			"_mev-relay._tcp.relays.example.com": {
+				{Target: "relay-b.example.com.", Port: 443, Priority: 0, Weight: 10},
+				{Target: "relay-c.example.com.", Port: 18550, Priority: 1, Weight: 5},
+			},
+		},
+	}
+
+	t.Run("Discovers relays from TXT and SRV records", func(t *testing.T) {
+		relays, err := dnsRelaySource(resolver, "relays.example.com", time.Minute).fetch(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			testRelayListRelayA,
+			testRelayListRelayB + ":443",
+			"http://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-c.example.com:18550",
+		}, types.RelayEntriesToStrings(relays))
+
+		require.Equal(t, 750*time.Millisecond, relays[0].TimeoutGetHeader)
+		require.Equal(t, 0, relays[1].Tier)
+		require.Equal(t, 10, relays[1].Weight)
+		require.Equal(t, 1, relays[2].Tier)
+		require.Equal(t, 5, relays[2].Weight)
+	})
+
+	t.Run("Applies the discovered relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relaySources = []relaySource{dnsRelaySource(resolver, "relays.example.com", time.Minute)}
+
+		require.NoError(t, backend.boost.UpdateRelaySources())
+		require.Len(t, backend.boost.currentConfig().relays, 4)
+	})
+
+	t.Run("Fails without relay records", func(t *testing.T) {
+		_, err := dnsRelaySource(resolver, "example.com", time.Minute).fetch(context.Background())
+		require.ErrorIs(t, err, errNoDNSRelays)
+	})
+
+	t.Run("Fails without relay pubkey", func(t *testing.T) {
+		resolver := testResolver{srv: map[string][]*net.SRV{
+			"_mev-relay._tcp.relays.example.com": {{Target: "relay-d.example.com.", Port: 443}},
+		}}
+		_, err := dnsRelaySource(resolver, "relays.example.com", time.Minute).fetch(context.Background())
+		require.ErrorIs(t, err, errMissingDNSPubkey)
+	})
+}
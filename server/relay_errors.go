@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// relayErrorClass is the kind of a relay failure
+type relayErrorClass string
+
+const (
+	relayErrorTimeout           relayErrorClass = "timeout"
+	relayErrorConnectionRefused relayErrorClass = "connection_refused"
+	relayErrorHTTP4xx           relayErrorClass = "http_4xx"
+	relayErrorHTTP5xx           relayErrorClass = "http_5xx"
+	relayErrorBadSignature      relayErrorClass = "bad_signature"
+	relayErrorSchema            relayErrorClass = "schema_error"
+	relayErrorBlobMismatch      relayErrorClass = "blob_mismatch"
+	relayErrorPayloadMismatch   relayErrorClass = "payload_mismatch"
+	relayErrorParentHash        relayErrorClass = "parent_hash_mismatch"
+	relayErrorTimestamp         relayErrorClass = "timestamp_mismatch"
+	relayErrorOther             relayErrorClass = "other"
+)
+
+var relayErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "relay_errors_total",
+	Help:      "Number of failed relay requests and invalid relay responses, by error class",
+}, []string{"relay", "method", "class"})
+
+func init() {
+	metricsRegistry.MustRegister(relayErrors)
+}
+
+// classifyRelayError returns the class of an error returned by SendHTTPRequest(WithRetries), with the response code (if any)
+func classifyRelayError(code int, err error) relayErrorClass {
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case code >= 500:
+		return relayErrorHTTP5xx
+	case code >= 400:
+		return relayErrorHTTP4xx
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return relayErrorTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return relayErrorConnectionRefused
+	case errors.Is(err, errInvalidResponse), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return relayErrorSchema
+	default:
+		return relayErrorOther
+	}
+}
+
+// classifyPayloadError returns the class of an error returned by verifyPayload
+func classifyPayloadError(err error) relayErrorClass {
+	switch {
+	case errors.Is(err, errInvalidKZG), errors.Is(err, errInvalidKZGLength):
+		return relayErrorBlobMismatch
+	case errors.Is(err, errInvalidVersion), errors.Is(err, errInvalidBlockhash), errors.Is(err, errEmptyPayload),
+		errors.Is(err, errPayloadBlockHash), errors.Is(err, errPayloadTransactionsRoot), errors.Is(err, errPayloadWithdrawalsRoot),
+		errors.Is(err, errExecutionRequests):
+		return relayErrorPayloadMismatch
+	default:
+		return relayErrorOther
+	}
+}
+
+// recordRelayRequest records the outcome of a request to a relay in the relay health, classifying the error (if any)
+func (m *BoostService) recordRelayRequest(relay types.RelayEntry, method string, start time.Time, code int, err error) {
+	m.relayHealth.record(relay, time.Since(start), err)
+	var class relayErrorClass
+	if err != nil {
+		class = classifyRelayError(code, err)
+		m.recordRelayError(relay, method, class)
+	}
+
+	// Rejected requests don't mean the relay is down
+	if m.breaker != nil && class != relayErrorHTTP4xx && m.breaker.record(relay, err != nil) {
+		go m.replayRegistrationsToRecoveredRelay(relay)
+	}
+}
+
+// recordRelayError counts a relay failure by class in the metrics and the relay health
+func (m *BoostService) recordRelayError(relay types.RelayEntry, method string, class relayErrorClass) {
+	relayErrors.WithLabelValues(relayLabel(relay), method, string(class)).Inc()
+	m.metricsSink.Count("relay_errors", relayMetricTags(relay, map[string]string{"method": method, "class": string(class)}))
+	m.relayHealth.recordErrorClass(relay, class)
+	m.recordRelayFault(relay, class)
+}
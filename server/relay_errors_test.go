@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyRelayError(t *testing.T) {
+	send := func(t *testing.T, handler http.HandlerFunc, timeout time.Duration) (int, error) {
+		t.Helper()
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+		dst := new(testValueResponse)
+		return SendHTTPRequest(context.Background(), http.Client{Timeout: timeout}, http.MethodGet, ts.URL, "", nil, nil, dst)
+	}
+
+	t.Run("HTTP errors", func(t *testing.T) {
+		code, err := send(t, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) }, time.Second)
+		require.Equal(t, relayErrorHTTP5xx, classifyRelayError(code, err))
+
+		code, err = send(t, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadRequest) }, time.Second)
+		require.Equal(t, relayErrorHTTP4xx, classifyRelayError(code, err))
+
+		// The last code is kept after retries
+		require.Equal(t, relayErrorHTTP5xx, classifyRelayError(http.StatusInternalServerError, fmt.Errorf("%w: %w", errMaxRetriesExceeded, errHTTPErrorResponse)))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		code, err := send(t, func(_ http.ResponseWriter, _ *http.Request) { time.Sleep(50 * time.Millisecond) }, 10*time.Millisecond)
+		require.Equal(t, relayErrorTimeout, classifyRelayError(code, err))
+	})
+
+	t.Run("Connection refused", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		ts.Close()
+		code, err := SendHTTPRequest(context.Background(), http.Client{}, http.MethodGet, ts.URL, "", nil, nil, nil)
+		require.Equal(t, relayErrorConnectionRefused, classifyRelayError(code, err))
+	})
+
+	t.Run("Schema error", func(t *testing.T) {
+		code, err := send(t, func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(`{"value": 1`)) }, time.Second)
+		require.Equal(t, relayErrorSchema, classifyRelayError(code, err))
+
+		code, err = send(t, func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(`{"value": "1"}`)) }, time.Second)
+		require.Equal(t, relayErrorSchema, classifyRelayError(code, err))
+	})
+
+	t.Run("Payload errors", func(t *testing.T) {
+		require.Equal(t, relayErrorBlobMismatch, classifyPayloadError(errInvalidKZG))
+		require.Equal(t, relayErrorBlobMismatch, classifyPayloadError(errInvalidKZGLength))
+		require.Equal(t, relayErrorPayloadMismatch, classifyPayloadError(errInvalidBlockhash))
+		require.Equal(t, relayErrorPayloadMismatch, classifyPayloadError(errPayloadBlockHash))
+		require.Equal(t, relayErrorPayloadMismatch, classifyPayloadError(errPayloadWithdrawalsRoot))
+	})
+}
+
+// testValueResponse is a minimal response type for the classification tests
+type testValueResponse struct {
+	Value int `json:"value"`
+}
+
+func TestRelayErrorsInStatus(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+		12345,
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionDeneb,
+	)
+	backend.relays[0].GetHeaderResponse.Deneb.Signature = phase0.BLSSignature{}
+
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	health := backend.boost.relayHealth.snapshot([]types.RelayEntry{backend.relays[0].RelayEntry})
+	require.Equal(t, map[string]uint64{string(relayErrorBadSignature): 1}, health[0].Errors)
+	require.Zero(t, health[0].RecentErrorRate)
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+const (
+	// relayHealthWindow is the number of recent requests the error rate is computed over
+	relayHealthWindow = 100
+	// relayLatencyAlpha is the weight of the latest request in the latency moving average
+	relayLatencyAlpha = 0.2
+)
+
+// RelayHealth is the recent request health of a relay, as returned by the verbose status endpoint
+type RelayHealth struct {
+	Relay           string  `json:"relay"`
+	LastSuccessMs   int64   `json:"last_success_ms,omitempty"`
+	LastError       string  `json:"last_error,omitempty"`
+	RecentRequests  int     `json:"recent_requests"`
+	RecentErrorRate float64 `json:"recent_error_rate"`
+	LatencyMs       float64 `json:"latency_ms"`        // moving average over successful requests
+	Circuit         string  `json:"circuit,omitempty"` // the circuit breaker state, if enabled
+
+	// QuarantinedUntilMs is the end of the quarantine of the relay, if quarantined
+	QuarantinedUntilMs int64 `json:"quarantined_until_ms,omitempty"`
+
+	// Latency percentiles of the recent getHeader requests and the adapted timeout, if adaptive timeouts are enabled
+	LatencyP50Ms       int64 `json:"latency_p50_ms,omitempty"`
+	LatencyP99Ms       int64 `json:"latency_p99_ms,omitempty"`
+	TimeoutGetHeaderMs int64 `json:"timeout_get_header_ms,omitempty"`
+
+	// ConsensusVersions are the versions of the bids and payloads of the relay since startup, i.e. deneb or electra
+	ConsensusVersions []string `json:"consensus_versions,omitempty"`
+
+	// Errors are the number of failures since startup by class, i.e. timeout, http_5xx or bad_signature
+	Errors map[string]uint64 `json:"errors,omitempty"`
+}
+
+// StatusResponse is the response body of the status endpoint, if called with verbose=true
+type StatusResponse struct {
+	Relays []RelayHealth `json:"relays"`
+}
+
+// relayHealth tracks the outcome of the last relayHealthWindow requests to a relay in a ring buffer
+type relayHealth struct {
+	lastSuccess time.Time
+	lastError   string
+	failed      [relayHealthWindow]bool
+	count, next int
+	latencyMs   float64
+	errors      map[relayErrorClass]uint64
+}
+
+// relayHealthStore keeps the relayHealth of all relays
+type relayHealthStore struct {
+	mu     sync.Mutex
+	health map[string]*relayHealth
+}
+
+func newRelayHealthStore() *relayHealthStore {
+	return &relayHealthStore{health: make(map[string]*relayHealth)}
+}
+
+// record adds the outcome of a request to a relay
+func (s *relayHealthStore) record(relay types.RelayEntry, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.get(relay)
+	h.failed[h.next] = err != nil
+	h.next = (h.next + 1) % relayHealthWindow
+	h.count = min(h.count+1, relayHealthWindow)
+	if err != nil {
+		h.lastError = err.Error()
+		return
+	}
+
+	h.lastSuccess = time.Now()
+	latencyMs := float64(duration.Microseconds()) / 1000
+	if h.latencyMs == 0 {
+		h.latencyMs = latencyMs
+	} else {
+		h.latencyMs = relayLatencyAlpha*latencyMs + (1-relayLatencyAlpha)*h.latencyMs
+	}
+}
+
+// recordErrorClass counts a failure of the relay by class
+func (s *relayHealthStore) recordErrorClass(relay types.RelayEntry, class relayErrorClass) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(relay).errors[class]++
+}
+
+// get returns the health of a relay, creating it if needed. The caller must hold the lock.
+func (s *relayHealthStore) get(relay types.RelayEntry) *relayHealth {
+	h, ok := s.health[relay.String()]
+	if !ok {
+		h = &relayHealth{errors: make(map[relayErrorClass]uint64)}
+		s.health[relay.String()] = h
+	}
+	return h
+}
+
+// snapshot returns the health of the given relays, in the same order
+func (s *relayHealthStore) snapshot(relays []types.RelayEntry) []RelayHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]RelayHealth, len(relays))
+	for i, relay := range relays {
+		ret[i] = RelayHealth{Relay: relay.GetURI("")}
+		h, ok := s.health[relay.String()]
+		if !ok {
+			continue
+		}
+		failures := 0
+		for _, failed := range h.failed[:h.count] {
+			if failed {
+				failures++
+			}
+		}
+		if !h.lastSuccess.IsZero() {
+			ret[i].LastSuccessMs = h.lastSuccess.UnixMilli()
+		}
+		ret[i].LastError = h.lastError
+		ret[i].RecentRequests = h.count
+		if h.count > 0 {
+			ret[i].RecentErrorRate = float64(failures) / float64(h.count)
+		}
+		ret[i].LatencyMs = h.latencyMs
+		if len(h.errors) > 0 {
+			ret[i].Errors = make(map[string]uint64, len(h.errors))
+			for class, num := range h.errors {
+				ret[i].Errors[string(class)] = num
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayHealth(t *testing.T) {
+	relay := mock.NewRelay(t).RelayEntry
+	store := newRelayHealthStore()
+
+	// Unknown relays have no health yet
+	health := store.snapshot([]types.RelayEntry{relay})
+	require.Equal(t, []RelayHealth{{Relay: relay.GetURI("")}}, health)
+
+	store.record(relay, 100*time.Millisecond, nil)
+	store.record(relay, 200*time.Millisecond, errHTTPErrorResponse)
+	store.record(relay, 200*time.Millisecond, nil)
+	store.record(relay, 0, errHTTPErrorResponse)
+
+	health = store.snapshot([]types.RelayEntry{relay})
+	require.Equal(t, 4, health[0].RecentRequests)
+	require.InDelta(t, 0.5, health[0].RecentErrorRate, 0.0001)
+	require.InDelta(t, 120.0, health[0].LatencyMs, 0.0001)
+	require.Equal(t, errHTTPErrorResponse.Error(), health[0].LastError)
+	require.Positive(t, health[0].LastSuccessMs)
+
+	// Only the most recent requests count towards the error rate
+	for range relayHealthWindow {
+		store.record(relay, 100*time.Millisecond, nil)
+	}
+	health = store.snapshot([]types.RelayEntry{relay})
+	require.Equal(t, relayHealthWindow, health[0].RecentRequests)
+	require.Zero(t, health[0].RecentErrorRate)
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// defaultRelayHostMaxTTL is the longest the addresses of a relay host are cached, if not configured
+	defaultRelayHostMaxTTL = 30 * time.Second
+	// relayHostMinTTL is the shortest the addresses of a relay host are cached, for records with lower TTLs
+	relayHostMinTTL = time.Second
+
+	relayHostLookupTimeout = 2 * time.Second
+)
+
+var (
+	errNoRelayHostAddrs = errors.New("no addresses found for relay host")
+	errDNSResponse      = errors.New("invalid DNS response")
+)
+
+// relayHost are the cached addresses of a relay host
+type relayHost struct {
+	addrs   []netip.Addr
+	expires time.Time
+}
+
+// relayHosts resolves the host names of the relays for their connections, with the DNS server if set. The addresses
+// are cached for the TTL of the DNS records, up to maxTTL, and re-resolved once they expired, so a relay moving to other
+// addresses (DNS failover) is connected at the new addresses within the TTL. The system resolver doesn't report TTLs,
+// its addresses are cached for maxTTL.
+type relayHosts struct {
+	server string
+	maxTTL time.Duration
+	lookup func(ctx context.Context, host string) ([]netip.Addr, time.Duration, error)
+
+	mu    sync.Mutex
+	hosts map[string]relayHost
+}
+
+// newRelayHosts returns the relay host resolver querying the DNS server (host:port, port 53 if not given), or the system
+// resolver if empty
+func newRelayHosts(server string, maxTTL time.Duration) *relayHosts {
+	if maxTTL <= 0 {
+		maxTTL = defaultRelayHostMaxTTL
+	}
+	if _, _, err := net.SplitHostPort(server); server != "" && err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	h := &relayHosts{server: server, maxTTL: maxTTL, hosts: make(map[string]relayHost)}
+	h.lookup = h.lookupSystem
+	if server != "" {
+		h.lookup = h.lookupServer
+	}
+	return h
+}
+
+// resolver returns the resolver of the relay discovery by DNS, querying the DNS server if set
+func (h *relayHosts) resolver() *net.Resolver {
+	if h.server == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, h.server)
+		},
+	}
+}
+
+// addrs returns the addresses of the host, resolving them if not cached or expired. The expired addresses are used if
+// the host can't be resolved.
+func (h *relayHosts) addrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	h.mu.Lock()
+	cached, ok := h.hosts[host]
+	h.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.addrs, nil
+	}
+
+	resolved, _, err := h.resolve(ctx, host)
+	if err != nil && ok {
+		return cached.addrs, nil
+	}
+	return resolved, err
+}
+
+// resolve looks up the addresses of the host and caches them, and returns whether they changed
+func (h *relayHosts) resolve(ctx context.Context, host string) ([]netip.Addr, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, relayHostLookupTimeout)
+	defer cancel()
+	addrs, ttl, err := h.lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("%w: %s", errNoRelayHostAddrs, host)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	slices.SortFunc(addrs, netip.Addr.Compare)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous, ok := h.hosts[host]
+	h.hosts[host] = relayHost{addrs: addrs, expires: time.Now().Add(min(max(ttl, relayHostMinTTL), h.maxTTL))}
+	return addrs, ok && !slices.Equal(previous.addrs, addrs), nil
+}
+
+// refresh re-resolves the expired hosts, and returns the hosts with changed addresses
+func (h *relayHosts) refresh(ctx context.Context) map[string][]netip.Addr {
+	now := time.Now()
+	h.mu.Lock()
+	var expired []string
+	for host, cached := range h.hosts {
+		if !now.Before(cached.expires) {
+			expired = append(expired, host)
+		}
+	}
+	h.mu.Unlock()
+
+	changed := make(map[string][]netip.Addr)
+	for _, host := range expired {
+		if addrs, ok, err := h.resolve(ctx, host); err == nil && ok {
+			changed[host] = addrs
+		}
+	}
+	return changed
+}
+
+// dialContext connects to the address with the dialer, resolving host names with the relay host resolver. The
+// addresses of the host are tried in order.
+func (h *relayHosts) dialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := h.addrs(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// lookupSystem resolves the host with the system resolver, which doesn't report the TTL
+func (h *relayHosts) lookupSystem(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	for i, addr := range addrs {
+		addrs[i] = addr.Unmap()
+	}
+	return addrs, h.maxTTL, err
+}
+
+// lookupServer resolves the A and AAAA records of the host with the DNS server, the TTL is the lowest of the records
+func (h *relayHosts) lookupServer(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+	var addrs []netip.Addr
+	ttl := h.maxTTL
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		records, recordsTTL, err := queryDNS(ctx, h.server, host, qtype)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(records) > 0 {
+			addrs = append(addrs, records...)
+			ttl = min(ttl, recordsTTL)
+		}
+	}
+	return addrs, ttl, nil
+}
+
+// queryDNS returns the addresses of the A or AAAA records of the name and their lowest TTL, asking the DNS server over
+// UDP. Names without records have no addresses.
+func queryDNS(ctx context.Context, server, name string, qtype dnsmessage.Type) ([]netip.Addr, time.Duration, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true}, //nolint:gosec
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(packed); err != nil {
+		return nil, 0, err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(buf[:n]); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", errDNSResponse, err)
+	}
+	switch {
+	case resp.ID != query.ID:
+		return nil, 0, fmt.Errorf("%w: unexpected ID", errDNSResponse)
+	case resp.RCode == dnsmessage.RCodeNameError:
+		return nil, 0, nil
+	case resp.RCode != dnsmessage.RCodeSuccess:
+		return nil, 0, fmt.Errorf("%w: %s for %s", errDNSResponse, resp.RCode, name)
+	}
+
+	var addrs []netip.Addr
+	var ttl uint32
+	for _, answer := range resp.Answers {
+		var addr netip.Addr
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			addr = netip.AddrFrom4(body.A)
+		case *dnsmessage.AAAAResource:
+			addr = netip.AddrFrom16(body.AAAA)
+		default:
+			continue
+		}
+		if len(addrs) == 0 || answer.Header.TTL < ttl {
+			ttl = answer.Header.TTL
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, time.Duration(ttl) * time.Second, nil
+}
+
+// startRelayHostsRefreshTask re-resolves the expired relay hosts once per slot. The idle relay connections are closed
+// if the addresses of a host changed, so the next requests connect to the new addresses.
+func (m *BoostService) startRelayHostsRefreshTask() {
+	for {
+		time.Sleep(time.Duration(m.slotTimeSec) * time.Second)
+		m.refreshRelayHosts()
+	}
+}
+
+func (m *BoostService) refreshRelayHosts() {
+	changed := m.relayHosts.refresh(context.Background())
+	if len(changed) == 0 {
+		return
+	}
+	for host, addrs := range changed {
+		m.log.WithFields(logrus.Fields{"host": host, "addrs": addrs}).Info("relay host addresses changed")
+	}
+	m.relayTransports.closeIdleConnections()
+}
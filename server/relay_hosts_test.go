@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// testDNSServer answers the A queries of its records with a TTL, and the other queries without answers
+type testDNSServer struct {
+	addr string
+	mu   sync.Mutex
+	ttl  uint32
+	a    map[string]netip.Addr
+}
+
+func (s *testDNSServer) set(name string, addr netip.Addr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.a[name] = addr
+}
+
+func startTestDNSServer(t *testing.T, ttl uint32) *testDNSServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	s := &testDNSServer{addr: conn.LocalAddr().String(), ttl: ttl, a: make(map[string]netip.Addr)}
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			question := query.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			s.mu.Lock()
+			addr, ok := s.a[question.Name.String()]
+			s.mu.Unlock()
+			switch {
+			case !ok:
+				resp.RCode = dnsmessage.RCodeNameError
+			case question.Type == dnsmessage.TypeA:
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: s.ttl},
+					Body:   &dnsmessage.AResource{A: addr.As4()},
+				}}
+			}
+			packed, err := resp.Pack()
+			if err == nil {
+				_, _ = conn.WriteTo(packed, from)
+			}
+		}
+	}()
+	return s
+}
+
+func TestRelayHosts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Addresses are cached for the TTL of the records", func(t *testing.T) {
+		dns := startTestDNSServer(t, 3600)
+		dns.set("relay.test.", netip.MustParseAddr("127.0.0.1"))
+		hosts := newRelayHosts(dns.addr, time.Minute)
+
+		addrs, err := hosts.addrs(ctx, "relay.test")
+		require.NoError(t, err)
+		require.Equal(t, []netip.Addr{netip.MustParseAddr("127.0.0.1")}, addrs)
+		require.WithinDuration(t, time.Now().Add(time.Minute), hosts.hosts["relay.test"].expires, time.Second)
+
+		// The cached addresses are used until they expire
+		dns.set("relay.test.", netip.MustParseAddr("127.0.0.2"))
+		addrs, err = hosts.addrs(ctx, "relay.test")
+		require.NoError(t, err)
+		require.Equal(t, []netip.Addr{netip.MustParseAddr("127.0.0.1")}, addrs)
+		require.Empty(t, hosts.refresh(ctx))
+
+		hosts.hosts["relay.test"] = relayHost{addrs: addrs, expires: time.Now()}
+		require.Equal(t, map[string][]netip.Addr{"relay.test": {netip.MustParseAddr("127.0.0.2")}}, hosts.refresh(ctx))
+
+		_, err = hosts.addrs(ctx, "unknown.test")
+		require.ErrorIs(t, err, errNoRelayHostAddrs)
+	})
+
+	t.Run("Records with low TTLs are cached for the minimum TTL", func(t *testing.T) {
+		dns := startTestDNSServer(t, 0)
+		dns.set("relay.test.", netip.MustParseAddr("127.0.0.1"))
+		hosts := newRelayHosts(dns.addr, time.Minute)
+		_, err := hosts.addrs(ctx, "relay.test")
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(relayHostMinTTL), hosts.hosts["relay.test"].expires, 100*time.Millisecond)
+	})
+
+	t.Run("Expired addresses are used if the host can't be resolved", func(t *testing.T) {
+		hosts := newRelayHosts("127.0.0.1:1", time.Minute)
+		addrs := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+		hosts.hosts["relay.test"] = relayHost{addrs: addrs, expires: time.Now()}
+		resolved, err := hosts.addrs(ctx, "relay.test")
+		require.NoError(t, err)
+		require.Equal(t, addrs, resolved)
+	})
+
+	t.Run("Relays move to their new addresses", func(t *testing.T) {
+		// Two relays on the same port of two loopback addresses
+		relayA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Relay", "a")
+		}))
+		t.Cleanup(relayA.Close)
+		_, port, err := net.SplitHostPort(relayA.Listener.Addr().String())
+		require.NoError(t, err)
+		listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+		if err != nil {
+			t.Skip("127.0.0.2 not available:", err)
+		}
+		relayB := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Relay", "b")
+		}))
+		relayB.Listener = listener
+		relayB.Start()
+		t.Cleanup(relayB.Close)
+
+		dns := startTestDNSServer(t, 1)
+		dns.set("relay.test.", netip.MustParseAddr("127.0.0.1"))
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relayHosts = newRelayHosts(dns.addr, time.Minute)
+		backend.boost.relayTransports.hosts = backend.boost.relayHosts
+
+		relayURL, err := url.Parse("http://relay.test:" + port)
+		require.NoError(t, err)
+		relay := types.RelayEntry{URL: relayURL}
+		request := func() string {
+			t.Helper()
+			client := backend.boost.relayTransports.client(http.Client{}, relay)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, relayURL.String(), nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			return resp.Header.Get("X-Relay")
+		}
+		require.Equal(t, "a", request())
+
+		dns.set("relay.test.", netip.MustParseAddr("127.0.0.2"))
+		require.Equal(t, "a", request())
+		time.Sleep(relayHostMinTTL)
+		backend.boost.refreshRelayHosts()
+		require.Equal(t, "b", request())
+	})
+}
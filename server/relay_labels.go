@@ -0,0 +1,40 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+var errInvalidLabelPreference = errors.New("relay label preference must be above -100 percent")
+
+// labelPreferences are the premiums of the bids of relays with a label when comparing bids, by label. With 0.05 for
+// non-filtering, the bids of non-filtering relays are preferred unless other bids are more than 5% higher.
+type labelPreferences map[string]float64
+
+// newLabelPreferences returns the label preferences of the percentages by label
+func newLabelPreferences(percents map[string]float64) (labelPreferences, error) {
+	preferences := make(labelPreferences, len(percents))
+	for label, percent := range percents {
+		if percent <= -100 {
+			return nil, errInvalidLabelPreference
+		}
+		preferences[label] = percent / 100
+	}
+	return preferences, nil
+}
+
+// factor returns the factor of the value of a bid delivered by the relays, the product of the premiums of the labels of
+// any of the relays
+func (p labelPreferences) factor(relays []types.RelayEntry) float64 {
+	factor := 1.0
+	for label, premium := range p {
+		for _, relay := range relays {
+			if relay.HasLabel(label) {
+				factor *= 1 + premium
+				break
+			}
+		}
+	}
+	return factor
+}
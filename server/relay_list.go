@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// relayListRequestTimeout is the timeout for fetching the relay list
+const relayListRequestTimeout = 10 * time.Second
+
+var (
+	errInvalidRelayListSignature = errors.New("invalid relay list signature")
+	errInvalidRelayList          = errors.New("invalid relay list")
+)
+
+// relaySource provides relays in addition to the static relays, i.e. the relay list URL or DNS discovery
+type relaySource struct {
+	name     string
+	interval time.Duration
+	fetch    func(ctx context.Context) ([]types.RelayEntry, error)
+}
+
+// signedRelayList is the response of the relay list URL. The signature is the ed25519 signature of the message bytes.
+type signedRelayList struct {
+	Message   json.RawMessage `json:"message"`
+	Signature string          `json:"signature"`
+}
+
+// relayListMessage is the signed message of the relay list. Relays are objects with an url field and the relay options,
+// i.e. {"url": "https://0x...@relay.example.com", "timeout_get_header": "750ms", "tier": 1}
+type relayListMessage struct {
+	Relays []map[string]any `json:"relays"`
+}
+
+// UpdateRelaySources fetches the relays of all relay sources and applies them right away, i.e. on startup
+func (m *BoostService) UpdateRelaySources() error {
+	var errs []error
+	for _, source := range m.relaySources {
+		relays, err := source.fetch(context.Background())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.name, err))
+			continue
+		}
+		m.setRemoteRelays(source.name, relays)
+	}
+	return errors.Join(errs...)
+}
+
+// StartRelaySourceUpdates periodically fetches the relays of the relay sources. Changes are applied between slots, see
+// untilRelayListApply.
+func (m *BoostService) StartRelaySourceUpdates() {
+	for _, source := range m.relaySources {
+		go m.updateRelaySource(source)
+	}
+}
+
+func (m *BoostService) updateRelaySource(source relaySource) {
+	log := m.log.WithFields(logrus.Fields{
+		"method": "updateRelaySource",
+		"source": source.name,
+	})
+	for {
+		time.Sleep(source.interval)
+
+		relays, err := source.fetch(context.Background())
+		if err != nil {
+			log.WithError(err).Warn("failed fetching relays")
+			continue
+		}
+
+		m.configLock.RLock()
+		unchanged := slices.Equal(relayListKeys(relays), relayListKeys(m.remoteRelaySources[source.name]))
+		m.configLock.RUnlock()
+		if unchanged {
+			continue
+		}
+
+		wait := m.untilRelayListApply(time.Now())
+		log.WithField("relays", types.RelayEntriesToStrings(relays)).Infof("relays changed, applying them in %s", wait)
+		time.Sleep(wait)
+		m.setRemoteRelays(source.name, relays)
+	}
+}
+
+// relayListSource returns the relay source of the relay list URL
+func relayListSource(url string, pubkey ed25519.PublicKey, interval time.Duration) relaySource {
+	return relaySource{
+		name:     url,
+		interval: interval,
+		fetch: func(ctx context.Context) ([]types.RelayEntry, error) {
+			return fetchRelayList(ctx, url, pubkey)
+		},
+	}
+}
+
+// fetchRelayList fetches the relay list and checks its signature
+func fetchRelayList(ctx context.Context, url string, pubkey ed25519.PublicKey) ([]types.RelayEntry, error) {
+	client := http.Client{Timeout: relayListRequestTimeout}
+	list := new(signedRelayList)
+	if _, err := SendHTTPRequest(ctx, client, http.MethodGet, url, "", nil, nil, list); err != nil {
+		return nil, err
+	}
+
+	signature, err := hexutil.Decode(list.Signature)
+	if err != nil || !ed25519.Verify(pubkey, list.Message, signature) {
+		return nil, errInvalidRelayListSignature
+	}
+
+	message := new(relayListMessage)
+	if err := json.Unmarshal(list.Message, message); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRelayList, err)
+	}
+	if len(message.Relays) == 0 {
+		return nil, fmt.Errorf("%w: no relays", errInvalidRelayList)
+	}
+
+	relays := make([]types.RelayEntry, 0, len(message.Relays))
+	for _, entry := range message.Relays {
+		relay, err := parseRelayListEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		relays = append(relays, relay)
+	}
+	return relays, nil
+}
+
+// parseRelayListEntry returns the relay of a relay list entry, the options are passed on as relay URL query args
+func parseRelayListEntry(entry map[string]any) (types.RelayEntry, error) {
+	url, ok := entry["url"].(string)
+	if !ok {
+		return types.RelayEntry{}, fmt.Errorf("%w: missing url", errInvalidRelayList)
+	}
+
+	args := neturl.Values{}
+	for option, value := range entry {
+		switch {
+		case option == "url":
+		case option == types.RelayArgHeader:
+			for _, header := range relayListValues(value) {
+				args.Add(option, header)
+			}
+		case slices.Contains(types.RelayArgs, option):
+			args.Set(option, fmt.Sprint(value))
+		default:
+			return types.RelayEntry{}, fmt.Errorf("%w: unknown option %s for relay %s", errInvalidRelayList, option, url)
+		}
+	}
+	if len(args) > 0 {
+		separator := "?"
+		if strings.Contains(url, "?") {
+			separator = "&"
+		}
+		url += separator + args.Encode()
+	}
+
+	relay, err := types.NewRelayEntry(url)
+	if err != nil {
+		return types.RelayEntry{}, fmt.Errorf("%w: %w", errInvalidRelayList, err)
+	}
+	return relay, nil
+}
+
+// setRemoteRelays applies the relays of a relay source. They are used in addition to the relays from the flags and the
+// config file.
+func (m *BoostService) setRemoteRelays(source string, relays []types.RelayEntry) {
+	defer m.replayRegistrationsToNewRelays(m.currentConfig())
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+	m.remoteRelaySources[source] = relays
+
+	sources := make([]string, 0, len(m.remoteRelaySources))
+	for name := range m.remoteRelaySources {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+	m.remoteRelays = nil
+	for _, name := range sources {
+		m.remoteRelays = mergeRelays(m.remoteRelays, m.remoteRelaySources[name])
+	}
+
+	m.log.WithFields(logrus.Fields{
+		"relays": types.RelayEntriesToStrings(relays),
+		"source": source,
+	}).Info("applied relays")
+}
+
+// untilRelayListApply returns how long to wait before applying new relays of a relay source. They are applied two
+// thirds into the slot, after the getPayload of the slot and before the getHeader of the next slot.
+func (m *BoostService) untilRelayListApply(now time.Time) time.Duration {
+	slotDuration := time.Duration(m.slotTimeSec) * time.Second
+	intoSlot := now.Sub(time.Unix(int64(m.genesisTime), 0)) % slotDuration
+	if intoSlot < 0 {
+		intoSlot += slotDuration
+	}
+
+	wait := slotDuration*2/3 - intoSlot
+	if wait < 0 {
+		wait += slotDuration
+	}
+	return wait
+}
+
+// mergeRelays returns the relays followed by the additional relays which are not among them
+func mergeRelays(relays, additional []types.RelayEntry) []types.RelayEntry {
+	ret := slices.Clip(relays)
+	for _, relay := range additional {
+		if !containsRelay(ret, relay) {
+			ret = append(ret, relay)
+		}
+	}
+	return ret
+}
+
+// relayListValues returns the entries of a list option, or the option value
+func relayListValues(value any) []string {
+	entries, ok := value.([]any)
+	if !ok {
+		return []string{fmt.Sprint(value)}
+	}
+	ret := make([]string, len(entries))
+	for i, entry := range entries {
+		ret[i] = fmt.Sprint(entry)
+	}
+	return ret
+}
+
+// relayListKeys identify the relays of a relay list including their options, to detect changes
+func relayListKeys(relays []types.RelayEntry) []string {
+	ret := make([]string, 0, len(relays))
+	for _, relay := range relays {
+		ret = append(ret, fmt.Sprintf("%s %s %s %s %d %d %s %t %g %t %v %t %d %s %s %s %s %v %s %v", relay.String(), relay.TimeoutGetHeader, relay.TimeoutGetPayload,
+			relay.TimeoutRegVal, relay.Tier, relay.Weight, relay.SignatureCheck, relay.Backup, relay.BoostFactor, relay.Constraints,
+			relay.Labels, relay.SSZ, relay.MaxRetries, relay.RetryBackoff, relay.RetryJitter,
+			relay.ClientCert, relay.ClientKey, relay.Proxy, relay.BasicAuth.String(), relay.Headers))
+	}
+	return ret
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRelayListRelayA = "https://0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249@relay-a.example.com"
+	testRelayListRelayB = "https://0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae@relay-b.example.com"
+)
+
+// newRelayListServer serves the relay list message, signed with the key
+func newRelayListServer(t *testing.T, key ed25519.PrivateKey, message string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The message is written as is, re-encoding would change the signed bytes
+		signature := hexutil.Encode(ed25519.Sign(key, []byte(message)))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"message": %s, "signature": "%s"}`, message, signature)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRelayList(t *testing.T) {
+	pubkey, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	newRelayListBackend := func(t *testing.T, srv *httptest.Server) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relaySources = []relaySource{relayListSource(srv.URL, pubkey, time.Minute)}
+		return backend
+	}
+
+	t.Run("Applies the relays in addition to the static relays", func(t *testing.T) {
+		srv := newRelayListServer(t, key, `{"relays": [{"url": "`+testRelayListRelayA+`", "timeout_get_header": "750ms", "tier": 1, "header": ["X-Api-Key: abc", "X-Team: eu"]}, {"url": "`+testRelayListRelayB+`"}]}`)
+		backend := newRelayListBackend(t, srv)
+
+		require.NoError(t, backend.boost.UpdateRelaySources())
+		relays := backend.boost.currentConfig().relays
+		require.Equal(t, []string{backend.relays[0].RelayEntry.String(), testRelayListRelayA, testRelayListRelayB}, types.RelayEntriesToStrings(relays))
+		require.Equal(t, 750*time.Millisecond, relays[1].TimeoutGetHeader)
+		require.Equal(t, 1, relays[1].Tier)
+		require.Equal(t, "abc", relays[1].Headers.Get("X-Api-Key"))
+		require.Equal(t, "eu", relays[1].Headers.Get("X-Team"))
+
+		// Reloading the static relays keeps the relays of the relay list
+		require.NoError(t, backend.boost.Reload(ReloadOpts{}))
+		require.Equal(t, []string{testRelayListRelayA, testRelayListRelayB}, types.RelayEntriesToStrings(backend.boost.currentConfig().relays))
+	})
+
+	t.Run("Rejects an invalid signature", func(t *testing.T) {
+		srv := newRelayListServer(t, otherKey, `{"relays": [{"url": "`+testRelayListRelayA+`"}]}`)
+		backend := newRelayListBackend(t, srv)
+
+		require.ErrorIs(t, backend.boost.UpdateRelaySources(), errInvalidRelayListSignature)
+		require.Len(t, backend.boost.currentConfig().relays, 1)
+	})
+
+	t.Run("Rejects an invalid relay list", func(t *testing.T) {
+		for _, message := range []string{
+			`{"relays": []}`,
+			`{"relays": [{"timeout_get_header": "750ms"}]}`,
+			`{"relays": [{"url": "` + testRelayListRelayA + `", "priority": 1}]}`,
+			`{"relays": [{"url": "` + testRelayListRelayA + `", "tier": -1}]}`,
+		} {
+			backend := newRelayListBackend(t, newRelayListServer(t, key, message))
+			require.ErrorIs(t, backend.boost.UpdateRelaySources(), errInvalidRelayList, message)
+		}
+	})
+
+	t.Run("Applies changes between slots", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.genesisTime = 1000
+		genesis := time.Unix(1000, 0)
+
+		require.Equal(t, 8*time.Second, backend.boost.untilRelayListApply(genesis))
+		require.Equal(t, 5*time.Second, backend.boost.untilRelayListApply(genesis.Add(15*time.Second)))
+		require.Equal(t, 11*time.Second, backend.boost.untilRelayListApply(genesis.Add(9*time.Second)))
+
+		backend.boost.slotTimeSec = 5 // Gnosis
+		require.Equal(t, 5*time.Second*2/3-time.Second, backend.boost.untilRelayListApply(genesis.Add(11*time.Second)))
+	})
+}
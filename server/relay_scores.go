@@ -0,0 +1,105 @@
+package server
+
+import (
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/flashbots/mev-boost/server/relayscore"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// relayAuctions counts the auctions a relay was queried in and how it bid, for the relay score
+type relayAuctions struct {
+	auctions   uint64
+	bids       uint64
+	valueShare float64
+}
+
+// relayAuctionStore keeps the relayAuctions of all relays
+type relayAuctionStore struct {
+	mu       sync.Mutex
+	auctions map[string]*relayAuctions
+}
+
+func newRelayAuctionStore() *relayAuctionStore {
+	return &relayAuctionStore{auctions: make(map[string]*relayAuctions)}
+}
+
+// record adds an auction of the queried relays. Bids below the min-bid count, they are valid bids of the relay.
+func (s *relayAuctionStore) record(queried []types.RelayEntry, records []*BidAuditRecord) {
+	relayBids := make(map[string]*big.Int)
+	best := new(big.Int)
+	for _, record := range records {
+		if record.RejectionReason != "" && record.RejectionReason != bidRejectedBelowMinBid {
+			continue
+		}
+		value, ok := new(big.Int).SetString(record.Value, 10)
+		if !ok {
+			continue
+		}
+		if highest, ok := relayBids[record.Relay]; !ok || value.Cmp(highest) > 0 {
+			relayBids[record.Relay] = value
+		}
+		if value.Cmp(best) > 0 {
+			best = value
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, relay := range queried {
+		a, ok := s.auctions[relay.String()]
+		if !ok {
+			a = new(relayAuctions)
+			s.auctions[relay.String()] = a
+		}
+		a.auctions++
+		value, ok := relayBids[relay.GetURI("")]
+		if !ok {
+			continue
+		}
+		a.bids++
+		if best.Sign() > 0 {
+			share, _ := new(big.Rat).SetFrac(value, best).Float64()
+			a.valueShare += share
+		}
+	}
+}
+
+// get returns a copy of the auction counters of a relay
+func (s *relayAuctionStore) get(relay types.RelayEntry) relayAuctions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.auctions[relay.String()]; ok {
+		return *a
+	}
+	return relayAuctions{}
+}
+
+// relayScores returns the scores of the given relays, in the same order
+func (m *BoostService) relayScores(relays []types.RelayEntry) []relayscore.Score {
+	health := m.relayHealth.snapshot(relays)
+	stats := m.relayStats.snapshot(relays)
+	scores := make([]relayscore.Score, len(relays))
+	for i, relay := range relays {
+		auctions := m.relayAuctions.get(relay)
+		scores[i] = relayscore.Compute(relay.GetURI(""), relayscore.Inputs{
+			LatencyMs:        health[i].LatencyMs,
+			ErrorRate:        health[i].RecentErrorRate,
+			Auctions:         auctions.auctions,
+			Bids:             auctions.bids,
+			ValueShare:       auctions.valueShare,
+			Deliveries:       stats[i].PayloadsDelivered + stats[i].PayloadsMissed,
+			FailedDeliveries: stats[i].PayloadsMissed + stats[i].PayloadsUnconfirmed + stats[i].BlocksMissed,
+		}, relayscore.DefaultWeights)
+	}
+	return scores
+}
+
+// handleRelayScores returns the scores of all relays, from the best to the worst relay
+func (m *BoostService) handleRelayScores(w http.ResponseWriter, _ *http.Request) {
+	scores := m.relayScores(m.currentConfig().relays)
+	relayscore.Rank(scores)
+	m.respondOK(w, scores)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/relayscore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayScores(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	backend := newTestBackend(t, 3, time.Second)
+
+	// Relay 0 bids half of relay 1, relay 2 is down
+	backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+		20000,
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionDeneb,
+	)
+	backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+		40000,
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab8",
+		"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionDeneb,
+	)
+	backend.relays[2].Server.Close()
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	auctions := backend.boost.relayAuctions.get(backend.relays[0].RelayEntry)
+	require.Equal(t, relayAuctions{auctions: 1, bids: 1, valueShare: 0.5}, auctions)
+	auctions = backend.boost.relayAuctions.get(backend.relays[1].RelayEntry)
+	require.Equal(t, relayAuctions{auctions: 1, bids: 1, valueShare: 1}, auctions)
+	auctions = backend.boost.relayAuctions.get(backend.relays[2].RelayEntry)
+	require.Equal(t, relayAuctions{auctions: 1}, auctions)
+
+	rr = backend.request(t, http.MethodGet, params.PathRelayScores, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	scores := []relayscore.Score{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &scores))
+	require.Len(t, scores, 3)
+	require.Equal(t, backend.relays[1].RelayEntry.GetURI(""), scores[0].Relay)
+	require.Equal(t, backend.relays[0].RelayEntry.GetURI(""), scores[1].Relay)
+	require.Equal(t, backend.relays[2].RelayEntry.GetURI(""), scores[2].Relay)
+	require.InDelta(t, 0.5, scores[1].ValueShare, 1e-9)
+	require.Zero(t, scores[2].BidFrequency)
+}
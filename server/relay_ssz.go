@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiBellatrix "github.com/attestantio/go-builder-client/api/bellatrix"
+	builderApiCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiElectra "github.com/attestantio/go-builder-client/api/electra"
+	builderApiFulu "github.com/attestantio/go-builder-client/api/fulu"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+// sszBody is an SSZ encoded request body, sent as is by SendHTTPRequest
+type sszBody []byte
+
+// sszResponse is the raw response of a relay to a request preferring SSZ responses, which may still be JSON encoded
+type sszResponse struct {
+	contentType string
+	version     string
+	body        []byte
+}
+
+type sszEncoder interface {
+	MarshalSSZ() ([]byte, error)
+}
+
+// rejectsEncoding returns true if the response code means the relay doesn't support the encoding of the request
+func rejectsEncoding(code int) bool {
+	return code == http.StatusNotAcceptable || code == http.StatusUnsupportedMediaType
+}
+
+// relaySSZStore records the relays which rejected SSZ requests, they get JSON requests from then on
+type relaySSZStore struct {
+	mu       sync.Mutex
+	rejected map[string]bool
+}
+
+func newRelaySSZStore() *relaySSZStore {
+	return &relaySSZStore{rejected: make(map[string]bool)}
+}
+
+// useSSZ returns true if builder API requests to the relay are SSZ encoded
+func (s *relaySSZStore) useSSZ(relay types.RelayEntry) bool {
+	if !relay.SSZ {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.rejected[relay.String()]
+}
+
+// reject records that the relay rejected an SSZ request
+func (s *relaySSZStore) reject(relay types.RelayEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected[relay.String()] = true
+}
+
+// sendRelayRequest sends a builder API request to the relay, SSZ encoded if the relay supports it. The payload is nil,
+// a list of validator registrations or a signed blinded block, dst is nil, a bid or a payload. If the relay rejects
+// the SSZ request the request is sent again as JSON. Without retries (maxRetries 0 in the policy) the request is sent once.
+func (m *BoostService) sendRelayRequest(ctx context.Context, client http.Client, relay types.RelayEntry, method, url string, ua UserAgent, headers map[string]string, payload, dst any, policy retryPolicy, log *logrus.Entry) (int, error) {
+	send := func(payload, dst any) (int, error) {
+		if policy.maxRetries > 0 {
+			return sendHTTPRequestWithRetries(ctx, client, method, url, ua, headers, payload, dst, policy, log)
+		}
+		return SendHTTPRequest(ctx, client, method, url, ua, headers, payload, dst)
+	}
+	if !m.relaySSZ.useSSZ(relay) {
+		return send(payload, dst)
+	}
+	code, err := sendRequestSSZ(payload, dst, send)
+	if rejectsEncoding(code) {
+		log.WithError(err).Warn("relay rejected SSZ request, falling back to JSON")
+		m.relaySSZ.reject(relay)
+		return send(payload, dst)
+	}
+	return code, err
+}
+
+// SendRelayRequest sends a builder API request to the relay once, SSZ encoded if the relay is configured with SSZ, and
+// decodes the response into dst if set. Unlike the service it doesn't fall back to JSON if the relay rejects SSZ.
+func SendRelayRequest(ctx context.Context, client http.Client, relay types.RelayEntry, method, url string, payload, dst any) (int, error) {
+	send := func(payload, dst any) (int, error) {
+		return SendHTTPRequest(ctx, client, method, url, "", nil, payload, dst)
+	}
+	if !relay.SSZ {
+		return send(payload, dst)
+	}
+	return sendRequestSSZ(payload, dst, send)
+}
+
+// sendRequestSSZ sends the payload SSZ encoded, asking for an SSZ response if dst is set, and decodes the response into
+// dst
+func sendRequestSSZ(payload, dst any, send func(payload, dst any) (int, error)) (int, error) {
+	var sszPayload any
+	if payload != nil {
+		encoded, err := encodeRequestSSZ(payload)
+		if err != nil {
+			return 0, err
+		}
+		sszPayload = encoded
+	}
+	var response *sszResponse
+	var sszDst any
+	if dst != nil {
+		response = new(sszResponse)
+		sszDst = response
+	}
+	code, err := send(sszPayload, sszDst)
+	if err != nil || response == nil || code == http.StatusNoContent {
+		return code, err
+	}
+	return code, decodeResponseSSZ(response, dst)
+}
+
+// encodeRequestSSZ returns the SSZ encoding of a request payload
+func encodeRequestSSZ(payload any) (sszBody, error) {
+	switch payload := payload.(type) {
+	case []builderApiV1.SignedValidatorRegistration:
+		list := &builderApiV1.SignedValidatorRegistrations{Registrations: make([]*builderApiV1.SignedValidatorRegistration, len(payload))}
+		for i := range payload {
+			list.Registrations[i] = &payload[i]
+		}
+		return list.MarshalSSZ()
+	case sszEncoder:
+		return payload.MarshalSSZ()
+	}
+	return nil, fmt.Errorf("%w: %T", errSSZVersion, payload)
+}
+
+// decodeResponseSSZ decodes the relay response into dst, by the consensus version of the response if SSZ encoded
+func decodeResponseSSZ(response *sszResponse, dst any) error {
+	mediaType, _, err := mime.ParseMediaType(response.contentType)
+	if err != nil || mediaType != MediaTypeSSZ {
+		// The relay responded with JSON regardless
+		if err := json.Unmarshal(response.body, dst); err != nil {
+			return fmt.Errorf("%w %s: %w", errInvalidResponse, truncateBody(response.body), err)
+		}
+		return nil
+	}
+
+	version, err := spec.DataVersionFromString(response.version)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidResponse, err)
+	}
+	switch dst := dst.(type) {
+	case *builderSpec.VersionedSignedBuilderBid:
+		err = decodeBidSSZ(version, response.body, dst)
+	case *builderApi.VersionedSubmitBlindedBlockResponse:
+		err = decodePayloadSSZ(version, response.body, dst)
+	default:
+		err = fmt.Errorf("%w: %T", errSSZVersion, dst)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidResponse, err)
+	}
+	return nil
+}
+
+// decodeSSZ unmarshals the SSZ encoding into a new value
+func decodeSSZ[T any, PT interface {
+	*T
+	sszUnmarshaler
+}](body []byte) (*T, error) {
+	value := PT(new(T))
+	if err := value.UnmarshalSSZ(body); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeBidSSZ decodes an SSZ encoded signed builder bid of the consensus version
+func decodeBidSSZ(version spec.DataVersion, body []byte, bid *builderSpec.VersionedSignedBuilderBid) (err error) {
+	bid.Version = version
+	switch version {
+	case spec.DataVersionBellatrix:
+		bid.Bellatrix, err = decodeSSZ[builderApiBellatrix.SignedBuilderBid](body)
+	case spec.DataVersionCapella:
+		bid.Capella, err = decodeSSZ[builderApiCapella.SignedBuilderBid](body)
+	case spec.DataVersionDeneb:
+		bid.Deneb, err = decodeSSZ[builderApiDeneb.SignedBuilderBid](body)
+	case spec.DataVersionElectra:
+		bid.Electra, err = decodeSSZ[builderApiElectra.SignedBuilderBid](body)
+	case spec.DataVersionFulu:
+		bid.Fulu, err = decodeSSZ[builderApiElectra.SignedBuilderBid](body)
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
+		err = fmt.Errorf("%w: %s", errSSZVersion, version)
+	}
+	return err
+}
+
+// decodePayloadSSZ decodes an SSZ encoded payload of the consensus version
+func decodePayloadSSZ(version spec.DataVersion, body []byte, payload *builderApi.VersionedSubmitBlindedBlockResponse) (err error) {
+	payload.Version = version
+	switch version {
+	case spec.DataVersionBellatrix:
+		payload.Bellatrix, err = decodeSSZ[bellatrix.ExecutionPayload](body)
+	case spec.DataVersionCapella:
+		payload.Capella, err = decodeSSZ[capella.ExecutionPayload](body)
+	case spec.DataVersionDeneb:
+		payload.Deneb, err = decodeSSZ[builderApiDeneb.ExecutionPayloadAndBlobsBundle](body)
+	case spec.DataVersionElectra:
+		payload.Electra, err = decodeSSZ[builderApiDeneb.ExecutionPayloadAndBlobsBundle](body)
+	case spec.DataVersionFulu:
+		payload.Fulu, err = decodeSSZ[builderApiFulu.ExecutionPayloadAndBlobsBundle](body)
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
+		err = fmt.Errorf("%w: %s", errSSZVersion, version)
+	}
+	return err
+}
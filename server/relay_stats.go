@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RelayStats are the auction counters of a single relay since startup
+type RelayStats struct {
+	Relay             string `json:"relay"`
+	BidsReceived      uint64 `json:"bids_received"`
+	BidsWon           uint64 `json:"bids_won"`
+	BidsBelowMinBid   uint64 `json:"bids_below_min_bid"`
+	SignatureFailures uint64 `json:"signature_failures"`
+	PayloadsDelivered uint64 `json:"payloads_delivered"`
+	// PayloadsMissed are won auctions for which no relay returned the payload
+	PayloadsMissed uint64 `json:"payloads_missed"`
+	// PayloadsUnconfirmed are delivered payloads which the relay data API never reported as delivered
+	PayloadsUnconfirmed uint64 `json:"payloads_unconfirmed"`
+	// BlocksIncluded and BlocksMissed are the on-chain outcomes of delivered payloads, checked with the beacon node
+	BlocksIncluded uint64 `json:"blocks_included"`
+	BlocksMissed   uint64 `json:"blocks_missed"`
+}
+
+// relayStatsEvent is a single countable auction event
+type relayStatsEvent string
+
+const (
+	relayStatsBidReceived        relayStatsEvent = "bid_received"
+	relayStatsBidWon             relayStatsEvent = "bid_won"
+	relayStatsBidBelowMinBid     relayStatsEvent = "bid_below_min_bid"
+	relayStatsSignatureFailure   relayStatsEvent = "signature_failure"
+	relayStatsPayloadDelivered   relayStatsEvent = "payload_delivered"
+	relayStatsPayloadMissed      relayStatsEvent = "payload_missed"
+	relayStatsPayloadUnconfirmed relayStatsEvent = "payload_unconfirmed"
+	relayStatsBlockIncluded      relayStatsEvent = "block_included"
+	relayStatsBlockMissed        relayStatsEvent = "block_missed"
+)
+
+var relayAuctionEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "relay_auction_events_total",
+	Help:      "Number of auction events (bids received, won, below min-bid, signature failures, payloads delivered, missed and unconfirmed, blocks included and missed) per relay",
+}, []string{"relay", "event"})
+
+func init() {
+	metricsRegistry.MustRegister(relayAuctionEvents)
+}
+
+// relayStatsStore keeps the RelayStats of all relays
+type relayStatsStore struct {
+	mu    sync.Mutex
+	stats map[string]*RelayStats
+	sink  MetricsSink
+}
+
+func newRelayStatsStore(sink MetricsSink) *relayStatsStore {
+	return &relayStatsStore{stats: make(map[string]*RelayStats), sink: sink}
+}
+
+// record increases the counter for the event of a relay
+func (s *relayStatsStore) record(relay types.RelayEntry, event relayStatsEvent) {
+	relayAuctionEvents.WithLabelValues(relayLabel(relay), string(event)).Inc()
+	s.sink.Count("relay_auction_events", relayMetricTags(relay, map[string]string{"event": string(event)}))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.get(relay)
+	switch event {
+	case relayStatsBidReceived:
+		stats.BidsReceived++
+	case relayStatsBidWon:
+		stats.BidsWon++
+	case relayStatsBidBelowMinBid:
+		stats.BidsBelowMinBid++
+	case relayStatsSignatureFailure:
+		stats.SignatureFailures++
+	case relayStatsPayloadDelivered:
+		stats.PayloadsDelivered++
+	case relayStatsPayloadMissed:
+		stats.PayloadsMissed++
+	case relayStatsPayloadUnconfirmed:
+		stats.PayloadsUnconfirmed++
+	case relayStatsBlockIncluded:
+		stats.BlocksIncluded++
+	case relayStatsBlockMissed:
+		stats.BlocksMissed++
+	}
+}
+
+// snapshot returns a copy of the stats for the given relays, in the same order
+func (s *relayStatsStore) snapshot(relays []types.RelayEntry) []RelayStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]RelayStats, len(relays))
+	for i, relay := range relays {
+		ret[i] = *s.get(relay)
+	}
+	return ret
+}
+
+// get returns the stats of a relay, creating them if needed. The caller must hold the lock.
+func (s *relayStatsStore) get(relay types.RelayEntry) *RelayStats {
+	key := relay.String()
+	stats, ok := s.stats[key]
+	if !ok {
+		stats = &RelayStats{Relay: relay.GetURI("")}
+		s.stats[key] = stats
+	}
+	return stats
+}
+
+// handleRelayStats returns the auction counters of every relay since startup
+func (m *BoostService) handleRelayStats(w http.ResponseWriter, _ *http.Request) {
+	m.respondOK(w, m.relayStats.snapshot(m.currentConfig().relays))
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayStats(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	backend := newTestBackend(t, 2, time.Second)
+
+	// Relay 0 bids below the min-bid, relay 1 wins the auction
+	getHeaderPath := "/eth/v1/builder/header/12345/0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2/0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+		12344,
+		"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+		"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionDeneb,
+	)
+	backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+		12345,
+		"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+		"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		spec.DataVersionDeneb,
+	)
+	rr := backend.request(t, http.MethodGet, getHeaderPath, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Only relay 1 delivers the payload
+	backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	backend.relays[1].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+	rr = backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = backend.request(t, http.MethodGet, params.PathRelayStats, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	stats := []RelayStats{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	require.Equal(t, []RelayStats{
+		{
+			Relay:           backend.relays[0].RelayEntry.GetURI(""),
+			BidsReceived:    1,
+			BidsBelowMinBid: 1,
+		},
+		{
+			Relay:             backend.relays[1].RelayEntry.GetURI(""),
+			BidsReceived:      1,
+			BidsWon:           1,
+			PayloadsDelivered: 1,
+		},
+	}, stats)
+}
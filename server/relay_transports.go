@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errRelayClientCert = errors.New("the relay client certificate needs a certificate and a key file")
+
+var relayConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "relay_connections_total",
+	Help:      "Number of connections used for relay requests, by whether an idle connection was reused",
+}, []string{"relay", "reused"})
+
+func init() {
+	metricsRegistry.MustRegister(relayConnections)
+}
+
+// defaultRelayKeepAlive is the TCP keepalive period of the relay connections, if not configured
+const defaultRelayKeepAlive = 30 * time.Second
+
+// relayTransportSettings tune the connections to the relays. Zero values keep the defaults of the Go HTTP client.
+type relayTransportSettings struct {
+	disableHTTP2        bool
+	maxIdleConnsPerHost int           // idle connections kept per relay
+	idleConnTimeout     time.Duration // idle connections are closed after this
+	keepAlive           time.Duration // TCP keepalive period, negative to disable
+}
+
+// apply sets the settings on the transport
+func (s relayTransportSettings) apply(transport *http.Transport) {
+	if s.disableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	if s.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = s.maxIdleConnsPerHost
+	}
+	if s.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = s.idleConnTimeout
+	}
+}
+
+// dialer returns the dialer of the relay connections
+func (s relayTransportSettings) dialer() *net.Dialer {
+	keepAlive := s.keepAlive
+	if keepAlive == 0 {
+		keepAlive = defaultRelayKeepAlive
+	}
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive}
+}
+
+// clientCert are the files of a TLS client certificate and its key
+type clientCert struct {
+	certFile string
+	keyFile  string
+}
+
+// relayTransportKey identifies the transport settings of a relay
+type relayTransportKey struct {
+	cert  clientCert
+	proxy string
+}
+
+// relayTransports are the transports of relays with their own connection settings: a TLS client certificate for relays
+// gating access by mutual TLS, or a proxy. There's one transport per setting, relays without settings share a transport.
+// All transports have the relayTransportSettings. Certificates of relays added later, i.e. by a reload, are loaded on
+// their first request.
+type relayTransports struct {
+	base        *http.Transport
+	dialer      *net.Dialer
+	defaultCert clientCert
+	hosts       *relayHosts // resolves the relay host names of the connections, if set
+
+	mu         sync.Mutex
+	transports map[relayTransportKey]http.RoundTripper
+}
+
+// newRelayTransports returns the relay transports with the global client certificate, if any, and the settings, and
+// checks the client certificates of the relays
+func newRelayTransports(certFile, keyFile string, settings relayTransportSettings, relays []types.RelayEntry) (*relayTransports, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errRelayClientCert
+	}
+	//nolint:forcetypeassert
+	t := &relayTransports{
+		base:        http.DefaultTransport.(*http.Transport).Clone(),
+		dialer:      settings.dialer(),
+		defaultCert: clientCert{certFile: certFile, keyFile: keyFile},
+		transports:  make(map[relayTransportKey]http.RoundTripper),
+	}
+	settings.apply(t.base)
+	t.base.DialContext = t.dialContext
+	for _, relay := range relays {
+		if key := t.keyOf(relay); key != (relayTransportKey{}) {
+			if _, err := t.transport(key); err != nil {
+				return nil, fmt.Errorf("relay %s: %w", relay.String(), err)
+			}
+		}
+	}
+	if t.defaultCert.certFile != "" {
+		if _, err := t.transport(relayTransportKey{cert: t.defaultCert}); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// keyOf returns the transport settings of the relay. Relays without client certificate get the global one.
+func (t *relayTransports) keyOf(relay types.RelayEntry) relayTransportKey {
+	key := relayTransportKey{cert: t.defaultCert}
+	if relay.ClientCert != "" {
+		key.cert = clientCert{certFile: relay.ClientCert, keyFile: relay.ClientKey}
+	}
+	if relay.Proxy != nil {
+		key.proxy = relay.Proxy.String()
+	}
+	return key
+}
+
+// transport returns the transport with the settings, loading the client certificate on first use
+func (t *relayTransports) transport(key relayTransportKey) (http.RoundTripper, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if transport, ok := t.transports[key]; ok {
+		return transport, nil
+	}
+
+	transport := t.base.Clone()
+	if key.cert.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(key.cert.certFile, key.cert.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the relay client certificate: %w", err)
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+	}
+	if key.proxy != "" {
+		proxy, err := url.Parse(key.proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	t.transports[key] = transport
+	return transport, nil
+}
+
+// client returns a copy of the relay client with the transport of the relay, sending the credentials and headers of the
+// relay and counting the reused connections. Requests fail if the client certificate can't be loaded.
+func (t *relayTransports) client(client http.Client, relay types.RelayEntry) http.Client {
+	if t != nil {
+		transport, err := t.transport(t.keyOf(relay))
+		if err != nil {
+			transport = failingTransport{err: err}
+		}
+		transport = &connMetricsTransport{base: transport, relay: relayLabel(relay)}
+		if compression, ok := client.Transport.(*compressionTransport); ok {
+			client.Transport = &compressionTransport{base: transport, minSize: compression.minSize}
+		} else {
+			client.Transport = transport
+		}
+	}
+	if relay.BasicAuth != nil || len(relay.Headers) > 0 {
+		client.Transport = &credentialsTransport{base: client.Transport, basicAuth: relay.BasicAuth, headers: relay.Headers}
+	}
+	return client
+}
+
+// credentialsTransport adds the basic auth credentials and headers of a relay to its requests
+type credentialsTransport struct {
+	base      http.RoundTripper
+	basicAuth *url.Userinfo
+	headers   http.Header
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	if t.basicAuth != nil {
+		password, _ := t.basicAuth.Password()
+		req.SetBasicAuth(t.basicAuth.Username(), password)
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// dialContext connects to the relays, resolving their host names with relayHosts if set
+func (t *relayTransports) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if t.hosts != nil {
+		return t.hosts.dialContext(ctx, t.dialer, network, address)
+	}
+	return t.dialer.DialContext(ctx, network, address)
+}
+
+// connMetricsTransport counts the connections of the relay requests by whether they were reused
+type connMetricsTransport struct {
+	base  http.RoundTripper
+	relay string
+}
+
+func (t *connMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			relayConnections.WithLabelValues(t.relay, strconv.FormatBool(info.Reused)).Inc()
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// closeIdleConnections closes the idle connections to the relays, so the next requests connect anew
+func (t *relayTransports) closeIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transport := range t.transports {
+		if transport, ok := transport.(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+	}
+}
+
+// failingTransport fails all requests with the error
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
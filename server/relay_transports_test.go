@@ -0,0 +1,369 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCert writes a self-signed certificate for the name and its key to the directory, for clients or servers
+func writeClientCert(t *testing.T, dir, name string) clientCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert := clientCert{certFile: filepath.Join(dir, name+".crt"), keyFile: filepath.Join(dir, name+".key")}
+	require.NoError(t, os.WriteFile(cert.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(cert.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert
+}
+
+// startSOCKS5Proxy starts a SOCKS5 proxy connecting all requests to the target address, and returns its address and
+// the requested host names
+func startSOCKS5Proxy(t *testing.T, target string) (string, chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	hosts := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// Greeting without authentication, then a connect request with a domain name
+				buf := make([]byte, 262)
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				if _, err := conn.Write([]byte{5, 0}); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:5]); err != nil || buf[3] != 3 {
+					return
+				}
+				host := make([]byte, buf[4]+2)
+				if _, err := io.ReadFull(conn, host); err != nil {
+					return
+				}
+				hosts <- string(host[:len(host)-2])
+
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+					return
+				}
+				go func() { _, _ = io.Copy(upstream, conn) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), hosts
+}
+
+func TestRelayProxies(t *testing.T) {
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Host", r.Host)
+	}))
+	t.Cleanup(relay.Close)
+	relayURL, err := url.Parse(relay.URL)
+	require.NoError(t, err)
+
+	// The HTTP proxy forwards requests to the relay
+	proxied := make(chan string, 10)
+	httpProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.Host
+		resp, err := http.Get(relay.URL) //nolint:noctx
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		resp.Body.Close()
+		w.Header().Set("X-Proxy", "http")
+	}))
+	t.Cleanup(httpProxy.Close)
+
+	transports, err := newRelayTransports("", "", relayTransportSettings{}, nil)
+	require.NoError(t, err)
+	request := func(relay types.RelayEntry) *http.Response {
+		t.Helper()
+		client := transports.client(http.Client{}, relay)
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, relay.URL.String(), nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("Relays without proxy are connected directly", func(t *testing.T) {
+		resp := request(types.RelayEntry{URL: relayURL})
+		require.Empty(t, resp.Header.Get("X-Proxy"))
+		require.Empty(t, proxied)
+	})
+
+	t.Run("Relays with an HTTP proxy", func(t *testing.T) {
+		proxy, err := url.Parse(httpProxy.URL)
+		require.NoError(t, err)
+		onion, err := url.Parse("http://relay.onion")
+		require.NoError(t, err)
+		resp := request(types.RelayEntry{URL: onion, Proxy: proxy})
+		require.Equal(t, "http", resp.Header.Get("X-Proxy"))
+		require.Equal(t, "relay.onion", <-proxied)
+	})
+
+	t.Run("Relays with a SOCKS5 proxy resolve the host through the proxy", func(t *testing.T) {
+		addr, hosts := startSOCKS5Proxy(t, relayURL.Host)
+		proxy, err := url.Parse("socks5h://" + addr)
+		require.NoError(t, err)
+		onion, err := url.Parse("http://relay.onion")
+		require.NoError(t, err)
+		resp := request(types.RelayEntry{URL: onion, Proxy: proxy})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "relay.onion", resp.Header.Get("X-Host"))
+		require.Equal(t, "relay.onion", <-hosts)
+	})
+}
+
+func TestRelayCredentials(t *testing.T) {
+	requests := make(chan *http.Request, 10)
+	relay := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		requests <- r
+	}))
+	t.Cleanup(relay.Close)
+	relayURL, err := url.Parse(relay.URL)
+	require.NoError(t, err)
+	entry := types.RelayEntry{
+		URL:       relayURL,
+		BasicAuth: url.UserPassword("operator", "s3cr3t"),
+		Headers:   http.Header{"X-Api-Key": []string{"abc"}},
+	}
+
+	request := func(transports *relayTransports, client http.Client) *http.Request {
+		t.Helper()
+		client = transports.client(client, entry)
+		payload := bytes.Repeat([]byte("a"), 100)
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, relay.URL, bytes.NewReader(payload))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return <-requests
+	}
+
+	t.Run("Requests carry the credentials and headers of the relay", func(t *testing.T) {
+		req := request(nil, http.Client{})
+		user, password, ok := req.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "operator", user)
+		require.Equal(t, "s3cr3t", password)
+		require.Equal(t, "abc", req.Header.Get("X-Api-Key"))
+	})
+
+	t.Run("Compressed requests through the relay transport carry them as well", func(t *testing.T) {
+		transports, err := newRelayTransports("", "", relayTransportSettings{}, nil)
+		require.NoError(t, err)
+		proxy, err := url.Parse("http://" + relayURL.Host)
+		require.NoError(t, err)
+		entry.Proxy = proxy
+		t.Cleanup(func() { entry.Proxy = nil })
+		req := request(transports, http.Client{Transport: newCompressionTransport(10)})
+		require.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+		require.Equal(t, "abc", req.Header.Get("X-Api-Key"))
+		_, _, ok := req.BasicAuth()
+		require.True(t, ok)
+	})
+}
+
+func TestRelayClientCerts(t *testing.T) {
+	dir := t.TempDir()
+	globalCert := writeClientCert(t, dir, "global")
+	relayCert := writeClientCert(t, dir, "relay")
+
+	// The relay requires a client certificate and answers with its common name
+	relay := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Client-Cert", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	relay.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	relay.StartTLS()
+	t.Cleanup(relay.Close)
+	relayURL, err := url.Parse(relay.URL)
+	require.NoError(t, err)
+
+	newTransports := func(defaultCert clientCert) *relayTransports {
+		return &relayTransports{
+			base:        relay.Client().Transport.(*http.Transport), //nolint:forcetypeassert
+			defaultCert: defaultCert,
+			transports:  make(map[relayTransportKey]http.RoundTripper),
+		}
+	}
+	// request returns the common name of the client certificate seen by the relay
+	request := func(client http.Client) (string, error) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, relay.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		return resp.Header.Get("X-Client-Cert"), nil
+	}
+
+	t.Run("Relays present their own or the global client certificate", func(t *testing.T) {
+		transports := newTransports(globalCert)
+		name, err := request(transports.client(http.Client{}, types.RelayEntry{URL: relayURL}))
+		require.NoError(t, err)
+		require.Equal(t, "global", name)
+
+		withCert := types.RelayEntry{URL: relayURL, ClientCert: relayCert.certFile, ClientKey: relayCert.keyFile}
+		name, err = request(transports.client(http.Client{}, withCert))
+		require.NoError(t, err)
+		require.Equal(t, "relay", name)
+	})
+
+	t.Run("Relays without client certificate are refused", func(t *testing.T) {
+		transports := newTransports(clientCert{})
+		client := transports.client(http.Client{Transport: relay.Client().Transport}, types.RelayEntry{URL: relayURL})
+		_, err := request(client)
+		require.Error(t, err)
+	})
+
+	t.Run("Compressed requests present the client certificate", func(t *testing.T) {
+		transports := newTransports(globalCert)
+		client := transports.client(http.Client{Transport: newCompressionTransport(1)}, types.RelayEntry{URL: relayURL})
+		require.IsType(t, &compressionTransport{}, client.Transport)
+		name, err := request(client)
+		require.NoError(t, err)
+		require.Equal(t, "global", name)
+	})
+
+	t.Run("Requests fail if the client certificate can't be loaded", func(t *testing.T) {
+		transports := newTransports(clientCert{})
+		missing := types.RelayEntry{URL: relayURL, ClientCert: filepath.Join(dir, "missing.crt"), ClientKey: filepath.Join(dir, "missing.key")}
+		_, err := request(transports.client(http.Client{}, missing))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("The client certificates are checked on startup", func(t *testing.T) {
+		opts := BoostServiceOpts{
+			Log:                   mock.TestLog,
+			Relays:                []types.RelayEntry{mock.NewRelay(t).RelayEntry},
+			GenesisForkVersionHex: "0x00000000",
+			RelayClientCert:       globalCert.certFile,
+		}
+		_, err := NewBoostService(opts)
+		require.ErrorIs(t, err, errRelayClientCert)
+
+		opts.RelayClientKey = globalCert.keyFile
+		_, err = NewBoostService(opts)
+		require.NoError(t, err)
+
+		opts.Relays[0].ClientCert, opts.Relays[0].ClientKey = filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key")
+		_, err = NewBoostService(opts)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestRelayTransportSettings(t *testing.T) {
+	relay := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	relay.EnableHTTP2 = true
+	relay.StartTLS()
+	t.Cleanup(relay.Close)
+	relayURL, err := url.Parse(relay.URL)
+	require.NoError(t, err)
+	entry := types.RelayEntry{URL: relayURL}
+
+	// request returns the response of a request with transports of the settings, trusting the relay certificate
+	request := func(settings relayTransportSettings) *http.Response {
+		t.Helper()
+		transports, err := newRelayTransports("", "", settings, nil)
+		require.NoError(t, err)
+		transports.base.TLSClientConfig = &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}
+		transports.base.TLSClientConfig.RootCAs.AddCert(relay.Certificate())
+		client := transports.client(http.Client{}, entry)
+		resp, err := client.Get(relay.URL) //nolint:noctx
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+	connections := func(reused string) float64 {
+		metric := new(dto.Metric)
+		require.NoError(t, relayConnections.WithLabelValues(relayLabel(entry), reused).Write(metric))
+		return metric.GetCounter().GetValue()
+	}
+
+	t.Run("HTTP/2 can be disabled", func(t *testing.T) {
+		require.Equal(t, 2, request(relayTransportSettings{}).ProtoMajor)
+		require.Equal(t, 1, request(relayTransportSettings{disableHTTP2: true}).ProtoMajor)
+	})
+
+	t.Run("The idle connections are tuned", func(t *testing.T) {
+		transport := &http.Transport{}
+		relayTransportSettings{maxIdleConnsPerHost: 8, idleConnTimeout: time.Minute}.apply(transport)
+		require.Equal(t, 8, transport.MaxIdleConnsPerHost)
+		require.Equal(t, time.Minute, transport.IdleConnTimeout)
+
+		require.Equal(t, defaultRelayKeepAlive, relayTransportSettings{}.dialer().KeepAlive)
+		require.Equal(t, -time.Second, relayTransportSettings{keepAlive: -time.Second}.dialer().KeepAlive)
+	})
+
+	t.Run("Reused connections are counted", func(t *testing.T) {
+		transports, err := newRelayTransports("", "", relayTransportSettings{disableHTTP2: true}, nil)
+		require.NoError(t, err)
+		transports.base.TLSClientConfig = &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}
+		transports.base.TLSClientConfig.RootCAs.AddCert(relay.Certificate())
+		client := transports.client(http.Client{}, entry)
+
+		newConns, reusedConns := connections("false"), connections("true")
+		for range 3 {
+			resp, err := client.Get(relay.URL) //nolint:noctx
+			require.NoError(t, err)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		require.InDelta(t, newConns+1, connections("false"), 0)
+		require.InDelta(t, reusedConns+2, connections("true"), 0)
+	})
+}
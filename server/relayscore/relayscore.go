@@ -0,0 +1,93 @@
+package relayscore
+
+import (
+	"cmp"
+	"slices"
+)
+
+// latencyReferenceMs is the latency at which the latency component of the score is 0.5
+const latencyReferenceMs = 500
+
+// Inputs are the statistics of a relay which make up its score
+type Inputs struct {
+	LatencyMs float64 // moving average latency of the successful requests
+	ErrorRate float64 // share of the recent requests which failed
+
+	// Auctions is the number of auctions the relay was queried in, Bids the number of auctions it bid in
+	Auctions uint64
+	Bids     uint64
+	// ValueShare is the sum over all auctions of the best valid bid of the relay divided by the best bid of the auction
+	ValueShare float64
+
+	// Deliveries is the number of won auctions, FailedDeliveries the ones without a payload or without a block
+	Deliveries       uint64
+	FailedDeliveries uint64
+}
+
+// Weights are the weights of the score components. They don't need to sum up to 1.
+type Weights struct {
+	Latency      float64 `json:"latency"`
+	ErrorRate    float64 `json:"error_rate"`
+	BidFrequency float64 `json:"bid_frequency"`
+	ValueShare   float64 `json:"value_share"`
+	Delivery     float64 `json:"delivery"`
+}
+
+// DefaultWeights value the bids of a relay most, followed by the reliability of its responses and deliveries
+var DefaultWeights = Weights{
+	Latency:      0.1,
+	ErrorRate:    0.2,
+	BidFrequency: 0.2,
+	ValueShare:   0.3,
+	Delivery:     0.2,
+}
+
+// Score is the score of a relay along with its components. The components are between 0 (worst) and 1 (best), the
+// score is their weighted average between 0 and 100.
+type Score struct {
+	Relay        string  `json:"relay"`
+	Score        float64 `json:"score"`
+	Latency      float64 `json:"latency"`
+	ErrorRate    float64 `json:"error_rate"`
+	BidFrequency float64 `json:"bid_frequency"`
+	ValueShare   float64 `json:"value_share"`
+	Delivery     float64 `json:"delivery"`
+}
+
+// Compute returns the score of a relay. Relays without auctions score 0 for the bid components, relays without
+// deliveries score 1 for the delivery component.
+func Compute(relay string, in Inputs, w Weights) Score {
+	s := Score{
+		Relay:     relay,
+		Latency:   latencyReferenceMs / (latencyReferenceMs + max(in.LatencyMs, 0)),
+		ErrorRate: clamp(1 - in.ErrorRate),
+		Delivery:  1,
+	}
+	if in.Auctions > 0 {
+		s.BidFrequency = clamp(float64(in.Bids) / float64(in.Auctions))
+		s.ValueShare = clamp(in.ValueShare / float64(in.Auctions))
+	}
+	if in.Deliveries > 0 {
+		s.Delivery = clamp(1 - float64(in.FailedDeliveries)/float64(in.Deliveries))
+	}
+
+	total := w.Latency + w.ErrorRate + w.BidFrequency + w.ValueShare + w.Delivery
+	if total <= 0 {
+		return s
+	}
+	weighted := w.Latency*s.Latency + w.ErrorRate*s.ErrorRate + w.BidFrequency*s.BidFrequency +
+		w.ValueShare*s.ValueShare + w.Delivery*s.Delivery
+	s.Score = 100 * weighted / total
+	return s
+}
+
+// Rank sorts the scores from the best to the worst relay. Relays with the same score keep their order.
+func Rank(scores []Score) {
+	slices.SortStableFunc(scores, func(a, b Score) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+}
+
+func clamp(v float64) float64 {
+	return min(max(v, 0), 1)
+}
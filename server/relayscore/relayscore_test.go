@@ -0,0 +1,55 @@
+package relayscore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	t.Run("Relay without data", func(t *testing.T) {
+		s := Compute("relay", Inputs{}, DefaultWeights)
+		require.Equal(t, Score{Relay: "relay", Score: 50, Latency: 1, ErrorRate: 1, Delivery: 1}, s)
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		s := Compute("relay", Inputs{
+			LatencyMs:        500,
+			ErrorRate:        0.1,
+			Auctions:         10,
+			Bids:             8,
+			ValueShare:       6,
+			Deliveries:       4,
+			FailedDeliveries: 1,
+		}, DefaultWeights)
+		require.InDelta(t, 0.5, s.Latency, 1e-9)
+		require.InDelta(t, 0.9, s.ErrorRate, 1e-9)
+		require.InDelta(t, 0.8, s.BidFrequency, 1e-9)
+		require.InDelta(t, 0.6, s.ValueShare, 1e-9)
+		require.InDelta(t, 0.75, s.Delivery, 1e-9)
+		require.InDelta(t, 100*(0.05+0.18+0.16+0.18+0.15), s.Score, 1e-9)
+	})
+
+	t.Run("Weights", func(t *testing.T) {
+		in := Inputs{Auctions: 4, Bids: 1, ValueShare: 1}
+		s := Compute("relay", in, Weights{BidFrequency: 2})
+		require.InDelta(t, 25, s.Score, 1e-9)
+		s = Compute("relay", in, Weights{})
+		require.Zero(t, s.Score)
+	})
+}
+
+func TestRank(t *testing.T) {
+	scores := []Score{
+		{Relay: "a", Score: 10},
+		{Relay: "b", Score: 30},
+		{Relay: "c", Score: 10},
+		{Relay: "d", Score: 20},
+	}
+	Rank(scores)
+	relays := make([]string, len(scores))
+	for i, s := range scores {
+		relays[i] = s.Relay
+	}
+	require.Equal(t, []string{"b", "d", "a", "c"}, relays)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/sirupsen/logrus"
+)
+
+var errReloadUnsupported = errors.New("reloading is not supported without config file")
+
+// relayDataAPITimeout is the timeout of the relay data API requests, which aren't time critical and may take longer
+// than the builder API requests
+const relayDataAPITimeout = 5 * time.Second
+
+// ReloadOpts are the settings which can be changed while mev-boost is running
+type ReloadOpts struct {
+	Relays                   []types.RelayEntry
+	RelayMinBid              types.U256Str
+	RequestTimeoutGetHeader  time.Duration
+	RequestTimeoutGetPayload time.Duration
+	RequestTimeoutRegVal     time.Duration
+	ValidatorRoutes          ValidatorRoutes
+	RelayFilters             RelayFilters
+	BidPlugin                *BidPlugin
+}
+
+// reloadableConfig is a consistent snapshot of the settings which can be reloaded
+type reloadableConfig struct {
+	relays               []types.RelayEntry
+	relayMinBid          types.U256Str
+	httpClientGetHeader  http.Client
+	httpClientGetPayload http.Client
+	httpClientRegVal     http.Client
+	validatorRoutes      ValidatorRoutes
+	relayFilters         RelayFilters
+	bidPlugin            *BidPlugin
+	relayTransports      *relayTransports
+}
+
+// currentConfig returns the current reloadable settings. Requests should use a single snapshot, so a reload doesn't
+// change the settings halfway through.
+func (m *BoostService) currentConfig() reloadableConfig {
+	m.configLock.RLock()
+	defer m.configLock.RUnlock()
+	return reloadableConfig{
+		relays:               m.adminRelays.active(mergeRelays(m.relays, m.remoteRelays)),
+		relayMinBid:          m.relayMinBid,
+		httpClientGetHeader:  m.httpClientGetHeader,
+		httpClientGetPayload: m.httpClientGetPayload,
+		httpClientRegVal:     m.httpClientRegVal,
+		validatorRoutes:      m.validatorRoutes,
+		relayFilters:         m.relayFilters,
+		bidPlugin:            m.bidPlugin,
+		relayTransports:      m.relayTransports,
+	}
+}
+
+// getHeaderClient returns the getHeader client with the timeout and client certificate of the relay
+func (c reloadableConfig) getHeaderClient(relay types.RelayEntry) http.Client {
+	return c.relayTransports.client(clientWithTimeout(c.httpClientGetHeader, relay.TimeoutGetHeader), relay)
+}
+
+// getPayloadClient returns the getPayload client with the timeout and client certificate of the relay
+func (c reloadableConfig) getPayloadClient(relay types.RelayEntry) http.Client {
+	return c.relayTransports.client(clientWithTimeout(c.httpClientGetPayload, relay.TimeoutGetPayload), relay)
+}
+
+// regValClient returns the registerValidator client with the timeout and client certificate of the relay
+func (c reloadableConfig) regValClient(relay types.RelayEntry) http.Client {
+	return c.relayTransports.client(clientWithTimeout(c.httpClientRegVal, relay.TimeoutRegVal), relay)
+}
+
+// dataAPIClient returns the relay data API client with the client certificate of the relay
+func (c reloadableConfig) dataAPIClient(relay types.RelayEntry) http.Client {
+	return c.relayTransports.client(http.Client{Timeout: relayDataAPITimeout, CheckRedirect: httpClientDisallowRedirects}, relay)
+}
+
+// clientWithTimeout returns a copy of the client with the timeout, if set
+func clientWithTimeout(client http.Client, timeout time.Duration) http.Client {
+	if timeout > 0 {
+		client.Timeout = timeout
+	}
+	return client
+}
+
+// Reload applies new relay settings. Requests in flight finish with the previous settings.
+func (m *BoostService) Reload(opts ReloadOpts) error {
+	if len(opts.Relays) == 0 && len(m.relaySources) == 0 {
+		return errNoRelays
+	}
+
+	// Runs after the unlock, replaying the registrations to the added relays
+	defer m.replayRegistrationsToNewRelays(m.currentConfig())
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+	m.relays = opts.Relays
+	m.relayMinBid = opts.RelayMinBid
+	m.httpClientGetHeader.Timeout = opts.RequestTimeoutGetHeader
+	m.httpClientGetPayload.Timeout = opts.RequestTimeoutGetPayload
+	m.httpClientRegVal.Timeout = opts.RequestTimeoutRegVal
+	m.validatorRoutes = opts.ValidatorRoutes
+	m.relayFilters = opts.RelayFilters
+
+	// Auctions in flight keep using the replaced bid plugin for a while
+	if previous := m.bidPlugin; previous != nil && previous != opts.BidPlugin {
+		time.AfterFunc(bidPluginCloseDelay, func() { _ = previous.Close() })
+	}
+	m.bidPlugin = opts.BidPlugin
+
+	m.log.WithFields(logrus.Fields{
+		"relays":                   types.RelayEntriesToStrings(opts.Relays),
+		"minBid":                   opts.RelayMinBid.String(),
+		"requestTimeoutGetHeader":  opts.RequestTimeoutGetHeader,
+		"requestTimeoutGetPayload": opts.RequestTimeoutGetPayload,
+		"requestTimeoutRegVal":     opts.RequestTimeoutRegVal,
+		"validatorRoutes":          len(opts.ValidatorRoutes),
+		"relayFilters":             len(opts.RelayFilters),
+		"bidPlugin":                opts.BidPlugin != nil,
+	}).Info("reloaded relay settings")
+	return nil
+}
+
+// authorizedAdminRequest checks the bearer token, or basic auth password, of admin API requests
+func (m *BoostService) authorizedAdminRequest(req *http.Request) bool {
+	return authorizedRequest(req, m.adminToken)
+}
+
+// handleAdminReload reloads the config file
+func (m *BoostService) handleAdminReload(w http.ResponseWriter, req *http.Request) {
+	if !m.authorizedAdminRequest(req) {
+		m.respondError(w, http.StatusUnauthorized, errUnauthorized.Error())
+		return
+	}
+	if m.reloadConfig == nil {
+		m.respondError(w, http.StatusNotImplemented, errReloadUnsupported.Error())
+		return
+	}
+
+	if err := m.reloadConfig(); err != nil {
+		m.log.WithError(err).Error("failed reloading config")
+		m.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	m.respondOK(w, nilResponse)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+
+	t.Run("Applies relays, min-bid and timeouts", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		relay := mock.NewRelay(t)
+
+		err := backend.boost.Reload(ReloadOpts{
+			Relays:                   []types.RelayEntry{relay.RelayEntry},
+			RelayMinBid:              types.IntToU256(12346),
+			RequestTimeoutGetHeader:  100 * time.Millisecond,
+			RequestTimeoutGetPayload: 200 * time.Millisecond,
+			RequestTimeoutRegVal:     300 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		cfg := backend.boost.currentConfig()
+		require.Equal(t, 100*time.Millisecond, cfg.httpClientGetHeader.Timeout)
+		require.Equal(t, 200*time.Millisecond, cfg.httpClientGetPayload.Timeout)
+		require.Equal(t, 300*time.Millisecond, cfg.httpClientRegVal.Timeout)
+
+		// The bid of 12345 is below the new min-bid
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+		require.Equal(t, 1, relay.GetRequestCount(path))
+	})
+
+	t.Run("Errors without relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.ErrorIs(t, backend.boost.Reload(ReloadOpts{}), errNoRelays)
+		require.Len(t, backend.boost.currentConfig().relays, 1)
+	})
+}
+
+func TestAdminReload(t *testing.T) {
+	adminRequest := func(t *testing.T, backend *testBackend, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, params.PathAdminReload, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Disabled without token", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := adminRequest(t, backend, "")
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.adminToken = "secret"
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rr := adminRequest(t, backend, "wrong")
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Without config file", func(t *testing.T) {
+		rr := adminRequest(t, backend, "secret")
+		require.Equal(t, http.StatusNotImplemented, rr.Code)
+	})
+
+	t.Run("Reloads config", func(t *testing.T) {
+		reloads := 0
+		backend.boost.reloadConfig = func() error {
+			reloads++
+			return nil
+		}
+		rr := adminRequest(t, backend, "secret")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, reloads)
+
+		backend.boost.reloadConfig = func() error { return errors.New("invalid config") } //nolint:err113
+		rr = adminRequest(t, backend, "secret")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid config")
+	})
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reputationSaveInterval is how often the relay reputation is saved to the reputation file
+const reputationSaveInterval = time.Minute
+
+// relayReputation is what mev-boost learned about a relay, persisted across restarts
+type relayReputation struct {
+	Stats RelayStats `json:"stats"`
+
+	Auctions   uint64  `json:"auctions"`
+	Bids       uint64  `json:"bids"`
+	ValueShare float64 `json:"value_share"`
+
+	LastSuccess    time.Time         `json:"last_success"`
+	LastError      string            `json:"last_error,omitempty"`
+	RecentFailures []bool            `json:"recent_failures,omitempty"` // oldest first
+	LatencyMs      float64           `json:"latency_ms"`
+	Errors         map[string]uint64 `json:"errors,omitempty"`
+
+	Circuit         string    `json:"circuit,omitempty"`
+	CircuitFailures int       `json:"circuit_failures,omitempty"`
+	CircuitOpenedAt time.Time `json:"circuit_opened_at"`
+
+	GetHeaderLatencies []time.Duration `json:"get_header_latencies,omitempty"` // oldest first
+}
+
+// reputationFile is the content of the reputation file, with the relays by URL
+type reputationFile struct {
+	SavedAt time.Time                   `json:"saved_at"`
+	Relays  map[string]*relayReputation `json:"relays"`
+}
+
+// reputation returns the reputation of all relays mev-boost has seen since startup
+func (m *BoostService) reputation() map[string]*relayReputation {
+	relays := make(map[string]*relayReputation)
+	get := func(key string) *relayReputation {
+		r, ok := relays[key]
+		if !ok {
+			r = new(relayReputation)
+			relays[key] = r
+		}
+		return r
+	}
+
+	m.relayStats.mu.Lock()
+	for key, stats := range m.relayStats.stats {
+		get(key).Stats = *stats
+	}
+	m.relayStats.mu.Unlock()
+
+	m.relayAuctions.mu.Lock()
+	for key, a := range m.relayAuctions.auctions {
+		r := get(key)
+		r.Auctions, r.Bids, r.ValueShare = a.auctions, a.bids, a.valueShare
+	}
+	m.relayAuctions.mu.Unlock()
+
+	m.relayHealth.mu.Lock()
+	for key, h := range m.relayHealth.health {
+		r := get(key)
+		r.LastSuccess, r.LastError, r.LatencyMs = h.lastSuccess, h.lastError, h.latencyMs
+		r.RecentFailures = ringItems(h.failed[:], h.count, h.next)
+		r.Errors = make(map[string]uint64, len(h.errors))
+		for class, num := range h.errors {
+			r.Errors[string(class)] = num
+		}
+	}
+	m.relayHealth.mu.Unlock()
+
+	if m.breaker != nil {
+		for key, c := range m.breaker.export() {
+			r := get(key)
+			r.Circuit, r.CircuitFailures, r.CircuitOpenedAt = c.state, c.failures, c.openedAt
+		}
+	}
+
+	if m.adaptiveTimeouts != nil {
+		for key, latencies := range m.adaptiveTimeouts.export() {
+			get(key).GetHeaderLatencies = latencies
+		}
+	}
+	return relays
+}
+
+// restoreReputation restores the reputation of the relays, i.e. after a restart
+func (m *BoostService) restoreReputation(relays map[string]*relayReputation) {
+	for key, r := range relays {
+		stats := r.Stats
+		m.relayStats.mu.Lock()
+		m.relayStats.stats[key] = &stats
+		m.relayStats.mu.Unlock()
+
+		m.relayAuctions.mu.Lock()
+		m.relayAuctions.auctions[key] = &relayAuctions{auctions: r.Auctions, bids: r.Bids, valueShare: r.ValueShare}
+		m.relayAuctions.mu.Unlock()
+
+		m.relayHealth.mu.Lock()
+		h := &relayHealth{
+			lastSuccess: r.LastSuccess,
+			lastError:   r.LastError,
+			latencyMs:   r.LatencyMs,
+			errors:      make(map[relayErrorClass]uint64, len(r.Errors)),
+		}
+		h.count, h.next = fillRing(h.failed[:], r.RecentFailures)
+		for class, num := range r.Errors {
+			h.errors[relayErrorClass(class)] = num
+		}
+		m.relayHealth.health[key] = h
+		m.relayHealth.mu.Unlock()
+
+		if m.breaker != nil && r.Circuit != "" {
+			m.breaker.restore(key, relayCircuit{state: r.Circuit, failures: r.CircuitFailures, openedAt: r.CircuitOpenedAt})
+		}
+
+		if m.adaptiveTimeouts != nil && len(r.GetHeaderLatencies) > 0 {
+			m.adaptiveTimeouts.restore(key, r.GetHeaderLatencies)
+		}
+	}
+}
+
+// loadReputation restores the relay reputation from the reputation file, if it exists
+func (m *BoostService) loadReputation() error {
+	data, err := os.ReadFile(m.reputationFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file reputationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	m.restoreReputation(file.Relays)
+	m.log.WithField("savedAt", file.SavedAt).Infof("restored the reputation of %d relays from %s", len(file.Relays), m.reputationFile)
+	return nil
+}
+
+// saveReputation writes the relay reputation to the reputation file. The file is replaced atomically, a crash while
+// saving doesn't corrupt it.
+func (m *BoostService) saveReputation() error {
+	data, err := json.Marshal(reputationFile{SavedAt: time.Now().UTC(), Relays: m.reputation()})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(m.reputationFile, data)
+}
+
+// writeFileAtomic replaces the file with the data through a temporary file in the same directory
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// startReputationSaveTask saves the relay reputation every reputationSaveInterval
+func (m *BoostService) startReputationSaveTask() {
+	for {
+		time.Sleep(reputationSaveInterval)
+		if err := m.saveReputation(); err != nil {
+			m.log.WithError(err).Error("could not save the relay reputation")
+		}
+	}
+}
+
+// ringItems returns the count items of a ring buffer whose next item is written at next, oldest first
+func ringItems[T any](ring []T, count, next int) []T {
+	items := make([]T, 0, count)
+	for i := range count {
+		items = append(items, ring[(next-count+i+len(ring))%len(ring)])
+	}
+	return items
+}
+
+// fillRing writes the most recent items (oldest first) to an empty ring buffer and returns its count and next index
+func fillRing[T any](ring []T, items []T) (count, next int) {
+	if len(items) > len(ring) {
+		items = items[len(items)-len(ring):]
+	}
+	copy(ring, items)
+	return len(items), len(items) % len(ring)
+}
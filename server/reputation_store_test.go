@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingItems(t *testing.T) {
+	ring := make([]int, 3)
+	count, next := fillRing(ring, []int{1, 2, 3, 4})
+	require.Equal(t, []int{2, 3, 4}, ring)
+	require.Equal(t, []int{2, 3, 4}, ringItems(ring, count, next))
+
+	count, next = fillRing(make([]int, 3), []int{1, 2})
+	require.Equal(t, 2, count)
+	require.Equal(t, 2, next)
+	require.Equal(t, []int{3, 4, 2}, ringItems([]int{2, 3, 4}, 3, 1))
+}
+
+func TestReputationStore(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := filepath.Join(t.TempDir(), "reputation.json")
+
+	backend := newTestBackend(t, 2, time.Second)
+	backend.boost.reputationFile = path
+	backend.boost.breaker = newCircuitBreaker(1, time.Hour, nopMetricsSink{})
+	backend.boost.adaptiveTimeouts = newAdaptiveTimeouts(time.Millisecond, time.Second)
+
+	// Relay 0 bids, relay 1 is down and trips the circuit breaker
+	backend.relays[1].Server.Close()
+	rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, backend.boost.saveReputation())
+	saved, err := json.Marshal(backend.boost.reputation())
+	require.NoError(t, err)
+
+	// A restarted service knows the relays
+	relays := []types.RelayEntry{backend.relays[0].RelayEntry, backend.relays[1].RelayEntry}
+	service, err := NewBoostService(BoostServiceOpts{
+		Log:                     mock.TestLog,
+		Relays:                  relays,
+		GenesisForkVersionHex:   "0x00000000",
+		RequestTimeoutGetHeader: time.Second,
+		CircuitBreakerFailures:  1,
+		CircuitBreakerCooldown:  time.Hour,
+		AdaptiveTimeoutMin:      time.Millisecond,
+		AdaptiveTimeoutMax:      time.Second,
+		ReputationFile:          path,
+	})
+	require.NoError(t, err)
+	restored, err := json.Marshal(service.reputation())
+	require.NoError(t, err)
+	require.JSONEq(t, string(saved), string(restored))
+
+	require.Equal(t, uint64(1), service.relayStats.snapshot(relays)[0].BidsReceived)
+	require.Equal(t, circuitOpen, service.breaker.state(relays[1]))
+	require.False(t, service.breaker.allow(relays[1]))
+	health := service.relayHealth.snapshot(relays)
+	require.Equal(t, 1, health[1].RecentRequests)
+	require.InDelta(t, 1.0, health[1].RecentErrorRate, 1e-9)
+
+	t.Run("Missing file", func(t *testing.T) {
+		service.reputationFile = filepath.Join(t.TempDir(), "missing.json")
+		require.NoError(t, service.loadReputation())
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		service.reputationFile = filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(service.reputationFile, []byte("{"), 0o600))
+		require.Error(t, service.loadReputation())
+	})
+}
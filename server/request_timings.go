@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type requestTimingsKey struct{}
+
+// requestTimings collects the durations of the phases of a request, filled in by SendHTTPRequest
+type requestTimings struct {
+	mu sync.Mutex
+
+	start, dnsStart, connectStart, tlsStart, wroteRequest, firstByte time.Time
+
+	dns, connect, tls, ttfb, body, total time.Duration
+}
+
+// withRequestTimings returns a context which makes SendHTTPRequest record the request phase durations
+func withRequestTimings(ctx context.Context) (context.Context, *requestTimings) {
+	timings := new(requestTimings)
+	return context.WithValue(ctx, requestTimingsKey{}, timings), timings
+}
+
+func requestTimingsFromContext(ctx context.Context) *requestTimings {
+	timings, _ := ctx.Value(requestTimingsKey{}).(*requestTimings)
+	return timings
+}
+
+// clientTrace hooks into the phases of the request. Phases which don't happen (i.e. on connection reuse) stay zero.
+func (t *requestTimings) clientTrace() *httptrace.ClientTrace {
+	now := func(ts *time.Time) {
+		t.mu.Lock()
+		*ts = time.Now()
+		t.mu.Unlock()
+	}
+	since := func(ts *time.Time, d *time.Duration) {
+		t.mu.Lock()
+		*d = time.Since(*ts)
+		t.mu.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&t.dnsStart, &t.dns) },
+		ConnectStart:         func(string, string) { now(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { since(&t.connectStart, &t.connect) },
+		TLSHandshakeStart:    func() { now(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&t.tlsStart, &t.tls) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteRequest) },
+		GotFirstResponseByte: func() { now(&t.firstByte); since(&t.wroteRequest, &t.ttfb) },
+	}
+}
+
+// begin resets the phases for a new request attempt. The start of the first attempt is kept, so the total duration
+// includes the retries.
+func (t *requestTimings) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dnsStart, t.connectStart, t.tlsStart, t.wroteRequest, t.firstByte = time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	t.dns, t.connect, t.tls, t.ttfb, t.body, t.total = 0, 0, 0, 0, 0, 0
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+}
+
+// finish records the body-read and total durations
+func (t *requestTimings) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.body = time.Since(t.firstByte)
+	}
+	t.total = time.Since(t.start)
+}
+
+// phases returns all non-zero phase durations by name
+func (t *requestTimings) phases() map[string]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phases := make(map[string]time.Duration)
+	for name, d := range map[string]time.Duration{
+		"dns":     t.dns,
+		"connect": t.connect,
+		"tls":     t.tls,
+		"ttfb":    t.ttfb,
+		"body":    t.body,
+		"total":   t.total,
+	} {
+		if d > 0 {
+			phases[name] = d
+		}
+	}
+	return phases
+}
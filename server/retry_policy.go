@@ -0,0 +1,42 @@
+package server
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// defaultRetryBackoff is the delay between the attempts of a request, if not configured
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// retryPolicy is how a request to a relay is retried
+type retryPolicy struct {
+	maxRetries int           // maximum number of attempts, the request is sent once if zero
+	backoff    time.Duration // delay between the attempts
+	jitter     time.Duration // random extra delay up to this
+}
+
+// delay returns the delay before the next attempt
+func (p retryPolicy) delay() time.Duration {
+	if p.jitter <= 0 {
+		return p.backoff
+	}
+	return p.backoff + rand.N(p.jitter)
+}
+
+// getPayloadRetryPolicy returns the getPayload retry policy of the relay, the global policy for the options the relay
+// doesn't set
+func (m *BoostService) getPayloadRetryPolicy(relay types.RelayEntry) retryPolicy {
+	policy := retryPolicy{maxRetries: m.requestMaxRetries, backoff: m.requestRetryBackoff, jitter: m.requestRetryJitter}
+	if relay.MaxRetries > 0 {
+		policy.maxRetries = relay.MaxRetries
+	}
+	if relay.RetryBackoff > 0 {
+		policy.backoff = relay.RetryBackoff
+	}
+	if relay.RetryJitter > 0 {
+		policy.jitter = relay.RetryJitter
+	}
+	return policy
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("Relays override the global policy", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.requestRetryJitter = 10 * time.Millisecond
+		relay := backend.relays[0].RelayEntry
+		require.Equal(t, retryPolicy{maxRetries: 5, backoff: defaultRetryBackoff, jitter: 10 * time.Millisecond}, backend.boost.getPayloadRetryPolicy(relay))
+
+		relay.MaxRetries = 2
+		relay.RetryBackoff = time.Second
+		require.Equal(t, retryPolicy{maxRetries: 2, backoff: time.Second, jitter: 10 * time.Millisecond}, backend.boost.getPayloadRetryPolicy(relay))
+	})
+
+	t.Run("The jitter is added to the backoff", func(t *testing.T) {
+		policy := retryPolicy{backoff: 100 * time.Millisecond, jitter: 50 * time.Millisecond}
+		for range 100 {
+			delay := policy.delay()
+			require.GreaterOrEqual(t, delay, 100*time.Millisecond)
+			require.Less(t, delay, 150*time.Millisecond)
+		}
+		require.Equal(t, 100*time.Millisecond, retryPolicy{backoff: 100 * time.Millisecond}.delay())
+	})
+
+	t.Run("getPayload requests use the policy of the relay", func(t *testing.T) {
+		jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+		require.NoError(t, err)
+		defer jsonFile.Close()
+		signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+		require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		u, err := url.Parse(backend.relays[0].RelayEntry.String() + "?max_retries=2&retry_backoff=10ms")
+		require.NoError(t, err)
+		relay, err := types.NewRelayEntry(u.String())
+		require.NoError(t, err)
+		backend.boost.relays = []types.RelayEntry{relay}
+
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+)
+
+// EventEquivocationPrevented is emitted if a signed blinded block is refused because another block of the same slot
+// was already revealed
+const EventEquivocationPrevented EventType = "equivocation_prevented"
+
+var errEquivocation = errors.New("a different block was already revealed for this slot")
+
+// revealRecord is a signed blinded block sent to the relays
+type revealRecord struct {
+	Slot       phase0.Slot   `json:"slot,string"`
+	BlockHash  phase0.Hash32 `json:"block_hash"`
+	RevealedAt time.Time     `json:"revealed_at"`
+}
+
+const (
+	// revealJournalSlots is how long reveals are kept in the journal, the blocks of older slots can't be proposed
+	// anymore
+	revealJournalSlots = 4 * slotsPerEpoch
+	// revealJournalCompactLines is the number of lines after which the journal file is rewritten with the kept reveals
+	revealJournalCompactLines = 1024
+)
+
+// revealJournal records which block was revealed for each slot, like slashing protection of a validator client, so a
+// second block of the same slot is never sent to the relays. Each reveal is appended to the file as a JSON line and
+// synced before the block is sent.
+type revealJournal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File // nil until the file is opened for appending
+	lines   int      // lines of the file, including the reveals pruned since
+	latest  phase0.Slot
+	reveals map[phase0.Slot]revealRecord
+}
+
+// loadRevealJournal reads the reveal journal from the file, if it exists, and rewrites it without the pruned reveals
+func loadRevealJournal(path string) (*revealJournal, error) {
+	j := &revealJournal{path: path, reveals: make(map[phase0.Slot]revealRecord)}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	dec := json.NewDecoder(file)
+	for {
+		var record revealRecord
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// A line cut off by a crash was never synced, so its block wasn't sent
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		j.add(record)
+	}
+	j.prune()
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// reveal records the block of the slot. It fails if another block of the slot was revealed, or if the journal can't
+// be written, the block must not be sent to the relays then.
+func (j *revealJournal) reveal(slot phase0.Slot, blockHash phase0.Hash32, now time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if record, ok := j.reveals[slot]; ok {
+		if record.BlockHash != blockHash {
+			return fmt.Errorf("%w: block %s was revealed at %s", errEquivocation, record.BlockHash.String(), record.RevealedAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	record := revealRecord{Slot: slot, BlockHash: blockHash, RevealedAt: now.UTC()}
+	if err := j.append(record); err != nil {
+		return fmt.Errorf("could not write the reveal journal: %w", err)
+	}
+	j.add(record)
+	j.prune()
+	return nil
+}
+
+func (j *revealJournal) add(record revealRecord) {
+	j.reveals[record.Slot] = record
+	j.latest = max(j.latest, record.Slot)
+	j.lines++
+}
+
+// prune drops the reveals of slots before the last revealJournalSlots
+func (j *revealJournal) prune() {
+	for slot := range j.reveals {
+		if slot+revealJournalSlots <= j.latest {
+			delete(j.reveals, slot)
+		}
+	}
+}
+
+// append writes the record to the end of the file and syncs it, after compacting the file if it has grown too long
+func (j *revealJournal) append(record revealRecord) error {
+	if j.lines >= revealJournalCompactLines {
+		if err := j.compact(); err != nil {
+			return err
+		}
+	}
+	if j.file == nil {
+		file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return err
+		}
+		j.file = file
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// compact rewrites the file with the kept reveals, by slot
+func (j *revealJournal) compact() error {
+	records := make([]revealRecord, 0, len(j.reveals))
+	for _, record := range j.reveals {
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b revealRecord) int {
+		return cmp.Compare(a.Slot, b.Slot)
+	})
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if err := writeFileAtomic(j.path, data); err != nil {
+		return err
+	}
+	// The file was replaced, appends have to go to the new one
+	if err := j.closeFile(); err != nil {
+		return err
+	}
+	j.lines = len(records)
+	return nil
+}
+
+// Close closes the journal file
+func (j *revealJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.closeFile()
+}
+
+// closeFile closes the file, it is opened again by the next reveal
+func (j *revealJournal) closeFile() error {
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// checkReveal records the block in the reveal journal, if enabled, and alerts if it is refused
+func (m *BoostService) checkReveal(log *logrus.Entry, slot phase0.Slot, blockHash phase0.Hash32) error {
+	if m.revealJournal == nil {
+		return nil
+	}
+	err := m.revealJournal.reveal(slot, blockHash, time.Now())
+	if errors.Is(err, errEquivocation) {
+		log.WithFields(logrus.Fields{
+			"alert":     "EQUIVOCATION_PREVENTED",
+			"severity":  severityCritical,
+			"slot":      slot,
+			"blockHash": blockHash.String(),
+		}).WithError(err).Error("ALERT: refusing to reveal a second block for the slot")
+		m.emitEvent(Event{
+			Type:      EventEquivocationPrevented,
+			Severity:  severityCritical,
+			Slot:      uint64(slot),
+			BlockHash: blockHash.String(),
+			Error:     err.Error(),
+		})
+	} else if err != nil {
+		log.WithError(err).Error("refusing to reveal the block")
+	}
+	return err
+}
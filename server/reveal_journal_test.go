@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevealJournal(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	// Another block of the same slot
+	otherBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	_, err = jsonFile.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	require.NoError(t, DecodeJSON(jsonFile, &otherBlock))
+	otherBlock.Message.Body.ExecutionPayloadHeader.BlockHash[0] ^= 0xff
+
+	path := filepath.Join(t.TempDir(), "reveals.json")
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.revealJournal, err = loadRevealJournal(path)
+	require.NoError(t, err)
+	backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+
+	rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Retries of the same block are allowed
+	rr = backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathGetPayload))
+
+	// Another block of the slot isn't sent to the relays
+	rr = backend.request(t, http.MethodPost, params.PathGetPayload, otherBlock)
+	require.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), errEquivocation.Error())
+	require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathGetPayload))
+
+	// The journal is kept across restarts
+	journal, err := loadRevealJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, journal.reveal(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash, time.Now()))
+	require.ErrorIs(t, journal.reveal(otherBlock.Message.Slot, otherBlock.Message.Body.ExecutionPayloadHeader.BlockHash, time.Now()), errEquivocation)
+	require.NoError(t, journal.reveal(otherBlock.Message.Slot+1, otherBlock.Message.Body.ExecutionPayloadHeader.BlockHash, time.Now()))
+}
+
+func TestRevealJournalPruning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reveals.jsonl")
+	journal, err := loadRevealJournal(path)
+	require.NoError(t, err)
+	last := phase0.Slot(revealJournalCompactLines + 10)
+	for slot := range last + 1 {
+		require.NoError(t, journal.reveal(slot, phase0.Hash32{byte(slot)}, time.Now()))
+	}
+
+	// Only the reveals of the last slots are kept, and the file was compacted on the way
+	require.Len(t, journal.reveals, revealJournalSlots)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Less(t, bytes.Count(data, []byte("\n")), revealJournalCompactLines)
+	require.Equal(t, journal.lines, bytes.Count(data, []byte("\n")))
+	require.NoError(t, journal.Close())
+
+	// A line cut off by a crash is ignored
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"slot":"2000","block_hash":"0x`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal, err = loadRevealJournal(path)
+	require.NoError(t, err)
+	require.Len(t, journal.reveals, revealJournalSlots)
+	require.ErrorIs(t, journal.reveal(last, phase0.Hash32{0xff}, time.Now()), errEquivocation)
+	require.NoError(t, journal.reveal(last-revealJournalSlots, phase0.Hash32{0xff}, time.Now()))
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/flashbots/mev-boost/config"
+	"github.com/getsentry/sentry-go"
+)
+
+// sentryFlushTimeout is the maximum time to wait for reports to be sent before a crash or shutdown
+const sentryFlushTimeout = 2 * time.Second
+
+// SentryReporter sends panics and high-severity auction failures to Sentry
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter creates a SentryReporter sending to the project of the DSN
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		Release:          "mev-boost@" + config.Version,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SentryReporter{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// Close sends the pending reports
+func (s *SentryReporter) Close() error {
+	s.hub.Flush(sentryFlushTimeout)
+	return nil
+}
+
+func (s *SentryReporter) ReportPanic(recovered any, tags map[string]string) {
+	s.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelFatal)
+		scope.SetTags(tags)
+		s.hub.Recover(recovered)
+	})
+	s.hub.Flush(sentryFlushTimeout)
+}
+
+func (s *SentryReporter) ReportEvent(event Event) {
+	s.hub.WithScope(func(scope *sentry.Scope) {
+		level := sentry.LevelError
+		if event.Severity == severityCritical {
+			level = sentry.LevelFatal
+		}
+		scope.SetLevel(level)
+		scope.SetFingerprint([]string{string(event.Type)})
+		scope.SetTags(map[string]string{
+			"event":   string(event.Type),
+			"slot":    strconv.FormatUint(event.Slot, 10),
+			"slotUID": event.SlotUID,
+		})
+		scope.SetContext("auction", sentry.Context{
+			"blockHash":   event.BlockHash,
+			"value":       event.Value,
+			"relays":      event.Relays,
+			"relayErrors": event.RelayErrors,
+		})
+
+		message := fmt.Sprintf("%s in slot %d", event.Type, event.Slot)
+		if event.Error != "" {
+			message += ": " + event.Error
+		}
+		s.hub.CaptureMessage(message)
+	})
+}
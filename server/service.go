@@ -2,25 +2,29 @@ package server
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	builderApi "github.com/attestantio/go-builder-client/api"
 	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
 	eth2ApiV1Bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
 	eth2ApiV1Capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
 	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/flashbots/go-utils/httplogger"
@@ -29,16 +33,33 @@ import (
 	"github.com/flashbots/mev-boost/server/types"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/holiman/uint256"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 var (
 	errNoRelays                  = errors.New("no relays")
+	errGetHeaderTooLate          = errors.New("getHeader request too late into the slot")
+	errGetHeaderPastSlot         = errors.New("getHeader request for a past slot")
+	errGetHeaderFutureSlot       = errors.New("getHeader request for a slot too far in the future")
 	errInvalidSlot               = errors.New("invalid slot")
 	errInvalidHash               = errors.New("invalid hash")
 	errInvalidPubkey             = errors.New("invalid pubkey")
 	errNoSuccessfulRelayResponse = errors.New("no successful relay response")
 	errServerAlreadyRunning      = errors.New("server already running")
+	errUnauthorized              = errors.New("unauthorized")
+	errRouteNotFound             = errors.New("route not found")
+	errMethodNotAllowed          = errors.New("method not allowed")
+	errUnknownSignatureCheck     = errors.New("unknown relay signature check")
+	errInvalidMinBidSlots        = errors.New("relative min-bid needs a positive number of slots")
+	errInvalidAdaptiveTimeouts   = errors.New("the min adaptive timeout is larger than the max")
+	errInvalidLocalBlockValue    = errors.New("invalid local block value")
+	errUnknownBidAnomalyAction   = errors.New("unknown bid anomaly action")
+	errUnknownGasLimitCheck      = errors.New("unknown gas limit check")
+	errUnknownPayloadCheck       = errors.New("unknown payload check")
 )
 
 var (
@@ -46,9 +67,28 @@ var (
 	nilResponse = struct{}{}
 )
 
+// httpErrorResp is the error response of the beacon and builder APIs, with the failures of the relays if the request
+// failed at the relays
 type httpErrorResp struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code     int            `json:"code"`
+	Message  string         `json:"message"`
+	Failures []relayFailure `json:"failures,omitempty"`
+}
+
+// relayFailure is how a relay failed a request: the error and its class, when the relay was asked (in ms after the
+// request to mev-boost) and how long it took to fail
+type relayFailure struct {
+	Relay            string `json:"relay"`
+	Message          string `json:"message"`
+	Class            string `json:"class"`
+	RequestedAfterMs int64  `json:"requested_after_ms"`
+	DurationMs       int64  `json:"duration_ms"`
+}
+
+// sortRelayFailures sorts the failures by relay
+func sortRelayFailures(failures []relayFailure) []relayFailure {
+	slices.SortFunc(failures, func(a, b relayFailure) int { return strings.Compare(a.Relay, b.Relay) })
+	return failures
 }
 
 type slotUID struct {
@@ -60,35 +100,368 @@ type slotUID struct {
 type BoostServiceOpts struct {
 	Log                   *logrus.Entry
 	ListenAddr            string
+	AdditionalListenAddrs []string // the builder API is served on these addresses as well
+	MetricsAddr           string
+	PprofAddr             string
+	GRPCAddr              string // disabled if empty
 	Relays                []types.RelayEntry
 	RelayMonitors         []*url.URL
 	GenesisForkVersionHex string
 	GenesisTime           uint64
 	RelayCheck            bool
 	RelayMinBid           types.U256Str
+	LocalBlockValueDelta  types.U256Str // a bid must beat the local block value sent by the beacon node by this much
+
+	// SlotTimeSec is the slot duration of the network, config.SlotTimeSec if zero
+	SlotTimeSec uint64
+
+	// FuluForkEpoch is the epoch of the Fulu fork, nil if it isn't scheduled. Electra blinded blocks from this epoch on
+	// are Fulu blocks, unless the beacon node sends their consensus version.
+	FuluForkEpoch *phase0.Epoch
+
+	// LegacyForks accepts Bellatrix and Capella bids and blinded blocks, which are rejected otherwise
+	LegacyForks bool
+
+	// RelayMinBidPercent raises the min-bid to this percentage of the median bid value of the last RelayMinBidSlots
+	// slots, if higher. Disabled if zero.
+	RelayMinBidPercent float64
+	RelayMinBidSlots   int
+
+	// MaxBid and MaxBidMedianMultiple (times the median bid value of the last RelayMinBidSlots slots) bound the
+	// plausible bid values, disabled if zero. BidAnomalyAction decides about bids above, see BidAnomalyActions.
+	MaxBid               types.U256Str
+	MaxBidMedianMultiple float64
+	BidAnomalyAction     string
+
+	// GasLimitCheck decides about bids whose gas limit deviates from the registered gas limit of the validator beyond
+	// the allowed adjustment, see GasLimitChecks. GasLimitCheckWarn if empty.
+	GasLimitCheck string
+
+	// PayloadCheck decides about getPayload responses whose recomputed block hash, transactions root or withdrawals
+	// root doesn't match the signed blinded block, or whose execution requests don't match the bid, see PayloadChecks.
+	// PayloadCheckWarn if empty.
+	PayloadCheck string
+
+	// BidPolicyURL enables the operator's policy service, which may veto or reorder the candidate bids of getHeader
+	// within BidPolicyTimeout (100ms if zero). With BidPolicyFailClosed, no bid is returned if the service fails.
+	BidPolicyURL        *url.URL
+	BidPolicyTimeout    time.Duration
+	BidPolicyFailClosed bool
+
+	// BidScript accepts, rejects or scores each bid, if set
+	BidScript *BidScript
+	// BidPlugin filters, scores and breaks ties between bids, if set. It can be replaced with Reload.
+	BidPlugin *BidPlugin
+
+	// CircuitBreakerFailures is the number of consecutive failed requests after which a relay isn't queried for
+	// getHeader during the CircuitBreakerCooldown. Disabled if zero.
+	CircuitBreakerFailures int
+	CircuitBreakerCooldown time.Duration
+
+	// QuarantineFaults is the number of consecutive invalid responses (bad signatures, wrong parent hashes, invalid
+	// blobs or payloads) after which a relay isn't asked for bids for QuarantineEpochs. Disabled if zero.
+	QuarantineFaults int
+	QuarantineEpochs int
+
+	// AdaptiveTimeoutMin and AdaptiveTimeoutMax bound the getHeader timeouts derived from the recent latencies of the
+	// relays. Disabled if the max is zero.
+	AdaptiveTimeoutMin time.Duration
+	AdaptiveTimeoutMax time.Duration
+
+	// BidTiebreaker decides between bids of the same value and relay priority, see Tiebreakers
+	BidTiebreaker string
+
+	// RelayLabelPreferences are the percentages by which the bids of relays with a label are preferred when comparing
+	// bids, i.e. 5 for non-filtering relays uses their bids unless other bids are more than 5% higher
+	RelayLabelPreferences map[string]float64
+
+	// BidSelector decides which bid wins getHeader, if set. Otherwise the relay tiers, boost factors, label preferences
+	// and the BidTiebreaker decide, along with the tiebreak function of the BidPlugin (if any).
+	BidSelector BidSelector
+
+	// RelaySignatureCheck is the signature check of relays without signature_check option, see types.SignatureChecks.
+	// Bids are verified if empty.
+	RelaySignatureCheck string
+
+	// RelaysURL is fetched every RelaysURLInterval for a relay list signed with RelaysURLPubkey, the relays are used in
+	// addition to Relays
+	RelaysURL         string
+	RelaysURLPubkey   ed25519.PublicKey
+	RelaysURLInterval time.Duration
+
+	// RelaysDNS are domains whose DNS records are resolved every RelaysDNSInterval for relays, used in addition to
+	// Relays, see dnsRelaySource
+	RelaysDNS         []string
+	RelaysDNSInterval time.Duration
+
+	// ValidatorRoutes overrides the relays and min bid for some validators
+	ValidatorRoutes ValidatorRoutes
+	// RelayFilters restricts which validators use some relays
+	RelayFilters RelayFilters
+
+	// ReadyMinRelays is the number of reachable relays required by the readiness probe
+	ReadyMinRelays int
+	// ReadyRelayMaxAge is how long a successful relay request counts as reachable for the readiness probe
+	ReadyRelayMaxAge time.Duration
+
+	// ReputationFile persists the relay statistics, circuit breakers and latencies across restarts, if set
+	ReputationFile string
+	// RegistrationsFile persists the latest validator registrations across restarts, if set
+	RegistrationsFile string
 
 	RequestTimeoutGetHeader  time.Duration
 	RequestTimeoutGetPayload time.Duration
 	RequestTimeoutRegVal     time.Duration
 	RequestMaxRetries        int
+	// RequestRetryBackoff is the delay between the attempts of a relay request, 100ms if zero. RequestRetryJitter adds a
+	// random extra delay up to this. Relays can override them and the max retries for getPayload requests.
+	RequestRetryBackoff time.Duration
+	RequestRetryJitter  time.Duration
+	// RequestCompressionMinSize gzips getPayload and registerValidator request bodies to relays of at least this many
+	// bytes, if set
+	RequestCompressionMinSize int
+	// RelayClientCert and RelayClientKey are the files of the TLS client certificate presented to relays, if set.
+	// Relays can have their own client certificate.
+	RelayClientCert string
+	RelayClientKey  string
+	// RegValResendInterval is how often unchanged validator registrations are forwarded to the relays. All
+	// registrations are forwarded if zero.
+	RegValResendInterval time.Duration
+	// RegValChunkSize splits the validator registrations to a relay into concurrent requests of at most this many
+	// registrations, if set
+	RegValChunkSize int
+	// RegValChunkRetries is how often a failed request with validator registrations is retried
+	RegValChunkRetries int
+	// RegValSpreadWindow buffers the validator registrations and forwards each to the relays at a random time within
+	// this window, if set. The registrations are forwarded at once if zero.
+	RegValSpreadWindow time.Duration
+	// RegValVerifySignatures rejects validator registrations with an invalid signature instead of forwarding them
+	RegValVerifySignatures bool
+
+	// GetHeaderMaxIntoSlot rejects getHeader requests arriving later into the slot with 204, if set
+	GetHeaderMaxIntoSlot time.Duration
+	// GetHeaderSoftDeadline returns the best bid at this time into the slot without waiting for the remaining relays
+	GetHeaderSoftDeadline time.Duration
+	// GetHeaderStaggerDelay staggers the getHeader requests by relay latency: the fastest relay is asked first, each
+	// slower relay this much later. All relays are asked at once if zero.
+	GetHeaderStaggerDelay time.Duration
+	// BackupRelayWindow is how long getHeader waits for a usable bid from the other relays before asking the backup
+	// relays. The backup relays are asked once all other relays answered if zero.
+	BackupRelayWindow time.Duration
+	// GetHeaderPollInterval and GetHeaderPollBudget poll the relays for newer bids every interval until the budget
+	// (from the start of the getHeader request) is over, for relays with bid cancellations. Disabled if either is zero.
+	GetHeaderPollInterval time.Duration
+	GetHeaderPollBudget   time.Duration
+	// GetHeaderRetryDelay asks the relays once more after this delay if none of them had a usable bid, if the retry
+	// starts before the getHeader deadlines. Disabled if zero.
+	GetHeaderRetryDelay time.Duration
+
+	// GetPayloadFallbackDelay is how long getPayload waits for the relays of the bid before asking the other relays too.
+	// All relays are asked at once if zero.
+	GetPayloadFallbackDelay time.Duration
+	// GetPayloadHedgeDelay asks the relays for the payload one after another, the relays of the bid and the faster relays
+	// first: each further relay this much later, or once the previous relay failed. All relays are asked at once if zero.
+	GetPayloadHedgeDelay time.Duration
+	// GetPayloadCacheTTL serves retried getPayload requests for the same block with the payload delivered within this
+	// time, if set
+	GetPayloadCacheTTL time.Duration
+	// GetPayloadStrict refuses getPayload requests for blocks whose header wasn't returned by getHeader
+	GetPayloadStrict bool
+	// RevealJournalFile records the block revealed for each slot in this file, and refuses a second block of a slot, if
+	// set
+	RevealJournalFile string
+
+	// PayloadDeliveryCheckDelay enables confirming payload deliveries with the relay data API, queried after this delay
+	PayloadDeliveryCheckDelay time.Duration
+
+	// BeaconNodeURL enables checking that blocks with delivered payloads landed on chain
+	BeaconNodeURL *url.URL
+	// PublishBlockFallback publishes the block through the beacon node if the payload couldn't be returned to the
+	// beacon node which requested it
+	PublishBlockFallback bool
+
+	// ExecutionNodeURL enables checking that the registered fee recipients received the bid values of delivered payloads
+	ExecutionNodeURL *url.URL
+
+	// MetricsSink additionally receives the relay metrics, i.e. a StatsdSink
+	MetricsSink MetricsSink
+
+	// ErrorReporter receives panics and high-severity auction failures, i.e. a SentryReporter
+	ErrorReporter ErrorReporter
+
+	// BidAuditLog receives every bid as JSON line, if set
+	BidAuditLog io.Writer
+
+	// BidStore persists bids and payload deliveries and enables the history API, if set
+	BidStore *BidStore
+
+	// Webhooks receive auction events as JSON POST requests
+	Webhooks       []string
+	WebhookTimeout time.Duration
+
+	// EventsToken enables the Server-Sent Events and WebSocket bid streams, authenticated with this bearer token
+	EventsToken string
+
+	// AdminToken enables the admin API, authenticated with this bearer token
+	AdminToken string
+	// MetricsToken protects the metrics and the relay stats, relay scores and bid history endpoints with this bearer
+	// token, if set
+	MetricsToken string
+	// ClientAllowlist refuses requests of clients outside of these networks with 403, if set. ClientDenylist refuses
+	// requests of clients in these networks.
+	ClientAllowlist []netip.Prefix
+	ClientDenylist  []netip.Prefix
+	// RegisterValidatorMaxBytes and GetPayloadMaxBytes refuse request bodies larger than this with 413. Zero uses the
+	// defaults, by fork of the blinded block for getPayload.
+	RegisterValidatorMaxBytes int64
+	GetPayloadMaxBytes        int64
+	// RelayDNSResolver is the DNS server (host:port) resolving the relay host names and the relay DNS discovery, the
+	// system resolver if empty. The relay host addresses are cached for the TTL of their records, up to RelayDNSMaxTTL.
+	RelayDNSResolver string
+	RelayDNSMaxTTL   time.Duration
+	// Tuning of the relay connections, the defaults of the Go HTTP client are used if zero. RelayKeepAlive is the TCP
+	// keepalive period, negative to disable.
+	RelayDisableHTTP2        bool
+	RelayMaxIdleConnsPerHost int
+	RelayIdleConnTimeout     time.Duration
+	RelayKeepAlive           time.Duration
+	// RateLimitPerIP and RateLimitGlobal are the builder API requests per second of a client address and of all
+	// clients, over which requests are refused with 429. getHeader and getPayload are never limited. LoadShedding
+	// refuses registerValidator requests while getHeader or getPayload requests are served.
+	RateLimitPerIP  float64
+	RateLimitGlobal float64
+	LoadShedding    bool
+	// RelayPrewarmLead is how long before each slot the relay connections are established or refreshed, 0 to disable
+	RelayPrewarmLead time.Duration
+	// ReloadConfig is called by the admin API to reload the config file, see Reload
+	ReloadConfig func() error
 }
 
 // BoostService - the mev-boost service
 type BoostService struct {
 	listenAddr    string
+	metricsAddr   string
+	pprofAddr     string
+	grpcAddr      string
+	configLock    sync.RWMutex // guards the reloadable settings, read them with currentConfig
 	relays        []types.RelayEntry
 	relayMonitors []*url.URL
 	log           *logrus.Entry
+	serversLock   sync.Mutex // guards the servers, they are started and shut down by different goroutines
 	srv           *http.Server
+	metricsSrv    *http.Server
+	pprofSrv      *http.Server
+	grpcSrv       *grpc.Server
 	relayCheck    bool
 	relayMinBid   types.U256Str
 	genesisTime   uint64
+	slotTimeSec   uint64
+	fuluForkEpoch *phase0.Epoch // nil if not scheduled
+	legacyForks   bool
+	tiebreaker    tiebreaker
+
+	labelPreferences labelPreferences
+	signatureCheck   string
+
+	relayMinBidPercent float64
+	bidValues          *bidValueHistory // the recent bid values, nil without relative min-bid or max-bid
+
+	localBlockValueDelta types.U256Str
+	maxBid               types.U256Str
+	maxBidMedianMultiple float64
+	bidAnomalyAction     string
+
+	gasLimitCheck string
+	payloadCheck  string
+	registrations *validatorRegistrations
+	constraints   *slotConstraints // the constraints API commitments of the proposers
+	bidPolicy     *bidPolicy       // nil if disabled
+	bidScript     *BidScript       // nil if disabled
+	bidPlugin     *BidPlugin       // nil if disabled, guarded by configLock
+	bidSelector   BidSelector      // nil for the default selector
+
+	// registrationCache skips forwarding unchanged registrations to the relays, nil if disabled
+	registrationCache      *registrationCache
+	regValChunkSize        int // zero sends all registrations in one request
+	regValChunkRetries     int
+	regValVerifySignatures bool
+	// registrationSpreader buffers the registrations to spread their forwarding, nil if disabled
+	registrationSpreader *registrationSpreader
+
+	validatorRoutes ValidatorRoutes
+	relayFilters    RelayFilters
+
+	relaySources       []relaySource
+	remoteRelaySources map[string][]types.RelayEntry // the relays of each relay source, guarded by configLock
+	remoteRelays       []types.RelayEntry            // the relays of all relay sources, guarded by configLock
+
+	adminRelays         adminRelays   // the relay changes of the admin API, guarded by configLock
+	relayChanges        []relayChange // pending relay changes of the admin API, guarded by configLock
+	relayChangesApplyAt time.Time     // when the pending relay changes are applied, guarded by configLock
+
+	readyMinRelays   int
+	readyRelayMaxAge time.Duration
+
+	reputationFile    string // empty if disabled
+	registrationsFile string // empty if disabled
 
 	builderSigningDomain phase0.Domain
 	httpClientGetHeader  http.Client
 	httpClientGetPayload http.Client
 	httpClientRegVal     http.Client
+	relayTransports      *relayTransports
 	requestMaxRetries    int
+	requestRetryBackoff  time.Duration
+	requestRetryJitter   time.Duration
+	adaptiveTimeouts     *adaptiveTimeouts // nil if disabled
+
+	getHeaderMaxIntoSlot  time.Duration
+	getHeaderSoftDeadline time.Duration
+	getHeaderStaggerDelay time.Duration
+	backupRelayWindow     time.Duration
+	getHeaderPollInterval time.Duration
+	getHeaderPollBudget   time.Duration
+	getHeaderRetryDelay   time.Duration
+
+	payloadDeliveryCheckDelay time.Duration
+	beaconNodeURL             *url.URL
+	publishBlockFallback      bool
+	executionNodeURL          *url.URL
+	getPayloadFallbackDelay   time.Duration
+	getPayloadHedgeDelay      time.Duration
+	payloadCache              *payloadCache  // nil if disabled
+	revealJournal             *revealJournal // nil if disabled
+	getPayloadStrict          bool
+
+	metricsSink   MetricsSink
+	errorReporter ErrorReporter
+	relayStats    *relayStatsStore
+	relayAuctions *relayAuctionStore
+	relayHealth   *relayHealthStore
+	breaker       *circuitBreaker  // nil if disabled
+	quarantine    *relayQuarantine // nil if disabled
+	bidAuditLog   *bidAuditLog
+	bidStore      *BidStore
+	webhooks      *webhookNotifier
+	events        *eventBroker
+	eventsToken   string
+
+	relayCapabilities *relayCapabilityStore
+	relaySSZ          *relaySSZStore
+
+	adminToken   string
+	metricsToken string
+	reloadConfig func() error
+
+	clientAllowlist []netip.Prefix
+	clientDenylist  []netip.Prefix
+
+	additionalListenAddrs []string
+	shutdown              shutdownState
+	bodyLimits            bodyLimits
+	relayHosts            *relayHosts
+	prewarmLead           time.Duration
+	rateLimiter           *rateLimiter
 
 	bids     map[string]bidResp // keeping track of bids, to log the originating relay on withholding
 	bidsLock sync.Mutex
@@ -99,26 +472,223 @@ type BoostService struct {
 
 // NewBoostService created a new BoostService
 func NewBoostService(opts BoostServiceOpts) (*BoostService, error) {
-	if len(opts.Relays) == 0 {
+	var relaySources []relaySource
+	if opts.RelaysURL != "" {
+		relaySources = append(relaySources, relayListSource(opts.RelaysURL, opts.RelaysURLPubkey, opts.RelaysURLInterval))
+	}
+	relayHosts := newRelayHosts(opts.RelayDNSResolver, opts.RelayDNSMaxTTL)
+	for _, domain := range opts.RelaysDNS {
+		relaySources = append(relaySources, dnsRelaySource(relayHosts.resolver(), domain, opts.RelaysDNSInterval))
+	}
+	if len(opts.Relays) == 0 && len(relaySources) == 0 {
 		return nil, errNoRelays
 	}
 
+	tiebreak, err := newTiebreaker(opts.BidTiebreaker)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := newLabelPreferences(opts.RelayLabelPreferences)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureCheck := opts.RelaySignatureCheck
+	if signatureCheck == "" {
+		signatureCheck = types.SignatureCheckVerify
+	}
+	if !slices.Contains(types.SignatureChecks, signatureCheck) {
+		return nil, fmt.Errorf("%w: %s", errUnknownSignatureCheck, signatureCheck)
+	}
+
+	slotTimeSec := opts.SlotTimeSec
+	if slotTimeSec == 0 {
+		slotTimeSec = config.SlotTimeSec
+	}
+
 	builderSigningDomain, err := ComputeDomain(ssz.DomainTypeAppBuilder, opts.GenesisForkVersionHex, phase0.Root{}.String())
 	if err != nil {
 		return nil, err
 	}
 
-	return &BoostService{
+	bidAnomalyAction := opts.BidAnomalyAction
+	if bidAnomalyAction == "" {
+		bidAnomalyAction = BidAnomalyReject
+	}
+	if !slices.Contains(BidAnomalyActions, bidAnomalyAction) {
+		return nil, fmt.Errorf("%w: %s", errUnknownBidAnomalyAction, bidAnomalyAction)
+	}
+
+	gasLimitCheck := opts.GasLimitCheck
+	if gasLimitCheck == "" {
+		gasLimitCheck = GasLimitCheckWarn
+	}
+	if !slices.Contains(GasLimitChecks, gasLimitCheck) {
+		return nil, fmt.Errorf("%w: %s", errUnknownGasLimitCheck, gasLimitCheck)
+	}
+
+	payloadCheck := opts.PayloadCheck
+	if payloadCheck == "" {
+		payloadCheck = PayloadCheckWarn
+	}
+	if !slices.Contains(PayloadChecks, payloadCheck) {
+		return nil, fmt.Errorf("%w: %s", errUnknownPayloadCheck, payloadCheck)
+	}
+
+	var bidValues *bidValueHistory
+	if opts.RelayMinBidPercent > 0 || opts.MaxBidMedianMultiple > 0 {
+		if opts.RelayMinBidSlots <= 0 {
+			return nil, errInvalidMinBidSlots
+		}
+		bidValues = newBidValueHistory(opts.RelayMinBidSlots)
+	}
+
+	var auditLog *bidAuditLog
+	if opts.BidAuditLog != nil {
+		auditLog = newBidAuditLog(opts.BidAuditLog, opts.Log)
+	}
+
+	var metricsSink MetricsSink = nopMetricsSink{}
+	if opts.MetricsSink != nil {
+		metricsSink = opts.MetricsSink
+	}
+
+	var breaker *circuitBreaker
+	if opts.CircuitBreakerFailures > 0 {
+		breaker = newCircuitBreaker(opts.CircuitBreakerFailures, opts.CircuitBreakerCooldown, metricsSink)
+	}
+
+	var quarantine *relayQuarantine
+	if opts.QuarantineFaults > 0 {
+		epoch := time.Duration(32*slotTimeSec) * time.Second
+		quarantine = newRelayQuarantine(opts.QuarantineFaults, time.Duration(opts.QuarantineEpochs)*epoch)
+	}
+
+	var policy *bidPolicy
+	if opts.BidPolicyURL != nil {
+		policy = newBidPolicy(opts.BidPolicyURL.String(), opts.BidPolicyTimeout, opts.BidPolicyFailClosed)
+	}
+
+	var timeouts *adaptiveTimeouts
+	if opts.AdaptiveTimeoutMax > 0 {
+		if opts.AdaptiveTimeoutMin > opts.AdaptiveTimeoutMax {
+			return nil, errInvalidAdaptiveTimeouts
+		}
+		timeouts = newAdaptiveTimeouts(opts.AdaptiveTimeoutMin, opts.AdaptiveTimeoutMax)
+	}
+
+	if opts.PublishBlockFallback && opts.BeaconNodeURL == nil {
+		return nil, errPublishBlockBeaconNode
+	}
+
+	transportSettings := relayTransportSettings{
+		disableHTTP2:        opts.RelayDisableHTTP2,
+		maxIdleConnsPerHost: opts.RelayMaxIdleConnsPerHost,
+		idleConnTimeout:     opts.RelayIdleConnTimeout,
+		keepAlive:           opts.RelayKeepAlive,
+	}
+	relayTransports, err := newRelayTransports(opts.RelayClientCert, opts.RelayClientKey, transportSettings, opts.Relays)
+	if err != nil {
+		return nil, err
+	}
+	relayTransports.hosts = relayHosts
+
+	var errorReporter ErrorReporter = nopErrorReporter{}
+	if opts.ErrorReporter != nil {
+		errorReporter = opts.ErrorReporter
+	}
+
+	var webhooks *webhookNotifier
+	if len(opts.Webhooks) > 0 {
+		webhooks = newWebhookNotifier(opts.Log, opts.Webhooks, opts.WebhookTimeout)
+	}
+
+	m := &BoostService{
 		listenAddr:    opts.ListenAddr,
+		metricsAddr:   opts.MetricsAddr,
+		pprofAddr:     opts.PprofAddr,
+		grpcAddr:      opts.GRPCAddr,
 		relays:        opts.Relays,
 		relayMonitors: opts.RelayMonitors,
 		log:           opts.Log,
 		relayCheck:    opts.RelayCheck,
 		relayMinBid:   opts.RelayMinBid,
 		genesisTime:   opts.GenesisTime,
+		slotTimeSec:   slotTimeSec,
+		fuluForkEpoch: opts.FuluForkEpoch,
+		legacyForks:   opts.LegacyForks,
+		tiebreaker:    tiebreak,
+
+		labelPreferences: preferences,
+		signatureCheck:   signatureCheck,
+
+		relayMinBidPercent: opts.RelayMinBidPercent,
+		bidValues:          bidValues,
+
+		localBlockValueDelta: opts.LocalBlockValueDelta,
+		maxBid:               opts.MaxBid,
+		maxBidMedianMultiple: opts.MaxBidMedianMultiple,
+		bidAnomalyAction:     bidAnomalyAction,
+
+		gasLimitCheck: gasLimitCheck,
+		payloadCheck:  payloadCheck,
+		registrations: newValidatorRegistrations(),
+		constraints:   newSlotConstraints(),
+		bidPolicy:     policy,
+		bidScript:     opts.BidScript,
+		bidPlugin:     opts.BidPlugin,
+		bidSelector:   opts.BidSelector,
+
+		registrationCache:      newRegistrationCache(opts.RegValResendInterval),
+		regValChunkSize:        opts.RegValChunkSize,
+		regValChunkRetries:     opts.RegValChunkRetries,
+		regValVerifySignatures: opts.RegValVerifySignatures,
+
+		registrationSpreader: newRegistrationSpreader(opts.RegValSpreadWindow),
+
 		bids:          make(map[string]bidResp),
+		metricsSink:   metricsSink,
+		errorReporter: errorReporter,
+		relayStats:    newRelayStatsStore(metricsSink),
+		relayAuctions: newRelayAuctionStore(),
+		relayHealth:   newRelayHealthStore(),
+		breaker:       breaker,
+		quarantine:    quarantine,
+		bidAuditLog:   auditLog,
+		bidStore:      opts.BidStore,
+		webhooks:      webhooks,
+		events:        newEventBroker(),
+		eventsToken:   opts.EventsToken,
+		adminToken:    opts.AdminToken,
+		metricsToken:  opts.MetricsToken,
+		reloadConfig:  opts.ReloadConfig,
 		slotUID:       &slotUID{},
 
+		clientAllowlist: opts.ClientAllowlist,
+		clientDenylist:  opts.ClientDenylist,
+
+		additionalListenAddrs: opts.AdditionalListenAddrs,
+		bodyLimits:            bodyLimits{registerValidator: opts.RegisterValidatorMaxBytes, getPayload: opts.GetPayloadMaxBytes},
+		relayHosts:            relayHosts,
+		prewarmLead:           opts.RelayPrewarmLead,
+		rateLimiter:           newRateLimiter(opts.RateLimitPerIP, opts.RateLimitGlobal, opts.LoadShedding),
+
+		validatorRoutes: opts.ValidatorRoutes,
+		relayFilters:    opts.RelayFilters,
+
+		relayCapabilities: newRelayCapabilityStore(),
+		relaySSZ:          newRelaySSZStore(),
+
+		relaySources:       relaySources,
+		remoteRelaySources: make(map[string][]types.RelayEntry),
+		adminRelays:        newAdminRelays(),
+
+		readyMinRelays:   opts.ReadyMinRelays,
+		readyRelayMaxAge: opts.ReadyRelayMaxAge,
+
+		reputationFile:    opts.ReputationFile,
+		registrationsFile: opts.RegistrationsFile,
+
 		builderSigningDomain: builderSigningDomain,
 		httpClientGetHeader: http.Client{
 			Timeout:       opts.RequestTimeoutGetHeader,
@@ -127,19 +697,61 @@ func NewBoostService(opts BoostServiceOpts) (*BoostService, error) {
 		httpClientGetPayload: http.Client{
 			Timeout:       opts.RequestTimeoutGetPayload,
 			CheckRedirect: httpClientDisallowRedirects,
+			Transport:     newCompressionTransport(opts.RequestCompressionMinSize),
 		},
 		httpClientRegVal: http.Client{
 			Timeout:       opts.RequestTimeoutRegVal,
 			CheckRedirect: httpClientDisallowRedirects,
+			Transport:     newCompressionTransport(opts.RequestCompressionMinSize),
 		},
-		requestMaxRetries: opts.RequestMaxRetries,
-	}, nil
+		relayTransports:           relayTransports,
+		requestMaxRetries:         opts.RequestMaxRetries,
+		requestRetryBackoff:       cmp.Or(opts.RequestRetryBackoff, defaultRetryBackoff),
+		requestRetryJitter:        opts.RequestRetryJitter,
+		adaptiveTimeouts:          timeouts,
+		getHeaderMaxIntoSlot:      opts.GetHeaderMaxIntoSlot,
+		getHeaderSoftDeadline:     opts.GetHeaderSoftDeadline,
+		getHeaderStaggerDelay:     opts.GetHeaderStaggerDelay,
+		backupRelayWindow:         opts.BackupRelayWindow,
+		getHeaderPollInterval:     opts.GetHeaderPollInterval,
+		getHeaderPollBudget:       opts.GetHeaderPollBudget,
+		getHeaderRetryDelay:       opts.GetHeaderRetryDelay,
+		payloadDeliveryCheckDelay: opts.PayloadDeliveryCheckDelay,
+		beaconNodeURL:             opts.BeaconNodeURL,
+		publishBlockFallback:      opts.PublishBlockFallback,
+		executionNodeURL:          opts.ExecutionNodeURL,
+		getPayloadFallbackDelay:   opts.GetPayloadFallbackDelay,
+		getPayloadHedgeDelay:      opts.GetPayloadHedgeDelay,
+		payloadCache:              newPayloadCache(opts.GetPayloadCacheTTL),
+		getPayloadStrict:          opts.GetPayloadStrict,
+	}
+	if m.reputationFile != "" {
+		if err := m.loadReputation(); err != nil {
+			return nil, fmt.Errorf("could not load the relay reputation: %w", err)
+		}
+	}
+	if m.registrationsFile != "" {
+		if err := m.loadRegistrations(); err != nil {
+			return nil, fmt.Errorf("could not load the validator registrations: %w", err)
+		}
+	}
+	if opts.RevealJournalFile != "" {
+		if m.revealJournal, err = loadRevealJournal(opts.RevealJournalFile); err != nil {
+			return nil, fmt.Errorf("could not load the reveal journal: %w", err)
+		}
+	}
+	return m, nil
 }
 
 func (m *BoostService) respondError(w http.ResponseWriter, code int, message string) {
+	m.respondRelayFailures(w, code, message, nil)
+}
+
+// respondRelayFailures responds with the error, and why each relay failed the request
+func (m *BoostService) respondRelayFailures(w http.ResponseWriter, code int, message string, failures []relayFailure) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
-	resp := httpErrorResp{code, message}
+	resp := httpErrorResp{Code: code, Message: message, Failures: failures}
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		m.log.WithField("response", resp).WithError(err).Error("could not write error response")
 		http.Error(w, "", http.StatusInternalServerError)
@@ -157,27 +769,81 @@ func (m *BoostService) respondOK(w http.ResponseWriter, response any) {
 
 func (m *BoostService) getRouter() http.Handler {
 	r := mux.NewRouter()
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		m.respondError(w, http.StatusNotFound, errRouteNotFound.Error())
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		m.respondError(w, http.StatusMethodNotAllowed, errMethodNotAllowed.Error())
+	})
 	r.HandleFunc("/", m.handleRoot)
 
+	r.Handle(params.PathStatus, m.requireToken(m.metricsToken, http.HandlerFunc(m.handleStatus))).Methods(http.MethodGet).MatcherFunc(verboseStatusRequest)
 	r.HandleFunc(params.PathStatus, m.handleStatus).Methods(http.MethodGet)
-	r.HandleFunc(params.PathRegisterValidator, m.handleRegisterValidator).Methods(http.MethodPost)
-	r.HandleFunc(params.PathGetHeader, m.handleGetHeader).Methods(http.MethodGet)
-	r.HandleFunc(params.PathGetPayload, m.handleGetPayload).Methods(http.MethodPost)
+	r.HandleFunc(params.PathRegisterValidator, m.limitBody(m.bodyLimits.registerValidatorLimit, m.handleRegisterValidator)).Methods(http.MethodPost)
+	r.HandleFunc(params.PathGetHeader, m.trackGetHeader(m.handleGetHeader)).Methods(http.MethodGet)
+	r.HandleFunc(params.PathGetPayload, m.trackGetPayload(m.limitBody(m.bodyLimits.getPayloadLimit, m.handleGetPayload))).Methods(http.MethodPost)
+	r.HandleFunc(params.PathConstraints, m.handleSubmitConstraints).Methods(http.MethodPost)
+	r.HandleFunc(params.PathConstraintsDelegate, m.handleConstraintsDelegation).Methods(http.MethodPost)
+	r.HandleFunc(params.PathConstraintsRevoke, m.handleConstraintsDelegation).Methods(http.MethodPost)
+	r.HandleFunc(params.PathLivez, m.handleLivez).Methods(http.MethodGet)
+	r.HandleFunc(params.PathReadyz, m.handleReadyz).Methods(http.MethodGet)
+	r.Handle(params.PathRelayStats, m.requireToken(m.metricsToken, http.HandlerFunc(m.handleRelayStats))).Methods(http.MethodGet)
+	r.Handle(params.PathRelayScores, m.requireToken(m.metricsToken, http.HandlerFunc(m.handleRelayScores))).Methods(http.MethodGet)
+	r.Handle(params.PathValidatorRegistration, m.requireToken(m.metricsToken, http.HandlerFunc(m.handleValidatorRegistration))).Methods(http.MethodGet)
+	if m.bidStore != nil {
+		r.Handle(params.PathBidHistory, m.requireToken(m.metricsToken, http.HandlerFunc(m.handleBidHistory))).Methods(http.MethodGet)
+		r.Handle(params.PathPayloadHistory, m.requireToken(m.metricsToken, http.HandlerFunc(m.handlePayloadHistory))).Methods(http.MethodGet)
+	}
+	if m.adminToken != "" {
+		r.HandleFunc(params.PathAdminReload, m.handleAdminReload).Methods(http.MethodPost)
+		r.HandleFunc(params.PathAdminRelays, m.handleAdminRelays).Methods(http.MethodGet)
+		r.HandleFunc(params.PathAdminRelays, m.handleAdminAddRelay).Methods(http.MethodPost)
+		r.HandleFunc(params.PathAdminRelay, m.handleAdminRemoveRelay).Methods(http.MethodDelete)
+		r.HandleFunc(params.PathAdminRelayPause, m.handleAdminPauseRelay).Methods(http.MethodPost)
+		r.HandleFunc(params.PathAdminRelayResume, m.handleAdminResumeRelay).Methods(http.MethodPost)
+	}
 
 	r.Use(mux.CORSMethodMiddleware(r))
+	r.Use(m.reportPanics)
+	r.Use(m.limitRequests)
 	loggedRouter := httplogger.LoggingMiddlewareLogrus(m.log, r)
-	return loggedRouter
+	if m.eventsToken == "" {
+		return m.filterClients(loggedRouter)
+	}
+
+	// The event streams need to flush and hijack the connection, which the logging middleware doesn't support
+	root := mux.NewRouter()
+	root.HandleFunc(params.PathEvents, m.handleEvents).Methods(http.MethodGet)
+	root.HandleFunc(params.PathBidStream, m.handleBidStream).Methods(http.MethodGet)
+	root.PathPrefix("/").Handler(loggedRouter)
+	return m.filterClients(root)
 }
 
 // StartHTTPServer starts the HTTP server for this boost service instance
 func (m *BoostService) StartHTTPServer() error {
-	if m.srv != nil {
-		return errServerAlreadyRunning
+	m.serversLock.Lock()
+	if err := m.checkServerStart(m.srv != nil); err != nil {
+		m.serversLock.Unlock()
+		return err
 	}
 
 	go m.startBidCacheCleanupTask()
+	go m.startRelayCapabilityCheck()
+	go m.startRelayHostsRefreshTask()
+	if m.prewarmLead > 0 && m.genesisTime > 0 {
+		go m.startRelayPrewarmTask()
+	}
+	if m.reputationFile != "" {
+		go m.startReputationSaveTask()
+	}
+	if m.registrationsFile != "" {
+		go m.startRegistrationsSaveTask()
+	}
+	if m.registrationSpreader != nil {
+		go m.startRegistrationSpreadTask()
+	}
 
-	m.srv = &http.Server{
+	srv := &http.Server{
 		Addr:    m.listenAddr,
 		Handler: m.getRouter(),
 
@@ -188,11 +854,28 @@ func (m *BoostService) StartHTTPServer() error {
 
 		MaxHeaderBytes: config.ServerMaxHeaderBytes,
 	}
+	m.srv = srv
+	m.serversLock.Unlock()
 
-	err := m.srv.ListenAndServe()
+	listeners, err := listenHTTPAll(append([]string{m.listenAddr}, m.additionalListenAddrs...))
+	if err != nil {
+		return err
+	}
+	m.notifySystemd(sdNotifyReady)
+	if interval := watchdogInterval(); interval > 0 {
+		go m.startWatchdog(interval)
+	}
+
+	// All listen addresses are served by the same server, the first failure stops it
+	errc := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func() { errc <- srv.Serve(listener) }()
+	}
+	err = <-errc
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
+	_ = srv.Close()
 	return err
 }
 
@@ -215,7 +898,10 @@ func (m *BoostService) sendValidatorRegistrationsToRelayMonitors(payload []build
 		go func(relayMonitor *url.URL) {
 			url := types.GetURI(relayMonitor, params.PathRegisterValidator)
 			log = log.WithField("url", url)
-			_, err := SendHTTPRequest(context.Background(), m.httpClientRegVal, http.MethodPost, url, "", nil, payload, nil)
+			// Relay monitors get uncompressed requests
+			client := m.currentConfig().httpClientRegVal
+			client.Transport = nil
+			_, err := SendHTTPRequest(context.Background(), client, http.MethodPost, url, "", nil, payload, nil)
 			if err != nil {
 				log.WithError(err).Warn("error calling registerValidator on relay monitor")
 				return
@@ -225,15 +911,64 @@ func (m *BoostService) sendValidatorRegistrationsToRelayMonitors(payload []build
 	}
 }
 
+// slotUIDString returns the uid of the slot, or an empty string if the slot is not the latest one
+func (m *BoostService) slotUIDString(slot phase0.Slot) string {
+	m.slotUIDLock.Lock()
+	defer m.slotUIDLock.Unlock()
+	if m.slotUID.slot != slot {
+		return ""
+	}
+	return m.slotUID.uid.String()
+}
+
 func (m *BoostService) handleRoot(w http.ResponseWriter, _ *http.Request) {
 	m.respondOK(w, nilResponse)
 }
 
+// verboseStatusRequest matches status requests for the relay details, which are protected by the metrics token unlike
+// the status check of the beacon node
+func verboseStatusRequest(req *http.Request, _ *mux.RouteMatch) bool {
+	verbose, _ := strconv.ParseBool(req.URL.Query().Get("verbose"))
+	return verbose
+}
+
 // handleStatus sends calls to the status endpoint of every relay.
 // It returns OK if at least one returned OK, and returns error otherwise.
-func (m *BoostService) handleStatus(w http.ResponseWriter, _ *http.Request) {
+// With verbose=true, the response body contains the recent health of every relay.
+func (m *BoostService) handleStatus(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set(HeaderKeyVersion, config.Version)
-	if !m.relayCheck || m.CheckRelays() > 0 {
+	ok := !m.relayCheck || m.CheckRelays() > 0
+
+	if verboseStatusRequest(req, nil) {
+		code := http.StatusOK
+		if !ok {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		relays := m.currentConfig().relays
+		resp := StatusResponse{Relays: m.relayHealth.snapshot(relays)}
+		for i, relay := range relays {
+			if m.breaker != nil {
+				resp.Relays[i].Circuit = m.breaker.state(relay)
+			}
+			if m.adaptiveTimeouts != nil {
+				resp.Relays[i].setAdaptiveTimeout(m.adaptiveTimeouts, relay)
+			}
+			resp.Relays[i].setConsensusVersions(m.relayCapabilities, relay)
+			if m.quarantine != nil {
+				if until, ok := m.quarantine.quarantinedUntil(relay); ok {
+					resp.Relays[i].QuarantinedUntilMs = until.UnixMilli()
+				}
+			}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			m.log.WithError(err).Error("could not write status response")
+		}
+		return
+	}
+
+	if ok {
 		m.respondOK(w, nilResponse)
 	} else {
 		m.respondError(w, http.StatusServiceUnavailable, "all relays are unavailable")
@@ -245,9 +980,22 @@ func (m *BoostService) handleRegisterValidator(w http.ResponseWriter, req *http.
 	log := m.log.WithField("method", "registerValidator")
 	log.Debug("registerValidator")
 
+	ctx, span := startSpan(req.Context(), "registerValidator")
+	defer span.End()
+	ctx = context.WithoutCancel(ctx)
+
 	payload := []builderApiV1.SignedValidatorRegistration{}
-	if err := DecodeJSON(req.Body, &payload); err != nil {
-		m.respondError(w, http.StatusBadRequest, err.Error())
+	if isSSZRequest(req) {
+		body, err := io.ReadAll(req.Body)
+		if err == nil {
+			payload, err = decodeRegistrationsSSZ(body)
+		}
+		if err != nil {
+			m.respondBodyError(w, err)
+			return
+		}
+	} else if err := DecodeJSON(req.Body, &payload); err != nil {
+		m.respondBodyError(w, err)
 		return
 	}
 
@@ -256,38 +1004,85 @@ func (m *BoostService) handleRegisterValidator(w http.ResponseWriter, req *http.
 		"numRegistrations": len(payload),
 		"ua":               ua,
 	})
+	span.SetAttributes(attribute.Int("numRegistrations", len(payload)))
+
+	// The relays would reject the batch, name the validators with invalid signature instead
+	if m.regValVerifySignatures {
+		if invalid := invalidRegistrationSignatures(payload, m.builderSigningDomain); len(invalid) > 0 {
+			err := registrationSignatureError(invalid)
+			log.WithError(err).Warn("rejected validator registrations with invalid signature")
+			m.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	m.registrations.record(payload)
+
+	// Buffered registrations are forwarded later, relay errors aren't reported to the beacon node
+	if m.registrationSpreader != nil {
+		m.registrationSpreader.add(payload, time.Now())
+		go m.sendValidatorRegistrationsToRelayMonitors(payload)
+		m.respondOK(w, nilResponse)
+		return
+	}
 
 	// Add request headers
 	headers := map[string]string{
 		HeaderStartTimeUnixMS: fmt.Sprintf("%d", time.Now().UTC().UnixMilli()),
 	}
 
-	relayRespCh := make(chan error, len(m.relays))
+	// Each relay receives the registrations of the validators routed to it
+	cfg := m.currentConfig()
+	relayPayloads := cfg.registrationsByRelay(payload)
+	type relayResp struct {
+		relay   types.RelayEntry
+		code    int
+		elapsed time.Duration
+		err     error
+	}
+	relayRespCh := make(chan relayResp, len(relayPayloads))
 
-	for _, relay := range m.relays {
-		go func(relay types.RelayEntry) {
+	for _, relayPayload := range relayPayloads {
+		go func(relay types.RelayEntry, registrations []builderApiV1.SignedValidatorRegistration) {
 			url := relay.GetURI(params.PathRegisterValidator)
-			log := log.WithField("url", url)
+			log := log.WithFields(logrus.Fields{
+				"url":                   url,
+				"numRelayRegistrations": len(registrations),
+			})
 
-			_, err := SendHTTPRequest(context.Background(), m.httpClientRegVal, http.MethodPost, url, ua, headers, payload, nil)
-			if err != nil {
-				log.WithError(err).Warn("error calling registerValidator on relay")
+			// Unchanged registrations are only forwarded again after the resend interval
+			start := time.Now()
+			if m.registrationCache != nil {
+				registrations = m.registrationCache.changed(relay, registrations, start)
+				if len(registrations) == 0 {
+					log.Debug("no changed registrations for the relay")
+					relayRespCh <- relayResp{relay: relay}
+					return
+				}
 			}
-			relayRespCh <- err
-		}(relay)
+
+			code, err := m.sendRegistrations(ctx, cfg.regValClient(relay), relay, ua, headers, registrations, log)
+			relayRespCh <- relayResp{relay, code, time.Since(start), err}
+		}(relayPayload.relay, relayPayload.registrations)
 	}
 
 	go m.sendValidatorRegistrationsToRelayMonitors(payload)
 
-	for i := 0; i < len(m.relays); i++ {
-		respErr := <-relayRespCh
-		if respErr == nil {
+	failures := make([]relayFailure, 0, len(relayPayloads))
+	for i := 0; i < len(relayPayloads); i++ {
+		resp := <-relayRespCh
+		if resp.err == nil {
 			m.respondOK(w, nilResponse)
 			return
 		}
+		failures = append(failures, relayFailure{
+			Relay:      resp.relay.GetURI(""),
+			Message:    resp.err.Error(),
+			Class:      string(classifyRelayError(resp.code, resp.err)),
+			DurationMs: resp.elapsed.Milliseconds(),
+		})
 	}
 
-	m.respondError(w, http.StatusBadGateway, errNoSuccessfulRelayResponse.Error())
+	m.respondRelayFailures(w, http.StatusBadGateway, errNoSuccessfulRelayResponse.Error(), sortRelayFailures(failures))
 }
 
 // handleGetHeader requests bids from the relays
@@ -315,15 +1110,64 @@ func (m *BoostService) handleGetHeader(w http.ResponseWriter, req *http.Request)
 	})
 	log.Debug("getHeader")
 
+	// Relay requests are not cancelled if the beacon node disconnects, only the trace is propagated
+	ctx, span := startSpan(req.Context(), "getHeader", trace.WithAttributes(
+		attribute.Int64("slot", int64(slot)),
+		attribute.String("parentHash", parentHashHex),
+		attribute.String("pubkey", pubkey),
+	))
+	defer span.End()
+
+	// The beacon node might send the value of its local payload, the bid must beat it
+	var localValue *uint256.Int
+	if header := req.Header.Get(HeaderLocalBlockValue); header != "" {
+		if localValue, err = parseLocalBlockValue(header); err != nil {
+			m.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	// Query the relays for the header
-	result, err := m.getHeader(log, ua, slot, pubkey, parentHashHex)
+	result, err := m.getHeader(context.WithoutCancel(ctx), log, ua, slot, pubkey, parentHashHex)
+	if err == nil && result.response.IsEmpty() && m.retryGetHeader(slot) {
+		log.WithField("delay", m.getHeaderRetryDelay.String()).Info("no bid received, asking the relays again")
+		time.Sleep(m.getHeaderRetryDelay)
+		result, err = m.getHeader(context.WithoutCancel(ctx), log, ua, slot, pubkey, parentHashHex)
+	}
+	if errors.Is(err, errGetHeaderTooLate) {
+		log.WithField("maxMsIntoSlot", m.getHeaderMaxIntoSlot.Milliseconds()).Warn("getHeader request too late into the slot, not requesting bids")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, errGetHeaderPastSlot) || errors.Is(err, errGetHeaderFutureSlot) {
+		log.WithError(err).Warn("getHeader request for a slot which isn't the current one, not requesting bids")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	if err != nil {
 		m.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	if localValue != nil && !result.response.IsEmpty() && !m.beatsLocalBlock(result, localValue) {
+		log.WithFields(logrus.Fields{
+			"value":      result.bidInfo.value.Dec(),
+			"localValue": localValue.Dec(),
+			"delta":      m.localBlockValueDelta.String(),
+		}).Info("best bid doesn't beat the local block value, not returning it")
+		result = bidResp{}
+	}
+
 	if result.response.IsEmpty() {
 		log.Info("no bid received")
+		m.emitEvent(Event{
+			Type:       EventNoBids,
+			Slot:       uint64(slot),
+			SlotUID:    m.slotUIDString(slot),
+			ParentHash: parentHashHex,
+			Pubkey:     pubkey,
+		})
+		w.Header().Set(HeaderKeyRelaysResponded, strconv.Itoa(result.responded))
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
@@ -332,6 +1176,21 @@ func (m *BoostService) handleGetHeader(w http.ResponseWriter, req *http.Request)
 	m.bidsLock.Lock()
 	m.bids[bidKey(slot, result.bidInfo.blockHash)] = result
 	m.bidsLock.Unlock()
+	m.shutdown.bidReturned()
+
+	for _, relay := range result.relays {
+		m.relayStats.record(relay, relayStatsBidWon)
+	}
+	m.emitEvent(Event{
+		Type:       EventBidSelected,
+		Slot:       uint64(slot),
+		SlotUID:    m.slotUIDString(slot),
+		ParentHash: parentHashHex,
+		Pubkey:     pubkey,
+		BlockHash:  result.bidInfo.blockHash.String(),
+		Value:      result.bidInfo.value.Dec(),
+		Relays:     relayURIs(result.relays),
+	})
 
 	// Log result
 	valueEth := weiBigIntToEthBigFloat(result.bidInfo.value.ToBig())
@@ -343,20 +1202,50 @@ func (m *BoostService) handleGetHeader(w http.ResponseWriter, req *http.Request)
 		"relays":      strings.Join(types.RelayEntriesToStrings(result.relays), ", "),
 	}).Info("best bid")
 
-	// Return the bid
+	// Return the bid, with the auction outcome in the headers
+	w.Header().Set(HeaderKeyRelay, strings.Join(relayURIs(result.relays), ","))
+	w.Header().Set(HeaderKeyBidValueWei, result.bidInfo.value.Dec())
+	w.Header().Set(HeaderKeyRelaysResponded, strconv.Itoa(result.responded))
+	if acceptsSSZ(req) {
+		encoded, err := bidSSZ(&result.response)
+		m.respondSSZ(w, result.response.Version, encoded, err)
+		return
+	}
+	w.Header().Set(HeaderEthConsensusVersion, result.response.Version.String())
 	m.respondOK(w, &result.response)
 }
 
 // respondPayload responds to the proposer with the payload
-func (m *BoostService) respondPayload(w http.ResponseWriter, log *logrus.Entry, result *builderApi.VersionedSubmitBlindedBlockResponse, originalBid bidResp) {
+func (m *BoostService) respondPayload(w http.ResponseWriter, log *logrus.Entry, result deliveredPayload, originalBid bidResp, sszResponse bool) {
+	switch {
+	case errors.Is(result.refused, errUnknownBid):
+		m.respondError(w, http.StatusBadRequest, result.refused.Error())
+		return
+	case errors.Is(result.refused, errEquivocation):
+		m.respondError(w, http.StatusConflict, result.refused.Error())
+		return
+	case result.refused != nil:
+		m.respondError(w, http.StatusInternalServerError, result.refused.Error())
+		return
+	}
 	// If no payload has been received from relay, log loudly about withholding!
-	if result == nil || getPayloadResponseIsEmpty(result) {
+	if result.response == nil || getPayloadResponseIsEmpty(result.response) {
 		originRelays := types.RelayEntriesToStrings(originalBid.relays)
 		log.WithField("relaysWithBid", strings.Join(originRelays, ", ")).Error("no payload received from relay!")
-		m.respondError(w, http.StatusBadGateway, errNoSuccessfulRelayResponse.Error())
+		m.respondRelayFailures(w, http.StatusBadGateway, errNoSuccessfulRelayResponse.Error(), result.failures)
 		return
 	}
-	m.respondOK(w, result)
+	w.Header().Set(HeaderKeyRelay, result.relay.GetURI(""))
+	if originalBid.bidInfo.value != nil {
+		w.Header().Set(HeaderKeyBidValueWei, originalBid.bidInfo.value.Dec())
+	}
+	if sszResponse {
+		encoded, err := payloadSSZ(result.response)
+		m.respondSSZ(w, result.response.Version, encoded, err)
+		return
+	}
+	w.Header().Set(HeaderEthConsensusVersion, result.response.Version.String())
+	m.respondOK(w, result.response)
 }
 
 // handleGetPayload requests the payload from the relays
@@ -364,75 +1253,118 @@ func (m *BoostService) handleGetPayload(w http.ResponseWriter, req *http.Request
 	log := m.log.WithField("method", "getPayload")
 	log.Debug("getPayload request starts")
 
+	ctx, span := startSpan(req.Context(), "getPayload")
+	defer span.End()
+	ctx = context.WithoutCancel(ctx)
+
 	// Read the body first, so we can log it later on error
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
 		log.WithError(err).Error("could not read body of request from the beacon node")
-		m.respondError(w, http.StatusBadRequest, err.Error())
+		m.respondBodyError(w, err)
 		return
 	}
 
 	// Read user agent for logging
 	userAgent := UserAgent(req.Header.Get("User-Agent"))
+	consensusVersion := strings.ToLower(req.Header.Get(HeaderEthConsensusVersion))
+
+	// SSZ encoded blocks can't be told apart by trying the decoders, the consensus version decides. Fulu blinded blocks
+	// are decoded as Electra blinded blocks.
+	sszRequest := isSSZRequest(req)
+	sszFork := consensusVersion
+	if sszFork == spec.DataVersionFulu.String() {
+		sszFork = spec.DataVersionElectra.String()
+	}
+	if sszRequest && sszFork == "" {
+		m.respondError(w, http.StatusBadRequest, errSSZConsensusVersion.Error())
+		return
+	}
 
 	// New forks need to be added at the front of this array.
 	// The ordering of the array conveys precedence of the decoders.
 	decoders := []struct {
 		fork      string
 		payload   any
-		processor func(payload any) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp)
+		processor func(payload any) (deliveredPayload, bidResp)
 	}{
 		{
 			fork:    "electra",
 			payload: new(eth2ApiV1Electra.SignedBlindedBeaconBlock),
-			processor: func(payload any) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp) {
+			processor: func(payload any) (deliveredPayload, bidResp) {
 				//nolint: forcetypeassert
-				return processPayload(m, log, userAgent, payload.(*eth2ApiV1Electra.SignedBlindedBeaconBlock))
+				block := payload.(*eth2ApiV1Electra.SignedBlindedBeaconBlock)
+				// Fulu blinded blocks have the same contents as Electra blinded blocks
+				if m.isFulu(block.Message.Slot, consensusVersion) {
+					return processFuluPayload(ctx, m, log, userAgent, block)
+				}
+				return processPayload(ctx, m, log, userAgent, block)
 			},
 		},
 		{
 			fork:    "deneb",
 			payload: new(eth2ApiV1Deneb.SignedBlindedBeaconBlock),
-			processor: func(payload any) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp) {
+			processor: func(payload any) (deliveredPayload, bidResp) {
 				//nolint: forcetypeassert
-				return processPayload(m, log, userAgent, payload.(*eth2ApiV1Deneb.SignedBlindedBeaconBlock))
+				return processPayload(ctx, m, log, userAgent, payload.(*eth2ApiV1Deneb.SignedBlindedBeaconBlock))
 			},
 		},
 		{
 			fork:    "capella",
 			payload: new(eth2ApiV1Capella.SignedBlindedBeaconBlock),
-			processor: func(payload any) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp) {
+			processor: func(payload any) (deliveredPayload, bidResp) {
 				//nolint: forcetypeassert
-				return processPayload(m, log, userAgent, payload.(*eth2ApiV1Capella.SignedBlindedBeaconBlock))
+				return processPayload(ctx, m, log, userAgent, payload.(*eth2ApiV1Capella.SignedBlindedBeaconBlock))
 			},
 		},
 		{
 			fork:    "bellatrix",
 			payload: new(eth2ApiV1Bellatrix.SignedBlindedBeaconBlock),
-			processor: func(payload any) (*builderApi.VersionedSubmitBlindedBlockResponse, bidResp) {
+			processor: func(payload any) (deliveredPayload, bidResp) {
 				//nolint: forcetypeassert
-				return processPayload(m, log, userAgent, payload.(*eth2ApiV1Bellatrix.SignedBlindedBeaconBlock))
+				return processPayload(ctx, m, log, userAgent, payload.(*eth2ApiV1Bellatrix.SignedBlindedBeaconBlock))
 			},
 		},
 	}
 
 	// Decode the body now
 	for _, decoder := range decoders {
-		payload := decoder.payload
-		// Try to decode the payload
-		log.Debugf("attempting to decode body into %v payload", decoder.fork)
-		if err := DecodeJSON(bytes.NewReader(body), payload); err != nil {
-			log.Debugf("could not decode %v request payload", decoder.fork)
+		if !m.legacyForks && isLegacyFork(decoder.fork) {
 			continue
 		}
+		payload := decoder.payload
+		if sszRequest {
+			if decoder.fork != sszFork {
+				continue
+			}
+			//nolint: forcetypeassert
+			if err = payload.(sszUnmarshaler).UnmarshalSSZ(body); err != nil {
+				log.WithError(err).Debugf("could not decode %v SSZ request payload", decoder.fork)
+				break
+			}
+		} else {
+			// Try to decode the payload
+			log.Debugf("attempting to decode body into %v payload", decoder.fork)
+			if err := DecodeJSON(bytes.NewReader(body), payload); err != nil {
+				log.Debugf("could not decode %v request payload", decoder.fork)
+				continue
+			}
+		}
 		// Decoding was successful, process the payload
 		result, originalBid := decoder.processor(payload)
-		m.respondPayload(w, log, result, originalBid)
+		rw := &responseWriteRecorder{ResponseWriter: w}
+		m.respondPayload(rw, log, result, originalBid, acceptsSSZ(req))
+		m.publishBlockIfUndelivered(log, req, rw, payload, result)
 		return
 	}
 
 	// No decoder was able to decode the body, log error
-	log.WithError(err).WithField("body", string(body)).Error("could not decode request payload from the beacon-node (signed blinded beacon block)")
+	if sszRequest {
+		log = log.WithField("consensusVersion", consensusVersion).WithField("bodyLength", len(body))
+	} else {
+		log = log.WithField("body", string(body))
+	}
+	log.WithError(err).Error("could not decode request payload from the beacon-node (signed blinded beacon block)")
 	m.respondError(w, http.StatusBadRequest, "could not decode body")
 }
 
@@ -441,7 +1373,8 @@ func (m *BoostService) CheckRelays() int {
 	var wg sync.WaitGroup
 	var numSuccessRequestsToRelay uint32
 
-	for _, r := range m.relays {
+	cfg := m.currentConfig()
+	for _, r := range cfg.relays {
 		wg.Add(1)
 
 		go func(relay types.RelayEntry) {
@@ -450,17 +1383,17 @@ func (m *BoostService) CheckRelays() int {
 			log := m.log.WithField("url", url)
 			log.Debug("checking relay status")
 
-			code, err := SendHTTPRequest(context.Background(), m.httpClientGetHeader, http.MethodGet, url, "", nil, nil, nil)
-			if err != nil {
-				log.WithError(err).Error("relay status error - request failed")
-				return
+			start := time.Now()
+			code, err := SendHTTPRequest(context.Background(), cfg.getHeaderClient(relay), http.MethodGet, url, "", nil, nil, nil)
+			if err == nil && code != http.StatusOK {
+				err = fmt.Errorf("%w: unexpected status code %d", errHTTPErrorResponse, code)
 			}
-			if code == http.StatusOK {
-				log.Debug("relay status OK")
-			} else {
-				log.Errorf("relay status error - unexpected status code %d", code)
+			m.recordRelayRequest(relay, "status", start, code, err)
+			if err != nil {
+				log.WithError(err).Error("relay status error")
 				return
 			}
+			log.Debug("relay status OK")
 
 			// Success: increase counter and cancel all pending requests to other relays
 			atomic.AddUint32(&numSuccessRequestsToRelay, 1)
@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -75,6 +77,30 @@ func newTestBackend(t *testing.T, numRelays int, relayTimeout time.Duration) *te
 	return &backend
 }
 
+// requireRelayFailures checks that the response is the 502 error response with the failures of the relays, and returns
+// the failures sorted by relay
+func requireRelayFailures(t *testing.T, rr *httptest.ResponseRecorder, relays ...*mock.Relay) []relayFailure {
+	t.Helper()
+	require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+	resp := new(httpErrorResp)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, http.StatusBadGateway, resp.Code)
+	require.Equal(t, errNoSuccessfulRelayResponse.Error(), resp.Message)
+
+	expected := make([]string, 0, len(relays))
+	for _, relay := range relays {
+		expected = append(expected, relay.RelayEntry.GetURI(""))
+	}
+	slices.Sort(expected)
+	failed := make([]string, 0, len(resp.Failures))
+	for _, failure := range resp.Failures {
+		require.NotEmpty(t, failure.Message)
+		failed = append(failed, failure.Relay)
+	}
+	require.Equal(t, expected, failed)
+	return resp.Failures
+}
+
 func (be *testBackend) request(t *testing.T, method, path string, payload any) *httptest.ResponseRecorder {
 	t.Helper()
 	var req *http.Request
@@ -190,6 +216,37 @@ func TestStatus(t *testing.T) {
 		require.NotEmpty(t, rr.Header().Get("X-MEVBoost-Version"))
 		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
 	})
+
+	t.Run("Verbose status with relay health", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.relays[1].Server.Close() // makes the second relay unavailable
+
+		rr := backend.request(t, http.MethodGet, "/eth/v1/builder/status?verbose=true", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		resp := StatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Relays, 2)
+		require.Equal(t, backend.relays[0].RelayEntry.GetURI(""), resp.Relays[0].Relay)
+		require.Positive(t, resp.Relays[0].LastSuccessMs)
+		require.Equal(t, 1, resp.Relays[0].RecentRequests)
+		require.Zero(t, resp.Relays[0].RecentErrorRate)
+		require.Positive(t, resp.Relays[0].LatencyMs)
+		require.Zero(t, resp.Relays[1].LastSuccessMs)
+		require.Equal(t, 1.0, resp.Relays[1].RecentErrorRate)
+		require.NotEmpty(t, resp.Relays[1].LastError)
+	})
+
+	t.Run("Verbose status if no relays available", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].Server.Close()
+
+		rr := backend.request(t, http.MethodGet, "/eth/v1/builder/status?verbose=true", nil)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		resp := StatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Relays, 1)
+	})
 }
 
 func TestRegisterValidator(t *testing.T) {
@@ -238,8 +295,8 @@ func TestRegisterValidator(t *testing.T) {
 			w.WriteHeader(http.StatusBadRequest)
 		})
 		rr = backend.request(t, http.MethodPost, path, payload)
-		require.JSONEq(t, `{"code":502,"message":"no successful relay response"}`+"\n", rr.Body.String())
-		require.Equal(t, http.StatusBadGateway, rr.Code)
+		failures := requireRelayFailures(t, rr, backend.relays...)
+		require.Equal(t, "HTTP error response: 400 / ", failures[0].Message)
 		require.Equal(t, 3, backend.relays[0].GetRequestCount(path))
 		require.Equal(t, 3, backend.relays[1].GetRequestCount(path))
 	})
@@ -252,10 +309,19 @@ func TestRegisterValidator(t *testing.T) {
 		// Now make the relay return slowly, mev-boost should return an error
 		backend.relays[0].ResponseDelay = 180 * time.Millisecond
 		rr = backend.request(t, http.MethodPost, path, payload)
-		require.JSONEq(t, `{"code":502,"message":"no successful relay response"}`+"\n", rr.Body.String())
-		require.Equal(t, http.StatusBadGateway, rr.Code)
+		requireRelayFailures(t, rr, backend.relays...)
 		require.Equal(t, 2, backend.relays[0].GetRequestCount(path))
 	})
+
+	t.Run("Per-relay timeout overrides the global timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 1, 150*time.Millisecond)
+		backend.relays[0].ResponseDelay = 180 * time.Millisecond
+		backend.boost.relays[0].TimeoutRegVal = time.Second
+
+		rr := backend.request(t, http.MethodPost, path, payload)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+	})
 }
 
 func getHeaderPath(slot uint64, parentHash phase0.Hash32, pubkey phase0.BLSPubKey) string {
@@ -276,6 +342,88 @@ func TestGetHeader(t *testing.T) {
 		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
 	})
 
+	t.Run("Per-relay timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 2, 100*time.Millisecond)
+		backend.relays[0].ResponseDelay = 150 * time.Millisecond
+		backend.relays[1].ResponseDelay = 150 * time.Millisecond
+		backend.boost.relays[0].TimeoutGetHeader = time.Second
+
+		// Only the relay with the longer timeout responds in time
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		stats := backend.boost.relayStats.snapshot(backend.boost.relays)
+		require.Equal(t, uint64(1), stats[0].BidsReceived)
+		require.Equal(t, uint64(0), stats[1].BidsReceived)
+	})
+
+	t.Run("Too late into the slot", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getHeaderMaxIntoSlot = 4 * time.Second
+
+		// Slot 1 started 5 seconds ago
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 12 - 5
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+
+		// Slot 1 started now
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 12
+		backend.relays[0].Timestamp = backend.boost.genesisTime + 12
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+	})
+
+	t.Run("Bids for other slots", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+
+		// Slot 1 ended a second ago
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 2*12 - 1
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+
+		// Slot 1 starts in 2 slots
+		backend.boost.genesisTime = uint64(time.Now().Unix()) + 12
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+
+		// The bid of the current slot has the wrong timestamp
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 12
+		backend.relays[0].Timestamp = backend.boost.genesisTime + 24
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+	})
+
+	t.Run("Soft deadline returns the best bid so far", func(t *testing.T) {
+		backend := newTestBackend(t, 2, 3*time.Second)
+		backend.boost.getHeaderSoftDeadline = 1200 * time.Millisecond
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 12
+		backend.relays[0].Timestamp = backend.boost.genesisTime + 12
+		backend.relays[1].Timestamp = backend.boost.genesisTime + 12
+		backend.relays[1].ResponseDelay = 2500 * time.Millisecond
+		backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+			12346,
+			"0xa38385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+
+		// The higher bid of the slow relay arrives after the deadline
+		start := time.Now()
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Less(t, time.Since(start), 2*time.Second)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt(12345), value)
+	})
+
 	t.Run("Okay response from relay deneb", func(t *testing.T) {
 		backend := newTestBackend(t, 1, time.Second)
 		resp := backend.relays[0].MakeGetHeaderResponse(
@@ -360,6 +508,34 @@ func TestGetHeader(t *testing.T) {
 		require.Equal(t, http.StatusNoContent, rr.Code)
 	})
 
+	t.Run("Per-relay signature check", func(t *testing.T) {
+		for _, tc := range []struct {
+			global, relay string
+			code          int
+		}{
+			{types.SignatureCheckVerify, types.SignatureCheckWarn, http.StatusOK},
+			{types.SignatureCheckVerify, types.SignatureCheckSkip, http.StatusOK},
+			{types.SignatureCheckSkip, "", http.StatusOK},
+			{types.SignatureCheckSkip, types.SignatureCheckVerify, http.StatusNoContent},
+		} {
+			backend := newTestBackend(t, 1, time.Second)
+			backend.boost.signatureCheck = tc.global
+			backend.boost.relays[0].SignatureCheck = tc.relay
+
+			backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+				12345,
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				spec.DataVersionDeneb,
+			)
+			backend.relays[0].GetHeaderResponse.Deneb.Signature = phase0.BLSSignature{}
+
+			rr := backend.request(t, http.MethodGet, path, nil)
+			require.Equal(t, tc.code, rr.Code, "global %s, relay %s", tc.global, tc.relay)
+		}
+	})
+
 	t.Run("Invalid slot number", func(t *testing.T) {
 		// Number larger than uint64 creates parsing error
 		slot := fmt.Sprintf("%d0", uint64(math.MaxUint64))
@@ -510,6 +686,119 @@ func TestGetHeaderBids(t *testing.T) {
 		require.Equal(t, "0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", blockHash.String())
 	})
 
+	t.Run("Use header of relay with highest weight if same value", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			12345,
+			"0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+			12345,
+			"0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.boost.relays[1].Weight = 2
+
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// The higher weight wins over the lower block hash
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		blockHash, err := resp.BlockHash()
+		require.NoError(t, err)
+		require.Equal(t, "0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", blockHash.String())
+	})
+
+	t.Run("Use configured tiebreaker if same value", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+
+		// The first relay has the higher block hash, and the second relay responds slowly
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			12345,
+			"0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+			12345,
+			"0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.relays[1].ResponseDelay = 50 * time.Millisecond
+
+		for name, expectedBlockHash := range map[string]string{
+			TiebreakerBlockHash:  "0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			TiebreakerRelayOrder: "0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			TiebreakerLatency:    "0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+		} {
+			tiebreak, err := newTiebreaker(name)
+			require.NoError(t, err)
+			backend.boost.tiebreaker = tiebreak
+
+			rr := backend.request(t, http.MethodGet, path, nil)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			resp := new(builderSpec.VersionedSignedBuilderBid)
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+			blockHash, err := resp.BlockHash()
+			require.NoError(t, err)
+			require.Equal(t, expectedBlockHash, blockHash.String(), name)
+		}
+	})
+
+	t.Run("Use lower tier only without bids of higher tier", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+
+		// The lower tier relay has the higher bid
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			12345,
+			"0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.relays[1].GetHeaderResponse = backend.relays[1].MakeGetHeaderResponse(
+			12347,
+			"0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		backend.boost.relays[1].Tier = 1
+
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err := resp.Value()
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt(12345), value)
+
+		// Without a valid bid of the top tier, the lower tier bid is used
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			12344, // below min-bid
+			"0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		value, err = resp.Value()
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt(12347), value)
+	})
+
 	t.Run("Respect minimum bid cutoff", func(t *testing.T) {
 		// Create backend and register relay.
 		backend := newTestBackend(t, 1, time.Second)
@@ -635,8 +924,52 @@ func TestGetPayload(t *testing.T) {
 		rr = backend.request(t, http.MethodPost, path, payload)
 		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
 		require.Equal(t, 1, backend.relays[1].GetRequestCount(path))
-		require.JSONEq(t, `{"code":502,"message":"no successful relay response"}`+"\n", rr.Body.String())
+		requireRelayFailures(t, rr, backend.relays...)
+	})
+
+	t.Run("Alert on missed payload", func(t *testing.T) {
+		backend := newTestBackend(t, 2, time.Second)
+		backend.relays[0].GetPayloadResponse = &builderApi.VersionedSubmitBlindedBlockResponse{
+			Version: spec.DataVersionDeneb,
+			Deneb: &builderApiDeneb.ExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: &deneb.ExecutionPayload{
+					BaseFeePerGas: uint256.NewInt(0),
+				},
+				BlobsBundle: &builderApiDeneb.BlobsBundle{},
+			},
+		}
+		backend.relays[1].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		events := backend.boost.events.subscribe()
+
+		rr := backend.request(t, http.MethodPost, path, payload)
 		require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+
+		var event Event
+		for event = range events {
+			if event.Type == EventGetPayloadFailed {
+				break
+			}
+		}
+		require.Equal(t, severityCritical, event.Severity)
+		require.Equal(t, blockHash.String(), event.BlockHash)
+		require.Equal(t, map[string]string{
+			backend.relays[0].RelayEntry.GetURI(""): errEmptyPayload.Error(),
+			backend.relays[1].RelayEntry.GetURI(""): "max retries exceeded: HTTP error response: 400 / ",
+		}, event.RelayErrors)
+
+		// The error response tells how each relay failed
+		classes := make(map[string]string)
+		for _, failure := range requireRelayFailures(t, rr, backend.relays...) {
+			classes[failure.Relay] = failure.Class
+			require.GreaterOrEqual(t, failure.RequestedAfterMs, int64(0))
+			require.GreaterOrEqual(t, failure.DurationMs, int64(0))
+		}
+		require.Equal(t, map[string]string{
+			backend.relays[0].RelayEntry.GetURI(""): string(relayErrorPayloadMismatch),
+			backend.relays[1].RelayEntry.GetURI(""): string(relayErrorHTTP4xx),
+		}, classes)
 	})
 
 	t.Run("Retries on error from relay", func(t *testing.T) {
@@ -677,8 +1010,22 @@ func TestGetPayload(t *testing.T) {
 		})
 		rr := backend.request(t, http.MethodPost, path, payload)
 		require.Equal(t, 5, backend.relays[0].GetRequestCount(path))
-		require.JSONEq(t, `{"code":502,"message":"no successful relay response"}`+"\n", rr.Body.String())
-		require.Equal(t, http.StatusBadGateway, rr.Code, rr.Body.String())
+		failures := requireRelayFailures(t, rr, backend.relays...)
+		require.Contains(t, failures[0].Message, `{"code":500,"message":"internal server error"}`)
+	})
+
+	t.Run("Strict mode refuses blocks without a served header", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getPayloadStrict = true
+		rr := backend.request(t, http.MethodPost, path, payload)
+		require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		require.Contains(t, rr.Body.String(), errUnknownBid.Error())
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+
+		backend.boost.bids[bidKey(payload.Message.Slot, blockHash)] = bidResp{}
+		rr = backend.request(t, http.MethodPost, path, payload)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
 	})
 }
 
@@ -876,6 +1223,7 @@ func TestGetPayloadForks(t *testing.T) {
 			signedBlindedBeaconBlock := tt.signedBeaconBlock
 			require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
 			backend := newTestBackend(t, 1, time.Second)
+			backend.boost.legacyForks = true
 			// Prepare getPayload response
 			backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
 			// call getPayload, ensure it's only called on relay 0 (origin of the bid)
@@ -891,6 +1239,63 @@ func TestGetPayloadForks(t *testing.T) {
 	}
 }
 
+func TestGetHeaderPreDeneb(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+	for _, version := range []spec.DataVersion{spec.DataVersionBellatrix, spec.DataVersionCapella} {
+		t.Run(version.String(), func(t *testing.T) {
+			backend := newTestBackend(t, 1, time.Second)
+			backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(12345, hash.String(), hash.String(), pubkey, version)
+			rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+			require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+
+			backend.boost.legacyForks = true
+			rr = backend.request(t, http.MethodGet, getHeaderPath(1, hash, mock.HexToPubkey(pubkey)), nil)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			resp := new(builderSpec.VersionedSignedBuilderBid)
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+			require.Equal(t, version, resp.Version)
+		})
+	}
+}
+
+func TestGetPayloadLegacyForksDisabled(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-capella.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Capella.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	backend := newTestBackend(t, 1, time.Second)
+	backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+	rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	require.Equal(t, 0, backend.relays[0].GetRequestCount(params.PathGetPayload))
+}
+
+func TestVerifyPayload(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-electra.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	block := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &block))
+	response := blindedBlockToBlockResponse(block)
+	bid := builderSpec.VersionedSignedBuilderBid{}
+
+	require.NoError(t, verifyPayload(block, mock.TestLog, response, bid, PayloadCheckOff))
+
+	// The payload of a Fulu block has to be a Fulu payload
+	require.ErrorIs(t, verifyPayload(&fuluSignedBlindedBeaconBlock{block}, mock.TestLog, response, bid, PayloadCheckOff), errInvalidVersion)
+
+	wrongHash := blindedBlockToBlockResponse(block)
+	wrongHash.Electra.ExecutionPayload.BlockHash = phase0.Hash32{0x01}
+	require.ErrorIs(t, verifyPayload(block, mock.TestLog, wrongHash, bid, PayloadCheckOff), errInvalidBlockhash)
+
+	missingBlobs := blindedBlockToBlockResponse(block)
+	missingBlobs.Electra.BlobsBundle.Commitments = append(missingBlobs.Electra.BlobsBundle.Commitments, deneb.KZGCommitment{})
+	require.ErrorIs(t, verifyPayload(block, mock.TestLog, missingBlobs, bid, PayloadCheckOff), errInvalidKZGLength)
+}
+
 func TestGetPayloadToAllRelays(t *testing.T) {
 	// Load the signed blinded beacon block used for getPayload
 	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
@@ -925,3 +1330,293 @@ func TestGetPayloadToAllRelays(t *testing.T) {
 	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathGetPayload))
 	require.Equal(t, 1, backend.relays[1].GetRequestCount(params.PathGetPayload))
 }
+
+func TestGetPayloadFallback(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	// Only relay 0 delivered the bid
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.getPayloadFallbackDelay = time.Hour
+		backend.boost.requestMaxRetries = 1
+		key := bidKey(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash)
+		backend.boost.bids[key] = bidResp{
+			response: *backend.relays[0].MakeGetHeaderResponse(
+				12345,
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2",
+				"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				spec.DataVersionDeneb,
+			),
+			relays: []types.RelayEntry{backend.relays[0].RelayEntry},
+		}
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		backend.relays[1].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		return backend
+	}
+
+	t.Run("Only the relays of the bid are asked", func(t *testing.T) {
+		backend := setup(t)
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathGetPayload))
+		require.Equal(t, 0, backend.relays[1].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("The other relays are asked if the relays of the bid fail", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("The other relays are asked after the delay", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.getPayloadFallbackDelay = 50 * time.Millisecond
+		backend.relays[0].ResponseDelay = 500 * time.Millisecond
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("The other relays have their full timeout after the delay", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.getPayloadFallbackDelay = 300 * time.Millisecond
+		backend.relays[0].OverrideHandleGetPayload(func(_ http.ResponseWriter, req *http.Request) {
+			// The context is only cancelled on disconnects once the body is read
+			_, _ = io.Copy(io.Discard, req.Body)
+			<-req.Context().Done()
+		})
+		backend.relays[1].ResponseDelay = 800 * time.Millisecond
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(params.PathGetPayload))
+	})
+}
+
+func TestGetHeaderBackupRelays(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	path := getHeaderPath(1, hash, pubkey)
+
+	// Relay 1 is a backup relay
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 2, time.Second)
+		backend.boost.relays[1].Backup = true
+		return backend
+	}
+
+	t.Run("Not asked if a primary relay has a bid", func(t *testing.T) {
+		backend := setup(t)
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+		require.Equal(t, 0, backend.relays[1].GetRequestCount(path))
+	})
+
+	t.Run("Asked if no primary relay has a usable bid", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[0].GetHeaderResponse = backend.relays[0].MakeGetHeaderResponse(
+			100,
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+			spec.DataVersionDeneb,
+		)
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(path))
+	})
+
+	t.Run("Asked after the backup window", func(t *testing.T) {
+		backend := setup(t)
+		backend.boost.backupRelayWindow = 50 * time.Millisecond
+		backend.relays[0].ResponseDelay = 300 * time.Millisecond
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(path))
+	})
+}
+
+func TestGetHeaderStagger(t *testing.T) {
+	backend := newTestBackend(t, 3, time.Second)
+	relays := []types.RelayEntry{backend.relays[0].RelayEntry, backend.relays[1].RelayEntry, backend.relays[2].RelayEntry}
+	cfg := backend.boost.currentConfig()
+	require.Empty(t, backend.boost.getHeaderStagger(cfg, relays), "disabled")
+
+	// Relay 0 is slow, relay 1 fast and relay 2 has no latency yet
+	backend.boost.getHeaderStaggerDelay = 300 * time.Millisecond
+	backend.boost.relayHealth.record(relays[0], 200*time.Millisecond, nil)
+	backend.boost.relayHealth.record(relays[1], 10*time.Millisecond, nil)
+	require.Equal(t, map[string]time.Duration{
+		relays[2].String(): 0,
+		relays[1].String(): 300 * time.Millisecond,
+		relays[0].String(): 500 * time.Millisecond, // half of the timeout
+	}, backend.boost.getHeaderStagger(cfg, relays))
+
+	// All relays are asked
+	path := getHeaderPath(1, mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"))
+	rr := backend.request(t, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	for _, relay := range backend.relays {
+		require.Equal(t, 1, relay.GetRequestCount(path))
+	}
+}
+
+func TestGetHeaderRetry(t *testing.T) {
+	path := getHeaderPath(1, mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"))
+
+	t.Run("Retries once without a usable bid", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getHeaderRetryDelay = 10 * time.Millisecond
+
+		// The relay has no bid at the first request
+		requests := 0
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(backend.relays[0].MakeGetHeaderResponse(
+				12345,
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				"0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7",
+				"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+				spec.DataVersionDeneb,
+			)))
+		})
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(path))
+	})
+
+	t.Run("Retries only once", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getHeaderRetryDelay = 10 * time.Millisecond
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(path))
+	})
+
+	t.Run("No retry after the deadlines", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.getHeaderRetryDelay = 10 * time.Millisecond
+		require.True(t, backend.boost.retryGetHeader(1), "no genesis time")
+
+		// Slot 2 started a second ago
+		backend.boost.genesisTime = uint64(time.Now().Unix()) - 2*backend.boost.slotTimeSec - 1
+		backend.boost.getHeaderSoftDeadline = 3 * time.Second
+		require.True(t, backend.boost.retryGetHeader(2))
+		backend.boost.getHeaderSoftDeadline = 500 * time.Millisecond
+		require.False(t, backend.boost.retryGetHeader(2))
+
+		backend.boost.getHeaderSoftDeadline = 0
+		backend.boost.getHeaderMaxIntoSlot = 500 * time.Millisecond
+		require.False(t, backend.boost.retryGetHeader(2))
+	})
+}
+
+func TestGetHeaderLocalBlockValue(t *testing.T) {
+	path := getHeaderPath(1, mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"))
+	request := func(backend *testBackend, localValue string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		if localValue != "" {
+			req.Header.Set(HeaderLocalBlockValue, localValue)
+		}
+		rr := httptest.NewRecorder()
+		backend.boost.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The relays bid 12345 wei
+	backend := newTestBackend(t, 1, time.Second)
+	require.Equal(t, http.StatusOK, request(backend, "").Code)
+	require.Equal(t, http.StatusOK, request(backend, "12345").Code)
+	require.Equal(t, http.StatusNoContent, request(backend, "12346").Code)
+
+	backend.boost.localBlockValueDelta = types.IntToU256(45)
+	require.Equal(t, http.StatusOK, request(backend, "12300").Code)
+	require.Equal(t, http.StatusNoContent, request(backend, "12301").Code)
+
+	rr := request(backend, "0.1")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), errInvalidLocalBlockValue.Error())
+}
+
+func TestAuctionOutcomeHeaders(t *testing.T) {
+	t.Run("getHeader", func(t *testing.T) {
+		path := getHeaderPath(1, mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7"), mock.HexToPubkey(
+			"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"))
+
+		// Relay 0 bids, relay 1 has no bid and relay 2 is down
+		backend := newTestBackend(t, 3, time.Second)
+		backend.relays[1].OverrideHandleGetHeader(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		backend.relays[2].Server.Close()
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, backend.relays[0].RelayEntry.GetURI(""), rr.Header().Get(HeaderKeyRelay))
+		require.Equal(t, "12345", rr.Header().Get(HeaderKeyBidValueWei))
+		require.Equal(t, "2", rr.Header().Get(HeaderKeyRelaysResponded))
+
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Empty(t, rr.Header().Get(HeaderKeyRelay))
+		require.Equal(t, "2", rr.Header().Get(HeaderKeyRelaysResponded))
+	})
+
+	t.Run("getPayload", func(t *testing.T) {
+		jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+		require.NoError(t, err)
+		defer jsonFile.Close()
+		signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+		require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+		backend := newTestBackend(t, 1, time.Second)
+		key := bidKey(signedBlindedBeaconBlock.Message.Slot, signedBlindedBeaconBlock.Message.Body.ExecutionPayloadHeader.BlockHash)
+		backend.boost.bids[key] = bidResp{
+			bidInfo: bidInfo{value: uint256.NewInt(12345)},
+			relays:  []types.RelayEntry{backend.relays[0].RelayEntry},
+		}
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, backend.relays[0].RelayEntry.GetURI(""), rr.Header().Get(HeaderKeyRelay))
+		require.Equal(t, "12345", rr.Header().Get(HeaderKeyBidValueWei))
+	})
+}
+
+func TestErrorResponses(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+
+	rr := backend.request(t, http.MethodGet, "/eth/v1/builder/unknown", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.JSONEq(t, `{"code":404,"message":"route not found"}`, rr.Body.String())
+
+	rr = backend.request(t, http.MethodGet, params.PathGetPayload, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.JSONEq(t, `{"code":405,"message":"method not allowed"}`, rr.Body.String())
+}
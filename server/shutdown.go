@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownBidWindow is how long after returning a bid the beacon node might still ask for its payload
+const shutdownBidWindow = 4 * time.Second
+
+// shutdownPollInterval is how often the drain checks for requests in flight
+const shutdownPollInterval = 10 * time.Millisecond
+
+var errShuttingDown = errors.New("mev-boost is shutting down")
+
+// shutdownState tracks the requests of the slot in flight, so a shutdown doesn't abort a block proposal
+type shutdownState struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+	lastBid  atomic.Int64 // unix nanoseconds
+
+	refusedHeaders atomic.Int64
+	drainedPayload atomic.Int64
+}
+
+// bidReturned records that a bid was returned, its payload might still be requested
+func (s *shutdownState) bidReturned() {
+	s.lastBid.Store(time.Now().UnixNano())
+}
+
+// busy returns true while requests are in flight or the payload of a recent bid might still be requested
+func (s *shutdownState) busy() bool {
+	return s.inFlight.Load() > 0 || time.Since(time.Unix(0, s.lastBid.Load())) < shutdownBidWindow
+}
+
+// trackGetHeader counts getHeader requests in flight, and refuses new ones with 503 while shutting down, so the beacon
+// node builds a local block
+func (m *BoostService) trackGetHeader(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if m.shutdown.draining.Load() {
+			m.shutdown.refusedHeaders.Add(1)
+			m.respondError(w, http.StatusServiceUnavailable, errShuttingDown.Error())
+			return
+		}
+		m.shutdown.inFlight.Add(1)
+		defer m.shutdown.inFlight.Add(-1)
+		next(w, req)
+	}
+}
+
+// trackGetPayload counts getPayload requests in flight, they are still served while shutting down
+func (m *BoostService) trackGetPayload(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		m.shutdown.inFlight.Add(1)
+		defer m.shutdown.inFlight.Add(-1)
+		if m.shutdown.draining.Load() {
+			m.shutdown.drainedPayload.Add(1)
+		}
+		next(w, req)
+	}
+}
+
+// checkServerStart returns an error if a server can't be started, because it is running or mev-boost is shutting
+// down. It's called with the servers lock held.
+func (m *BoostService) checkServerStart(running bool) error {
+	if running {
+		return errServerAlreadyRunning
+	}
+	if m.shutdown.draining.Load() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+// Shutdown stops mev-boost without aborting a block proposal. New getHeader requests are refused right away, while
+// getPayload requests are served until none is in flight and no bid was returned within the bid window, or until the
+// context is done. Then the servers are stopped and the state files are saved.
+func (m *BoostService) Shutdown(ctx context.Context) error {
+	start := time.Now()
+	m.shutdown.draining.Store(true)
+	m.notifySystemd(sdNotifyStopping)
+	m.log.Info("shutting down, draining in-flight getPayload requests")
+
+	drained := true
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for m.shutdown.busy() && drained {
+		select {
+		case <-ctx.Done():
+			drained = false
+		case <-ticker.C:
+		}
+	}
+
+	var errs []error
+	m.serversLock.Lock()
+	// Other requests get the rest of the drain timeout, i.e. event streams are closed by then
+	if m.srv != nil && m.srv.Shutdown(ctx) != nil {
+		errs = append(errs, m.srv.Close())
+	}
+	if m.grpcSrv != nil {
+		m.grpcSrv.Stop()
+	}
+	if m.metricsSrv != nil {
+		errs = append(errs, m.metricsSrv.Close())
+	}
+	if m.pprofSrv != nil {
+		errs = append(errs, m.pprofSrv.Close())
+	}
+	m.serversLock.Unlock()
+	if m.reputationFile != "" {
+		errs = append(errs, m.saveReputation())
+	}
+	if m.registrationsFile != "" {
+		errs = append(errs, m.saveRegistrations())
+	}
+	if m.bidAuditLog != nil {
+		m.bidAuditLog.close()
+	}
+	if m.revealJournal != nil {
+		errs = append(errs, m.revealJournal.Close())
+	}
+
+	log := m.log.WithFields(logrus.Fields{
+		"drainDuration":    time.Since(start).String(),
+		"drained":          drained,
+		"inFlight":         m.shutdown.inFlight.Load(),
+		"refusedGetHeader": m.shutdown.refusedHeaders.Load(),
+		"servedGetPayload": m.shutdown.drainedPayload.Load(),
+	})
+	if !drained {
+		log.Warn("shutdown drain timed out, aborting the requests in flight")
+	} else {
+		log.Info("shutdown complete")
+	}
+	return errors.Join(errs...)
+}
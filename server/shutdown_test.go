@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	eth2ApiV1Deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-deneb.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	signedBlindedBeaconBlock := new(eth2ApiV1Deneb.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &signedBlindedBeaconBlock))
+
+	// getPayload starts a getPayload request and returns its response code once done
+	getPayload := func(t *testing.T, backend *testBackend) chan int {
+		t.Helper()
+		code := make(chan int, 1)
+		go func() {
+			code <- backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock).Code
+		}()
+		require.Eventually(t, func() bool { return backend.boost.shutdown.inFlight.Load() == 1 }, time.Second, time.Millisecond)
+		return code
+	}
+
+	t.Run("New getHeader requests are refused", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		require.NoError(t, backend.boost.Shutdown(context.Background()))
+
+		hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+		pubkey := mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(getHeaderPath(1, hash, pubkey)))
+		require.Equal(t, http.StatusServiceUnavailable, backend.request(t, http.MethodGet, params.PathReadyz, nil).Code)
+	})
+
+	t.Run("In-flight getPayload requests are served", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		backend.relays[0].ResponseDelay = 100 * time.Millisecond
+		code := getPayload(t, backend)
+
+		require.NoError(t, backend.boost.Shutdown(context.Background()))
+		require.Len(t, code, 1)
+		require.Equal(t, http.StatusOK, <-code)
+	})
+
+	t.Run("The payload of a recent bid can still be requested", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(signedBlindedBeaconBlock)
+		backend.boost.shutdown.bidReturned()
+
+		done := make(chan error, 1)
+		go func() { done <- backend.boost.Shutdown(context.Background()) }()
+		time.Sleep(50 * time.Millisecond)
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, signedBlindedBeaconBlock)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, done)
+		require.Equal(t, int64(1), backend.boost.shutdown.drainedPayload.Load())
+	})
+
+	t.Run("The drain stops at the timeout", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].ResponseDelay = 500 * time.Millisecond
+		getPayload(t, backend)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		require.NoError(t, backend.boost.Shutdown(ctx))
+		require.Less(t, time.Since(start), 400*time.Millisecond)
+		require.Equal(t, int64(1), backend.boost.shutdown.inFlight.Load())
+	})
+}
+
+func TestShutdownBeforeServersStart(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	backend.boost.metricsAddr = "localhost:0"
+	backend.boost.pprofAddr = "localhost:0"
+	backend.boost.grpcAddr = "localhost:0"
+	require.NoError(t, backend.boost.Shutdown(context.Background()))
+
+	// Servers started after the shutdown would keep running
+	require.ErrorIs(t, backend.boost.StartMetricsServer(), errShuttingDown)
+	require.ErrorIs(t, backend.boost.StartPprofServer(), errShuttingDown)
+	require.ErrorIs(t, backend.boost.StartGRPCServer(), errShuttingDown)
+}
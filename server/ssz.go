@@ -0,0 +1,126 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// The media types of the builder API
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeSSZ  = "application/octet-stream"
+)
+
+var (
+	errSSZConsensusVersion = errors.New("SSZ requests need the Eth-Consensus-Version header")
+	errSSZVersion          = errors.New("no SSZ encoding for consensus version")
+)
+
+type sszUnmarshaler interface {
+	UnmarshalSSZ(buf []byte) error
+}
+
+// isSSZRequest returns true if the request body is SSZ encoded
+func isSSZRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && mediaType == MediaTypeSSZ
+}
+
+// acceptsSSZ returns true if the client prefers SSZ over JSON responses by the Accept header, i.e.
+// "application/octet-stream;q=1.0,application/json;q=0.9". Of equally weighted types the first one wins.
+func acceptsSSZ(req *http.Request) bool {
+	best, bestWeight := MediaTypeJSON, 0.0
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		weight := 1.0
+		if q, ok := params["q"]; ok {
+			if weight, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if mediaType == "*/*" {
+			mediaType = MediaTypeJSON
+		}
+		if (mediaType == MediaTypeJSON || mediaType == MediaTypeSSZ) && weight > bestWeight {
+			best, bestWeight = mediaType, weight
+		}
+	}
+	return best == MediaTypeSSZ
+}
+
+// decodeRegistrationsSSZ decodes an SSZ list of signed validator registrations
+func decodeRegistrationsSSZ(body []byte) ([]builderApiV1.SignedValidatorRegistration, error) {
+	list := new(builderApiV1.SignedValidatorRegistrations)
+	if err := list.UnmarshalSSZ(body); err != nil {
+		return nil, err
+	}
+	registrations := make([]builderApiV1.SignedValidatorRegistration, 0, len(list.Registrations))
+	for _, registration := range list.Registrations {
+		registrations = append(registrations, *registration)
+	}
+	return registrations, nil
+}
+
+// bidSSZ returns the SSZ encoding of the signed builder bid
+func bidSSZ(bid *builderSpec.VersionedSignedBuilderBid) ([]byte, error) {
+	switch bid.Version {
+	case spec.DataVersionBellatrix:
+		return bid.Bellatrix.MarshalSSZ()
+	case spec.DataVersionCapella:
+		return bid.Capella.MarshalSSZ()
+	case spec.DataVersionDeneb:
+		return bid.Deneb.MarshalSSZ()
+	case spec.DataVersionElectra:
+		return bid.Electra.MarshalSSZ()
+	case spec.DataVersionFulu:
+		return bid.Fulu.MarshalSSZ()
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
+	}
+	return nil, fmt.Errorf("%w: %s", errSSZVersion, bid.Version)
+}
+
+// payloadSSZ returns the SSZ encoding of the payload: the execution payload before Deneb, the execution payload and
+// blobs bundle since
+func payloadSSZ(payload *builderApi.VersionedSubmitBlindedBlockResponse) ([]byte, error) {
+	switch payload.Version {
+	case spec.DataVersionBellatrix:
+		return payload.Bellatrix.MarshalSSZ()
+	case spec.DataVersionCapella:
+		return payload.Capella.MarshalSSZ()
+	case spec.DataVersionDeneb:
+		return payload.Deneb.MarshalSSZ()
+	case spec.DataVersionElectra:
+		return payload.Electra.MarshalSSZ()
+	case spec.DataVersionFulu:
+		return payload.Fulu.MarshalSSZ()
+	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
+	}
+	return nil, fmt.Errorf("%w: %s", errSSZVersion, payload.Version)
+}
+
+// respondSSZ responds with the SSZ encoded response of the consensus version
+func (m *BoostService) respondSSZ(w http.ResponseWriter, version spec.DataVersion, encoded []byte, err error) {
+	if err != nil {
+		m.log.WithError(err).Error("could not encode SSZ response")
+		m.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", MediaTypeSSZ)
+	w.Header().Set(HeaderEthConsensusVersion, version.String())
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(encoded); err != nil {
+		m.log.WithError(err).Error("could not write SSZ response")
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderApiDeneb "github.com/attestantio/go-builder-client/api/deneb"
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	eth2ApiV1Electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsSSZ(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"":                         false,
+		"application/json":         false,
+		"*/*":                      false,
+		"application/octet-stream": true,
+		"application/octet-stream;q=1.0,application/json;q=0.9": true,
+		"application/json;q=0.9,application/octet-stream":       true,
+		"application/json,application/octet-stream":             false,
+		"application/octet-stream;q=0.5,*/*":                    false,
+		"text/html,application/octet-stream;q=0.1":              true,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		require.Equal(t, expected, acceptsSSZ(req), accept)
+	}
+}
+
+// sszRequest sends an SSZ request to mev-boost, with SSZ as the preferred response encoding
+func (be *testBackend) sszRequest(t *testing.T, method, path string, body []byte, consensusVersion string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if body != nil {
+		req.Header.Set("Content-Type", MediaTypeSSZ)
+	}
+	if consensusVersion != "" {
+		req.Header.Set(HeaderEthConsensusVersion, consensusVersion)
+	}
+	req.Header.Set("Accept", "application/octet-stream;q=1.0,application/json;q=0.9")
+	rr := httptest.NewRecorder()
+	be.boost.getRouter().ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGetHeaderSSZ(t *testing.T) {
+	backend := newTestBackend(t, 1, time.Second)
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+
+	rr := backend.sszRequest(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil, "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, MediaTypeSSZ, rr.Header().Get("Content-Type"))
+	require.Equal(t, "deneb", rr.Header().Get(HeaderEthConsensusVersion))
+
+	bid := new(builderApiDeneb.SignedBuilderBid)
+	require.NoError(t, bid.UnmarshalSSZ(rr.Body.Bytes()))
+	require.Equal(t, hash, bid.Message.Header.BlockHash)
+}
+
+func TestGetPayloadSSZ(t *testing.T) {
+	jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-electra.json")
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	block := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+	require.NoError(t, DecodeJSON(jsonFile, &block))
+	body, err := block.MarshalSSZ()
+	require.NoError(t, err)
+
+	t.Run("SSZ request and response", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.relays[0].GetPayloadResponse = blindedBlockToBlockResponse(block)
+		rr := backend.sszRequest(t, http.MethodPost, params.PathGetPayload, body, "electra")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, MediaTypeSSZ, rr.Header().Get("Content-Type"))
+		require.Equal(t, "electra", rr.Header().Get(HeaderEthConsensusVersion))
+
+		payload := new(builderApiDeneb.ExecutionPayloadAndBlobsBundle)
+		require.NoError(t, payload.UnmarshalSSZ(rr.Body.Bytes()))
+		require.Equal(t, blockHash(block), payload.ExecutionPayload.BlockHash)
+	})
+
+	t.Run("Missing consensus version", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := backend.sszRequest(t, http.MethodPost, params.PathGetPayload, body, "")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(params.PathGetPayload))
+	})
+
+	t.Run("Wrong consensus version", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		rr := backend.sszRequest(t, http.MethodPost, params.PathGetPayload, body, "deneb")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func testRegistration() *builderApiV1.SignedValidatorRegistration {
+	return &builderApiV1.SignedValidatorRegistration{
+		Message: &builderApiV1.ValidatorRegistration{
+			FeeRecipient: mock.HexToAddress("0xdb65fEd33dc262Fe09D9a2Ba8F80b329BA25f941"),
+			Timestamp:    time.Unix(1234356, 0),
+			Pubkey:       mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"),
+		},
+		Signature: mock.HexToSignature("0x81510b571e22f89d1697545aac01c9ad0c1e7a3e778b3078bef524efae14990e58a6e960a152abd49de2e18d7fd3081c15d5c25867ccfad3d47beef6b39ac24b6b9fbf2cfa91c88f67aff750438a6841ec9e4a06a94ae41410c4f97b75ab284c"),
+	}
+}
+
+func TestRegisterValidatorSSZ(t *testing.T) {
+	reg := testRegistration()
+	body, err := (&builderApiV1.SignedValidatorRegistrations{Registrations: []*builderApiV1.SignedValidatorRegistration{reg}}).MarshalSSZ()
+	require.NoError(t, err)
+
+	backend := newTestBackend(t, 1, time.Second)
+	rr := backend.sszRequest(t, http.MethodPost, params.PathRegisterValidator, body, "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 1, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+
+	rr = backend.sszRequest(t, http.MethodPost, params.PathRegisterValidator, body[:100], "")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRelaySSZ(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkey := mock.HexToPubkey("0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relays[0].SSZ = true
+		return backend
+	}
+
+	t.Run("getHeader", func(t *testing.T) {
+		backend := setup(t)
+		bid := backend.relays[0].MakeGetHeaderResponse(12345, hash.String(), hash.String(), pubkey.String(), spec.DataVersionDeneb)
+		backend.relays[0].OverrideHandleGetHeader(func(w http.ResponseWriter, req *http.Request) {
+			require.True(t, acceptsSSZ(req))
+			encoded, err := bid.Deneb.MarshalSSZ()
+			require.NoError(t, err)
+			w.Header().Set("Content-Type", MediaTypeSSZ)
+			w.Header().Set(HeaderEthConsensusVersion, "deneb")
+			_, err = w.Write(encoded)
+			require.NoError(t, err)
+		})
+
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, pubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderSpec.VersionedSignedBuilderBid)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, spec.DataVersionDeneb, resp.Version)
+		require.Equal(t, hash, resp.Deneb.Message.Header.BlockHash)
+	})
+
+	t.Run("getPayload", func(t *testing.T) {
+		jsonFile, err := os.Open("../testdata/signed-blinded-beacon-block-electra.json")
+		require.NoError(t, err)
+		defer jsonFile.Close()
+		block := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+		require.NoError(t, DecodeJSON(jsonFile, &block))
+
+		backend := setup(t)
+		backend.relays[0].OverrideHandleGetPayload(func(w http.ResponseWriter, req *http.Request) {
+			require.Equal(t, MediaTypeSSZ, req.Header.Get("Content-Type"))
+			require.Equal(t, "electra", req.Header.Get(HeaderEthConsensusVersion))
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			relayBlock := new(eth2ApiV1Electra.SignedBlindedBeaconBlock)
+			require.NoError(t, relayBlock.UnmarshalSSZ(body))
+			encoded, err := blindedBlockToBlockResponse(relayBlock).Electra.MarshalSSZ()
+			require.NoError(t, err)
+			w.Header().Set("Content-Type", MediaTypeSSZ)
+			w.Header().Set(HeaderEthConsensusVersion, "electra")
+			_, err = w.Write(encoded)
+			require.NoError(t, err)
+		})
+
+		rr := backend.request(t, http.MethodPost, params.PathGetPayload, block)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(builderApi.VersionedSubmitBlindedBlockResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, spec.DataVersionElectra, resp.Version)
+		require.Equal(t, blockHash(block), resp.Electra.ExecutionPayload.BlockHash)
+	})
+
+	t.Run("Fallback to JSON", func(t *testing.T) {
+		backend := setup(t)
+		backend.relays[0].OverrideHandleRegisterValidator(func(w http.ResponseWriter, req *http.Request) {
+			if isSSZRequest(req) {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			payload := []builderApiV1.SignedValidatorRegistration{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+			require.Len(t, payload, 1)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		payload := []builderApiV1.SignedValidatorRegistration{*testRegistration()}
+		rr := backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 2, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+		require.False(t, backend.boost.relaySSZ.useSSZ(backend.boost.relays[0]))
+
+		// The relay gets JSON requests from then on
+		rr = backend.request(t, http.MethodPost, params.PathRegisterValidator, payload)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 3, backend.relays[0].GetRequestCount(params.PathRegisterValidator))
+	})
+}
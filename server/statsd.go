@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// MetricsSink receives the relay metrics in addition to the prometheus registry
+type MetricsSink interface {
+	// Count increments the counter with the given name and tags by one
+	Count(name string, tags map[string]string)
+	// Timing records a single duration
+	Timing(name string, d time.Duration, tags map[string]string)
+}
+
+// nopMetricsSink drops all metrics. It's used unless a MetricsSink is configured.
+type nopMetricsSink struct{}
+
+func (nopMetricsSink) Count(string, map[string]string)                 {}
+func (nopMetricsSink) Timing(string, time.Duration, map[string]string) {}
+
+// StatsdSink ships metrics over UDP to a StatsD server. With DogStatsD enabled the tags are sent as DogStatsD
+// tags, otherwise the tag values are appended to the metric name.
+type StatsdSink struct {
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+}
+
+// NewStatsdSink creates a StatsdSink sending to the StatsD server at addr (host:port)
+func NewStatsdSink(addr, prefix string, dogStatsD bool) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &StatsdSink{conn: conn, prefix: prefix, dogStatsD: dogStatsD}, nil
+}
+
+// Close closes the UDP connection
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsdSink) Count(name string, tags map[string]string) {
+	s.send(name, "1|c", tags)
+}
+
+func (s *StatsdSink) Timing(name string, d time.Duration, tags map[string]string) {
+	s.send(name, fmt.Sprintf("%.3f|ms", float64(d.Microseconds())/1000), tags)
+}
+
+// send writes a single metric. Errors are ignored, StatsD is best-effort by design.
+func (s *StatsdSink) send(name, value string, tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+	if !s.dogStatsD {
+		for _, k := range keys {
+			b.WriteString(".")
+			b.WriteString(statsdSanitize(tags[k]))
+		}
+	}
+	b.WriteString(":")
+	b.WriteString(value)
+	if s.dogStatsD && len(keys) > 0 {
+		for i, k := range keys {
+			if i == 0 {
+				b.WriteString("|#")
+			} else {
+				b.WriteString(",")
+			}
+			b.WriteString(k + ":" + tags[k])
+		}
+	}
+	_, _ = s.conn.Write([]byte(b.String()))
+}
+
+// statsdSanitize replaces the characters which have a meaning in StatsD metric names
+var statsdSanitize = strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", "#", "_").Replace
+
+// relayMetricTags returns the tags of a relay metric, matching the prometheus labels
+func relayMetricTags(relay types.RelayEntry, tags map[string]string) map[string]string {
+	tags["relay"] = relayLabel(relay)
+	return tags
+}
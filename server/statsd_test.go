@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	read := func() string {
+		t.Helper()
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	relay := mock.NewRelay(t)
+	tags := map[string]string{"relay": "relay.example.com:9000", "event": "bid_received"}
+
+	t.Run("StatsD", func(t *testing.T) {
+		sink, err := NewStatsdSink(conn.LocalAddr().String(), "mevboost", false)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		sink.Count("relay_auction_events", tags)
+		require.Equal(t, "mevboost.relay_auction_events.bid_received.relay_example_com_9000:1|c", read())
+
+		sink.Timing("relay_request_phase_duration", 1500*time.Microsecond, map[string]string{"phase": "ttfb"})
+		require.Equal(t, "mevboost.relay_request_phase_duration.ttfb:1.500|ms", read())
+	})
+
+	t.Run("DogStatsD", func(t *testing.T) {
+		sink, err := NewStatsdSink(conn.LocalAddr().String(), "mevboost.", true)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		sink.Count("relay_auction_events", tags)
+		require.Equal(t, "mevboost.relay_auction_events:1|c|#event:bid_received,relay:relay.example.com:9000", read())
+	})
+
+	t.Run("Receives relay metrics", func(t *testing.T) {
+		sink, err := NewStatsdSink(conn.LocalAddr().String(), "", true)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		stats := newRelayStatsStore(sink)
+		stats.record(relay.RelayEntry, relayStatsBidWon)
+		require.Equal(t, "relay_auction_events:1|c|#event:bid_won,relay:"+relayLabel(relay.RelayEntry), read())
+	})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/params"
+)
+
+// systemd notify states, see sd_notify(3)
+const (
+	sdNotifyReady    = "READY=1"
+	sdNotifyStopping = "STOPPING=1"
+	sdNotifyWatchdog = "WATCHDOG=1"
+)
+
+// sdNotify sends the state to the notify socket of systemd. It does nothing unless mev-boost runs as a notify service.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Names starting with @ are in the abstract namespace, net handles them
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval returns the watchdog interval of systemd, zero if the watchdog isn't enabled for this process
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// notifySystemd logs errors of notifying systemd, which don't affect serving requests
+func (m *BoostService) notifySystemd(state string) {
+	if err := sdNotify(state); err != nil {
+		m.log.WithError(err).WithField("state", state).Warn("could not notify systemd")
+	}
+}
+
+// startWatchdog notifies the systemd watchdog twice per interval, as long as the HTTP server answers the liveness
+// check on the listen address. If it stops answering, systemd restarts mev-boost.
+func (m *BoostService) startWatchdog(interval time.Duration) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := m.checkLiveness(interval / 2); err != nil {
+			m.log.WithError(err).Warn("liveness check failed, not notifying the systemd watchdog")
+			continue
+		}
+		m.notifySystemd(sdNotifyWatchdog)
+	}
+}
+
+// checkLiveness requests the liveness probe on the first listen address. Any response proves the server is alive, i.e.
+// a 403 of the client allowlist, or the 400 of a TLS listen address to a plain HTTP request.
+func (m *BoostService) checkLiveness(timeout time.Duration) error {
+	addr, err := parseListenAddr(m.listenAddr)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: timeout}
+	url := "http://" + addr.addr + params.PathLivez
+	if path, ok := strings.CutPrefix(addr.addr, unixAddrPrefix); ok {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}
+		url = "http://mev-boost" + params.PathLivez
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotifySocket serves a systemd notify socket and returns the states sent to it
+func listenNotifySocket(t *testing.T) chan string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	states := make(chan string, 10)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			states <- string(buf[:n])
+		}
+	}()
+	return states
+}
+
+func TestSystemdNotify(t *testing.T) {
+	t.Run("Nothing is sent without notify socket", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+		require.NoError(t, sdNotify(sdNotifyReady))
+	})
+
+	t.Run("Ready once listening, stopping on shutdown", func(t *testing.T) {
+		states := listenNotifySocket(t)
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.listenAddr = unixAddrPrefix + filepath.Join(t.TempDir(), "mev-boost.sock")
+		go func() {
+			err := backend.boost.StartHTTPServer()
+			require.NoError(t, err) //nolint:testifylint
+		}()
+		require.Equal(t, sdNotifyReady, <-states)
+
+		require.NoError(t, backend.boost.Shutdown(context.Background()))
+		require.Equal(t, sdNotifyStopping, <-states)
+	})
+
+	t.Run("The watchdog is notified while the server is alive", func(t *testing.T) {
+		states := listenNotifySocket(t)
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.listenAddr = unixAddrPrefix + filepath.Join(t.TempDir(), "mev-boost.sock")
+		t.Setenv("WATCHDOG_USEC", "100000")
+		go func() {
+			err := backend.boost.StartHTTPServer()
+			require.NoError(t, err) //nolint:testifylint
+		}()
+		require.Equal(t, sdNotifyReady, <-states)
+		require.Equal(t, sdNotifyWatchdog, <-states)
+
+		// No more pings once the server is gone
+		require.NoError(t, backend.boost.srv.Close())
+		time.Sleep(100 * time.Millisecond)
+		for len(states) > 0 {
+			<-states
+		}
+		time.Sleep(200 * time.Millisecond)
+		require.Empty(t, states)
+	})
+
+	t.Run("Watchdog interval", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "")
+		require.Zero(t, watchdogInterval())
+		t.Setenv("WATCHDOG_USEC", "30000000")
+		require.Equal(t, 30*time.Second, watchdogInterval())
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+		require.Equal(t, 30*time.Second, watchdogInterval())
+		t.Setenv("WATCHDOG_PID", "1")
+		require.Zero(t, watchdogInterval())
+	})
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// Bid tiebreakers decide between bids of the same value and relay priority
+const (
+	TiebreakerBlockHash  = "blockhash"   // the lowest block hash wins
+	TiebreakerRelayOrder = "relay-order" // the bid of the relay listed first wins
+	TiebreakerLatency    = "latency"     // the bid of the fastest relay response wins
+	TiebreakerRandom     = "random"      // a random bid wins
+)
+
+var errUnknownTiebreaker = errors.New("unknown bid tiebreaker")
+
+// Tiebreakers are the names of all bid tiebreakers
+var Tiebreakers = []string{TiebreakerBlockHash, TiebreakerRelayOrder, TiebreakerLatency, TiebreakerRandom}
+
+// tiebreaker returns true if the bid should replace the best bid of the same value and relay priority
+type tiebreaker func(bid, best *Bid) bool
+
+// newTiebreaker returns the tiebreaker with the name, the block hash tiebreaker if empty. All tiebreakers fall back to
+// the block hash, so the result is deterministic.
+func newTiebreaker(name string) (tiebreaker, error) {
+	switch name {
+	case "", TiebreakerBlockHash:
+		return lowerBlockHash, nil
+	case TiebreakerRelayOrder:
+		return func(bid, best *Bid) bool {
+			if bid.RelayOrder != best.RelayOrder {
+				return bid.RelayOrder < best.RelayOrder
+			}
+			return lowerBlockHash(bid, best)
+		}, nil
+	case TiebreakerLatency:
+		return func(bid, best *Bid) bool {
+			if bid.Latency != best.Latency {
+				return bid.Latency < best.Latency
+			}
+			return lowerBlockHash(bid, best)
+		}, nil
+	case TiebreakerRandom:
+		return func(bid, best *Bid) bool {
+			if bid.random != best.random {
+				return bid.random < best.random
+			}
+			return lowerBlockHash(bid, best)
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", errUnknownTiebreaker, name)
+}
+
+func lowerBlockHash(bid, best *Bid) bool {
+	return bid.BlockHash.String() < best.BlockHash.String()
+}
+
+// bidCandidate is a bid of the auction, along with the relays which delivered it
+type bidCandidate struct {
+	info       bidInfo
+	response   builderSpec.VersionedSignedBuilderBid // the most valuable response of the bid, for the bid policy
+	relays     []types.RelayEntry
+	relayOrder int           // the lowest position of the relays in the relay list
+	latency    time.Duration // the fastest response of the relays
+	random     uint64        // drawn once per bid, so the random tiebreaker picks each tied bid with equal chance
+	score      float64       // the highest bid script score of the relays, 0 without bid script
+
+	relayOrders    []int           // the position of each relay, to update relayOrder if a relay withdraws the bid
+	relayLatencies []time.Duration // the response time of each relay, to update latency if a relay withdraws the bid
+	relayScores    []float64       // the bid script score of each relay, to update score if a relay withdraws the bid
+}
+
+func newBidCandidate(info bidInfo) *bidCandidate {
+	return &bidCandidate{
+		info:       info,
+		relayOrder: math.MaxInt,
+		latency:    math.MaxInt64,
+		random:     rand.Uint64(), //nolint:gosec
+	}
+}
+
+// add records a relay which delivered the bid
+func (c *bidCandidate) add(relay types.RelayEntry, relayOrder int, latency time.Duration, score float64) {
+	c.relays = append(c.relays, relay)
+	c.relayOrders = append(c.relayOrders, relayOrder)
+	c.relayLatencies = append(c.relayLatencies, latency)
+	c.relayScores = append(c.relayScores, score)
+	c.relayOrder = min(c.relayOrder, relayOrder)
+	c.latency = min(c.latency, latency)
+	c.score = max(c.score, score)
+}
+
+// bid returns the candidate as compared by the bid selector
+func (c *bidCandidate) bid() *Bid {
+	return &Bid{
+		BlockHash:  c.info.blockHash,
+		Builder:    c.info.pubkey,
+		Value:      c.info.value,
+		GasLimit:   c.info.gasLimit,
+		Relays:     c.relays,
+		RelayOrder: c.relayOrder,
+		Latency:    c.latency,
+		Score:      c.score,
+		random:     c.random,
+	}
+}
+
+// remove drops a relay which withdrew the bid, i.e. by replacing it with a newer bid
+func (c *bidCandidate) remove(relay types.RelayEntry) {
+	relays, orders, latencies, scores := c.relays, c.relayOrders, c.relayLatencies, c.relayScores
+	c.relays, c.relayOrders, c.relayLatencies, c.relayScores = nil, nil, nil, nil
+	c.relayOrder, c.latency, c.score = math.MaxInt, math.MaxInt64, 0
+	for i := range relays {
+		if relays[i].String() != relay.String() {
+			c.add(relays[i], orders[i], latencies[i], scores[i])
+		}
+	}
+}
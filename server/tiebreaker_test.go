@@ -0,0 +1,108 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiebreaker(t *testing.T) {
+	relayA, relayB := types.RelayEntry{}, types.RelayEntry{}
+	newCandidate := func(blockHash string, relay types.RelayEntry, relayOrder int, latency time.Duration) *Bid {
+		candidate := newBidCandidate(bidInfo{blockHash: mock.HexToHash(blockHash), value: uint256.NewInt(12345)})
+		candidate.add(relay, relayOrder, latency, 0)
+		return candidate.bid()
+	}
+	bidA := newCandidate("0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", relayA, 1, 30*time.Millisecond)
+	bidB := newCandidate("0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", relayB, 0, 20*time.Millisecond)
+
+	t.Run("Tiebreakers", func(t *testing.T) {
+		for name, expected := range map[string]*Bid{
+			"":                   bidA,
+			TiebreakerBlockHash:  bidA,
+			TiebreakerRelayOrder: bidB,
+			TiebreakerLatency:    bidB,
+		} {
+			tiebreak, err := newTiebreaker(name)
+			require.NoError(t, err)
+			winner := bidA
+			if isBetterBid(bidB, bidA, tiebreak, nil) {
+				winner = bidB
+			}
+			require.Same(t, expected, winner, name)
+		}
+	})
+
+	t.Run("Random tiebreaker picks both bids", func(t *testing.T) {
+		tiebreak, err := newTiebreaker(TiebreakerRandom)
+		require.NoError(t, err)
+
+		wins := 0
+		for range 100 {
+			bidA := newCandidate("0xa18385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", relayA, 0, 0)
+			bidB := newCandidate("0xa28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7", relayB, 0, 0)
+			if tiebreak(bidB, bidA) {
+				wins++
+			}
+			require.NotEqual(t, tiebreak(bidA, bidB), tiebreak(bidB, bidA))
+		}
+		require.Positive(t, wins)
+		require.Less(t, wins, 100)
+	})
+
+	t.Run("Boost factor", func(t *testing.T) {
+		newBid := func(value uint64, relays ...types.RelayEntry) *Bid {
+			candidate := newBidCandidate(bidInfo{value: uint256.NewInt(value)})
+			for i, relay := range relays {
+				candidate.add(relay, i, 0, 0)
+			}
+			return candidate.bid()
+		}
+		trusted := types.RelayEntry{BoostFactor: 1.05}
+		untrusted := types.RelayEntry{BoostFactor: 0.9}
+
+		// 1000 wei from the trusted relay are worth 1050 wei
+		require.True(t, isBetterBid(newBid(1000, trusted), newBid(1049, relayA), nil, nil))
+		require.True(t, isBetterBid(newBid(1051, relayA), newBid(1000, trusted), nil, nil))
+		require.True(t, isBetterBid(newBid(1000, relayA), newBid(1100, untrusted), nil, nil))
+
+		// The highest boost factor of the relays of a bid counts, relays without boost factor count as 1
+		require.True(t, isBetterBid(newBid(1000, untrusted, relayA), newBid(950, trusted), nil, nil))
+	})
+
+	t.Run("Label preferences", func(t *testing.T) {
+		newBid := func(value uint64, relays ...types.RelayEntry) *Bid {
+			candidate := newBidCandidate(bidInfo{value: uint256.NewInt(value)})
+			for i, relay := range relays {
+				candidate.add(relay, i, 0, 0)
+			}
+			return candidate.bid()
+		}
+		nonFiltering := types.RelayEntry{Labels: []string{"non-filtering"}}
+		filtering := types.RelayEntry{Labels: []string{"filtering"}}
+		preferences, err := newLabelPreferences(map[string]float64{"non-filtering": 5})
+		require.NoError(t, err)
+
+		// Non-filtering bids win unless the filtering bid is more than 5% higher
+		require.True(t, isBetterBid(newBid(1000, nonFiltering), newBid(1049, filtering), nil, preferences))
+		require.True(t, isBetterBid(newBid(1051, filtering), newBid(1000, nonFiltering), nil, preferences))
+		require.True(t, isBetterBid(newBid(1000, filtering, nonFiltering), newBid(1049, relayA), nil, preferences))
+		require.True(t, isBetterBid(newBid(1049, filtering), newBid(1000, nonFiltering), nil, nil))
+
+		// The preference combines with the boost factor
+		boosted := types.RelayEntry{BoostFactor: 1.1}
+		require.True(t, isBetterBid(newBid(1000, boosted), newBid(1000, nonFiltering), nil, preferences))
+
+		_, err = newLabelPreferences(map[string]float64{"filtering": -100})
+		require.ErrorIs(t, err, errInvalidLabelPreference)
+	})
+
+	t.Run("Unknown tiebreaker", func(t *testing.T) {
+		_, err := newTiebreaker("fastest")
+		require.ErrorIs(t, err, errUnknownTiebreaker)
+	})
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name used for all mev-boost spans
+const TracerName = "github.com/flashbots/mev-boost/server"
+
+// tracer returns the tracer of the globally registered provider (a no-op unless tracing was set up)
+func tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// startSpan starts a new span as child of the span in ctx (if any)
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, opts...)
+}
+
+// setSpanError records err on the span and marks it as failed
+func setSpanError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// endSpan records err on the span (if set) and ends it
+func endSpan(span trace.Span, err error) {
+	setSpanError(span, err)
+	span.End()
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSendHTTPRequestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	traceparent := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceparent <- r.Header.Get("traceparent")
+	}))
+	defer ts.Close()
+
+	ctx, span := startSpan(context.Background(), "test")
+	code, err := SendHTTPRequest(ctx, *http.DefaultClient, http.MethodGet, ts.URL, "", nil, nil, nil)
+	span.End()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+
+	// The trace context is propagated to the relay
+	require.Contains(t, <-traceparent, span.SpanContext().TraceID().String())
+
+	// Both the parent span and the request span are recorded
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "SendHTTPRequest", spans[0].Name)
+	require.Equal(t, span.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
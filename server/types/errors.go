@@ -7,3 +7,6 @@ var ErrMissingRelayPubkey = errors.New("missing relay public key")
 
 // ErrPointAtInfinityPubkey is returned if a new RelayEntry URL has point-at-infinity public key.
 var ErrPointAtInfinityPubkey = errors.New("relay public key cannot be the point-at-infinity")
+
+// ErrInvalidRelayOption is returned if a new RelayEntry URL has an invalid relay option query arg.
+var ErrInvalidRelayOption = errors.New("invalid relay option")
@@ -1,17 +1,122 @@
 package types
 
 import (
+	"fmt"
+	"math"
+	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/flashbots/go-boost-utils/utils"
 )
 
+// Relay URL query args to set per-relay options. They are removed from the URL, so they are not sent to the relay.
+const (
+	RelayArgTimeoutGetHeader  = "timeout_get_header"
+	RelayArgTimeoutGetPayload = "timeout_get_payload"
+	RelayArgTimeoutRegVal     = "timeout_register_validator"
+	RelayArgTier              = "tier"
+	RelayArgWeight            = "weight"
+	RelayArgSignatureCheck    = "signature_check"
+	RelayArgBackup            = "backup"
+	RelayArgBoostFactor       = "boost_factor"
+	RelayArgConstraints       = "constraints"
+	RelayArgLabels            = "labels"
+	RelayArgSSZ               = "ssz"
+	RelayArgMaxRetries        = "max_retries"
+	RelayArgRetryBackoff      = "retry_backoff"
+	RelayArgRetryJitter       = "retry_jitter"
+	RelayArgClientCert        = "client_cert"
+	RelayArgClientKey         = "client_key"
+	RelayArgProxy             = "proxy"
+	RelayArgBasicAuth         = "basic_auth"
+	RelayArgHeader            = "header"
+)
+
+// RelayArgs are all relay options
+var RelayArgs = []string{
+	RelayArgTimeoutGetHeader, RelayArgTimeoutGetPayload, RelayArgTimeoutRegVal, RelayArgTier, RelayArgWeight,
+	RelayArgSignatureCheck, RelayArgBackup, RelayArgBoostFactor, RelayArgConstraints, RelayArgLabels, RelayArgSSZ,
+	RelayArgMaxRetries, RelayArgRetryBackoff, RelayArgRetryJitter, RelayArgClientCert, RelayArgClientKey,
+	RelayArgProxy, RelayArgBasicAuth, RelayArgHeader,
+}
+
+// Signature checks of the relay bids
+const (
+	SignatureCheckVerify = "verify" // bids with an invalid signature are rejected
+	SignatureCheckWarn   = "warn"   // bids with an invalid signature are used, with a warning
+	SignatureCheckSkip   = "skip"   // signatures are not checked
+)
+
+// SignatureChecks are all signature checks
+var SignatureChecks = []string{SignatureCheckVerify, SignatureCheckWarn, SignatureCheckSkip}
+
 // RelayEntry represents a relay that mev-boost connects to.
 type RelayEntry struct {
 	PublicKey phase0.BLSPubKey
 	URL       *url.URL
+
+	// Request timeouts for this relay, the global timeouts are used if zero
+	TimeoutGetHeader  time.Duration
+	TimeoutGetPayload time.Duration
+	TimeoutRegVal     time.Duration
+
+	// Tier of the relay: bids of lower tiers are only used if no relay of a higher tier (lower number) has a bid
+	Tier int
+	// Weight of the relay: bids of relays with higher weight win over equal-value bids
+	Weight int
+
+	// SignatureCheck of the relay bids, see SignatureChecks. The global signature check is used if empty.
+	SignatureCheck string
+
+	// Backup relays are only asked for bids if no other relay has a usable bid within the backup window
+	Backup bool
+
+	// BoostFactor multiplies the bid values of the relay when comparing bids, i.e. 1.05 for a trusted relay. 1 if zero.
+	BoostFactor float64
+
+	// Constraints relays support the constraints API, they receive the proposer constraints and prove their inclusion
+	Constraints bool
+
+	// Labels categorize the relay for the relay label preferences, i.e. filtering or non-filtering. They are separated
+	// by spaces (or '+' in the URL).
+	Labels []string
+
+	// SSZ relays get the builder API requests SSZ encoded, and JSON requests if they reject SSZ with 406 or 415
+	SSZ bool
+
+	// getPayload retry policy of this relay: the maximum number of attempts, the delay between attempts and the random
+	// extra delay up to the jitter. The global retry policy is used if zero.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	RetryJitter  time.Duration
+
+	// TLS client certificate and key files of this relay, for relays gating access by mutual TLS. The global client
+	// certificate is used if empty.
+	ClientCert string
+	ClientKey  string
+
+	// Proxy routes the connections to this relay through a SOCKS5 or HTTP proxy, i.e. socks5h://127.0.0.1:9050 for Tor.
+	// Relays without proxy are connected directly.
+	Proxy *url.URL
+
+	// BasicAuth are the credentials of relays gating access by basic auth, and Headers are sent with all requests to
+	// the relay, i.e. an API key. They aren't part of the URL, so they don't show in logs and APIs.
+	BasicAuth *url.Userinfo
+	Headers   http.Header
+}
+
+// ProxySchemes are the supported schemes of relay proxies. socks5h resolves the relay host name through the proxy,
+// like socks5.
+var ProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// HasLabel returns true if the relay has the label
+func (r *RelayEntry) HasLabel(label string) bool {
+	return slices.Contains(r.Labels, label)
 }
 
 func (r *RelayEntry) String() string {
@@ -20,9 +125,25 @@ func (r *RelayEntry) String() string {
 
 // GetURI returns the full request URI with scheme, host, path and args.
 func GetURI(url *url.URL, path string) string {
-	u2 := *url
+	return GetURIWithQuery(url, path, nil)
+}
+
+// GetURIWithQuery returns the full request URI of the API path with the query args added to the args of the URL. The
+// path is appended to the base path of the URL, i.e. of relays served under a path, and the public key isn't sent.
+func GetURIWithQuery(u *url.URL, path string, query url.Values) string {
+	u2 := *u
 	u2.User = nil
-	u2.Path = path
+	if path != "" {
+		u2.Path = strings.TrimSuffix(u.Path, "/") + path
+		u2.RawPath = ""
+	}
+	if len(query) > 0 {
+		args := u.Query()
+		for key, values := range query {
+			args[key] = values
+		}
+		u2.RawQuery = args.Encode()
+	}
 	return u2.String()
 }
 
@@ -31,6 +152,11 @@ func (r *RelayEntry) GetURI(path string) string {
 	return GetURI(r.URL, path)
 }
 
+// GetURIWithQuery returns the full request URI with scheme, host, path and args for the relay, with the query args
+func (r *RelayEntry) GetURIWithQuery(path string, query url.Values) string {
+	return GetURIWithQuery(r.URL, path, query)
+}
+
 // NewRelayEntry creates a new instance based on an input string
 // relayURL can be IP@PORT, PUBKEY@IP:PORT, https://IP, etc.
 func NewRelayEntry(relayURL string) (entry RelayEntry, err error) {
@@ -61,9 +187,169 @@ func NewRelayEntry(relayURL string) (entry RelayEntry, err error) {
 		return entry, ErrPointAtInfinityPubkey
 	}
 
+	// Extract the relay options from the query args.
+	if err := entry.parseOptions(); err != nil {
+		return entry, err
+	}
+
 	return entry, nil
 }
 
+// parseOptions sets the relay options from the URL query args and removes them. Other query args are kept, so they are
+// still sent to the relay.
+func (r *RelayEntry) parseOptions() error {
+	query := r.URL.Query()
+	durations := map[string]*time.Duration{
+		RelayArgTimeoutGetHeader:  &r.TimeoutGetHeader,
+		RelayArgTimeoutGetPayload: &r.TimeoutGetPayload,
+		RelayArgTimeoutRegVal:     &r.TimeoutRegVal,
+		RelayArgRetryBackoff:      &r.RetryBackoff,
+		RelayArgRetryJitter:       &r.RetryJitter,
+	}
+
+	found := false
+	for arg, duration := range durations {
+		if !query.Has(arg) {
+			continue
+		}
+		d, err := time.ParseDuration(query.Get(arg))
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, arg, query.Get(arg))
+		}
+		*duration = d
+		query.Del(arg)
+		found = true
+	}
+
+	numbers := map[string]*int{
+		RelayArgTier:       &r.Tier,
+		RelayArgWeight:     &r.Weight,
+		RelayArgMaxRetries: &r.MaxRetries,
+	}
+	for arg, number := range numbers {
+		if !query.Has(arg) {
+			continue
+		}
+		n, err := strconv.Atoi(query.Get(arg))
+		if err != nil || n < 0 {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, arg, query.Get(arg))
+		}
+		*number = n
+		query.Del(arg)
+		found = true
+	}
+
+	if query.Has(RelayArgSignatureCheck) {
+		check := query.Get(RelayArgSignatureCheck)
+		if !slices.Contains(SignatureChecks, check) {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgSignatureCheck, check)
+		}
+		r.SignatureCheck = check
+		query.Del(RelayArgSignatureCheck)
+		found = true
+	}
+
+	if query.Has(RelayArgBackup) {
+		backup, err := strconv.ParseBool(query.Get(RelayArgBackup))
+		if err != nil {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgBackup, query.Get(RelayArgBackup))
+		}
+		r.Backup = backup
+		query.Del(RelayArgBackup)
+		found = true
+	}
+
+	if query.Has(RelayArgBoostFactor) {
+		factor, err := strconv.ParseFloat(query.Get(RelayArgBoostFactor), 64)
+		if err != nil || factor <= 0 || math.IsInf(factor, 0) {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgBoostFactor, query.Get(RelayArgBoostFactor))
+		}
+		r.BoostFactor = factor
+		query.Del(RelayArgBoostFactor)
+		found = true
+	}
+
+	if query.Has(RelayArgConstraints) {
+		constraints, err := strconv.ParseBool(query.Get(RelayArgConstraints))
+		if err != nil {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgConstraints, query.Get(RelayArgConstraints))
+		}
+		r.Constraints = constraints
+		query.Del(RelayArgConstraints)
+		found = true
+	}
+
+	if query.Has(RelayArgSSZ) {
+		ssz, err := strconv.ParseBool(query.Get(RelayArgSSZ))
+		if err != nil {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgSSZ, query.Get(RelayArgSSZ))
+		}
+		r.SSZ = ssz
+		query.Del(RelayArgSSZ)
+		found = true
+	}
+
+	if query.Has(RelayArgLabels) {
+		labels := strings.Fields(query.Get(RelayArgLabels))
+		if len(labels) == 0 {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgLabels, query.Get(RelayArgLabels))
+		}
+		r.Labels = labels
+		query.Del(RelayArgLabels)
+		found = true
+	}
+
+	if query.Has(RelayArgClientCert) || query.Has(RelayArgClientKey) {
+		r.ClientCert, r.ClientKey = query.Get(RelayArgClientCert), query.Get(RelayArgClientKey)
+		if r.ClientCert == "" || r.ClientKey == "" {
+			return fmt.Errorf("%w: %s and %s are both needed", ErrInvalidRelayOption, RelayArgClientCert, RelayArgClientKey)
+		}
+		query.Del(RelayArgClientCert)
+		query.Del(RelayArgClientKey)
+		found = true
+	}
+
+	if query.Has(RelayArgProxy) {
+		proxy, err := url.Parse(query.Get(RelayArgProxy))
+		if err != nil || !slices.Contains(ProxySchemes, proxy.Scheme) || proxy.Host == "" {
+			return fmt.Errorf("%w: %s=%s", ErrInvalidRelayOption, RelayArgProxy, query.Get(RelayArgProxy))
+		}
+		r.Proxy = proxy
+		query.Del(RelayArgProxy)
+		found = true
+	}
+
+	// The credentials aren't part of the errors
+	if query.Has(RelayArgBasicAuth) {
+		user, password, ok := strings.Cut(query.Get(RelayArgBasicAuth), ":")
+		if !ok || user == "" {
+			return fmt.Errorf("%w: %s needs user:password", ErrInvalidRelayOption, RelayArgBasicAuth)
+		}
+		r.BasicAuth = url.UserPassword(user, password)
+		query.Del(RelayArgBasicAuth)
+		found = true
+	}
+
+	if query.Has(RelayArgHeader) {
+		r.Headers = make(http.Header)
+		for _, header := range query[RelayArgHeader] {
+			name, value, ok := strings.Cut(header, ":")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" || strings.ContainsAny(name, " \t") {
+				return fmt.Errorf("%w: %s needs name:value", ErrInvalidRelayOption, RelayArgHeader)
+			}
+			r.Headers.Add(name, strings.TrimSpace(value))
+		}
+		query.Del(RelayArgHeader)
+		found = true
+	}
+
+	if found {
+		r.URL.RawQuery = query.Encode()
+	}
+	return nil
+}
+
 // RelayEntriesToStrings returns the string representation of a list of relay entries
 func RelayEntriesToStrings(relays []RelayEntry) []string {
 	ret := make([]string, len(relays))
@@ -2,7 +2,9 @@ package types
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/go-boost-utils/utils"
@@ -109,3 +111,168 @@ func TestParseRelaysURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestRelayEntryGetURIWithQuery(t *testing.T) {
+	publicKey, err := utils.HexToPubkey("0x82f6e7cc57a2ce68ec41321bebc55bcb31945fe66a8e67eb8251425fab4c6a38c10c53210aea9796dd0ba0441b46762a")
+	require.NoError(t, err)
+
+	// The base path and the args of the relay URL are kept for all API paths, the options are not sent
+	relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com/relay/?token=abc&tier=1", publicKey.String()))
+	require.NoError(t, err)
+	require.Equal(t, "http://foo.com/relay/eth/v1/builder/status?token=abc", relayEntry.GetURI("/eth/v1/builder/status"))
+	require.Equal(t, "http://foo.com/relay/relay/v1/data/bidtraces/proposer_payload_delivered?slot=1&token=abc",
+		relayEntry.GetURIWithQuery("/relay/v1/data/bidtraces/proposer_payload_delivered", url.Values{"slot": []string{"1"}}))
+	require.Equal(t, "http://foo.com/relay/?token=abc", relayEntry.GetURI(""))
+}
+
+func TestRelayEntryOptions(t *testing.T) {
+	publicKey, err := utils.HexToPubkey("0x82f6e7cc57a2ce68ec41321bebc55bcb31945fe66a8e67eb8251425fab4c6a38c10c53210aea9796dd0ba0441b46762a")
+	require.NoError(t, err)
+
+	t.Run("Timeouts", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?id=foo&timeout_get_header=750ms&timeout_get_payload=2s&timeout_register_validator=5s", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, 750*time.Millisecond, relayEntry.TimeoutGetHeader)
+		require.Equal(t, 2*time.Second, relayEntry.TimeoutGetPayload)
+		require.Equal(t, 5*time.Second, relayEntry.TimeoutRegVal)
+
+		// The options are not sent to the relay
+		require.Equal(t, "http://foo.com/eth/v1/builder/status?id=foo", relayEntry.GetURI("/eth/v1/builder/status"))
+	})
+
+	t.Run("Tier and weight", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?tier=1&weight=3", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, 1, relayEntry.Tier)
+		require.Equal(t, 3, relayEntry.Weight)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?tier=-1", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Signature check", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@localhost:28545?signature_check=skip", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, SignatureCheckSkip, relayEntry.SignatureCheck)
+		require.Equal(t, "http://localhost:28545/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?signature_check=never", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Backup", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?backup=true", publicKey.String()))
+		require.NoError(t, err)
+		require.True(t, relayEntry.Backup)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?backup=maybe", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Boost factor", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?boost_factor=1.05", publicKey.String()))
+		require.NoError(t, err)
+		require.InDelta(t, 1.05, relayEntry.BoostFactor, 1e-9)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?boost_factor=0", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Constraints", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?constraints=true", publicKey.String()))
+		require.NoError(t, err)
+		require.True(t, relayEntry.Constraints)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?constraints=maybe", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("SSZ", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?ssz=true", publicKey.String()))
+		require.NoError(t, err)
+		require.True(t, relayEntry.SSZ)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?ssz=maybe", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Retry policy", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?max_retries=2&retry_backoff=250ms&retry_jitter=50ms", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, 2, relayEntry.MaxRetries)
+		require.Equal(t, 250*time.Millisecond, relayEntry.RetryBackoff)
+		require.Equal(t, 50*time.Millisecond, relayEntry.RetryJitter)
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?max_retries=-1", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?retry_backoff=soon", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Client certificate", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("https://%s@foo.com?client_cert=/etc/mev-boost/relay.crt&client_key=/etc/mev-boost/relay.key", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, "/etc/mev-boost/relay.crt", relayEntry.ClientCert)
+		require.Equal(t, "/etc/mev-boost/relay.key", relayEntry.ClientKey)
+		require.Equal(t, "https://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("https://%s@foo.com?client_cert=/etc/mev-boost/relay.crt", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.onion?proxy=socks5h://127.0.0.1:9050", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, "socks5h://127.0.0.1:9050", relayEntry.Proxy.String())
+		require.Equal(t, "http://foo.onion/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?proxy=ftp://127.0.0.1:21", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?proxy=127.0.0.1:9050", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Credentials and headers", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("https://%s@foo.com?basic_auth=operator:s3cr3t&header=X-Api-Key:abc&header=Authorization:Bearer+xyz", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, "operator", relayEntry.BasicAuth.Username())
+		password, _ := relayEntry.BasicAuth.Password()
+		require.Equal(t, "s3cr3t", password)
+		require.Equal(t, "abc", relayEntry.Headers.Get("X-Api-Key"))
+		require.Equal(t, "Bearer xyz", relayEntry.Headers.Get("Authorization"))
+		require.Equal(t, fmt.Sprintf("https://%s@foo.com", publicKey.String()), relayEntry.String())
+
+		_, err = NewRelayEntry(fmt.Sprintf("https://%s@foo.com?basic_auth=s3cr3t", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+		require.NotContains(t, err.Error(), "s3cr3t")
+		_, err = NewRelayEntry(fmt.Sprintf("https://%s@foo.com?header=abc", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+		require.NotContains(t, err.Error(), "abc")
+	})
+
+	t.Run("Labels", func(t *testing.T) {
+		relayEntry, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?labels=non-filtering+eu", publicKey.String()))
+		require.NoError(t, err)
+		require.Equal(t, []string{"non-filtering", "eu"}, relayEntry.Labels)
+		require.True(t, relayEntry.HasLabel("eu"))
+		require.False(t, relayEntry.HasLabel("filtering"))
+		require.Equal(t, "http://foo.com/eth/v1/builder/status", relayEntry.GetURI("/eth/v1/builder/status"))
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?labels=", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+
+	t.Run("Invalid timeouts", func(t *testing.T) {
+		_, err := NewRelayEntry(fmt.Sprintf("http://%s@foo.com?timeout_get_header=750", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+
+		_, err = NewRelayEntry(fmt.Sprintf("http://%s@foo.com?timeout_get_payload=-1s", publicKey.String()))
+		require.ErrorIs(t, err, ErrInvalidRelayOption)
+	})
+}
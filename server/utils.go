@@ -9,6 +9,7 @@ import (
 	"io"
 	"math/big"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
 
@@ -24,20 +25,39 @@ import (
 	"github.com/flashbots/mev-boost/server/types"
 	"github.com/holiman/uint256"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	HeaderKeySlotUID      = "X-MEVBoost-SlotID"
 	HeaderKeyVersion      = "X-MEVBoost-Version"
 	HeaderStartTimeUnixMS = "X-MEVBoost-StartTimeUnixMS"
+	HeaderLocalBlockValue = "X-MEVBoost-LocalBlockValue" // the value of the local payload in wei, sent by the beacon node
+
+	// HeaderEthConsensusVersion is the consensus version of a request or response body, as in the builder API
+	HeaderEthConsensusVersion = "Eth-Consensus-Version"
+)
+
+// Response headers with the auction outcome, for consensus clients and wrappers
+const (
+	HeaderKeyRelay           = "X-MEVBoost-Relay"            // the relays of the bid, or the relay which delivered the payload
+	HeaderKeyBidValueWei     = "X-MEVBoost-Bid-Value-Wei"    // the value of the bid
+	HeaderKeyRelaysResponded = "X-MEVBoost-Relays-Responded" // the number of relays which answered getHeader
 )
 
 var (
 	errHTTPErrorResponse  = errors.New("HTTP error response")
 	errInvalidForkVersion = errors.New("invalid fork version")
 	errMaxRetriesExceeded = errors.New("max retries exceeded")
+	errInvalidResponse    = errors.New("could not unmarshal response")
 )
 
+// maxErrorBodyBytes is the length of a response body kept in errors, relays can respond with large bodies
+const maxErrorBodyBytes = 512
+
 // UserAgent is a custom string type to avoid confusing url + userAgent parameters in SendHTTPRequest
 type UserAgent string
 
@@ -46,6 +66,15 @@ type BlockHashHex string
 
 // SendHTTPRequest - prepare and send HTTP request, marshaling the payload if any, and decoding the response if dst is set
 func SendHTTPRequest(ctx context.Context, client http.Client, method, url string, userAgent UserAgent, headers map[string]string, payload, dst any) (code int, err error) {
+	ctx, span := startSpan(ctx, "SendHTTPRequest", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", url),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.status_code", code))
+		endSpan(span, err)
+	}()
+
 	var req *http.Request
 
 	if payload == nil {
@@ -54,16 +83,29 @@ func SendHTTPRequest(ctx context.Context, client http.Client, method, url string
 			return 0, fmt.Errorf("could not prepare request: %w", err)
 		}
 	} else {
-		payloadBytes, err2 := json.Marshal(payload)
-		if err2 != nil {
-			return 0, fmt.Errorf("could not marshal request: %w", err2)
+		contentType := MediaTypeJSON
+		payloadBytes, isSSZ := payload.(sszBody)
+		if isSSZ {
+			contentType = MediaTypeSSZ
+		} else {
+			var err2 error
+			payloadBytes, err2 = json.Marshal(payload)
+			if err2 != nil {
+				return 0, fmt.Errorf("could not marshal request: %w", err2)
+			}
 		}
 		req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payloadBytes))
 		if err != nil {
 			return 0, fmt.Errorf("could not prepare request: %w", err)
 		}
 		// Set Content-Type header
-		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Content-Type", contentType)
+	}
+
+	// Prefer SSZ responses if the caller decodes them
+	rawResponse, isSSZ := dst.(*sszResponse)
+	if isSSZ {
+		req.Header.Set("Accept", "application/octet-stream;q=1.0,application/json;q=0.9")
 	}
 
 	// Set User-Agent header
@@ -74,6 +116,16 @@ func SendHTTPRequest(ctx context.Context, client http.Client, method, url string
 		req.Header.Set(key, value)
 	}
 
+	// Propagate the trace context to the relay
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	// Record the request phase durations if requested
+	if timings := requestTimingsFromContext(ctx); timings != nil {
+		timings.begin()
+		defer timings.finish()
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), timings.clientTrace()))
+	}
+
 	// Execute request
 	resp, err := client.Do(req)
 	if err != nil {
@@ -86,11 +138,12 @@ func SendHTTPRequest(ctx context.Context, client http.Client, method, url string
 	}
 
 	if resp.StatusCode > 299 {
-		bodyBytes, err := io.ReadAll(resp.Body)
+		// The error is reported to the beacon node, webhooks and Sentry, only the start of the body is kept
+		bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes+1))
 		if err != nil {
 			return resp.StatusCode, fmt.Errorf("could not read error response body for status code %d: %w", resp.StatusCode, err)
 		}
-		return resp.StatusCode, fmt.Errorf("%w: %d / %s", errHTTPErrorResponse, resp.StatusCode, string(bodyBytes))
+		return resp.StatusCode, fmt.Errorf("%w: %d / %s", errHTTPErrorResponse, resp.StatusCode, truncateBody(bodyBytes))
 	}
 
 	if dst != nil {
@@ -99,8 +152,15 @@ func SendHTTPRequest(ctx context.Context, client http.Client, method, url string
 			return resp.StatusCode, fmt.Errorf("could not read response body: %w", err)
 		}
 
+		if isSSZ {
+			rawResponse.contentType = resp.Header.Get("Content-Type")
+			rawResponse.version = resp.Header.Get(HeaderEthConsensusVersion)
+			rawResponse.body = bodyBytes
+			return resp.StatusCode, nil
+		}
+
 		if err := json.Unmarshal(bodyBytes, dst); err != nil {
-			return resp.StatusCode, fmt.Errorf("could not unmarshal response %s: %w", string(bodyBytes), err)
+			return resp.StatusCode, fmt.Errorf("%w %s: %w", errInvalidResponse, truncateBody(bodyBytes), err)
 		}
 	}
 
@@ -109,6 +169,11 @@ func SendHTTPRequest(ctx context.Context, client http.Client, method, url string
 
 // SendHTTPRequestWithRetries - prepare and send HTTP request, retrying the request if within the client timeout
 func SendHTTPRequestWithRetries(ctx context.Context, client http.Client, method, url string, userAgent UserAgent, headers map[string]string, payload, dst any, maxRetries int, log *logrus.Entry) (code int, err error) {
+	return sendHTTPRequestWithRetries(ctx, client, method, url, userAgent, headers, payload, dst, retryPolicy{maxRetries: maxRetries, backoff: defaultRetryBackoff}, log)
+}
+
+// sendHTTPRequestWithRetries sends the HTTP request like SendHTTPRequestWithRetries, with the retry policy
+func sendHTTPRequestWithRetries(ctx context.Context, client http.Client, method, url string, userAgent UserAgent, headers map[string]string, payload, dst any, policy retryPolicy, log *logrus.Entry) (code int, err error) {
 	var requestCtx context.Context
 	var cancel context.CancelFunc
 	if client.Timeout > 0 {
@@ -119,20 +184,36 @@ func SendHTTPRequestWithRetries(ctx context.Context, client http.Client, method,
 	}
 	defer cancel()
 
+	spanCtx, span := startSpan(ctx, "SendHTTPRequestWithRetries", trace.WithAttributes(attribute.String("url.full", url)))
 	attempts := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("attempts", attempts))
+		endSpan(span, err)
+	}()
+
 	for {
 		attempts++
 		if requestCtx.Err() != nil {
 			return 0, fmt.Errorf("request context error after %d attempts: %w", attempts, requestCtx.Err())
 		}
-		if attempts > maxRetries {
+		if attempts > policy.maxRetries {
+			if err != nil {
+				// Keep the last error and code, so callers can tell why the relay failed
+				return code, fmt.Errorf("%w: %w", errMaxRetriesExceeded, err)
+			}
 			return 0, errMaxRetriesExceeded
 		}
 
-		code, err = SendHTTPRequest(ctx, client, method, url, userAgent, headers, payload, dst)
+		span.AddEvent("attempt", trace.WithAttributes(attribute.Int("attempt", attempts)))
+		code, err = SendHTTPRequest(spanCtx, client, method, url, userAgent, headers, payload, dst)
+		if err != nil && rejectsEncoding(code) {
+			// Retrying doesn't help if the relay doesn't accept the encoding
+			return code, err
+		}
 		if err != nil {
-			log.WithError(err).Warn("error making request to relay, retrying")
-			time.Sleep(100 * time.Millisecond) // note: this timeout is only applied between retries, it does not delay the initial request!
+			delay := policy.delay()
+			log.WithError(err).WithField("retryDelay", delay.String()).Warn("error making request to relay, retrying")
+			time.Sleep(delay) // note: this delay is only applied between retries, it does not delay the initial request!
 			continue
 		}
 		return code, nil
@@ -151,6 +232,14 @@ func ComputeDomain(domainType phase0.DomainType, forkVersionHex, genesisValidato
 	return ssz.ComputeDomain(domainType, forkVersion, genesisValidatorsRoot), nil
 }
 
+// truncateBody returns the first maxErrorBodyBytes of the response body, for errors
+func truncateBody(body []byte) string {
+	if len(body) <= maxErrorBodyBytes {
+		return string(body)
+	}
+	return string(body[:maxErrorBodyBytes]) + "..."
+}
+
 // DecodeJSON reads JSON from io.Reader and decodes it into a struct
 func DecodeJSON(r io.Reader, dst any) error {
 	decoder := json.NewDecoder(r)
@@ -164,6 +253,9 @@ type bidResp struct {
 	response builderSpec.VersionedSignedBuilderBid
 	bidInfo  bidInfo
 	relays   []types.RelayEntry
+
+	responded int    // the number of relays which answered, with or without bid
+	pubkey    string // the proposer pubkey of the getHeader request
 }
 
 // bidInfo is used to store bid response fields for logging and validation
@@ -172,7 +264,9 @@ type bidInfo struct {
 	parentHash  phase0.Hash32
 	pubkey      phase0.BLSPubKey
 	blockNumber uint64
+	timestamp   uint64
 	txRoot      phase0.Root
+	gasLimit    uint64
 	value       *uint256.Int
 }
 
@@ -205,6 +299,10 @@ func parseBidInfo(bid *builderSpec.VersionedSignedBuilderBid) (bidInfo, error) {
 	if err != nil {
 		return bidInfo{}, err
 	}
+	timestamp, err := bid.Timestamp()
+	if err != nil {
+		return bidInfo{}, err
+	}
 	txRoot, err := bid.TransactionsRoot()
 	if err != nil {
 		return bidInfo{}, err
@@ -218,7 +316,9 @@ func parseBidInfo(bid *builderSpec.VersionedSignedBuilderBid) (bidInfo, error) {
 		parentHash:  parentHash,
 		pubkey:      pubkey,
 		blockNumber: blockNumber,
+		timestamp:   timestamp,
 		txRoot:      txRoot,
+		gasLimit:    bidGasLimit(bid),
 		value:       value,
 	}, nil
 }
@@ -241,6 +341,11 @@ func checkRelaySignature(bid *builderSpec.VersionedSignedBuilderBid, domain phas
 	return bls.VerifySignatureBytes(msg[:], sig[:], pubKey[:])
 }
 
+// isLegacyFork returns true for the pre-Deneb forks with builder API support, Bellatrix and Capella
+func isLegacyFork(fork string) bool {
+	return fork == spec.DataVersionBellatrix.String() || fork == spec.DataVersionCapella.String()
+}
+
 func getPayloadResponseIsEmpty(payload *builderApi.VersionedSubmitBlindedBlockResponse) bool {
 	switch payload.Version {
 	case spec.DataVersionBellatrix:
@@ -263,6 +368,12 @@ func getPayloadResponseIsEmpty(payload *builderApi.VersionedSubmitBlindedBlockRe
 			payload.Electra.BlobsBundle == nil {
 			return true
 		}
+	case spec.DataVersionFulu:
+		if payload.Fulu == nil || payload.Fulu.ExecutionPayload == nil ||
+			payload.Fulu.ExecutionPayload.BlockHash == nilHash ||
+			payload.Fulu.BlobsBundle == nil {
+			return true
+		}
 	case spec.DataVersionUnknown, spec.DataVersionPhase0, spec.DataVersionAltair:
 		return true
 	}
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	builderApi "github.com/attestantio/go-builder-client/api"
@@ -89,6 +90,36 @@ func TestSendHTTPRequestGzip(t *testing.T) {
 	require.Equal(t, "test-message", resp.Msg)
 }
 
+func TestSendHTTPRequestInvalidResponse(t *testing.T) {
+	body := strings.Repeat("x", 2*maxErrorBodyBytes)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	resp := struct{ Msg string }{}
+	_, err := SendHTTPRequest(context.Background(), *http.DefaultClient, http.MethodGet, ts.URL, "", nil, nil, &resp)
+	require.ErrorIs(t, err, errInvalidResponse)
+	// Only the start of the body is kept in the error
+	require.Contains(t, err.Error(), body[:maxErrorBodyBytes]+"...")
+	require.NotContains(t, err.Error(), body[:maxErrorBodyBytes+1])
+}
+
+func TestSendHTTPRequestErrorResponse(t *testing.T) {
+	body := strings.Repeat("x", 2*maxErrorBodyBytes)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	code, err := SendHTTPRequest(context.Background(), *http.DefaultClient, http.MethodGet, ts.URL, "", nil, nil, nil)
+	require.Equal(t, http.StatusInternalServerError, code)
+	require.ErrorIs(t, err, errHTTPErrorResponse)
+	require.Contains(t, err.Error(), body[:maxErrorBodyBytes]+"...")
+	require.NotContains(t, err.Error(), body[:maxErrorBodyBytes+1])
+}
+
 func TestWeiBigIntToEthBigFloat(t *testing.T) {
 	// test with valid input
 	i := big.NewInt(1)
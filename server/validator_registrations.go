@@ -0,0 +1,76 @@
+package server
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+)
+
+// validatorRegistrations remembers the latest signed registration of each validator, by lowercase pubkey, i.e. for its
+// gas limit and fee recipient and to replay it to new relays
+type validatorRegistrations struct {
+	mu            sync.Mutex
+	registrations map[string]*builderApiV1.SignedValidatorRegistration
+	dirty         bool // changed since the last snapshot
+}
+
+func newValidatorRegistrations() *validatorRegistrations {
+	return &validatorRegistrations{registrations: make(map[string]*builderApiV1.SignedValidatorRegistration)}
+}
+
+// record remembers the registrations
+func (r *validatorRegistrations) record(registrations []builderApiV1.SignedValidatorRegistration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range registrations {
+		if registrations[i].Message == nil {
+			continue
+		}
+		registration := registrations[i]
+		r.registrations[strings.ToLower(registration.Message.Pubkey.String())] = &registration
+		r.dirty = true
+	}
+}
+
+// get returns the latest registration of the validator, false if it didn't register since the start
+func (r *validatorRegistrations) get(pubkey string) (*builderApiV1.ValidatorRegistration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registration, ok := r.registrations[strings.ToLower(pubkey)]
+	if !ok {
+		return nil, false
+	}
+	return registration.Message, true
+}
+
+// all returns the latest signed registrations of all validators, sorted by pubkey
+func (r *validatorRegistrations) all() []builderApiV1.SignedValidatorRegistration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.list()
+}
+
+// snapshot returns the registrations like all, and whether they changed since the previous snapshot
+func (r *validatorRegistrations) snapshot() ([]builderApiV1.SignedValidatorRegistration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dirty := r.dirty
+	r.dirty = false
+	return r.list(), dirty
+}
+
+// list returns the registrations sorted by pubkey. The caller must hold the lock.
+func (r *validatorRegistrations) list() []builderApiV1.SignedValidatorRegistration {
+	pubkeys := make([]string, 0, len(r.registrations))
+	for pubkey := range r.registrations {
+		pubkeys = append(pubkeys, pubkey)
+	}
+	sort.Strings(pubkeys)
+	ret := make([]builderApiV1.SignedValidatorRegistration, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		ret = append(ret, *r.registrations[pubkey])
+	}
+	return ret
+}
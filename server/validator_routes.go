@@ -0,0 +1,125 @@
+package server
+
+import (
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/utils"
+	"github.com/flashbots/mev-boost/server/types"
+)
+
+// ValidatorRoute are the relays and min bid used for a group of validators instead of the defaults
+type ValidatorRoute struct {
+	Name        string
+	Relays      []types.RelayEntry // uses the default relays if empty
+	RelayMinBid *types.U256Str     // uses the default min bid if nil
+}
+
+// ValidatorRoutes maps validator pubkeys to their route, validators without route use the default relays and min bid
+type ValidatorRoutes map[phase0.BLSPubKey]*ValidatorRoute
+
+// RelayFilter restricts which validators use a relay, for their registrations, bids and payloads
+type RelayFilter struct {
+	Allow map[phase0.BLSPubKey]bool // only these validators use the relay, if not empty
+	Deny  map[phase0.BLSPubKey]bool // these validators don't use the relay
+}
+
+// RelayFilters maps relay pubkeys to their filter, relays without filter are used by all validators
+type RelayFilters map[phase0.BLSPubKey]*RelayFilter
+
+// allows returns true if the validator may use the relay
+func (f *RelayFilter) allows(pubkey phase0.BLSPubKey) bool {
+	if f.Deny[pubkey] {
+		return false
+	}
+	return len(f.Allow) == 0 || f.Allow[pubkey]
+}
+
+// filter returns the relays the validator may use
+func (f RelayFilters) filter(relays []types.RelayEntry, pubkey phase0.BLSPubKey) []types.RelayEntry {
+	ret := make([]types.RelayEntry, 0, len(relays))
+	for _, relay := range relays {
+		if filter, ok := f[relay.PublicKey]; !ok || filter.allows(pubkey) {
+			ret = append(ret, relay)
+		}
+	}
+	return ret
+}
+
+// relayRegistrations are the validator registrations sent to a relay
+type relayRegistrations struct {
+	relay         types.RelayEntry
+	registrations []builderApiV1.SignedValidatorRegistration
+}
+
+// forValidator returns the settings with the relays and min bid of the validator's route, without the relays whose
+// filter excludes the validator
+func (c reloadableConfig) forValidator(pubkeyHex string) reloadableConfig {
+	if len(c.validatorRoutes) == 0 && len(c.relayFilters) == 0 {
+		return c
+	}
+	pubkey, err := utils.HexToPubkey(pubkeyHex)
+	if err != nil {
+		return c
+	}
+	return c.forValidatorPubkey(pubkey)
+}
+
+func (c reloadableConfig) forValidatorPubkey(pubkey phase0.BLSPubKey) reloadableConfig {
+	if route, ok := c.validatorRoutes[pubkey]; ok {
+		if len(route.Relays) > 0 {
+			c.relays = route.Relays
+		}
+		if route.RelayMinBid != nil {
+			c.relayMinBid = *route.RelayMinBid
+		}
+	}
+	if len(c.relayFilters) > 0 {
+		c.relays = c.relayFilters.filter(c.relays, pubkey)
+	}
+	return c
+}
+
+// withBidRelays adds the relays of the bid which are not among the relays, i.e. when the bid was requested from the
+// relays of a validator route
+func (c reloadableConfig) withBidRelays(bidRelays []types.RelayEntry) reloadableConfig {
+	c.relays = mergeRelays(c.relays, bidRelays)
+	return c
+}
+
+// registrationsByRelay groups the validator registrations by the relays of their validator routes and relay filters.
+// Without routes and filters, all relays receive all registrations.
+func (c reloadableConfig) registrationsByRelay(payload []builderApiV1.SignedValidatorRegistration) []relayRegistrations {
+	ret := make([]relayRegistrations, 0, len(c.relays))
+	if len(c.validatorRoutes) == 0 && len(c.relayFilters) == 0 {
+		for _, relay := range c.relays {
+			ret = append(ret, relayRegistrations{relay: relay, registrations: payload})
+		}
+		return ret
+	}
+
+	index := make(map[string]int)
+	for _, registration := range payload {
+		if registration.Message == nil {
+			continue
+		}
+		for _, relay := range c.forValidatorPubkey(registration.Message.Pubkey).relays {
+			i, ok := index[relay.String()]
+			if !ok {
+				i = len(ret)
+				index[relay.String()] = i
+				ret = append(ret, relayRegistrations{relay: relay})
+			}
+			ret[i].registrations = append(ret[i].registrations, registration)
+		}
+	}
+	return ret
+}
+
+func containsRelay(relays []types.RelayEntry, relay types.RelayEntry) bool {
+	for _, r := range relays {
+		if r.String() == relay.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	builderApiV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/mev-boost/server/mock"
+	"github.com/flashbots/mev-boost/server/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorRoutes(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	routedPubkey := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	defaultPubkey := mock.HexToPubkey(
+		"0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae")
+
+	// newRoutedBackend returns a backend with two default relays and a route to the third relay
+	newRoutedBackend := func(t *testing.T, minBid *types.U256Str) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 3, time.Second)
+		backend.boost.relays = []types.RelayEntry{backend.relays[0].RelayEntry, backend.relays[1].RelayEntry}
+		backend.boost.validatorRoutes = ValidatorRoutes{
+			routedPubkey: {Name: "client-a", Relays: []types.RelayEntry{backend.relays[2].RelayEntry}, RelayMinBid: minBid},
+		}
+		return backend
+	}
+
+	t.Run("getHeader queries the relays of the route", func(t *testing.T) {
+		backend := newRoutedBackend(t, nil)
+
+		path := getHeaderPath(1, hash, routedPubkey)
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+		require.Equal(t, 0, backend.relays[1].GetRequestCount(path))
+		require.Equal(t, 1, backend.relays[2].GetRequestCount(path))
+
+		path = getHeaderPath(1, hash, defaultPubkey)
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+		require.Equal(t, 1, backend.relays[1].GetRequestCount(path))
+		require.Equal(t, 0, backend.relays[2].GetRequestCount(path))
+	})
+
+	t.Run("getHeader uses the min bid of the route", func(t *testing.T) {
+		minBid := types.IntToU256(12346)
+		backend := newRoutedBackend(t, &minBid)
+
+		// The default bid of 12345 is below the min bid of the route, but not below the default min bid
+		rr := backend.request(t, http.MethodGet, getHeaderPath(1, hash, routedPubkey), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+
+		rr = backend.request(t, http.MethodGet, getHeaderPath(1, hash, defaultPubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("registerValidator sends the registrations to the relays of their route", func(t *testing.T) {
+		backend := newRoutedBackend(t, nil)
+
+		var mu sync.Mutex
+		received := make(map[int][]string)
+		for i, relay := range backend.relays {
+			relay.OverrideHandleRegisterValidator(func(w http.ResponseWriter, req *http.Request) {
+				payload := []builderApiV1.SignedValidatorRegistration{}
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				mu.Lock()
+				for _, registration := range payload {
+					received[i] = append(received[i], registration.Message.Pubkey.String())
+				}
+				mu.Unlock()
+				w.WriteHeader(http.StatusOK)
+			})
+		}
+
+		payload := []builderApiV1.SignedValidatorRegistration{
+			{Message: &builderApiV1.ValidatorRegistration{Timestamp: time.Unix(1234356, 0), Pubkey: routedPubkey}},
+			{Message: &builderApiV1.ValidatorRegistration{Timestamp: time.Unix(1234356, 0), Pubkey: defaultPubkey}},
+		}
+		rr := backend.request(t, http.MethodPost, "/eth/v1/builder/validators", payload)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// Wait for the responses of the other relays
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received) == 3
+		}, time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{defaultPubkey.String()}, received[0])
+		require.Equal(t, []string{defaultPubkey.String()}, received[1])
+		require.Equal(t, []string{routedPubkey.String()}, received[2])
+	})
+
+	t.Run("getPayload includes the relays of the bid", func(t *testing.T) {
+		backend := newRoutedBackend(t, nil)
+		bidRelays := []types.RelayEntry{backend.relays[0].RelayEntry, backend.relays[2].RelayEntry}
+
+		cfg := backend.boost.currentConfig().withBidRelays(bidRelays)
+		require.Equal(t, []string{
+			backend.relays[0].RelayEntry.String(),
+			backend.relays[1].RelayEntry.String(),
+			backend.relays[2].RelayEntry.String(),
+		}, types.RelayEntriesToStrings(cfg.relays))
+		require.Len(t, backend.boost.relays, 2)
+	})
+}
+
+func TestRelayFilters(t *testing.T) {
+	hash := mock.HexToHash("0xe28385e7bd68df656cd0042b74b69c3104b5356ed1f20eb69f1f925df47a3ab7")
+	pubkeyA := mock.HexToPubkey(
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249")
+	pubkeyB := mock.HexToPubkey(
+		"0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae")
+
+	t.Run("Filters the relays of each validator", func(t *testing.T) {
+		relayA, err := types.NewRelayEntry("https://" + pubkeyA.String() + "@relay-a.example.com")
+		require.NoError(t, err)
+		relayB, err := types.NewRelayEntry("https://" + pubkeyB.String() + "@relay-b.example.com")
+		require.NoError(t, err)
+		relays := []types.RelayEntry{relayA, relayB}
+
+		// Relay A is only used by validator A, relay B by all validators but A
+		filters := RelayFilters{
+			relayA.PublicKey: {Allow: map[phase0.BLSPubKey]bool{pubkeyA: true}},
+			relayB.PublicKey: {Deny: map[phase0.BLSPubKey]bool{pubkeyA: true}},
+		}
+		require.Equal(t, []types.RelayEntry{relayA}, filters.filter(relays, pubkeyA))
+		require.Equal(t, []types.RelayEntry{relayB}, filters.filter(relays, pubkeyB))
+
+		cfg := reloadableConfig{relays: relays, relayFilters: filters}
+		payload := []builderApiV1.SignedValidatorRegistration{
+			{Message: &builderApiV1.ValidatorRegistration{Pubkey: pubkeyA}},
+			{Message: &builderApiV1.ValidatorRegistration{Pubkey: pubkeyB}},
+		}
+		relayPayloads := cfg.registrationsByRelay(payload)
+		require.Len(t, relayPayloads, 2)
+		require.Equal(t, relayA.String(), relayPayloads[0].relay.String())
+		require.Equal(t, payload[:1], relayPayloads[0].registrations)
+		require.Equal(t, relayB.String(), relayPayloads[1].relay.String())
+		require.Equal(t, payload[1:], relayPayloads[1].registrations)
+	})
+
+	t.Run("getHeader skips the filtered relays", func(t *testing.T) {
+		backend := newTestBackend(t, 1, time.Second)
+		backend.boost.relayFilters = RelayFilters{
+			backend.relays[0].RelayEntry.PublicKey: {Deny: map[phase0.BLSPubKey]bool{pubkeyA: true}},
+		}
+
+		path := getHeaderPath(1, hash, pubkeyA)
+		rr := backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		require.Equal(t, 0, backend.relays[0].GetRequestCount(path))
+
+		path = getHeaderPath(1, hash, pubkeyB)
+		rr = backend.request(t, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, 1, backend.relays[0].GetRequestCount(path))
+	})
+}